@@ -17,8 +17,9 @@ limitations under the License.
 package main
 
 import (
-	//blockchain "knative.dev/eventing-blockchain/pkg/reconciler/source"
-	//"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/eventing-blockchain/pkg/reconciler/network"
+	blockchain "knative.dev/eventing-blockchain/pkg/reconciler/source"
+	"knative.dev/pkg/injection/sharedmain"
 )
 
 const (
@@ -26,5 +27,5 @@ const (
 )
 
 func main() {
-	//sharedmain.Main(component, blockchain.NewController)
+	sharedmain.Main(component, blockchain.NewController, network.NewController)
 }
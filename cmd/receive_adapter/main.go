@@ -17,11 +17,11 @@ limitations under the License.
 package main
 
 import (
-	//"knative.dev/eventing/pkg/adapter/v2"
+	"knative.dev/eventing/pkg/adapter/v2"
 
-	//githubadapter "knative.dev/eventing-github/pkg/adapter"
+	blockchainadapter "knative.dev/eventing-blockchain/pkg/adapter"
 )
 
 func main() {
-	//adapter.Main("githubsource", githubadapter.NewEnvConfig, githubadapter.NewAdapter)
+	adapter.Main("blockchainsource", blockchainadapter.NewBlockchainEnvConfig, blockchainadapter.NewBlockchainAdapter)
 }
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/pkg/leaderelection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/signals"
+	tracingconfig "knative.dev/pkg/tracing/config"
+	"knative.dev/pkg/webhook"
+	"knative.dev/pkg/webhook/certificates"
+	"knative.dev/pkg/webhook/configmaps"
+	"knative.dev/pkg/webhook/resourcesemantics"
+	"knative.dev/pkg/webhook/resourcesemantics/conversion"
+	"knative.dev/pkg/webhook/resourcesemantics/defaulting"
+	"knative.dev/pkg/webhook/resourcesemantics/validation"
+
+	"knative.dev/eventing-blockchain/pkg/apis/config"
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+var types = map[schema.GroupVersionKind]resourcesemantics.GenericCRD{
+	sourcesv1alpha1.SchemeGroupVersion.WithKind("BlockchainSource"):  &sourcesv1alpha1.BlockchainSource{},
+	sourcesv1alpha1.SchemeGroupVersion.WithKind("BlockchainNetwork"): &sourcesv1alpha1.BlockchainNetwork{},
+}
+
+var callbacks = map[schema.GroupVersionKind]validation.Callback{}
+
+// configStore decorates the contexts of the admission and conversion
+// controllers with the current state of the config, such as the network
+// profiles used by SetDefaults. The controllers share the store, which
+// watches the configs once, with the watcher of the first one built.
+type configStore struct {
+	*config.Store
+	watch sync.Once
+}
+
+func newConfigStore(ctx context.Context) *configStore {
+	return &configStore{Store: config.NewStore(logging.FromContext(ctx).Named("config-store"))}
+}
+
+// contextFunc returns the function decorating contexts, watching the
+// configs with cmw on the first call.
+func (s *configStore) contextFunc(cmw configmap.Watcher) func(context.Context) context.Context {
+	s.watch.Do(func() {
+		s.WatchConfigs(cmw)
+	})
+	return s.ToContext
+}
+
+func NewDefaultingAdmissionController(store *configStore) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		return defaulting.NewAdmissionController(ctx,
+
+			// Name of the resource webhook.
+			"defaulting.webhook.blockchain.sources.knative.dev",
+
+			// The path on which to serve the webhook.
+			"/defaulting",
+
+			// The resources to default.
+			types,
+
+			// A function that infuses the context passed to Validate/SetDefaults with custom metadata.
+			store.contextFunc(cmw),
+
+			// Whether to disallow unknown fields.
+			true,
+		)
+	}
+}
+
+func NewValidationAdmissionController(store *configStore) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		return validation.NewAdmissionController(ctx,
+
+			// Name of the resource webhook.
+			"validation.webhook.blockchain.sources.knative.dev",
+
+			// The path on which to serve the webhook.
+			"/resource-validation",
+
+			// The resources to validate.
+			types,
+
+			// A function that infuses the context passed to Validate/SetDefaults with custom metadata.
+			store.contextFunc(cmw),
+
+			// Whether to disallow unknown fields.
+			true,
+
+			// Extra validating callbacks to be applied to resources.
+			callbacks,
+		)
+	}
+}
+
+func NewConfigValidationController(ctx context.Context, _ configmap.Watcher) *controller.Impl {
+	return configmaps.NewAdmissionController(ctx,
+
+		// Name of the configmap webhook.
+		"config.webhook.blockchain.sources.knative.dev",
+
+		// The path on which to serve the webhook.
+		"/config-validation",
+
+		// The configmaps to validate.
+		configmap.Constructors{
+			tracingconfig.ConfigName:       tracingconfig.NewTracingConfigFromConfigMap,
+			metrics.ConfigMapName():        metrics.NewObservabilityConfigFromConfigMap,
+			logging.ConfigMapName():        logging.NewConfigFromConfigMap,
+			leaderelection.ConfigMapName(): leaderelection.NewConfigFromConfigMap,
+			config.NetworksConfigName:      config.NewNetworksConfigFromConfigMap,
+		},
+	)
+}
+
+func NewConversionController(store *configStore) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		var (
+			sourcesv1alpha1_ = sourcesv1alpha1.SchemeGroupVersion.Version
+		)
+
+		return conversion.NewConversionController(ctx,
+			// The path on which to serve the webhook
+			"/resource-conversion",
+
+			// Specify the types of custom resource definitions that should be converted.
+			// v1alpha1 is the only version so far, new versions are added as
+			// zygotes converting to and from the hub.
+			map[schema.GroupKind]conversion.GroupKindConversion{
+				sourcesv1alpha1.Kind("BlockchainSource"): {
+					DefinitionName: sourcesv1alpha1.Resource("blockchainsources").String(),
+					HubVersion:     sourcesv1alpha1_,
+					Zygotes: map[string]conversion.ConvertibleObject{
+						sourcesv1alpha1_: &sourcesv1alpha1.BlockchainSource{},
+					},
+				},
+				sourcesv1alpha1.Kind("BlockchainNetwork"): {
+					DefinitionName: sourcesv1alpha1.Resource("blockchainnetworks").String(),
+					HubVersion:     sourcesv1alpha1_,
+					Zygotes: map[string]conversion.ConvertibleObject{
+						sourcesv1alpha1_: &sourcesv1alpha1.BlockchainNetwork{},
+					},
+				},
+			},
+
+			// A function that infuses the context passed to ConvertTo/ConvertFrom/SetDefaults with custom metadata.
+			store.contextFunc(cmw),
+		)
+	}
+}
+
+func main() {
+	// Set up a signal context with our webhook options
+	ctx := webhook.WithOptions(signals.NewContext(), webhook.Options{
+		ServiceName: webhook.NameFromEnv(),
+		Port:        webhook.PortFromEnv(8443),
+		// SecretName must match the name of the Secret created in the configuration.
+		SecretName: "blockchain-webhook-certs",
+	})
+
+	store := newConfigStore(ctx)
+	sharedmain.WebhookMainWithContext(ctx, webhook.NameFromEnv(),
+		certificates.NewController,
+		NewConfigValidationController,
+		NewValidationAdmissionController(store),
+		NewDefaultingAdmissionController(store),
+		NewConversionController(store),
+	)
+}
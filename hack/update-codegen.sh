@@ -50,7 +50,7 @@ group "Deepcopy Gen"
 ${GOPATH}/bin/deepcopy-gen \
   -O zz_generated.deepcopy \
   --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate.go.txt \
-  -i knative.dev/eventing-blockchain/pkg/apis \
+  -i knative.dev/eventing-blockchain/pkg/apis,knative.dev/eventing-blockchain/pkg/apis/config \
 
 group "Update deps post-codegen"
 
@@ -0,0 +1,439 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"knative.dev/eventing/pkg/adapter/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/beacon"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+)
+
+type beaconEnvConfig struct {
+	chainEnvConfig
+
+	// Environment variable containing the JSON encoded list of the topics
+	// of the events to emit. All topics are emitted when not set
+	EnvTopics string `envconfig:"BLOCKCHAIN_BEACON_TOPICS"`
+}
+
+// NewBeaconEnvConfig function reads env variables defined in
+// beaconEnvConfig structure and returns accessor interface
+func NewBeaconEnvConfig() adapter.EnvConfigAccessor {
+	return &beaconEnvConfig{}
+}
+
+// beaconAdapter reads the /eth/v1/events stream of the nodes of a beacon
+// chain, and converts the events of the selected topics to CloudEvents.
+// The stream always includes the head topic, which the adapter follows the
+// slots with. The head and block events of the slots missed while
+// disconnected are recovered from the block headers of the canonical chain
+// once reconnected, and the finalized checkpoint from the finality
+// checkpoints of the head state. Reorgs and voluntary exits cannot be
+// recovered.
+type beaconAdapter struct {
+	emitter
+
+	rpcURL            string
+	endpointsJSON     string
+	chainID           string
+	topicsJSON        string
+	minReconnectDelay time.Duration
+	startBlock        *uint64
+
+	// nodes are the nodes, in priority order.
+	nodes []*beaconNode
+	// topics are the topics of the events to emit.
+	topics map[string]bool
+
+	// observedChainID is the chain ID the nodes reported, and source the
+	// CloudEvent source of the emitted events, once known.
+	observedChainID string
+	source          string
+	// spec holds the parameters of the chain, and genesisTime the start of
+	// its first slot, once a node is reached.
+	spec        *beacon.Spec
+	genesisTime int64
+	// active is the name of the node last read from.
+	active string
+	// head is the slot of the newest head.
+	head uint64
+	// first is the first slot whose events are emitted.
+	first uint64
+	// finalized is the last finalized epoch, once known.
+	finalized      uint64
+	finalizedKnown bool
+	// recent tracks the blocks of the slots of the last epoch, whose
+	// events may be both recovered and notified.
+	recent map[uint64]map[string]bool
+}
+
+// errStreamLost is the error of the event streams that end, which nodes do
+// when restarting or overloaded.
+var errStreamLost = errors.New("event stream lost")
+
+// beaconNode is a node, whose Beacon API is read with api.
+type beaconNode struct {
+	*endpoint
+	api *beacon.Client
+}
+
+// NewBeaconAdapter returns the instance of beaconAdapter that implements adapter.Adapter interface
+func NewBeaconAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	env := processed.(*beaconEnvConfig)
+
+	a := &beaconAdapter{
+		emitter:           newEmitter(ctx, &env.chainEnvConfig, ceClient),
+		rpcURL:            env.EnvRPCURL,
+		endpointsJSON:     env.EnvEndpoints,
+		chainID:           env.EnvChainID,
+		topicsJSON:        env.EnvTopics,
+		minReconnectDelay: minReconnectDelay,
+		startBlock:        env.EnvStartBlock,
+	}
+	a.cursor = a
+	return a
+}
+
+func (a *beaconAdapter) Start(ctx context.Context) error {
+	if err := a.setup(); err != nil {
+		return err
+	}
+	return a.hold(ctx, func(ctx context.Context) error {
+		if err := a.init(ctx); err != nil {
+			return err
+		}
+		return a.stream(ctx)
+	})
+}
+
+// setup checks the settings of the adapter, and parses the topics and the
+// filters they hold.
+func (a *beaconAdapter) setup() error {
+	configs, err := endpointConfigs(a.rpcURL, a.endpointsJSON)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(configs, func(i, j int) bool {
+		return configs[i].Priority < configs[j].Priority
+	})
+	a.nodes = make([]*beaconNode, 0, len(configs))
+	for _, c := range configs {
+		if isWebSocket(c.URL) {
+			return fmt.Errorf("endpoint %s: beacon nodes are read over HTTP", c.URL)
+		}
+		var opts []beacon.Option
+		if c.Credentials != "" {
+			opts = append(opts, beacon.WithHeader("Authorization", c.Credentials))
+		}
+		a.nodes = append(a.nodes, &beaconNode{endpoint: &endpoint{endpointConfig: c}, api: beacon.NewClient(c.URL, opts...)})
+	}
+
+	if a.topics, err = parseBeaconTopics(a.topicsJSON); err != nil {
+		return err
+	}
+
+	if err := a.setupFilters(); err != nil {
+		return err
+	}
+
+	if a.chainID != "" {
+		if _, err := strconv.ParseUint(a.chainID, 10, 64); err != nil {
+			return fmt.Errorf("invalid beacon chain ID %q, expected the decimal chain ID of the deposit contract", a.chainID)
+		}
+		a.observedChainID = a.chainID
+		a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilyBeacon, a.chainID)
+	}
+	return nil
+}
+
+// parseBeaconTopics parses the JSON encoded list of topics. All topics are
+// selected when there is none.
+func parseBeaconTopics(topicsJSON string) (map[string]bool, error) {
+	topics := make(map[string]bool, len(sourcesv1alpha1.BeaconTopics))
+	if topicsJSON == "" {
+		for _, t := range sourcesv1alpha1.BeaconTopics {
+			topics[string(t)] = true
+		}
+		return topics, nil
+	}
+
+	var raw []string
+	if err := json.Unmarshal([]byte(topicsJSON), &raw); err != nil {
+		return nil, fmt.Errorf("invalid beacon topics: %w", err)
+	}
+	for _, t := range raw {
+		if _, ok := beaconEventTypes[t]; !ok {
+			return nil, fmt.Errorf("unsupported beacon topic %q", t)
+		}
+		topics[t] = true
+	}
+	return topics, nil
+}
+
+// streamTopics returns the topics of the event stream: the selected ones,
+// and the head topic.
+func (a *beaconAdapter) streamTopics() []string {
+	topics := []string{beacon.TopicHead}
+	for _, t := range sourcesv1alpha1.BeaconTopics {
+		if a.topics[string(t)] && string(t) != beacon.TopicHead {
+			topics = append(topics, string(t))
+		}
+	}
+	return topics
+}
+
+// init resumes from the saved checkpoint. Without a checkpoint, events are
+// emitted from the start slot, or from the slot following the head once the
+// first node is reached.
+func (a *beaconAdapter) init(ctx context.Context) error {
+	resumed, err := a.resume(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resumed:
+		a.logger.Infof("Resuming from checkpoint at slot %d", a.next)
+	case a.startBlock != nil:
+		a.next, a.positioned = *a.startBlock, true
+		a.first = a.next
+		a.logger.Infof("Backfilling from slot %d", a.next)
+	}
+	return nil
+}
+
+// stream reads the event stream of the nodes until ctx is done, trying them
+// in priority order and starting over with an exponential backoff whenever
+// none could be read.
+func (a *beaconAdapter) stream(ctx context.Context) error {
+	defer a.saveCheckpoint(context.Background(), true)
+
+	delay := a.minReconnectDelay
+	for {
+		received, err := a.streamNodes(ctx)
+		if ctx.Err() != nil {
+			a.logger.Infof("Streaming stopped")
+			return nil
+		}
+		if a.reachedEnd() {
+			a.saveCheckpoint(ctx, true)
+			a.logger.Infof("Reached end slot %d, streaming stopped", *a.endBlock)
+			<-ctx.Done()
+			return nil
+		}
+		if received {
+			delay = a.minReconnectDelay
+		}
+		a.reportConnection(ctx, err)
+		a.logger.Errorf("Stream interrupted, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			a.logger.Infof("Streaming stopped")
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// streamNodes reads the event stream of the first node that can be read,
+// in priority order. It returns the error of the last attempt, and whether
+// the stream of any node was opened.
+func (a *beaconAdapter) streamNodes(ctx context.Context) (bool, error) {
+	var err error
+	for _, n := range a.nodes {
+		var opened bool
+		opened, err = a.streamOnce(ctx, n)
+		if opened || ctx.Err() != nil || a.reachedEnd() {
+			return opened, err
+		}
+		if err != nil {
+			a.logger.Warnf("Failed to read the events of %s: %v", n.name(), err)
+		}
+	}
+	return false, err
+}
+
+// streamOnce opens the event stream of a node, recovers the events missed
+// since the checkpoint and emits the streamed events until the stream
+// ends, the end slot is reached or ctx is done. It reports whether the
+// stream was opened, so that the caller can tell a flapping node from a
+// working one.
+func (a *beaconAdapter) streamOnce(ctx context.Context, n *beaconNode) (bool, error) {
+	if err := a.checkNode(ctx, n); err != nil {
+		return false, err
+	}
+
+	// The stream is opened before the missed events are recovered, so that
+	// the ones sent in between are not missed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := n.api.Events(ctx, a.streamTopics())
+	if err != nil {
+		return false, fmt.Errorf("failed to open the event stream of %s: %w", n.name(), err)
+	}
+	defer stream.Close()
+
+	events := make(chan *beacon.Event)
+	streamErr := make(chan error, 1)
+	go func() {
+		for {
+			event, err := stream.Next()
+			if err != nil {
+				streamErr <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := a.catchUp(ctx, n); err != nil {
+		return false, err
+	}
+	a.active = n.name()
+	a.reportConnection(ctx, nil)
+	a.logger.Infof("Streaming chain %s from %s", a.observedChainID, n.name())
+	if a.reachedEnd() {
+		return true, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case err := <-streamErr:
+			if errors.Is(err, io.EOF) {
+				return true, fmt.Errorf("%w: %s closed it", errStreamLost, n.name())
+			}
+			return true, fmt.Errorf("%w: reading from %s: %v", errStreamLost, n.name(), err)
+		case event := <-events:
+			if err := a.handleEvent(ctx, n, event); err != nil {
+				return true, err
+			}
+			if a.reachedEnd() {
+				return true, nil
+			}
+			a.saveCheckpoint(ctx, false)
+		}
+	}
+}
+
+// checkNode checks that a node serves the expected chain, learns the chain
+// when none is expected, and reads its parameters.
+func (a *beaconAdapter) checkNode(ctx context.Context, n *beaconNode) error {
+	deposit, err := n.api.DepositContract(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the deposit contract of %s: %w", n.name(), err)
+	}
+	chainID := strconv.FormatUint(deposit.ChainID, 10)
+	if a.observedChainID != "" && chainID != a.observedChainID {
+		return &chainMismatchError{endpoint: n.name(), got: chainID, want: a.observedChainID}
+	}
+
+	spec, err := n.api.Spec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the spec of %s: %w", n.name(), err)
+	}
+	genesis, err := n.api.Genesis(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the genesis of %s: %w", n.name(), err)
+	}
+
+	if a.observedChainID == "" {
+		a.observedChainID = chainID
+		a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilyBeacon, chainID)
+	}
+	a.spec, a.genesisTime = spec, genesis.GenesisTime
+	return nil
+}
+
+// currentCheckpoint returns the newest slot whose events have all been
+// emitted, along with the last finalized epoch, or nil if there is none
+// yet.
+func (a *beaconAdapter) currentCheckpoint() *checkpoint.Checkpoint {
+	if a.source == "" || !a.positioned || a.next == 0 {
+		return nil
+	}
+	cp := &checkpoint.Checkpoint{BlockNumber: a.next - 1}
+	if a.finalizedKnown {
+		epoch := a.finalized
+		cp.Epoch = &epoch
+	}
+	return cp
+}
+
+// seek moves the adapter past the slot of a checkpoint, and restores the
+// last finalized epoch.
+func (a *beaconAdapter) seek(cp *checkpoint.Checkpoint) {
+	a.next, a.positioned = cp.BlockNumber+1, true
+	a.first = a.next
+	if cp.Epoch != nil {
+		a.finalized, a.finalizedKnown = *cp.Epoch, true
+	}
+}
+
+// chainProgress reports how the adapter keeps up with the chain. The final
+// slot is the first one of the last finalized epoch.
+func (a *beaconAdapter) chainProgress() *checkpoint.Progress {
+	p := &checkpoint.Progress{
+		ChainID:  a.observedChainID,
+		Head:     a.head,
+		Endpoint: a.active,
+	}
+	if a.finalizedKnown && a.spec != nil {
+		p.Final = a.finalized * a.spec.SlotsPerEpoch
+	}
+	return p
+}
+
+// connectionFailure returns the report of a failure to read from a beacon
+// node, or nil if err is not one. Lost event streams are reported as
+// unreachable nodes.
+func (a *beaconAdapter) connectionFailure(err error) *checkpoint.Connection {
+	var (
+		delivery *deliveryError
+		httpErr  *beacon.HTTPError
+	)
+	switch {
+	case errors.As(err, &delivery):
+		return nil
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden):
+		return &checkpoint.Connection{Reason: checkpoint.ReasonUnauthorized, Message: err.Error()}
+	case errors.As(err, &httpErr), errors.Is(err, errStreamLost):
+		return &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable, Message: err.Error()}
+	}
+	return connectionFailure(nil, err)
+}
@@ -0,0 +1,454 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/beacon"
+)
+
+// beaconEventTypes are the CloudEvent types of the events emitted for the
+// topics of the event stream.
+var beaconEventTypes = map[string]string{
+	beacon.TopicHead:                sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindHead),
+	beacon.TopicBlock:               sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindBlock),
+	beacon.TopicFinalizedCheckpoint: sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindFinalizedCheckpoint),
+	beacon.TopicChainReorg:          sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindReorg),
+	beacon.TopicVoluntaryExit:       sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindVoluntaryExit),
+}
+
+// beaconHead is the data of head events. The duty dependent roots are only
+// known for the heads the node notifies, not for recovered ones.
+type beaconHead struct {
+	ChainID                   string `json:"chainID"`
+	Slot                      uint64 `json:"slot"`
+	Epoch                     uint64 `json:"epoch"`
+	Block                     string `json:"block"`
+	State                     string `json:"state"`
+	EpochTransition           bool   `json:"epochTransition"`
+	PreviousDutyDependentRoot string `json:"previousDutyDependentRoot,omitempty"`
+	CurrentDutyDependentRoot  string `json:"currentDutyDependentRoot,omitempty"`
+	ExecutionOptimistic       bool   `json:"executionOptimistic"`
+}
+
+// beaconBlock is the data of block events.
+type beaconBlock struct {
+	ChainID             string `json:"chainID"`
+	Slot                uint64 `json:"slot"`
+	Epoch               uint64 `json:"epoch"`
+	Block               string `json:"block"`
+	ExecutionOptimistic bool   `json:"executionOptimistic"`
+}
+
+// beaconFinalizedCheckpoint is the data of finalized checkpoint events. The
+// state is only known for the checkpoints the node notifies.
+type beaconFinalizedCheckpoint struct {
+	ChainID             string `json:"chainID"`
+	Epoch               uint64 `json:"epoch"`
+	Block               string `json:"block"`
+	State               string `json:"state,omitempty"`
+	ExecutionOptimistic bool   `json:"executionOptimistic"`
+}
+
+// beaconReorg is the data of reorg events.
+type beaconReorg struct {
+	ChainID             string `json:"chainID"`
+	Slot                uint64 `json:"slot"`
+	Epoch               uint64 `json:"epoch"`
+	Depth               uint64 `json:"depth"`
+	OldHeadBlock        string `json:"oldHeadBlock"`
+	NewHeadBlock        string `json:"newHeadBlock"`
+	OldHeadState        string `json:"oldHeadState"`
+	NewHeadState        string `json:"newHeadState"`
+	ExecutionOptimistic bool   `json:"executionOptimistic"`
+}
+
+// beaconVoluntaryExit is the data of voluntary exit events.
+type beaconVoluntaryExit struct {
+	ChainID        string `json:"chainID"`
+	Epoch          uint64 `json:"epoch"`
+	ValidatorIndex uint64 `json:"validatorIndex"`
+	Signature      string `json:"signature"`
+}
+
+// catchUp reads the head of a node, and emits the events of the slots and
+// the finalized checkpoint missed since the checkpoint. Without one, the
+// events are emitted from the slot following the head.
+func (a *beaconAdapter) catchUp(ctx context.Context, n *beaconNode) error {
+	head, err := n.api.Header(ctx, "head")
+	if err != nil {
+		return fmt.Errorf("failed to get the head of %s: %w", n.name(), err)
+	}
+	a.setHead(head.Header.Message.Slot)
+
+	if !a.positioned {
+		a.next, a.positioned = a.head+1, true
+		a.first = a.next
+		a.logger.Infof("Following chain %s from slot %d", a.observedChainID, a.next)
+	}
+	if err := a.recoverSlots(ctx, n, a.head); err != nil {
+		return err
+	}
+	return a.recoverFinality(ctx, n)
+}
+
+// recoverSlots emits the head and block events of the canonical blocks of
+// the slots from the next one up to slot, skipping the slots without block.
+func (a *beaconAdapter) recoverSlots(ctx context.Context, n *beaconNode, slot uint64) error {
+	var prev *uint64
+	for s := a.next; s <= slot && !a.pastEnd(s); s++ {
+		header, err := n.api.Header(ctx, strconv.FormatUint(s, 10))
+		switch {
+		case beacon.IsNotFound(err):
+			a.advance(s + 1)
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to get the block of slot %d from %s: %w", s, n.name(), err)
+		}
+
+		if prev == nil {
+			parent, err := a.parentSlot(ctx, n, header)
+			if err != nil {
+				return err
+			}
+			prev = &parent
+		}
+		if err := a.emitBlock(ctx, &beacon.BlockEvent{Slot: s, Block: header.Root}); err != nil {
+			return err
+		}
+		msg := header.Header.Message
+		if err := a.emitHead(ctx, &beacon.HeadEvent{
+			Slot:            s,
+			Block:           header.Root,
+			State:           msg.StateRoot,
+			EpochTransition: a.spec.Epoch(s) != a.spec.Epoch(*prev),
+		}); err != nil {
+			return err
+		}
+		*prev = s
+		a.advance(s + 1)
+		a.saveCheckpoint(ctx, false)
+	}
+	return nil
+}
+
+// parentSlot returns the slot of the parent of a block.
+func (a *beaconAdapter) parentSlot(ctx context.Context, n *beaconNode, header *beacon.BlockHeader) (uint64, error) {
+	if header.Header.Message.Slot == 0 {
+		return 0, nil
+	}
+	parent, err := n.api.Header(ctx, header.Header.Message.ParentRoot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the parent of block %s from %s: %w", header.Root, n.name(), err)
+	}
+	return parent.Header.Message.Slot, nil
+}
+
+// recoverFinality emits the finalized checkpoint of the head state if it is
+// newer than the last one. Only the last missed checkpoint is emitted.
+func (a *beaconAdapter) recoverFinality(ctx context.Context, n *beaconNode) error {
+	checkpoints, err := n.api.FinalityCheckpoints(ctx, "head")
+	if err != nil {
+		return fmt.Errorf("failed to get the finality checkpoints of %s: %w", n.name(), err)
+	}
+	return a.emitFinalizedCheckpoint(ctx, &beacon.FinalizedCheckpointEvent{
+		Block: checkpoints.Finalized.Root,
+		Epoch: checkpoints.Finalized.Epoch,
+	})
+}
+
+// handleEvent emits the event of the stream of a node.
+func (a *beaconAdapter) handleEvent(ctx context.Context, n *beaconNode, event *beacon.Event) error {
+	switch event.Topic {
+	case beacon.TopicHead:
+		var head beacon.HeadEvent
+		if err := json.Unmarshal(event.Data, &head); err != nil {
+			return fmt.Errorf("invalid head event: %w", err)
+		}
+		a.setHead(head.Slot)
+		// Heads are notified for every block, a gap is a slot whose block
+		// came late or a reorg onto a later slot.
+		if head.Slot > a.next {
+			if err := a.recoverSlots(ctx, n, head.Slot-1); err != nil {
+				return err
+			}
+		}
+		if err := a.emitHead(ctx, &head); err != nil {
+			return err
+		}
+		a.advance(head.Slot + 1)
+	case beacon.TopicBlock:
+		var block beacon.BlockEvent
+		if err := json.Unmarshal(event.Data, &block); err != nil {
+			return fmt.Errorf("invalid block event: %w", err)
+		}
+		return a.emitBlock(ctx, &block)
+	case beacon.TopicFinalizedCheckpoint:
+		var finalized beacon.FinalizedCheckpointEvent
+		if err := json.Unmarshal(event.Data, &finalized); err != nil {
+			return fmt.Errorf("invalid finalized checkpoint event: %w", err)
+		}
+		return a.emitFinalizedCheckpoint(ctx, &finalized)
+	case beacon.TopicChainReorg:
+		var reorg beacon.ChainReorgEvent
+		if err := json.Unmarshal(event.Data, &reorg); err != nil {
+			return fmt.Errorf("invalid chain reorg event: %w", err)
+		}
+		return a.emitReorg(ctx, &reorg)
+	case beacon.TopicVoluntaryExit:
+		var exit beacon.SignedVoluntaryExit
+		if err := json.Unmarshal(event.Data, &exit); err != nil {
+			return fmt.Errorf("invalid voluntary exit event: %w", err)
+		}
+		return a.emitVoluntaryExit(ctx, &exit)
+	default:
+		a.logger.Debugf("Ignoring event of topic %q", event.Topic)
+	}
+	return nil
+}
+
+// setHead records the slot of a head, if newer than the known one.
+func (a *beaconAdapter) setHead(slot uint64) {
+	if slot < a.head {
+		return
+	}
+	a.head = slot
+	a.blockTime = a.spec.SlotTime(a.genesisTime, slot)
+}
+
+// advance moves the next slot to emit the head of, no further than the one
+// following the end slot, and forgets the blocks of the slots older than
+// an epoch, which the node no longer notifies.
+func (a *beaconAdapter) advance(slot uint64) {
+	if a.endBlock != nil && slot > *a.endBlock+1 {
+		slot = *a.endBlock + 1
+	}
+	if slot <= a.next {
+		return
+	}
+	a.next = slot
+	for s := range a.recent {
+		if s+a.spec.SlotsPerEpoch < a.next {
+			delete(a.recent, s)
+		}
+	}
+}
+
+// inRange reports whether the events of a slot are emitted.
+func (a *beaconAdapter) inRange(slot uint64) bool {
+	return slot >= a.first && !a.pastEnd(slot)
+}
+
+// emitHead emits a head, unless its topic is not selected or it was
+// already emitted.
+func (a *beaconAdapter) emitHead(ctx context.Context, head *beacon.HeadEvent) error {
+	key := beacon.TopicHead + "/" + head.Block
+	if !a.topics[beacon.TopicHead] || !a.inRange(head.Slot) || a.emittedIn(head.Slot)[key] {
+		return nil
+	}
+
+	slot, epoch := head.Slot, a.spec.Epoch(head.Slot)
+	event := a.newEvent(beacon.TopicHead, head.Block+"-head", strconv.FormatUint(slot, 10), sourcesv1alpha1.FinalityLevelLatest)
+	event.SetTime(a.spec.SlotTime(a.genesisTime, slot))
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &slot,
+		blockHash:   head.Block,
+		epoch:       &epoch,
+	}
+	data := &beaconHead{
+		ChainID:                   a.observedChainID,
+		Slot:                      slot,
+		Epoch:                     epoch,
+		Block:                     head.Block,
+		State:                     head.State,
+		EpochTransition:           head.EpochTransition,
+		PreviousDutyDependentRoot: head.PreviousDutyDependentRoot,
+		CurrentDutyDependentRoot:  head.CurrentDutyDependentRoot,
+		ExecutionOptimistic:       head.ExecutionOptimistic,
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit head %s: %w", head.Block, err)
+	}
+	a.emittedIn(slot)[key] = true
+	return nil
+}
+
+// emitBlock emits a block, unless its topic is not selected or it was
+// already emitted.
+func (a *beaconAdapter) emitBlock(ctx context.Context, block *beacon.BlockEvent) error {
+	key := beacon.TopicBlock + "/" + block.Block
+	if !a.topics[beacon.TopicBlock] || !a.inRange(block.Slot) || a.emittedIn(block.Slot)[key] {
+		return nil
+	}
+
+	slot, epoch := block.Slot, a.spec.Epoch(block.Slot)
+	event := a.newEvent(beacon.TopicBlock, block.Block, strconv.FormatUint(slot, 10), sourcesv1alpha1.FinalityLevelLatest)
+	event.SetTime(a.spec.SlotTime(a.genesisTime, slot))
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &slot,
+		blockHash:   block.Block,
+		epoch:       &epoch,
+	}
+	data := &beaconBlock{
+		ChainID:             a.observedChainID,
+		Slot:                slot,
+		Epoch:               epoch,
+		Block:               block.Block,
+		ExecutionOptimistic: block.ExecutionOptimistic,
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit block %s: %w", block.Block, err)
+	}
+	a.emittedIn(slot)[key] = true
+	return nil
+}
+
+// emitFinalizedCheckpoint emits a finalized checkpoint newer than the last
+// one, unless its topic is not selected. The first checkpoint known
+// without a saved one is only recorded, as it was finalized before the
+// adapter started.
+func (a *beaconAdapter) emitFinalizedCheckpoint(ctx context.Context, finalized *beacon.FinalizedCheckpointEvent) error {
+	if a.finalizedKnown && finalized.Epoch <= a.finalized {
+		return nil
+	}
+	if !a.finalizedKnown {
+		a.finalized, a.finalizedKnown = finalized.Epoch, true
+		return nil
+	}
+
+	if a.topics[beacon.TopicFinalizedCheckpoint] {
+		epoch := finalized.Epoch
+		slot := epoch * a.spec.SlotsPerEpoch
+		event := a.newEvent(beacon.TopicFinalizedCheckpoint, finalized.Block+"-finalized", strconv.FormatUint(epoch, 10), sourcesv1alpha1.FinalityLevelFinalized)
+		event.SetTime(a.spec.SlotTime(a.genesisTime, slot))
+		ext := chainExtensions{
+			chainID:   a.observedChainID,
+			blockHash: finalized.Block,
+			epoch:     &epoch,
+		}
+		data := &beaconFinalizedCheckpoint{
+			ChainID:             a.observedChainID,
+			Epoch:               epoch,
+			Block:               finalized.Block,
+			State:               finalized.State,
+			ExecutionOptimistic: finalized.ExecutionOptimistic,
+		}
+		if err := a.deliver(ctx, event, ext, data); err != nil {
+			return fmt.Errorf("failed to emit the finalized checkpoint of epoch %d: %w", epoch, err)
+		}
+	}
+	a.finalized = finalized.Epoch
+	return nil
+}
+
+// emitReorg emits a reorg of the head, unless its topic is not selected or
+// its slot is out of range.
+func (a *beaconAdapter) emitReorg(ctx context.Context, reorg *beacon.ChainReorgEvent) error {
+	if !a.topics[beacon.TopicChainReorg] || !a.inRange(reorg.Slot) {
+		return nil
+	}
+
+	slot, epoch := reorg.Slot, reorg.Epoch
+	event := a.newEvent(beacon.TopicChainReorg, reorg.NewHeadBlock+"-reorg", strconv.FormatUint(slot, 10), sourcesv1alpha1.FinalityLevelLatest)
+	event.SetTime(a.spec.SlotTime(a.genesisTime, slot))
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &slot,
+		blockHash:   reorg.NewHeadBlock,
+		epoch:       &epoch,
+	}
+	data := &beaconReorg{
+		ChainID:             a.observedChainID,
+		Slot:                slot,
+		Epoch:               epoch,
+		Depth:               reorg.Depth,
+		OldHeadBlock:        reorg.OldHeadBlock,
+		NewHeadBlock:        reorg.NewHeadBlock,
+		OldHeadState:        reorg.OldHeadState,
+		NewHeadState:        reorg.NewHeadState,
+		ExecutionOptimistic: reorg.ExecutionOptimistic,
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit the reorg to %s: %w", reorg.NewHeadBlock, err)
+	}
+	return nil
+}
+
+// emitVoluntaryExit emits a voluntary exit, unless its topic is not
+// selected. Exits are identified by their signature, as a validator may
+// only exit once.
+func (a *beaconAdapter) emitVoluntaryExit(ctx context.Context, exit *beacon.SignedVoluntaryExit) error {
+	if !a.topics[beacon.TopicVoluntaryExit] {
+		return nil
+	}
+
+	epoch := exit.Message.Epoch
+	event := a.newEvent(beacon.TopicVoluntaryExit, exit.Signature, strconv.FormatUint(exit.Message.ValidatorIndex, 10), "")
+	event.SetTime(time.Now())
+	ext := chainExtensions{
+		chainID: a.observedChainID,
+		epoch:   &epoch,
+	}
+	data := &beaconVoluntaryExit{
+		ChainID:        a.observedChainID,
+		Epoch:          epoch,
+		ValidatorIndex: exit.Message.ValidatorIndex,
+		Signature:      exit.Signature,
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit the exit of validator %d: %w", exit.Message.ValidatorIndex, err)
+	}
+	return nil
+}
+
+// newEvent returns an event of a topic, at the given finality level if
+// any.
+func (a *beaconAdapter) newEvent(topic, id, subject string, level sourcesv1alpha1.FinalityLevel) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetType(beaconEventTypes[topic])
+	event.SetSource(a.source)
+	event.SetSubject(subject)
+	if level != "" {
+		event.SetExtension(finalityExtension, string(level))
+	}
+	return event
+}
+
+// emittedIn returns the keys of the events emitted for the blocks of a
+// slot.
+func (a *beaconAdapter) emittedIn(slot uint64) map[string]bool {
+	if a.recent == nil {
+		a.recent = make(map[uint64]map[string]bool)
+	}
+	emitted, ok := a.recent[slot]
+	if !ok {
+		emitted = make(map[string]bool)
+		a.recent[slot] = emitted
+	}
+	return emitted
+}
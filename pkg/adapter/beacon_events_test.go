@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	"knative.dev/eventing-blockchain/pkg/beacon"
+)
+
+func TestBeaconAdapterRecoversMissedSlots(t *testing.T) {
+	node := newFakeBeacon(1, 10)
+	rpcURL := serveFakeBeacon(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBeaconAdapter(t, ce, rpcURL)
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "finality_checkpoints", 1)
+		node.produce()
+		waitForEvents(t, ce, 2)
+
+		// The blocks and the checkpoint finalized while the stream is
+		// down are recovered once reconnected.
+		node.disconnect()
+		node.produce()
+		node.skip()
+		node.produce()
+		node.finalize(2)
+		node.reconnect()
+		waitForEvents(t, ce, 7)
+	})
+
+	want := []string{"11", "11", "12", "12", "14", "14", "2"}
+	if diff := cmp.Diff(want, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+
+	var head beaconHead
+	if err := json.Unmarshal(ce.Sent()[3].Data(), &head); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantHead := beaconHead{
+		ChainID:         "1",
+		Slot:            12,
+		Epoch:           3,
+		Block:           testBeaconRoot(12),
+		State:           testBeaconStateRoot(12),
+		EpochTransition: true,
+	}
+	if diff := cmp.Diff(wantHead, head); diff != "" {
+		t.Errorf("unexpected recovered head (-want, +got) = %v", diff)
+	}
+
+	var finalized beaconFinalizedCheckpoint
+	if err := json.Unmarshal(ce.Sent()[6].Data(), &finalized); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	// The state of recovered checkpoints is unknown.
+	if diff := cmp.Diff(beaconFinalizedCheckpoint{ChainID: "1", Epoch: 2, Block: testBeaconRoot(8)}, finalized); diff != "" {
+		t.Errorf("unexpected recovered checkpoint (-want, +got) = %v", diff)
+	}
+}
+
+func TestBeaconAdapterEmitsEventsOnce(t *testing.T) {
+	node := newFakeBeacon(1, 10)
+	rpcURL := serveFakeBeacon(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBeaconAdapter(t, ce, rpcURL)
+	a.topicsJSON = `["block", "finalized_checkpoint"]`
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "finality_checkpoints", 1)
+		node.produce()
+		// Notifying a block again, or an older finalized checkpoint, is
+		// not emitted again.
+		node.push(beacon.TopicBlock, beacon.BlockEvent{Slot: 11, Block: testBeaconRoot(11)})
+		node.finalize(1)
+		// The block of slot 12 is not notified.
+		node.mu.Lock()
+		node.propose(12)
+		node.mu.Unlock()
+		node.produce()
+		node.finalize(3)
+		waitForEvents(t, ce, 4)
+	})
+
+	// The block of slot 12 is recovered once the head of slot 13 is
+	// notified, after its block.
+	want := []string{"11", "13", "12", "3"}
+	if diff := cmp.Diff(want, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestBeaconAdapterSelectsTopics(t *testing.T) {
+	node := newFakeBeacon(1, 10)
+	rpcURL := serveFakeBeacon(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBeaconAdapter(t, ce, rpcURL)
+	a.topicsJSON = `["voluntary_exit"]`
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "finality_checkpoints", 1)
+		// The head topic is always streamed, to follow the slots.
+		if diff := cmp.Diff([][]string{{"head", "voluntary_exit"}}, node.streamTopics()); diff != "" {
+			t.Errorf("unexpected stream topics (-want, +got) = %v", diff)
+		}
+		node.produce()
+		node.finalize(2)
+		node.push(beacon.TopicVoluntaryExit, beacon.SignedVoluntaryExit{
+			Message:   beacon.VoluntaryExit{Epoch: 3, ValidatorIndex: 7},
+			Signature: "0xe417",
+		})
+		waitForEvents(t, ce, 1)
+	})
+
+	if diff := cmp.Diff([]string{"7"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+	if a.next != 12 {
+		t.Errorf("next slot = %d, want 12", a.next)
+	}
+}
@@ -0,0 +1,568 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/pkg/logging"
+	pkgtesting "knative.dev/pkg/reconciler/testing"
+
+	"knative.dev/eventing-blockchain/pkg/beacon"
+)
+
+const (
+	// testBeaconGenesisTime is the genesis time of mainnet.
+	testBeaconGenesisTime = 1606824023
+	// testBeaconSlotsPerEpoch is shorter than on mainnet, so that tests
+	// cross epochs in a few slots.
+	testBeaconSlotsPerEpoch = 4
+	// testHoleskyChainID is the chain ID of the Holesky testnet.
+	testHoleskyChainID = 17000
+)
+
+// fakeBeacon is an in-memory beacon node serving the Beacon API. Blocks are
+// proposed at every slot, unless skipped.
+type fakeBeacon struct {
+	mu      sync.Mutex
+	chainID uint64
+	slot    uint64
+	// blocks are the canonical blocks by slot.
+	blocks    map[uint64]*beacon.BlockHeader
+	head      *beacon.BlockHeader
+	finalized beacon.Checkpoint
+	// streams are the topics of the open event streams.
+	streams map[chan *beacon.Event][]string
+	// down makes event streams fail to open.
+	down bool
+
+	// callCounter counts the requests received per endpoint, named after
+	// the last element of their path.
+	callCounter
+}
+
+// newFakeBeacon returns a node whose head is a block at slot, with the
+// blocks of all the previous slots.
+func newFakeBeacon(chainID uint64, slot uint64) *fakeBeacon {
+	n := &fakeBeacon{
+		chainID: chainID,
+		blocks:  make(map[uint64]*beacon.BlockHeader),
+		streams: make(map[chan *beacon.Event][]string),
+	}
+	n.propose(0)
+	for s := uint64(1); s <= slot; s++ {
+		n.propose(s)
+	}
+	n.finalized = beacon.Checkpoint{Epoch: n.epoch(slot) - 1, Root: testBeaconRoot(0)}
+	return n
+}
+
+// testBeaconRoot returns the root of the block of a slot.
+func testBeaconRoot(slot uint64) string {
+	return fmt.Sprintf("0x%064x", slot+1)
+}
+
+// testBeaconStateRoot returns the root of the state after the block of a
+// slot.
+func testBeaconStateRoot(slot uint64) string {
+	return fmt.Sprintf("0x%064x", slot+1<<32)
+}
+
+func (n *fakeBeacon) epoch(slot uint64) uint64 {
+	return slot / testBeaconSlotsPerEpoch
+}
+
+func (n *fakeBeacon) propose(slot uint64) *beacon.BlockHeader {
+	header := &beacon.BlockHeader{
+		Root:      testBeaconRoot(slot),
+		Canonical: true,
+		Header: beacon.SignedBeaconBlockHeader{
+			Message: beacon.BeaconBlockHeader{
+				Slot:      slot,
+				StateRoot: testBeaconStateRoot(slot),
+			},
+		},
+	}
+	if n.head != nil {
+		header.Header.Message.ParentRoot = n.head.Root
+	}
+	n.slot, n.head = slot, header
+	n.blocks[slot] = header
+	return header
+}
+
+// produce moves to the next slot and proposes its block, and notifies the
+// streams of the block and of the new head.
+func (n *fakeBeacon) produce() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	parent := n.head.Header.Message.Slot
+	header := n.propose(n.slot + 1)
+	slot := header.Header.Message.Slot
+	n.notify(beacon.TopicBlock, beacon.BlockEvent{Slot: slot, Block: header.Root})
+	n.notify(beacon.TopicHead, beacon.HeadEvent{
+		Slot:            slot,
+		Block:           header.Root,
+		State:           header.Header.Message.StateRoot,
+		EpochTransition: n.epoch(slot) != n.epoch(parent),
+	})
+}
+
+// skip moves to the next slot without block.
+func (n *fakeBeacon) skip() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.slot++
+}
+
+// finalize finalizes an epoch, and notifies the streams of its checkpoint.
+func (n *fakeBeacon) finalize(epoch uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	root := testBeaconRoot(epoch * testBeaconSlotsPerEpoch)
+	n.finalized = beacon.Checkpoint{Epoch: epoch, Root: root}
+	n.notify(beacon.TopicFinalizedCheckpoint, beacon.FinalizedCheckpointEvent{
+		Block: root,
+		State: testBeaconStateRoot(epoch * testBeaconSlotsPerEpoch),
+		Epoch: epoch,
+	})
+}
+
+// notify sends an event to the streams of its topic.
+func (n *fakeBeacon) notify(topic string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+	for ch, topics := range n.streams {
+		for _, t := range topics {
+			if t == topic {
+				ch <- &beacon.Event{Topic: topic, Data: raw}
+			}
+		}
+	}
+}
+
+// push sends an event to the streams of its topic.
+func (n *fakeBeacon) push(topic string, data interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notify(topic, data)
+}
+
+// disconnect closes all event streams, and fails to open new ones until
+// reconnect is called.
+func (n *fakeBeacon) disconnect() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.down = true
+	for ch := range n.streams {
+		close(ch)
+		delete(n.streams, ch)
+	}
+}
+
+func (n *fakeBeacon) reconnect() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.down = false
+}
+
+func (n *fakeBeacon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/eth/v1/")
+	elems := strings.Split(path, "/")
+
+	n.mu.Lock()
+	n.called(elems[len(elems)-1])
+	if path == "events" {
+		n.serveEvents(w, r)
+		return
+	}
+	defer n.mu.Unlock()
+
+	var data interface{}
+	switch {
+	case path == "config/deposit_contract":
+		data = beacon.DepositContract{ChainID: n.chainID, Address: "0x00000000219ab540356cBB839Cbe05303d7705Fa"}
+	case path == "config/spec":
+		data = beacon.Spec{SecondsPerSlot: 12, SlotsPerEpoch: testBeaconSlotsPerEpoch}
+	case path == "beacon/genesis":
+		data = beacon.Genesis{GenesisTime: testBeaconGenesisTime}
+	case path == "beacon/states/head/finality_checkpoints":
+		data = beacon.FinalityCheckpoints{Finalized: n.finalized}
+	case strings.HasPrefix(path, "beacon/headers/"):
+		if header := n.header(elems[len(elems)-1]); header != nil {
+			data = header
+		}
+	}
+	if data == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"code":404,"message":"%s not found"}`, path)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// header returns the header of a block given by its slot or root, or of
+// the head.
+func (n *fakeBeacon) header(id string) *beacon.BlockHeader {
+	if id == "head" {
+		return n.head
+	}
+	if slot, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return n.blocks[slot]
+	}
+	for _, header := range n.blocks {
+		if header.Root == id {
+			return header
+		}
+	}
+	return nil
+}
+
+// serveEvents streams the events of the requested topics as server-sent
+// events. It is called with the lock held, released once the stream is
+// registered.
+func (n *fakeBeacon) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if n.down {
+		n.mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"code":503,"message":"node is syncing"}`)
+		return
+	}
+	// Events are buffered, so that they are notified while the adapter
+	// catches up.
+	ch := make(chan *beacon.Event, 100)
+	n.streams[ch] = strings.Split(r.URL.Query().Get("topics"), ",")
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		if _, ok := n.streams[ch]; ok {
+			delete(n.streams, ch)
+		}
+		n.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	w.(http.Flusher).Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Topic, event.Data)
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+// streamTopics returns the topics of the open event streams.
+func (n *fakeBeacon) streamTopics() [][]string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var topics [][]string
+	for _, t := range n.streams {
+		topics = append(topics, t)
+	}
+	return topics
+}
+
+// serveFakeBeacon serves a node, and returns the base URL of its Beacon
+// API.
+func serveFakeBeacon(t *testing.T, n *fakeBeacon) string {
+	t.Helper()
+	server := httptest.NewServer(n)
+	t.Cleanup(func() {
+		// Open event streams would keep Close from returning.
+		n.disconnect()
+		server.Close()
+	})
+	return server.URL
+}
+
+func newTestBeaconAdapter(t *testing.T, ce *adaptertest.TestCloudEventsClient, rpcURL string) *beaconAdapter {
+	env := beaconEnvConfig{
+		chainEnvConfig: chainEnvConfig{
+			EnvConfig: adapter.EnvConfig{
+				Namespace: "default",
+			},
+			EnvRPCURL: rpcURL,
+		},
+	}
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+	logger := zap.NewExample().Sugar()
+	ctx = logging.WithLogger(ctx, logger)
+
+	a := NewBeaconAdapter(ctx, &env, ce).(*beaconAdapter)
+	a.minReconnectDelay = 10 * time.Millisecond
+	return a
+}
+
+func TestBeaconAdapterEmitsEvents(t *testing.T) {
+	node := newFakeBeacon(1, 10)
+	rpcURL := serveFakeBeacon(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBeaconAdapter(t, ce, rpcURL)
+
+	exit := beacon.SignedVoluntaryExit{
+		Message:   beacon.VoluntaryExit{Epoch: 3, ValidatorIndex: 42},
+		Signature: "0xe417",
+	}
+	reorg := beacon.ChainReorgEvent{
+		Slot:         12,
+		Depth:        1,
+		OldHeadBlock: testBeaconRoot(12),
+		NewHeadBlock: "0x12b",
+		OldHeadState: testBeaconStateRoot(12),
+		NewHeadState: "0x12c",
+		Epoch:        3,
+	}
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "finality_checkpoints", 1)
+		// Blocks proposed before the adapter started are not emitted.
+		node.produce()
+		node.produce()
+		node.finalize(2)
+		node.push(beacon.TopicChainReorg, reorg)
+		node.push(beacon.TopicVoluntaryExit, exit)
+		waitForEvents(t, ce, 7)
+	})
+
+	slot11, slot12, epoch2, epoch3 := uint64(11), uint64(12), uint64(2), uint64(3)
+	sent := ce.Sent()
+	for i, want := range []struct {
+		eventType  string
+		id         string
+		subject    string
+		extensions map[string]interface{}
+	}{{
+		eventType: beaconEventTypes[beacon.TopicBlock],
+		id:        testBeaconRoot(11),
+		subject:   "11",
+		extensions: map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     "1",
+			blockNumberExtension: int32(slot11),
+			blockHashExtension:   testBeaconRoot(11),
+			epochExtension:       int32(epoch2),
+		},
+	}, {
+		eventType: beaconEventTypes[beacon.TopicHead],
+		id:        testBeaconRoot(11) + "-head",
+		subject:   "11",
+		extensions: map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     "1",
+			blockNumberExtension: int32(slot11),
+			blockHashExtension:   testBeaconRoot(11),
+			epochExtension:       int32(epoch2),
+		},
+	}, {
+		eventType: beaconEventTypes[beacon.TopicBlock],
+		id:        testBeaconRoot(12),
+		subject:   "12",
+		extensions: map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     "1",
+			blockNumberExtension: int32(slot12),
+			blockHashExtension:   testBeaconRoot(12),
+			epochExtension:       int32(epoch3),
+		},
+	}, {
+		eventType: beaconEventTypes[beacon.TopicHead],
+		id:        testBeaconRoot(12) + "-head",
+		subject:   "12",
+		extensions: map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     "1",
+			blockNumberExtension: int32(slot12),
+			blockHashExtension:   testBeaconRoot(12),
+			epochExtension:       int32(epoch3),
+		},
+	}, {
+		eventType: beaconEventTypes[beacon.TopicFinalizedCheckpoint],
+		id:        testBeaconRoot(8) + "-finalized",
+		subject:   "2",
+		extensions: map[string]interface{}{
+			finalityExtension:  "finalized",
+			chainIDExtension:   "1",
+			blockHashExtension: testBeaconRoot(8),
+			epochExtension:     int32(epoch2),
+		},
+	}, {
+		eventType: beaconEventTypes[beacon.TopicChainReorg],
+		id:        "0x12b-reorg",
+		subject:   "12",
+		extensions: map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     "1",
+			blockNumberExtension: int32(slot12),
+			blockHashExtension:   "0x12b",
+			epochExtension:       int32(epoch3),
+		},
+	}, {
+		eventType: beaconEventTypes[beacon.TopicVoluntaryExit],
+		id:        "0xe417",
+		subject:   "42",
+		extensions: map[string]interface{}{
+			chainIDExtension: "1",
+			epochExtension:   int32(epoch3),
+		},
+	}} {
+		e := sent[i]
+		if e.Type() != want.eventType || e.ID() != want.id {
+			t.Errorf("event %d = %s %s, want %s %s", i, e.Type(), e.ID(), want.eventType, want.id)
+		}
+		if e.Source() != "beacon:1" || e.Subject() != want.subject {
+			t.Errorf("event %d source and subject = %s %s, want beacon:1 %s", i, e.Source(), e.Subject(), want.subject)
+		}
+		if diff := cmp.Diff(want.extensions, e.Extensions()); diff != "" {
+			t.Errorf("unexpected extensions of event %d (-want, +got) = %v", i, diff)
+		}
+	}
+
+	slotTime := time.Unix(testBeaconGenesisTime+12*12, 0).UTC()
+	if !sent[3].Time().Equal(slotTime) {
+		t.Errorf("head time = %s, want %s", sent[3].Time(), slotTime)
+	}
+	var head beaconHead
+	if err := json.Unmarshal(sent[3].Data(), &head); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantHead := beaconHead{
+		ChainID:         "1",
+		Slot:            12,
+		Epoch:           3,
+		Block:           testBeaconRoot(12),
+		State:           testBeaconStateRoot(12),
+		EpochTransition: true,
+	}
+	if diff := cmp.Diff(wantHead, head); diff != "" {
+		t.Errorf("unexpected head (-want, +got) = %v", diff)
+	}
+
+	var finalized beaconFinalizedCheckpoint
+	if err := json.Unmarshal(sent[4].Data(), &finalized); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantFinalized := beaconFinalizedCheckpoint{
+		ChainID: "1",
+		Epoch:   2,
+		Block:   testBeaconRoot(8),
+		State:   testBeaconStateRoot(8),
+	}
+	if diff := cmp.Diff(wantFinalized, finalized); diff != "" {
+		t.Errorf("unexpected finalized checkpoint (-want, +got) = %v", diff)
+	}
+
+	var gotReorg beaconReorg
+	if err := json.Unmarshal(sent[5].Data(), &gotReorg); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantReorg := beaconReorg{
+		ChainID:      "1",
+		Slot:         12,
+		Epoch:        3,
+		Depth:        1,
+		OldHeadBlock: testBeaconRoot(12),
+		NewHeadBlock: "0x12b",
+		OldHeadState: testBeaconStateRoot(12),
+		NewHeadState: "0x12c",
+	}
+	if diff := cmp.Diff(wantReorg, gotReorg); diff != "" {
+		t.Errorf("unexpected reorg (-want, +got) = %v", diff)
+	}
+
+	var gotExit beaconVoluntaryExit
+	if err := json.Unmarshal(sent[6].Data(), &gotExit); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	if diff := cmp.Diff(beaconVoluntaryExit{ChainID: "1", Epoch: 3, ValidatorIndex: 42, Signature: "0xe417"}, gotExit); diff != "" {
+		t.Errorf("unexpected voluntary exit (-want, +got) = %v", diff)
+	}
+}
+
+func TestBeaconAdapterBackfillsFromStartSlot(t *testing.T) {
+	node := newFakeBeacon(1, 5)
+	node.skip()
+	node.produce()
+	node.produce()
+	rpcURL := serveFakeBeacon(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBeaconAdapter(t, ce, rpcURL)
+	a.topicsJSON = `["block"]`
+	start, end := uint64(4), uint64(7)
+	a.startBlock, a.endBlock = &start, &end
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "finality_checkpoints", 1)
+		waitForEvents(t, ce, 3)
+	})
+
+	if !a.reachedEnd() {
+		t.Error("reachedEnd() = false, want true past the end slot")
+	}
+
+	// Slot 6 has no block, and slot 8 is past the end.
+	if diff := cmp.Diff([]string{"4", "5", "7"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestBeaconAdapterFailsOver(t *testing.T) {
+	node := newFakeBeacon(1, 10)
+	rpcURL := serveFakeBeacon(t, node)
+	otherURL := serveFakeBeacon(t, newFakeBeacon(testHoleskyChainID, 10))
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBeaconAdapter(t, ce, "")
+	a.chainID = "1"
+	a.endpointsJSON = fmt.Sprintf(`[{"url": %q}, {"url": %q, "priority": 1}]`, otherURL, rpcURL)
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "finality_checkpoints", 1)
+		node.produce()
+		waitForEvents(t, ce, 2)
+	})
+
+	if a.active != rpcURL {
+		t.Errorf("active node = %q, want %q", a.active, rpcURL)
+	}
+	if got := ce.Sent()[0].Source(); got != "beacon:1" {
+		t.Errorf("event source = %s, want beacon:1", got)
+	}
+}
@@ -0,0 +1,546 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"knative.dev/eventing/pkg/adapter/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+var (
+	// bitcoinBlockEventType is the CloudEvent type of the events emitted
+	// for every new block.
+	bitcoinBlockEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindBlock)
+
+	// bitcoinTransactionEventType is the CloudEvent type of the events
+	// emitted for every transaction entering the mempool.
+	bitcoinTransactionEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindTransaction)
+)
+
+// Codes of the errors returned by bitcoind.
+const (
+	// bitcoinErrInvalidAddressOrKey is returned for unknown blocks and
+	// transactions.
+	bitcoinErrInvalidAddressOrKey = -5
+	// bitcoinErrInvalidParameter is returned for heights past the head.
+	bitcoinErrInvalidParameter = -8
+)
+
+type bitcoinEnvConfig struct {
+	chainEnvConfig
+
+	// Environment variable containing how often the health of the endpoints
+	// is checked, when there are several
+	EnvHealthCheckInterval time.Duration `envconfig:"BLOCKCHAIN_HEALTH_CHECK_INTERVAL" default:"30s"`
+	// Environment variable containing the number of blocks an endpoint may
+	// lag behind the most advanced one before being considered unhealthy
+	EnvMaxHeadLag uint64 `envconfig:"BLOCKCHAIN_MAX_HEAD_LAG" default:"1"`
+	// Environment variable containing the share of failed requests above
+	// which an endpoint is considered unhealthy
+	EnvMaxErrorRate float64 `envconfig:"BLOCKCHAIN_MAX_ERROR_RATE" default:"0.5"`
+	// Environment variable containing the ingestion mode, polling or
+	// streaming from the ZMQ notifications of bitcoind
+	EnvMode string `envconfig:"BLOCKCHAIN_MODE" default:"polling"`
+	// Environment variable containing the ZMQ endpoint bitcoind publishes
+	// its hashblock and rawtx notifications on, when streaming
+	EnvZMQURL string `envconfig:"BLOCKCHAIN_ZMQ_URL"`
+	// Environment variable containing how often the node is polled for new
+	// blocks and transactions
+	EnvPollInterval time.Duration `envconfig:"BLOCKCHAIN_POLL_INTERVAL" default:"30s"`
+	// Environment variable containing how many recent blocks are tracked to
+	// retract their events should they be orphaned by a reorg
+	EnvReorgWindow uint64 `envconfig:"BLOCKCHAIN_REORG_WINDOW" default:"16"`
+	// Environment variable containing the finality level blocks must reach
+	// before being emitted, latest or confirmed
+	EnvFinality string `envconfig:"BLOCKCHAIN_FINALITY" default:"latest"`
+	// Environment variable containing the number of confirmations of the
+	// confirmed finality level
+	EnvConfirmations uint64 `envconfig:"BLOCKCHAIN_CONFIRMATIONS" default:"6"`
+}
+
+// NewBitcoinEnvConfig function reads env variables defined in
+// bitcoinEnvConfig structure and returns accessor interface
+func NewBitcoinEnvConfig() adapter.EnvConfigAccessor {
+	return &bitcoinEnvConfig{}
+}
+
+// bitcoinProbe probes bitcoind nodes.
+type bitcoinProbe struct{}
+
+func (bitcoinProbe) chainID(ctx context.Context, rpc rpcCaller) (string, error) {
+	info, err := blockchainInfo(ctx, rpc)
+	if err != nil {
+		return "", err
+	}
+	return info.Chain, nil
+}
+
+func (bitcoinProbe) head(ctx context.Context, rpc rpcCaller) (uint64, error) {
+	return blockCount(ctx, rpc)
+}
+
+// bitcoinAdapter reads the blocks and the mempool of Bitcoin Core through
+// its JSON-RPC interface, which must support JSON-RPC 2.0 requests as
+// bitcoind does since version 28, and converts them to CloudEvents. It
+// learns about new blocks and transactions from the ZMQ notifications of
+// bitcoind when streaming, and by polling otherwise.
+type bitcoinAdapter struct {
+	emitter
+
+	rpc *endpointPool
+
+	rpcURL              string
+	endpointsJSON       string
+	chainID             string
+	healthCheckInterval time.Duration
+	maxHeadLag          uint64
+	maxErrorRate        float64
+	mode                sourcesv1alpha1.IngestionMode
+	zmqURL              string
+	pollInterval        time.Duration
+	minReconnectDelay   time.Duration
+	reorgWindow         uint64
+	finality            sourcesv1alpha1.FinalityLevel
+	confirmations       uint64
+	startBlock          *uint64
+
+	// source is the CloudEvent source of the emitted events, known once the
+	// chain has been read from the node.
+	source string
+	// observedChainID is the name of the chain read from the node.
+	observedChainID string
+	// head is the height of the newest block seen, final the height of the
+	// newest block final enough to be emitted.
+	head  uint64
+	final uint64
+	// recent are the blocks processed within the reorg window, oldest first.
+	recent []blockRecord
+	// pending are the reorg and retraction events not delivered yet.
+	pending []cloudevents.Event
+	// mempool holds the IDs of the transactions in the mempool as of the
+	// last poll, nil until the mempool is first polled.
+	mempool map[string]bool
+}
+
+// NewBitcoinAdapter returns the instance of bitcoinAdapter that implements adapter.Adapter interface
+func NewBitcoinAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	env := processed.(*bitcoinEnvConfig)
+
+	a := &bitcoinAdapter{
+		emitter:             newEmitter(ctx, &env.chainEnvConfig, ceClient),
+		rpcURL:              env.EnvRPCURL,
+		endpointsJSON:       env.EnvEndpoints,
+		chainID:             env.EnvChainID,
+		healthCheckInterval: env.EnvHealthCheckInterval,
+		maxHeadLag:          env.EnvMaxHeadLag,
+		maxErrorRate:        env.EnvMaxErrorRate,
+		mode:                sourcesv1alpha1.IngestionMode(env.EnvMode),
+		zmqURL:              env.EnvZMQURL,
+		pollInterval:        env.EnvPollInterval,
+		minReconnectDelay:   minReconnectDelay,
+		reorgWindow:         env.EnvReorgWindow,
+		finality:            sourcesv1alpha1.FinalityLevel(env.EnvFinality),
+		confirmations:       env.EnvConfirmations,
+		startBlock:          env.EnvStartBlock,
+	}
+	a.cursor = a
+	return a
+}
+
+func (a *bitcoinAdapter) Start(ctx context.Context) error {
+	if err := a.setupEndpoints(); err != nil {
+		return err
+	}
+	switch a.finality {
+	case sourcesv1alpha1.FinalityLevelLatest, sourcesv1alpha1.FinalityLevelConfirmed:
+	default:
+		return fmt.Errorf("finality level %s is not supported by bitcoin nodes", a.finality)
+	}
+	if err := a.setupFilters(); err != nil {
+		return err
+	}
+	streaming := a.mode == sourcesv1alpha1.IngestionModeStreaming
+	if streaming {
+		if a.zmqURL == "" {
+			return errors.New("no ZMQ endpoint given to stream from")
+		}
+		if !strings.HasPrefix(a.zmqURL, "tcp://") {
+			return fmt.Errorf("invalid ZMQ endpoint %q, expected tcp://host:port", a.zmqURL)
+		}
+	}
+
+	return a.hold(ctx, func(ctx context.Context) error {
+		if len(a.rpc.endpoints) > 1 {
+			a.rpc.verifyAll(ctx)
+			go a.rpc.run(ctx, a.healthCheckInterval)
+		}
+
+		if streaming {
+			return a.stream(ctx)
+		}
+		return a.pollBlocks(ctx)
+	})
+}
+
+// setupEndpoints builds the pool of endpoints given to the adapter, either
+// as a list or as a single URL.
+func (a *bitcoinAdapter) setupEndpoints() error {
+	configs, err := endpointConfigs(a.rpcURL, a.endpointsJSON)
+	if err != nil {
+		return err
+	}
+	a.rpc = newEndpointPool(a.logger, bitcoinProbe{}, configs, a.maxHeadLag, a.maxErrorRate)
+	if a.chainID != "" {
+		a.rpc.chainID, a.rpc.chainKnown = a.chainID, true
+	}
+	return nil
+}
+
+// init reads the chain the first time the adapter reaches the node, and
+// resumes from the saved checkpoint. Without a checkpoint, blocks are
+// emitted from the start block, or from the one following the current head.
+// The endpoint pool checks that the chain is the expected one.
+func (a *bitcoinAdapter) init(ctx context.Context) error {
+	if a.source != "" {
+		return nil
+	}
+
+	info, err := blockchainInfo(ctx, a.rpc)
+	if err != nil {
+		return fmt.Errorf("failed to read blockchain info: %w", err)
+	}
+	a.observeHead(info.Blocks)
+
+	resumed, err := a.resume(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resumed:
+		a.logger.Infof("Resuming from checkpoint at block %d, head is %d", a.next, info.Blocks)
+	case a.startBlock != nil:
+		a.next = *a.startBlock
+		a.logger.Infof("Backfilling from block %d, head is %d", a.next, info.Blocks)
+	default:
+		a.next = info.Blocks + 1
+	}
+	a.positioned = true
+	a.observedChainID = info.Chain
+	a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilyBitcoin, info.Chain)
+	return nil
+}
+
+// pollBlocks polls the node for new blocks and transactions until ctx is
+// done.
+func (a *bitcoinAdapter) pollBlocks(ctx context.Context) error {
+	if err := a.init(ctx); err != nil {
+		a.reportConnection(ctx, err)
+		return err
+	}
+	a.reportConnection(ctx, nil)
+	// Take note of the transactions already in the mempool, the ones
+	// entering it from now on are emitted.
+	if err := a.pollMempool(ctx); err != nil {
+		a.logger.Errorf("Failed to read mempool: %v", err)
+	}
+
+	a.logger.Infof("Polling chain %s every %s starting at block %d", a.source, a.pollInterval, a.next)
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.saveCheckpoint(context.Background(), true)
+			a.logger.Infof("Polling stopped")
+			return nil
+		case <-ticker.C:
+			err := a.poll(ctx)
+			if err != nil && ctx.Err() == nil {
+				a.logger.Errorf("Polling for new blocks failed: %v", err)
+			}
+			if ctx.Err() == nil {
+				a.reportConnection(ctx, err)
+			}
+			if a.reachedEnd() {
+				a.saveCheckpoint(ctx, true)
+				a.logger.Infof("Reached end block %d, polling stopped", *a.endBlock)
+				<-ctx.Done()
+				return nil
+			}
+			a.saveCheckpoint(ctx, false)
+		}
+	}
+}
+
+// poll emits an event for every block between the last emitted one and the
+// newest block final enough, then for every transaction that entered the
+// mempool since the last poll.
+func (a *bitcoinAdapter) poll(ctx context.Context) error {
+	if err := a.catchUpHead(ctx); err != nil {
+		return err
+	}
+	return a.pollMempool(ctx)
+}
+
+// catchUpHead emits an event for every block up to the newest block final
+// enough, after retracting the events of orphaned blocks.
+func (a *bitcoinAdapter) catchUpHead(ctx context.Context) error {
+	head, err := blockCount(ctx, a.rpc)
+	if err != nil {
+		return fmt.Errorf("failed to read block count: %w", err)
+	}
+	a.observeHead(head)
+	if err := a.checkReorg(ctx); err != nil {
+		return err
+	}
+	final, ok := a.finalHead(head)
+	if !ok {
+		return nil
+	}
+	return a.catchUp(ctx, final)
+}
+
+// finalHead returns the height of the newest block final enough to be
+// emitted, given the height of the current head, up to the end block. ok is
+// false when no block is final enough yet.
+func (a *bitcoinAdapter) finalHead(head uint64) (final uint64, ok bool) {
+	final = head
+	if a.finality == sourcesv1alpha1.FinalityLevelConfirmed {
+		if head < a.confirmations {
+			return 0, false
+		}
+		final = head - a.confirmations
+	}
+	if a.endBlock != nil && final > *a.endBlock {
+		final = *a.endBlock
+	}
+	return final, true
+}
+
+// catchUp emits an event for every block from the next one up to and
+// including final. Blocks are emitted in order and a block that could not be
+// delivered is retried on the next call. A block that does not extend the
+// last emitted one reveals a reorg, which is handled before going on.
+func (a *bitcoinAdapter) catchUp(ctx context.Context, final uint64) error {
+	a.observeFinal(final)
+	for a.next <= final {
+		block, err := blockByHeight(ctx, a.rpc, a.next)
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", a.next, err)
+		}
+		if !a.extends(block) {
+			if err := a.checkReorg(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := a.emitBlock(ctx, block); err != nil {
+			return fmt.Errorf("failed to emit block %d: %w", a.next, err)
+		}
+		a.next++
+		a.saveCheckpoint(ctx, false)
+	}
+	return nil
+}
+
+// observeHead records the height of a head block, unless a newer one was
+// already seen.
+func (a *bitcoinAdapter) observeHead(height uint64) {
+	if height > a.head {
+		a.head = height
+	}
+}
+
+// observeFinal records the height of a block final enough to be emitted,
+// unless a newer one was already seen.
+func (a *bitcoinAdapter) observeFinal(height uint64) {
+	if height > a.final {
+		a.final = height
+	}
+}
+
+func (a *bitcoinAdapter) emitBlock(ctx context.Context, block *btcBlock) error {
+	event := cloudevents.NewEvent()
+	event.SetID(block.Hash)
+	event.SetType(bitcoinBlockEventType)
+	event.SetSource(a.source)
+	event.SetSubject(strconv.FormatUint(block.Height, 10))
+	event.SetTime(time.Unix(block.Time, 0))
+	event.SetExtension(finalityExtension, string(a.finality))
+	height := block.Height
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &height,
+		blockHash:   block.Hash,
+	}
+	if err := ext.apply(&event); err != nil {
+		return fmt.Errorf("failed to set event extensions: %w", err)
+	}
+
+	if err := event.SetData(cloudevents.ApplicationJSON, []byte(block.raw)); err != nil {
+		return fmt.Errorf("failed to set event data: %w", err)
+	}
+
+	if a.eventFilters.drops(ctx, a.logger, event) {
+		a.blockTime = event.Time()
+		a.record(block.Height, block.Hash, block.PreviousBlockHash, nil)
+		return nil
+	}
+	if result := a.send(ctx, event); !cloudevents.IsACK(result) {
+		return result
+	}
+	a.blockTime = event.Time()
+	a.record(block.Height, block.Hash, block.PreviousBlockHash, &sentEvent{
+		id:        event.ID(),
+		eventType: event.Type(),
+		source:    event.Source(),
+		subject:   event.Subject(),
+		ext:       ext,
+	})
+	return nil
+}
+
+// btcBlockchainInfo holds the fields of the result of getblockchaininfo
+// that the adapter needs.
+type btcBlockchainInfo struct {
+	// Chain is the name of the chain, e.g. main.
+	Chain string `json:"chain"`
+	// Blocks is the height of the head.
+	Blocks uint64 `json:"blocks"`
+}
+
+func blockchainInfo(ctx context.Context, rpc rpcCaller) (*btcBlockchainInfo, error) {
+	info := &btcBlockchainInfo{}
+	if err := rpc.Call(ctx, info, "getblockchaininfo"); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func blockCount(ctx context.Context, rpc rpcCaller) (uint64, error) {
+	var count uint64
+	if err := rpc.Call(ctx, &count, "getblockcount"); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// blockHashAt returns the hash of the block at a height of the chain the node
+// follows, or errBlockNotFound past its head.
+func blockHashAt(ctx context.Context, rpc rpcCaller, height uint64) (string, error) {
+	var hash string
+	err := rpc.Call(ctx, &hash, "getblockhash", height)
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.Code == bitcoinErrInvalidParameter {
+		return "", errBlockNotFound
+	}
+	return hash, err
+}
+
+// blockByHeight returns the block at a height of the chain the node
+// follows, with its transactions decoded.
+func blockByHeight(ctx context.Context, rpc rpcCaller, height uint64) (*btcBlock, error) {
+	hash, err := blockHashAt(ctx, rpc, height)
+	if err != nil {
+		return nil, err
+	}
+	var raw json.RawMessage
+	// Verbosity 2 decodes the inputs and outputs of the transactions.
+	if err := rpc.Call(ctx, &raw, "getblock", hash, 2); err != nil {
+		return nil, err
+	}
+	return parseBitcoinBlock(raw)
+}
+
+// btcBlock holds the fields of a block returned by getblock that the
+// adapter needs, along with the raw JSON object sent as event data.
+type btcBlock struct {
+	Hash              string `json:"hash"`
+	Height            uint64 `json:"height"`
+	PreviousBlockHash string `json:"previousblockhash"`
+	Time              int64  `json:"time"`
+
+	raw json.RawMessage
+}
+
+func parseBitcoinBlock(raw json.RawMessage) (*btcBlock, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errBlockNotFound
+	}
+	block := &btcBlock{raw: raw}
+	if err := json.Unmarshal(raw, block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block: %w", err)
+	}
+	return block, nil
+}
+
+// currentCheckpoint returns the block up to which events have been
+// delivered, or nil if nothing was delivered yet. Transactions of the
+// mempool are not checkpointed, they are only emitted while the adapter
+// runs.
+func (a *bitcoinAdapter) currentCheckpoint() *checkpoint.Checkpoint {
+	if a.source == "" || a.next == 0 {
+		return nil
+	}
+	cp := &checkpoint.Checkpoint{BlockNumber: a.next - 1}
+	for i := len(a.recent) - 1; i >= 0; i-- {
+		if a.recent[i].number == cp.BlockNumber {
+			cp.BlockHash = a.recent[i].hash
+			break
+		}
+	}
+	return cp
+}
+
+// seek moves the adapter past the block of a checkpoint.
+func (a *bitcoinAdapter) seek(cp *checkpoint.Checkpoint) {
+	a.next = cp.BlockNumber + 1
+	if cp.BlockHash != "" {
+		// Detect the checkpointed block being orphaned while the adapter
+		// was down.
+		a.record(cp.BlockNumber, cp.BlockHash, "", nil)
+	}
+}
+
+// chainProgress reports how the adapter keeps up with the chain.
+func (a *bitcoinAdapter) chainProgress() *checkpoint.Progress {
+	return &checkpoint.Progress{
+		ChainID:  a.observedChainID,
+		Head:     a.head,
+		Final:    a.final,
+		Endpoint: a.rpc.activeName(),
+	}
+}
+
+// connectionFailure returns the report of a failure to reach the node, or
+// nil if err is not one.
+func (a *bitcoinAdapter) connectionFailure(err error) *checkpoint.Connection {
+	return connectionFailure(a.rpc, err)
+}
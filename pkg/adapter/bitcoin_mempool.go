@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing-blockchain/pkg/bitcoin"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+// btcTransaction holds the fields of a transaction returned by
+// getrawtransaction that the adapter needs, along with the raw JSON object
+// sent as event data.
+type btcTransaction struct {
+	TxID string `json:"txid"`
+	// BlockHash and Confirmations are set once the transaction is in a
+	// block.
+	BlockHash     string `json:"blockhash"`
+	Confirmations uint64 `json:"confirmations"`
+
+	raw json.RawMessage
+}
+
+// handleRawTx emits the transaction published by a rawtx notification, if
+// it entered the mempool. bitcoind publishes the transactions of connected
+// blocks as well, which are emitted along with their block instead.
+func (a *bitcoinAdapter) handleRawTx(ctx context.Context, raw []byte) error {
+	tx, err := bitcoin.ParseTransaction(raw)
+	if err != nil {
+		return fmt.Errorf("invalid rawtx notification: %w", err)
+	}
+	return a.emitTransaction(ctx, tx.TxID())
+}
+
+// pollMempool emits the transactions that entered the mempool since the
+// last poll. The first poll only takes note of the transactions already in
+// the mempool.
+func (a *bitcoinAdapter) pollMempool(ctx context.Context) error {
+	var txids []string
+	if err := a.rpc.Call(ctx, &txids, "getrawmempool"); err != nil {
+		return fmt.Errorf("failed to read mempool: %w", err)
+	}
+
+	first := a.mempool == nil
+	if first {
+		a.mempool = make(map[string]bool, len(txids))
+	}
+	for _, txid := range txids {
+		if a.mempool[txid] {
+			continue
+		}
+		if !first {
+			if err := a.emitTransaction(ctx, txid); err != nil {
+				// Transactions not delivered yet are retried on the
+				// next poll.
+				return err
+			}
+		}
+		a.mempool[txid] = true
+	}
+
+	// Forget the transactions that left the mempool.
+	current := make(map[string]bool, len(txids))
+	for _, txid := range txids {
+		current[txid] = true
+	}
+	for txid := range a.mempool {
+		if !current[txid] {
+			delete(a.mempool, txid)
+		}
+	}
+	return nil
+}
+
+// emitTransaction emits a transaction of the mempool, with its inputs and
+// outputs decoded. Transactions that are already in a block, or no longer
+// known to the node, are skipped.
+func (a *bitcoinAdapter) emitTransaction(ctx context.Context, txid string) error {
+	var raw json.RawMessage
+	err := a.rpc.Call(ctx, &raw, "getrawtransaction", txid, true)
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.Code == bitcoinErrInvalidAddressOrKey {
+		// Evicted from the mempool, or mined in a block, since.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read transaction %s: %w", txid, err)
+	}
+	tx := &btcTransaction{raw: raw}
+	if err := json.Unmarshal(raw, tx); err != nil {
+		return fmt.Errorf("failed to unmarshal transaction %s: %w", txid, err)
+	}
+	if tx.BlockHash != "" || tx.Confirmations > 0 {
+		return nil
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetID(tx.TxID)
+	event.SetType(bitcoinTransactionEventType)
+	event.SetSource(a.source)
+	event.SetSubject(tx.TxID)
+	event.SetTime(time.Now())
+	ext := chainExtensions{
+		chainID: a.observedChainID,
+		txHash:  tx.TxID,
+	}
+	if err := ext.apply(&event); err != nil {
+		return fmt.Errorf("failed to set event extensions: %w", err)
+	}
+	if err := event.SetData(cloudevents.ApplicationJSON, []byte(tx.raw)); err != nil {
+		return fmt.Errorf("failed to set event data: %w", err)
+	}
+
+	if a.eventFilters.drops(ctx, a.logger, event) {
+		return nil
+	}
+	if result := a.send(ctx, event); !cloudevents.IsACK(result) {
+		return fmt.Errorf("failed to emit transaction %s: %w", txid, result)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestBitcoinAdapterPollsMempool(t *testing.T) {
+	node := newFakeBitcoind("main", 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.source = "bip122:000000000019d6689c085ae165831e93"
+	a.observedChainID = "main"
+
+	// Transactions already in the mempool on the first poll are not
+	// emitted.
+	node.addTx(1)
+	if err := a.pollMempool(context.Background()); err != nil {
+		t.Fatalf("pollMempool() = %v", err)
+	}
+	if len(ce.Sent()) != 0 {
+		t.Fatalf("sent %d events, want none", len(ce.Sent()))
+	}
+
+	_, tx2 := node.addTx(2)
+	_, tx3 := node.addTx(3)
+	if err := a.pollMempool(context.Background()); err != nil {
+		t.Fatalf("pollMempool() = %v", err)
+	}
+	// Mined transactions leave the mempool, and are not emitted again.
+	node.mine()
+	_, tx4 := node.addTx(4)
+	if err := a.pollMempool(context.Background()); err != nil {
+		t.Fatalf("pollMempool() = %v", err)
+	}
+
+	if diff := cmp.Diff([]string{tx2, tx3, tx4}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected transaction subjects (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff(map[string]bool{tx4: true}, a.mempool); diff != "" {
+		t.Errorf("unexpected mempool (-want, +got) = %v", diff)
+	}
+
+	e := ce.Sent()[0]
+	if e.Type() != bitcoinTransactionEventType {
+		t.Errorf("event type = %q, want %q", e.Type(), bitcoinTransactionEventType)
+	}
+	if e.ID() != tx2 {
+		t.Errorf("event ID = %q, want %q", e.ID(), tx2)
+	}
+	wantExt := map[string]interface{}{
+		chainIDExtension: "main",
+		txHashExtension:  tx2,
+	}
+	if diff := cmp.Diff(wantExt, e.Extensions()); diff != "" {
+		t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+	}
+	var data struct {
+		TxID string        `json:"txid"`
+		Vout []interface{} `json:"vout"`
+	}
+	if err := json.Unmarshal(e.Data(), &data); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	if data.TxID != tx2 || len(data.Vout) != 1 {
+		t.Errorf("event data = %+v, want transaction %s decoded", data, tx2)
+	}
+}
+
+func TestBitcoinAdapterHandlesRawTx(t *testing.T) {
+	node := newFakeBitcoind("main", 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.source = "bip122:000000000019d6689c085ae165831e93"
+
+	confirmed, _ := node.addTx(1)
+	node.mine()
+	pending, txid := node.addTx(2)
+
+	for _, raw := range [][]byte{
+		pending,
+		// Transactions of connected blocks are emitted with their block.
+		confirmed,
+		// Transactions the node no longer knows of are skipped.
+		rawTransaction(3),
+	} {
+		if err := a.handleRawTx(context.Background(), raw); err != nil {
+			t.Fatalf("handleRawTx() = %v", err)
+		}
+	}
+	if diff := cmp.Diff([]string{txid}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected transaction subjects (-want, +got) = %v", diff)
+	}
+
+	if err := a.handleRawTx(context.Background(), []byte{1, 2, 3}); err == nil {
+		t.Error("handleRawTx() = nil, want an error for a truncated transaction")
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+var (
+	// bitcoinReorgEventType is the CloudEvent type of the events emitted
+	// when blocks that events were sent for are orphaned by a reorg.
+	bitcoinReorgEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindReorg)
+
+	// bitcoinRetractedEventType is the CloudEvent type of the events
+	// emitted for every event sent for an orphaned block.
+	bitcoinRetractedEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindRetracted)
+)
+
+// record remembers a processed block, and an event sent for it when e is not
+// nil, so that the event can be retracted should the block be orphaned.
+// Blocks must be recorded in increasing order. Blocks older than the reorg
+// window are forgotten.
+func (a *bitcoinAdapter) record(height uint64, hash, previousHash string, e *sentEvent) {
+	n := len(a.recent)
+	if n == 0 || a.recent[n-1].number < height {
+		a.recent = append(a.recent, blockRecord{number: height, hash: hash})
+		n++
+	}
+	tail := &a.recent[n-1]
+	if tail.number != height || tail.hash != hash {
+		return
+	}
+	if previousHash != "" {
+		tail.parentHash = previousHash
+	}
+	if e != nil {
+		tail.events = append(tail.events, *e)
+	}
+
+	for len(a.recent) > 0 && a.recent[0].number+a.reorgWindow <= height {
+		a.recent = a.recent[1:]
+	}
+}
+
+// extends reports whether block can follow the last recorded block, which is
+// not the case when the chain was reorganized since.
+func (a *bitcoinAdapter) extends(block *btcBlock) bool {
+	n := len(a.recent)
+	if n == 0 {
+		return true
+	}
+	tail := a.recent[n-1]
+	if block.Height <= tail.number {
+		return false
+	}
+	return block.Height != tail.number+1 || block.PreviousBlockHash == tail.hash
+}
+
+// checkReorg compares the recorded blocks with the chain the node follows.
+// Events sent for orphaned blocks are retracted, and the adapter rewinds to
+// the block following the fork so that the blocks of the best chain get
+// emitted.
+func (a *bitcoinAdapter) checkReorg(ctx context.Context) error {
+	if err := a.flushPending(ctx); err != nil {
+		return err
+	}
+
+	// Find the newest recorded block that is still in the best chain.
+	i := len(a.recent)
+	for ; i > 0; i-- {
+		record := a.recent[i-1]
+		hash, err := blockHashAt(ctx, a.rpc, record.number)
+		if err != nil && !errors.Is(err, errBlockNotFound) {
+			return fmt.Errorf("failed to check block %d for a reorg: %w", record.number, err)
+		}
+		if err == nil && hash == record.hash {
+			break
+		}
+	}
+	if i == len(a.recent) {
+		return nil
+	}
+
+	orphaned := make([]blockRecord, 0, len(a.recent)-i)
+	for j := len(a.recent) - 1; j >= i; j-- {
+		orphaned = append(orphaned, a.recent[j])
+	}
+	a.recent = a.recent[:i]
+
+	data := reorgEventData{}
+	oldest := orphaned[len(orphaned)-1]
+	if i > 0 {
+		fork := a.recent[i-1]
+		data.ForkBlockNumber, data.ForkBlockHash = fork.number, fork.hash
+		a.next = fork.number + 1
+	} else {
+		a.logger.Errorf("Reorg deeper than the window of %d blocks, events sent before block %d cannot be retracted", a.reorgWindow, oldest.number)
+		data.ForkBlockNumber, data.ForkBlockHash = oldest.number-1, oldest.parentHash
+		a.next = oldest.number
+	}
+	for _, o := range orphaned {
+		data.Orphaned = append(data.Orphaned, orphanedBlock{Number: o.number, Hash: o.hash})
+	}
+	a.logger.Infof("Chain reorganized after block %d, %d blocks orphaned", data.ForkBlockNumber, len(orphaned))
+
+	events, err := reorgEvents(bitcoinReorgEventType, bitcoinRetractedEventType, a.source, data, orphaned)
+	if err != nil {
+		return err
+	}
+	a.pending = append(a.pending, events...)
+	return a.flushPending(ctx)
+}
+
+// flushPending sends the reorg and retraction events that are not delivered
+// yet, in order.
+func (a *bitcoinAdapter) flushPending(ctx context.Context) error {
+	for len(a.pending) > 0 {
+		event := a.pending[0]
+		if result := a.send(ctx, event); !cloudevents.IsACK(result) {
+			return fmt.Errorf("failed to emit %s event %s: %w", event.Type(), event.ID(), result)
+		}
+		a.pending = a.pending[1:]
+	}
+	return nil
+}
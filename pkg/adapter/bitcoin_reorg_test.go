@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestBitcoinAdapterRetractsOrphanedBlocks(t *testing.T) {
+	node := newFakeBitcoind("main", 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.source = "bip122:000000000019d6689c085ae165831e93"
+	a.next = 3
+
+	node.mine()
+	node.mine()
+	node.mine()
+	if err := a.catchUpHead(context.Background()); err != nil {
+		t.Fatalf("catchUpHead() = %v", err)
+	}
+
+	// Blocks 4 and 5 are replaced, and the chain grows past them.
+	node.reorg(2)
+	node.mine()
+	node.mine()
+	node.mine()
+	if err := a.catchUpHead(context.Background()); err != nil {
+		t.Fatalf("catchUpHead() = %v", err)
+	}
+
+	want := []string{
+		"block " + btcBlockHash(0, 3),
+		"block " + btcBlockHash(0, 4),
+		"block " + btcBlockHash(0, 5),
+		"reorg " + btcBlockHash(0, 4) + "-reorg",
+		"retracted " + btcBlockHash(0, 5) + "-retracted " + btcBlockHash(0, 5),
+		"retracted " + btcBlockHash(0, 4) + "-retracted " + btcBlockHash(0, 4),
+		"block " + btcBlockHash(1, 4),
+		"block " + btcBlockHash(1, 5),
+		"block " + btcBlockHash(1, 6),
+	}
+	if diff := cmp.Diff(want, sentSummary(ce)); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+
+	reorg := ce.Sent()[3]
+	if reorg.Subject() != "3" {
+		t.Errorf("reorg subject = %q, want the fork block 3", reorg.Subject())
+	}
+}
+
+func TestBitcoinAdapterReorgDeeperThanWindow(t *testing.T) {
+	node := newFakeBitcoind("main", 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.source = "bip122:000000000019d6689c085ae165831e93"
+	a.reorgWindow = 2
+	a.next = 3
+
+	node.mine()
+	node.mine()
+	node.mine()
+	if err := a.catchUpHead(context.Background()); err != nil {
+		t.Fatalf("catchUpHead() = %v", err)
+	}
+
+	// Block 3 was forgotten, its event cannot be retracted.
+	node.reorg(3)
+	node.mine()
+	node.mine()
+	node.mine()
+	if err := a.catchUpHead(context.Background()); err != nil {
+		t.Fatalf("catchUpHead() = %v", err)
+	}
+
+	want := []string{
+		"block " + btcBlockHash(0, 3),
+		"block " + btcBlockHash(0, 4),
+		"block " + btcBlockHash(0, 5),
+		"reorg " + btcBlockHash(0, 4) + "-reorg",
+		"retracted " + btcBlockHash(0, 5) + "-retracted " + btcBlockHash(0, 5),
+		"retracted " + btcBlockHash(0, 4) + "-retracted " + btcBlockHash(0, 4),
+		"block " + btcBlockHash(1, 4),
+		"block " + btcBlockHash(1, 5),
+	}
+	if diff := cmp.Diff(want, sentSummary(ce)); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,441 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/pkg/logging"
+	pkgtesting "knative.dev/pkg/reconciler/testing"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/bitcoin"
+)
+
+// fakeBitcoind is an in-memory bitcoind JSON-RPC endpoint.
+type fakeBitcoind struct {
+	mu    sync.Mutex
+	chain string
+	// blocks are the blocks of the best chain, by height.
+	blocks []map[string]interface{}
+	// orphaned are the blocks orphaned by reorgs, by hash.
+	orphaned map[string]map[string]interface{}
+	// mempool holds the decoded transactions of the mempool, in the order
+	// they entered it.
+	mempool []map[string]interface{}
+	// confirmed holds the decoded transactions of the blocks, by ID.
+	confirmed map[string]map[string]interface{}
+	// fork distinguishes the hashes of blocks mined after a reorg.
+	fork uint64
+	// failing makes every request fail with a JSON-RPC error.
+	failing bool
+
+	callCounter
+}
+
+func newFakeBitcoind(chain string, blocks int) *fakeBitcoind {
+	n := &fakeBitcoind{
+		chain:     chain,
+		orphaned:  make(map[string]map[string]interface{}),
+		confirmed: make(map[string]map[string]interface{}),
+	}
+	for i := 0; i < blocks; i++ {
+		n.mine()
+	}
+	return n
+}
+
+// btcBlockHash returns the hash of a block mined by a fakeBitcoind.
+func btcBlockHash(fork, height uint64) string {
+	return fmt.Sprintf("%064x", 0xb10c000+fork<<20+height)
+}
+
+// rawTransaction returns a serialized transaction, made unique by n.
+func rawTransaction(n uint32) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, uint32(2))
+	b.WriteByte(1)
+	b.Write(make([]byte, 32))
+	binary.Write(&b, binary.LittleEndian, n)
+	b.WriteByte(0)
+	binary.Write(&b, binary.LittleEndian, uint32(0xffffffff))
+	b.WriteByte(1)
+	binary.Write(&b, binary.LittleEndian, uint64(n)*1000)
+	b.Write([]byte{1, 0x51})
+	binary.Write(&b, binary.LittleEndian, uint32(0))
+	return b.Bytes()
+}
+
+// addTx adds a transaction to the mempool, and returns it serialized along
+// with its ID.
+func (n *fakeBitcoind) addTx(i uint32) ([]byte, string) {
+	raw := rawTransaction(i)
+	tx, err := bitcoin.ParseTransaction(raw)
+	if err != nil {
+		panic(err)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.mempool = append(n.mempool, map[string]interface{}{
+		"txid": tx.TxID(),
+		"hash": tx.TxID(),
+		"hex":  hex.EncodeToString(raw),
+		"vout": []interface{}{map[string]interface{}{"value": float64(i) / 100000, "n": 0}},
+	})
+	return raw, tx.TxID()
+}
+
+// mine appends a new block containing the transactions of the mempool on
+// top of the current head.
+func (n *fakeBitcoind) mine() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	height := uint64(len(n.blocks))
+	hash := btcBlockHash(n.fork, height)
+	block := map[string]interface{}{
+		"hash":   hash,
+		"height": height,
+		"time":   1600000000 + 600*height,
+		"tx":     []interface{}{},
+	}
+	if height > 0 {
+		block["previousblockhash"] = n.blocks[height-1]["hash"]
+	}
+	txs := []interface{}{}
+	for _, tx := range n.mempool {
+		tx["blockhash"] = hash
+		tx["confirmations"] = 1
+		n.confirmed[tx["txid"].(string)] = tx
+		txs = append(txs, tx)
+	}
+	block["tx"] = txs
+	n.mempool = nil
+	n.blocks = append(n.blocks, block)
+}
+
+// reorg orphans the last depth blocks.
+func (n *fakeBitcoind) reorg(depth int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, b := range n.blocks[len(n.blocks)-depth:] {
+		n.orphaned[b["hash"].(string)] = b
+	}
+	n.blocks = n.blocks[:len(n.blocks)-depth]
+	n.fork++
+}
+
+func (n *fakeBitcoind) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req fakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(n.handle(&req))
+}
+
+// handle returns the response to a request.
+func (n *fakeBitcoind) handle(req *fakeRequest) map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.called(req.Method)
+	fail := func(code int, message string) map[string]interface{} {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"error":   map[string]interface{}{"code": code, "message": message},
+		}
+	}
+	if n.failing {
+		return fail(-28, "Loading block index...")
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "getblockchaininfo":
+		result = map[string]interface{}{
+			"chain":         n.chain,
+			"blocks":        len(n.blocks) - 1,
+			"bestblockhash": n.blocks[len(n.blocks)-1]["hash"],
+		}
+	case "getblockcount":
+		result = len(n.blocks) - 1
+	case "getblockhash":
+		var height int
+		json.Unmarshal(req.Params[0], &height)
+		if height >= len(n.blocks) {
+			return fail(bitcoinErrInvalidParameter, "Block height out of range")
+		}
+		result = n.blocks[height]["hash"]
+	case "getblock":
+		var hash string
+		json.Unmarshal(req.Params[0], &hash)
+		for _, b := range n.blocks {
+			if b["hash"] == hash {
+				result = b
+			}
+		}
+		if b, ok := n.orphaned[hash]; ok {
+			result = b
+		}
+		if result == nil {
+			return fail(bitcoinErrInvalidAddressOrKey, "Block not found")
+		}
+	case "getrawmempool":
+		txids := []string{}
+		for _, tx := range n.mempool {
+			txids = append(txids, tx["txid"].(string))
+		}
+		result = txids
+	case "getrawtransaction":
+		var txid string
+		json.Unmarshal(req.Params[0], &txid)
+		for _, tx := range n.mempool {
+			if tx["txid"] == txid {
+				result = tx
+			}
+		}
+		if tx, ok := n.confirmed[txid]; ok {
+			result = tx
+		}
+		if result == nil {
+			return fail(bitcoinErrInvalidAddressOrKey, "No such mempool or blockchain transaction")
+		}
+	default:
+		return fail(-32601, "Method not found")
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+		"result":  result,
+	}
+}
+
+func newTestBitcoinAdapter(t *testing.T, ce *adaptertest.TestCloudEventsClient, rpcURL string) *bitcoinAdapter {
+	env := bitcoinEnvConfig{
+		chainEnvConfig: chainEnvConfig{
+			EnvConfig: adapter.EnvConfig{
+				Namespace: "default",
+			},
+			EnvRPCURL: rpcURL,
+		},
+		EnvMode:                string(sourcesv1alpha1.IngestionModePolling),
+		EnvPollInterval:        10 * time.Millisecond,
+		EnvReorgWindow:         16,
+		EnvFinality:            string(sourcesv1alpha1.FinalityLevelLatest),
+		EnvConfirmations:       6,
+		EnvHealthCheckInterval: 10 * time.Millisecond,
+		EnvMaxHeadLag:          1,
+		EnvMaxErrorRate:        0.5,
+	}
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+	logger := zap.NewExample().Sugar()
+	ctx = logging.WithLogger(ctx, logger)
+
+	a := NewBitcoinAdapter(ctx, &env, ce).(*bitcoinAdapter)
+	a.minReconnectDelay = 10 * time.Millisecond
+	if err := a.setupEndpoints(); err != nil {
+		t.Fatalf("setupEndpoints() = %v", err)
+	}
+	return a
+}
+
+func TestBitcoinAdapterEmitsNewBlocks(t *testing.T) {
+	node := newFakeBitcoind("main", 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+
+	runAdapter(t, a, func() {
+		// Blocks mined before the adapter started are not emitted.
+		node.waitForCalls(t, "getblockcount", 1)
+		node.mine()
+		node.mine()
+		waitForEvents(t, ce, 2)
+	})
+
+	source := "bip122:000000000019d6689c085ae165831e93"
+	for _, e := range ce.Sent() {
+		if e.Type() != bitcoinBlockEventType {
+			t.Errorf("event type = %q, want %q", e.Type(), bitcoinBlockEventType)
+		}
+		if e.Source() != source {
+			t.Errorf("event source = %q, want %q", e.Source(), source)
+		}
+		var data btcBlock
+		if err := json.Unmarshal(e.Data(), &data); err != nil {
+			t.Fatalf("Could not unmarshal sent data: %v", err)
+		}
+		if data.Hash != e.ID() {
+			t.Errorf("event ID = %q, want block hash %q", e.ID(), data.Hash)
+		}
+		if !e.Time().Equal(time.Unix(data.Time, 0)) {
+			t.Errorf("event time = %v, want block time %v", e.Time(), time.Unix(data.Time, 0))
+		}
+		wantExt := map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     "main",
+			blockNumberExtension: int32(data.Height),
+			blockHashExtension:   data.Hash,
+		}
+		if diff := cmp.Diff(wantExt, e.Extensions()); diff != "" {
+			t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+		}
+	}
+	if diff := cmp.Diff([]string{"3", "4"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestBitcoinAdapterDecodesBlockTransactions(t *testing.T) {
+	node := newFakeBitcoind("regtest", 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.source = "bip122:0f9188f13cb7b2c71f2a335e3a4fc328"
+	a.next = 1
+
+	_, txid := node.addTx(1)
+	node.mine()
+	if err := a.catchUpHead(context.Background()); err != nil {
+		t.Fatalf("catchUpHead() = %v", err)
+	}
+
+	sent := ce.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d events, want 1", len(sent))
+	}
+	var data struct {
+		Tx []struct {
+			TxID string        `json:"txid"`
+			Vout []interface{} `json:"vout"`
+		} `json:"tx"`
+	}
+	if err := json.Unmarshal(sent[0].Data(), &data); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	if len(data.Tx) != 1 || data.Tx[0].TxID != txid || len(data.Tx[0].Vout) != 1 {
+		t.Errorf("block transactions = %+v, want %s decoded", data.Tx, txid)
+	}
+}
+
+func TestBitcoinAdapterEmitsConfirmedBlocks(t *testing.T) {
+	node := newFakeBitcoind("main", 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.source = "bip122:000000000019d6689c085ae165831e93"
+	a.finality = sourcesv1alpha1.FinalityLevelConfirmed
+	a.confirmations = 2
+	a.next = 1
+
+	// Block 1 has a single block on top of it.
+	if err := a.catchUpHead(context.Background()); err != nil {
+		t.Fatalf("catchUpHead() = %v", err)
+	}
+	if len(ce.Sent()) != 0 {
+		t.Fatalf("sent %d events, want none", len(ce.Sent()))
+	}
+
+	node.mine()
+	node.mine()
+	if err := a.catchUpHead(context.Background()); err != nil {
+		t.Fatalf("catchUpHead() = %v", err)
+	}
+	if diff := cmp.Diff([]string{"1", "2"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+	if got := ce.Sent()[0].Extensions()[finalityExtension]; got != "confirmed" {
+		t.Errorf("finality = %v, want confirmed", got)
+	}
+}
+
+func TestBitcoinAdapterRejectsOtherChain(t *testing.T) {
+	node := newFakeBitcoind("main", 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.chainID = "test"
+
+	if err := a.Start(context.Background()); err == nil {
+		t.Fatal("Start() = nil, want an error")
+	}
+	if len(ce.Sent()) != 0 {
+		t.Errorf("sent %d events, want none", len(ce.Sent()))
+	}
+}
+
+func TestBitcoinAdapterRetriesUndeliveredBlocks(t *testing.T) {
+	node := newFakeBitcoind("main", 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.source = "bip122:000000000019d6689c085ae165831e93"
+	a.next = 1
+
+	node.mine()
+	node.mine()
+
+	// A failing node stops the poll without advancing.
+	node.mu.Lock()
+	node.failing = true
+	node.mu.Unlock()
+	if err := a.poll(context.Background()); err == nil {
+		t.Fatal("poll() = nil, want an error")
+	}
+	if a.next != 1 {
+		t.Fatalf("next = %d, want 1", a.next)
+	}
+
+	node.mu.Lock()
+	node.failing = false
+	node.mu.Unlock()
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	if a.next != 3 {
+		t.Errorf("next = %d, want 3", a.next)
+	}
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("sent %d events, want 2", got)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-zeromq/zmq4"
+	"go.uber.org/zap"
+)
+
+// Topics of the ZMQ notifications of bitcoind.
+const (
+	hashBlockTopic = "hashblock"
+	rawTxTopic     = "rawtx"
+)
+
+// zmqDialTimeout bounds the time to connect to the ZMQ endpoint of
+// bitcoind. Failed connections are retried by stream, with a backoff.
+const zmqDialTimeout = 10 * time.Second
+
+// stream subscribes to the ZMQ notifications of bitcoind until ctx is done,
+// reconnecting with an exponential backoff whenever the connection is lost.
+func (a *bitcoinAdapter) stream(ctx context.Context) error {
+	defer a.saveCheckpoint(context.Background(), true)
+
+	delay := a.minReconnectDelay
+	for {
+		received, err := a.streamOnce(ctx)
+		if ctx.Err() != nil {
+			a.logger.Infof("Streaming stopped")
+			return nil
+		}
+		if a.reachedEnd() {
+			a.saveCheckpoint(ctx, true)
+			a.logger.Infof("Reached end block %d, streaming stopped", *a.endBlock)
+			<-ctx.Done()
+			return nil
+		}
+		if received {
+			delay = a.minReconnectDelay
+		}
+		a.reportConnection(ctx, err)
+		a.logger.Errorf("Stream interrupted, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			a.logger.Infof("Streaming stopped")
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// streamOnce subscribes to the hashblock and rawtx notifications over a new
+// connection and emits the announced blocks and transactions until the
+// connection is lost or the end block is reached. It reports whether any
+// notification was received, so that the caller can tell a flapping
+// connection from a working one.
+//
+// Publishers drop notifications when subscribers fall behind, so the node is
+// also polled for blocks every poll interval.
+func (a *bitcoinAdapter) streamOnce(ctx context.Context) (bool, error) {
+	// Subscribe first, so that no block produced while catching up is
+	// missed.
+	sub, err := a.subscribe(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to subscribe to %s: %w", a.zmqURL, err)
+	}
+	defer sub.Close()
+
+	messages := make(chan [][]byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			msg, err := sub.Recv()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- msg.Frames:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := a.init(ctx); err != nil {
+		return false, err
+	}
+	a.reportConnection(ctx, nil)
+
+	if err := a.catchUpHead(ctx); err != nil {
+		return false, err
+	}
+	if a.reachedEnd() {
+		return false, nil
+	}
+	a.logger.Infof("Streaming chain %s from block %d through %s", a.source, a.next, a.zmqURL)
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			return received, nil
+		case err := <-readErr:
+			return received, fmt.Errorf("ZMQ connection lost: %w", err)
+		case <-ticker.C:
+			if err := a.catchUpHead(ctx); err != nil {
+				return received, err
+			}
+		case frames := <-messages:
+			received = true
+			if err := a.handleNotification(ctx, frames); err != nil {
+				return received, err
+			}
+		}
+		if a.reachedEnd() {
+			return received, nil
+		}
+		a.saveCheckpoint(ctx, false)
+	}
+}
+
+// subscribe connects a SUB socket to the ZMQ endpoint of bitcoind, and
+// subscribes it to the hashblock and rawtx notifications. The socket is
+// closed when ctx is done.
+func (a *bitcoinAdapter) subscribe(ctx context.Context) (zmq4.Socket, error) {
+	sub := zmq4.NewSub(ctx,
+		zmq4.WithDialerTimeout(zmqDialTimeout),
+		zmq4.WithDialerRetry(0),
+		zmq4.WithLogger(zap.NewStdLog(a.logger.Desugar())),
+	)
+	if err := sub.Dial(a.zmqURL); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	for _, topic := range []string{hashBlockTopic, rawTxTopic} {
+		if err := sub.SetOption(zmq4.OptionSubscribe, topic); err != nil {
+			sub.Close()
+			return nil, err
+		}
+	}
+	return sub, nil
+}
+
+// handleNotification handles a ZMQ notification, made of its topic, its
+// body and its sequence number. A hashblock notification tells that there
+// are new blocks, or that the chain was reorganized. A rawtx notification
+// carries a serialized transaction.
+func (a *bitcoinAdapter) handleNotification(ctx context.Context, frames [][]byte) error {
+	if len(frames) < 2 {
+		return fmt.Errorf("ZMQ notification has %d frames instead of 3", len(frames))
+	}
+	switch string(frames[0]) {
+	case hashBlockTopic:
+		return a.catchUpHead(ctx)
+	case rawTxTopic:
+		return a.handleRawTx(ctx, frames[1])
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-zeromq/zmq4"
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+// zmqPublisher is a ZeroMQ publisher listening on the loopback interface,
+// publishing the way bitcoind does.
+type zmqPublisher struct {
+	t        *testing.T
+	endpoint string
+
+	mu        sync.Mutex
+	pub       zmq4.Socket
+	sequences map[string]uint32
+}
+
+// newZMQPublisher starts a publisher, closed at the end of the test.
+func newZMQPublisher(t *testing.T) *zmqPublisher {
+	t.Helper()
+	p := &zmqPublisher{t: t, sequences: make(map[string]uint32)}
+	p.listen("tcp://127.0.0.1:0")
+	p.endpoint = "tcp://" + p.pub.Addr().String()
+	t.Cleanup(func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.pub.Close()
+	})
+	return p
+}
+
+func (p *zmqPublisher) listen(endpoint string) {
+	p.t.Helper()
+	p.pub = zmq4.NewPub(context.Background())
+	if err := p.pub.Listen(endpoint); err != nil {
+		p.t.Fatalf("Listen() = %v", err)
+	}
+}
+
+// waitForSubscription waits until a subscriber subscribed to the topic.
+func (p *zmqPublisher) waitForSubscription(topic string) {
+	p.t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !p.subscribed(topic) {
+		if time.Now().After(deadline) {
+			p.t.Fatalf("No subscription to %s", topic)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (p *zmqPublisher) subscribed(topic string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.pub.(zmq4.Topics).Topics() {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// publish sends a message to the subscribers of its topic, made of the
+// topic, the body and the sequence number of the message within the topic
+// in little endian, like bitcoind notifications.
+func (p *zmqPublisher) publish(topic string, body []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seq := make([]byte, 4)
+	binary.LittleEndian.PutUint32(seq, p.sequences[topic])
+	p.sequences[topic]++
+	if err := p.pub.Send(zmq4.NewMsgFrom([]byte(topic), body, seq)); err != nil {
+		p.t.Errorf("Send() = %v", err)
+	}
+}
+
+// disconnect closes the connections of the current subscribers, by
+// restarting the publisher on the same endpoint.
+func (p *zmqPublisher) disconnect() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pub.Close()
+	p.listen(p.endpoint)
+}
+
+// publishBlock publishes the hashblock notification of the head of node.
+func publishBlock(t *testing.T, p *zmqPublisher, node *fakeBitcoind) {
+	node.mu.Lock()
+	hash := node.blocks[len(node.blocks)-1]["hash"].(string)
+	node.mu.Unlock()
+	body, err := hex.DecodeString(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.publish(hashBlockTopic, body)
+}
+
+func TestBitcoinAdapterStreamsNotifications(t *testing.T) {
+	node := newFakeBitcoind("main", 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+	publisher := newZMQPublisher(t)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.mode = sourcesv1alpha1.IngestionModeStreaming
+	a.zmqURL = publisher.endpoint
+	// Blocks are only learnt of through notifications.
+	a.pollInterval = time.Hour
+
+	var txid string
+	runAdapter(t, a, func() {
+		publisher.waitForSubscription(hashBlockTopic)
+		publisher.waitForSubscription(rawTxTopic)
+		node.waitForCalls(t, "getblockcount", 1)
+
+		node.mine()
+		publishBlock(t, publisher, node)
+		waitForEvents(t, ce, 1)
+
+		var raw []byte
+		raw, txid = node.addTx(1)
+		publisher.publish(rawTxTopic, raw)
+		waitForEvents(t, ce, 2)
+
+		// Transactions of connected blocks are published before the block,
+		// and emitted with it.
+		raw, _ = node.addTx(2)
+		node.mine()
+		publisher.publish(rawTxTopic, raw)
+		publishBlock(t, publisher, node)
+		waitForEvents(t, ce, 3)
+
+		// Blocks whose notification was missed are emitted after reconnecting.
+		node.mine()
+		publisher.disconnect()
+		waitForEvents(t, ce, 4)
+	})
+
+	if diff := cmp.Diff([]string{"3", txid, "4", "5"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestBitcoinAdapterPollsWhileStreaming(t *testing.T) {
+	node := newFakeBitcoind("main", 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+	publisher := newZMQPublisher(t)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestBitcoinAdapter(t, ce, server.URL)
+	a.mode = sourcesv1alpha1.IngestionModeStreaming
+	a.zmqURL = publisher.endpoint
+
+	runAdapter(t, a, func() {
+		// Notifications dropped by the publisher do not hold blocks back.
+		publisher.waitForSubscription(hashBlockTopic)
+		node.waitForCalls(t, "getblockcount", 1)
+		node.mine()
+		waitForEvents(t, ce, 1)
+	})
+	if diff := cmp.Diff([]string{"3"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"os"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"knative.dev/eventing/pkg/adapter/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+// EnvFamily is the environment variable containing the family of the chain
+// the receive adapter reads, which selects the adapter to run. Defaults to
+// evm.
+const EnvFamily = "BLOCKCHAIN_FAMILY"
+
+// chainEnvConfig holds the environment variables the adapters of every
+// family read. The form of the endpoints and of the chain ID depends on the
+// family.
+type chainEnvConfig struct {
+	adapter.EnvConfig
+
+	// Environment variable containing the endpoint of the node
+	EnvRPCURL string `envconfig:"BLOCKCHAIN_RPC_URL"`
+	// Environment variable containing the JSON encoded list of endpoints,
+	// used instead of BLOCKCHAIN_RPC_URL. The credentials of the endpoint at
+	// index i are read from BLOCKCHAIN_ENDPOINT_<i>_CREDENTIALS
+	EnvEndpoints string `envconfig:"BLOCKCHAIN_ENDPOINTS"`
+	// Environment variable containing the ID of the chain the endpoints must
+	// serve. Any chain is accepted when not set, unless the family needs it
+	EnvChainID string `envconfig:"BLOCKCHAIN_CHAIN_ID"`
+	// Environment variable containing the JSON encoded list of filters
+	// events must pass to be delivered
+	EnvFilters string `envconfig:"BLOCKCHAIN_FILTERS"`
+	// Environment variable containing the name of the ConfigMap the checkpoint
+	// is saved in
+	EnvCheckpointConfigMap string `envconfig:"BLOCKCHAIN_CHECKPOINT_CONFIGMAP"`
+	// Environment variable containing the path of the file the checkpoint is
+	// saved in, instead of a ConfigMap
+	EnvCheckpointFile string `envconfig:"BLOCKCHAIN_CHECKPOINT_FILE"`
+	// Environment variable containing how often the checkpoint is saved
+	EnvCheckpointInterval time.Duration `envconfig:"BLOCKCHAIN_CHECKPOINT_INTERVAL" default:"10s"`
+	// Environment variable containing the number of the first block, or
+	// slot, to emit when there is no checkpoint, instead of the one
+	// following the head
+	EnvStartBlock *uint64 `envconfig:"BLOCKCHAIN_START_BLOCK"`
+	// Environment variable containing the number of the last block, or
+	// slot, to emit
+	EnvEndBlock *uint64 `envconfig:"BLOCKCHAIN_END_BLOCK"`
+}
+
+// NewBlockchainEnvConfig returns the accessor of the environment variables
+// of the adapter of the family of the chain.
+func NewBlockchainEnvConfig() adapter.EnvConfigAccessor {
+	switch sourcesv1alpha1.ChainFamily(os.Getenv(EnvFamily)) {
+	case sourcesv1alpha1.ChainFamilyBitcoin:
+		return NewBitcoinEnvConfig()
+	case sourcesv1alpha1.ChainFamilyFabric:
+		return NewFabricEnvConfig()
+	case sourcesv1alpha1.ChainFamilyTendermint:
+		return NewTendermintEnvConfig()
+	case sourcesv1alpha1.ChainFamilySolana:
+		return NewSolanaEnvConfig()
+	case sourcesv1alpha1.ChainFamilyBeacon:
+		return NewBeaconEnvConfig()
+	default:
+		return NewEthereumEnvConfig()
+	}
+}
+
+// NewBlockchainAdapter returns the adapter of the family of the chain, given
+// the environment read by the accessor NewBlockchainEnvConfig returned.
+func NewBlockchainAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	switch processed.(type) {
+	case *bitcoinEnvConfig:
+		return NewBitcoinAdapter(ctx, processed, ceClient)
+	case *fabricEnvConfig:
+		return NewFabricAdapter(ctx, processed, ceClient)
+	case *tendermintEnvConfig:
+		return NewTendermintAdapter(ctx, processed, ceClient)
+	case *solanaEnvConfig:
+		return NewSolanaAdapter(ctx, processed, ceClient)
+	case *beaconEnvConfig:
+		return NewBeaconAdapter(ctx, processed, ceClient)
+	default:
+		return NewEthereumAdapter(ctx, processed, ceClient)
+	}
+}
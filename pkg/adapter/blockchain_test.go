@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/fabric/fabrictest"
+)
+
+// callCounter counts the requests received by a fake node, per method or
+// endpoint.
+type callCounter struct {
+	callsMu sync.Mutex
+	calls   map[string]int
+}
+
+// called counts a request.
+func (c *callCounter) called(method string) {
+	c.callsMu.Lock()
+	defer c.callsMu.Unlock()
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[method]++
+}
+
+// callCount returns the number of requests received for method.
+func (c *callCounter) callCount(method string) int {
+	c.callsMu.Lock()
+	defer c.callsMu.Unlock()
+	return c.calls[method]
+}
+
+// waitForCalls waits until method has been called at least count times.
+func (c *callCounter) waitForCalls(t *testing.T, method string, count int) {
+	t.Helper()
+	waitForCount(t, method+" calls", count, func() int {
+		return c.callCount(method)
+	})
+}
+
+// waitForEvents waits until at least count events have been sent.
+func waitForEvents(t *testing.T, ce *adaptertest.TestCloudEventsClient, count int) {
+	t.Helper()
+	waitForCount(t, "events", count, func() int {
+		return len(ce.Sent())
+	})
+}
+
+// waitForCount waits until got returns at least count.
+func waitForCount(t *testing.T, what string, count int, got func() int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		n := got()
+		if n >= count {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d %s, got %d", count, what, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// runAdapter starts an adapter, calls fn, then stops the adapter.
+func runAdapter(t *testing.T, a adapter.Adapter, fn func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Start(ctx)
+	}()
+	fn()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+}
+
+func TestNewBlockchainEnvConfig(t *testing.T) {
+	if _, ok := NewBlockchainEnvConfig().(*ethereumEnvConfig); !ok {
+		t.Errorf("NewBlockchainEnvConfig() without family = %T, want *ethereumEnvConfig", NewBlockchainEnvConfig())
+	}
+
+	t.Setenv(EnvFamily, "evm")
+	if _, ok := NewBlockchainEnvConfig().(*ethereumEnvConfig); !ok {
+		t.Errorf("NewBlockchainEnvConfig() for evm = %T, want *ethereumEnvConfig", NewBlockchainEnvConfig())
+	}
+
+	t.Setenv(EnvFamily, "bitcoin")
+	if _, ok := NewBlockchainEnvConfig().(*bitcoinEnvConfig); !ok {
+		t.Errorf("NewBlockchainEnvConfig() for bitcoin = %T, want *bitcoinEnvConfig", NewBlockchainEnvConfig())
+	}
+
+	t.Setenv(EnvFamily, "fabric")
+	if _, ok := NewBlockchainEnvConfig().(*fabricEnvConfig); !ok {
+		t.Errorf("NewBlockchainEnvConfig() for fabric = %T, want *fabricEnvConfig", NewBlockchainEnvConfig())
+	}
+
+	t.Setenv(EnvFamily, "tendermint")
+	if _, ok := NewBlockchainEnvConfig().(*tendermintEnvConfig); !ok {
+		t.Errorf("NewBlockchainEnvConfig() for tendermint = %T, want *tendermintEnvConfig", NewBlockchainEnvConfig())
+	}
+
+	t.Setenv(EnvFamily, "solana")
+	if _, ok := NewBlockchainEnvConfig().(*solanaEnvConfig); !ok {
+		t.Errorf("NewBlockchainEnvConfig() for solana = %T, want *solanaEnvConfig", NewBlockchainEnvConfig())
+	}
+
+	t.Setenv(EnvFamily, "beacon")
+	if _, ok := NewBlockchainEnvConfig().(*beaconEnvConfig); !ok {
+		t.Errorf("NewBlockchainEnvConfig() for beacon = %T, want *beaconEnvConfig", NewBlockchainEnvConfig())
+	}
+}
+
+func TestAdaptersRejectInvalidSettings(t *testing.T) {
+	tests := map[string]struct {
+		newAdapter func(t *testing.T) adapter.Adapter
+		// wantErr is a part of the error Start returns, if not empty.
+		wantErr string
+	}{
+		"bitcoin finalized finality": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestBitcoinAdapter(t, adaptertest.NewTestClient(), "http://bitcoind:8332")
+			a.finality = sourcesv1alpha1.FinalityLevelFinalized
+			return a
+		}},
+		"bitcoin streaming without ZMQ endpoint": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestBitcoinAdapter(t, adaptertest.NewTestClient(), "http://bitcoind:8332")
+			a.mode = sourcesv1alpha1.IngestionModeStreaming
+			return a
+		}},
+		"bitcoin IPC ZMQ endpoint": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestBitcoinAdapter(t, adaptertest.NewTestClient(), "http://bitcoind:8332")
+			a.mode = sourcesv1alpha1.IngestionModeStreaming
+			a.zmqURL = "ipc:///var/run/bitcoind.sock"
+			return a
+		}},
+		"fabric missing channel": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestFabricAdapter(t, adaptertest.NewTestClient(), "grpc://peer0:7051")
+			a.channelID = ""
+			return a
+		}},
+		"fabric unsupported block type": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestFabricAdapter(t, adaptertest.NewTestClient(), "grpc://peer0:7051")
+			a.blockType = "private"
+			return a
+		}},
+		"fabric invalid identity": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestFabricAdapter(t, adaptertest.NewTestClient(), "grpc://peer0:7051")
+			_, otherKey := fabrictest.NewIdentity(t, "other")
+			a.keyPEM = string(otherKey)
+			return a
+		}},
+		"tendermint HTTP endpoint": {newAdapter: func(t *testing.T) adapter.Adapter {
+			return newTestTendermintAdapter(t, adaptertest.NewTestClient(), "http://localhost:26657")
+		}},
+		"tendermint invalid query": {newAdapter: func(t *testing.T) adapter.Adapter {
+			return newTestTendermintAdapter(t, adaptertest.NewTestClient(), "ws://localhost:26657/websocket", "tm.event='Tx' OR tx.height=5")
+		}},
+		"tendermint no event selected": {newAdapter: func(t *testing.T) adapter.Adapter {
+			return newTestTendermintAdapter(t, adaptertest.NewTestClient(), "ws://localhost:26657/websocket", "transfer.recipient='cosmos1bob'")
+		}},
+		"tendermint header events": {newAdapter: func(t *testing.T) adapter.Adapter {
+			return newTestTendermintAdapter(t, adaptertest.NewTestClient(), "ws://localhost:26657/websocket", "tm.event='NewBlockHeader'")
+		}},
+		"solana WebSocket endpoint": {newAdapter: func(t *testing.T) adapter.Adapter {
+			return newTestSolanaAdapter(t, adaptertest.NewTestClient(), "ws://localhost:8900")
+		}},
+		"solana safe finality": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestSolanaAdapter(t, adaptertest.NewTestClient(), "http://localhost:8899")
+			a.finality = sourcesv1alpha1.FinalityLevelSafe
+			return a
+		}},
+		"solana invalid mention": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestSolanaAdapter(t, adaptertest.NewTestClient(), "http://localhost:8899")
+			a.mentionsJSON = `["0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"]`
+			return a
+		}},
+		"beacon WebSocket node": {newAdapter: func(t *testing.T) adapter.Adapter {
+			return newTestBeaconAdapter(t, adaptertest.NewTestClient(), "ws://localhost:5052")
+		}, wantErr: "beacon nodes are read over HTTP"},
+		"beacon unknown topic": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestBeaconAdapter(t, adaptertest.NewTestClient(), "http://localhost:5052")
+			a.topicsJSON = `["head", "attestation"]`
+			return a
+		}, wantErr: `unsupported beacon topic "attestation"`},
+		"beacon invalid chain ID": {newAdapter: func(t *testing.T) adapter.Adapter {
+			a := newTestBeaconAdapter(t, adaptertest.NewTestClient(), "http://localhost:5052")
+			a.chainID = "mainnet"
+			return a
+		}, wantErr: `invalid beacon chain ID "mainnet"`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.newAdapter(t).Start(context.Background())
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Start() = %v, want an error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEventDataSchemas(t *testing.T) {
+	for _, tc := range []struct {
+		family sourcesv1alpha1.ChainFamily
+		kind   sourcesv1alpha1.BlockchainEventKind
+		data   interface{}
+		// raw is set for the data sent as returned by the node, of which
+		// the schema only describes the fields the adapter reads.
+		raw bool
+	}{
+		{sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindBlock, ethBlock{}, true},
+		{sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindLog, logEventData{}, false},
+		{sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindReorg, reorgEventData{}, false},
+		{sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindRetracted, retractedEventData{}, false},
+		{sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindBlock, btcBlock{}, true},
+		{sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindTransaction, btcTransaction{}, true},
+		{sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindReorg, reorgEventData{}, false},
+		{sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindRetracted, retractedEventData{}, false},
+		{sourcesv1alpha1.ChainFamilyFabric, sourcesv1alpha1.BlockchainEventKindTransaction, fabricTransaction{}, false},
+		{sourcesv1alpha1.ChainFamilyFabric, sourcesv1alpha1.BlockchainEventKindChaincodeEvent, fabricChaincodeEvent{}, false},
+		{sourcesv1alpha1.ChainFamilyTendermint, sourcesv1alpha1.BlockchainEventKindBlock, tendermintBlock{}, false},
+		{sourcesv1alpha1.ChainFamilyTendermint, sourcesv1alpha1.BlockchainEventKindTransaction, tendermintTransaction{}, false},
+		{sourcesv1alpha1.ChainFamilySolana, sourcesv1alpha1.BlockchainEventKindLog, solanaTransaction{}, false},
+		{sourcesv1alpha1.ChainFamilySolana, sourcesv1alpha1.BlockchainEventKindAccount, solanaAccount{}, false},
+		{sourcesv1alpha1.ChainFamilySolana, sourcesv1alpha1.BlockchainEventKindSlot, solanaSlot{}, false},
+		{sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindHead, beaconHead{}, false},
+		{sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindBlock, beaconBlock{}, false},
+		{sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindFinalizedCheckpoint, beaconFinalizedCheckpoint{}, false},
+		{sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindReorg, beaconReorg{}, false},
+		{sourcesv1alpha1.ChainFamilyBeacon, sourcesv1alpha1.BlockchainEventKindVoluntaryExit, beaconVoluntaryExit{}, false},
+	} {
+		url := sourcesv1alpha1.BlockchainEventSchema(tc.family, tc.kind)
+		t.Run(strings.TrimPrefix(url, sourcesv1alpha1.BlockchainEventSchemaPrefix+"/"), func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join("../../docs/schemas", string(tc.family), string(tc.kind)+".json"))
+			if err != nil {
+				t.Fatalf("schema not published: %v", err)
+			}
+			var schema struct {
+				ID         string                     `json:"$id"`
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []string                   `json:"required"`
+			}
+			if err := json.Unmarshal(b, &schema); err != nil {
+				t.Fatalf("invalid schema: %v", err)
+			}
+			if schema.ID != url {
+				t.Errorf("$id = %s, want %s", schema.ID, url)
+			}
+
+			var fields, required []string
+			typ := reflect.TypeOf(tc.data)
+			for i := 0; i < typ.NumField(); i++ {
+				tag := typ.Field(i).Tag.Get("json")
+				if tag == "" {
+					continue
+				}
+				name := strings.Split(tag, ",")[0]
+				fields = append(fields, name)
+				if !strings.HasSuffix(tag, ",omitempty") {
+					required = append(required, name)
+				}
+				if _, ok := schema.Properties[name]; !ok {
+					t.Errorf("property %s not in the schema", name)
+				}
+			}
+			if tc.raw {
+				return
+			}
+			if len(schema.Properties) != len(fields) {
+				t.Errorf("schema properties = %d, want %v", len(schema.Properties), fields)
+			}
+			if diff := cmp.Diff(required, schema.Required); diff != "" {
+				t.Errorf("unexpected required properties (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+)
+
+// newCheckpointStore returns the store adapters save their checkpoint in:
+// the local file if set, else the ConfigMap, or nil if neither is set.
+// ConfigMap stores are locked by a Lease of the same name held by the pod,
+// so that only one replica, or only one pod of a restart, streams the chain
+// and writes its checkpoint.
+func newCheckpointStore(ctx context.Context, namespace, configMap, file string) checkpoint.Store {
+	switch {
+	case file != "":
+		return checkpoint.NewFileStore(file)
+	case configMap != "":
+		client := kubeclient.Get(ctx)
+		store := checkpoint.NewConfigMapStore(client.CoreV1().ConfigMaps(namespace), configMap)
+		return checkpoint.NewLeaseStore(store, client.CoordinationV1().Leases(namespace), configMap, leaseHolder(ctx), checkpoint.DefaultLeaseDuration)
+	}
+	return nil
+}
+
+// leaseHolder returns the identity the pod holds leases with, its name.
+func leaseHolder(ctx context.Context) string {
+	holder, err := os.Hostname()
+	if err != nil {
+		holder = uuid.NewString()
+		logging.FromContext(ctx).Warnf("Failed to read the pod name, holding the checkpoint lease as %s: %v", holder, err)
+	}
+	return holder
+}
+
+// cursor is the position of an adapter in its chain. The checkpointer of
+// the adapter saves it, and restores it after a restart, without looking
+// into it.
+type cursor interface {
+	// currentCheckpoint returns the position up to which events have been
+	// delivered, or nil if nothing was delivered yet or the chain is not
+	// known yet.
+	currentCheckpoint() *checkpoint.Checkpoint
+	// seek moves the adapter past the position of a saved checkpoint.
+	seek(cp *checkpoint.Checkpoint)
+	// chainProgress returns the chain ID, head and final block the adapter
+	// knows of, and the endpoint it reads from.
+	chainProgress() *checkpoint.Progress
+	// connectionFailure returns the report of a failure to reach the
+	// chain, or nil if err is not one, e.g. when the sink returned an
+	// error.
+	connectionFailure(err error) *checkpoint.Connection
+}
+
+// checkpointer saves the position of an adapter, how it keeps up with the
+// chain and whether it can reach it. Adapters embed it and set its cursor.
+type checkpointer struct {
+	cursor cursor
+
+	checkpoints        checkpoint.Store
+	checkpointInterval time.Duration
+
+	// blockTime is when the last processed block was produced, if known.
+	blockTime time.Time
+	// emitted counts the delivered events by type.
+	emitted map[string]uint64
+
+	// saved is the last saved checkpoint, at savedAt.
+	saved   *checkpoint.Checkpoint
+	savedAt time.Time
+	// connection is the last saved connection report.
+	connection *checkpoint.Connection
+}
+
+// errLeaseLost is returned by the adapters stopped because another replica
+// took over the checkpoint lease.
+var errLeaseLost = errors.New("checkpoint lease lost to another replica")
+
+// hold runs an adapter while the pod holds the lock of the checkpoint
+// store, when it has one: run is only called once the lock is acquired,
+// its context is canceled once the lock is lost, and the lock is released
+// when run returns.
+func (c *checkpointer) hold(ctx context.Context, run func(context.Context) error) error {
+	locker, ok := c.checkpoints.(checkpoint.Locker)
+	if !ok {
+		return run(ctx)
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("Waiting for the checkpoint lease")
+	held, err := locker.Lock(ctx)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to acquire the checkpoint lease: %w", err)
+	}
+	logger.Info("Acquired the checkpoint lease")
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := locker.Unlock(ctx); err != nil {
+			logger.Errorf("Failed to release the checkpoint lease: %v", err)
+		}
+	}()
+
+	if err := run(held); err != nil {
+		return err
+	}
+	if locker.Lost() {
+		return errLeaseLost
+	}
+	return nil
+}
+
+// resume restores the position of the adapter from the saved checkpoint.
+// It reports whether there was one.
+func (c *checkpointer) resume(ctx context.Context) (bool, error) {
+	if c.checkpoints == nil {
+		return false, nil
+	}
+	cp, err := c.checkpoints.Load(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if cp == nil {
+		return false, nil
+	}
+
+	c.cursor.seek(cp)
+	if cp.Progress != nil {
+		// Keep counting the events emitted before the restart.
+		c.emitted = make(map[string]uint64, len(cp.Progress.EmittedEvents))
+		for t, n := range cp.Progress.EmittedEvents {
+			c.emitted[t] = n
+		}
+		if cp.Progress.BlockTime != nil {
+			c.blockTime = *cp.Progress.BlockTime
+		}
+	}
+	c.saved = cp
+	return true, nil
+}
+
+// saveCheckpoint saves the current checkpoint if it, or the head of the
+// chain, moved since the last save, at most once per checkpoint interval
+// unless force is set. Failures are logged, the checkpoint is saved again
+// on the next call.
+func (c *checkpointer) saveCheckpoint(ctx context.Context, force bool) {
+	if c.checkpoints == nil {
+		return
+	}
+	if !force && time.Since(c.savedAt) < c.checkpointInterval {
+		return
+	}
+
+	cp := c.cursor.currentCheckpoint()
+	if cp == nil {
+		return
+	}
+	p := c.progress()
+	if c.saved != nil && samePosition(cp, c.saved) && c.saved.Progress != nil && c.saved.Progress.Head == p.Head {
+		return
+	}
+	cp.Time = time.Now().UTC()
+	cp.Progress = p
+	if err := c.checkpoints.Save(ctx, cp); err != nil {
+		logging.FromContext(ctx).Errorf("Failed to save checkpoint at %d: %v", cp.BlockNumber, err)
+		return
+	}
+	c.saved, c.savedAt = cp, time.Now()
+}
+
+// progress reports how the adapter keeps up with the chain.
+func (c *checkpointer) progress() *checkpoint.Progress {
+	p := c.cursor.chainProgress()
+	if !c.blockTime.IsZero() {
+		t := c.blockTime.UTC()
+		p.BlockTime = &t
+	}
+	if len(c.emitted) > 0 {
+		p.EmittedEvents = make(map[string]uint64, len(c.emitted))
+		for t, n := range c.emitted {
+			p.EmittedEvents[t] = n
+		}
+	}
+	return p
+}
+
+// reportConnection saves whether the adapter could reach the chain, after
+// an attempt that failed with err, or succeeded if err is nil. A report is
+// only saved when it differs from the last one.
+func (c *checkpointer) reportConnection(ctx context.Context, err error) {
+	reporter, ok := c.checkpoints.(checkpoint.Reporter)
+	if !ok {
+		return
+	}
+
+	chainID := c.cursor.chainProgress().ChainID
+	var conn *checkpoint.Connection
+	if err == nil {
+		if chainID == "" {
+			return
+		}
+		conn = &checkpoint.Connection{}
+	} else if conn = c.cursor.connectionFailure(err); conn == nil {
+		return
+	}
+	if conn.ChainID == "" {
+		conn.ChainID = chainID
+	}
+	if c.connection != nil && c.connection.ChainID == conn.ChainID && c.connection.Reason == conn.Reason {
+		return
+	}
+
+	conn.Time = time.Now().UTC()
+	if err := reporter.Report(ctx, conn); err != nil {
+		logging.FromContext(ctx).Errorf("Failed to report the connection to the chain: %v", err)
+		return
+	}
+	c.connection = conn
+}
+
+// samePosition reports whether two checkpoints are at the same position.
+func samePosition(a, b *checkpoint.Checkpoint) bool {
+	return a.BlockNumber == b.BlockNumber && a.BlockHash == b.BlockHash &&
+		sameIndex(a.LogIndex, b.LogIndex) && sameIndex(a.Epoch, b.Epoch)
+}
+
+// sameIndex reports whether two optional indexes are equal.
+func sameIndex(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
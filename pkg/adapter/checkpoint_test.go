@@ -0,0 +1,739 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	pkgtesting "knative.dev/pkg/reconciler/testing"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	"knative.dev/eventing-blockchain/pkg/beacon"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/fabric"
+	"knative.dev/eventing-blockchain/pkg/fabric/fabrictest"
+)
+
+func TestNewCheckpointStore(t *testing.T) {
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+
+	if s := newCheckpointStore(ctx, "default", "", ""); s != nil {
+		t.Errorf("newCheckpointStore() without settings = %T, want nil", s)
+	}
+
+	// A local file is not shared with other pods.
+	s := newCheckpointStore(ctx, "default", "source-checkpoint", filepath.Join(t.TempDir(), "checkpoint.json"))
+	if err := s.Save(context.Background(), &checkpoint.Checkpoint{BlockNumber: 1}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	client := fakekubeclient.Get(ctx)
+	if _, err := client.CoordinationV1().Leases("default").Get(ctx, "source-checkpoint", metav1.GetOptions{}); err == nil {
+		t.Error("Save() to a file took a lease")
+	}
+
+	s = newCheckpointStore(ctx, "default", "source-checkpoint", "")
+	if err := s.Save(context.Background(), &checkpoint.Checkpoint{BlockNumber: 2}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	lease, err := client.CoordinationV1().Leases("default").Get(ctx, "source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() lease = %v", err)
+	}
+	if got, want := *lease.Spec.HolderIdentity, leaseHolder(ctx); got != want {
+		t.Errorf("lease holder = %s, want %s", got, want)
+	}
+	if cp, err := s.Load(context.Background()); err != nil || cp.BlockNumber != 2 {
+		t.Errorf("Load() = %+v, %v, want block 2", cp, err)
+	}
+}
+
+// fakeCursor is a cursor whose position and progress are set by tests.
+type fakeCursor struct {
+	cp       *checkpoint.Checkpoint
+	progress checkpoint.Progress
+	failure  *checkpoint.Connection
+	// sought is the checkpoint last passed to seek.
+	sought *checkpoint.Checkpoint
+}
+
+func (c *fakeCursor) currentCheckpoint() *checkpoint.Checkpoint {
+	if c.cp == nil {
+		return nil
+	}
+	cp := *c.cp
+	return &cp
+}
+
+func (c *fakeCursor) seek(cp *checkpoint.Checkpoint) {
+	c.sought = cp
+}
+
+func (c *fakeCursor) chainProgress() *checkpoint.Progress {
+	p := c.progress
+	return &p
+}
+
+func (c *fakeCursor) connectionFailure(err error) *checkpoint.Connection {
+	if c.failure == nil {
+		return nil
+	}
+	f := *c.failure
+	return &f
+}
+
+// countingStore counts the checkpoints saved in a store.
+type countingStore struct {
+	checkpoint.Store
+	saves int
+}
+
+func (s *countingStore) Save(ctx context.Context, cp *checkpoint.Checkpoint) error {
+	s.saves++
+	return s.Store.Save(ctx, cp)
+}
+
+func TestCheckpointer(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{Store: checkpoint.NewFileStore(filepath.Join(t.TempDir(), "checkpoint.json"))}
+	cursor := &fakeCursor{}
+	c := &checkpointer{cursor: cursor, checkpoints: store, checkpointInterval: time.Hour}
+
+	if ok, err := c.resume(ctx); ok || err != nil {
+		t.Fatalf("resume() without checkpoint = %v, %v, want false", ok, err)
+	}
+	// Nothing is saved before the position is known.
+	c.saveCheckpoint(ctx, true)
+	if store.saves != 0 {
+		t.Fatalf("saveCheckpoint() without position saved %d checkpoints", store.saves)
+	}
+
+	blockTime := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	cursor.cp = &checkpoint.Checkpoint{BlockNumber: 4, BlockHash: "0x04"}
+	cursor.progress = checkpoint.Progress{ChainID: "1", Head: 5, Final: 4, Endpoint: "node"}
+	c.blockTime = blockTime
+	c.emitted = map[string]uint64{ethereumBlockEventType: 2}
+	c.saveCheckpoint(ctx, false)
+	want := &checkpoint.Checkpoint{
+		BlockNumber: 4,
+		BlockHash:   "0x04",
+		Progress: &checkpoint.Progress{
+			ChainID:       "1",
+			Head:          5,
+			Final:         4,
+			BlockTime:     &blockTime,
+			Endpoint:      "node",
+			EmittedEvents: map[string]uint64{ethereumBlockEventType: 2},
+		},
+	}
+	cp, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if diff := cmp.Diff(want, cp, cmpopts.IgnoreFields(checkpoint.Checkpoint{}, "Time")); diff != "" {
+		t.Errorf("unexpected checkpoint (-want, +got) = %v", diff)
+	}
+
+	for _, step := range []struct {
+		name  string
+		move  func()
+		force bool
+		saves int
+	}{{
+		name:  "unchanged",
+		move:  func() {},
+		force: true,
+		saves: 1,
+	}, {
+		name:  "within the interval",
+		move:  func() { cursor.progress.Head = 6 },
+		saves: 1,
+	}, {
+		name:  "head moved",
+		move:  func() {},
+		force: true,
+		saves: 2,
+	}, {
+		name:  "epoch moved",
+		move:  func() { epoch := uint64(1); cursor.cp.Epoch = &epoch },
+		force: true,
+		saves: 3,
+	}} {
+		step.move()
+		c.saveCheckpoint(ctx, step.force)
+		if store.saves != step.saves {
+			t.Errorf("%s: saveCheckpoint() saved %d checkpoints, want %d", step.name, store.saves, step.saves)
+		}
+	}
+
+	// Resuming restores the position and the counted events.
+	cursor = &fakeCursor{}
+	c = &checkpointer{cursor: cursor, checkpoints: store}
+	if ok, err := c.resume(ctx); !ok || err != nil {
+		t.Fatalf("resume() = %v, %v, want true", ok, err)
+	}
+	if cursor.sought == nil || cursor.sought.BlockNumber != 4 || cursor.sought.Epoch == nil {
+		t.Errorf("seek() = %+v, want block 4 of epoch 1", cursor.sought)
+	}
+	if diff := cmp.Diff(map[string]uint64{ethereumBlockEventType: 2}, c.emitted); diff != "" {
+		t.Errorf("unexpected emitted events (-want, +got) = %v", diff)
+	}
+	if !c.blockTime.Equal(blockTime) {
+		t.Errorf("block time = %v, want %v", c.blockTime, blockTime)
+	}
+}
+
+func TestCheckpointerReportsConnection(t *testing.T) {
+	ctx := context.Background()
+	client, store := newReportingStore()
+	cursor := &fakeCursor{}
+	c := &checkpointer{cursor: cursor, checkpoints: store}
+
+	// Reaching a chain is not reported before its ID is known.
+	c.reportConnection(ctx, nil)
+	if c.connection != nil {
+		t.Fatalf("reportConnection() without chain ID = %+v, want no report", c.connection)
+	}
+
+	cursor.progress.ChainID = "1"
+	c.reportConnection(ctx, nil)
+	if diff := cmp.Diff(&checkpoint.Connection{ChainID: "1"}, reportedConnection(t, client), ignoreReportTime); diff != "" {
+		t.Errorf("unexpected connection report (-want, +got) = %v", diff)
+	}
+
+	// Errors that are not connection failures are not reported.
+	c.reportConnection(ctx, errors.New("sink unreachable"))
+	if diff := cmp.Diff(&checkpoint.Connection{ChainID: "1"}, reportedConnection(t, client), ignoreReportTime); diff != "" {
+		t.Errorf("unexpected connection report (-want, +got) = %v", diff)
+	}
+
+	cursor.failure = &checkpoint.Connection{ChainID: "5", Reason: checkpoint.ReasonChainIDMismatch}
+	c.reportConnection(ctx, errors.New("chain mismatch"))
+	want := &checkpoint.Connection{ChainID: "5", Reason: checkpoint.ReasonChainIDMismatch}
+	if diff := cmp.Diff(want, reportedConnection(t, client), ignoreReportTime); diff != "" {
+		t.Errorf("unexpected connection report (-want, +got) = %v", diff)
+	}
+
+	cursor.failure = &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable}
+	c.reportConnection(ctx, errors.New("connection refused"))
+	want = &checkpoint.Connection{ChainID: "1", Reason: checkpoint.ReasonUnreachable}
+	if diff := cmp.Diff(want, reportedConnection(t, client), ignoreReportTime); diff != "" {
+		t.Errorf("unexpected connection report (-want, +got) = %v", diff)
+	}
+	reported := c.connection
+
+	// The same failure is only reported once.
+	c.reportConnection(ctx, errors.New("connection refused"))
+	if c.connection != reported {
+		t.Errorf("reportConnection() reported the same failure again")
+	}
+}
+
+// lockingStore is a store whose lock is acquired once released, and lost
+// when lose is called.
+type lockingStore struct {
+	checkpoint.Store
+	release  chan struct{}
+	lose     context.CancelFunc
+	lost     bool
+	unlocked bool
+}
+
+func (s *lockingStore) Lock(ctx context.Context) (context.Context, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.release:
+	}
+	held, cancel := context.WithCancel(ctx)
+	s.lose = func() {
+		s.lost = true
+		cancel()
+	}
+	return held, nil
+}
+
+func (s *lockingStore) Lost() bool {
+	return s.lost
+}
+
+func (s *lockingStore) Unlock(ctx context.Context) error {
+	s.unlocked = true
+	return nil
+}
+
+func TestCheckpointerHoldsLock(t *testing.T) {
+	store := &lockingStore{Store: checkpoint.NewFileStore(filepath.Join(t.TempDir(), "checkpoint.json")), release: make(chan struct{})}
+	c := &checkpointer{cursor: &fakeCursor{}, checkpoints: store}
+
+	// The adapter does not run before the lock is acquired, and stops once
+	// it is lost.
+	running := make(chan struct{})
+	errs := make(chan error)
+	go func() {
+		errs <- c.hold(context.Background(), func(ctx context.Context) error {
+			close(running)
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	select {
+	case <-running:
+		t.Fatal("adapter ran before the lock was acquired")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(store.release)
+	<-running
+	store.lose()
+	if err := <-errs; !errors.Is(err, errLeaseLost) {
+		t.Errorf("hold() = %v, want %v", err, errLeaseLost)
+	}
+	if !store.unlocked {
+		t.Error("lock not released once the adapter stopped")
+	}
+
+	// Adapters stopped while waiting for the lock do not run.
+	store = &lockingStore{Store: store.Store, release: make(chan struct{})}
+	c.checkpoints = store
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := c.hold(ctx, func(context.Context) error {
+		t.Error("adapter ran without the lock")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("hold() = %v", err)
+	}
+}
+
+func TestCursors(t *testing.T) {
+	index := uint64(2)
+	epoch := uint64(3)
+	tests := []struct {
+		name string
+		// cursor is an adapter at the position of want, and empty the same
+		// adapter before it is positioned.
+		cursor, empty func() cursor
+		want          *checkpoint.Checkpoint
+		progress      *checkpoint.Progress
+	}{{
+		name: "ethereum block",
+		cursor: func() cursor {
+			return &ethereumAdapter{
+				source: "test", rpc: &endpointPool{}, observedChainID: "1", head: 7, final: 5, emitter: emitter{next: 5},
+				recent: []blockRecord{{number: 4, hash: "0x04"}},
+			}
+		},
+		empty:    func() cursor { return &ethereumAdapter{source: "test", reorgWindow: 64} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 4, BlockHash: "0x04"},
+		progress: &checkpoint.Progress{ChainID: "1", Head: 7, Final: 5},
+	}, {
+		name: "ethereum partly delivered block",
+		cursor: func() cursor {
+			return &ethereumAdapter{
+				source: "test", rpc: &endpointPool{}, filter: &logFilter{}, emitter: emitter{next: 5},
+				lastLog: logPosition{block: 5, index: 2}, emittedLog: true,
+			}
+		},
+		empty:    func() cursor { return &ethereumAdapter{source: "test", filter: &logFilter{}, reorgWindow: 64} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 5, LogIndex: &index},
+		progress: &checkpoint.Progress{},
+	}, {
+		name: "ethereum fully delivered logs",
+		cursor: func() cursor {
+			return &ethereumAdapter{
+				source: "test", rpc: &endpointPool{}, filter: &logFilter{}, emitter: emitter{next: 7},
+				lastLog: logPosition{block: 5, index: 2}, emittedLog: true,
+			}
+		},
+		empty:    func() cursor { return &ethereumAdapter{source: "test", filter: &logFilter{}, reorgWindow: 64} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 6},
+		progress: &checkpoint.Progress{},
+	}, {
+		name: "bitcoin",
+		cursor: func() cursor {
+			return &bitcoinAdapter{
+				source: "test", rpc: &endpointPool{}, observedChainID: "main", head: 5, final: 5, emitter: emitter{next: 5},
+				recent: []blockRecord{{number: 4, hash: "04"}},
+			}
+		},
+		empty:    func() cursor { return &bitcoinAdapter{source: "test", reorgWindow: 6} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 4, BlockHash: "04"},
+		progress: &checkpoint.Progress{ChainID: "main", Head: 5, Final: 5},
+	}, {
+		name:     "fabric before positioning",
+		cursor:   func() cursor { return &fabricAdapter{source: "test", channelID: testChannel} },
+		progress: &checkpoint.Progress{ChainID: testChannel},
+	}, {
+		name: "fabric at the genesis block",
+		cursor: func() cursor {
+			return &fabricAdapter{emitter: emitter{positioned: true}, source: "test", channelID: testChannel}
+		},
+		progress: &checkpoint.Progress{ChainID: testChannel},
+	}, {
+		name: "fabric partly delivered block",
+		cursor: func() cursor {
+			return &fabricAdapter{emitter: emitter{positioned: true, next: 5}, source: "test", channelID: testChannel, active: "peer0", head: 6, nextTx: 3}
+		},
+		empty:    func() cursor { return &fabricAdapter{source: "test"} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 5, LogIndex: &index},
+		progress: &checkpoint.Progress{ChainID: testChannel, Head: 6, Final: 6, Endpoint: "peer0"},
+	}, {
+		name: "fabric block",
+		cursor: func() cursor {
+			return &fabricAdapter{emitter: emitter{positioned: true, next: 5}, source: "test", channelID: testChannel}
+		},
+		empty:    func() cursor { return &fabricAdapter{source: "test"} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 4},
+		progress: &checkpoint.Progress{ChainID: testChannel},
+	}, {
+		name:     "beacon before positioning",
+		cursor:   func() cursor { return &beaconAdapter{emitter: emitter{next: 12}, source: "test"} },
+		progress: &checkpoint.Progress{},
+	}, {
+		name: "beacon",
+		cursor: func() cursor {
+			return &beaconAdapter{emitter: emitter{positioned: true, next: 12}, source: "test", observedChainID: "1", active: "node", head: 13}
+		},
+		empty:    func() cursor { return &beaconAdapter{source: "test"} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 11},
+		progress: &checkpoint.Progress{ChainID: "1", Head: 13, Endpoint: "node"},
+	}, {
+		name: "beacon finalized epoch",
+		cursor: func() cursor {
+			return &beaconAdapter{
+				emitter: emitter{positioned: true, next: 128}, source: "test", spec: &beacon.Spec{SlotsPerEpoch: 32}, head: 130,
+				finalized: epoch, finalizedKnown: true,
+			}
+		},
+		empty:    func() cursor { return &beaconAdapter{source: "test"} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 127, Epoch: &epoch},
+		progress: &checkpoint.Progress{Head: 130, Final: 96},
+	}, {
+		name:     "solana before positioning",
+		cursor:   func() cursor { return &solanaAdapter{emitter: emitter{next: 12}, source: "test"} },
+		progress: &checkpoint.Progress{},
+	}, {
+		name: "solana",
+		cursor: func() cursor {
+			return &solanaAdapter{emitter: emitter{positioned: true, next: 12}, source: "test", observedChainID: testSolanaChain, head: 14, committed: 12}
+		},
+		empty:    func() cursor { return &solanaAdapter{source: "test"} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 11},
+		progress: &checkpoint.Progress{ChainID: testSolanaChain, Head: 14, Final: 12},
+	}, {
+		name:     "tendermint before positioning",
+		cursor:   func() cursor { return &tendermintAdapter{emitter: emitter{next: 5}, source: "test"} },
+		progress: &checkpoint.Progress{},
+	}, {
+		name: "tendermint",
+		cursor: func() cursor {
+			return &tendermintAdapter{emitter: emitter{positioned: true, next: 5}, source: "test", observedChainID: testCosmosChain, head: 6}
+		},
+		empty:    func() cursor { return &tendermintAdapter{source: "test"} },
+		want:     &checkpoint.Checkpoint{BlockNumber: 4},
+		progress: &checkpoint.Progress{ChainID: testCosmosChain, Head: 6, Final: 6},
+	}, {
+		name:     "unknown source",
+		cursor:   func() cursor { return &solanaAdapter{emitter: emitter{positioned: true, next: 12}} },
+		progress: &checkpoint.Progress{},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := test.cursor()
+			cp := c.currentCheckpoint()
+			if diff := cmp.Diff(test.want, cp); diff != "" {
+				t.Errorf("unexpected checkpoint (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.progress, c.chainProgress()); diff != "" {
+				t.Errorf("unexpected progress (-want, +got) = %v", diff)
+			}
+			if cp == nil {
+				return
+			}
+
+			// Seeking the checkpoint restores the position.
+			resumed := test.empty()
+			resumed.seek(cp)
+			if diff := cmp.Diff(cp, resumed.currentCheckpoint()); diff != "" {
+				t.Errorf("unexpected checkpoint after seek (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestCursorConnectionFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor cursor
+		err    error
+		want   *checkpoint.Connection
+	}{{
+		name:   "fabric delivery",
+		cursor: &fabricAdapter{},
+		err:    &deliveryError{err: errors.New("sink unreachable")},
+	}, {
+		name:   "fabric forbidden",
+		cursor: &fabricAdapter{},
+		err:    &fabric.StatusError{Status: cb.Status_FORBIDDEN},
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonUnauthorized, Message: "deliver failed with status FORBIDDEN"},
+	}, {
+		name:   "fabric other channel",
+		cursor: &fabricAdapter{channelID: testChannel},
+		err:    &fabric.StatusError{Status: cb.Status_NOT_FOUND},
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonChainIDMismatch, Message: "peer does not serve channel " + testChannel},
+	}, {
+		name:   "fabric bad request",
+		cursor: &fabricAdapter{},
+		err:    &fabric.StatusError{Status: cb.Status_BAD_REQUEST},
+	}, {
+		name:   "fabric peer unavailable",
+		cursor: &fabricAdapter{},
+		err:    fmt.Errorf("deliver: %w", status.Error(codes.Unavailable, "connection refused")),
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable, Message: "deliver: rpc error: code = Unavailable desc = connection refused"},
+	}, {
+		name:   "fabric unauthenticated",
+		cursor: &fabricAdapter{},
+		err:    status.Error(codes.Unauthenticated, "bad certificate"),
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonUnauthorized, Message: "rpc error: code = Unauthenticated desc = bad certificate"},
+	}, {
+		name:   "beacon delivery",
+		cursor: &beaconAdapter{},
+		err:    &deliveryError{err: errors.New("sink unreachable")},
+	}, {
+		name:   "beacon unauthorized",
+		cursor: &beaconAdapter{},
+		err:    &beacon.HTTPError{StatusCode: http.StatusUnauthorized},
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonUnauthorized},
+	}, {
+		name:   "beacon syncing",
+		cursor: &beaconAdapter{},
+		err:    &beacon.HTTPError{StatusCode: http.StatusServiceUnavailable},
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable},
+	}, {
+		name:   "beacon stream lost",
+		cursor: &beaconAdapter{},
+		err:    errStreamLost,
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable, Message: errStreamLost.Error()},
+	}, {
+		name:   "beacon chain mismatch",
+		cursor: &beaconAdapter{},
+		err:    &chainMismatchError{got: "17000", want: "1"},
+		want:   &checkpoint.Connection{ChainID: "17000", Reason: checkpoint.ReasonChainIDMismatch},
+	}, {
+		name:   "solana no endpoint",
+		cursor: &solanaAdapter{},
+		err:    errNoEndpoint,
+		want:   &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable, Message: errNoEndpoint.Error()},
+	}, {
+		name:   "tendermint chain mismatch",
+		cursor: &tendermintAdapter{},
+		err:    &chainMismatchError{got: "otherchain-1", want: testCosmosChain},
+		want:   &checkpoint.Connection{ChainID: "otherchain-1", Reason: checkpoint.ReasonChainIDMismatch},
+	}, {
+		name:   "bitcoin delivery",
+		cursor: &bitcoinAdapter{rpc: &endpointPool{}},
+		err:    &deliveryError{err: errors.New("sink unreachable")},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.cursor.connectionFailure(test.err)
+			if test.want != nil && test.want.Message == "" && got != nil {
+				// The message is the error of the node.
+				got.Message = ""
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected connection failure (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestAdaptersResumeFromCheckpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		// saved is the checkpoint saved before the adapter starts.
+		saved *checkpoint.Checkpoint
+		// run runs the adapter, and returns the subjects of the events it
+		// sent.
+		run  func(t *testing.T, store checkpoint.Store) []string
+		want []string
+	}{{
+		name:  "ethereum",
+		saved: &checkpoint.Checkpoint{BlockNumber: 4, BlockHash: blockHash(0, 4)},
+		run: func(t *testing.T, store checkpoint.Store) []string {
+			node := newFakeNode(1, 3)
+			server := httptest.NewServer(node)
+			defer server.Close()
+			for i := 0; i < 4; i++ {
+				node.mine()
+			}
+
+			ce := adaptertest.NewTestClient()
+			a := newTestEthereumAdapter(t, ce, server.URL)
+			a.checkpoints = store
+			runAdapter(t, a, func() {
+				node.waitForCalls(t, "eth_blockNumber", 2)
+				node.mine()
+				waitForEvents(t, ce, 3)
+			})
+			return sentSubjects(ce)
+		},
+		want: []string{"5", "6", "7"},
+	}, {
+		name:  "bitcoin",
+		saved: &checkpoint.Checkpoint{BlockNumber: 4, BlockHash: btcBlockHash(0, 4)},
+		run: func(t *testing.T, store checkpoint.Store) []string {
+			node := newFakeBitcoind("main", 3)
+			server := httptest.NewServer(node)
+			defer server.Close()
+			for i := 0; i < 3; i++ {
+				node.mine()
+			}
+
+			ce := adaptertest.NewTestClient()
+			a := newTestBitcoinAdapter(t, ce, server.URL)
+			a.checkpoints = store
+			runAdapter(t, a, func() {
+				node.waitForCalls(t, "getblockcount", 1)
+				node.mine()
+				waitForEvents(t, ce, 2)
+			})
+			return sentSubjects(ce)
+		},
+		want: []string{"5", "6"},
+	}, {
+		name: "fabric",
+		// The first transaction of block 1 was delivered.
+		saved: &checkpoint.Checkpoint{BlockNumber: 1, LogIndex: new(uint64)},
+		run: func(t *testing.T, store checkpoint.Store) []string {
+			peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+			peer.AddBlock(fabricTx(1), fabricTx(2), fabricTx(3))
+
+			ce := adaptertest.NewTestClient()
+			a := newTestFabricAdapter(t, ce, peer.Endpoint())
+			a.checkpoints = store
+			runAdapter(t, a, func() {
+				waitForEvents(t, ce, 2)
+				peer.AddBlock(fabricTx(4))
+				waitForEvents(t, ce, 3)
+			})
+			return sentSubjects(ce)
+		},
+		want: []string{fabricTx(2).TxID, fabricTx(3).TxID, fabricTx(4).TxID},
+	}, {
+		name: "beacon",
+		// The events of slot 11 and of epoch 1 were delivered.
+		saved: &checkpoint.Checkpoint{BlockNumber: 11, Epoch: func() *uint64 { e := uint64(1); return &e }()},
+		run: func(t *testing.T, store checkpoint.Store) []string {
+			node := newFakeBeacon(1, 14)
+			rpcURL := serveFakeBeacon(t, node)
+
+			ce := adaptertest.NewTestClient()
+			a := newTestBeaconAdapter(t, ce, rpcURL)
+			a.checkpoints = store
+			runAdapter(t, a, func() {
+				node.waitForCalls(t, "finality_checkpoints", 1)
+				waitForEvents(t, ce, 7)
+				node.produce()
+				waitForEvents(t, ce, 9)
+			})
+			return sentSubjects(ce)
+		},
+		want: []string{"12", "12", "13", "13", "14", "14", "2", "15", "15"},
+	}, {
+		name: "solana",
+		// The events of slot 11 were delivered.
+		saved: &checkpoint.Checkpoint{BlockNumber: 11},
+		run: func(t *testing.T, store checkpoint.Store) []string {
+			node := newFakeSolana(testSolanaGenesis, 10)
+			for i := 0; i < 3; i++ {
+				node.produce(programTx(i))
+			}
+			rpcURL := serveFakeSolana(t, node)
+
+			ce := adaptertest.NewTestClient()
+			a := newTestSolanaAdapter(t, ce, rpcURL)
+			a.mentionsJSON = fmt.Sprintf("[%q]", testSolanaProgram)
+			a.checkpoints = store
+			runAdapter(t, a, func() {
+				waitForEvents(t, ce, 2)
+				node.waitForCalls(t, "logsSubscribe", 1)
+				node.produce(programTx(3))
+				waitForEvents(t, ce, 3)
+			})
+			return sentSubjects(ce)
+		},
+		want: []string{programTx(1).signature, programTx(2).signature, programTx(3).signature},
+	}, {
+		name: "tendermint",
+		// The events of block 2 were delivered.
+		saved: &checkpoint.Checkpoint{BlockNumber: 2},
+		run: func(t *testing.T, store checkpoint.Store) []string {
+			node := newFakeCometBFT(testCosmosChain, 4)
+			server := httptest.NewServer(node)
+			defer server.Close()
+
+			ce := adaptertest.NewTestClient()
+			a := newTestTendermintAdapter(t, ce, wsURL(server))
+			a.checkpoints = store
+			runAdapter(t, a, func() {
+				node.waitForCalls(t, "subscribe", 2)
+				node.commit()
+				waitForEvents(t, ce, 3)
+			})
+			return sentSubjects(ce)
+		},
+		want: []string{"3", "4", "5"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := checkpoint.NewFileStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+			if err := store.Save(context.Background(), test.saved); err != nil {
+				t.Fatalf("Save() = %v", err)
+			}
+
+			got := test.run(t, store)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+			}
+
+			// The checkpoint moved past the emitted events.
+			cp, err := store.Load(context.Background())
+			if err != nil {
+				t.Fatalf("Load() = %v", err)
+			}
+			if cp.BlockNumber < test.saved.BlockNumber || cp.Progress == nil || len(cp.Progress.EmittedEvents) == 0 {
+				t.Errorf("checkpoint = %+v, want it moved past %d with emitted events", cp, test.saved.BlockNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// emitter delivers the events of an adapter to its sink and tracks the
+// block, or slot, it emits next. Adapters embed it and count on its
+// checkpointer to save their position.
+type emitter struct {
+	checkpointer
+
+	logger *zap.SugaredLogger
+	client cloudevents.Client
+
+	filtersJSON string
+	// eventFilters are the filters events must pass to be delivered, parsed
+	// from filtersJSON by setupFilters.
+	eventFilters eventFilters
+
+	// next is the number of the block, or slot, to emit next, once
+	// positioned.
+	next       uint64
+	positioned bool
+	// endBlock is the number of the last block, or slot, to emit, if any.
+	endBlock *uint64
+}
+
+// newEmitter returns the emitter of an adapter configured by env.
+func newEmitter(ctx context.Context, env *chainEnvConfig, ceClient cloudevents.Client) emitter {
+	return emitter{
+		checkpointer: checkpointer{
+			checkpoints:        newCheckpointStore(ctx, env.Namespace, env.EnvCheckpointConfigMap, env.EnvCheckpointFile),
+			checkpointInterval: env.EnvCheckpointInterval,
+		},
+		logger:      logging.FromContext(ctx),
+		client:      ceClient,
+		filtersJSON: env.EnvFilters,
+		endBlock:    env.EnvEndBlock,
+	}
+}
+
+// setupFilters parses the filters events must pass to be delivered.
+func (e *emitter) setupFilters() error {
+	filters, err := parseEventFilters(e.filtersJSON)
+	if err != nil {
+		return err
+	}
+	e.eventFilters = filters
+	return nil
+}
+
+// reachedEnd reports whether the events of all the blocks up to the end
+// block have been emitted.
+func (e *emitter) reachedEnd() bool {
+	return e.endBlock != nil && e.positioned && e.next > *e.endBlock
+}
+
+// pastEnd reports whether a block is after the end block.
+func (e *emitter) pastEnd(number uint64) bool {
+	return e.endBlock != nil && number > *e.endBlock
+}
+
+// send delivers an event, and counts it once acknowledged.
+func (e *emitter) send(ctx context.Context, event cloudevents.Event) cloudevents.Result {
+	result := e.client.Send(ctx, event)
+	if !cloudevents.IsACK(result) {
+		return &deliveryError{err: result}
+	}
+	if e.emitted == nil {
+		e.emitted = make(map[string]uint64)
+	}
+	e.emitted[event.Type()]++
+	return result
+}
+
+// deliver sets the extensions and the data of an event, and sends it unless
+// filtered out.
+func (e *emitter) deliver(ctx context.Context, event cloudevents.Event, ext chainExtensions, data interface{}) error {
+	if err := ext.apply(&event); err != nil {
+		return fmt.Errorf("failed to set event extensions: %w", err)
+	}
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return fmt.Errorf("failed to set event data: %w", err)
+	}
+	if e.eventFilters.drops(ctx, e.logger, event) {
+		return nil
+	}
+	if result := e.send(ctx, event); !cloudevents.IsACK(result) {
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestEmitterDeliver(t *testing.T) {
+	resetMetrics()
+	ce := adaptertest.NewTestClient()
+	e := &emitter{
+		logger:      logtesting.TestLogger(t),
+		client:      ce,
+		filtersJSON: `[{"name": "even", "cel": "event.blocknumber % 2 == 0"}]`,
+	}
+	if err := e.setupFilters(); err != nil {
+		t.Fatal("setupFilters() =", err)
+	}
+
+	for _, number := range []uint64{1, 2} {
+		number := number
+		event := cloudevents.NewEvent()
+		event.SetID("block")
+		event.SetType(ethereumBlockEventType)
+		event.SetSource("eip155:1")
+		if err := e.deliver(context.Background(), event, chainExtensions{blockNumber: &number}, map[string]uint64{"number": number}); err != nil {
+			t.Fatalf("deliver(%d) = %v", number, err)
+		}
+	}
+	if sent := ce.Sent(); len(sent) != 1 {
+		t.Fatalf("sent %d events, want 1", len(sent))
+	}
+	if got := e.emitted[ethereumBlockEventType]; got != 1 {
+		t.Errorf("emitted = %d, want 1", got)
+	}
+
+	// An event the sink does not accept is not counted.
+	two := uint64(2)
+	event := cloudevents.NewEvent()
+	event.SetID("block")
+	event.SetType("unit.sendFail")
+	event.SetSource("eip155:1")
+	err := e.deliver(context.Background(), event, chainExtensions{blockNumber: &two}, nil)
+	var delivery *deliveryError
+	if !errors.As(err, &delivery) {
+		t.Fatalf("deliver() = %v, want a delivery error", err)
+	}
+	if got := e.emitted["unit.sendFail"]; got != 0 {
+		t.Errorf("emitted = %d, want 0", got)
+	}
+}
+
+func TestEmitterEnd(t *testing.T) {
+	end := uint64(10)
+	e := &emitter{endBlock: &end, next: 11}
+	if e.reachedEnd() {
+		t.Error("reachedEnd() = true before positioning")
+	}
+	e.positioned = true
+	if !e.reachedEnd() {
+		t.Error("reachedEnd() = false past the end block")
+	}
+	if e.pastEnd(10) || !e.pastEnd(11) {
+		t.Errorf("pastEnd(10), pastEnd(11) = %t, %t, want false, true", e.pastEnd(10), e.pastEnd(11))
+	}
+
+	e.endBlock = nil
+	if e.reachedEnd() || e.pastEnd(11) {
+		t.Error("an emitter without end block reached its end")
+	}
+}
@@ -0,0 +1,509 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"knative.dev/eventing/pkg/adapter/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/evm"
+)
+
+// ethereumBlockEventType is the CloudEvent type of the events emitted for
+// every new block.
+var ethereumBlockEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindBlock)
+
+type ethereumEnvConfig struct {
+	chainEnvConfig
+
+	// Environment variable containing how often the health of the endpoints
+	// is checked, when there are several
+	EnvHealthCheckInterval time.Duration `envconfig:"BLOCKCHAIN_HEALTH_CHECK_INTERVAL" default:"30s"`
+	// Environment variable containing the number of blocks an endpoint may
+	// lag behind the most advanced one before being considered unhealthy
+	EnvMaxHeadLag uint64 `envconfig:"BLOCKCHAIN_MAX_HEAD_LAG" default:"5"`
+	// Environment variable containing the share of failed requests above
+	// which an endpoint is considered unhealthy
+	EnvMaxErrorRate float64 `envconfig:"BLOCKCHAIN_MAX_ERROR_RATE" default:"0.5"`
+	// Environment variable containing the ingestion mode, polling or
+	// streaming from a WebSocket endpoint
+	EnvMode string `envconfig:"BLOCKCHAIN_MODE" default:"polling"`
+	// Environment variable containing how often the node is polled for new blocks
+	EnvPollInterval time.Duration `envconfig:"BLOCKCHAIN_POLL_INTERVAL" default:"12s"`
+	// Environment variable containing the JSON encoded contract subscription,
+	// when receiving contract logs instead of blocks
+	EnvContracts string `envconfig:"BLOCKCHAIN_CONTRACTS"`
+	// Environment variable containing the JSON ABI used to decode contract logs
+	EnvABI string `envconfig:"BLOCKCHAIN_ABI"`
+	// Environment variable containing how many recent blocks are tracked to
+	// retract their events should they be orphaned by a reorg
+	EnvReorgWindow uint64 `envconfig:"BLOCKCHAIN_REORG_WINDOW" default:"64"`
+	// Environment variable containing the finality level blocks must reach
+	// before being emitted, latest, confirmed, safe or finalized
+	EnvFinality string `envconfig:"BLOCKCHAIN_FINALITY" default:"latest"`
+	// Environment variable containing the number of confirmations of the
+	// confirmed finality level
+	EnvConfirmations uint64 `envconfig:"BLOCKCHAIN_CONFIRMATIONS" default:"12"`
+	// Environment variable containing the largest number of blocks whose
+	// logs are requested at once
+	EnvLogsChunkSize uint64 `envconfig:"BLOCKCHAIN_LOGS_CHUNK_SIZE" default:"2000"`
+}
+
+// NewEthereumEnvConfig function reads env variables defined in ethereumEnvConfig
+// structure and returns accessor interface
+func NewEthereumEnvConfig() adapter.EnvConfigAccessor {
+	return &ethereumEnvConfig{}
+}
+
+// rpcCaller calls JSON-RPC methods, over HTTP or over a WebSocket connection.
+type rpcCaller interface {
+	Call(ctx context.Context, result interface{}, method string, params ...interface{}) error
+}
+
+// ethereumAdapter polls or subscribes to an Ethereum-compatible JSON-RPC
+// endpoint and converts new blocks, or the contract logs they contain, to
+// CloudEvents
+type ethereumAdapter struct {
+	emitter
+
+	rpc *endpointPool
+
+	rpcURL              string
+	endpointsJSON       string
+	chainID             string
+	healthCheckInterval time.Duration
+	maxHeadLag          uint64
+	maxErrorRate        float64
+	mode                sourcesv1alpha1.IngestionMode
+	pollInterval        time.Duration
+	minReconnectDelay   time.Duration
+	contractsJSON       string
+	abiJSON             string
+	reorgWindow         uint64
+	finality            sourcesv1alpha1.FinalityLevel
+	confirmations       uint64
+	startBlock          *uint64
+	maxChunkSize        uint64
+
+	// filter selects the contract logs to emit. Blocks are emitted when it
+	// is nil.
+	filter *logFilter
+	// abi decodes the arguments of contract logs, when given.
+	abi *evm.ABI
+
+	// source is the CloudEvent source of the emitted events, known once the
+	// chain ID has been read from the node.
+	source string
+	// observedChainID is the chain ID read from the node, in decimal.
+	observedChainID string
+	// head is the number of the newest block seen, final the number of the
+	// newest block final enough to be emitted.
+	head  uint64
+	final uint64
+	// lastLog is the position of the last emitted log, if emittedLog.
+	lastLog    logPosition
+	emittedLog bool
+	// recent are the blocks processed within the reorg window, oldest first.
+	recent []blockRecord
+	// pending are the reorg and retraction events not delivered yet.
+	pending []cloudevents.Event
+	// chunkSize is the number of blocks whose logs are currently requested
+	// at once, shrunk when the node reports too many results.
+	chunkSize uint64
+}
+
+// NewEthereumAdapter returns the instance of ethereumAdapter that implements adapter.Adapter interface
+func NewEthereumAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	env := processed.(*ethereumEnvConfig)
+
+	a := &ethereumAdapter{
+		emitter:             newEmitter(ctx, &env.chainEnvConfig, ceClient),
+		rpcURL:              env.EnvRPCURL,
+		endpointsJSON:       env.EnvEndpoints,
+		chainID:             env.EnvChainID,
+		healthCheckInterval: env.EnvHealthCheckInterval,
+		maxHeadLag:          env.EnvMaxHeadLag,
+		maxErrorRate:        env.EnvMaxErrorRate,
+		mode:                sourcesv1alpha1.IngestionMode(env.EnvMode),
+		pollInterval:        env.EnvPollInterval,
+		minReconnectDelay:   minReconnectDelay,
+		contractsJSON:       env.EnvContracts,
+		abiJSON:             env.EnvABI,
+		reorgWindow:         env.EnvReorgWindow,
+		finality:            sourcesv1alpha1.FinalityLevel(env.EnvFinality),
+		confirmations:       env.EnvConfirmations,
+		startBlock:          env.EnvStartBlock,
+		maxChunkSize:        env.EnvLogsChunkSize,
+		chunkSize:           env.EnvLogsChunkSize,
+	}
+	a.cursor = a
+	return a
+}
+
+func (a *ethereumAdapter) Start(ctx context.Context) error {
+	if err := a.setupEndpoints(); err != nil {
+		return err
+	}
+	if err := a.setupContracts(); err != nil {
+		return err
+	}
+	if err := a.setupFilters(); err != nil {
+		return err
+	}
+
+	return a.hold(ctx, func(ctx context.Context) error {
+		if len(a.rpc.endpoints) > 1 {
+			a.rpc.verifyAll(ctx)
+			go a.rpc.run(ctx, a.healthCheckInterval)
+		}
+
+		if a.mode == sourcesv1alpha1.IngestionModeStreaming {
+			return a.stream(ctx)
+		}
+		return a.pollBlocks(ctx)
+	})
+}
+
+// init reads the chain ID the first time the adapter connects to the node,
+// checks that it is the expected one, and resumes from the saved checkpoint.
+// Without a checkpoint, blocks are emitted from the start block, or from the
+// one following the current head.
+func (a *ethereumAdapter) init(ctx context.Context, rpc rpcCaller) error {
+	if a.source != "" {
+		return nil
+	}
+
+	var chainID hexUint64
+	if err := rpc.Call(ctx, &chainID, "eth_chainId"); err != nil {
+		return fmt.Errorf("failed to read chain ID: %w", err)
+	}
+	if a.chainID != "" && strconv.FormatUint(uint64(chainID), 10) != a.chainID {
+		return &chainMismatchError{got: strconv.FormatUint(uint64(chainID), 10), want: a.chainID}
+	}
+	head, err := blockNumber(ctx, rpc)
+	if err != nil {
+		return fmt.Errorf("failed to read head block number: %w", err)
+	}
+	a.observeHead(head)
+
+	resumed, err := a.resume(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resumed:
+		a.logger.Infof("Resuming from checkpoint at block %d, head is %d", a.next, head)
+	case a.startBlock != nil:
+		a.next = *a.startBlock
+		a.logger.Infof("Backfilling from block %d, head is %d", a.next, head)
+	default:
+		a.next = head + 1
+	}
+	a.positioned = true
+	a.observedChainID = strconv.FormatUint(uint64(chainID), 10)
+	a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilyEVM, a.observedChainID)
+	return nil
+}
+
+// pollBlocks polls the node for new blocks until ctx is done.
+func (a *ethereumAdapter) pollBlocks(ctx context.Context) error {
+	if err := a.init(ctx, a.rpc); err != nil {
+		a.reportConnection(ctx, err)
+		return err
+	}
+	a.reportConnection(ctx, nil)
+
+	a.logger.Infof("Polling chain %s every %s starting at block %d", a.source, a.pollInterval, a.next)
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.saveCheckpoint(context.Background(), true)
+			a.logger.Infof("Polling stopped")
+			return nil
+		case <-ticker.C:
+			err := a.poll(ctx)
+			if err != nil && ctx.Err() == nil {
+				a.logger.Errorf("Polling for new blocks failed: %v", err)
+			}
+			if ctx.Err() == nil {
+				a.reportConnection(ctx, err)
+			}
+			if a.reachedEnd() {
+				a.saveCheckpoint(ctx, true)
+				a.logger.Infof("Reached end block %d, polling stopped", *a.endBlock)
+				<-ctx.Done()
+				return nil
+			}
+			a.saveCheckpoint(ctx, false)
+		}
+	}
+}
+
+// poll emits an event for every block between the last emitted one and the
+// newest block final enough, after retracting the events of blocks orphaned
+// since the last poll.
+func (a *ethereumAdapter) poll(ctx context.Context) error {
+	return a.catchUpHead(ctx, a.rpc)
+}
+
+// catchUpHead emits an event for every block, or every matching contract
+// log, up to the newest block final enough, after retracting the events of
+// orphaned blocks.
+func (a *ethereumAdapter) catchUpHead(ctx context.Context, rpc rpcCaller) error {
+	head, err := blockNumber(ctx, rpc)
+	if err != nil {
+		return fmt.Errorf("failed to read head block number: %w", err)
+	}
+	a.observeHead(head)
+	final, ok, err := a.finalHead(ctx, rpc, head)
+	if err != nil {
+		return err
+	}
+	if err := a.checkReorg(ctx, rpc); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return a.catchUp(ctx, rpc, final)
+}
+
+// catchUp emits an event for every block, or every matching contract log,
+// from the next block up to and including head. Blocks are emitted in order
+// and a block that could not be delivered is retried on the next call. A
+// block that does not extend the last emitted one reveals a reorg, which is
+// handled before going on.
+func (a *ethereumAdapter) catchUp(ctx context.Context, rpc rpcCaller, head uint64) error {
+	a.observeFinal(head)
+	if a.filter != nil {
+		return a.catchUpLogs(ctx, rpc, head)
+	}
+	for a.next <= head {
+		block, err := blockByNumber(ctx, rpc, a.next)
+		if err != nil {
+			return err
+		}
+		if !a.extends(block) {
+			if err := a.checkReorg(ctx, rpc); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := a.emitBlock(ctx, block); err != nil {
+			return fmt.Errorf("failed to emit block %d: %w", a.next, err)
+		}
+		a.next++
+		a.saveCheckpoint(ctx, false)
+	}
+	return nil
+}
+
+func blockNumber(ctx context.Context, rpc rpcCaller) (uint64, error) {
+	var head hexUint64
+	if err := rpc.Call(ctx, &head, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(head), nil
+}
+
+// observeHead records the number of a head block, unless a newer one was
+// already seen.
+func (a *ethereumAdapter) observeHead(number uint64) {
+	if number > a.head {
+		a.head = number
+	}
+}
+
+// observeFinal records the number of a block final enough to be emitted,
+// unless a newer one was already seen.
+func (a *ethereumAdapter) observeFinal(number uint64) {
+	if number > a.final {
+		a.final = number
+	}
+}
+
+func blockByNumber(ctx context.Context, rpc rpcCaller, number uint64) (*ethBlock, error) {
+	return getBlock(ctx, rpc, hexUint64(number))
+}
+
+// getBlock returns the block identified by a number or a block tag, without
+// its transactions.
+func getBlock(ctx context.Context, rpc rpcCaller, numberOrTag interface{}) (*ethBlock, error) {
+	var raw json.RawMessage
+	if err := rpc.Call(ctx, &raw, "eth_getBlockByNumber", numberOrTag, false); err != nil {
+		return nil, err
+	}
+	return parseBlock(raw)
+}
+
+func (a *ethereumAdapter) emitBlock(ctx context.Context, block *ethBlock) error {
+	event := cloudevents.NewEvent()
+	event.SetID(block.Hash)
+	event.SetType(ethereumBlockEventType)
+	event.SetSource(a.source)
+	event.SetSubject(strconv.FormatUint(uint64(block.Number), 10))
+	event.SetTime(time.Unix(int64(block.Timestamp), 0))
+	event.SetExtension(finalityExtension, string(a.finality))
+	number := uint64(block.Number)
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &number,
+		blockHash:   block.Hash,
+	}
+	if err := ext.apply(&event); err != nil {
+		return fmt.Errorf("failed to set event extensions: %w", err)
+	}
+
+	if err := event.SetData(cloudevents.ApplicationJSON, []byte(block.raw)); err != nil {
+		return fmt.Errorf("failed to set event data: %w", err)
+	}
+
+	if a.eventFilters.drops(ctx, a.logger, event) {
+		a.blockTime = event.Time()
+		a.record(uint64(block.Number), block.Hash, block.ParentHash, nil)
+		return nil
+	}
+	if result := a.send(ctx, event); !cloudevents.IsACK(result) {
+		return result
+	}
+	a.blockTime = event.Time()
+	a.record(uint64(block.Number), block.Hash, block.ParentHash, &sentEvent{
+		id:        event.ID(),
+		eventType: event.Type(),
+		source:    event.Source(),
+		subject:   event.Subject(),
+		ext:       ext,
+	})
+	return nil
+}
+
+// errBlockNotFound is returned for blocks the node does not know of.
+var errBlockNotFound = errors.New("block not found")
+
+// ethBlock holds the fields of a block returned by eth_getBlockByNumber that
+// the adapter needs, along with the raw JSON object sent as event data.
+type ethBlock struct {
+	Number     hexUint64 `json:"number"`
+	Hash       string    `json:"hash"`
+	ParentHash string    `json:"parentHash"`
+	Timestamp  hexUint64 `json:"timestamp"`
+
+	raw json.RawMessage
+}
+
+func parseBlock(raw json.RawMessage) (*ethBlock, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errBlockNotFound
+	}
+	block := &ethBlock{raw: raw}
+	if err := json.Unmarshal(raw, block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block: %w", err)
+	}
+	return block, nil
+}
+
+// hexUint64 is an unsigned integer encoded as a 0x-prefixed hex string, the
+// way quantities are exchanged with Ethereum JSON-RPC endpoints.
+type hexUint64 uint64
+
+func (h hexUint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("0x%x", uint64(h)))
+}
+
+func (h *hexUint64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("quantity %q is missing the 0x prefix", s)
+	}
+	v, err := strconv.ParseUint(s[2:], 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quantity %q: %w", s, err)
+	}
+	*h = hexUint64(v)
+	return nil
+}
+
+// currentCheckpoint returns the position up to which events have been
+// delivered, or nil if nothing was delivered yet.
+func (a *ethereumAdapter) currentCheckpoint() *checkpoint.Checkpoint {
+	if a.source == "" {
+		return nil
+	}
+	cp := &checkpoint.Checkpoint{}
+	switch {
+	case a.filter != nil && a.emittedLog && a.lastLog.block >= a.next:
+		// Logs of the block are partly delivered.
+		index := a.lastLog.index
+		cp.BlockNumber, cp.LogIndex = a.lastLog.block, &index
+	case a.next > 0:
+		cp.BlockNumber = a.next - 1
+	default:
+		return nil
+	}
+
+	for i := len(a.recent) - 1; i >= 0; i-- {
+		if a.recent[i].number == cp.BlockNumber {
+			cp.BlockHash = a.recent[i].hash
+			break
+		}
+	}
+	return cp
+}
+
+// seek moves the adapter past the block, or the log, of a checkpoint.
+func (a *ethereumAdapter) seek(cp *checkpoint.Checkpoint) {
+	if cp.LogIndex != nil {
+		a.next = cp.BlockNumber
+		a.lastLog = logPosition{block: cp.BlockNumber, index: *cp.LogIndex}
+	} else {
+		a.next = cp.BlockNumber + 1
+		a.lastLog = logPosition{block: cp.BlockNumber, index: math.MaxUint64}
+	}
+	a.emittedLog = true
+	if cp.BlockHash != "" {
+		// Detect the checkpointed block being orphaned while the adapter
+		// was down.
+		a.record(cp.BlockNumber, cp.BlockHash, "", nil)
+	}
+}
+
+// chainProgress reports how the adapter keeps up with the chain.
+func (a *ethereumAdapter) chainProgress() *checkpoint.Progress {
+	return &checkpoint.Progress{
+		ChainID:  a.observedChainID,
+		Head:     a.head,
+		Final:    a.final,
+		Endpoint: a.rpc.activeName(),
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+// chainMismatchError is returned when a node serves another chain than the
+// expected one.
+type chainMismatchError struct {
+	// endpoint is the name of the endpoint, empty for the node the adapter
+	// is connected to.
+	endpoint string
+	got      string
+	want     string
+}
+
+func (e *chainMismatchError) Error() string {
+	if e.endpoint == "" {
+		return fmt.Sprintf("node serves chain %s instead of %s", e.got, e.want)
+	}
+	return fmt.Sprintf("endpoint %s serves chain %s instead of %s", e.endpoint, e.got, e.want)
+}
+
+// deliveryError is returned when the sink did not accept an event. It tells
+// nothing about the connection to the chain.
+type deliveryError struct {
+	err error
+}
+
+func (e *deliveryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *deliveryError) Unwrap() error {
+	return e.err
+}
+
+// connectionFailure returns the report of a failure to reach the chain, or
+// nil if err is not one, e.g. when the sink or the node returned an error.
+func (a *ethereumAdapter) connectionFailure(err error) *checkpoint.Connection {
+	return connectionFailure(a.rpc, err)
+}
+
+// connectionFailure returns the report of a failure to reach the chain
+// through the endpoints of pool, or nil if err is not one.
+func connectionFailure(pool *endpointPool, err error) *checkpoint.Connection {
+	if errors.Is(err, errNoEndpoint) && pool != nil {
+		// Endpoints of other chains are not usable either, tell so.
+		if mismatch := pool.mismatch(); mismatch != nil {
+			err = mismatch
+		}
+	}
+
+	var (
+		delivery *deliveryError
+		mismatch *chainMismatchError
+		httpErr  *jsonrpc.HTTPError
+		netErr   net.Error
+	)
+	switch {
+	case errors.As(err, &delivery):
+		return nil
+	case errors.As(err, &mismatch):
+		return &checkpoint.Connection{
+			ChainID: mismatch.got,
+			Reason:  checkpoint.ReasonChainIDMismatch,
+			Message: err.Error(),
+		}
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden):
+		return &checkpoint.Connection{Reason: checkpoint.ReasonUnauthorized, Message: err.Error()}
+	case errors.As(err, &httpErr), errors.As(err, &netErr), errors.Is(err, errNoEndpoint), errors.Is(err, jsonrpc.ErrClosed):
+		return &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable, Message: err.Error()}
+	}
+	return nil
+}
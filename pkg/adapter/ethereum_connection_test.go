@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+// reportedConnection returns the connection report saved in the ConfigMap of
+// a store created by newReportingStore.
+func reportedConnection(t *testing.T, client *fake.Clientset) *checkpoint.Connection {
+	t.Helper()
+	cm, err := client.CoreV1().ConfigMaps("default").Get(context.Background(), "source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	c, err := checkpoint.ConnectionFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("ConnectionFromConfigMap() = %v", err)
+	}
+	return c
+}
+
+func newReportingStore() (*fake.Clientset, checkpoint.Store) {
+	client := fake.NewSimpleClientset()
+	return client, checkpoint.NewConfigMapStore(client.CoreV1().ConfigMaps("default"), "source-checkpoint")
+}
+
+var ignoreReportTime = cmpopts.IgnoreFields(checkpoint.Connection{}, "Time")
+
+func TestEthereumAdapterReportsChainIDMismatch(t *testing.T) {
+	server := httptest.NewServer(newFakeNode(5, 3))
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.chainID = "1"
+	client, store := newReportingStore()
+	a.checkpoints = store
+
+	if err := a.Start(context.Background()); err == nil {
+		t.Fatal("Start() = nil, want an error")
+	}
+	want := &checkpoint.Connection{
+		ChainID: "5",
+		Reason:  checkpoint.ReasonChainIDMismatch,
+		Message: fmt.Sprintf("failed to read chain ID: endpoint %s serves chain 5 instead of 1", server.URL),
+	}
+	if diff := cmp.Diff(want, reportedConnection(t, client), ignoreReportTime); diff != "" {
+		t.Errorf("unexpected connection report (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterReportsUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	client, store := newReportingStore()
+	a.checkpoints = store
+
+	if err := a.Start(context.Background()); err == nil {
+		t.Fatal("Start() = nil, want an error")
+	}
+	if got := reportedConnection(t, client).Reason; got != checkpoint.ReasonUnauthorized {
+		t.Errorf("reported reason = %q, want %q", got, checkpoint.ReasonUnauthorized)
+	}
+}
+
+func TestEthereumAdapterReportsConnection(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	client, store := newReportingStore()
+	a.checkpoints = store
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_blockNumber", 2)
+		if diff := cmp.Diff(&checkpoint.Connection{ChainID: "1"}, reportedConnection(t, client), ignoreReportTime); diff != "" {
+			t.Errorf("unexpected connection report (-want, +got) = %v", diff)
+		}
+
+		// The node going down is reported on the next poll.
+		server.Close()
+		deadline := time.Now().Add(5 * time.Second)
+		for reportedConnection(t, client).Reason != checkpoint.ReasonUnreachable {
+			if time.Now().After(deadline) {
+				t.Fatalf("connection report = %+v, want an unreachable endpoint", reportedConnection(t, client))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := reportedConnection(t, client).ChainID; got != "1" {
+			t.Errorf("reported chain ID = %q, want the last known one", got)
+		}
+	})
+}
+
+func TestConnectionFailure(t *testing.T) {
+	a := &ethereumAdapter{}
+	for name, tc := range map[string]struct {
+		err  error
+		want string
+	}{
+		"delivery": {
+			err: &deliveryError{err: errors.New("sink unreachable")},
+		},
+		"node error": {
+			err: fmt.Errorf("failed to read head block number: %w", &jsonrpc.Error{Code: -32000, Message: "header not found"}),
+		},
+		"no endpoint": {
+			err:  errNoEndpoint,
+			want: checkpoint.ReasonUnreachable,
+		},
+		"server error": {
+			err:  fmt.Errorf("eth_blockNumber request failed with %w", &jsonrpc.HTTPError{StatusCode: http.StatusBadGateway}),
+			want: checkpoint.ReasonUnreachable,
+		},
+		"forbidden": {
+			err:  fmt.Errorf("eth_blockNumber request failed with %w", &jsonrpc.HTTPError{StatusCode: http.StatusForbidden}),
+			want: checkpoint.ReasonUnauthorized,
+		},
+		"chain mismatch": {
+			err:  &chainMismatchError{got: "5", want: "1"},
+			want: checkpoint.ReasonChainIDMismatch,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := a.connectionFailure(tc.err)
+			if tc.want == "" {
+				if got != nil {
+					t.Errorf("connectionFailure() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Reason != tc.want {
+				t.Errorf("connectionFailure() = %+v, want reason %q", got, tc.want)
+			}
+		})
+	}
+}
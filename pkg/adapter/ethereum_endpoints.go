@@ -0,0 +1,496 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+const (
+	// errorDecay is the weight of the past requests of an endpoint in its
+	// error rate, so that recent failures matter more than old ones.
+	errorDecay = 0.9
+	// healthCheckTimeout bounds the time an endpoint has to answer a health
+	// check.
+	healthCheckTimeout = 10 * time.Second
+)
+
+// errNoEndpoint is returned when no endpoint can serve a request.
+var errNoEndpoint = errors.New("no usable RPC endpoint")
+
+// endpointConfig is an endpoint as given to the adapter.
+type endpointConfig struct {
+	URL      string `json:"url"`
+	Priority int32  `json:"priority,omitempty"`
+	// Credentials is the value of the Authorization header sent to the
+	// endpoint, if any.
+	Credentials string `json:"-"`
+}
+
+// parseEndpoints returns the endpoints listed as JSON, along with the
+// credentials read from the BLOCKCHAIN_ENDPOINT_<index>_CREDENTIALS
+// environment variables.
+func parseEndpoints(endpointsJSON string) ([]endpointConfig, error) {
+	var configs []endpointConfig
+	if err := json.Unmarshal([]byte(endpointsJSON), &configs); err != nil {
+		return nil, fmt.Errorf("invalid RPC endpoints: %w", err)
+	}
+	for i := range configs {
+		if configs[i].URL == "" {
+			return nil, fmt.Errorf("RPC endpoint %d has no URL", i)
+		}
+		configs[i].Credentials = os.Getenv(fmt.Sprintf("BLOCKCHAIN_ENDPOINT_%d_CREDENTIALS", i))
+	}
+	return configs, nil
+}
+
+// endpoint is a JSON-RPC endpoint, along with its health.
+type endpoint struct {
+	endpointConfig
+	// client calls HTTP endpoints. WebSocket endpoints are dialed for
+	// every call made outside of a stream.
+	client *jsonrpc.Client
+
+	// The fields below are guarded by the mutex of the pool.
+
+	// verified is true once the endpoint reported the chain ID of the
+	// pool, mismatched once it reported another one.
+	verified   bool
+	mismatched bool
+	// servedChainID is the chain ID reported by a mismatched endpoint.
+	servedChainID string
+	// head is the head block number reported by the last health check.
+	head uint64
+	// latency is a moving average of the response times.
+	latency time.Duration
+	// requests and failures are decaying counts of the requests sent to
+	// the endpoint and of the ones that failed.
+	requests float64
+	failures float64
+	// healthy is false when the last request failed, or when the last
+	// health check found the endpoint lagging or failing too often.
+	healthy bool
+}
+
+func newEndpoint(c endpointConfig) *endpoint {
+	e := &endpoint{endpointConfig: c, healthy: true}
+	if !isWebSocket(c.URL) {
+		e.client = jsonrpc.NewClient(c.URL, e.options()...)
+	}
+	return e
+}
+
+func isWebSocket(u string) bool {
+	return strings.HasPrefix(u, "ws://") || strings.HasPrefix(u, "wss://")
+}
+
+func (e *endpoint) options() []jsonrpc.Option {
+	if e.Credentials == "" {
+		return nil
+	}
+	return []jsonrpc.Option{jsonrpc.WithHeader("Authorization", e.Credentials)}
+}
+
+// name identifies the endpoint in logs without its path and query, which
+// often hold an API key.
+func (e *endpoint) name() string {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Call invokes a method on the endpoint, without failing over.
+func (e *endpoint) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if e.client != nil {
+		return e.client.Call(ctx, result, method, params...)
+	}
+	ws, err := jsonrpc.DialWebSocket(ctx, e.URL, e.options()...)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	return ws.Call(ctx, result, method, params...)
+}
+
+func (e *endpoint) errorRate() float64 {
+	if e.requests == 0 {
+		return 0
+	}
+	return e.failures / e.requests
+}
+
+// cost estimates the time the endpoint takes to successfully serve a
+// request, retries included.
+func (e *endpoint) cost() float64 {
+	rate := e.errorRate()
+	if rate >= 1 {
+		return math.Inf(1)
+	}
+	return float64(e.latency) / (1 - rate)
+}
+
+// nodeProbe reads what the health of an endpoint is judged on, in the
+// protocol of its chain.
+type nodeProbe interface {
+	// chainID returns the ID of the chain a node serves.
+	chainID(ctx context.Context, rpc rpcCaller) (string, error)
+	// head returns the number of the head block of a node.
+	head(ctx context.Context, rpc rpcCaller) (uint64, error)
+}
+
+// ethereumProbe probes Ethereum-compatible nodes.
+type ethereumProbe struct{}
+
+func (ethereumProbe) chainID(ctx context.Context, rpc rpcCaller) (string, error) {
+	var chainID hexUint64
+	if err := rpc.Call(ctx, &chainID, "eth_chainId"); err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(uint64(chainID), 10), nil
+}
+
+func (ethereumProbe) head(ctx context.Context, rpc rpcCaller) (uint64, error) {
+	return blockNumber(ctx, rpc)
+}
+
+// endpointPool calls JSON-RPC methods on the best of several endpoints of
+// the same chain, failing over to the next one when an endpoint cannot be
+// reached.
+type endpointPool struct {
+	logger       *zap.SugaredLogger
+	probe        nodeProbe
+	endpoints    []*endpoint
+	maxHeadLag   uint64
+	maxErrorRate float64
+
+	mu sync.Mutex
+	// chainID is the chain ID every endpoint must report, once known.
+	chainID    string
+	chainKnown bool
+	// preferred is the best endpoint as of the last health check.
+	preferred *endpoint
+	// active is the endpoint that last served a call or a stream.
+	active *endpoint
+	// changed is signaled when the preferred endpoint changes.
+	changed chan struct{}
+}
+
+func newEndpointPool(logger *zap.SugaredLogger, probe nodeProbe, configs []endpointConfig, maxHeadLag uint64, maxErrorRate float64) *endpointPool {
+	p := &endpointPool{
+		logger:       logger,
+		probe:        probe,
+		maxHeadLag:   maxHeadLag,
+		maxErrorRate: maxErrorRate,
+		changed:      make(chan struct{}, 1),
+	}
+	for _, c := range configs {
+		p.endpoints = append(p.endpoints, newEndpoint(c))
+	}
+	sort.SliceStable(p.endpoints, func(i, j int) bool {
+		return p.endpoints[i].Priority < p.endpoints[j].Priority
+	})
+	return p
+}
+
+// Call invokes a method on the best endpoint. Endpoints that cannot be
+// reached are marked unhealthy and the call is retried on the next one.
+// Errors returned by a node are returned as is, the node being reachable.
+func (p *endpointPool) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	err := errNoEndpoint
+	for _, e := range p.ranked() {
+		if err = p.verify(ctx, e, e); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			continue
+		}
+
+		start := time.Now()
+		err = e.Call(ctx, result, method, params...)
+		var rpcErr *jsonrpc.Error
+		if err == nil || errors.As(err, &rpcErr) {
+			p.observe(e, time.Since(start), true)
+			p.use(e)
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		p.fail(e, err)
+	}
+	return err
+}
+
+// use records that an endpoint is being read from.
+func (p *endpointPool) use(e *endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active = e
+}
+
+// activeName returns the name of the endpoint that last served a call or a
+// stream, or an empty string if none did.
+func (p *endpointPool) activeName() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == nil {
+		return ""
+	}
+	return p.active.name()
+}
+
+// ranked returns the usable endpoints, best first: healthy endpoints come
+// first, then lower priority values, then lower costs.
+func (p *endpointPool) ranked() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rankedLocked()
+}
+
+func (p *endpointPool) rankedLocked() []*endpoint {
+	ranked := make([]*endpoint, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		if !e.mismatched {
+			ranked = append(ranked, e)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.healthy != b.healthy {
+			return a.healthy
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.cost() < b.cost()
+	})
+	return ranked
+}
+
+// best returns the best endpoint, or nil if none is usable.
+func (p *endpointPool) best() *endpoint {
+	if ranked := p.ranked(); len(ranked) > 0 {
+		return ranked[0]
+	}
+	return nil
+}
+
+// verify checks that an endpoint serves the chain of the pool, using rpc
+// to reach it. The first endpoint to report its chain ID sets the chain of
+// the pool, endpoints reporting another one are never used.
+func (p *endpointPool) verify(ctx context.Context, e *endpoint, rpc rpcCaller) error {
+	p.mu.Lock()
+	verified, mismatched := e.verified, e.mismatched
+	served, expected := e.servedChainID, p.chainID
+	p.mu.Unlock()
+	if verified {
+		return nil
+	}
+	if mismatched {
+		return &chainMismatchError{endpoint: e.name(), got: served, want: expected}
+	}
+
+	chainID, err := p.probe.chainID(ctx, rpc)
+	if err != nil {
+		p.fail(e, err)
+		return fmt.Errorf("failed to read chain ID of endpoint %s: %w", e.name(), err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.chainKnown {
+		p.chainID, p.chainKnown = chainID, true
+	}
+	if chainID != p.chainID {
+		e.mismatched, e.servedChainID = true, chainID
+		p.logger.Errorf("Endpoint %s serves chain %s instead of %s, it is not used", e.name(), chainID, p.chainID)
+		return &chainMismatchError{endpoint: e.name(), got: chainID, want: p.chainID}
+	}
+	e.verified = true
+	return nil
+}
+
+// mismatch returns the error of an endpoint serving another chain if every
+// endpoint does, or nil otherwise.
+func (p *endpointPool) mismatch() *chainMismatchError {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.endpoints {
+		if !e.mismatched {
+			return nil
+		}
+	}
+	e := p.endpoints[0]
+	return &chainMismatchError{endpoint: e.name(), got: e.servedChainID, want: p.chainID}
+}
+
+// verifyAll checks the chain of every endpoint, in priority order, so that
+// the preferred endpoints set the chain of the pool.
+func (p *endpointPool) verifyAll(ctx context.Context) {
+	for _, e := range p.endpoints {
+		if err := p.verify(ctx, e, e); err != nil && ctx.Err() == nil {
+			p.logger.Errorf("Could not verify endpoint %s: %v", e.name(), err)
+		}
+	}
+}
+
+// observe records the outcome of a request sent to an endpoint.
+func (p *endpointPool) observe(e *endpoint, latency time.Duration, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e.requests = e.requests*errorDecay + 1
+	e.failures *= errorDecay
+	if !ok {
+		e.failures++
+		return
+	}
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = (4*e.latency + latency) / 5
+	}
+}
+
+// fail marks an endpoint unhealthy after a failed request, until the next
+// health check.
+func (p *endpointPool) fail(e *endpoint, err error) {
+	p.observe(e, 0, false)
+	p.mu.Lock()
+	wasHealthy := e.healthy
+	e.healthy = false
+	p.mu.Unlock()
+	if wasHealthy && len(p.endpoints) > 1 {
+		p.logger.Errorf("Endpoint %s failed, failing over: %v", e.name(), err)
+	}
+}
+
+// check probes the head block number of every endpoint, and updates their
+// health. Endpoints are unhealthy when they cannot be reached, lag behind
+// the most advanced one by more than maxHeadLag blocks, or fail more often
+// than maxErrorRate.
+func (p *endpointPool) check(ctx context.Context) {
+	heads := make(map[*endpoint]uint64, len(p.endpoints))
+	var newest uint64
+	for _, e := range p.ranked() {
+		ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		if err := p.verify(ctx, e, e); err != nil {
+			// Already recorded as a failure.
+			cancel()
+			continue
+		}
+		start := time.Now()
+		head, err := p.probe.head(ctx, e)
+		cancel()
+		if err != nil {
+			p.observe(e, 0, false)
+			continue
+		}
+		p.observe(e, time.Since(start), true)
+		heads[e] = head
+		if head > newest {
+			newest = head
+		}
+	}
+
+	p.mu.Lock()
+	for _, e := range p.endpoints {
+		head, ok := heads[e]
+		e.head = head
+		e.healthy = ok && newest-head <= p.maxHeadLag && e.errorRate() <= p.maxErrorRate
+	}
+	var preferred *endpoint
+	if ranked := p.rankedLocked(); len(ranked) > 0 {
+		preferred = ranked[0]
+	}
+	previous := p.preferred
+	p.preferred = preferred
+	p.mu.Unlock()
+
+	if previous != nil && preferred != previous {
+		if preferred != nil {
+			p.logger.Infof("Preferred endpoint is now %s", preferred.name())
+		}
+		select {
+		case p.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run checks the health of the endpoints every interval until ctx is done.
+func (p *endpointPool) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.check(ctx)
+		}
+	}
+}
+
+// setupEndpoints builds the pool of endpoints given to the adapter, either
+// as a list or as a single URL.
+func (a *ethereumAdapter) setupEndpoints() error {
+	configs, err := endpointConfigs(a.rpcURL, a.endpointsJSON)
+	if err != nil {
+		return err
+	}
+	a.rpc = newEndpointPool(a.logger, ethereumProbe{}, configs, a.maxHeadLag, a.maxErrorRate)
+	if a.chainID != "" {
+		if _, err := strconv.ParseUint(a.chainID, 10, 64); err != nil {
+			return fmt.Errorf("invalid chain ID %q: %w", a.chainID, err)
+		}
+		a.rpc.chainID, a.rpc.chainKnown = a.chainID, true
+	}
+	return nil
+}
+
+// endpointConfigs returns the endpoints given to an adapter, either as a
+// list or as a single URL.
+func endpointConfigs(rpcURL, endpointsJSON string) ([]endpointConfig, error) {
+	configs := []endpointConfig{{URL: rpcURL}}
+	if endpointsJSON != "" {
+		var err error
+		if configs, err = parseEndpoints(endpointsJSON); err != nil {
+			return nil, err
+		}
+	}
+	if len(configs) == 0 || configs[0].URL == "" {
+		return nil, errors.New("no RPC endpoint given")
+	}
+	return configs, nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+func newTestEndpointPool(configs ...endpointConfig) *endpointPool {
+	return newEndpointPool(zap.NewExample().Sugar(), ethereumProbe{}, configs, 5, 0.5)
+}
+
+func TestEndpointPoolFailsOver(t *testing.T) {
+	down := httptest.NewServer(newFakeNode(1, 3))
+	down.Close()
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	p := newTestEndpointPool(
+		endpointConfig{URL: server.URL, Priority: 1},
+		endpointConfig{URL: down.URL},
+	)
+	for i := 0; i < 2; i++ {
+		head, err := blockNumber(context.Background(), p)
+		if err != nil {
+			t.Fatalf("blockNumber() = %v", err)
+		}
+		if head != 2 {
+			t.Errorf("blockNumber() = %d, want 2", head)
+		}
+	}
+	if got := p.best().URL; got != server.URL {
+		t.Errorf("best endpoint = %s, want %s", got, server.URL)
+	}
+
+	// Errors returned by the node do not fail over.
+	node.mu.Lock()
+	node.failing = true
+	node.mu.Unlock()
+	if _, err := blockNumber(context.Background(), p); err == nil {
+		t.Error("blockNumber() = nil, want an error")
+	}
+	if got := p.best().URL; got != server.URL {
+		t.Errorf("best endpoint = %s, want %s", got, server.URL)
+	}
+}
+
+func TestEndpointPoolRejectsOtherChains(t *testing.T) {
+	primary := newFakeNode(1, 3)
+	primaryServer := httptest.NewServer(primary)
+	other := newFakeNode(5, 10)
+	otherServer := httptest.NewServer(other)
+	defer otherServer.Close()
+
+	p := newTestEndpointPool(
+		endpointConfig{URL: otherServer.URL, Priority: 1},
+		endpointConfig{URL: primaryServer.URL},
+	)
+	p.verifyAll(context.Background())
+	if len(p.ranked()) != 1 {
+		t.Fatalf("usable endpoints = %d, want 1", len(p.ranked()))
+	}
+
+	// The endpoint of the other chain is not used even when the primary
+	// one is down.
+	primaryServer.Close()
+	if _, err := blockNumber(context.Background(), p); err == nil {
+		t.Error("blockNumber() = nil, want an error")
+	}
+	other.mu.Lock()
+	defer other.mu.Unlock()
+	if got := other.callCount("eth_blockNumber"); got != 0 {
+		t.Errorf("eth_blockNumber called %d times on the other chain", got)
+	}
+}
+
+func TestEndpointPoolHealthCheck(t *testing.T) {
+	primary := newFakeNode(1, 3)
+	primaryServer := httptest.NewServer(primary)
+	defer primaryServer.Close()
+	secondary := newFakeNode(1, 10)
+	secondaryServer := httptest.NewServer(secondary)
+	defer secondaryServer.Close()
+
+	p := newTestEndpointPool(
+		endpointConfig{URL: primaryServer.URL},
+		endpointConfig{URL: secondaryServer.URL, Priority: 1},
+	)
+	p.check(context.Background())
+	if got := p.best().URL; got != secondaryServer.URL {
+		t.Errorf("best endpoint = %s, want the secondary one while the primary one lags", got)
+	}
+
+	for i := 0; i < 5; i++ {
+		primary.mine()
+	}
+	p.check(context.Background())
+	if got := p.best().URL; got != primaryServer.URL {
+		t.Errorf("best endpoint = %s, want the primary one once caught up", got)
+	}
+	select {
+	case <-p.changed:
+	default:
+		t.Error("change of preferred endpoint not signaled")
+	}
+}
+
+func TestEndpointPoolCredentials(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		node.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	t.Setenv("BLOCKCHAIN_ENDPOINT_1_CREDENTIALS", "Bearer secret")
+	configs, err := parseEndpoints(fmt.Sprintf(`[{"url": "http://localhost:1"}, {"url": %q, "priority": 2}]`, server.URL))
+	if err != nil {
+		t.Fatalf("parseEndpoints() = %v", err)
+	}
+	want := []endpointConfig{
+		{URL: "http://localhost:1"},
+		{URL: server.URL, Priority: 2, Credentials: "Bearer secret"},
+	}
+	if diff := cmp.Diff(want, configs); diff != "" {
+		t.Errorf("unexpected endpoints (-want, +got) = %v", diff)
+	}
+
+	p := newTestEndpointPool(configs[1])
+	if _, err := blockNumber(context.Background(), p); err != nil {
+		t.Errorf("blockNumber() = %v", err)
+	}
+}
+
+func TestEthereumAdapterFailsOver(t *testing.T) {
+	down := httptest.NewServer(newFakeNode(1, 3))
+	down.Close()
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "")
+	a.healthCheckInterval = time.Hour
+	a.endpointsJSON = fmt.Sprintf(`[{"url": %q}, {"url": %q, "priority": 1}]`, down.URL, server.URL)
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_blockNumber", 2)
+		node.mine()
+		node.mine()
+		waitForEvents(t, ce, 2)
+	})
+	if diff := cmp.Diff([]string{"3", "4"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterStreamsFromNextEndpoint(t *testing.T) {
+	down := httptest.NewServer(newFakeNode(1, 3))
+	down.Close()
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "")
+	a.healthCheckInterval = time.Hour
+	a.mode = sourcesv1alpha1.IngestionModeStreaming
+	a.endpointsJSON = fmt.Sprintf(`[{"url": %q}, {"url": %q, "priority": 1}]`,
+		"ws"+strings.TrimPrefix(down.URL, "http"), "ws"+strings.TrimPrefix(server.URL, "http"))
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine()
+		waitForEvents(t, ce, 1)
+	})
+	if diff := cmp.Diff([]string{"3"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterSkipsEndpointsOfOtherChains(t *testing.T) {
+	other := httptest.NewServer(newFakeNode(5, 10))
+	defer other.Close()
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "")
+	a.healthCheckInterval = time.Hour
+	a.chainID = "1"
+	a.endpointsJSON = fmt.Sprintf(`[{"url": %q}, {"url": %q, "priority": 1}]`, other.URL, server.URL)
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_blockNumber", 2)
+		node.mine()
+		waitForEvents(t, ce, 1)
+	})
+	if got := ce.Sent()[0].Source(); got != "eip155:1" {
+		t.Errorf("event source = %q, want %q", got, "eip155:1")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+// finalityExtension is the CloudEvent extension holding the finality level
+// the block of an event had reached when the event was emitted.
+const finalityExtension = "finality"
+
+// finalHead returns the number of the newest block final enough to be
+// emitted, given the number of the current head, up to the end block. ok is
+// false when no block is final enough yet.
+func (a *ethereumAdapter) finalHead(ctx context.Context, rpc rpcCaller, head uint64) (final uint64, ok bool, err error) {
+	final, ok, err = a.finalBlock(ctx, rpc, head)
+	if ok && a.endBlock != nil && final > *a.endBlock {
+		final = *a.endBlock
+	}
+	return final, ok, err
+}
+
+func (a *ethereumAdapter) finalBlock(ctx context.Context, rpc rpcCaller, head uint64) (uint64, bool, error) {
+	switch a.finality {
+	case sourcesv1alpha1.FinalityLevelConfirmed:
+		if head < a.confirmations {
+			return 0, false, nil
+		}
+		return head - a.confirmations, true, nil
+
+	case sourcesv1alpha1.FinalityLevelSafe, sourcesv1alpha1.FinalityLevelFinalized:
+		block, err := blockByTag(ctx, rpc, string(a.finality))
+		if errors.Is(err, errBlockNotFound) {
+			// The node does not know of such a block yet, e.g. right
+			// after the genesis.
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to read %s block: %w", a.finality, err)
+		}
+		return uint64(block.Number), true, nil
+	}
+	return head, true, nil
+}
+
+// blockByTag returns the block the node reports under a block tag such as
+// "safe" or "finalized".
+func blockByTag(ctx context.Context, rpc rpcCaller, tag string) (*ethBlock, error) {
+	return getBlock(ctx, rpc, tag)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+func TestEthereumAdapterWaitsForConfirmations(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.finality = sourcesv1alpha1.FinalityLevelConfirmed
+	a.confirmations = 2
+	a.source = "eip155:1"
+	a.next = 3
+
+	node.mine()
+	node.mine()
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Fatalf("sent %d events for blocks not confirmed enough", got)
+	}
+
+	node.mine()
+	node.mine()
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	if diff := cmp.Diff([]string{"3", "4"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+	for _, e := range ce.Sent() {
+		if got := e.Extensions()[finalityExtension]; got != "confirmed" {
+			t.Errorf("finality = %v, want confirmed", got)
+		}
+	}
+}
+
+func TestEthereumAdapterEmitsTaggedBlocks(t *testing.T) {
+	for _, level := range []sourcesv1alpha1.FinalityLevel{
+		sourcesv1alpha1.FinalityLevelSafe,
+		sourcesv1alpha1.FinalityLevelFinalized,
+	} {
+		t.Run(string(level), func(t *testing.T) {
+			node := newFakeNode(1, 6)
+			server := httptest.NewServer(node)
+			defer server.Close()
+
+			ce := adaptertest.NewTestClient()
+			a := newTestEthereumAdapter(t, ce, server.URL)
+			a.finality = level
+			a.source = "eip155:1"
+			a.next = 2
+
+			// Nothing is emitted until the node reports a block under
+			// the tag.
+			if err := a.poll(context.Background()); err != nil {
+				t.Fatalf("poll() = %v", err)
+			}
+			node.mu.Lock()
+			node.tags[string(level)] = 3
+			node.mu.Unlock()
+			if err := a.poll(context.Background()); err != nil {
+				t.Fatalf("poll() = %v", err)
+			}
+
+			if diff := cmp.Diff([]string{"2", "3"}, sentSubjects(ce)); diff != "" {
+				t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+			}
+			for _, e := range ce.Sent() {
+				if got := e.Extensions()[finalityExtension]; got != string(level) {
+					t.Errorf("finality = %v, want %s", got, level)
+				}
+			}
+		})
+	}
+}
+
+func TestEthereumAdapterStreamsConfirmedLogs(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "ws"+strings.TrimPrefix(server.URL, "http"))
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q]}`, tokenAddress)
+	a.finality = sourcesv1alpha1.FinalityLevelConfirmed
+	a.confirmations = 1
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine(fakeLog(tokenAddress, transferTopic, 1))
+		node.mine(fakeLog(tokenAddress, transferTopic, 2))
+		waitForEvents(t, ce, 1)
+		node.mine()
+		waitForEvents(t, ce, 2)
+	})
+
+	want := []string{
+		"log " + blockHash(0, 3) + "-0",
+		"log " + blockHash(0, 4) + "-0",
+	}
+	if diff := cmp.Diff(want, sentSummary(ce)); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+}
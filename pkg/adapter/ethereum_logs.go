@@ -0,0 +1,344 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/evm"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+// ethereumLogEventType is the CloudEvent type of the events emitted for
+// every contract log.
+var ethereumLogEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindLog)
+
+// ethLog is a log as returned by eth_getLogs and logs subscriptions.
+type ethLog struct {
+	Address          string    `json:"address"`
+	Topics           []string  `json:"topics"`
+	Data             string    `json:"data"`
+	BlockNumber      hexUint64 `json:"blockNumber"`
+	BlockHash        string    `json:"blockHash"`
+	TransactionHash  string    `json:"transactionHash"`
+	TransactionIndex hexUint64 `json:"transactionIndex"`
+	LogIndex         hexUint64 `json:"logIndex"`
+	Removed          bool      `json:"removed"`
+}
+
+// logFilter is the filter object of eth_getLogs and logs subscriptions.
+type logFilter struct {
+	FromBlock *hexUint64 `json:"fromBlock,omitempty"`
+	ToBlock   *hexUint64 `json:"toBlock,omitempty"`
+	Address   []string   `json:"address,omitempty"`
+	Topics    [][]string `json:"topics,omitempty"`
+}
+
+// logEventData is the data of the events emitted for contract logs. Args
+// holds the decoded arguments when the ABI declares the event, Topics and
+// Data the raw log otherwise.
+type logEventData struct {
+	Address          string                 `json:"address"`
+	BlockNumber      uint64                 `json:"blockNumber"`
+	BlockHash        string                 `json:"blockHash"`
+	TransactionHash  string                 `json:"transactionHash"`
+	TransactionIndex uint64                 `json:"transactionIndex"`
+	LogIndex         uint64                 `json:"logIndex"`
+	Event            string                 `json:"event,omitempty"`
+	Signature        string                 `json:"signature,omitempty"`
+	Args             map[string]interface{} `json:"args,omitempty"`
+	Topics           []string               `json:"topics,omitempty"`
+	Data             string                 `json:"data,omitempty"`
+}
+
+// logPosition is the position of a log in the chain.
+type logPosition struct {
+	block uint64
+	index uint64
+}
+
+// setupContracts parses the contract subscription and ABI given to the
+// adapter, if any, and builds the matching log filter.
+func (a *ethereumAdapter) setupContracts() error {
+	if a.contractsJSON == "" {
+		return nil
+	}
+
+	var contracts sourcesv1alpha1.ContractSubscription
+	if err := json.Unmarshal([]byte(a.contractsJSON), &contracts); err != nil {
+		return fmt.Errorf("invalid contract subscription: %w", err)
+	}
+
+	if a.abiJSON != "" {
+		abi, err := evm.ParseABI([]byte(a.abiJSON))
+		if err != nil {
+			return err
+		}
+		a.abi = abi
+	}
+
+	filter := &logFilter{
+		Address: contracts.Addresses,
+		Topics:  contracts.Topics,
+	}
+	if len(contracts.EventSignatures) > 0 {
+		signatures := make([]string, 0, len(contracts.EventSignatures))
+		for _, s := range contracts.EventSignatures {
+			topic, err := a.eventTopic(s)
+			if err != nil {
+				return err
+			}
+			signatures = append(signatures, topic)
+		}
+		if len(filter.Topics) == 0 {
+			filter.Topics = [][]string{signatures}
+		} else {
+			filter.Topics = append([][]string{signatures}, filter.Topics[1:]...)
+		}
+	}
+	a.filter = filter
+	return nil
+}
+
+// eventTopic returns the topic of an event given by its signature, its
+// topic or its name in the ABI.
+func (a *ethereumAdapter) eventTopic(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, "0x"):
+		return strings.ToLower(s), nil
+	case strings.Contains(s, "("):
+		return evm.EventTopic(strings.ReplaceAll(s, " ", "")), nil
+	}
+	if a.abi == nil {
+		return "", fmt.Errorf("event %q is not a signature and no ABI was given", s)
+	}
+	event := a.abi.EventByName(s)
+	if event == nil {
+		return "", fmt.Errorf("event %q is not declared by the ABI", s)
+	}
+	return event.Topic(), nil
+}
+
+// catchUpLogs emits an event for every matching log from the next block up
+// to and including head. Logs are requested by chunks of blocks, shrunk
+// whenever the node reports too many results for a chunk. Logs already
+// emitted are skipped, so a log that could not be delivered is retried on
+// the next call without duplicating the ones before it.
+func (a *ethereumAdapter) catchUpLogs(ctx context.Context, rpc rpcCaller, head uint64) error {
+	for a.next <= head {
+		to := head
+		if a.chunkSize > 0 && head-a.next >= a.chunkSize {
+			to = a.next + a.chunkSize - 1
+		}
+
+		filter := *a.filter
+		fromBlock, toBlock := hexUint64(a.next), hexUint64(to)
+		filter.FromBlock, filter.ToBlock = &fromBlock, &toBlock
+
+		var logs []ethLog
+		if err := rpc.Call(ctx, &logs, "eth_getLogs", filter); err != nil {
+			if isTooManyResults(err) && to > a.next {
+				a.chunkSize = (to - a.next + 1) / 2
+				a.logger.Infof("Too many logs in blocks %d to %d, requesting %d blocks at once", a.next, to, a.chunkSize)
+				continue
+			}
+			return fmt.Errorf("failed to get logs of blocks %d to %d: %w", a.next, to, err)
+		}
+		for i := range logs {
+			if err := a.handleLog(ctx, &logs[i]); err != nil {
+				return err
+			}
+		}
+
+		if to == head {
+			// Remember the head too, so that reorgs are detected even if
+			// no log was emitted.
+			block, err := blockByNumber(ctx, rpc, head)
+			if err != nil {
+				return err
+			}
+			a.record(head, block.Hash, block.ParentHash, nil)
+			a.blockTime = time.Unix(int64(block.Timestamp), 0)
+		}
+		a.next = to + 1
+		a.saveCheckpoint(ctx, false)
+
+		// Grow the chunks back after a successful request.
+		if a.chunkSize *= 2; a.maxChunkSize > 0 && a.chunkSize > a.maxChunkSize {
+			a.chunkSize = a.maxChunkSize
+		}
+	}
+	return nil
+}
+
+// isTooManyResults reports whether an eth_getLogs request failed because the
+// node or provider limits the number of results or the range of blocks.
+// There is no standard error for it, providers word it differently.
+func isTooManyResults(err error) bool {
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.Code == -32005 {
+		// Limit exceeded, as defined by EIP-1474.
+		return true
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	for _, s := range []string{"too many", "more than", "exceed", "limit", "range"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleLogNotification emits the log announced by a logs subscription. Logs
+// notified as removed reveal a reorg, after which the logs of the canonical
+// blocks are fetched again, up to the end block.
+func (a *ethereumAdapter) handleLogNotification(ctx context.Context, rpc rpcCaller, raw json.RawMessage) error {
+	var l ethLog
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return fmt.Errorf("failed to unmarshal log: %w", err)
+	}
+	if !l.Removed {
+		return a.handleLog(ctx, &l)
+	}
+	return a.catchUpHead(ctx, rpc)
+}
+
+// handleLog emits a log unless it was already emitted. Logs are received in
+// chain order, so every block before the one of the log has been handled,
+// and the end block is passed once a log after it is received.
+func (a *ethereumAdapter) handleLog(ctx context.Context, l *ethLog) error {
+	if l.Removed {
+		return nil
+	}
+	if a.pastEnd(uint64(l.BlockNumber)) {
+		a.next = *a.endBlock + 1
+		return nil
+	}
+	pos := logPosition{block: uint64(l.BlockNumber), index: uint64(l.LogIndex)}
+	if a.emittedLog && (pos.block < a.lastLog.block || pos.block == a.lastLog.block && pos.index <= a.lastLog.index) {
+		return nil
+	}
+
+	event, err := a.emitLog(ctx, l)
+	if err != nil {
+		return fmt.Errorf("failed to emit log %d of block %d: %w", pos.index, pos.block, err)
+	}
+	a.record(pos.block, l.BlockHash, "", event)
+	a.lastLog, a.emittedLog = pos, true
+	if pos.block > a.next {
+		a.next = pos.block
+	}
+	return nil
+}
+
+func (a *ethereumAdapter) emitLog(ctx context.Context, l *ethLog) (*sentEvent, error) {
+	data := logEventData{
+		Address:          l.Address,
+		BlockNumber:      uint64(l.BlockNumber),
+		BlockHash:        l.BlockHash,
+		TransactionHash:  l.TransactionHash,
+		TransactionIndex: uint64(l.TransactionIndex),
+		LogIndex:         uint64(l.LogIndex),
+	}
+	if addr, err := evm.ParseAddress(l.Address); err == nil {
+		data.Address = evm.ChecksumAddress(addr)
+	}
+	a.decodeLog(l, &data)
+
+	event := cloudevents.NewEvent()
+	event.SetID(fmt.Sprintf("%s-%d", l.BlockHash, uint64(l.LogIndex)))
+	event.SetType(ethereumLogEventType)
+	// Logs are sourced from their contract, which triggers filter on.
+	event.SetSource(sourcesv1alpha1.BlockchainContractEventSource(a.source, l.Address))
+	event.SetSubject(data.Address)
+	event.SetExtension(finalityExtension, string(a.finality))
+	ext := a.logExtensions(&data)
+	if err := ext.apply(&event); err != nil {
+		return nil, fmt.Errorf("failed to set event extensions: %w", err)
+	}
+
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return nil, fmt.Errorf("failed to set event data: %w", err)
+	}
+
+	if a.eventFilters.drops(ctx, a.logger, event) {
+		return nil, nil
+	}
+	if result := a.send(ctx, event); !cloudevents.IsACK(result) {
+		return nil, result
+	}
+	return &sentEvent{id: event.ID(), eventType: event.Type(), source: event.Source(), subject: event.Subject(), ext: ext}, nil
+}
+
+// logExtensions returns the extension attributes of the event of a log. The
+// sender and the recipient are the "from" and "to" arguments of transfer
+// events, with or without a leading underscore.
+func (a *ethereumAdapter) logExtensions(data *logEventData) chainExtensions {
+	blockNumber, logIndex := data.BlockNumber, data.LogIndex
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &blockNumber,
+		blockHash:   data.BlockHash,
+		txHash:      data.TransactionHash,
+		logIndex:    &logIndex,
+		contract:    data.Address,
+		eventName:   data.Event,
+	}
+	for name, value := range data.Args {
+		addr, ok := value.(string)
+		if !ok {
+			continue
+		}
+		switch strings.TrimPrefix(name, "_") {
+		case "from":
+			ext.from = addr
+		case "to":
+			ext.to = addr
+		}
+	}
+	return ext
+}
+
+// decodeLog fills in the decoded arguments of a log, or its raw topics and
+// data when the ABI does not declare its event.
+func (a *ethereumAdapter) decodeLog(l *ethLog, data *logEventData) {
+	if a.abi != nil && len(l.Topics) > 0 {
+		if event := a.abi.EventByTopic(l.Topics[0]); event != nil {
+			args, err := event.DecodeLog(l.Topics, l.Data)
+			if err == nil {
+				data.Event = event.Name
+				data.Signature = event.Signature()
+				data.Args = args
+				return
+			}
+			a.logger.Errorf("Failed to decode %s log %d of block %d: %v", event.Name, uint64(l.LogIndex), uint64(l.BlockNumber), err)
+		}
+	}
+	data.Topics = l.Topics
+	data.Data = l.Data
+}
@@ -0,0 +1,427 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	"knative.dev/eventing-blockchain/pkg/evm"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+const (
+	tokenAddress = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	otherAddress = "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"
+
+	tokenABI = `[
+  {"type": "event", "name": "Transfer", "inputs": [
+    {"name": "from", "type": "address", "indexed": true},
+    {"name": "to", "type": "address", "indexed": true},
+    {"name": "value", "type": "uint256"}
+  ]},
+  {"type": "event", "name": "Approval", "inputs": [
+    {"name": "owner", "type": "address", "indexed": true},
+    {"name": "spender", "type": "address", "indexed": true},
+    {"name": "value", "type": "uint256"}
+  ]}
+]`
+)
+
+var (
+	transferTopic = evm.EventTopic("Transfer(address,address,uint256)")
+	approvalTopic = evm.EventTopic("Approval(address,address,uint256)")
+)
+
+// addressTopic returns the topic of an indexed address argument.
+func addressTopic(addr string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(addr, "0x")
+}
+
+// fakeLog returns a log of the event with the given topic emitted by the
+// contract at address, with value as its only non-indexed argument.
+func fakeLog(address, topic string, value uint64) map[string]interface{} {
+	return map[string]interface{}{
+		"address": address,
+		"topics":  []string{topic, addressTopic(tokenAddress), addressTopic(otherAddress)},
+		"data":    fmt.Sprintf("0x%064x", value),
+	}
+}
+
+// sentLogs returns the data of the sent log events, in order.
+func sentLogs(t *testing.T, ce *adaptertest.TestCloudEventsClient) []logEventData {
+	t.Helper()
+	var logs []logEventData
+	for _, e := range ce.Sent() {
+		if e.Type() != ethereumLogEventType {
+			t.Errorf("event type = %q, want %q", e.Type(), ethereumLogEventType)
+		}
+		var data logEventData
+		if err := json.Unmarshal(e.Data(), &data); err != nil {
+			t.Fatalf("Could not unmarshal sent data: %v", err)
+		}
+		if want := fmt.Sprintf("%s-%d", data.BlockHash, data.LogIndex); e.ID() != want {
+			t.Errorf("event ID = %q, want %q", e.ID(), want)
+		}
+		if e.Subject() != data.Address {
+			t.Errorf("event subject = %q, want %q", e.Subject(), data.Address)
+		}
+		if want := "eip155:1/contract/" + strings.ToLower(data.Address); e.Source() != want {
+			t.Errorf("event source = %q, want %q", e.Source(), want)
+		}
+		logs = append(logs, data)
+	}
+	return logs
+}
+
+func TestEthereumAdapterEmitsDecodedLogs(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q], "eventSignatures": ["Transfer"]}`, tokenAddress)
+	a.abiJSON = tokenABI
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_blockNumber", 2)
+		node.mine(
+			fakeLog(tokenAddress, transferTopic, 1000),
+			fakeLog(otherAddress, transferTopic, 2000),
+			fakeLog(tokenAddress, approvalTopic, 3000),
+		)
+		node.mine()
+		node.mine(fakeLog(tokenAddress, transferTopic, 4000))
+		waitForEvents(t, ce, 2)
+	})
+
+	logs := sentLogs(t, ce)
+	want := []logEventData{{
+		Address:         "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		BlockNumber:     3,
+		BlockHash:       fmt.Sprintf("0x%064x", 0xb10c003),
+		TransactionHash: fmt.Sprintf("0x%064x", 0x7e000000+3000),
+		Event:           "Transfer",
+		Signature:       "Transfer(address,address,uint256)",
+		Args: map[string]interface{}{
+			"from":  "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			"to":    "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+			"value": "1000",
+		},
+	}, {
+		Address:         "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		BlockNumber:     5,
+		BlockHash:       fmt.Sprintf("0x%064x", 0xb10c005),
+		TransactionHash: fmt.Sprintf("0x%064x", 0x7e000000+5000),
+		Event:           "Transfer",
+		Signature:       "Transfer(address,address,uint256)",
+		Args: map[string]interface{}{
+			"from":  "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			"to":    "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+			"value": "4000",
+		},
+	}}
+	if diff := cmp.Diff(want, logs); diff != "" {
+		t.Errorf("unexpected logs (-want, +got) = %v", diff)
+	}
+
+	wantExt := map[string]interface{}{
+		finalityExtension:    "latest",
+		chainIDExtension:     "1",
+		blockNumberExtension: int32(3),
+		blockHashExtension:   fmt.Sprintf("0x%064x", 0xb10c003),
+		txHashExtension:      fmt.Sprintf("0x%064x", 0x7e000000+3000),
+		logIndexExtension:    int32(0),
+		contractExtension:    "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		eventNameExtension:   "Transfer",
+		fromAddrExtension:    "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		toAddrExtension:      "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+	}
+	if diff := cmp.Diff(wantExt, ce.Sent()[0].Extensions()); diff != "" {
+		t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterEmitsRawLogs(t *testing.T) {
+	node := newFakeNode(1, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.contractsJSON = fmt.Sprintf(`{"topics": [[], [%q]]}`, addressTopic(tokenAddress))
+	a.abiJSON = `[]`
+	if err := a.setupContracts(); err != nil {
+		t.Fatalf("setupContracts() = %v", err)
+	}
+	a.source = "eip155:1"
+	a.next = 1
+
+	node.mine(fakeLog(otherAddress, approvalTopic, 1))
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+
+	logs := sentLogs(t, ce)
+	if len(logs) != 1 {
+		t.Fatalf("sent %d logs, want 1", len(logs))
+	}
+	if logs[0].Args != nil {
+		t.Errorf("args = %v for an event missing from the ABI", logs[0].Args)
+	}
+	if diff := cmp.Diff([]string{approvalTopic, addressTopic(tokenAddress), addressTopic(otherAddress)}, logs[0].Topics); diff != "" {
+		t.Errorf("unexpected topics (-want, +got) = %v", diff)
+	}
+	if want := fmt.Sprintf("0x%064x", 1); logs[0].Data != want {
+		t.Errorf("data = %s, want %s", logs[0].Data, want)
+	}
+}
+
+func TestEthereumAdapterRetriesUndeliveredLogs(t *testing.T) {
+	node := newFakeNode(1, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.contractsJSON = `{"eventSignatures": ["Transfer(address, address, uint256)"]}`
+	if err := a.setupContracts(); err != nil {
+		t.Fatalf("setupContracts() = %v", err)
+	}
+	a.source = "eip155:1"
+	a.next = 1
+
+	node.mine(fakeLog(tokenAddress, transferTopic, 1), fakeLog(tokenAddress, transferTopic, 2))
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	node.mine(fakeLog(tokenAddress, transferTopic, 3))
+
+	// Rescanning blocks whose logs were partly emitted, as after a failed
+	// delivery, does not emit them again.
+	a.next = 1
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	if a.next != 3 {
+		t.Errorf("next = %d, want 3", a.next)
+	}
+
+	var got []string
+	for _, l := range sentLogs(t, ce) {
+		got = append(got, l.Data)
+	}
+	want := []string{fmt.Sprintf("0x%064x", 1), fmt.Sprintf("0x%064x", 2), fmt.Sprintf("0x%064x", 3)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected log data (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterStreamsLogs(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "ws"+strings.TrimPrefix(server.URL, "http"))
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q]}`, tokenAddress)
+	a.abiJSON = tokenABI
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine(fakeLog(tokenAddress, transferTopic, 1), fakeLog(tokenAddress, approvalTopic, 2))
+		waitForEvents(t, ce, 2)
+
+		// Logs emitted while disconnected are emitted after reconnecting.
+		node.mu.Lock()
+		node.rejecting = true
+		node.mu.Unlock()
+		node.disconnect()
+		node.mine(fakeLog(tokenAddress, transferTopic, 3))
+		node.mine(fakeLog(otherAddress, transferTopic, 4))
+		node.mu.Lock()
+		node.rejecting = false
+		node.mu.Unlock()
+
+		node.waitForCalls(t, "eth_subscribe", 2)
+		waitForEvents(t, ce, 3)
+		node.mine(fakeLog(tokenAddress, approvalTopic, 5))
+		waitForEvents(t, ce, 4)
+	})
+
+	var got []string
+	for _, l := range sentLogs(t, ce) {
+		got = append(got, fmt.Sprintf("%d:%s:%s", l.BlockNumber, l.Event, l.Args["value"]))
+	}
+	want := []string{"3:Transfer:1", "3:Approval:2", "4:Transfer:3", "6:Approval:5"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected logs (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterStreamsLogsUpToEndBlock(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "ws"+strings.TrimPrefix(server.URL, "http"))
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q]}`, tokenAddress)
+	a.abiJSON = tokenABI
+	end := uint64(4)
+	a.endBlock = &end
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine(fakeLog(tokenAddress, transferTopic, 1))
+		node.mine(fakeLog(tokenAddress, approvalTopic, 2))
+		waitForEvents(t, ce, 2)
+
+		// A log after the end block tells that the end block is passed,
+		// which closes the subscription for good.
+		node.mine(fakeLog(tokenAddress, transferTopic, 3))
+		waitForCount(t, "closed subscriptions", 1, func() int {
+			node.mu.Lock()
+			defer node.mu.Unlock()
+			if len(node.subscribers) == 0 {
+				return 1
+			}
+			return 0
+		})
+	})
+
+	if !a.reachedEnd() {
+		t.Errorf("reachedEnd() = false, next = %d", a.next)
+	}
+	if got := node.callCount("eth_subscribe"); got != 1 {
+		t.Errorf("eth_subscribe calls = %d, want 1", got)
+	}
+	var got []string
+	for _, l := range sentLogs(t, ce) {
+		got = append(got, fmt.Sprintf("%d:%s:%s", l.BlockNumber, l.Event, l.Args["value"]))
+	}
+	want := []string{"3:Transfer:1", "4:Approval:2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected logs (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterInvalidContracts(t *testing.T) {
+	tests := map[string]struct {
+		contracts string
+		abi       string
+	}{
+		"malformed subscription": {
+			contracts: `{"addresses": "0x1"}`,
+		},
+		"malformed ABI": {
+			contracts: `{}`,
+			abi:       `{`,
+		},
+		"event name without ABI": {
+			contracts: `{"eventSignatures": ["Transfer"]}`,
+		},
+		"event missing from ABI": {
+			contracts: `{"eventSignatures": ["Deposit"]}`,
+			abi:       tokenABI,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := newTestEthereumAdapter(t, adaptertest.NewTestClient(), "http://localhost")
+			a.contractsJSON = tc.contracts
+			a.abiJSON = tc.abi
+			if err := a.Start(context.Background()); err == nil {
+				t.Error("Start() = nil, wanted error")
+			}
+		})
+	}
+}
+
+func TestEthereumAdapterBackfillsLogsInChunks(t *testing.T) {
+	node := newFakeNode(1, 1)
+	for i := uint64(1); i <= 8; i++ {
+		node.mine(fakeLog(tokenAddress, transferTopic, i), fakeLog(tokenAddress, transferTopic, 100+i))
+	}
+	node.mine()
+	node.mu.Lock()
+	node.maxLogs = 4
+	node.mu.Unlock()
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q]}`, tokenAddress)
+	if err := a.setupContracts(); err != nil {
+		t.Fatalf("setupContracts() = %v", err)
+	}
+	a.source = "eip155:1"
+	a.next = 1
+	a.chunkSize, a.maxChunkSize = 8, 8
+
+	// Chunks matching too many logs are split until the node accepts them.
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	if a.next != 10 {
+		t.Errorf("next = %d, want 10", a.next)
+	}
+
+	var got []string
+	for _, l := range sentLogs(t, ce) {
+		got = append(got, l.Data)
+	}
+	var want []string
+	for i := 1; i <= 8; i++ {
+		want = append(want, fmt.Sprintf("0x%064x", i), fmt.Sprintf("0x%064x", 100+i))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected log data (-want, +got) = %v", diff)
+	}
+}
+
+func TestIsTooManyResults(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{err: &jsonrpc.Error{Code: -32005, Message: "limit exceeded"}, want: true},
+		{err: &jsonrpc.Error{Code: -32602, Message: "query returned more than 10000 results"}, want: true},
+		{err: &jsonrpc.Error{Code: -32000, Message: "block range is too wide"}, want: true},
+		{err: &jsonrpc.Error{Code: -32000, Message: "header not found"}, want: false},
+		{err: fmt.Errorf("failed: %w", &jsonrpc.Error{Code: -32005}), want: true},
+		{err: errors.New("connection refused"), want: false},
+	} {
+		if got := isTooManyResults(tc.err); got != tc.want {
+			t.Errorf("isTooManyResults(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+var (
+	// ethereumReorgEventType is the CloudEvent type of the events emitted
+	// when blocks that events were sent for are orphaned by a reorg.
+	ethereumReorgEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindReorg)
+
+	// ethereumRetractedEventType is the CloudEvent type of the events
+	// emitted for every event sent for an orphaned block.
+	ethereumRetractedEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindRetracted)
+)
+
+const (
+	// retractedIDExtension is the CloudEvent extension holding the ID of the
+	// event that a retraction retracts.
+	retractedIDExtension = "retractedid"
+)
+
+// sentEvent is an event sent for a block.
+type sentEvent struct {
+	id        string
+	eventType string
+	source    string
+	subject   string
+	// ext are the extension attributes of the event, which its retraction
+	// carries as well to reach the same triggers.
+	ext chainExtensions
+}
+
+// blockRecord is a recently processed block, along with the events sent for
+// it.
+type blockRecord struct {
+	number     uint64
+	hash       string
+	parentHash string
+	events     []sentEvent
+}
+
+// reorgEventData is the data of the events emitted for reorgs.
+type reorgEventData struct {
+	// ForkBlockNumber and ForkBlockHash identify the last block shared by
+	// the orphaned and the canonical chains, if still known.
+	ForkBlockNumber uint64 `json:"forkBlockNumber"`
+	ForkBlockHash   string `json:"forkBlockHash,omitempty"`
+	// Orphaned are the orphaned blocks, newest first.
+	Orphaned []orphanedBlock `json:"orphaned"`
+}
+
+type orphanedBlock struct {
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+// retractedEventData is the data of the events emitted to retract an event
+// sent for an orphaned block.
+type retractedEventData struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Source      string `json:"source"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+}
+
+// record remembers a processed block, and an event sent for it when e is not
+// nil, so that the event can be retracted should the block be orphaned.
+// Blocks must be recorded in increasing order. Blocks older than the reorg
+// window are forgotten.
+func (a *ethereumAdapter) record(number uint64, hash, parentHash string, e *sentEvent) {
+	n := len(a.recent)
+	if n == 0 || a.recent[n-1].number < number {
+		a.recent = append(a.recent, blockRecord{number: number, hash: hash})
+		n++
+	}
+	tail := &a.recent[n-1]
+	if tail.number != number || tail.hash != hash {
+		// Out of order, or from another fork than the recorded block; the
+		// next reorg check sorts it out.
+		return
+	}
+	if parentHash != "" {
+		tail.parentHash = parentHash
+	}
+	if e != nil {
+		tail.events = append(tail.events, *e)
+	}
+
+	for len(a.recent) > 0 && a.recent[0].number+a.reorgWindow <= number {
+		a.recent = a.recent[1:]
+	}
+}
+
+// extends reports whether block can follow the last recorded block, which is
+// not the case when the chain was reorganized since.
+func (a *ethereumAdapter) extends(block *ethBlock) bool {
+	n := len(a.recent)
+	if n == 0 {
+		return true
+	}
+	tail := a.recent[n-1]
+	if uint64(block.Number) <= tail.number {
+		return false
+	}
+	return uint64(block.Number) != tail.number+1 || block.ParentHash == tail.hash
+}
+
+// checkReorg compares the recorded blocks with the canonical chain. Events
+// sent for orphaned blocks are retracted, and the adapter rewinds to the
+// block following the fork so that the canonical blocks get emitted.
+func (a *ethereumAdapter) checkReorg(ctx context.Context, rpc rpcCaller) error {
+	if err := a.flushPending(ctx); err != nil {
+		return err
+	}
+
+	// Find the newest recorded block that is still canonical.
+	i := len(a.recent)
+	for ; i > 0; i-- {
+		record := a.recent[i-1]
+		block, err := blockByNumber(ctx, rpc, record.number)
+		if err != nil && !errors.Is(err, errBlockNotFound) {
+			return fmt.Errorf("failed to check block %d for a reorg: %w", record.number, err)
+		}
+		if err == nil && block.Hash == record.hash {
+			break
+		}
+	}
+	if i == len(a.recent) {
+		return nil
+	}
+
+	orphaned := make([]blockRecord, 0, len(a.recent)-i)
+	for j := len(a.recent) - 1; j >= i; j-- {
+		orphaned = append(orphaned, a.recent[j])
+	}
+	a.recent = a.recent[:i]
+
+	data := reorgEventData{}
+	oldest := orphaned[len(orphaned)-1]
+	if i > 0 {
+		fork := a.recent[i-1]
+		data.ForkBlockNumber, data.ForkBlockHash = fork.number, fork.hash
+		a.next = fork.number + 1
+	} else {
+		a.logger.Errorf("Reorg deeper than the window of %d blocks, events sent before block %d cannot be retracted", a.reorgWindow, oldest.number)
+		data.ForkBlockNumber, data.ForkBlockHash = oldest.number-1, oldest.parentHash
+		a.next = oldest.number
+	}
+	for _, o := range orphaned {
+		data.Orphaned = append(data.Orphaned, orphanedBlock{Number: o.number, Hash: o.hash})
+	}
+	a.logger.Infof("Chain reorganized after block %d, %d blocks orphaned", data.ForkBlockNumber, len(orphaned))
+
+	// Logs of the rewound blocks are emitted again from the canonical chain.
+	a.lastLog, a.emittedLog = logPosition{block: a.next - 1, index: math.MaxUint64}, a.next > 0
+
+	events, err := reorgEvents(ethereumReorgEventType, ethereumRetractedEventType, a.source, data, orphaned)
+	if err != nil {
+		return err
+	}
+	a.pending = append(a.pending, events...)
+	return a.flushPending(ctx)
+}
+
+// flushPending sends the reorg and retraction events that are not delivered
+// yet, in order.
+func (a *ethereumAdapter) flushPending(ctx context.Context) error {
+	for len(a.pending) > 0 {
+		event := a.pending[0]
+		if result := a.send(ctx, event); !cloudevents.IsACK(result) {
+			return fmt.Errorf("failed to emit %s event %s: %w", event.Type(), event.ID(), result)
+		}
+		a.pending = a.pending[1:]
+	}
+	return nil
+}
+
+// reorgEvents returns the event announcing a reorg that orphaned blocks,
+// newest first, followed by the retractions of the events sent for them.
+func reorgEvents(reorgType, retractedType, source string, data reorgEventData, orphaned []blockRecord) ([]cloudevents.Event, error) {
+	oldest := orphaned[len(orphaned)-1]
+	reorg := cloudevents.NewEvent()
+	reorg.SetID(oldest.hash + "-reorg")
+	reorg.SetType(reorgType)
+	reorg.SetSource(source)
+	reorg.SetSubject(strconv.FormatUint(data.ForkBlockNumber, 10))
+	if err := reorg.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return nil, fmt.Errorf("failed to set event data: %w", err)
+	}
+	events := []cloudevents.Event{reorg}
+
+	// Retract the newest events first, the order in which consumers would
+	// undo them.
+	for _, o := range orphaned {
+		for j := len(o.events) - 1; j >= 0; j-- {
+			e := o.events[j]
+			retraction := cloudevents.NewEvent()
+			retraction.SetID(e.id + "-retracted")
+			retraction.SetType(retractedType)
+			// Events are identified by their source and ID.
+			retraction.SetSource(e.source)
+			retraction.SetSubject(e.subject)
+			retraction.SetExtension(retractedIDExtension, e.id)
+			if err := e.ext.apply(&retraction); err != nil {
+				return nil, fmt.Errorf("failed to set event extensions: %w", err)
+			}
+			err := retraction.SetData(cloudevents.ApplicationJSON, retractedEventData{
+				ID:          e.id,
+				Type:        e.eventType,
+				Source:      e.source,
+				BlockNumber: o.number,
+				BlockHash:   o.hash,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to set event data: %w", err)
+			}
+			events = append(events, retraction)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+// sentSummary returns the type and ID of the sent events, in order, along
+// with the ID of the retracted event for retractions.
+func sentSummary(ce *adaptertest.TestCloudEventsClient) []string {
+	var summary []string
+	for _, e := range ce.Sent() {
+		s := e.Type()[strings.LastIndex(e.Type(), ".")+1:] + " " + e.ID()
+		if id, ok := e.Extensions()[retractedIDExtension]; ok {
+			s += " " + id.(string)
+		}
+		summary = append(summary, s)
+	}
+	return summary
+}
+
+// blockHash returns the hash of a block mined by a fakeNode.
+func blockHash(fork, number uint64) string {
+	return fmt.Sprintf("0x%064x", 0xb10c000+fork<<20+number)
+}
+
+func TestEthereumAdapterRetractsOrphanedBlocks(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.source = "eip155:1"
+	a.next = 3
+
+	node.mine()
+	node.mine()
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+
+	// Block 4 is replaced, and the chain grows past it.
+	node.reorg(1)
+	node.mine()
+	node.mine()
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+
+	want := []string{
+		"block " + blockHash(0, 3),
+		"block " + blockHash(0, 4),
+		"reorg " + blockHash(0, 4) + "-reorg",
+		"retracted " + blockHash(0, 4) + "-retracted " + blockHash(0, 4),
+		"block " + blockHash(1, 4),
+		"block " + blockHash(1, 5),
+	}
+	if diff := cmp.Diff(want, sentSummary(ce)); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+
+	var reorg reorgEventData
+	if err := json.Unmarshal(ce.Sent()[2].Data(), &reorg); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantReorg := reorgEventData{
+		ForkBlockNumber: 3,
+		ForkBlockHash:   blockHash(0, 3),
+		Orphaned:        []orphanedBlock{{Number: 4, Hash: blockHash(0, 4)}},
+	}
+	if diff := cmp.Diff(wantReorg, reorg); diff != "" {
+		t.Errorf("unexpected reorg data (-want, +got) = %v", diff)
+	}
+
+	var retracted retractedEventData
+	if err := json.Unmarshal(ce.Sent()[3].Data(), &retracted); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantRetracted := retractedEventData{
+		ID:          blockHash(0, 4),
+		Type:        ethereumBlockEventType,
+		Source:      "eip155:1",
+		BlockNumber: 4,
+		BlockHash:   blockHash(0, 4),
+	}
+	if diff := cmp.Diff(wantRetracted, retracted); diff != "" {
+		t.Errorf("unexpected retraction data (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterRetractsOrphanedLogs(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q]}`, tokenAddress)
+	a.abiJSON = tokenABI
+	if err := a.setupContracts(); err != nil {
+		t.Fatalf("setupContracts() = %v", err)
+	}
+	a.source = "eip155:1"
+	a.next = 3
+
+	node.mine(fakeLog(tokenAddress, transferTopic, 1))
+	node.mine(fakeLog(tokenAddress, transferTopic, 2), fakeLog(tokenAddress, approvalTopic, 3))
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+
+	// The orphaned block had no log, its replacement has one.
+	node.mine()
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	node.reorg(2)
+	node.mine(fakeLog(tokenAddress, transferTopic, 4))
+	node.mine(fakeLog(tokenAddress, transferTopic, 5))
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+
+	want := []string{
+		"log " + blockHash(0, 3) + "-0",
+		"log " + blockHash(0, 4) + "-0",
+		"log " + blockHash(0, 4) + "-1",
+		"reorg " + blockHash(0, 4) + "-reorg",
+		"retracted " + blockHash(0, 4) + "-1-retracted " + blockHash(0, 4) + "-1",
+		"retracted " + blockHash(0, 4) + "-0-retracted " + blockHash(0, 4) + "-0",
+		"log " + blockHash(1, 4) + "-0",
+		"log " + blockHash(1, 5) + "-0",
+	}
+	if diff := cmp.Diff(want, sentSummary(ce)); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterStreamsReorgs(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "ws"+strings.TrimPrefix(server.URL, "http"))
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine()
+		node.mine()
+		waitForEvents(t, ce, 2)
+
+		// The new head replaces the last one.
+		node.reorg(1)
+		node.mine()
+		waitForEvents(t, ce, 5)
+		node.mine()
+		waitForEvents(t, ce, 6)
+	})
+
+	want := []string{
+		"block " + blockHash(0, 3),
+		"block " + blockHash(0, 4),
+		"reorg " + blockHash(0, 4) + "-reorg",
+		"retracted " + blockHash(0, 4) + "-retracted " + blockHash(0, 4),
+		"block " + blockHash(1, 4),
+		"block " + blockHash(1, 5),
+	}
+	if diff := cmp.Diff(want, sentSummary(ce)); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterReorgWindow(t *testing.T) {
+	a := &ethereumAdapter{reorgWindow: 3}
+	for i := uint64(1); i <= 5; i++ {
+		a.record(i, blockHash(0, i), blockHash(0, i-1), &sentEvent{id: blockHash(0, i)})
+	}
+	// Out of order blocks are ignored.
+	a.record(2, blockHash(1, 2), blockHash(0, 1), nil)
+
+	var got []uint64
+	for _, r := range a.recent {
+		got = append(got, r.number)
+	}
+	if diff := cmp.Diff([]uint64{3, 4, 5}, got); diff != "" {
+		t.Errorf("unexpected recorded blocks (-want, +got) = %v", diff)
+	}
+
+	for _, tc := range []struct {
+		block *ethBlock
+		want  bool
+	}{
+		{block: &ethBlock{Number: 6, ParentHash: blockHash(0, 5)}, want: true},
+		{block: &ethBlock{Number: 6, ParentHash: blockHash(1, 5)}, want: false},
+		{block: &ethBlock{Number: 5, ParentHash: blockHash(0, 4)}, want: false},
+		{block: &ethBlock{Number: 8, ParentHash: blockHash(0, 7)}, want: true},
+	} {
+		if got := a.extends(tc.block); got != tc.want {
+			t.Errorf("extends(%d, %s) = %v, want %v", tc.block.Number, tc.block.ParentHash, got, tc.want)
+		}
+	}
+}
+
+func TestEthereumAdapterStreamsRemovedLogs(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "ws"+strings.TrimPrefix(server.URL, "http"))
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q]}`, tokenAddress)
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine(fakeLog(tokenAddress, transferTopic, 1))
+		waitForEvents(t, ce, 1)
+
+		// The removed log is retracted, and its replacement emitted.
+		node.reorg(1)
+		node.mine(fakeLog(tokenAddress, approvalTopic, 2))
+		waitForEvents(t, ce, 4)
+	})
+
+	want := []string{
+		"log " + blockHash(0, 3) + "-0",
+		"reorg " + blockHash(0, 3) + "-reorg",
+		"retracted " + blockHash(0, 3) + "-0-retracted " + blockHash(0, 3) + "-0",
+		"log " + blockHash(1, 3) + "-0",
+	}
+	if diff := cmp.Diff(want, sentSummary(ce)); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+}
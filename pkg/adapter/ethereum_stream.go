@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+const (
+	// minReconnectDelay and maxReconnectDelay bound the exponential backoff
+	// between two attempts to reconnect a lost stream.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// stream subscribes to new blocks over a WebSocket connection until ctx is
+// done, reconnecting with an exponential backoff whenever the connection is
+// lost.
+func (a *ethereumAdapter) stream(ctx context.Context) error {
+	defer a.saveCheckpoint(context.Background(), true)
+
+	delay := a.minReconnectDelay
+	for {
+		received, err := a.streamOnce(ctx)
+		if ctx.Err() != nil {
+			a.logger.Infof("Streaming stopped")
+			return nil
+		}
+		if a.reachedEnd() {
+			a.saveCheckpoint(ctx, true)
+			a.logger.Infof("Reached end block %d, streaming stopped", *a.endBlock)
+			<-ctx.Done()
+			return nil
+		}
+		if received {
+			delay = a.minReconnectDelay
+		}
+		a.reportConnection(ctx, err)
+		a.logger.Errorf("Stream interrupted, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			a.logger.Infof("Streaming stopped")
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// streamOnce subscribes to new heads, or to contract logs, over a new
+// connection and emits them until the connection is lost or the end block is
+// reached. It reports whether any notification was received, so that the
+// caller can tell a flapping connection from a working one.
+func (a *ethereumAdapter) streamOnce(ctx context.Context) (bool, error) {
+	e := a.rpc.best()
+	if e == nil {
+		return false, errNoEndpoint
+	}
+	ws, err := jsonrpc.DialWebSocket(ctx, e.URL, e.options()...)
+	if err != nil {
+		a.rpc.fail(e, err)
+		return false, err
+	}
+	defer ws.Close()
+
+	if err := a.rpc.verify(ctx, e, ws); err != nil {
+		return false, err
+	}
+	a.rpc.use(e)
+
+	if err := a.init(ctx, ws); err != nil {
+		return false, err
+	}
+	a.reportConnection(ctx, nil)
+
+	// Backfill the blocks before the head first, so that notifications do
+	// not pile up in the subscription meanwhile.
+	if err := a.catchUpHead(ctx, ws); err != nil {
+		return false, err
+	}
+	if a.reachedEnd() {
+		return false, nil
+	}
+
+	// Logs are streamed as they are produced at the latest finality level.
+	// Otherwise, new heads tell when more blocks become final enough.
+	params := []interface{}{"newHeads"}
+	handle := a.handleHead
+	if a.filter != nil && a.finality == sourcesv1alpha1.FinalityLevelLatest {
+		params = []interface{}{"logs", a.filter}
+		handle = a.handleLogNotification
+	}
+	notifications := make(chan json.RawMessage)
+	if _, err := ws.Subscribe(ctx, notifications, "eth_subscribe", params...); err != nil {
+		return false, fmt.Errorf("failed to subscribe to %s: %w", params[0], err)
+	}
+
+	// Fill in the blocks produced before subscribing, after retracting the
+	// events of blocks orphaned in the meantime. Notifications received in
+	// the meantime are queued by the subscription, so none is missed.
+	if err := a.catchUpHead(ctx, ws); err != nil {
+		return false, err
+	}
+	a.logger.Infof("Streaming chain %s from block %d through %s", a.source, a.next, e.name())
+
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			return received, nil
+		case <-ws.Done():
+			a.rpc.fail(e, ws.Err())
+			return received, ws.Err()
+		case <-a.rpc.changed:
+			if best := a.rpc.best(); best != e {
+				return received, fmt.Errorf("switching to endpoint %s", best.name())
+			}
+		case raw := <-notifications:
+			received = true
+			if err := handle(ctx, ws, raw); err != nil {
+				return received, err
+			}
+			if a.reachedEnd() {
+				return received, nil
+			}
+			a.saveCheckpoint(ctx, false)
+		}
+	}
+}
+
+// handleHead emits the block announced by a newHeads notification, after
+// any block skipped since the last one. A head that does not extend the last
+// emitted block reveals a reorg, which is handled first. Unless blocks are
+// emitted at the latest finality level, the head only tells that more
+// blocks may have become final enough.
+func (a *ethereumAdapter) handleHead(ctx context.Context, rpc rpcCaller, raw json.RawMessage) error {
+	header, err := parseBlock(raw)
+	if err != nil {
+		return err
+	}
+
+	number := uint64(header.Number)
+	a.observeHead(number)
+	if a.finality != sourcesv1alpha1.FinalityLevelLatest {
+		final, ok, err := a.finalHead(ctx, rpc, number)
+		if err != nil || !ok {
+			return err
+		}
+		if err := a.checkReorg(ctx, rpc); err != nil {
+			return err
+		}
+		return a.catchUp(ctx, rpc, final)
+	}
+
+	if !a.extends(header) {
+		if err := a.checkReorg(ctx, rpc); err != nil {
+			return err
+		}
+	}
+	if number < a.next {
+		// Already emitted while catching up.
+		return nil
+	}
+	if a.endBlock != nil && number > *a.endBlock {
+		return a.catchUp(ctx, rpc, *a.endBlock)
+	}
+	a.observeFinal(number)
+	if number > 0 {
+		if err := a.catchUp(ctx, rpc, number-1); err != nil {
+			return err
+		}
+	}
+	// Notifications only hold the header, the block is read the way it is
+	// when polling so that events hold the same data either way.
+	block, err := blockByNumber(ctx, rpc, number)
+	if err != nil {
+		return err
+	}
+	if !a.extends(block) {
+		// Orphaned while catching up, the next head emits the canonical
+		// block.
+		return nil
+	}
+	if err := a.emitBlock(ctx, block); err != nil {
+		return fmt.Errorf("failed to emit block %d: %w", number, err)
+	}
+	a.next = number + 1
+	return nil
+}
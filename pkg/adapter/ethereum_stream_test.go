@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+)
+
+func TestEthereumAdapterStreamsNewHeads(t *testing.T) {
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "ws"+strings.TrimPrefix(server.URL, "http"))
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine()
+		node.mine()
+		waitForEvents(t, ce, 2)
+
+		// Blocks mined while disconnected are emitted after reconnecting.
+		node.mu.Lock()
+		node.rejecting = true
+		node.mu.Unlock()
+		node.disconnect()
+		node.mine()
+		node.mine()
+		node.mu.Lock()
+		node.rejecting = false
+		node.mu.Unlock()
+
+		node.waitForCalls(t, "eth_subscribe", 2)
+		waitForEvents(t, ce, 4)
+		node.mine()
+		waitForEvents(t, ce, 5)
+	})
+
+	if diff := cmp.Diff([]string{"3", "4", "5", "6", "7"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterStreamsAfterBackfill(t *testing.T) {
+	node := newFakeNode(1, 6)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, "ws"+strings.TrimPrefix(server.URL, "http"))
+	start := uint64(2)
+	a.startBlock = &start
+
+	runAdapter(t, a, func() {
+		// Past blocks are backfilled, then new heads follow without any gap or
+		// duplicate.
+		node.waitForCalls(t, "eth_subscribe", 1)
+		node.waitForCalls(t, "eth_blockNumber", 3)
+		node.mine()
+		node.mine()
+		waitForEvents(t, ce, 6)
+	})
+
+	if diff := cmp.Diff([]string{"2", "3", "4", "5", "6", "7"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterStreamsFullBlocks(t *testing.T) {
+	node := newFakeNode(1, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.source = "eip155:1"
+
+	// The genesis block of a fresh chain is the first head, and only its
+	// header is notified.
+	raw, err := json.Marshal(header(node.blocks[0]))
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if err := a.handleHead(context.Background(), jsonrpc.NewClient(server.URL), raw); err != nil {
+		t.Fatalf("handleHead() = %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"0"}, sentSubjects(ce)); diff != "" {
+		t.Fatalf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+	var block map[string]interface{}
+	if err := json.Unmarshal(ce.Sent()[0].Data(), &block); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	if _, ok := block["transactions"]; !ok {
+		t.Errorf("streamed block = %v, want the block polling emits", block)
+	}
+}
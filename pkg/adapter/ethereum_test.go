@@ -0,0 +1,519 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/pkg/logging"
+	pkgtesting "knative.dev/pkg/reconciler/testing"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+// fakeNode is an in-memory Ethereum JSON-RPC endpoint.
+type fakeNode struct {
+	mu      sync.Mutex
+	chainID uint64
+	blocks  []map[string]interface{}
+	logs    []map[string]interface{}
+	// fork distinguishes the hashes of blocks mined after a reorg.
+	fork uint64
+	// tags are the numbers of the blocks reported under block tags such as
+	// "finalized".
+	tags map[string]uint64
+	// failing makes every request fail with a JSON-RPC error.
+	failing bool
+	// maxLogs limits the number of logs returned by eth_getLogs, when not
+	// zero. Requests matching more logs fail.
+	maxLogs int
+
+	callCounter
+
+	// subscribers are the WebSocket connections subscribed to new heads, or
+	// to the logs matching a filter.
+	subscribers map[*fakeConn]*logFilter
+	// rejecting makes the node refuse new WebSocket connections.
+	rejecting bool
+}
+
+// fakeConn is a WebSocket connection to a fakeNode.
+type fakeConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (c *fakeConn) write(v interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.WriteJSON(v)
+}
+
+func newFakeNode(chainID uint64, blocks int) *fakeNode {
+	n := &fakeNode{
+		chainID:     chainID,
+		subscribers: make(map[*fakeConn]*logFilter),
+		tags:        make(map[string]uint64),
+	}
+	for i := 0; i < blocks; i++ {
+		n.mine()
+	}
+	return n
+}
+
+// mine appends a new block containing logs on top of the current head.
+func (n *fakeNode) mine(logs ...map[string]interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	number := uint64(len(n.blocks))
+	parent := fmt.Sprintf("0x%064x", 0)
+	if number > 0 {
+		parent = n.blocks[number-1]["hash"].(string)
+	}
+	block := map[string]interface{}{
+		"number":       hexUint64(number),
+		"hash":         fmt.Sprintf("0x%064x", 0xb10c000+n.fork<<20+number),
+		"parentHash":   parent,
+		"timestamp":    hexUint64(1600000000 + 12*number),
+		"size":         hexUint64(0x220),
+		"transactions": []string{},
+	}
+	n.blocks = append(n.blocks, block)
+
+	for i, l := range logs {
+		l["blockNumber"] = block["number"]
+		l["blockHash"] = block["hash"]
+		l["transactionHash"] = fmt.Sprintf("0x%064x", 0x7e000000+number*1000+uint64(i))
+		l["transactionIndex"] = hexUint64(i)
+		l["logIndex"] = hexUint64(i)
+		l["removed"] = false
+		n.logs = append(n.logs, l)
+	}
+
+	for c, filter := range n.subscribers {
+		if filter == nil {
+			c.notify("0x1", header(block))
+			continue
+		}
+		for _, l := range logs {
+			if filter.matches(l) {
+				c.notify("0x2", l)
+			}
+		}
+	}
+}
+
+// header returns the header of a block, as newHeads notifications hold it.
+func header(block map[string]interface{}) map[string]interface{} {
+	h := make(map[string]interface{}, len(block))
+	for k, v := range block {
+		if k != "size" && k != "transactions" {
+			h[k] = v
+		}
+	}
+	return h
+}
+
+// reorg orphans the last depth blocks. Subscribers to logs are notified of
+// the removed logs.
+func (n *fakeNode) reorg(depth int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.blocks = n.blocks[:len(n.blocks)-depth]
+	n.fork++
+
+	var removed []map[string]interface{}
+	logs := n.logs[:0]
+	for _, l := range n.logs {
+		if int(l["blockNumber"].(hexUint64)) < len(n.blocks) {
+			logs = append(logs, l)
+			continue
+		}
+		r := make(map[string]interface{}, len(l))
+		for k, v := range l {
+			r[k] = v
+		}
+		r["removed"] = true
+		removed = append(removed, r)
+	}
+	n.logs = logs
+
+	for c, filter := range n.subscribers {
+		for _, l := range removed {
+			if filter != nil && filter.matches(l) {
+				c.notify("0x2", l)
+			}
+		}
+	}
+}
+
+func (c *fakeConn) notify(subscription string, result interface{}) {
+	c.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "eth_subscription",
+		"params": map[string]interface{}{
+			"subscription": subscription,
+			"result":       result,
+		},
+	})
+}
+
+// matches reports whether a log built by fakeNode.mine matches the filter.
+func (f *logFilter) matches(l map[string]interface{}) bool {
+	number := l["blockNumber"].(hexUint64)
+	if f.FromBlock != nil && number < *f.FromBlock || f.ToBlock != nil && number > *f.ToBlock {
+		return false
+	}
+	if len(f.Address) > 0 && !containsFold(f.Address, l["address"].(string)) {
+		return false
+	}
+	topics := l["topics"].([]string)
+	for i, want := range f.Topics {
+		if len(want) == 0 {
+			continue
+		}
+		if i >= len(topics) || !containsFold(want, topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// disconnect closes all WebSocket connections.
+func (n *fakeNode) disconnect() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for c := range n.subscribers {
+		c.conn.Close()
+		delete(n.subscribers, c)
+	}
+}
+
+type fakeRequest struct {
+	ID     uint64            `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		n.serveWebSocket(w, r)
+		return
+	}
+
+	var req fakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(n.handle(&req, nil))
+}
+
+func (n *fakeNode) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	rejecting := n.rejecting
+	n.mu.Unlock()
+	if rejecting {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &fakeConn{conn: conn}
+	defer func() {
+		n.mu.Lock()
+		delete(n.subscribers, c)
+		n.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var req fakeRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		c.write(n.handle(&req, c))
+	}
+}
+
+// handle returns the response to a request, received over conn when it is
+// not nil.
+func (n *fakeNode) handle(req *fakeRequest, conn *fakeConn) map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.called(req.Method)
+	if n.failing {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"error":   map[string]interface{}{"code": -32000, "message": "unavailable"},
+		}
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "eth_chainId":
+		result = hexUint64(n.chainID)
+	case "eth_blockNumber":
+		result = hexUint64(len(n.blocks) - 1)
+	case "eth_getBlockByNumber":
+		var number hexUint64
+		if err := json.Unmarshal(req.Params[0], &number); err != nil {
+			var tag string
+			json.Unmarshal(req.Params[0], &tag)
+			tagged, ok := n.tags[tag]
+			if !ok {
+				break
+			}
+			number = hexUint64(tagged)
+		}
+		if int(number) < len(n.blocks) {
+			result = n.blocks[number]
+		}
+	case "eth_getLogs":
+		var filter logFilter
+		json.Unmarshal(req.Params[0], &filter)
+		logs := []map[string]interface{}{}
+		for _, l := range n.logs {
+			if filter.matches(l) {
+				logs = append(logs, l)
+			}
+		}
+		if n.maxLogs > 0 && len(logs) > n.maxLogs {
+			return map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      req.ID,
+				"error":   map[string]interface{}{"code": -32005, "message": fmt.Sprintf("query returned more than %d results", n.maxLogs)},
+			}
+		}
+		result = logs
+	case "eth_subscribe":
+		var kind string
+		json.Unmarshal(req.Params[0], &kind)
+		if conn != nil && kind == "newHeads" {
+			n.subscribers[conn] = nil
+			result = "0x1"
+		}
+		if conn != nil && kind == "logs" {
+			filter := &logFilter{}
+			json.Unmarshal(req.Params[1], filter)
+			n.subscribers[conn] = filter
+			result = "0x2"
+		}
+	}
+
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+		"result":  result,
+	}
+}
+
+func newTestEthereumAdapter(t *testing.T, ce *adaptertest.TestCloudEventsClient, rpcURL string) *ethereumAdapter {
+	env := ethereumEnvConfig{
+		chainEnvConfig: chainEnvConfig{
+			EnvConfig: adapter.EnvConfig{
+				Namespace: "default",
+			},
+			EnvRPCURL: rpcURL,
+		},
+		EnvMode:                string(sourcesv1alpha1.IngestionModePolling),
+		EnvPollInterval:        10 * time.Millisecond,
+		EnvReorgWindow:         64,
+		EnvFinality:            string(sourcesv1alpha1.FinalityLevelLatest),
+		EnvLogsChunkSize:       2000,
+		EnvHealthCheckInterval: 10 * time.Millisecond,
+		EnvMaxHeadLag:          5,
+		EnvMaxErrorRate:        0.5,
+	}
+	if strings.HasPrefix(rpcURL, "ws") {
+		env.EnvMode = string(sourcesv1alpha1.IngestionModeStreaming)
+	}
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+	logger := zap.NewExample().Sugar()
+	ctx = logging.WithLogger(ctx, logger)
+
+	a := NewEthereumAdapter(ctx, &env, ce).(*ethereumAdapter)
+	a.minReconnectDelay = 10 * time.Millisecond
+	if rpcURL != "" {
+		if err := a.setupEndpoints(); err != nil {
+			t.Fatalf("setupEndpoints() = %v", err)
+		}
+	}
+	return a
+}
+
+// sentSubjects returns the subjects of the sent events, in order.
+func sentSubjects(ce *adaptertest.TestCloudEventsClient) []string {
+	var subjects []string
+	for _, e := range ce.Sent() {
+		subjects = append(subjects, e.Subject())
+	}
+	return subjects
+}
+
+func TestEthereumAdapterEmitsNewBlocks(t *testing.T) {
+	node := newFakeNode(5, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+
+	runAdapter(t, a, func() {
+		// Blocks mined before the adapter started are not emitted.
+		node.waitForCalls(t, "eth_blockNumber", 2)
+		node.mine()
+		node.mine()
+		waitForEvents(t, ce, 2)
+	})
+
+	var got []string
+	for _, e := range ce.Sent() {
+		if e.Type() != ethereumBlockEventType {
+			t.Errorf("event type = %q, want %q", e.Type(), ethereumBlockEventType)
+		}
+		if e.Source() != "eip155:5" {
+			t.Errorf("event source = %q, want %q", e.Source(), "eip155:5")
+		}
+		var data ethBlock
+		if err := json.Unmarshal(e.Data(), &data); err != nil {
+			t.Fatalf("Could not unmarshal sent data: %v", err)
+		}
+		if data.Hash != e.ID() {
+			t.Errorf("event ID = %q, want block hash %q", e.ID(), data.Hash)
+		}
+		wantExt := map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     "5",
+			blockNumberExtension: int32(data.Number),
+			blockHashExtension:   data.Hash,
+		}
+		if diff := cmp.Diff(wantExt, e.Extensions()); diff != "" {
+			t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+		}
+		got = append(got, e.Subject())
+	}
+	if diff := cmp.Diff([]string{"3", "4"}, got); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestEthereumAdapterRejectsOtherChain(t *testing.T) {
+	node := newFakeNode(5, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.chainID = "1"
+
+	if err := a.Start(context.Background()); err == nil {
+		t.Fatal("Start() = nil, want an error")
+	}
+	if len(ce.Sent()) != 0 {
+		t.Errorf("sent %d events, want none", len(ce.Sent()))
+	}
+}
+
+func TestEthereumAdapterRetriesUndeliveredBlocks(t *testing.T) {
+	node := newFakeNode(1, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.source = "eip155:1"
+	a.next = 1
+
+	node.mine()
+	node.mine()
+
+	// A failing node stops the poll without advancing.
+	node.mu.Lock()
+	node.failing = true
+	node.mu.Unlock()
+	if err := a.poll(context.Background()); err == nil {
+		t.Fatal("poll() = nil, want an error")
+	}
+	if a.next != 1 {
+		t.Fatalf("next = %d, want 1", a.next)
+	}
+
+	node.mu.Lock()
+	node.failing = false
+	node.mu.Unlock()
+	if err := a.poll(context.Background()); err != nil {
+		t.Fatalf("poll() = %v", err)
+	}
+	if a.next != 3 {
+		t.Errorf("next = %d, want 3", a.next)
+	}
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("sent %d events, want 2", got)
+	}
+}
+
+func TestEthereumAdapterBackfillsFromStartBlock(t *testing.T) {
+	node := newFakeNode(1, 9)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	start, end := uint64(2), uint64(6)
+	a.startBlock, a.endBlock = &start, &end
+
+	runAdapter(t, a, func() {
+		waitForEvents(t, ce, 5)
+	})
+
+	// Blocks past the end block are not emitted.
+	if !a.reachedEnd() {
+		t.Errorf("next = %d, want past the end block", a.next)
+	}
+
+	if diff := cmp.Diff([]string{"2", "3", "4", "5", "6"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected block subjects (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Names of the CloudEvent extension attributes describing the chain data an
+// event is about, which Trigger filters match exactly without parsing the
+// event data.
+const (
+	chainIDExtension     = "chainid"
+	blockNumberExtension = "blocknumber"
+	blockHashExtension   = "blockhash"
+	txHashExtension      = "txhash"
+	logIndexExtension    = "logindex"
+	contractExtension    = "contract"
+	eventNameExtension   = "eventname"
+	fromAddrExtension    = "fromaddr"
+	toAddrExtension      = "toaddr"
+	validationExtension  = "validationcode"
+	accountExtension     = "account"
+	epochExtension       = "epoch"
+)
+
+// maxExtensionNameLength is the length CloudEvents attribute names should
+// not exceed.
+const maxExtensionNameLength = 20
+
+// chainExtensions are the extension attributes of an event describing the
+// chain data it is about. Empty fields are left out of the event.
+type chainExtensions struct {
+	chainID     string
+	blockNumber *uint64
+	blockHash   string
+	txHash      string
+	logIndex    *uint64
+	// contract, from and to are addresses, in lowercase so that filters
+	// do not depend on their checksum encoding.
+	contract  string
+	eventName string
+	from      string
+	to        string
+	// validationCode is the outcome of the validation of a Fabric
+	// transaction, e.g. VALID.
+	validationCode string
+	// account is the base58 address of a Solana account, kept as is as
+	// base58 is case sensitive.
+	account string
+	// epoch is the epoch of a beacon chain the event is about.
+	epoch *uint64
+}
+
+// apply sets the extension attributes on an event.
+func (x *chainExtensions) apply(event *cloudevents.Event) error {
+	attrs := []struct {
+		name  string
+		value interface{}
+	}{
+		{chainIDExtension, x.chainID},
+		{blockNumberExtension, extensionNumber(x.blockNumber)},
+		{blockHashExtension, x.blockHash},
+		{txHashExtension, x.txHash},
+		{logIndexExtension, extensionNumber(x.logIndex)},
+		{contractExtension, strings.ToLower(x.contract)},
+		{eventNameExtension, x.eventName},
+		{fromAddrExtension, strings.ToLower(x.from)},
+		{toAddrExtension, strings.ToLower(x.to)},
+		{validationExtension, x.validationCode},
+		{accountExtension, x.account},
+		{epochExtension, extensionNumber(x.epoch)},
+	}
+	for _, attr := range attrs {
+		if attr.value == nil || attr.value == "" {
+			continue
+		}
+		if err := setExtension(event, attr.name, attr.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extensionNumber returns a number as a CloudEvents integer, or as a decimal
+// string beyond the 32 bits of CloudEvents integers, or nil.
+func extensionNumber(n *uint64) interface{} {
+	switch {
+	case n == nil:
+		return nil
+	case *n > math.MaxInt32:
+		return strconv.FormatUint(*n, 10)
+	default:
+		return int32(*n)
+	}
+}
+
+// setExtension sets an extension attribute on an event, which must be named
+// and valued following the CloudEvents rules.
+func setExtension(event *cloudevents.Event, name string, value interface{}) error {
+	if err := validateExtensionName(name); err != nil {
+		return err
+	}
+	switch value.(type) {
+	case string, int32:
+	default:
+		return fmt.Errorf("extension %s: value %v is neither a string nor an integer", name, value)
+	}
+	if err := event.Context.SetExtension(name, value); err != nil {
+		return fmt.Errorf("extension %s: %w", name, err)
+	}
+	return nil
+}
+
+// validateExtensionName checks that a name is made of lowercase ASCII
+// letters and digits, and is not too long, as required of CloudEvents
+// attribute names.
+func validateExtensionName(name string) error {
+	if name == "" {
+		return errors.New("extension names must not be empty")
+	}
+	if len(name) > maxExtensionNameLength {
+		return fmt.Errorf("extension name %q is longer than %d characters", name, maxExtensionNameLength)
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("extension name %q must only contain lowercase letters and digits", name)
+		}
+	}
+	return nil
+}
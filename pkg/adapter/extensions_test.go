@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChainExtensionsApply(t *testing.T) {
+	blockNumber, logIndex, epoch := uint64(19000000), uint64(7), uint64(270000)
+	ext := chainExtensions{
+		chainID:     "1",
+		blockNumber: &blockNumber,
+		blockHash:   "0xb10c",
+		txHash:      "0x7e",
+		logIndex:    &logIndex,
+		contract:    "0xdAC17F958D2ee523a2206206994597C13D831ec7",
+		eventName:   "Transfer",
+		from:        "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		// Set on Fabric transactions, Solana and beacon events only,
+		// tested along for brevity.
+		validationCode: "VALID",
+		account:        "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",
+		epoch:          &epoch,
+	}
+
+	event := cloudevents.NewEvent()
+	if err := ext.apply(&event); err != nil {
+		t.Fatalf("apply() = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"chainid":        "1",
+		"blocknumber":    int32(19000000),
+		"blockhash":      "0xb10c",
+		"txhash":         "0x7e",
+		"logindex":       int32(7),
+		"contract":       "0xdac17f958d2ee523a2206206994597c13d831ec7",
+		"eventname":      "Transfer",
+		"fromaddr":       "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"validationcode": "VALID",
+		"account":        "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",
+		"epoch":          int32(270000),
+	}
+	if diff := cmp.Diff(want, event.Extensions()); diff != "" {
+		t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+	}
+}
+
+func TestChainExtensionsLargeNumbers(t *testing.T) {
+	slot := uint64(1) << 40
+	ext := chainExtensions{blockNumber: &slot}
+
+	event := cloudevents.NewEvent()
+	if err := ext.apply(&event); err != nil {
+		t.Fatalf("apply() = %v", err)
+	}
+	if got, want := event.Extensions()[blockNumberExtension], "1099511627776"; got != want {
+		t.Errorf("blocknumber = %#v, want %#v", got, want)
+	}
+}
+
+func TestExtensionNames(t *testing.T) {
+	for _, name := range []string{
+		chainIDExtension,
+		blockNumberExtension,
+		blockHashExtension,
+		txHashExtension,
+		logIndexExtension,
+		contractExtension,
+		eventNameExtension,
+		fromAddrExtension,
+		toAddrExtension,
+		validationExtension,
+		accountExtension,
+		epochExtension,
+		finalityExtension,
+		retractedIDExtension,
+	} {
+		if err := validateExtensionName(name); err != nil {
+			t.Errorf("validateExtensionName(%q) = %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"", "chainId", "block_number", "averyveryverylongextension"} {
+		if err := validateExtensionName(name); err == nil {
+			t.Errorf("validateExtensionName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestSetExtensionRejectsOtherValues(t *testing.T) {
+	event := cloudevents.NewEvent()
+	if err := setExtension(&event, "amount", 1.5); err == nil {
+		t.Error("setExtension() = nil, want an error for a float value")
+	}
+	if err := setExtension(&event, "amount", "1.5"); err != nil {
+		t.Errorf("setExtension() = %v", err)
+	}
+}
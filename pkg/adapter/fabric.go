@@ -0,0 +1,406 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	ab "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"knative.dev/eventing/pkg/adapter/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/fabric"
+)
+
+type fabricEnvConfig struct {
+	chainEnvConfig
+
+	// Environment variable containing the kind of blocks to read, full or
+	// filtered
+	EnvBlockType string `envconfig:"BLOCKCHAIN_FABRIC_BLOCK_TYPE" default:"full"`
+	// Environment variable containing the ID of the MSP of the client
+	// identity
+	EnvMSPID string `envconfig:"BLOCKCHAIN_FABRIC_MSP_ID"`
+	// Environment variable containing the PEM encoded certificate of the
+	// client identity
+	EnvCert string `envconfig:"BLOCKCHAIN_FABRIC_CERT"`
+	// Environment variable containing the PEM encoded private key of the
+	// client identity
+	EnvKey string `envconfig:"BLOCKCHAIN_FABRIC_KEY"`
+	// Environment variable containing the PEM encoded certificates of the
+	// TLS CAs of the peers, the system ones are used when not set
+	EnvTLSRootCert string `envconfig:"BLOCKCHAIN_FABRIC_TLS_ROOT_CERT"`
+	// Environment variable containing the JSON encoded list of chaincode
+	// event subscriptions. Transactions are emitted when not set
+	EnvChaincodeEvents string `envconfig:"BLOCKCHAIN_FABRIC_CHAINCODE_EVENTS"`
+}
+
+// NewFabricEnvConfig function reads env variables defined in
+// fabricEnvConfig structure and returns accessor interface
+func NewFabricEnvConfig() adapter.EnvConfigAccessor {
+	return &fabricEnvConfig{}
+}
+
+// fabricAdapter reads the blocks of a Hyperledger Fabric channel from the
+// Deliver service of its peers, and converts their transactions, or the
+// chaincode events they set, to CloudEvents. Peers push committed blocks as
+// they come, so there is no polling, and committed blocks are final, so
+// there are no reorgs.
+type fabricAdapter struct {
+	emitter
+
+	rpcURL            string
+	endpointsJSON     string
+	channelID         string
+	blockType         sourcesv1alpha1.FabricBlockType
+	mspID             string
+	certPEM           string
+	keyPEM            string
+	tlsRootCertPEM    string
+	subscriptionsJSON string
+	minReconnectDelay time.Duration
+	startBlock        *uint64
+
+	// endpoints are the peers, in priority order.
+	endpoints []*endpoint
+	// signer signs the requests of the client identity.
+	signer *fabric.Signer
+	// subscriptions select the chaincode events to emit, if any.
+	subscriptions []chaincodeSubscription
+
+	// source is the CloudEvent source of the emitted events.
+	source string
+	// active is the name of the peer last read from.
+	active string
+	// head is the number of the newest block received.
+	head uint64
+	// nextTx is the index of the next transaction of the next block to
+	// emit.
+	nextTx int
+}
+
+// NewFabricAdapter returns the instance of fabricAdapter that implements adapter.Adapter interface
+func NewFabricAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	env := processed.(*fabricEnvConfig)
+
+	a := &fabricAdapter{
+		emitter:           newEmitter(ctx, &env.chainEnvConfig, ceClient),
+		rpcURL:            env.EnvRPCURL,
+		endpointsJSON:     env.EnvEndpoints,
+		channelID:         env.EnvChainID,
+		blockType:         sourcesv1alpha1.FabricBlockType(env.EnvBlockType),
+		mspID:             env.EnvMSPID,
+		certPEM:           env.EnvCert,
+		keyPEM:            env.EnvKey,
+		tlsRootCertPEM:    env.EnvTLSRootCert,
+		subscriptionsJSON: env.EnvChaincodeEvents,
+		minReconnectDelay: minReconnectDelay,
+		startBlock:        env.EnvStartBlock,
+	}
+	a.cursor = a
+	return a
+}
+
+func (a *fabricAdapter) Start(ctx context.Context) error {
+	if err := a.setup(); err != nil {
+		return err
+	}
+	return a.hold(ctx, func(ctx context.Context) error {
+		if err := a.init(ctx); err != nil {
+			return err
+		}
+		return a.stream(ctx)
+	})
+}
+
+// setup checks the settings of the adapter, and builds the identity and
+// the subscriptions they describe.
+func (a *fabricAdapter) setup() error {
+	if a.channelID == "" {
+		return errors.New("no channel given to read")
+	}
+	switch a.blockType {
+	case sourcesv1alpha1.FabricBlockTypeFull, sourcesv1alpha1.FabricBlockTypeFiltered:
+	default:
+		return fmt.Errorf("unsupported block type %q", a.blockType)
+	}
+
+	configs, err := endpointConfigs(a.rpcURL, a.endpointsJSON)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(configs, func(i, j int) bool {
+		return configs[i].Priority < configs[j].Priority
+	})
+	a.endpoints = make([]*endpoint, 0, len(configs))
+	for _, c := range configs {
+		a.endpoints = append(a.endpoints, &endpoint{endpointConfig: c})
+	}
+
+	signer, err := fabric.NewSigner(a.mspID, []byte(a.certPEM), []byte(a.keyPEM))
+	if err != nil {
+		return fmt.Errorf("invalid client identity: %w", err)
+	}
+	a.signer = signer
+
+	subscriptions, err := parseChaincodeSubscriptions(a.subscriptionsJSON)
+	if err != nil {
+		return err
+	}
+	a.subscriptions = subscriptions
+
+	if err := a.setupFilters(); err != nil {
+		return err
+	}
+
+	a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilyFabric, a.channelID)
+	return nil
+}
+
+// init resumes from the saved checkpoint. Without a checkpoint, blocks are
+// emitted from the start block, or from the one following the newest block
+// once the first peer is reached.
+func (a *fabricAdapter) init(ctx context.Context) error {
+	resumed, err := a.resume(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resumed:
+		a.logger.Infof("Resuming from checkpoint at block %d", a.next)
+	case a.startBlock != nil:
+		a.next, a.positioned = *a.startBlock, true
+		a.logger.Infof("Backfilling from block %d", a.next)
+	}
+	return nil
+}
+
+// seekInfo returns the blocks to request: from the next one up to the end
+// block, or from the newest one when the position is not known yet.
+func (a *fabricAdapter) seekInfo() *ab.SeekInfo {
+	seek := &ab.SeekInfo{
+		Start:    fabric.SeekNewest(),
+		Stop:     fabric.SeekSpecified(math.MaxUint64),
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+	}
+	if a.positioned {
+		seek.Start = fabric.SeekSpecified(a.next)
+	}
+	if a.endBlock != nil {
+		seek.Stop = fabric.SeekSpecified(*a.endBlock)
+	}
+	return seek
+}
+
+// stream reads blocks from the peers until ctx is done, trying them in
+// priority order and starting over with an exponential backoff whenever
+// none could be read from.
+func (a *fabricAdapter) stream(ctx context.Context) error {
+	defer a.saveCheckpoint(context.Background(), true)
+
+	delay := a.minReconnectDelay
+	for {
+		received, err := a.streamPeers(ctx)
+		if ctx.Err() != nil {
+			a.logger.Infof("Streaming stopped")
+			return nil
+		}
+		if a.reachedEnd() {
+			a.saveCheckpoint(ctx, true)
+			a.logger.Infof("Reached end block %d, streaming stopped", *a.endBlock)
+			<-ctx.Done()
+			return nil
+		}
+		if received {
+			delay = a.minReconnectDelay
+		}
+		a.reportConnection(ctx, err)
+		a.logger.Errorf("Stream interrupted, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			a.logger.Infof("Streaming stopped")
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// streamPeers reads blocks from the first peer that delivers any, in
+// priority order. It returns the error of the last attempt, and whether any
+// block was received.
+func (a *fabricAdapter) streamPeers(ctx context.Context) (bool, error) {
+	var err error
+	for _, e := range a.endpoints {
+		var received bool
+		received, err = a.streamOnce(ctx, e)
+		if received || ctx.Err() != nil || a.reachedEnd() {
+			return received, err
+		}
+		if err != nil {
+			a.logger.Warnf("Failed to read blocks from %s: %v", e.name(), err)
+		}
+	}
+	return false, err
+}
+
+// streamOnce requests blocks from a peer and emits them until the stream
+// ends, the end block is reached or ctx is done. It reports whether any
+// block was received, so that the caller can tell a flapping peer from a
+// working one.
+func (a *fabricAdapter) streamOnce(ctx context.Context, e *endpoint) (bool, error) {
+	conn, err := fabric.Dial(ctx, e.URL, []byte(a.tlsRootCertPEM))
+	if err != nil {
+		return false, fmt.Errorf("failed to dial %s: %w", e.name(), err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	filtered := a.blockType == sourcesv1alpha1.FabricBlockTypeFiltered
+	s, err := fabric.Deliver(ctx, conn, a.signer, a.channelID, a.seekInfo(), filtered)
+	if err != nil {
+		return false, fmt.Errorf("failed to request blocks from %s: %w", e.name(), err)
+	}
+
+	received := false
+	for {
+		resp, err := s.Recv()
+		switch {
+		case ctx.Err() != nil:
+			return received, nil
+		case errors.Is(err, io.EOF):
+			// The peer delivered every requested block.
+			return received, nil
+		case err != nil:
+			return received, fmt.Errorf("failed to read blocks from %s: %w", e.name(), err)
+		}
+
+		if !received {
+			received = true
+			a.active = e.name()
+			a.reportConnection(ctx, nil)
+			a.logger.Infof("Streaming channel %s from %s", a.channelID, e.name())
+		}
+		if err := a.handleBlock(ctx, resp); err != nil {
+			return received, err
+		}
+		if a.reachedEnd() {
+			return received, nil
+		}
+		a.saveCheckpoint(ctx, false)
+	}
+}
+
+// currentCheckpoint returns the position up to which events have been
+// delivered, or nil if nothing was delivered yet. The index of the last
+// delivered transaction is saved as the log index of a block whose
+// transactions are partly delivered.
+func (a *fabricAdapter) currentCheckpoint() *checkpoint.Checkpoint {
+	switch {
+	case a.source == "" || !a.positioned:
+		return nil
+	case a.nextTx > 0:
+		index := uint64(a.nextTx - 1)
+		return &checkpoint.Checkpoint{BlockNumber: a.next, LogIndex: &index}
+	case a.next == 0:
+		return nil
+	default:
+		return &checkpoint.Checkpoint{BlockNumber: a.next - 1}
+	}
+}
+
+// seek moves the adapter past the block, or the transaction, of a
+// checkpoint.
+func (a *fabricAdapter) seek(cp *checkpoint.Checkpoint) {
+	if cp.LogIndex != nil {
+		// Transactions of the block are partly delivered.
+		a.next, a.nextTx = cp.BlockNumber, int(*cp.LogIndex)+1
+	} else {
+		a.next, a.nextTx = cp.BlockNumber+1, 0
+	}
+	a.positioned = true
+}
+
+// chainProgress reports how the adapter keeps up with the channel.
+// Committed blocks are final, so the final block is the head.
+func (a *fabricAdapter) chainProgress() *checkpoint.Progress {
+	return &checkpoint.Progress{
+		ChainID:  a.channelID,
+		Head:     a.head,
+		Final:    a.head,
+		Endpoint: a.active,
+	}
+}
+
+// connectionFailure returns the report of a failure to read from the peers
+// of the channel, or nil if err is not one, e.g. when the sink returned an
+// error.
+func (a *fabricAdapter) connectionFailure(err error) *checkpoint.Connection {
+	var (
+		delivery  *deliveryError
+		statusErr *fabric.StatusError
+	)
+	switch {
+	case errors.As(err, &delivery):
+		return nil
+	case errors.As(err, &statusErr):
+		switch statusErr.Status {
+		case cb.Status_FORBIDDEN:
+			return &checkpoint.Connection{Reason: checkpoint.ReasonUnauthorized, Message: err.Error()}
+		case cb.Status_NOT_FOUND:
+			return &checkpoint.Connection{
+				Reason:  checkpoint.ReasonChainIDMismatch,
+				Message: fmt.Sprintf("peer does not serve channel %s", a.channelID),
+			}
+		case cb.Status_SERVICE_UNAVAILABLE:
+			return &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable, Message: err.Error()}
+		}
+		return nil
+	}
+	switch grpcCode(err) {
+	case codes.PermissionDenied, codes.Unauthenticated:
+		return &checkpoint.Connection{Reason: checkpoint.ReasonUnauthorized, Message: err.Error()}
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return &checkpoint.Connection{Reason: checkpoint.ReasonUnreachable, Message: err.Error()}
+	}
+	return nil
+}
+
+// grpcCode returns the code of the gRPC status error wrapped by err, or
+// codes.Unknown.
+func grpcCode(err error) codes.Code {
+	var s interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &s) {
+		return s.GRPCStatus().Code()
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/fabric"
+)
+
+var (
+	// fabricTransactionEventType is the CloudEvent type of the events
+	// emitted for every transaction.
+	fabricTransactionEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyFabric, sourcesv1alpha1.BlockchainEventKindTransaction)
+
+	// fabricChaincodeEventType is the CloudEvent type of the events
+	// emitted for every subscribed chaincode event.
+	fabricChaincodeEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyFabric, sourcesv1alpha1.BlockchainEventKindChaincodeEvent)
+)
+
+// chaincodeSubscription selects the events of a chaincode to emit.
+type chaincodeSubscription struct {
+	chaincodeName string
+	// eventName matches the full names of the events, or any event when
+	// nil.
+	eventName *regexp.Regexp
+}
+
+// parseChaincodeSubscriptions parses the JSON encoded list of chaincode
+// event subscriptions, if any.
+func parseChaincodeSubscriptions(subscriptionsJSON string) ([]chaincodeSubscription, error) {
+	if subscriptionsJSON == "" {
+		return nil, nil
+	}
+	var specs []sourcesv1alpha1.ChaincodeEventSubscription
+	if err := json.Unmarshal([]byte(subscriptionsJSON), &specs); err != nil {
+		return nil, fmt.Errorf("invalid chaincode event subscriptions: %w", err)
+	}
+
+	subscriptions := make([]chaincodeSubscription, 0, len(specs))
+	for i, s := range specs {
+		if s.ChaincodeName == "" {
+			return nil, fmt.Errorf("chaincode event subscription %d: missing chaincode name", i)
+		}
+		sub := chaincodeSubscription{chaincodeName: s.ChaincodeName}
+		if s.EventName != "" {
+			re, err := regexp.Compile("^(?:" + s.EventName + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("chaincode event subscription %d: %w", i, err)
+			}
+			sub.eventName = re
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, nil
+}
+
+// matches reports whether any subscription selects a chaincode event.
+func matches(subscriptions []chaincodeSubscription, ev *pb.ChaincodeEvent) bool {
+	for _, s := range subscriptions {
+		if s.chaincodeName == ev.ChaincodeId && (s.eventName == nil || s.eventName.MatchString(ev.EventName)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fabricBlock is a block received from a peer, full or filtered.
+type fabricBlock struct {
+	number uint64
+	// hash is only known for full blocks.
+	hash string
+	txs  []fabric.Transaction
+}
+
+func newFabricBlock(resp *pb.DeliverResponse) (*fabricBlock, error) {
+	if fb := resp.GetFilteredBlock(); fb != nil {
+		return &fabricBlock{
+			number: fb.Number,
+			txs:    fabric.FilteredTransactions(fb),
+		}, nil
+	}
+	blk := resp.GetBlock()
+	txs, err := fabric.BlockTransactions(blk)
+	if err != nil {
+		return nil, err
+	}
+	return &fabricBlock{
+		number: blk.GetHeader().GetNumber(),
+		hash:   hex.EncodeToString(fabric.BlockHeaderHash(blk.GetHeader())),
+		txs:    txs,
+	}, nil
+}
+
+// handleBlock emits the events of the transactions of a block, from the
+// next transaction to emit on. A transaction whose events could not be
+// delivered is retried when the block is received again, after
+// reconnecting. The newest block received while the position is not known
+// yet only positions the adapter past it.
+func (a *fabricAdapter) handleBlock(ctx context.Context, resp *pb.DeliverResponse) error {
+	block, err := newFabricBlock(resp)
+	if err != nil {
+		return err
+	}
+	if block.number > a.head {
+		a.head = block.number
+	}
+
+	if !a.positioned {
+		a.next, a.nextTx, a.positioned = block.number+1, 0, true
+		a.logger.Infof("Following channel %s from block %d", a.channelID, a.next)
+		return nil
+	}
+	switch {
+	case block.number < a.next:
+		// Already emitted.
+		return nil
+	case block.number > a.next:
+		return fmt.Errorf("received block %d while expecting block %d", block.number, a.next)
+	}
+
+	for a.nextTx < len(block.txs) {
+		tx := &block.txs[a.nextTx]
+		if err := a.emitTransaction(ctx, block, tx); err != nil {
+			return fmt.Errorf("failed to emit transaction %d of block %d: %w", tx.Index, block.number, err)
+		}
+		if !tx.Timestamp.IsZero() {
+			a.blockTime = tx.Timestamp
+		}
+		a.nextTx++
+	}
+	a.next, a.nextTx = a.next+1, 0
+	return nil
+}
+
+// fabricTransaction is the data of transaction events.
+type fabricTransaction struct {
+	ChannelID      string     `json:"channelID"`
+	BlockNumber    uint64     `json:"blockNumber"`
+	TxIndex        int        `json:"txIndex"`
+	TxID           string     `json:"txID,omitempty"`
+	Type           string     `json:"type"`
+	ValidationCode string     `json:"validationCode"`
+	Timestamp      *time.Time `json:"timestamp,omitempty"`
+	CreatorMSPID   string     `json:"creatorMSPID,omitempty"`
+	// ChaincodeEvents are the events set by the transaction, without
+	// payload when read from filtered blocks.
+	ChaincodeEvents []fabricChaincodeEvent `json:"chaincodeEvents,omitempty"`
+}
+
+// fabricChaincodeEvent is the data of chaincode events.
+type fabricChaincodeEvent struct {
+	ChannelID   string `json:"channelID,omitempty"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	TxIndex     int    `json:"txIndex,omitempty"`
+	TxID        string `json:"txID,omitempty"`
+	ChaincodeID string `json:"chaincodeID"`
+	EventName   string `json:"eventName"`
+	Payload     []byte `json:"payload,omitempty"`
+	// PayloadJSON is the payload, when it is a JSON document.
+	PayloadJSON json.RawMessage `json:"payloadJSON,omitempty"`
+}
+
+func newFabricChaincodeEvent(ev *pb.ChaincodeEvent) fabricChaincodeEvent {
+	data := fabricChaincodeEvent{
+		ChaincodeID: ev.ChaincodeId,
+		EventName:   ev.EventName,
+		Payload:     ev.Payload,
+	}
+	if len(ev.Payload) > 0 && json.Valid(ev.Payload) {
+		data.PayloadJSON = ev.Payload
+	}
+	return data
+}
+
+// emitTransaction emits the event of a transaction, or the events of the
+// subscribed chaincode events it set when valid.
+func (a *fabricAdapter) emitTransaction(ctx context.Context, block *fabricBlock, tx *fabric.Transaction) error {
+	if len(a.subscriptions) > 0 {
+		if tx.ValidationCode != pb.TxValidationCode_VALID {
+			// Chaincode events of invalid transactions never happened.
+			return nil
+		}
+		for i := range tx.ChaincodeEvents {
+			if !matches(a.subscriptions, tx.ChaincodeEvents[i]) {
+				continue
+			}
+			if err := a.emitChaincodeEvent(ctx, block, tx, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	id := tx.TxID
+	if id == "" {
+		// Config transactions may have no ID.
+		id = fmt.Sprintf("%d-%d", block.number, tx.Index)
+	}
+	data := fabricTransaction{
+		ChannelID:      a.channelID,
+		BlockNumber:    block.number,
+		TxIndex:        tx.Index,
+		TxID:           tx.TxID,
+		Type:           tx.Type.String(),
+		ValidationCode: tx.ValidationCode.String(),
+		CreatorMSPID:   tx.CreatorMSPID,
+	}
+	if !tx.Timestamp.IsZero() {
+		data.Timestamp = &tx.Timestamp
+	}
+	for i := range tx.ChaincodeEvents {
+		data.ChaincodeEvents = append(data.ChaincodeEvents, newFabricChaincodeEvent(tx.ChaincodeEvents[i]))
+	}
+
+	event := a.newEvent(tx, id, fabricTransactionEventType, a.source)
+	event.SetSubject(id)
+	number := block.number
+	ext := chainExtensions{
+		chainID:        a.channelID,
+		blockNumber:    &number,
+		blockHash:      block.hash,
+		txHash:         tx.TxID,
+		validationCode: tx.ValidationCode.String(),
+	}
+	return a.deliver(ctx, event, ext, data)
+}
+
+// emitChaincodeEvent emits the i-th chaincode event of a transaction. Its
+// source is the chaincode, and its subject the name of the event.
+func (a *fabricAdapter) emitChaincodeEvent(ctx context.Context, block *fabricBlock, tx *fabric.Transaction, i int) error {
+	ev := tx.ChaincodeEvents[i]
+	data := newFabricChaincodeEvent(ev)
+	data.ChannelID, data.BlockNumber, data.TxIndex, data.TxID = a.channelID, block.number, tx.Index, tx.TxID
+
+	id := fmt.Sprintf("%s-%d", tx.TxID, i)
+	source := sourcesv1alpha1.BlockchainChaincodeEventSource(a.source, ev.ChaincodeId)
+	event := a.newEvent(tx, id, fabricChaincodeEventType, source)
+	event.SetSubject(ev.EventName)
+	number := block.number
+	ext := chainExtensions{
+		chainID:     a.channelID,
+		blockNumber: &number,
+		blockHash:   block.hash,
+		txHash:      tx.TxID,
+		contract:    ev.ChaincodeId,
+		eventName:   ev.EventName,
+	}
+	return a.deliver(ctx, event, ext, data)
+}
+
+// newEvent returns an event about a transaction, dated with the
+// time the transaction was proposed, when known.
+func (a *fabricAdapter) newEvent(tx *fabric.Transaction, id, eventType, source string) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetType(eventType)
+	event.SetSource(source)
+	if !tx.Timestamp.IsZero() {
+		event.SetTime(tx.Timestamp)
+	}
+	// Committed blocks are final.
+	event.SetExtension(finalityExtension, string(sourcesv1alpha1.FinalityLevelFinalized))
+	return event
+}
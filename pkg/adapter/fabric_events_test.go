@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	"knative.dev/eventing-blockchain/pkg/fabric"
+)
+
+func TestParseChaincodeSubscriptions(t *testing.T) {
+	subscriptions, err := parseChaincodeSubscriptions(`[{"chaincodeName": "basic"}, {"chaincodeName": "token", "eventName": "Transfer|Mint"}]`)
+	if err != nil {
+		t.Fatalf("parseChaincodeSubscriptions() = %v", err)
+	}
+
+	tests := []struct {
+		chaincode string
+		event     string
+		want      bool
+	}{
+		{"basic", "Created", true},
+		{"token", "Transfer", true},
+		{"token", "Mint", true},
+		// Event names must match in full.
+		{"token", "TransferBatch", false},
+		{"token", "Burn", false},
+		{"Basic", "Created", false},
+	}
+	for _, test := range tests {
+		ev := &pb.ChaincodeEvent{ChaincodeId: test.chaincode, EventName: test.event}
+		if got := matches(subscriptions, ev); got != test.want {
+			t.Errorf("matches(%s, %s) = %v, want %v", test.chaincode, test.event, got, test.want)
+		}
+	}
+
+	if subscriptions, err := parseChaincodeSubscriptions(""); err != nil || subscriptions != nil {
+		t.Errorf(`parseChaincodeSubscriptions("") = %v, %v, want none`, subscriptions, err)
+	}
+	for _, invalid := range []string{`{}`, `[{"eventName": "Created"}]`, `[{"chaincodeName": "basic", "eventName": "("}]`} {
+		if _, err := parseChaincodeSubscriptions(invalid); err == nil {
+			t.Errorf("parseChaincodeSubscriptions(%s) = nil, want an error", invalid)
+		}
+	}
+}
+
+// newPositionedFabricAdapter returns an adapter set up to emit from the
+// given block on.
+func newPositionedFabricAdapter(t *testing.T, ce *adaptertest.TestCloudEventsClient, next uint64) *fabricAdapter {
+	t.Helper()
+	a := newTestFabricAdapter(t, ce, "grpc://peer0:7051")
+	if err := a.setup(); err != nil {
+		t.Fatalf("setup() = %v", err)
+	}
+	a.next, a.positioned = next, true
+	return a
+}
+
+func TestFabricAdapterEmitsChaincodeEvents(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := newPositionedFabricAdapter(t, ce, 1)
+	a.subscriptionsJSON = `[{"chaincodeName": "token", "eventName": "Transfer|Mint"}]`
+	if err := a.setup(); err != nil {
+		t.Fatalf("setup() = %v", err)
+	}
+
+	transfer := &pb.ChaincodeEvent{ChaincodeId: "token", EventName: "Transfer", Payload: []byte(`{"amount":10}`)}
+	mint := &pb.ChaincodeEvent{ChaincodeId: "token", EventName: "Mint", Payload: []byte("raw")}
+	approval := &pb.ChaincodeEvent{ChaincodeId: "token", EventName: "Approval"}
+	other := &pb.ChaincodeEvent{ChaincodeId: "basic", EventName: "Transfer"}
+	valid := fabricTx(1, approval, transfer, other, mint)
+	// Events of invalid transactions are not emitted.
+	invalid := fabricTx(2, transfer)
+	invalid.ValidationCode = pb.TxValidationCode_MVCC_READ_CONFLICT
+	block := fabric.NewBlock(1, []byte("parent"), []fabric.Transaction{valid, invalid})
+
+	if err := a.handleBlock(context.Background(), &pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: block}}); err != nil {
+		t.Fatalf("handleBlock() = %v", err)
+	}
+
+	sent := ce.Sent()
+	if diff := cmp.Diff([]string{"Transfer", "Mint"}, sentSubjects(ce)); diff != "" {
+		t.Fatalf("unexpected event subjects (-want, +got) = %v", diff)
+	}
+	e := sent[0]
+	if e.ID() != valid.TxID+"-1" {
+		t.Errorf("event ID = %q, want %q", e.ID(), valid.TxID+"-1")
+	}
+	if e.Type() != fabricChaincodeEventType {
+		t.Errorf("event type = %q, want %q", e.Type(), fabricChaincodeEventType)
+	}
+	if want := "fabric:mychannel/chaincode/token"; e.Source() != want {
+		t.Errorf("event source = %q, want %q", e.Source(), want)
+	}
+	if !e.Time().Equal(valid.Timestamp) {
+		t.Errorf("event time = %v, want %v", e.Time(), valid.Timestamp)
+	}
+	wantExt := map[string]interface{}{
+		finalityExtension:    "finalized",
+		chainIDExtension:     testChannel,
+		blockNumberExtension: int32(1),
+		blockHashExtension:   fmt.Sprintf("%x", fabric.BlockHeaderHash(block.Header)),
+		txHashExtension:      valid.TxID,
+		contractExtension:    "token",
+		eventNameExtension:   "Transfer",
+	}
+	if diff := cmp.Diff(wantExt, e.Extensions()); diff != "" {
+		t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(e.Data(), &data); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"amount": float64(10)}, data["payloadJSON"]); diff != "" {
+		t.Errorf("unexpected JSON payload (-want, +got) = %v", diff)
+	}
+	var mintData fabricChaincodeEvent
+	if err := json.Unmarshal(sent[1].Data(), &mintData); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	if string(mintData.Payload) != "raw" || mintData.PayloadJSON != nil {
+		t.Errorf("payload = %q, JSON %s, want raw bytes only", mintData.Payload, mintData.PayloadJSON)
+	}
+	if a.next != 2 || a.nextTx != 0 {
+		t.Errorf("next = %d/%d, want 2/0", a.next, a.nextTx)
+	}
+}
+
+func TestFabricAdapterSkipsNewestBlock(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := newPositionedFabricAdapter(t, ce, 0)
+	a.positioned = false
+
+	block := fabric.NewBlock(7, []byte("parent"), []fabric.Transaction{fabricTx(1)})
+	if err := a.handleBlock(context.Background(), &pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: block}}); err != nil {
+		t.Fatalf("handleBlock() = %v", err)
+	}
+	if len(ce.Sent()) != 0 {
+		t.Errorf("sent %d events, want none", len(ce.Sent()))
+	}
+	if !a.positioned || a.next != 8 || a.head != 7 {
+		t.Errorf("positioned = %v, next = %d, head = %d, want true, 8, 7", a.positioned, a.next, a.head)
+	}
+}
+
+func TestFabricAdapterRejectsMissingBlock(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := newPositionedFabricAdapter(t, ce, 3)
+
+	// Blocks already emitted are skipped.
+	old := fabric.NewBlock(2, []byte("parent"), []fabric.Transaction{fabricTx(1)})
+	if err := a.handleBlock(context.Background(), &pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: old}}); err != nil {
+		t.Fatalf("handleBlock() = %v", err)
+	}
+	if len(ce.Sent()) != 0 {
+		t.Errorf("sent %d events, want none", len(ce.Sent()))
+	}
+
+	ahead := fabric.NewBlock(4, []byte("parent"), []fabric.Transaction{fabricTx(1)})
+	if err := a.handleBlock(context.Background(), &pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: ahead}}); err == nil {
+		t.Error("handleBlock() = nil, want an error")
+	}
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/pkg/logging"
+	pkgtesting "knative.dev/pkg/reconciler/testing"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/fabric"
+	"knative.dev/eventing-blockchain/pkg/fabric/fabrictest"
+)
+
+const (
+	testChannel = "mychannel"
+	testMSPID   = "Org1MSP"
+)
+
+func newTestFabricAdapter(t *testing.T, ce *adaptertest.TestCloudEventsClient, rpcURL string) *fabricAdapter {
+	certPEM, keyPEM := fabrictest.NewIdentity(t, "client")
+	env := fabricEnvConfig{
+		chainEnvConfig: chainEnvConfig{
+			EnvConfig: adapter.EnvConfig{
+				Namespace: "default",
+			},
+			EnvRPCURL:  rpcURL,
+			EnvChainID: testChannel,
+		},
+		EnvBlockType: string(sourcesv1alpha1.FabricBlockTypeFull),
+		EnvMSPID:     testMSPID,
+		EnvCert:      string(certPEM),
+		EnvKey:       string(keyPEM),
+	}
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+	logger := zap.NewExample().Sugar()
+	ctx = logging.WithLogger(ctx, logger)
+
+	a := NewFabricAdapter(ctx, &env, ce).(*fabricAdapter)
+	a.minReconnectDelay = 10 * time.Millisecond
+	return a
+}
+
+// fabricTx returns a valid endorser transaction, made unique by n.
+func fabricTx(n int, events ...*pb.ChaincodeEvent) fabric.Transaction {
+	return fabric.Transaction{
+		Type:            cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:            fmt.Sprintf("%064x", n),
+		ChannelID:       testChannel,
+		Timestamp:       time.Unix(1700000000+int64(n), 0).UTC(),
+		CreatorMSPID:    testMSPID,
+		ValidationCode:  pb.TxValidationCode_VALID,
+		ChaincodeEvents: events,
+	}
+}
+
+func TestFabricAdapterEmitsNewBlocks(t *testing.T) {
+	peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+	peer.AddBlock(fabricTx(1))
+
+	ce := adaptertest.NewTestClient()
+	a := newTestFabricAdapter(t, ce, peer.Endpoint())
+
+	runAdapter(t, a, func() {
+		// Blocks committed before the adapter started are not emitted.
+		waitForCount(t, "requests", 1, func() int {
+			return len(peer.Requests())
+		})
+		peer.AddBlock(fabricTx(2), fabricTx(3))
+		waitForEvents(t, ce, 2)
+	})
+
+	if diff := cmp.Diff([]string{fabricTx(2).TxID, fabricTx(3).TxID}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected transaction subjects (-want, +got) = %v", diff)
+	}
+	blockHash := fmt.Sprintf("%x", fabric.BlockHeaderHash(peer.Block(2).Header))
+	for _, e := range ce.Sent() {
+		if e.Type() != fabricTransactionEventType {
+			t.Errorf("event type = %q, want %q", e.Type(), fabricTransactionEventType)
+		}
+		if e.Source() != "fabric:mychannel" {
+			t.Errorf("event source = %q, want fabric:mychannel", e.Source())
+		}
+		want := map[string]interface{}{
+			finalityExtension:    "finalized",
+			chainIDExtension:     testChannel,
+			blockNumberExtension: int32(2),
+			blockHashExtension:   blockHash,
+			txHashExtension:      e.Subject(),
+			validationExtension:  "VALID",
+		}
+		if diff := cmp.Diff(want, e.Extensions()); diff != "" {
+			t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+		}
+	}
+	if got := peer.Requests()[0].Seek.Start; !proto.Equal(got, fabric.SeekNewest()) {
+		t.Errorf("first seek start = %v, want newest", got)
+	}
+}
+
+func TestFabricAdapterBackfillsFromStartBlock(t *testing.T) {
+	peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+	for i := 1; i <= 4; i++ {
+		peer.AddBlock(fabricTx(i))
+	}
+
+	ce := adaptertest.NewTestClient()
+	a := newTestFabricAdapter(t, ce, peer.Endpoint())
+	start, end := uint64(0), uint64(2)
+	a.startBlock, a.endBlock = &start, &end
+
+	runAdapter(t, a, func() {
+		waitForEvents(t, ce, 3)
+	})
+
+	// The config transaction of the genesis block has no ID.
+	if diff := cmp.Diff([]string{"0-0", fabricTx(1).TxID, fabricTx(2).TxID}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected transaction subjects (-want, +got) = %v", diff)
+	}
+	if got := ce.Sent()[0].Extensions()[validationExtension]; got != "VALID" {
+		t.Errorf("config transaction validation code = %v, want VALID", got)
+	}
+	seek := peer.Requests()[0].Seek
+	if !proto.Equal(seek.Start, fabric.SeekSpecified(0)) || !proto.Equal(seek.Stop, fabric.SeekSpecified(2)) {
+		t.Errorf("seek = %v, want blocks 0 to 2", seek)
+	}
+}
+
+func TestFabricAdapterReadsFilteredBlocks(t *testing.T) {
+	peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+	invalid := fabricTx(2, &pb.ChaincodeEvent{ChaincodeId: "basic", EventName: "Created", Payload: []byte(`{}`)})
+	invalid.ValidationCode = pb.TxValidationCode_MVCC_READ_CONFLICT
+	peer.AddBlock(fabricTx(1), invalid)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestFabricAdapter(t, ce, peer.Endpoint())
+	a.blockType = sourcesv1alpha1.FabricBlockTypeFiltered
+	start, end := uint64(1), uint64(1)
+	a.startBlock, a.endBlock = &start, &end
+
+	runAdapter(t, a, func() {
+		waitForEvents(t, ce, 2)
+	})
+
+	if !peer.Requests()[0].Filtered {
+		t.Error("requested full blocks, want filtered ones")
+	}
+	sent := ce.Sent()
+	if _, ok := sent[0].Extensions()[blockHashExtension]; ok {
+		t.Error("filtered block events have a block hash, want none")
+	}
+	if got := sent[1].Extensions()[validationExtension]; got != "MVCC_READ_CONFLICT" {
+		t.Errorf("validation code = %v, want MVCC_READ_CONFLICT", got)
+	}
+
+	var data fabricTransaction
+	if err := json.Unmarshal(sent[1].Data(), &data); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	want := fabricTransaction{
+		ChannelID:       testChannel,
+		BlockNumber:     1,
+		TxIndex:         1,
+		TxID:            invalid.TxID,
+		Type:            "ENDORSER_TRANSACTION",
+		ValidationCode:  "MVCC_READ_CONFLICT",
+		ChaincodeEvents: []fabricChaincodeEvent{{ChaincodeID: "basic", EventName: "Created"}},
+	}
+	if diff := cmp.Diff(want, data); diff != "" {
+		t.Errorf("unexpected transaction (-want, +got) = %v", diff)
+	}
+}
+
+func TestFabricAdapterFailsOver(t *testing.T) {
+	peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+	peer.AddBlock(fabricTx(1))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	down := "grpc://" + l.Addr().String()
+	l.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestFabricAdapter(t, ce, "")
+	a.endpointsJSON = fmt.Sprintf(`[{"url": %q}, {"url": %q, "priority": 1}]`, down, peer.Endpoint())
+	start := uint64(1)
+	a.startBlock = &start
+
+	runAdapter(t, a, func() {
+		waitForEvents(t, ce, 1)
+	})
+
+	if a.active != peer.Endpoint() {
+		t.Errorf("active peer = %q, want %q", a.active, peer.Endpoint())
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/filter"
+)
+
+// eventFilter is a filter events must pass to be delivered.
+type eventFilter struct {
+	// name identifies the filter in the metrics of the events it drops.
+	name string
+	filter.Filter
+}
+
+// eventFilters are the filters events must all pass to be delivered.
+type eventFilters []eventFilter
+
+// parseEventFilters parses the JSON encoded list of filters of a source.
+// Filters without a name are named after their index.
+func parseEventFilters(filtersJSON string) (eventFilters, error) {
+	if filtersJSON == "" {
+		return nil, nil
+	}
+
+	var specs []sourcesv1alpha1.EventFilter
+	if err := json.Unmarshal([]byte(filtersJSON), &specs); err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	filters := make(eventFilters, 0, len(specs))
+	for i := range specs {
+		name := specs[i].Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		f, err := specs[i].Parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %s: %w", name, err)
+		}
+		filters = append(filters, eventFilter{name: name, Filter: f})
+	}
+	return filters, nil
+}
+
+// drops reports whether an event fails one of the filters, in which case it
+// must not be delivered. The event is counted as dropped by the first filter
+// it fails.
+func (fs eventFilters) drops(ctx context.Context, logger *zap.SugaredLogger, event cloudevents.Event) bool {
+	for _, f := range fs {
+		match, err := f.Match(event)
+		if err != nil {
+			logger.Debugw("Filter could not be evaluated", zap.String("filter", f.name),
+				zap.String("id", event.ID()), zap.Error(err))
+		}
+		if !match {
+			reportDroppedEvent(ctx, f.name, event.Type())
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+// resetMetrics forgets the recorded metrics.
+func resetMetrics() {
+	metricstest.Unregister(droppedEventCountM.Name())
+	register()
+}
+
+func TestParseEventFilters(t *testing.T) {
+	filters, err := parseEventFilters(`[{"name": "transfers", "cesql": "eventname = 'Transfer'"}, {"cel": "event.chainid == '1'"}]`)
+	if err != nil {
+		t.Fatal("parseEventFilters() =", err)
+	}
+	var names []string
+	for _, f := range filters {
+		names = append(names, f.name)
+	}
+	if got, want := fmt.Sprint(names), "[transfers 1]"; got != want {
+		t.Errorf("filter names = %s, want %s", got, want)
+	}
+
+	if filters, err := parseEventFilters(""); err != nil || filters != nil {
+		t.Errorf("parseEventFilters() = %v, %v, want no filters", filters, err)
+	}
+
+	for _, filtersJSON := range []string{
+		`{"cesql": "eventname = 'Transfer'"}`,
+		`[{"cesql": "eventname ="}]`,
+		`[{"cel": "event.eventname =="}]`,
+	} {
+		if _, err := parseEventFilters(filtersJSON); err == nil {
+			t.Errorf("parseEventFilters(%s) = nil error, want one", filtersJSON)
+		}
+	}
+}
+
+func TestEthereumAdapterFiltersBlocks(t *testing.T) {
+	resetMetrics()
+
+	node := newFakeNode(5, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.filtersJSON = `[{"name": "even", "cel": "event.blocknumber % 2 == 0"}]`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Start(ctx)
+	}()
+
+	node.waitForCalls(t, "eth_blockNumber", 2)
+	for i := 0; i < 4; i++ {
+		node.mine()
+	}
+	waitForEvents(t, ce, 2)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	var subjects []string
+	for _, e := range ce.Sent() {
+		subjects = append(subjects, e.Subject())
+	}
+	if got, want := fmt.Sprint(subjects), "[2 4]"; got != want {
+		t.Errorf("sent blocks = %s, want %s", got, want)
+	}
+	metricstest.CheckCountData(t, droppedEventCountM.Name(), map[string]string{
+		filterNameKey.Name(): "even",
+		eventTypeKey.Name():  ethereumBlockEventType,
+	}, 2)
+}
+
+func TestEthereumAdapterFiltersLogs(t *testing.T) {
+	resetMetrics()
+
+	node := newFakeNode(1, 3)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestEthereumAdapter(t, ce, server.URL)
+	a.contractsJSON = fmt.Sprintf(`{"addresses": [%q]}`, tokenAddress)
+	a.abiJSON = tokenABI
+	a.filtersJSON = `[
+		{"cesql": "eventname = 'Transfer'"},
+		{"name": "large", "cel": "int(data.args.value) >= 2000"}
+	]`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Start(ctx)
+	}()
+
+	node.waitForCalls(t, "eth_blockNumber", 2)
+	node.mine(
+		fakeLog(tokenAddress, transferTopic, 1000),
+		fakeLog(tokenAddress, approvalTopic, 3000),
+		fakeLog(tokenAddress, transferTopic, 4000),
+	)
+	waitForEvents(t, ce, 1)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	logs := sentLogs(t, ce)
+	if len(logs) != 1 || logs[0].Args["value"] != "4000" {
+		t.Errorf("sent logs = %+v, want the transfer of 4000", logs)
+	}
+	metricstest.CheckStatsReported(t, droppedEventCountM.Name())
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+)
+
+var (
+	// droppedEventCountM counts the events a filter dropped instead of
+	// delivering them.
+	droppedEventCountM = stats.Int64(
+		"filtered_event_count",
+		"Number of events dropped by a filter of the source",
+		stats.UnitDimensionless,
+	)
+
+	filterNameKey = tag.MustNewKey("filter_name")
+	eventTypeKey  = tag.MustNewKey("event_type")
+)
+
+func init() {
+	register()
+}
+
+func register() {
+	if err := metrics.RegisterResourceView(&view.View{
+		Description: droppedEventCountM.Description(),
+		Measure:     droppedEventCountM,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{filterNameKey, eventTypeKey},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// reportDroppedEvent counts an event of the given type dropped by a filter.
+func reportDroppedEvent(ctx context.Context, filterName, eventType string) {
+	ctx, err := tag.New(ctx,
+		tag.Insert(filterNameKey, filterName),
+		tag.Insert(eventTypeKey, eventType),
+	)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, droppedEventCountM.M(1))
+}
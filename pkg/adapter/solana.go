@@ -0,0 +1,474 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"knative.dev/eventing/pkg/adapter/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+	"knative.dev/eventing-blockchain/pkg/solana"
+)
+
+// solanaSlotPollInterval is how often the newest slot at the commitment of
+// the subscriptions is read, to move the checkpoint forward.
+const solanaSlotPollInterval = time.Second
+
+type solanaEnvConfig struct {
+	chainEnvConfig
+
+	// Environment variable containing the finality level events must reach
+	// before being emitted, latest, confirmed or finalized, for the
+	// processed, confirmed and finalized commitments
+	EnvFinality string `envconfig:"BLOCKCHAIN_FINALITY" default:"latest"`
+	// Environment variable containing the JSON encoded list of the
+	// addresses whose transactions are emitted with their logs
+	EnvMentions string `envconfig:"BLOCKCHAIN_SOLANA_MENTIONS"`
+	// Environment variable containing the JSON encoded list of the
+	// addresses of the accounts whose changes are emitted
+	EnvAccounts string `envconfig:"BLOCKCHAIN_SOLANA_ACCOUNTS"`
+	// Environment variable telling whether an event is emitted per slot.
+	// Slots are emitted when neither mentions nor accounts are set
+	EnvSlots bool `envconfig:"BLOCKCHAIN_SOLANA_SLOTS"`
+}
+
+// NewSolanaEnvConfig function reads env variables defined in
+// solanaEnvConfig structure and returns accessor interface
+func NewSolanaEnvConfig() adapter.EnvConfigAccessor {
+	return &solanaEnvConfig{}
+}
+
+// solanaAdapter subscribes to the PubSub endpoint of the nodes of a Solana
+// cluster, and converts the logs of the transactions mentioning the
+// subscribed addresses, the changes of the subscribed accounts and the
+// processed slots to CloudEvents. Transactions are read with getTransaction
+// to enrich their events. Notifications are made at the commitment of the
+// finality level, so there are no reorgs to follow, except for slots, which
+// are notified as soon as the node processes them. The checkpoint is the
+// slot before the oldest one whose events may not all have been emitted:
+// the transactions mentioning the addresses after it are read back with
+// getSignaturesForAddress once reconnected. Account changes and slots
+// notified while disconnected are not recovered, the next change of an
+// account holding its whole state.
+type solanaAdapter struct {
+	emitter
+
+	rpcURL            string
+	endpointsJSON     string
+	chainID           string
+	finality          sourcesv1alpha1.FinalityLevel
+	mentionsJSON      string
+	accountsJSON      string
+	slots             bool
+	minReconnectDelay time.Duration
+	slotPollInterval  time.Duration
+	startBlock        *uint64
+
+	// nodes are the nodes, in priority order.
+	nodes []*solanaNode
+	// commitment is the commitment of the subscriptions.
+	commitment string
+	// mentions and accounts are the subscribed addresses.
+	mentions []string
+	accounts []string
+
+	// observedChainID is the CAIP-2 reference of the cluster the nodes
+	// serve, once known, and source the CloudEvent source of the emitted
+	// events.
+	observedChainID string
+	source          string
+	// active is the name of the node last read from.
+	active string
+	// head is the newest slot processed by the node, and committed the
+	// newest slot at the commitment of the subscriptions, read at polledAt.
+	head      uint64
+	committed uint64
+	polledAt  time.Time
+	// recent holds the events emitted for the slots from the next one on,
+	// by CloudEvent source and ID, as transactions may be both recovered
+	// and notified.
+	recent map[uint64]map[string]bool
+}
+
+// solanaNode is a node, called over HTTP and subscribed to over its PubSub
+// WebSocket endpoint.
+type solanaNode struct {
+	*endpoint
+	pubSubURL string
+}
+
+// NewSolanaAdapter returns the instance of solanaAdapter that implements adapter.Adapter interface
+func NewSolanaAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	env := processed.(*solanaEnvConfig)
+
+	a := &solanaAdapter{
+		emitter:           newEmitter(ctx, &env.chainEnvConfig, ceClient),
+		rpcURL:            env.EnvRPCURL,
+		endpointsJSON:     env.EnvEndpoints,
+		chainID:           env.EnvChainID,
+		finality:          sourcesv1alpha1.FinalityLevel(env.EnvFinality),
+		mentionsJSON:      env.EnvMentions,
+		accountsJSON:      env.EnvAccounts,
+		slots:             env.EnvSlots,
+		minReconnectDelay: minReconnectDelay,
+		slotPollInterval:  solanaSlotPollInterval,
+		startBlock:        env.EnvStartBlock,
+	}
+	a.cursor = a
+	return a
+}
+
+func (a *solanaAdapter) Start(ctx context.Context) error {
+	if err := a.setup(); err != nil {
+		return err
+	}
+	return a.hold(ctx, func(ctx context.Context) error {
+		if err := a.init(ctx); err != nil {
+			return err
+		}
+		return a.stream(ctx)
+	})
+}
+
+// setup checks the settings of the adapter, and parses the addresses and
+// the filters they hold.
+func (a *solanaAdapter) setup() error {
+	configs, err := endpointConfigs(a.rpcURL, a.endpointsJSON)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(configs, func(i, j int) bool {
+		return configs[i].Priority < configs[j].Priority
+	})
+	a.nodes = make([]*solanaNode, 0, len(configs))
+	for _, c := range configs {
+		pubSubURL, err := solana.PubSubURL(c.URL)
+		if err != nil {
+			return fmt.Errorf("endpoint %s: %w", c.URL, err)
+		}
+		e := &endpoint{endpointConfig: c}
+		e.client = jsonrpc.NewClient(c.URL, e.options()...)
+		a.nodes = append(a.nodes, &solanaNode{endpoint: e, pubSubURL: pubSubURL})
+	}
+
+	switch a.finality {
+	case "", sourcesv1alpha1.FinalityLevelLatest:
+		a.commitment = solana.CommitmentProcessed
+	case sourcesv1alpha1.FinalityLevelConfirmed:
+		a.commitment = solana.CommitmentConfirmed
+	case sourcesv1alpha1.FinalityLevelFinalized:
+		a.commitment = solana.CommitmentFinalized
+	default:
+		return fmt.Errorf("finality level %s is not supported by solana nodes", a.finality)
+	}
+
+	if a.mentions, err = parseSolanaAddresses(a.mentionsJSON); err != nil {
+		return fmt.Errorf("invalid solana mentions: %w", err)
+	}
+	if a.accounts, err = parseSolanaAddresses(a.accountsJSON); err != nil {
+		return fmt.Errorf("invalid solana accounts: %w", err)
+	}
+	if len(a.mentions) == 0 && len(a.accounts) == 0 {
+		a.slots = true
+	}
+
+	if err := a.setupFilters(); err != nil {
+		return err
+	}
+
+	if a.chainID != "" {
+		a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilySolana, a.chainID)
+	}
+	return nil
+}
+
+// parseSolanaAddresses parses a JSON encoded list of base58 addresses.
+func parseSolanaAddresses(addressesJSON string) ([]string, error) {
+	if addressesJSON == "" {
+		return nil, nil
+	}
+	var addresses []string
+	if err := json.Unmarshal([]byte(addressesJSON), &addresses); err != nil {
+		return nil, err
+	}
+	for _, addr := range addresses {
+		if _, err := solana.ParseAddress(addr); err != nil {
+			return nil, err
+		}
+	}
+	return addresses, nil
+}
+
+// init resumes from the saved checkpoint. Without a checkpoint, the
+// transactions are recovered from the start slot, or events are emitted
+// from the slot following the newest one once the first node is reached.
+func (a *solanaAdapter) init(ctx context.Context) error {
+	resumed, err := a.resume(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resumed:
+		a.logger.Infof("Resuming from checkpoint at slot %d", a.next)
+	case a.startBlock != nil:
+		a.next, a.positioned = *a.startBlock, true
+		a.logger.Infof("Backfilling from slot %d", a.next)
+	}
+	return nil
+}
+
+// stream subscribes to the nodes until ctx is done, trying them in
+// priority order and starting over with an exponential backoff whenever
+// none could be subscribed to.
+func (a *solanaAdapter) stream(ctx context.Context) error {
+	defer a.saveCheckpoint(context.Background(), true)
+
+	delay := a.minReconnectDelay
+	for {
+		received, err := a.streamNodes(ctx)
+		if ctx.Err() != nil {
+			a.logger.Infof("Streaming stopped")
+			return nil
+		}
+		if a.reachedEnd() {
+			a.saveCheckpoint(ctx, true)
+			a.logger.Infof("Reached end slot %d, streaming stopped", *a.endBlock)
+			<-ctx.Done()
+			return nil
+		}
+		if received {
+			delay = a.minReconnectDelay
+		}
+		a.reportConnection(ctx, err)
+		a.logger.Errorf("Stream interrupted, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			a.logger.Infof("Streaming stopped")
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// streamNodes subscribes to the first node that can be, in priority order.
+// It returns the error of the last attempt, and whether any node was
+// subscribed to.
+func (a *solanaAdapter) streamNodes(ctx context.Context) (bool, error) {
+	var err error
+	for _, n := range a.nodes {
+		var subscribed bool
+		subscribed, err = a.streamOnce(ctx, n)
+		if subscribed || ctx.Err() != nil || a.reachedEnd() {
+			return subscribed, err
+		}
+		if err != nil {
+			a.logger.Warnf("Failed to subscribe to %s: %v", n.name(), err)
+		}
+	}
+	return false, err
+}
+
+// solanaNotification is a value notified to one of the subscriptions to a
+// node.
+type solanaNotification struct {
+	method string
+	// address is the mentioned address of logsSubscribe subscriptions,
+	// and the address of the account of accountSubscribe ones.
+	address string
+	value   json.RawMessage
+}
+
+// streamOnce subscribes to a node, recovers the transactions mentioning the
+// addresses since the checkpoint and emits the notified events until the
+// connection is lost, the end slot is reached or ctx is done. It reports
+// whether the node was subscribed to, so that the caller can tell a
+// flapping node from a working one.
+func (a *solanaAdapter) streamOnce(ctx context.Context, n *solanaNode) (bool, error) {
+	if err := a.checkNode(ctx, n); err != nil {
+		return false, err
+	}
+	// The events of the slots up to the committed one were notified
+	// before the subscriptions are made. Only the transactions among them
+	// can be recovered.
+	if err := a.pollSlot(ctx, n); err != nil {
+		return false, err
+	}
+	committed := a.committed
+
+	conn, err := jsonrpc.DialWebSocket(ctx, n.pubSubURL, n.options()...)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	// The node is subscribed to before the transactions are recovered, so
+	// that the ones made in between are not missed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	notifications := make(chan solanaNotification)
+	subscribe := func(method, address string, params ...interface{}) error {
+		ch := make(chan json.RawMessage)
+		if _, err := conn.Subscribe(ctx, ch, method, params...); err != nil {
+			if address != "" {
+				return fmt.Errorf("failed to %s to %s on %s: %w", method, address, n.name(), err)
+			}
+			return fmt.Errorf("failed to %s on %s: %w", method, n.name(), err)
+		}
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case value := <-ch:
+					select {
+					case notifications <- solanaNotification{method: method, address: address, value: value}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+		return nil
+	}
+	commitment := map[string]interface{}{"commitment": a.commitment}
+	for _, addr := range a.mentions {
+		if err := subscribe("logsSubscribe", addr, map[string]interface{}{"mentions": []string{addr}}, commitment); err != nil {
+			return false, err
+		}
+	}
+	for _, addr := range a.accounts {
+		if err := subscribe("accountSubscribe", addr, addr, map[string]interface{}{"commitment": a.commitment, "encoding": "jsonParsed"}); err != nil {
+			return false, err
+		}
+	}
+	if err := subscribe("slotSubscribe", ""); err != nil {
+		return false, err
+	}
+
+	if !a.positioned {
+		a.next, a.positioned = committed+1, true
+		a.logger.Infof("Following cluster %s from slot %d", a.observedChainID, a.next)
+	} else if err := a.recoverTransactions(ctx, n); err != nil {
+		return false, err
+	}
+	a.advance(committed + 1)
+	a.active = n.name()
+	a.reportConnection(ctx, nil)
+	a.logger.Infof("Streaming cluster %s from %s", a.observedChainID, n.name())
+	if a.reachedEnd() {
+		return true, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case <-conn.Done():
+			return true, fmt.Errorf("connection to %s lost: %w", n.name(), conn.Err())
+		case notification := <-notifications:
+			if err := a.handleNotification(ctx, n, &notification); err != nil {
+				return true, err
+			}
+			if a.reachedEnd() {
+				return true, nil
+			}
+			a.saveCheckpoint(ctx, false)
+		}
+	}
+}
+
+// checkNode checks that a node serves the expected cluster, and learns the
+// cluster when none is expected.
+func (a *solanaAdapter) checkNode(ctx context.Context, n *solanaNode) error {
+	var genesisHash string
+	if err := n.Call(ctx, &genesisHash, "getGenesisHash"); err != nil {
+		return fmt.Errorf("failed to get the genesis hash of %s: %w", n.name(), err)
+	}
+	if genesisHash == "" {
+		return fmt.Errorf("node %s reported no genesis hash", n.name())
+	}
+	ref := solana.ChainReference(genesisHash)
+	source := sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilySolana, ref)
+	if a.source != "" && source != a.source {
+		want := a.observedChainID
+		if want == "" {
+			want = a.chainID
+		}
+		return &chainMismatchError{endpoint: n.name(), got: ref, want: want}
+	}
+	a.observedChainID, a.source = ref, source
+	return nil
+}
+
+// pollSlot reads the newest slot at the commitment of the subscriptions.
+func (a *solanaAdapter) pollSlot(ctx context.Context, n *solanaNode) error {
+	var slot uint64
+	if err := n.Call(ctx, &slot, "getSlot", map[string]interface{}{"commitment": a.commitment}); err != nil {
+		return fmt.Errorf("failed to get the %s slot of %s: %w", a.commitment, n.name(), err)
+	}
+	if slot > a.committed {
+		a.committed = slot
+	}
+	if slot > a.head {
+		a.head = slot
+	}
+	a.polledAt = time.Now()
+	return nil
+}
+
+// currentCheckpoint returns the newest slot whose events have all been
+// emitted, or nil if there is none yet.
+func (a *solanaAdapter) currentCheckpoint() *checkpoint.Checkpoint {
+	if a.source == "" || !a.positioned || a.next == 0 {
+		return nil
+	}
+	return &checkpoint.Checkpoint{BlockNumber: a.next - 1}
+}
+
+// seek moves the adapter past the slot of a checkpoint.
+func (a *solanaAdapter) seek(cp *checkpoint.Checkpoint) {
+	a.next, a.positioned = cp.BlockNumber+1, true
+}
+
+// chainProgress reports how the adapter keeps up with the cluster. The
+// final slot is the newest one at the commitment of the subscriptions.
+func (a *solanaAdapter) chainProgress() *checkpoint.Progress {
+	return &checkpoint.Progress{
+		ChainID:  a.observedChainID,
+		Head:     a.head,
+		Final:    a.committed,
+		Endpoint: a.active,
+	}
+}
+
+// connectionFailure returns the report of a failure to subscribe to a
+// node, or nil if err is not one.
+func (a *solanaAdapter) connectionFailure(err error) *checkpoint.Connection {
+	return connectionFailure(nil, err)
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/solana"
+)
+
+// solanaSignaturesPageSize is the number of signatures requested at once
+// from getSignaturesForAddress, the most nodes return.
+const solanaSignaturesPageSize = 1000
+
+var (
+	// solanaLogEventType is the CloudEvent type of the events emitted for
+	// every transaction mentioning a subscribed address.
+	solanaLogEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilySolana, sourcesv1alpha1.BlockchainEventKindLog)
+
+	// solanaAccountEventType is the CloudEvent type of the events emitted
+	// for every change of a subscribed account.
+	solanaAccountEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilySolana, sourcesv1alpha1.BlockchainEventKindAccount)
+
+	// solanaSlotEventType is the CloudEvent type of the events emitted for
+	// every processed slot.
+	solanaSlotEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilySolana, sourcesv1alpha1.BlockchainEventKindSlot)
+)
+
+// solanaTransaction is the data of log events: the logs of a transaction
+// mentioning an address, and the details getTransaction returns, unless
+// the node could not return the transaction yet.
+type solanaTransaction struct {
+	ChainID   string `json:"chainID"`
+	Slot      uint64 `json:"slot"`
+	Signature string `json:"signature"`
+	// Mention is the subscribed address the transaction mentions.
+	Mention string `json:"mention"`
+	// Err is null when the transaction succeeded.
+	Err                  json.RawMessage `json:"err"`
+	Logs                 []string        `json:"logs"`
+	BlockTime            *time.Time      `json:"blockTime,omitempty"`
+	Fee                  *uint64         `json:"fee,omitempty"`
+	ComputeUnitsConsumed *uint64         `json:"computeUnitsConsumed,omitempty"`
+	Accounts             []string        `json:"accounts,omitempty"`
+	RecentBlockhash      string          `json:"recentBlockhash,omitempty"`
+	Version              json.RawMessage `json:"version,omitempty"`
+}
+
+// solanaAccount is the data of account events, the new state of an
+// account.
+type solanaAccount struct {
+	ChainID    string          `json:"chainID"`
+	Slot       uint64          `json:"slot"`
+	Address    string          `json:"address"`
+	Lamports   uint64          `json:"lamports"`
+	Owner      string          `json:"owner,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Executable bool            `json:"executable"`
+	RentEpoch  uint64          `json:"rentEpoch"`
+	Space      uint64          `json:"space"`
+}
+
+// solanaSlot is the data of slot events.
+type solanaSlot struct {
+	ChainID string `json:"chainID"`
+	Slot    uint64 `json:"slot"`
+	Parent  uint64 `json:"parent"`
+	Root    uint64 `json:"root"`
+}
+
+// handleNotification handles a value notified to one of the subscriptions
+// to a node.
+func (a *solanaAdapter) handleNotification(ctx context.Context, n *solanaNode, notification *solanaNotification) error {
+	switch notification.method {
+	case "slotSubscribe":
+		var info solana.SlotInfo
+		if err := json.Unmarshal(notification.value, &info); err != nil {
+			return fmt.Errorf("invalid slot notified by %s: %w", n.name(), err)
+		}
+		return a.handleSlot(ctx, n, &info)
+	case "logsSubscribe":
+		var logs solana.LogsNotification
+		if err := json.Unmarshal(notification.value, &logs); err != nil {
+			return fmt.Errorf("invalid logs notified by %s: %w", n.name(), err)
+		}
+		slot := logs.Context.Slot
+		if slot < a.next || a.pastEnd(slot) {
+			return nil
+		}
+		return a.emitTransaction(ctx, n, notification.address, slot, &logs.Value)
+	case "accountSubscribe":
+		var account solana.AccountNotification
+		if err := json.Unmarshal(notification.value, &account); err != nil {
+			return fmt.Errorf("invalid account notified by %s: %w", n.name(), err)
+		}
+		slot := account.Context.Slot
+		if slot < a.next || a.pastEnd(slot) {
+			return nil
+		}
+		return a.emitAccount(ctx, notification.address, slot, account.Value)
+	}
+	return nil
+}
+
+// handleSlot follows the slots the node processes. The newest slot at the
+// commitment of the subscriptions is polled on the way: the events of the
+// slots up to the one read on the previous poll have all been notified
+// since, so the adapter moves past them.
+func (a *solanaAdapter) handleSlot(ctx context.Context, n *solanaNode, info *solana.SlotInfo) error {
+	if info.Slot > a.head {
+		a.head = info.Slot
+	}
+	if a.slots && info.Slot >= a.next && !a.pastEnd(info.Slot) {
+		if err := a.emitSlot(ctx, info); err != nil {
+			return err
+		}
+	}
+
+	if time.Since(a.polledAt) < a.slotPollInterval {
+		return nil
+	}
+	previous := a.committed
+	if err := a.pollSlot(ctx, n); err != nil {
+		return err
+	}
+	a.advance(previous + 1)
+	return nil
+}
+
+// advance moves the adapter past the slots before slot, whose events have
+// all been emitted, up to the slot following the end slot.
+func (a *solanaAdapter) advance(slot uint64) {
+	if a.endBlock != nil && slot > *a.endBlock+1 {
+		slot = *a.endBlock + 1
+	}
+	if slot <= a.next {
+		return
+	}
+	a.next = slot
+	for s := range a.recent {
+		if s < a.next {
+			delete(a.recent, s)
+		}
+	}
+}
+
+// transactionCommitment is the commitment transactions are read at, as
+// nodes only return confirmed transactions.
+func (a *solanaAdapter) transactionCommitment() string {
+	if a.commitment == solana.CommitmentProcessed {
+		return solana.CommitmentConfirmed
+	}
+	return a.commitment
+}
+
+// recoverTransactions emits the transactions mentioning the subscribed
+// addresses from the next slot on, which may have been missed while
+// disconnected. Their logs are read from getTransaction.
+func (a *solanaAdapter) recoverTransactions(ctx context.Context, n *solanaNode) error {
+	for _, addr := range a.mentions {
+		signatures, err := a.signaturesSince(ctx, n, addr)
+		if err != nil {
+			return fmt.Errorf("failed to recover the transactions mentioning %s: %w", addr, err)
+		}
+		if len(signatures) > 0 {
+			a.logger.Infof("Recovering %d transactions mentioning %s from slot %d", len(signatures), addr, a.next)
+		}
+		// Signatures are listed from the newest.
+		for i := len(signatures) - 1; i >= 0; i-- {
+			sig := signatures[i]
+			if a.pastEnd(sig.Slot) {
+				continue
+			}
+			logs := &solana.Logs{Signature: sig.Signature, Err: sig.Err}
+			if err := a.emitTransaction(ctx, n, addr, sig.Slot, logs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// signaturesSince lists the signatures of the transactions mentioning an
+// address from the next slot on, from the newest.
+func (a *solanaAdapter) signaturesSince(ctx context.Context, n *solanaNode, addr string) ([]solana.SignatureInfo, error) {
+	var signatures []solana.SignatureInfo
+	opts := map[string]interface{}{
+		"commitment": a.transactionCommitment(),
+		"limit":      solanaSignaturesPageSize,
+	}
+	for {
+		var page []solana.SignatureInfo
+		if err := n.Call(ctx, &page, "getSignaturesForAddress", addr, opts); err != nil {
+			return nil, err
+		}
+		for _, sig := range page {
+			if sig.Slot < a.next {
+				return signatures, nil
+			}
+			signatures = append(signatures, sig)
+		}
+		if len(page) < solanaSignaturesPageSize {
+			return signatures, nil
+		}
+		opts["before"] = page[len(page)-1].Signature
+	}
+}
+
+// emitTransaction emits the logs of a transaction mentioning a subscribed
+// address, enriched with the details getTransaction returns. The logs are
+// read from the transaction when not notified.
+func (a *solanaAdapter) emitTransaction(ctx context.Context, n *solanaNode, mention string, slot uint64, logs *solana.Logs) error {
+	source := sourcesv1alpha1.BlockchainAccountEventSource(a.source, mention)
+	if a.emittedIn(slot)[source+"/"+logs.Signature] {
+		return nil
+	}
+
+	var tx *solana.TransactionResult
+	err := n.Call(ctx, &tx, "getTransaction", logs.Signature, map[string]interface{}{
+		"commitment":                     a.transactionCommitment(),
+		"encoding":                       "json",
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get transaction %s: %w", logs.Signature, err)
+	}
+
+	data := &solanaTransaction{
+		ChainID:   a.observedChainID,
+		Slot:      slot,
+		Signature: logs.Signature,
+		Mention:   mention,
+		Err:       logs.Err,
+		Logs:      logs.Logs,
+	}
+	if tx != nil {
+		// Processed transactions may not be returned yet, and are emitted
+		// with their notified logs only.
+		if tx.BlockTime != nil {
+			t := time.Unix(*tx.BlockTime, 0).UTC()
+			data.BlockTime = &t
+		}
+		if tx.Meta != nil {
+			data.Err = tx.Meta.Err
+			if data.Logs == nil {
+				data.Logs = tx.Meta.LogMessages
+			}
+			fee := tx.Meta.Fee
+			data.Fee = &fee
+			data.ComputeUnitsConsumed = tx.Meta.ComputeUnitsConsumed
+		}
+		data.Accounts = tx.AccountKeys()
+		data.RecentBlockhash = tx.Transaction.Message.RecentBlockhash
+		data.Version = tx.Version
+	}
+	if len(data.Err) == 0 {
+		data.Err = json.RawMessage("null")
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetID(logs.Signature)
+	event.SetType(solanaLogEventType)
+	event.SetSource(source)
+	event.SetSubject(logs.Signature)
+	if data.BlockTime != nil {
+		event.SetTime(*data.BlockTime)
+		a.blockTime = *data.BlockTime
+	}
+	a.setFinality(&event)
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &slot,
+		txHash:      logs.Signature,
+		account:     mention,
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit transaction %s of slot %d: %w", logs.Signature, slot, err)
+	}
+	a.emittedIn(slot)[source+"/"+logs.Signature] = true
+	return nil
+}
+
+// emitAccount emits the new state of an account.
+func (a *solanaAdapter) emitAccount(ctx context.Context, address string, slot uint64, account *solana.Account) error {
+	source := sourcesv1alpha1.BlockchainAccountEventSource(a.source, address)
+	id := strconv.FormatUint(slot, 10)
+	if a.emittedIn(slot)[source+"/"+id] {
+		return nil
+	}
+
+	data := &solanaAccount{
+		ChainID: a.observedChainID,
+		Slot:    slot,
+		Address: address,
+	}
+	if account != nil {
+		data.Lamports = account.Lamports
+		data.Owner = account.Owner
+		data.Data = account.Data
+		data.Executable = account.Executable
+		data.RentEpoch = account.RentEpoch
+		data.Space = account.Space
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetType(solanaAccountEventType)
+	event.SetSource(source)
+	event.SetSubject(address)
+	a.setFinality(&event)
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &slot,
+		account:     address,
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit the change of account %s at slot %d: %w", address, slot, err)
+	}
+	a.emittedIn(slot)[source+"/"+id] = true
+	return nil
+}
+
+// emitSlot emits a processed slot. Slots are notified before they reach
+// any commitment, so they are emitted at the latest finality level.
+func (a *solanaAdapter) emitSlot(ctx context.Context, info *solana.SlotInfo) error {
+	id := strconv.FormatUint(info.Slot, 10)
+	if a.emittedIn(info.Slot)[a.source+"/"+id] {
+		return nil
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetType(solanaSlotEventType)
+	event.SetSource(a.source)
+	event.SetSubject(id)
+	event.SetExtension(finalityExtension, string(sourcesv1alpha1.FinalityLevelLatest))
+	slot := info.Slot
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &slot,
+	}
+	data := &solanaSlot{
+		ChainID: a.observedChainID,
+		Slot:    info.Slot,
+		Parent:  info.Parent,
+		Root:    info.Root,
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit slot %d: %w", info.Slot, err)
+	}
+	a.emittedIn(info.Slot)[a.source+"/"+id] = true
+	return nil
+}
+
+// setFinality sets the finality level of the commitment of the
+// subscriptions on an event.
+func (a *solanaAdapter) setFinality(event *cloudevents.Event) {
+	level := a.finality
+	if level == "" {
+		level = sourcesv1alpha1.FinalityLevelLatest
+	}
+	event.SetExtension(finalityExtension, string(level))
+}
+
+// emittedIn returns the events emitted for a slot, by CloudEvent source
+// and ID.
+func (a *solanaAdapter) emittedIn(slot uint64) map[string]bool {
+	if a.recent == nil {
+		a.recent = make(map[uint64]map[string]bool)
+	}
+	emitted, ok := a.recent[slot]
+	if !ok {
+		emitted = make(map[string]bool)
+		a.recent[slot] = emitted
+	}
+	return emitted
+}
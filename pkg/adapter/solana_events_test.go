@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestSolanaAdapterRecoversMissedTransactions(t *testing.T) {
+	node := newFakeSolana(testSolanaGenesis, 10)
+	rpcURL := serveFakeSolana(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestSolanaAdapter(t, ce, rpcURL)
+	a.mentionsJSON = fmt.Sprintf("[%q]", testSolanaProgram)
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "logsSubscribe", 1)
+		node.produce(programTx(0))
+		waitForEvents(t, ce, 1)
+
+		// Transactions made while disconnected are recovered from the
+		// signatures of the mentioned address, without emitting the ones
+		// already delivered twice.
+		node.disconnect()
+		node.produce(programTx(1))
+		node.waitForCalls(t, "logsSubscribe", 2)
+		node.produce(programTx(2))
+		waitForEvents(t, ce, 3)
+	})
+
+	want := []string{programTx(0).signature, programTx(1).signature, programTx(2).signature}
+	if diff := cmp.Diff(want, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestSolanaAdapterEmitsTransactionsOnce(t *testing.T) {
+	node := newFakeSolana(testSolanaGenesis, 10)
+	rpcURL := serveFakeSolana(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestSolanaAdapter(t, ce, rpcURL)
+	a.mentionsJSON = fmt.Sprintf("[%q, %q]", testSolanaProgram, testSolanaAccount)
+
+	tx := programTx(0, testSolanaAccount)
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "logsSubscribe", 2)
+		node.produce(tx)
+		waitForEvents(t, ce, 2)
+		// A transaction notified again is not emitted twice.
+		node.disconnect()
+		node.waitForCalls(t, "logsSubscribe", 4)
+		node.produce(programTx(1))
+		waitForEvents(t, ce, 3)
+	})
+
+	// A transaction mentioning several addresses is emitted once per
+	// address, from the source of each.
+	var sources []string
+	for _, e := range ce.Sent() {
+		if e.Subject() == tx.signature {
+			sources = append(sources, e.Source())
+		}
+	}
+	sort.Strings(sources)
+	want := []string{
+		fmt.Sprintf("solana:%s/account/%s", testSolanaChain, testSolanaAccount),
+		fmt.Sprintf("solana:%s/account/%s", testSolanaChain, testSolanaProgram),
+	}
+	if diff := cmp.Diff(want, sources); diff != "" {
+		t.Errorf("unexpected sources (-want, +got) = %v", diff)
+	}
+	if n := len(ce.Sent()); n != 3 {
+		t.Errorf("sent %d events, want 3", n)
+	}
+}
+
+func TestSolanaAdapterEmitsUnconfirmedTransactions(t *testing.T) {
+	node := newFakeSolana(testSolanaGenesis, 10)
+	rpcURL := serveFakeSolana(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestSolanaAdapter(t, ce, rpcURL)
+	a.finality = "latest"
+	a.mentionsJSON = fmt.Sprintf("[%q]", testSolanaProgram)
+
+	tx := programTx(0)
+	node.unconfirmed[tx.signature] = true
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "logsSubscribe", 1)
+		node.produce(tx)
+		waitForEvents(t, ce, 1)
+	})
+
+	// Processed transactions are not returned by getTransaction yet, and
+	// are emitted with the logs they were notified with.
+	e := ce.Sent()[0]
+	if got := e.Extensions()[finalityExtension]; got != "latest" {
+		t.Errorf("finality = %v, want latest", got)
+	}
+	var got solanaTransaction
+	if err := json.Unmarshal(e.Data(), &got); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	want := solanaTransaction{
+		ChainID:   testSolanaChain,
+		Slot:      11,
+		Signature: tx.signature,
+		Mention:   testSolanaProgram,
+		Err:       json.RawMessage("null"),
+		Logs:      tx.logs,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected transaction (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,567 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/pkg/logging"
+	pkgtesting "knative.dev/pkg/reconciler/testing"
+
+	"knative.dev/eventing-blockchain/pkg/solana"
+)
+
+const (
+	// testSolanaGenesis is the genesis hash of mainnet-beta, and
+	// testSolanaChain its CAIP-2 reference.
+	testSolanaGenesis = "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d"
+	testSolanaChain   = "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"
+	// testDevnetGenesis is the genesis hash of devnet.
+	testDevnetGenesis = "EtWTRABZaYq6iMfeYKouRu166VU2xqa1wcaWoxPkrZBG"
+
+	testSolanaProgram = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	testSolanaAccount = "SysvarC1ock11111111111111111111111111111111"
+	testSolanaPayer   = "Vote111111111111111111111111111111111111111"
+)
+
+// fakeSolana is an in-memory Solana node serving its JSON-RPC endpoint over
+// HTTP and its PubSub endpoint over WebSocket. It has the same slot at all
+// commitment levels.
+type fakeSolana struct {
+	mu          sync.Mutex
+	genesisHash string
+	slot        uint64
+	txs         []*fakeSolanaTx
+	// unconfirmed are the signatures of the transactions getTransaction
+	// does not return yet.
+	unconfirmed map[string]bool
+	// subscriptions are the subscriptions made per connection.
+	subscriptions    map[*fakeConn][]fakeSolanaSubscription
+	lastSubscription uint64
+
+	callCounter
+}
+
+type fakeSolanaSubscription struct {
+	id     uint64
+	method string
+	// address is the mentioned address of logsSubscribe subscriptions, and
+	// the account of accountSubscribe ones.
+	address string
+}
+
+// fakeSolanaTx is a transaction of the node, using accounts.
+type fakeSolanaTx struct {
+	slot      uint64
+	signature string
+	accounts  []string
+	logs      []string
+}
+
+func newFakeSolana(genesisHash string, slot uint64) *fakeSolana {
+	return &fakeSolana{
+		genesisHash:   genesisHash,
+		slot:          slot,
+		unconfirmed:   make(map[string]bool),
+		subscriptions: make(map[*fakeConn][]fakeSolanaSubscription),
+	}
+}
+
+// programTx returns the n-th transaction, invoking the program.
+func programTx(n int, mentions ...string) fakeSolanaTx {
+	return fakeSolanaTx{
+		signature: solana.EncodeBase58(bytes.Repeat([]byte{byte(n + 1)}, 64)),
+		accounts:  append([]string{testSolanaPayer, testSolanaProgram}, mentions...),
+		logs: []string{
+			fmt.Sprintf("Program %s invoke [1]", testSolanaProgram),
+			fmt.Sprintf("Program log: Instruction: Transfer %d", n),
+			fmt.Sprintf("Program %s success", testSolanaProgram),
+		},
+	}
+}
+
+// produce moves to the next slot, holding txs, and notifies the
+// subscribers of the slot, then of the logs of its transactions.
+func (n *fakeSolana) produce(txs ...fakeSolanaTx) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.slot++
+	for i := range txs {
+		tx := txs[i]
+		tx.slot = n.slot
+		n.txs = append(n.txs, &tx)
+	}
+
+	for c, subs := range n.subscriptions {
+		for _, sub := range subs {
+			if sub.method == "slotSubscribe" {
+				n.notify(c, "slotNotification", sub, solana.SlotInfo{Slot: n.slot, Parent: n.slot - 1, Root: n.slot - 1})
+			}
+		}
+	}
+	for _, tx := range n.txs[len(n.txs)-len(txs):] {
+		for c, subs := range n.subscriptions {
+			for _, sub := range subs {
+				if sub.method == "logsSubscribe" && tx.mentions(sub.address) {
+					n.notify(c, "logsNotification", sub, solana.LogsNotification{
+						Context: solana.Context{Slot: tx.slot},
+						Value:   solana.Logs{Signature: tx.signature, Err: json.RawMessage("null"), Logs: tx.logs},
+					})
+				}
+			}
+		}
+	}
+}
+
+func (tx *fakeSolanaTx) mentions(address string) bool {
+	for _, m := range tx.accounts {
+		if m == address {
+			return true
+		}
+	}
+	return false
+}
+
+// setAccount changes the balance of an account at the current slot, and
+// notifies the subscribers of the account.
+func (n *fakeSolana) setAccount(address string, lamports uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for c, subs := range n.subscriptions {
+		for _, sub := range subs {
+			if sub.method == "accountSubscribe" && sub.address == address {
+				n.notify(c, "accountNotification", sub, solana.AccountNotification{
+					Context: solana.Context{Slot: n.slot},
+					Value: &solana.Account{
+						Lamports:  lamports,
+						Owner:     "11111111111111111111111111111111",
+						Data:      json.RawMessage(`["", "base64"]`),
+						RentEpoch: 18446744073709551615,
+					},
+				})
+			}
+		}
+	}
+}
+
+func (n *fakeSolana) notify(c *fakeConn, method string, sub fakeSolanaSubscription, result interface{}) {
+	c.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params": map[string]interface{}{
+			"subscription": sub.id,
+			"result":       result,
+		},
+	})
+}
+
+// transaction returns the result of getTransaction for a transaction.
+func (n *fakeSolana) transaction(tx *fakeSolanaTx) *solana.TransactionResult {
+	blockTime := int64(1700000000 + tx.slot)
+	computeUnits := uint64(150)
+	return &solana.TransactionResult{
+		Slot:      tx.slot,
+		BlockTime: &blockTime,
+		Meta: &solana.TransactionMeta{
+			Fee:                  5000,
+			LogMessages:          tx.logs,
+			ComputeUnitsConsumed: &computeUnits,
+		},
+		Transaction: solana.Transaction{
+			Signatures: []string{tx.signature},
+			Message: solana.Message{
+				AccountKeys:     tx.accounts,
+				RecentBlockhash: "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N",
+			},
+		},
+		Version: json.RawMessage("0"),
+	}
+}
+
+// disconnect closes all WebSocket connections.
+func (n *fakeSolana) disconnect() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for c := range n.subscriptions {
+		c.conn.Close()
+		delete(n.subscriptions, c)
+	}
+}
+
+func (n *fakeSolana) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		var req fakeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(n.handle(&req, nil))
+		return
+	}
+
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &fakeConn{conn: conn}
+	n.mu.Lock()
+	n.subscriptions[c] = nil
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.subscriptions, c)
+		n.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var req fakeRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		c.write(n.handle(&req, c))
+	}
+}
+
+// handle returns the response to a request, made over the WebSocket
+// connection c for subscriptions.
+func (n *fakeSolana) handle(req *fakeRequest, c *fakeConn) map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.called(req.Method)
+
+	var (
+		result  interface{}
+		message string
+	)
+	var address string
+	if len(req.Params) > 0 {
+		json.Unmarshal(req.Params[0], &address)
+	}
+	switch req.Method {
+	case "getGenesisHash":
+		result = n.genesisHash
+	case "getSlot":
+		result = n.slot
+	case "getTransaction":
+		result = nil
+		for _, tx := range n.txs {
+			if tx.signature == address && !n.unconfirmed[address] {
+				result = n.transaction(tx)
+			}
+		}
+	case "getSignaturesForAddress":
+		var opts struct {
+			Limit  int    `json:"limit"`
+			Before string `json:"before"`
+		}
+		json.Unmarshal(req.Params[1], &opts)
+		signatures := []solana.SignatureInfo{}
+		for i := len(n.txs) - 1; i >= 0 && len(signatures) < opts.Limit; i-- {
+			tx := n.txs[i]
+			if opts.Before != "" {
+				if tx.signature == opts.Before {
+					opts.Before = ""
+				}
+				continue
+			}
+			if tx.mentions(address) {
+				signatures = append(signatures, solana.SignatureInfo{Signature: tx.signature, Slot: tx.slot, Err: json.RawMessage("null")})
+			}
+		}
+		result = signatures
+	case "logsSubscribe", "accountSubscribe", "slotSubscribe":
+		if c == nil {
+			message = "subscriptions require a WebSocket connection"
+			break
+		}
+		if req.Method == "logsSubscribe" {
+			var filter struct {
+				Mentions []string `json:"mentions"`
+			}
+			json.Unmarshal(req.Params[0], &filter)
+			address = filter.Mentions[0]
+		}
+		n.lastSubscription++
+		n.subscriptions[c] = append(n.subscriptions[c], fakeSolanaSubscription{id: n.lastSubscription, method: req.Method, address: address})
+		result = n.lastSubscription
+	default:
+		message = "method not found"
+	}
+
+	resp := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+	}
+	if message != "" {
+		resp["error"] = map[string]interface{}{"code": -32601, "message": message}
+	} else {
+		resp["result"] = result
+	}
+	return resp
+}
+
+// serveFakeSolana serves a node on a port and the one following it, where
+// its PubSub endpoint is expected, and returns the URL of its JSON-RPC
+// endpoint.
+func serveFakeSolana(t *testing.T, n *fakeSolana) string {
+	t.Helper()
+	for attempt := 0; attempt < 10; attempt++ {
+		rpc, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen() = %v", err)
+		}
+		port := rpc.Addr().(*net.TCPAddr).Port
+		pubSub, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port+1))
+		if err != nil {
+			rpc.Close()
+			continue
+		}
+		for _, l := range []net.Listener{rpc, pubSub} {
+			server := httptest.NewUnstartedServer(n)
+			server.Listener = l
+			server.Start()
+			t.Cleanup(server.Close)
+		}
+		return fmt.Sprintf("http://127.0.0.1:%d", port)
+	}
+	t.Fatal("Could not find two consecutive free ports")
+	return ""
+}
+
+func newTestSolanaAdapter(t *testing.T, ce *adaptertest.TestCloudEventsClient, rpcURL string) *solanaAdapter {
+	env := solanaEnvConfig{
+		chainEnvConfig: chainEnvConfig{
+			EnvConfig: adapter.EnvConfig{
+				Namespace: "default",
+			},
+			EnvRPCURL: rpcURL,
+		},
+		EnvFinality: "confirmed",
+	}
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+	logger := zap.NewExample().Sugar()
+	ctx = logging.WithLogger(ctx, logger)
+
+	a := NewSolanaAdapter(ctx, &env, ce).(*solanaAdapter)
+	a.minReconnectDelay = 10 * time.Millisecond
+	// Slots are polled explicitly by the tests that need it, as the
+	// notifications of different subscriptions are handled in any order.
+	a.slotPollInterval = time.Hour
+	return a
+}
+
+func TestSolanaAdapterEmitsEvents(t *testing.T) {
+	node := newFakeSolana(testSolanaGenesis, 10)
+	rpcURL := serveFakeSolana(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestSolanaAdapter(t, ce, rpcURL)
+	a.mentionsJSON = fmt.Sprintf("[%q]", testSolanaProgram)
+	a.accountsJSON = fmt.Sprintf("[%q]", testSolanaAccount)
+	a.slots = true
+
+	tx := programTx(1)
+	runAdapter(t, a, func() {
+		// Slots produced before the adapter started are not emitted.
+		node.waitForCalls(t, "slotSubscribe", 1)
+		node.produce(tx)
+		node.setAccount(testSolanaAccount, 1000000000)
+		waitForEvents(t, ce, 3)
+	})
+
+	// Events notified over different subscriptions may be emitted in any
+	// order.
+	sent := ce.Sent()
+	sort.Slice(sent, func(i, j int) bool {
+		return sent[i].Type() < sent[j].Type()
+	})
+	accountSource := fmt.Sprintf("solana:%s/account/%s", testSolanaChain, testSolanaAccount)
+	programSource := fmt.Sprintf("solana:%s/account/%s", testSolanaChain, testSolanaProgram)
+	for i, want := range []struct {
+		eventType  string
+		id         string
+		source     string
+		subject    string
+		extensions map[string]interface{}
+	}{{
+		eventType: solanaAccountEventType,
+		id:        "11",
+		source:    accountSource,
+		subject:   testSolanaAccount,
+		extensions: map[string]interface{}{
+			finalityExtension:    "confirmed",
+			chainIDExtension:     testSolanaChain,
+			blockNumberExtension: int32(11),
+			accountExtension:     testSolanaAccount,
+		},
+	}, {
+		eventType: solanaLogEventType,
+		id:        tx.signature,
+		source:    programSource,
+		subject:   tx.signature,
+		extensions: map[string]interface{}{
+			finalityExtension:    "confirmed",
+			chainIDExtension:     testSolanaChain,
+			blockNumberExtension: int32(11),
+			txHashExtension:      tx.signature,
+			accountExtension:     testSolanaProgram,
+		},
+	}, {
+		eventType: solanaSlotEventType,
+		id:        "11",
+		source:    "solana:" + testSolanaChain,
+		subject:   "11",
+		extensions: map[string]interface{}{
+			finalityExtension:    "latest",
+			chainIDExtension:     testSolanaChain,
+			blockNumberExtension: int32(11),
+		},
+	}} {
+		e := sent[i]
+		if e.Type() != want.eventType || e.ID() != want.id {
+			t.Errorf("event %d = %s %s, want %s %s", i, e.Type(), e.ID(), want.eventType, want.id)
+		}
+		if e.Source() != want.source || e.Subject() != want.subject {
+			t.Errorf("event %d source and subject = %s %s, want %s %s", i, e.Source(), e.Subject(), want.source, want.subject)
+		}
+		if diff := cmp.Diff(want.extensions, e.Extensions()); diff != "" {
+			t.Errorf("unexpected extensions of event %d (-want, +got) = %v", i, diff)
+		}
+	}
+
+	var account solanaAccount
+	if err := json.Unmarshal(sent[0].Data(), &account); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantAccount := solanaAccount{
+		ChainID:   testSolanaChain,
+		Slot:      11,
+		Address:   testSolanaAccount,
+		Lamports:  1000000000,
+		Owner:     "11111111111111111111111111111111",
+		Data:      json.RawMessage(`["","base64"]`),
+		RentEpoch: 18446744073709551615,
+	}
+	if diff := cmp.Diff(wantAccount, account); diff != "" {
+		t.Errorf("unexpected account (-want, +got) = %v", diff)
+	}
+
+	blockTime := time.Unix(1700000011, 0).UTC()
+	if !sent[1].Time().Equal(blockTime) {
+		t.Errorf("event time = %s, want %s", sent[1].Time(), blockTime)
+	}
+	var got solanaTransaction
+	if err := json.Unmarshal(sent[1].Data(), &got); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	fee, computeUnits := uint64(5000), uint64(150)
+	wantTx := solanaTransaction{
+		ChainID:              testSolanaChain,
+		Slot:                 11,
+		Signature:            tx.signature,
+		Mention:              testSolanaProgram,
+		Err:                  json.RawMessage("null"),
+		Logs:                 tx.logs,
+		BlockTime:            &blockTime,
+		Fee:                  &fee,
+		ComputeUnitsConsumed: &computeUnits,
+		Accounts:             tx.accounts,
+		RecentBlockhash:      "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N",
+		Version:              json.RawMessage("0"),
+	}
+	if diff := cmp.Diff(wantTx, got); diff != "" {
+		t.Errorf("unexpected transaction (-want, +got) = %v", diff)
+	}
+
+	var slot solanaSlot
+	if err := json.Unmarshal(sent[2].Data(), &slot); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	if diff := cmp.Diff(solanaSlot{ChainID: testSolanaChain, Slot: 11, Parent: 10, Root: 10}, slot); diff != "" {
+		t.Errorf("unexpected slot (-want, +got) = %v", diff)
+	}
+}
+
+func TestSolanaAdapterBackfillsFromStartSlot(t *testing.T) {
+	node := newFakeSolana(testSolanaGenesis, 10)
+	for i := 0; i < 4; i++ {
+		node.produce(programTx(i))
+	}
+	rpcURL := serveFakeSolana(t, node)
+
+	ce := adaptertest.NewTestClient()
+	a := newTestSolanaAdapter(t, ce, rpcURL)
+	a.mentionsJSON = fmt.Sprintf("[%q]", testSolanaProgram)
+	start, end := uint64(12), uint64(13)
+	a.startBlock, a.endBlock = &start, &end
+
+	runAdapter(t, a, func() {
+		waitForEvents(t, ce, 2)
+		node.waitForCalls(t, "getTransaction", 2)
+	})
+
+	if !a.reachedEnd() {
+		t.Error("reachedEnd() = false, want true past the end slot")
+	}
+
+	// Transactions are recovered from the oldest.
+	want := []string{programTx(1).signature, programTx(2).signature}
+	if diff := cmp.Diff(want, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestSolanaAdapterFailsOver(t *testing.T) {
+	node := newFakeSolana(testSolanaGenesis, 10)
+	rpcURL := serveFakeSolana(t, node)
+	otherURL := serveFakeSolana(t, newFakeSolana(testDevnetGenesis, 10))
+
+	ce := adaptertest.NewTestClient()
+	a := newTestSolanaAdapter(t, ce, "")
+	a.chainID = "mainnet-beta"
+	a.endpointsJSON = fmt.Sprintf(`[{"url": %q}, {"url": %q, "priority": 1}]`, otherURL, rpcURL)
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "slotSubscribe", 1)
+		node.waitForCalls(t, "getSlot", 1)
+		node.produce()
+		waitForEvents(t, ce, 1)
+	})
+
+	if a.active != rpcURL {
+		t.Errorf("active node = %q, want %q", a.active, rpcURL)
+	}
+	if got := ce.Sent()[0].Source(); got != "solana:"+testSolanaChain {
+		t.Errorf("event source = %s, want solana:%s", got, testSolanaChain)
+	}
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"knative.dev/eventing/pkg/adapter/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+	"knative.dev/eventing-blockchain/pkg/tendermint"
+)
+
+// tendermintDefaultQuery is the query subscribed to when none is given.
+const tendermintDefaultQuery = "tm.event='NewBlock'"
+
+// tendermintHeaderQuery is the query of the subscription the adapter
+// follows the height of the chain with.
+var tendermintHeaderQuery = fmt.Sprintf("%s='%s'", tendermint.EventTypeKey, tendermint.EventNewBlockHeader)
+
+type tendermintEnvConfig struct {
+	chainEnvConfig
+
+	// Environment variable containing the JSON encoded list of the queries
+	// selecting the NewBlock and Tx events to emit. New blocks are emitted
+	// when not set
+	EnvQueries string `envconfig:"BLOCKCHAIN_TENDERMINT_QUERIES"`
+}
+
+// NewTendermintEnvConfig function reads env variables defined in
+// tendermintEnvConfig structure and returns accessor interface
+func NewTendermintEnvConfig() adapter.EnvConfigAccessor {
+	return &tendermintEnvConfig{}
+}
+
+// tendermintAdapter subscribes to the events of the nodes of a Tendermint
+// or CometBFT chain over WebSocket, and converts the NewBlock and Tx events
+// the queries select to CloudEvents. Nodes notify the events of the
+// committed blocks, which are final, so there are no reorgs. The events
+// notified while disconnected are recovered from the results of the blocks
+// once reconnected. Events notified over different subscriptions may be
+// emitted in another order than the node notified them.
+type tendermintAdapter struct {
+	emitter
+
+	rpcURL            string
+	endpointsJSON     string
+	chainID           string
+	queriesJSON       string
+	minReconnectDelay time.Duration
+	startBlock        *uint64
+
+	// endpoints are the nodes, in priority order.
+	endpoints []*endpoint
+	// queries select the events to emit.
+	queries []tendermintQuery
+
+	// observedChainID is the chain ID the nodes reported, and source the
+	// CloudEvent source of the emitted events, once known.
+	observedChainID string
+	source          string
+	// encoded is set when the active node encodes the attributes of events
+	// in base64.
+	encoded bool
+	// active is the name of the node last read from.
+	active string
+	// head is the height of the newest block.
+	head uint64
+	// recent tracks the blocks from the next one on, whose events may be
+	// notified over several subscriptions.
+	recent map[uint64]*tendermintRecentBlock
+}
+
+// tendermintQuery is a query subscribed to, selecting NewBlock or Tx
+// events.
+type tendermintQuery struct {
+	raw   string
+	query *tendermint.Query
+}
+
+// NewTendermintAdapter returns the instance of tendermintAdapter that implements adapter.Adapter interface
+func NewTendermintAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	env := processed.(*tendermintEnvConfig)
+
+	a := &tendermintAdapter{
+		emitter:           newEmitter(ctx, &env.chainEnvConfig, ceClient),
+		rpcURL:            env.EnvRPCURL,
+		endpointsJSON:     env.EnvEndpoints,
+		chainID:           env.EnvChainID,
+		queriesJSON:       env.EnvQueries,
+		minReconnectDelay: minReconnectDelay,
+		startBlock:        env.EnvStartBlock,
+	}
+	a.cursor = a
+	return a
+}
+
+func (a *tendermintAdapter) Start(ctx context.Context) error {
+	if err := a.setup(); err != nil {
+		return err
+	}
+	return a.hold(ctx, func(ctx context.Context) error {
+		if err := a.init(ctx); err != nil {
+			return err
+		}
+		return a.stream(ctx)
+	})
+}
+
+// setup checks the settings of the adapter, and parses the queries and the
+// filters they hold.
+func (a *tendermintAdapter) setup() error {
+	configs, err := endpointConfigs(a.rpcURL, a.endpointsJSON)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(configs, func(i, j int) bool {
+		return configs[i].Priority < configs[j].Priority
+	})
+	a.endpoints = make([]*endpoint, 0, len(configs))
+	for _, c := range configs {
+		if !isWebSocket(c.URL) {
+			return fmt.Errorf("endpoint %s: tendermint nodes are subscribed to over WebSocket", c.URL)
+		}
+		a.endpoints = append(a.endpoints, &endpoint{endpointConfig: c})
+	}
+
+	queries, err := parseTendermintQueries(a.queriesJSON)
+	if err != nil {
+		return err
+	}
+	a.queries = queries
+
+	if err := a.setupFilters(); err != nil {
+		return err
+	}
+
+	if a.chainID != "" {
+		a.observedChainID = a.chainID
+		a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilyTendermint, a.chainID)
+	}
+	return nil
+}
+
+// parseTendermintQueries parses the JSON encoded list of queries, which
+// must select NewBlock or Tx events. New blocks are selected when there is
+// none.
+func parseTendermintQueries(queriesJSON string) ([]tendermintQuery, error) {
+	raw := []string{tendermintDefaultQuery}
+	if queriesJSON != "" {
+		if err := json.Unmarshal([]byte(queriesJSON), &raw); err != nil {
+			return nil, fmt.Errorf("invalid tendermint queries: %w", err)
+		}
+	}
+
+	queries := make([]tendermintQuery, 0, len(raw))
+	for i, s := range raw {
+		q, err := tendermint.ParseQuery(s)
+		if err != nil {
+			return nil, fmt.Errorf("tendermint query %d: %w", i, err)
+		}
+		switch q.EventType() {
+		case tendermint.EventNewBlock, tendermint.EventTx:
+		default:
+			return nil, fmt.Errorf("tendermint query %d: %q selects neither NewBlock nor Tx events", i, s)
+		}
+		queries = append(queries, tendermintQuery{raw: s, query: q})
+	}
+	return queries, nil
+}
+
+// init resumes from the saved checkpoint. Without a checkpoint, events are
+// emitted from the start block, or from the newest block once the first
+// node is reached.
+func (a *tendermintAdapter) init(ctx context.Context) error {
+	resumed, err := a.resume(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resumed:
+		a.logger.Infof("Resuming from checkpoint at block %d", a.next)
+	case a.startBlock != nil:
+		a.next, a.positioned = *a.startBlock, true
+		a.logger.Infof("Backfilling from block %d", a.next)
+	}
+	return nil
+}
+
+// stream subscribes to the events of the nodes until ctx is done, trying
+// them in priority order and starting over with an exponential backoff
+// whenever none could be subscribed to.
+func (a *tendermintAdapter) stream(ctx context.Context) error {
+	defer a.saveCheckpoint(context.Background(), true)
+
+	delay := a.minReconnectDelay
+	for {
+		received, err := a.streamNodes(ctx)
+		if ctx.Err() != nil {
+			a.logger.Infof("Streaming stopped")
+			return nil
+		}
+		if a.reachedEnd() {
+			a.saveCheckpoint(ctx, true)
+			a.logger.Infof("Reached end block %d, streaming stopped", *a.endBlock)
+			<-ctx.Done()
+			return nil
+		}
+		if received {
+			delay = a.minReconnectDelay
+		}
+		a.reportConnection(ctx, err)
+		a.logger.Errorf("Stream interrupted, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			a.logger.Infof("Streaming stopped")
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// streamNodes subscribes to the events of the first node that notifies
+// any, in priority order. It returns the error of the last attempt, and
+// whether any block was received.
+func (a *tendermintAdapter) streamNodes(ctx context.Context) (bool, error) {
+	var err error
+	for _, e := range a.endpoints {
+		var received bool
+		received, err = a.streamOnce(ctx, e)
+		if received || ctx.Err() != nil || a.reachedEnd() {
+			return received, err
+		}
+		if err != nil {
+			a.logger.Warnf("Failed to subscribe to %s: %v", e.name(), err)
+		}
+	}
+	return false, err
+}
+
+// streamOnce subscribes to the events of a node and emits them until the
+// connection is lost, the end block is reached or ctx is done. It reports
+// whether any block was received, so that the caller can tell a flapping
+// node from a working one.
+func (a *tendermintAdapter) streamOnce(ctx context.Context, e *endpoint) (bool, error) {
+	conn, err := jsonrpc.DialWebSocket(ctx, e.URL, e.options()...)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	status, err := a.checkNode(ctx, conn, e)
+	if err != nil {
+		return false, err
+	}
+
+	// Events are subscribed to before the header of the blocks, so that
+	// the events of the first block notified are not missed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	notifications := make(chan json.RawMessage)
+	for _, q := range a.queries {
+		if _, err := conn.SubscribeResponses(ctx, notifications, "subscribe", q.raw); err != nil {
+			return false, fmt.Errorf("failed to subscribe to %q on %s: %w", q.raw, e.name(), err)
+		}
+	}
+	if _, err := conn.SubscribeResponses(ctx, notifications, "subscribe", tendermintHeaderQuery); err != nil {
+		return false, fmt.Errorf("failed to subscribe to new blocks on %s: %w", e.name(), err)
+	}
+
+	s := &tendermintStream{conn: conn, endpoint: e, earliest: uint64(status.SyncInfo.EarliestBlockHeight)}
+	for {
+		select {
+		case <-ctx.Done():
+			return s.synced, nil
+		case <-conn.Done():
+			return s.synced, fmt.Errorf("connection to %s lost: %w", e.name(), conn.Err())
+		case n := <-notifications:
+			if err := a.handleNotification(ctx, s, n); err != nil {
+				return s.synced, err
+			}
+			if a.reachedEnd() {
+				return s.synced, nil
+			}
+			a.saveCheckpoint(ctx, false)
+		}
+	}
+}
+
+// checkNode checks that a node serves the expected chain, and learns the
+// chain when none is expected. It returns the status of the node.
+func (a *tendermintAdapter) checkNode(ctx context.Context, conn *jsonrpc.WSClient, e *endpoint) (*tendermint.Status, error) {
+	var status tendermint.Status
+	if err := conn.Call(ctx, &status, "status"); err != nil {
+		return nil, fmt.Errorf("failed to get the status of %s: %w", e.name(), err)
+	}
+	chainID := status.NodeInfo.Network
+	if chainID == "" {
+		return nil, fmt.Errorf("node %s reported no chain ID", e.name())
+	}
+	if a.observedChainID != "" && chainID != a.observedChainID {
+		return nil, &chainMismatchError{endpoint: e.name(), got: chainID, want: a.observedChainID}
+	}
+	if a.observedChainID == "" {
+		a.observedChainID = chainID
+		a.source = sourcesv1alpha1.BlockchainEventSource(sourcesv1alpha1.ChainFamilyTendermint, chainID)
+	}
+	a.encoded = tendermint.AttributesEncoded(status.NodeInfo.Version)
+	return &status, nil
+}
+
+// currentCheckpoint returns the height of the newest block whose events
+// have all been emitted, or nil if there is none yet.
+func (a *tendermintAdapter) currentCheckpoint() *checkpoint.Checkpoint {
+	if a.source == "" || !a.positioned || a.next == 0 {
+		return nil
+	}
+	return &checkpoint.Checkpoint{BlockNumber: a.next - 1}
+}
+
+// seek moves the adapter past the block of a checkpoint.
+func (a *tendermintAdapter) seek(cp *checkpoint.Checkpoint) {
+	a.next, a.positioned = cp.BlockNumber+1, true
+}
+
+// chainProgress reports how the adapter keeps up with the chain. Committed
+// blocks are final, so the final block is the head.
+func (a *tendermintAdapter) chainProgress() *checkpoint.Progress {
+	return &checkpoint.Progress{
+		ChainID:  a.observedChainID,
+		Head:     a.head,
+		Final:    a.head,
+		Endpoint: a.active,
+	}
+}
+
+// connectionFailure returns the report of a failure to subscribe to a
+// node, or nil if err is not one.
+func (a *tendermintAdapter) connectionFailure(err error) *checkpoint.Connection {
+	return connectionFailure(nil, err)
+}
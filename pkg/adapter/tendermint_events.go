@@ -0,0 +1,425 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+	"knative.dev/eventing-blockchain/pkg/tendermint"
+)
+
+var (
+	// tendermintBlockEventType is the CloudEvent type of the events
+	// emitted for every NewBlock event.
+	tendermintBlockEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyTendermint, sourcesv1alpha1.BlockchainEventKindBlock)
+
+	// tendermintTransactionEventType is the CloudEvent type of the events
+	// emitted for every Tx event.
+	tendermintTransactionEventType = sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyTendermint, sourcesv1alpha1.BlockchainEventKindTransaction)
+)
+
+// tendermintStream is the state of the subscriptions to a node.
+type tendermintStream struct {
+	conn     *jsonrpc.WSClient
+	endpoint *endpoint
+	// earliest is the height of the oldest block the node keeps the
+	// results of.
+	earliest uint64
+	// synced is set once the first header is notified, and the events of
+	// the blocks before it recovered.
+	synced bool
+	// pending are the events notified before the first header.
+	pending []*tendermintEvent
+}
+
+// tendermintRecentBlock is a block whose events may still be notified.
+type tendermintRecentBlock struct {
+	time    time.Time
+	emitted map[string]bool
+}
+
+// tendermintEvent is a NewBlock or a Tx event, notified or recovered from
+// the results of its block.
+type tendermintEvent struct {
+	height uint64
+	block  *tendermintBlock
+	tx     *tendermintTransaction
+}
+
+// id returns the ID of the CloudEvent of an event: the height of blocks,
+// which are final, and the hash of transactions.
+func (e *tendermintEvent) id() string {
+	if e.block != nil {
+		return strconv.FormatUint(e.height, 10)
+	}
+	return e.tx.Hash
+}
+
+// tendermintBlock is the data of block events.
+type tendermintBlock struct {
+	ChainID string `json:"chainID"`
+	Height  int64  `json:"height"`
+	// Hash is not notified by nodes before CometBFT 0.38.
+	Hash            string     `json:"hash,omitempty"`
+	Time            *time.Time `json:"time,omitempty"`
+	ProposerAddress string     `json:"proposerAddress,omitempty"`
+	TxHashes        []string   `json:"txHashes,omitempty"`
+	// Events are the events the application emitted while processing the
+	// block, outside of its transactions.
+	Events []tendermint.Event `json:"events,omitempty"`
+}
+
+// tendermintTransaction is the data of transaction events.
+type tendermintTransaction struct {
+	ChainID   string             `json:"chainID"`
+	Height    int64              `json:"height"`
+	Index     uint32             `json:"index"`
+	Hash      string             `json:"hash"`
+	Code      uint32             `json:"code"`
+	Codespace string             `json:"codespace,omitempty"`
+	Log       string             `json:"log,omitempty"`
+	Info      string             `json:"info,omitempty"`
+	GasWanted int64              `json:"gasWanted"`
+	GasUsed   int64              `json:"gasUsed"`
+	Events    []tendermint.Event `json:"events,omitempty"`
+}
+
+// decodeEvents returns the events of a block or a transaction with their
+// attributes decoded, as nodes before Tendermint 0.35 encode them.
+func (a *tendermintAdapter) decodeEvents(events []tendermint.Event) ([]tendermint.Event, error) {
+	if !a.encoded {
+		return events, nil
+	}
+	return tendermint.DecodeEvents(events)
+}
+
+func (a *tendermintAdapter) newBlockEvent(block *tendermint.Block, hash string, events []tendermint.Event) (*tendermintEvent, error) {
+	events, err := a.decodeEvents(events)
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", block.Header.Height, err)
+	}
+	data := &tendermintBlock{
+		ChainID:         a.observedChainID,
+		Height:          block.Header.Height,
+		Hash:            hash,
+		ProposerAddress: block.Header.ProposerAddress,
+		Events:          events,
+	}
+	if t := block.Header.Time; !t.IsZero() {
+		data.Time = &t
+	}
+	for _, tx := range block.Data.Txs {
+		data.TxHashes = append(data.TxHashes, tendermint.TxHash(tx))
+	}
+	return &tendermintEvent{height: uint64(block.Header.Height), block: data}, nil
+}
+
+func (a *tendermintAdapter) newTxEvent(tx *tendermint.TxResultWithHeight) (*tendermintEvent, error) {
+	hash := tendermint.TxHash(tx.Tx)
+	events, err := a.decodeEvents(tx.Result.Events)
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", hash, err)
+	}
+	return &tendermintEvent{
+		height: uint64(tx.Height),
+		tx: &tendermintTransaction{
+			ChainID:   a.observedChainID,
+			Height:    tx.Height,
+			Index:     tx.Index,
+			Hash:      hash,
+			Code:      tx.Result.Code,
+			Codespace: tx.Result.Codespace,
+			Log:       tx.Result.Log,
+			Info:      tx.Result.Info,
+			GasWanted: tx.Result.GasWanted,
+			GasUsed:   tx.Result.GasUsed,
+			Events:    events,
+		},
+	}, nil
+}
+
+// handleNotification handles an event notified to one of the
+// subscriptions to a node. Events notified before the first header are
+// held until the blocks before it are recovered.
+func (a *tendermintAdapter) handleNotification(ctx context.Context, s *tendermintStream, raw json.RawMessage) error {
+	var result tendermint.ResultEvent
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("invalid event notified by %s: %w", s.endpoint.name(), err)
+	}
+
+	var (
+		ev  *tendermintEvent
+		err error
+	)
+	switch result.Data.Type {
+	case tendermint.EventDataTypeNewBlockHeader:
+		var data tendermint.EventDataNewBlockHeader
+		if err := json.Unmarshal(result.Data.Value, &data); err != nil {
+			return fmt.Errorf("invalid header notified by %s: %w", s.endpoint.name(), err)
+		}
+		return a.handleHeader(ctx, s, &data.Header)
+	case tendermint.EventDataTypeNewBlock:
+		var data tendermint.EventDataNewBlock
+		if err := json.Unmarshal(result.Data.Value, &data); err != nil {
+			return fmt.Errorf("invalid block notified by %s: %w", s.endpoint.name(), err)
+		}
+		var hash string
+		if data.BlockID != nil {
+			hash = data.BlockID.Hash
+		}
+		ev, err = a.newBlockEvent(&data.Block, hash, data.Events())
+	case tendermint.EventDataTypeTx:
+		var data tendermint.EventDataTx
+		if err := json.Unmarshal(result.Data.Value, &data); err != nil {
+			return fmt.Errorf("invalid transaction notified by %s: %w", s.endpoint.name(), err)
+		}
+		ev, err = a.newTxEvent(&data.TxResult)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !s.synced {
+		s.pending = append(s.pending, ev)
+		return nil
+	}
+	return a.emitNotified(ctx, ev)
+}
+
+// handleHeader follows the height of the chain. The first header notified
+// by a node positions the adapter, or has the events of the blocks before
+// it recovered. The events of a block may be notified over other
+// subscriptions after the header of the next block, so the events of a
+// block are only considered all emitted once the header of the block after
+// the next one is notified.
+func (a *tendermintAdapter) handleHeader(ctx context.Context, s *tendermintStream, h *tendermint.Header) error {
+	height := uint64(h.Height)
+	if height > a.head {
+		a.head, a.blockTime = height, h.Time
+	}
+	if height >= a.next {
+		a.recentBlock(height).time = h.Time
+	}
+
+	if s.synced {
+		a.advance(height - 1)
+		return nil
+	}
+
+	s.synced = true
+	a.active = s.endpoint.name()
+	a.reportConnection(ctx, nil)
+	a.logger.Infof("Streaming chain %s from %s", a.observedChainID, s.endpoint.name())
+	if !a.positioned {
+		a.next, a.positioned = height, true
+		a.logger.Infof("Following chain %s from block %d", a.observedChainID, a.next)
+	} else if err := a.recoverBlocks(ctx, s, height-1); err != nil {
+		return err
+	}
+	a.advance(height)
+
+	pending := s.pending
+	s.pending = nil
+	for _, ev := range pending {
+		if err := a.emitNotified(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance moves the adapter past the blocks before height, whose events
+// have all been emitted, up to the block following the end block.
+func (a *tendermintAdapter) advance(height uint64) {
+	if a.endBlock != nil && height > *a.endBlock+1 {
+		height = *a.endBlock + 1
+	}
+	if height <= a.next {
+		return
+	}
+	a.next = height
+	for h := range a.recent {
+		if h < a.next {
+			delete(a.recent, h)
+		}
+	}
+}
+
+// recentBlock returns the tracking of the block at height.
+func (a *tendermintAdapter) recentBlock(height uint64) *tendermintRecentBlock {
+	if a.recent == nil {
+		a.recent = make(map[uint64]*tendermintRecentBlock)
+	}
+	b, ok := a.recent[height]
+	if !ok {
+		b = &tendermintRecentBlock{emitted: make(map[string]bool)}
+		a.recent[height] = b
+	}
+	return b
+}
+
+// recoverBlocks emits the events the queries select in the blocks from the
+// next one up to height, from their results. Blocks the node no longer
+// keeps are skipped.
+func (a *tendermintAdapter) recoverBlocks(ctx context.Context, s *tendermintStream, height uint64) error {
+	if a.pastEnd(height) {
+		height = *a.endBlock
+	}
+	if a.next > height {
+		return nil
+	}
+	if s.earliest > a.next {
+		a.logger.Warnf("Blocks %d to %d are not available from %s, skipping them", a.next, s.earliest-1, s.endpoint.name())
+		a.advance(s.earliest)
+	}
+
+	if a.next <= height {
+		a.logger.Infof("Recovering the events of blocks %d to %d", a.next, height)
+	}
+	for a.next <= height {
+		if err := a.recoverBlock(ctx, s, a.next); err != nil {
+			return fmt.Errorf("failed to recover the events of block %d: %w", a.next, err)
+		}
+		a.advance(a.next + 1)
+		a.saveCheckpoint(ctx, false)
+	}
+	return nil
+}
+
+// recoverBlock emits the events the queries select in a block, matching
+// them against the queries the way nodes do.
+func (a *tendermintAdapter) recoverBlock(ctx context.Context, s *tendermintStream, height uint64) error {
+	param := strconv.FormatUint(height, 10)
+	var block tendermint.ResultBlock
+	if err := s.conn.Call(ctx, &block, "block", param); err != nil {
+		return err
+	}
+	var results tendermint.ResultBlockResults
+	if err := s.conn.Call(ctx, &results, "block_results", param); err != nil {
+		return err
+	}
+	txs := block.Block.Data.Txs
+	if len(results.TxsResults) != len(txs) {
+		return fmt.Errorf("%d results for %d transactions", len(results.TxsResults), len(txs))
+	}
+	a.recentBlock(height).time = block.Block.Header.Time
+
+	ev, err := a.newBlockEvent(&block.Block, block.BlockID.Hash, results.BlockEvents())
+	if err != nil {
+		return err
+	}
+	if a.selects(tendermint.NewBlockAttributes(int64(height), ev.block.Events)) {
+		if err := a.emit(ctx, ev); err != nil {
+			return err
+		}
+	}
+
+	for i, tx := range txs {
+		ev, err := a.newTxEvent(&tendermint.TxResultWithHeight{
+			Height: int64(height),
+			Index:  uint32(i),
+			Tx:     tx,
+			Result: results.TxsResults[i],
+		})
+		if err != nil {
+			return err
+		}
+		if !a.selects(tendermint.TxAttributes(int64(height), ev.tx.Hash, ev.tx.Events)) {
+			continue
+		}
+		if err := a.emit(ctx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selects reports whether any query selects an event with the given
+// attributes.
+func (a *tendermintAdapter) selects(attributes map[string][]string) bool {
+	for _, q := range a.queries {
+		if q.query.Matches(attributes) {
+			return true
+		}
+	}
+	return false
+}
+
+// emitNotified emits a notified event, unless its block was recovered
+// already or is past the end block.
+func (a *tendermintAdapter) emitNotified(ctx context.Context, ev *tendermintEvent) error {
+	if ev.height < a.next || a.pastEnd(ev.height) {
+		return nil
+	}
+	return a.emit(ctx, ev)
+}
+
+// emit emits an event once, even when several queries select it. Events
+// of blocks are dated with the time of the block.
+func (a *tendermintAdapter) emit(ctx context.Context, ev *tendermintEvent) error {
+	recent := a.recentBlock(ev.height)
+	id := ev.id()
+	if recent.emitted[id] {
+		return nil
+	}
+
+	if ev.block != nil && ev.block.Time != nil {
+		recent.time = *ev.block.Time
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetSource(a.source)
+	if !recent.time.IsZero() {
+		event.SetTime(recent.time)
+	}
+	// Committed blocks are final.
+	event.SetExtension(finalityExtension, string(sourcesv1alpha1.FinalityLevelFinalized))
+	number := ev.height
+	ext := chainExtensions{
+		chainID:     a.observedChainID,
+		blockNumber: &number,
+	}
+
+	var data interface{}
+	if ev.block != nil {
+		event.SetType(tendermintBlockEventType)
+		event.SetSubject(id)
+		ext.blockHash = ev.block.Hash
+		data = ev.block
+	} else {
+		event.SetType(tendermintTransactionEventType)
+		event.SetSubject(ev.tx.Hash)
+		ext.txHash = ev.tx.Hash
+		data = ev.tx
+	}
+	if err := a.deliver(ctx, event, ext, data); err != nil {
+		return fmt.Errorf("failed to emit event %s of block %d: %w", id, ev.height, err)
+	}
+	recent.emitted[id] = true
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+
+	"knative.dev/eventing-blockchain/pkg/tendermint"
+)
+
+func TestTendermintAdapterDecodesAttributes(t *testing.T) {
+	node := newFakeCometBFT(testCosmosChain, 1)
+	node.version = "0.34.29"
+	node.commit(transferTx(1, "cosmos1bob"))
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestTendermintAdapter(t, ce, wsURL(server), "tm.event='Tx' AND transfer.recipient='cosmos1bob'")
+	start := uint64(2)
+	a.startBlock = &start
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "subscribe", 2)
+		// The transaction of block 2 is recovered from its results, the
+		// one of block 3 notified.
+		node.commit(transferTx(2, "cosmos1bob"))
+		waitForEvents(t, ce, 2)
+	})
+
+	for i, e := range ce.Sent() {
+		var tx tendermintTransaction
+		if err := json.Unmarshal(e.Data(), &tx); err != nil {
+			t.Fatalf("Could not unmarshal sent data: %v", err)
+		}
+		want := transferTx(i+1, "cosmos1bob").events
+		if diff := cmp.Diff(want, tx.Events); diff != "" {
+			t.Errorf("unexpected events of transaction %d (-want, +got) = %v", i, diff)
+		}
+	}
+}
+
+func TestTendermintAdapterRecoversMissedEvents(t *testing.T) {
+	node := newFakeCometBFT(testCosmosChain, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestTendermintAdapter(t, ce, wsURL(server), "tm.event='Tx' AND transfer.recipient='cosmos1bob'")
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "subscribe", 2)
+		node.commit(transferTx(1, "cosmos1bob"))
+		waitForEvents(t, ce, 1)
+
+		// Blocks committed while disconnected are recovered once the
+		// height of the chain is notified again, without emitting the
+		// events already delivered twice.
+		node.disconnect()
+		node.commit(transferTx(2, "cosmos1alice"), transferTx(3, "cosmos1bob"))
+		node.waitForCalls(t, "subscribe", 4)
+		node.commit(transferTx(4, "cosmos1bob"))
+		waitForEvents(t, ce, 3)
+	})
+
+	want := []string{
+		tendermint.TxHash(transferTx(1, "").tx),
+		tendermint.TxHash(transferTx(3, "").tx),
+		tendermint.TxHash(transferTx(4, "").tx),
+	}
+	if diff := cmp.Diff(want, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestTendermintAdapterEmitsEventsOnce(t *testing.T) {
+	node := newFakeCometBFT(testCosmosChain, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestTendermintAdapter(t, ce, wsURL(server),
+		"tm.event='Tx' AND transfer.recipient='cosmos1bob'", "tm.event='Tx' AND transfer.amount EXISTS")
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "subscribe", 3)
+		node.commit(transferTx(1, "cosmos1bob"), transferTx(2, "cosmos1alice"))
+		waitForEvents(t, ce, 2)
+	})
+
+	want := []string{
+		tendermint.TxHash(transferTx(1, "").tx),
+		tendermint.TxHash(transferTx(2, "").tx),
+	}
+	if diff := cmp.Diff(want, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestTendermintAdapterSkipsPrunedBlocks(t *testing.T) {
+	node := newFakeCometBFT(testCosmosChain, 4)
+	node.earliest = 3
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestTendermintAdapter(t, ce, wsURL(server))
+	start := uint64(1)
+	a.startBlock = &start
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "subscribe", 2)
+		node.commit()
+		waitForEvents(t, ce, 3)
+	})
+
+	if diff := cmp.Diff([]string{"3", "4", "5"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,497 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	"knative.dev/pkg/logging"
+	pkgtesting "knative.dev/pkg/reconciler/testing"
+
+	"knative.dev/eventing-blockchain/pkg/tendermint"
+)
+
+const testCosmosChain = "testchain-1"
+
+// fakeCometBFT is an in-memory CometBFT node serving its WebSocket
+// JSON-RPC endpoint.
+type fakeCometBFT struct {
+	mu      sync.Mutex
+	chainID string
+	// version is the version of CometBFT the node reports. Nodes of
+	// Tendermint 0.34 encode the attributes of events in base64.
+	version string
+	blocks  []*fakeCometBFTBlock
+	// earliest is the height of the oldest block the node keeps, when not
+	// zero.
+	earliest int64
+	// subscriptions are the queries subscribed to per connection.
+	subscriptions map[*fakeConn][]fakeCometBFTSubscription
+
+	callCounter
+}
+
+type fakeCometBFTSubscription struct {
+	id    uint64
+	query *tendermint.Query
+}
+
+type fakeCometBFTBlock struct {
+	header  tendermint.Header
+	hash    string
+	txs     [][]byte
+	results []tendermint.TxResult
+	events  []tendermint.Event
+}
+
+// fakeCometBFTTx is a transaction to commit, with its events.
+type fakeCometBFTTx struct {
+	tx     []byte
+	code   uint32
+	events []tendermint.Event
+}
+
+func newFakeCometBFT(chainID string, blocks int) *fakeCometBFT {
+	n := &fakeCometBFT{
+		chainID:       chainID,
+		version:       "0.38.12",
+		subscriptions: make(map[*fakeConn][]fakeCometBFTSubscription),
+	}
+	for i := 0; i < blocks; i++ {
+		n.commit()
+	}
+	return n
+}
+
+// transferTx returns the n-th transaction, transferring coins to recipient.
+func transferTx(n int, recipient string) fakeCometBFTTx {
+	return fakeCometBFTTx{
+		tx: []byte(fmt.Sprintf("tx-%d", n)),
+		events: []tendermint.Event{{
+			Type: "transfer",
+			Attributes: []tendermint.EventAttribute{
+				{Key: "recipient", Value: recipient, Index: true},
+				{Key: "amount", Value: fmt.Sprintf("%duatom", n), Index: true},
+			},
+		}},
+	}
+}
+
+// commit appends a new block holding txs, and notifies the subscribers of
+// its events in the order nodes do.
+func (n *fakeCometBFT) commit(txs ...fakeCometBFTTx) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	height := int64(len(n.blocks) + 1)
+	b := &fakeCometBFTBlock{
+		header: tendermint.Header{
+			ChainID:         n.chainID,
+			Height:          height,
+			Time:            time.Unix(1700000000+6*height, 0).UTC(),
+			ProposerAddress: "7B3D",
+		},
+		hash: fmt.Sprintf("%064X", 0xb10c000+height),
+		events: []tendermint.Event{{
+			Type:       "mint",
+			Attributes: []tendermint.EventAttribute{{Key: "amount", Value: "100uatom", Index: true}},
+		}},
+	}
+	for _, tx := range txs {
+		b.txs = append(b.txs, tx.tx)
+		b.results = append(b.results, tendermint.TxResult{
+			Code:      tx.code,
+			GasWanted: 200000,
+			GasUsed:   100000,
+			Events:    tx.events,
+		})
+	}
+	n.blocks = append(n.blocks, b)
+
+	for c, subs := range n.subscriptions {
+		for _, sub := range subs {
+			n.notifyBlock(c, sub, b)
+		}
+	}
+}
+
+// notifyBlock notifies a subscription of the events of a block it
+// selects: the block, its header, then its transactions.
+func (n *fakeCometBFT) notifyBlock(c *fakeConn, sub fakeCometBFTSubscription, b *fakeCometBFTBlock) {
+	height := b.header.Height
+	if sub.query.Matches(tendermint.NewBlockAttributes(height, b.events)) {
+		value := map[string]interface{}{
+			"block": n.block(b),
+		}
+		if n.encoded() {
+			value["result_end_block"] = map[string]interface{}{"events": n.encode(b.events)}
+		} else {
+			value["block_id"] = tendermint.BlockID{Hash: b.hash}
+			value["result_finalize_block"] = map[string]interface{}{"events": b.events}
+		}
+		n.notify(c, sub, tendermint.EventDataTypeNewBlock, value)
+	}
+	if sub.query.Matches(map[string][]string{tendermint.EventTypeKey: {tendermint.EventNewBlockHeader}}) {
+		n.notify(c, sub, tendermint.EventDataTypeNewBlockHeader, tendermint.EventDataNewBlockHeader{Header: b.header})
+	}
+	for i, tx := range b.txs {
+		result := b.results[i]
+		if !sub.query.Matches(tendermint.TxAttributes(height, tendermint.TxHash(tx), result.Events)) {
+			continue
+		}
+		result.Events = n.encode(result.Events)
+		n.notify(c, sub, tendermint.EventDataTypeTx, tendermint.EventDataTx{
+			TxResult: tendermint.TxResultWithHeight{Height: height, Index: uint32(i), Tx: tx, Result: result},
+		})
+	}
+}
+
+func (n *fakeCometBFT) notify(c *fakeConn, sub fakeCometBFTSubscription, dataType string, value interface{}) {
+	var id interface{} = sub.id
+	if n.encoded() {
+		id = fmt.Sprintf("%d#event", sub.id)
+	}
+	valueJSON, _ := json.Marshal(value)
+	c.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result": tendermint.ResultEvent{
+			Query: sub.query.String(),
+			Data:  tendermint.EventData{Type: dataType, Value: valueJSON},
+		},
+	})
+}
+
+func (n *fakeCometBFT) encoded() bool {
+	return strings.HasPrefix(n.version, "0.34.")
+}
+
+// encode returns events as the node sends them.
+func (n *fakeCometBFT) encode(events []tendermint.Event) []tendermint.Event {
+	if !n.encoded() {
+		return events
+	}
+	encoded := make([]tendermint.Event, len(events))
+	for i, ev := range events {
+		encoded[i] = tendermint.Event{Type: ev.Type}
+		for _, attr := range ev.Attributes {
+			encoded[i].Attributes = append(encoded[i].Attributes, tendermint.EventAttribute{
+				Key:   base64.StdEncoding.EncodeToString([]byte(attr.Key)),
+				Value: base64.StdEncoding.EncodeToString([]byte(attr.Value)),
+				Index: attr.Index,
+			})
+		}
+	}
+	return encoded
+}
+
+func (n *fakeCometBFT) block(b *fakeCometBFTBlock) tendermint.Block {
+	return tendermint.Block{Header: b.header, Data: tendermint.BlockData{Txs: b.txs}}
+}
+
+// disconnect closes all WebSocket connections.
+func (n *fakeCometBFT) disconnect() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for c := range n.subscriptions {
+		c.conn.Close()
+		delete(n.subscriptions, c)
+	}
+}
+
+func (n *fakeCometBFT) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &fakeConn{conn: conn}
+	n.mu.Lock()
+	n.subscriptions[c] = nil
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.subscriptions, c)
+		n.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var req fakeRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		n.handle(&req, c)
+	}
+}
+
+// handle writes the response to a request.
+func (n *fakeCometBFT) handle(req *fakeRequest, c *fakeConn) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.called(req.Method)
+
+	var (
+		result  interface{}
+		message string
+	)
+	var param string
+	if len(req.Params) > 0 {
+		json.Unmarshal(req.Params[0], &param)
+	}
+	switch req.Method {
+	case "status":
+		result = tendermint.Status{
+			NodeInfo: tendermint.NodeInfo{Network: n.chainID, Version: n.version},
+			SyncInfo: tendermint.SyncInfo{LatestBlockHeight: int64(len(n.blocks)), EarliestBlockHeight: n.earliest},
+		}
+	case "subscribe":
+		q, err := tendermint.ParseQuery(param)
+		if err != nil {
+			message = err.Error()
+			break
+		}
+		n.subscriptions[c] = append(n.subscriptions[c], fakeCometBFTSubscription{id: req.ID, query: q})
+		result = struct{}{}
+	case "block", "block_results":
+		var height int64
+		fmt.Sscan(param, &height)
+		if height < 1 || height > int64(len(n.blocks)) || height < n.earliest {
+			message = fmt.Sprintf("height %d is not available", height)
+			break
+		}
+		b := n.blocks[height-1]
+		if req.Method == "block" {
+			result = tendermint.ResultBlock{BlockID: tendermint.BlockID{Hash: b.hash}, Block: n.block(b)}
+			break
+		}
+		results := tendermint.ResultBlockResults{Height: height}
+		for _, r := range b.results {
+			r.Events = n.encode(r.Events)
+			results.TxsResults = append(results.TxsResults, r)
+		}
+		if n.encoded() {
+			results.EndBlockEvents = n.encode(b.events)
+		} else {
+			results.FinalizeBlockEvents = b.events
+		}
+		result = results
+	default:
+		message = "method not found"
+	}
+
+	resp := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+	}
+	if message != "" {
+		resp["error"] = map[string]interface{}{"code": -32603, "message": message}
+	} else {
+		resp["result"] = result
+	}
+	c.write(resp)
+}
+
+// wsURL returns the WebSocket URL of a test server.
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/websocket"
+}
+
+func newTestTendermintAdapter(t *testing.T, ce *adaptertest.TestCloudEventsClient, rpcURL string, queries ...string) *tendermintAdapter {
+	env := tendermintEnvConfig{
+		chainEnvConfig: chainEnvConfig{
+			EnvConfig: adapter.EnvConfig{
+				Namespace: "default",
+			},
+			EnvRPCURL: rpcURL,
+		},
+	}
+	if len(queries) > 0 {
+		queriesJSON, _ := json.Marshal(queries)
+		env.EnvQueries = string(queriesJSON)
+	}
+	ctx, _ := pkgtesting.SetupFakeContext(t)
+	logger := zap.NewExample().Sugar()
+	ctx = logging.WithLogger(ctx, logger)
+
+	a := NewTendermintAdapter(ctx, &env, ce).(*tendermintAdapter)
+	a.minReconnectDelay = 10 * time.Millisecond
+	return a
+}
+
+func TestTendermintAdapterEmitsEvents(t *testing.T) {
+	node := newFakeCometBFT(testCosmosChain, 2)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestTendermintAdapter(t, ce, wsURL(server),
+		"tm.event='NewBlock'", "tm.event='Tx' AND transfer.recipient='cosmos1bob'")
+
+	bob := transferTx(1, "cosmos1bob")
+	runAdapter(t, a, func() {
+		// Blocks committed before the adapter started are not emitted.
+		node.waitForCalls(t, "subscribe", 3)
+		node.commit(transferTx(0, "cosmos1alice"), bob)
+		waitForEvents(t, ce, 2)
+	})
+
+	// Events notified over different subscriptions may be emitted in any
+	// order.
+	bobHash := tendermint.TxHash(bob.tx)
+	sent := ce.Sent()
+	sort.Slice(sent, func(i, j int) bool {
+		return sent[i].Type() < sent[j].Type()
+	})
+	blockTime := time.Unix(1700000018, 0).UTC()
+	for i, want := range []struct {
+		eventType  string
+		id         string
+		extensions map[string]interface{}
+	}{{
+		eventType: tendermintBlockEventType,
+		id:        "3",
+		extensions: map[string]interface{}{
+			finalityExtension:    "finalized",
+			chainIDExtension:     testCosmosChain,
+			blockNumberExtension: int32(3),
+			blockHashExtension:   fmt.Sprintf("%064X", 0xb10c003),
+		},
+	}, {
+		eventType: tendermintTransactionEventType,
+		id:        bobHash,
+		extensions: map[string]interface{}{
+			finalityExtension:    "finalized",
+			chainIDExtension:     testCosmosChain,
+			blockNumberExtension: int32(3),
+			txHashExtension:      bobHash,
+		},
+	}} {
+		e := sent[i]
+		if e.Type() != want.eventType || e.ID() != want.id {
+			t.Errorf("event %d = %s %s, want %s %s", i, e.Type(), e.ID(), want.eventType, want.id)
+		}
+		if e.Source() != "cosmos:testchain-1" {
+			t.Errorf("event source = %q, want cosmos:testchain-1", e.Source())
+		}
+		if !e.Time().Equal(blockTime) {
+			t.Errorf("event time = %s, want %s", e.Time(), blockTime)
+		}
+		if diff := cmp.Diff(want.extensions, e.Extensions()); diff != "" {
+			t.Errorf("unexpected extensions of event %d (-want, +got) = %v", i, diff)
+		}
+	}
+
+	var block tendermintBlock
+	if err := json.Unmarshal(sent[0].Data(), &block); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantBlock := tendermintBlock{
+		ChainID:         testCosmosChain,
+		Height:          3,
+		Hash:            fmt.Sprintf("%064X", 0xb10c003),
+		Time:            &blockTime,
+		ProposerAddress: "7B3D",
+		TxHashes:        []string{tendermint.TxHash(transferTx(0, "").tx), bobHash},
+		Events: []tendermint.Event{{
+			Type:       "mint",
+			Attributes: []tendermint.EventAttribute{{Key: "amount", Value: "100uatom", Index: true}},
+		}},
+	}
+	if diff := cmp.Diff(wantBlock, block); diff != "" {
+		t.Errorf("unexpected block (-want, +got) = %v", diff)
+	}
+
+	var tx tendermintTransaction
+	if err := json.Unmarshal(sent[1].Data(), &tx); err != nil {
+		t.Fatalf("Could not unmarshal sent data: %v", err)
+	}
+	wantTx := tendermintTransaction{
+		ChainID:   testCosmosChain,
+		Height:    3,
+		Index:     1,
+		Hash:      bobHash,
+		GasWanted: 200000,
+		GasUsed:   100000,
+		Events:    bob.events,
+	}
+	if diff := cmp.Diff(wantTx, tx); diff != "" {
+		t.Errorf("unexpected transaction (-want, +got) = %v", diff)
+	}
+}
+
+func TestTendermintAdapterBackfillsFromStartBlock(t *testing.T) {
+	node := newFakeCometBFT(testCosmosChain, 5)
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestTendermintAdapter(t, ce, wsURL(server))
+	start, end := uint64(2), uint64(3)
+	a.startBlock, a.endBlock = &start, &end
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "subscribe", 2)
+		// Blocks are recovered once the height of the chain is notified.
+		node.commit()
+		waitForEvents(t, ce, 2)
+	})
+
+	if !a.reachedEnd() {
+		t.Error("reachedEnd() = false, want true past the end block")
+	}
+
+	if diff := cmp.Diff([]string{"2", "3"}, sentSubjects(ce)); diff != "" {
+		t.Errorf("unexpected subjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestTendermintAdapterFailsOver(t *testing.T) {
+	node := newFakeCometBFT(testCosmosChain, 1)
+	server := httptest.NewServer(node)
+	defer server.Close()
+	other := httptest.NewServer(newFakeCometBFT("otherchain-1", 1))
+	defer other.Close()
+
+	ce := adaptertest.NewTestClient()
+	a := newTestTendermintAdapter(t, ce, "")
+	a.chainID = testCosmosChain
+	a.endpointsJSON = fmt.Sprintf(`[{"url": %q}, {"url": %q, "priority": 1}]`, wsURL(other), wsURL(server))
+
+	runAdapter(t, a, func() {
+		node.waitForCalls(t, "subscribe", 2)
+		node.commit()
+		waitForEvents(t, ce, 1)
+	})
+
+	if want := "ws://" + strings.TrimPrefix(server.URL, "http://"); a.active != want {
+		t.Errorf("active node = %q, want %q", a.active, want)
+	}
+}
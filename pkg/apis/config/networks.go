@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// NetworksConfigName is the name of the ConfigMap holding the
+	// per-network defaults of BlockchainSources.
+	NetworksConfigName = "config-blockchain-networks"
+)
+
+// NetworkProfile holds the defaults of the sources reading a network, used
+// for the fields their spec leaves unset.
+type NetworkProfile struct {
+	// Finality is the finality level of the blocks whose events are
+	// emitted.
+	Finality string `json:"finality,omitempty"`
+
+	// Confirmations is the confirmation depth of the confirmed finality
+	// level.
+	Confirmations *int64 `json:"confirmations,omitempty"`
+
+	// PollInterval is how often nodes are polled for new blocks.
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+
+	// MaxLogRange is the largest number of blocks whose logs are requested
+	// at once.
+	MaxLogRange *int64 `json:"maxLogRange,omitempty"`
+}
+
+// Networks holds the profiles of the known networks, keyed by NetworkKey.
+type Networks struct {
+	Profiles map[string]*NetworkProfile `json:"profiles,omitempty"`
+}
+
+// NetworkKey returns the key of the profile of a network, which is also its
+// key in the ConfigMap, e.g. "evm.1" for Ethereum mainnet.
+func NetworkKey(family, chainID string) string {
+	return family + "." + chainID
+}
+
+// Profile returns the profile of a network, or nil if it is not known.
+func (n *Networks) Profile(family, chainID string) *NetworkProfile {
+	if n == nil || chainID == "" {
+		return nil
+	}
+	return n.Profiles[NetworkKey(family, chainID)]
+}
+
+// NewNetworksConfigFromMap creates a Networks from the supplied map, whose
+// entries add profiles to the built-in ones or replace them.
+func NewNetworksConfigFromMap(data map[string]string) (*Networks, error) {
+	nc := &Networks{Profiles: builtinProfiles()}
+
+	for key, value := range data {
+		// Keys starting with an underscore, such as _example, hold
+		// documentation.
+		if strings.HasPrefix(key, "_") {
+			continue
+		}
+		if i := strings.Index(key, "."); i <= 0 || i == len(key)-1 {
+			return nil, fmt.Errorf("invalid network %q, expected <family>.<chain ID>", key)
+		}
+		profile := &NetworkProfile{}
+		if err := parseEntry(value, profile); err != nil {
+			return nil, fmt.Errorf("failed to parse the profile of network %q: %w", key, err)
+		}
+		nc.Profiles[key] = profile
+	}
+	return nc, nil
+}
+
+// NewNetworksConfigFromConfigMap creates a Networks from the supplied
+// ConfigMap.
+func NewNetworksConfigFromConfigMap(config *corev1.ConfigMap) (*Networks, error) {
+	return NewNetworksConfigFromMap(config.Data)
+}
+
+func parseEntry(entry string, out interface{}) error {
+	j, err := yaml.YAMLToJSON([]byte(entry))
+	if err != nil {
+		return fmt.Errorf("ConfigMap's value could not be converted to JSON: %w : %v", err, entry)
+	}
+	dec := json.NewDecoder(strings.NewReader(string(j)))
+	dec.DisallowUnknownFields()
+	return dec.Decode(out)
+}
+
+// builtinProfiles returns the profiles of well-known networks.
+func builtinProfiles() map[string]*NetworkProfile {
+	return map[string]*NetworkProfile{
+		// Ethereum mainnet and the Sepolia testnet have 12s slots.
+		NetworkKey("evm", "1"):        newProfile("confirmed", 12, 12*time.Second, 2000),
+		NetworkKey("evm", "11155111"): newProfile("confirmed", 6, 12*time.Second, 2000),
+		// Polygon PoS has 2s blocks and reorganizations dozens of blocks
+		// deep, and providers cap log queries to 1000 blocks.
+		NetworkKey("evm", "137"): newProfile("confirmed", 128, 2*time.Second, 1000),
+		// Arbitrum One produces a block every 250ms.
+		NetworkKey("evm", "42161"): newProfile("confirmed", 20, time.Second, 10000),
+		// Bitcoin has 10m blocks, whose transactions are traditionally
+		// considered settled after 6 confirmations. Blocks are mined on
+		// demand on regtest.
+		NetworkKey("bitcoin", "main"):    newProfile("confirmed", 6, time.Minute, 0),
+		NetworkKey("bitcoin", "regtest"): newProfile("latest", 0, time.Second, 0),
+	}
+}
+
+func newProfile(finality string, confirmations int64, pollInterval time.Duration, maxLogRange int64) *NetworkProfile {
+	p := &NetworkProfile{
+		Finality:     finality,
+		PollInterval: &metav1.Duration{Duration: pollInterval},
+	}
+	if confirmations > 0 {
+		p.Confirmations = &confirmations
+	}
+	if maxLogRange > 0 {
+		p.MaxLogRange = &maxLogRange
+	}
+	return p
+}
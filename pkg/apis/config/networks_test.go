@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewNetworksConfigFromMap(t *testing.T) {
+	nc, err := NewNetworksConfigFromMap(map[string]string{
+		"_example": "evm.1: |\n  confirmations: 64",
+		"evm.1": `
+finality: finalized
+pollInterval: 6s
+`,
+		"tendermint.cosmoshub-4": `
+finality: latest
+pollInterval: 3s
+`,
+	})
+	if err != nil {
+		t.Fatalf("NewNetworksConfigFromMap() = %v", err)
+	}
+
+	want := &NetworkProfile{
+		Finality:     "finalized",
+		PollInterval: &metav1.Duration{Duration: 6 * time.Second},
+	}
+	if diff := cmp.Diff(want, nc.Profile("evm", "1")); diff != "" {
+		t.Errorf("overridden profile (-want, +got) = %v", diff)
+	}
+
+	want = &NetworkProfile{
+		Finality:     "latest",
+		PollInterval: &metav1.Duration{Duration: 3 * time.Second},
+	}
+	if diff := cmp.Diff(want, nc.Profile("tendermint", "cosmoshub-4")); diff != "" {
+		t.Errorf("added profile (-want, +got) = %v", diff)
+	}
+
+	if diff := cmp.Diff(builtinProfiles()[NetworkKey("evm", "137")], nc.Profile("evm", "137")); diff != "" {
+		t.Errorf("built-in profile (-want, +got) = %v", diff)
+	}
+
+	if got := nc.Profile("evm", "31337"); got != nil {
+		t.Errorf("Profile() of an unknown network = %v, want nil", got)
+	}
+	if got := nc.Profile("evm", ""); got != nil {
+		t.Errorf("Profile() without chain ID = %v, want nil", got)
+	}
+}
+
+func TestNewNetworksConfigFromMapErrors(t *testing.T) {
+	for name, data := range map[string]map[string]string{
+		"key without chain ID": {"evm": "finality: latest"},
+		"key without family":   {".1": "finality: latest"},
+		"invalid yaml":         {"evm.1": "finality: [latest"},
+		"unknown field":        {"evm.1": "confirmationDepth: 12"},
+		"invalid duration":     {"evm.1": "pollInterval: often"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewNetworksConfigFromMap(data); err == nil {
+				t.Error("NewNetworksConfigFromMap() = nil, want an error")
+			}
+		})
+	}
+}
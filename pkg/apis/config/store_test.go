@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/configmap/informer"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/system"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+func TestStoreLoadWithContext(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+
+	networksConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NetworksConfigName,
+			Namespace: "knative-eventing",
+		},
+		Data: map[string]string{
+			"evm.31337": "finality: latest",
+		},
+	}
+	store.OnConfigChanged(networksConfig)
+
+	config := FromContextOrDefaults(store.ToContext(context.Background()))
+
+	expected, _ := NewNetworksConfigFromConfigMap(networksConfig)
+	if diff := cmp.Diff(expected, config.Networks); diff != "" {
+		t.Error("Unexpected networks config (-want, +got):", diff)
+	}
+}
+
+func TestStoreLoadWithContextOrDefaults(t *testing.T) {
+	config := FromContextOrDefaults(context.Background())
+
+	expected := &Networks{Profiles: builtinProfiles()}
+	if diff := cmp.Diff(expected, config.Networks); diff != "" {
+		t.Error("Unexpected networks config (-want, +got):", diff)
+	}
+}
+
+func TestStoreWatchConfigsWithoutConfigMap(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+
+	watcher := informer.NewInformedWatcher(fakekubeclient.NewSimpleClientset(), system.Namespace())
+	store.WatchConfigs(watcher)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := watcher.Start(stopCh); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	expected := &Networks{Profiles: builtinProfiles()}
+	if diff := cmp.Diff(expected, store.Load().Networks); diff != "" {
+		t.Error("Unexpected networks config (-want, +got):", diff)
+	}
+}
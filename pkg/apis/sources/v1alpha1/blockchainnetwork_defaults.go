@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"knative.dev/eventing-blockchain/pkg/apis/config"
+)
+
+func (n *BlockchainNetwork) SetDefaults(ctx context.Context) {
+	n.Spec.SetDefaults(ctx)
+}
+
+func (ns *BlockchainNetworkSpec) SetDefaults(ctx context.Context) {
+	if ns.Family == "" {
+		ns.Family = ChainFamilyEVM
+	}
+
+	profile := config.FromContextOrDefaults(ctx).Networks.Profile(string(ns.Family), ns.ChainID)
+	if profile != nil {
+		ns.applyProfile(profile)
+	}
+
+	if ns.Finality == nil {
+		ns.Finality = &Finality{}
+	}
+	ns.Finality.SetDefaults(ctx)
+}
+
+// applyProfile fills the fields left unset with the defaults of the network.
+func (ns *BlockchainNetworkSpec) applyProfile(profile *config.NetworkProfile) {
+	if ns.PollInterval == nil && profile.PollInterval != nil {
+		ns.PollInterval = profile.PollInterval.DeepCopy()
+	}
+	if ns.MaxLogRange == nil && profile.MaxLogRange != nil {
+		maxLogRange := *profile.MaxLogRange
+		ns.MaxLogRange = &maxLogRange
+	}
+
+	if ns.Finality == nil {
+		ns.Finality = &Finality{}
+	}
+	ns.Finality.applyProfile(profile)
+}
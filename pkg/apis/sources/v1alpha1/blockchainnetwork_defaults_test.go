@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/eventing-blockchain/pkg/apis/config"
+)
+
+func TestBlockchainNetworkDefaults(t *testing.T) {
+	networks, err := config.NewNetworksConfigFromMap(map[string]string{
+		"evm.1": "finality: confirmed\nconfirmations: 64\npollInterval: 12s",
+	})
+	if err != nil {
+		t.Fatalf("NewNetworksConfigFromMap() = %v", err)
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{Networks: networks})
+
+	testCases := map[string]struct {
+		initial  BlockchainNetworkSpec
+		expected BlockchainNetworkSpec
+	}{
+		"no profile": {
+			initial: BlockchainNetworkSpec{ChainID: "31337"},
+			expected: BlockchainNetworkSpec{
+				Family:  ChainFamilyEVM,
+				ChainID: "31337",
+				Finality: &Finality{
+					Level: FinalityLevelLatest,
+				},
+			},
+		},
+		"profile": {
+			initial: BlockchainNetworkSpec{ChainID: "1"},
+			expected: BlockchainNetworkSpec{
+				Family:       ChainFamilyEVM,
+				ChainID:      "1",
+				PollInterval: &metav1.Duration{Duration: 12 * time.Second},
+				Finality: &Finality{
+					Level:         FinalityLevelConfirmed,
+					Confirmations: ptrInt64(64),
+				},
+			},
+		},
+		"profile overridden": {
+			initial: BlockchainNetworkSpec{
+				ChainID:      "1",
+				PollInterval: &metav1.Duration{Duration: time.Second},
+				Finality: &Finality{
+					Level: FinalityLevelFinalized,
+				},
+			},
+			expected: BlockchainNetworkSpec{
+				Family:       ChainFamilyEVM,
+				ChainID:      "1",
+				PollInterval: &metav1.Duration{Duration: time.Second},
+				Finality: &Finality{
+					Level: FinalityLevelFinalized,
+				},
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got := BlockchainNetwork{Spec: tc.initial}
+			got.SetDefaults(ctx)
+			if diff := cmp.Diff(tc.expected, got.Spec); diff != "" {
+				t.Fatalf("Unexpected defaults (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/webhook/resourcesemantics"
+)
+
+// Check that BlockchainNetwork can be validated and can be defaulted.
+var _ runtime.Object = (*BlockchainNetwork)(nil)
+
+var _ resourcesemantics.GenericCRD = (*BlockchainNetwork)(nil)
+
+// Check that BlockchainNetwork can be converted by the conversion webhook.
+var _ apis.Convertible = (*BlockchainNetwork)(nil)
+
+// Check that the type conforms to the duck Knative Resource shape.
+var _ duckv1.KRShaped = (*BlockchainNetwork)(nil)
+
+// BlockchainNetworkSpec defines a chain and the nodes serving it, shared by
+// the BlockchainSources referencing the network.
+type BlockchainNetworkSpec struct {
+	// Family is the family of the chain, which determines the protocol
+	// spoken with its nodes and the events it produces. Defaults to evm.
+	// +optional
+	// +kubebuilder:validation:Enum=evm,bitcoin,fabric,tendermint,solana,beacon
+	Family ChainFamily `json:"family,omitempty"`
+
+	// ChainID identifies the chain, such as the EIP-155 chain ID of an EVM
+	// chain ("1" for Ethereum mainnet). Endpoints serving another chain are
+	// not used.
+	ChainID string `json:"chainID"`
+
+	// Endpoints are the JSON-RPC endpoints of the nodes of the chain. A
+	// source uses the HTTP endpoints when polling and the WebSocket ones
+	// when streaming. Their credentials are Secrets of the namespace of the
+	// controller, copied to the namespace of the sources using them.
+	Endpoints []RPCEndpoint `json:"endpoints"`
+
+	// AllowedNamespaces are the namespaces whose sources may use the network
+	// when its endpoints have credentials, which are copied to the namespace
+	// of the sources. Sources of other namespaces are rejected. Networks
+	// without credentials can be used from any namespace.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// PollInterval is how often nodes are polled for new blocks in polling
+	// mode, unless a source sets its own. Defaults to the interval of the
+	// network profile, if any.
+	// +optional
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+
+	// MaxLogRange is the largest number of blocks whose contract logs are
+	// requested at once, unless a source sets its own. Defaults to the range
+	// of the network profile, if any.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxLogRange *int64 `json:"maxLogRange,omitempty"`
+
+	// Finality is how final a block must be before the events it produces
+	// are emitted, unless a source sets its own. Defaults to the finality
+	// of the network profile, if any.
+	// +optional
+	Finality *Finality `json:"finality,omitempty"`
+}
+
+const (
+	// BlockchainNetworkConditionReady has status True when the endpoints of
+	// the BlockchainNetwork can be used by sources.
+	BlockchainNetworkConditionReady = apis.ConditionReady
+
+	// BlockchainNetworkConditionEndpointsReachable has status True when at
+	// least one endpoint of the BlockchainNetwork answers.
+	BlockchainNetworkConditionEndpointsReachable apis.ConditionType = "EndpointsReachable"
+
+	// BlockchainNetworkConditionChainIDVerified has status True when every
+	// endpoint answering serves the chain ID given in the spec.
+	BlockchainNetworkConditionChainIDVerified apis.ConditionType = "ChainIDVerified"
+)
+
+var BlockchainNetworkCondSet = apis.NewLivingConditionSet(
+	BlockchainNetworkConditionEndpointsReachable,
+	BlockchainNetworkConditionChainIDVerified)
+
+// BlockchainNetworkStatus defines the observed state of BlockchainNetwork
+type BlockchainNetworkStatus struct {
+	// inherits duck/v1 Status, which currently provides:
+	// * ObservedGeneration - the 'Generation' of the Service that was last
+	//   processed by the controller.
+	// * Conditions - the latest available observations of a resource's current
+	//   state.
+	duckv1.Status `json:",inline"`
+
+	// Endpoints reports the health of each endpoint, in the order of the
+	// spec, as of the last probe.
+	// +optional
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+}
+
+// EndpointStatus is the health of an RPC endpoint of a network.
+type EndpointStatus struct {
+	// Name identifies the endpoint by the scheme and host of its URL,
+	// leaving out the path and query which often hold an API key.
+	Name string `json:"name"`
+
+	// Healthy is whether the endpoint answered and serves the chain of the
+	// network.
+	Healthy bool `json:"healthy"`
+
+	// ChainID is the chain ID the endpoint reported.
+	// +optional
+	ChainID string `json:"chainID,omitempty"`
+
+	// HeadBlock is the number of the newest block the endpoint reported.
+	// +optional
+	HeadBlock int64 `json:"headBlock,omitempty"`
+
+	// Message explains why the endpoint is not healthy.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+func (*BlockchainNetwork) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("BlockchainNetwork")
+}
+
+// GetConditionSet retrieves the condition set for this resource. Implements the KRShaped interface.
+func (*BlockchainNetwork) GetConditionSet() apis.ConditionSet {
+	return BlockchainNetworkCondSet
+}
+
+// GetStatus retrieves the duck status for this resource. Implements the KRShaped interface.
+func (n *BlockchainNetwork) GetStatus() *duckv1.Status {
+	return &n.Status.Status
+}
+
+// GetCondition returns the condition currently associated with the given type, or nil.
+func (s *BlockchainNetworkStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return BlockchainNetworkCondSet.Manage(s).GetCondition(t)
+}
+
+// IsReady returns true if the resource is ready overall.
+func (s *BlockchainNetworkStatus) IsReady() bool {
+	return BlockchainNetworkCondSet.Manage(s).IsHappy()
+}
+
+// InitializeConditions sets relevant unset conditions to Unknown state.
+func (s *BlockchainNetworkStatus) InitializeConditions() {
+	BlockchainNetworkCondSet.Manage(s).InitializeConditions()
+}
+
+// MarkEndpointsReachable sets the condition that an endpoint answers.
+func (s *BlockchainNetworkStatus) MarkEndpointsReachable() {
+	BlockchainNetworkCondSet.Manage(s).MarkTrue(BlockchainNetworkConditionEndpointsReachable)
+}
+
+// MarkEndpointsUnreachable sets the condition that no endpoint answers.
+func (s *BlockchainNetworkStatus) MarkEndpointsUnreachable(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainNetworkCondSet.Manage(s).MarkFalse(BlockchainNetworkConditionEndpointsReachable, reason, messageFormat, messageA...)
+}
+
+// MarkChainIDVerified sets the condition that the endpoints serve the chain
+// of the network.
+func (s *BlockchainNetworkStatus) MarkChainIDVerified() {
+	BlockchainNetworkCondSet.Manage(s).MarkTrue(BlockchainNetworkConditionChainIDVerified)
+}
+
+// MarkChainIDMismatch sets the condition that an endpoint serves another
+// chain than the one of the network.
+func (s *BlockchainNetworkStatus) MarkChainIDMismatch(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainNetworkCondSet.Manage(s).MarkFalse(BlockchainNetworkConditionChainIDVerified, reason, messageFormat, messageA...)
+}
+
+// MarkChainIDVerificationUnknown sets the condition that the chain served by
+// the endpoints is not known.
+func (s *BlockchainNetworkStatus) MarkChainIDVerificationUnknown(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainNetworkCondSet.Manage(s).MarkUnknown(BlockchainNetworkConditionChainIDVerified, reason, messageFormat, messageA...)
+}
+
+// MarkNotProbed sets the conditions of the endpoints of a network that
+// are not probed, which do not keep the network from being ready.
+func (s *BlockchainNetworkStatus) MarkNotProbed(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainNetworkCondSet.Manage(s).MarkTrueWithReason(BlockchainNetworkConditionEndpointsReachable, reason, messageFormat, messageA...)
+	BlockchainNetworkCondSet.Manage(s).MarkTrueWithReason(BlockchainNetworkConditionChainIDVerified, reason, messageFormat, messageA...)
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +genreconciler
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BlockchainNetwork is the Schema for the BlockchainNetworks API, a chain
+// and the nodes serving it shared by BlockchainSources of any namespace.
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:categories=all,knative,eventing,sources
+// +kubebuilder:printcolumn:name="Family",type="string",JSONPath=".spec.family"
+// +kubebuilder:printcolumn:name="Chain ID",type="string",JSONPath=".spec.chainID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].reason"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type BlockchainNetwork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BlockchainNetworkSpec   `json:"spec,omitempty"`
+	Status BlockchainNetworkStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BlockchainNetworkList contains a list of BlockchainNetwork
+type BlockchainNetworkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BlockchainNetwork `json:"items"`
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/apis/duck"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+var _ = duck.VerifyType(&BlockchainNetwork{}, &duckv1.Conditions{})
+
+// readyNetworkStatus returns a network status with every condition Ready
+// depends on True.
+func readyNetworkStatus() *BlockchainNetworkStatus {
+	s := &BlockchainNetworkStatus{}
+	s.InitializeConditions()
+	s.MarkEndpointsReachable()
+	s.MarkChainIDVerified()
+	return s
+}
+
+func TestBlockchainNetworkGetConditionSet(t *testing.T) {
+	r := &BlockchainNetwork{}
+
+	if got, want := r.GetConditionSet().GetTopLevelConditionType(), apis.ConditionReady; got != want {
+		t.Errorf("GetTopLevelCondition=%v, want=%v", got, want)
+	}
+}
+
+func TestBlockchainNetworkStatusIsReady(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *BlockchainNetworkStatus
+		want bool
+	}{{
+		name: "uninitialized",
+		s:    &BlockchainNetworkStatus{},
+		want: false,
+	}, {
+		name: "initialized",
+		s: func() *BlockchainNetworkStatus {
+			s := &BlockchainNetworkStatus{}
+			s.InitializeConditions()
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "reachable",
+		s: func() *BlockchainNetworkStatus {
+			s := &BlockchainNetworkStatus{}
+			s.InitializeConditions()
+			s.MarkEndpointsReachable()
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "ready",
+		s:    readyNetworkStatus(),
+		want: true,
+	}, {
+		name: "ready, then unreachable",
+		s: func() *BlockchainNetworkStatus {
+			s := readyNetworkStatus()
+			s.MarkEndpointsUnreachable("EndpointsUnreachable", "")
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "ready, then chain ID mismatch",
+		s: func() *BlockchainNetworkStatus {
+			s := readyNetworkStatus()
+			s.MarkChainIDMismatch("ChainIDMismatch", "")
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "not probed",
+		s: func() *BlockchainNetworkStatus {
+			s := &BlockchainNetworkStatus{}
+			s.InitializeConditions()
+			s.MarkNotProbed("NotProbed", "")
+			return s
+		}(),
+		want: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.s.IsReady(); got != test.want {
+				t.Errorf("IsReady() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBlockchainSourcePropagateNetworkStatus(t *testing.T) {
+	ns := readyNetworkStatus()
+	ns.MarkChainIDMismatch("ChainIDMismatch", "Endpoints serve another chain than 1.")
+
+	s := readyStatus()
+	s.PropagateNetworkStatus(ns)
+
+	cond := s.GetCondition(BlockchainSourceConditionNetworkReady)
+	if !cond.IsFalse() || cond.Reason != "ChainIDMismatch" || cond.Message != "Endpoints serve another chain than 1." {
+		t.Errorf("condition NetworkReady = %v, want False with the reason and message of the network", cond)
+	}
+
+	s.MarkNoNetwork()
+	if cond := s.GetCondition(BlockchainSourceConditionNetworkReady); !cond.IsTrue() || cond.Reason != "NoNetwork" {
+		t.Errorf("condition NetworkReady = %v, want True with reason NoNetwork", cond)
+	}
+	if !s.IsReady() {
+		t.Error("IsReady() = false, want true without a network")
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"knative.dev/pkg/apis"
+)
+
+// networkEndpointSchemes are the URL schemes of the endpoints of a network,
+// which serves both polling and streaming sources.
+var networkEndpointSchemes = []string{"http", "https", "ws", "wss"}
+
+func (n *BlockchainNetwork) Validate(ctx context.Context) *apis.FieldError {
+	return n.Spec.Validate(ctx).ViaField("spec")
+}
+
+func (ns *BlockchainNetworkSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	switch ns.Family {
+	case "", ChainFamilyEVM:
+		if ns.ChainID != "" {
+			if _, err := strconv.ParseUint(ns.ChainID, 10, 64); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(ns.ChainID, "chainID", "EVM chain IDs must be decimal integers"))
+			}
+		}
+	case ChainFamilyBeacon:
+		if ns.ChainID != "" {
+			errs = errs.Also(validateBeaconChainID(ns.ChainID))
+		}
+	case ChainFamilyBitcoin, ChainFamilyFabric, ChainFamilyTendermint, ChainFamilySolana:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(ns.Family, "family"))
+	}
+
+	if ns.ChainID == "" {
+		errs = errs.Also(apis.ErrMissingField("chainID"))
+	}
+
+	if len(ns.Endpoints) == 0 {
+		errs = errs.Also(apis.ErrMissingField("endpoints"))
+	}
+	for i := range ns.Endpoints {
+		if ns.Family == ChainFamilyFabric {
+			errs = errs.Also(ns.Endpoints[i].validate(ctx, grpcSchemes,
+				"URL scheme must be grpc or grpcs for fabric peers").ViaFieldIndex("endpoints", i))
+			if ns.Endpoints[i].Credentials != nil {
+				// Peers authenticate the identity of the sources.
+				errs = errs.Also(apis.ErrDisallowedFields("credentials").ViaFieldIndex("endpoints", i))
+			}
+			continue
+		}
+		errs = errs.Also(ns.Endpoints[i].validate(ctx, networkEndpointSchemes,
+			"URL scheme must be http, https, ws or wss").ViaFieldIndex("endpoints", i))
+	}
+
+	for i, namespace := range ns.AllowedNamespaces {
+		if len(validation.IsDNS1123Label(namespace)) > 0 {
+			errs = errs.Also(apis.ErrInvalidArrayValue(namespace, "allowedNamespaces", i))
+		}
+	}
+
+	if ns.PollInterval != nil && ns.PollInterval.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(ns.PollInterval.Duration.String(), "pollInterval", "poll interval must be positive"))
+	}
+	if ns.MaxLogRange != nil && *ns.MaxLogRange < 1 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*ns.MaxLogRange, 1, math.MaxInt64, "maxLogRange"))
+	}
+
+	if ns.Finality != nil {
+		errs = errs.Also(ns.Finality.Validate(ctx).ViaField("finality"))
+	}
+
+	return errs
+}
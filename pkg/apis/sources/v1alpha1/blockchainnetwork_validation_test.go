@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/apis"
+)
+
+func TestBlockchainNetworkValidation(t *testing.T) {
+	testCases := map[string]struct {
+		spec BlockchainNetworkSpec
+		want *apis.FieldError
+	}{
+		"empty": {
+			spec: BlockchainNetworkSpec{},
+			want: apis.ErrMissingField("spec.chainID").
+				Also(apis.ErrMissingField("spec.endpoints")),
+		},
+		"http and websocket endpoints": {
+			spec: BlockchainNetworkSpec{
+				ChainID: "1",
+				Endpoints: []RPCEndpoint{{
+					URL: "https://mainnet.example.com",
+				}, {
+					URL:      "wss://mainnet.example.com",
+					Priority: 1,
+					Credentials: &SecretValueFromSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "provider"},
+							Key:                  "authorization",
+						},
+					},
+				}},
+			},
+		},
+		"non-evm chain": {
+			spec: BlockchainNetworkSpec{
+				Family:    ChainFamilyTendermint,
+				ChainID:   "cosmoshub-4",
+				Endpoints: testEndpoints,
+			},
+		},
+		"fabric peers": {
+			spec: BlockchainNetworkSpec{
+				Family:  ChainFamilyFabric,
+				ChainID: "mychannel",
+				Endpoints: []RPCEndpoint{{
+					URL: "grpcs://peer0.org1.example.com:7051",
+				}, {
+					URL: "https://peer1.org1.example.com:7051",
+					Credentials: &SecretValueFromSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "provider"},
+							Key:                  "authorization",
+						},
+					},
+				}},
+			},
+			want: apis.ErrInvalidValue("https://peer1.org1.example.com:7051", "spec.endpoints[1].url",
+				"URL scheme must be grpc or grpcs for fabric peers").Also(
+				apis.ErrDisallowedFields("spec.endpoints[1].credentials")),
+		},
+		"invalid evm chain ID": {
+			spec: BlockchainNetworkSpec{
+				ChainID:   "mainnet",
+				Endpoints: testEndpoints,
+			},
+			want: apis.ErrInvalidValue("mainnet", "spec.chainID", "EVM chain IDs must be decimal integers"),
+		},
+		"invalid beacon chain ID": {
+			spec: BlockchainNetworkSpec{
+				Family:    ChainFamilyBeacon,
+				ChainID:   "mainnet",
+				Endpoints: testEndpoints,
+			},
+			want: apis.ErrInvalidValue("mainnet", "spec.chainID", "beacon chain IDs are the decimal chain IDs of their deposit contract"),
+		},
+		"invalid family": {
+			spec: BlockchainNetworkSpec{
+				Family:    "cardano",
+				ChainID:   "mainnet",
+				Endpoints: testEndpoints,
+			},
+			want: apis.ErrInvalidValue("cardano", "spec.family"),
+		},
+		"invalid endpoints": {
+			spec: BlockchainNetworkSpec{
+				ChainID: "1",
+				Endpoints: []RPCEndpoint{{
+					URL:      "ftp://mainnet.example.com",
+					Priority: -1,
+				}, {
+					URL:         "https://mainnet.example.com",
+					Credentials: &SecretValueFromSource{},
+				}},
+			},
+			want: apis.ErrInvalidValue("ftp://mainnet.example.com", "spec.endpoints[0].url", "URL scheme must be http, https, ws or wss").
+				Also(apis.ErrOutOfBoundsValue(-1, 0, math.MaxInt32, "spec.endpoints[0].priority")).
+				Also(apis.ErrMissingField("spec.endpoints[1].credentials.secretKeyRef")),
+		},
+		"allowed namespaces": {
+			spec: BlockchainNetworkSpec{
+				ChainID:           "1",
+				Endpoints:         testEndpoints,
+				AllowedNamespaces: []string{"team-a", "Team_B"},
+			},
+			want: apis.ErrInvalidArrayValue("Team_B", "spec.allowedNamespaces", 1),
+		},
+		"invalid defaults": {
+			spec: BlockchainNetworkSpec{
+				ChainID:     "1",
+				Endpoints:   testEndpoints,
+				MaxLogRange: ptrInt64(0),
+				Finality: &Finality{
+					Level: "safest",
+				},
+			},
+			want: apis.ErrOutOfBoundsValue(0, 1, math.MaxInt64, "spec.maxLogRange").
+				Also(apis.ErrInvalidValue("safest", "spec.finality.level")),
+		},
+	}
+
+	for n, test := range testCases {
+		t.Run(n, func(t *testing.T) {
+			network := &BlockchainNetwork{Spec: test.spec}
+			got := network.Validate(context.Background())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("unexpected validation error (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	"context"
+
+	"knative.dev/eventing-blockchain/pkg/apis/config"
 )
 
 func (g *BlockchainSource) SetDefaults(ctx context.Context) {
@@ -25,5 +27,64 @@ func (g *BlockchainSource) SetDefaults(ctx context.Context) {
 }
 
 func (gs *BlockchainSourceSpec) SetDefaults(ctx context.Context) {
-	// Nothing yet.
+	if gs.Mode == "" {
+		gs.Mode = IngestionModePolling
+	}
+
+	if gs.Network != "" {
+		// The chain and its defaults are those of the network, resolved by
+		// the controller.
+		return
+	}
+
+	if gs.Family == "" {
+		gs.Family = ChainFamilyEVM
+	}
+
+	profile := config.FromContextOrDefaults(ctx).Networks.Profile(string(gs.Family), gs.ChainID)
+	if profile != nil {
+		gs.applyProfile(profile)
+	}
+
+	if gs.Finality == nil {
+		gs.Finality = &Finality{}
+	}
+	gs.Finality.SetDefaults(ctx)
+}
+
+// applyProfile fills the fields left unset with the defaults of the network.
+func (gs *BlockchainSourceSpec) applyProfile(profile *config.NetworkProfile) {
+	if gs.PollInterval == nil && profile.PollInterval != nil {
+		gs.PollInterval = profile.PollInterval.DeepCopy()
+	}
+	if gs.MaxLogRange == nil && profile.MaxLogRange != nil {
+		maxLogRange := *profile.MaxLogRange
+		gs.MaxLogRange = &maxLogRange
+	}
+
+	if gs.Finality == nil {
+		gs.Finality = &Finality{}
+	}
+	gs.Finality.applyProfile(profile)
+}
+
+// applyProfile fills the finality left unset with the one of the network.
+func (f *Finality) applyProfile(profile *config.NetworkProfile) {
+	if f.Level == "" {
+		f.Level = FinalityLevel(profile.Finality)
+	}
+	if f.Level == FinalityLevelConfirmed && f.Confirmations == nil && profile.Confirmations != nil {
+		confirmations := *profile.Confirmations
+		f.Confirmations = &confirmations
+	}
+}
+
+func (f *Finality) SetDefaults(ctx context.Context) {
+	if f.Level == "" {
+		f.Level = FinalityLevelLatest
+	}
+	if f.Level == FinalityLevelConfirmed && f.Confirmations == nil {
+		confirmations := int64(DefaultConfirmations)
+		f.Confirmations = &confirmations
+	}
 }
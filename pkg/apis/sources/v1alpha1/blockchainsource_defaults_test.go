@@ -19,8 +19,12 @@ package v1alpha1
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/eventing-blockchain/pkg/apis/config"
 )
 
 func TestBlockchainSourceDefaults(t *testing.T) {
@@ -31,7 +35,147 @@ func TestBlockchainSourceDefaults(t *testing.T) {
 		"nil spec": {
 			initial: BlockchainSource{},
 			expected: BlockchainSource{
-				Spec: BlockchainSourceSpec{},
+				Spec: BlockchainSourceSpec{
+					Family: ChainFamilyEVM,
+					Mode:   IngestionModePolling,
+					Finality: &Finality{
+						Level: FinalityLevelLatest,
+					},
+				},
+			},
+		},
+		"network": {
+			initial: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Network: "mainnet",
+				},
+			},
+			expected: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Network: "mainnet",
+					Mode:    IngestionModePolling,
+				},
+			},
+		},
+		"confirmed finality": {
+			initial: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Mode: IngestionModeStreaming,
+					Finality: &Finality{
+						Level: FinalityLevelConfirmed,
+					},
+				},
+			},
+			expected: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family: ChainFamilyEVM,
+					Mode:   IngestionModeStreaming,
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(DefaultConfirmations),
+					},
+				},
+			},
+		},
+		"explicit confirmations": {
+			initial: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(64),
+					},
+				},
+			},
+			expected: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family: ChainFamilyEVM,
+					Mode:   IngestionModePolling,
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(64),
+					},
+				},
+			},
+		},
+		"explicit family": {
+			initial: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:  ChainFamilyBitcoin,
+					ChainID: "signet",
+				},
+			},
+			expected: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:  ChainFamilyBitcoin,
+					ChainID: "signet",
+					Mode:    IngestionModePolling,
+					Finality: &Finality{
+						Level: FinalityLevelLatest,
+					},
+				},
+			},
+		},
+		"network profile": {
+			initial: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					ChainID: "1",
+				},
+			},
+			expected: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:       ChainFamilyEVM,
+					ChainID:      "1",
+					Mode:         IngestionModePolling,
+					PollInterval: &metav1.Duration{Duration: 12 * time.Second},
+					MaxLogRange:  ptrInt64(2000),
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(12),
+					},
+				},
+			},
+		},
+		"network profile with explicit values": {
+			initial: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					ChainID:      "137",
+					PollInterval: &metav1.Duration{Duration: 5 * time.Second},
+					Finality: &Finality{
+						Level: FinalityLevelFinalized,
+					},
+				},
+			},
+			expected: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:       ChainFamilyEVM,
+					ChainID:      "137",
+					Mode:         IngestionModePolling,
+					PollInterval: &metav1.Duration{Duration: 5 * time.Second},
+					MaxLogRange:  ptrInt64(1000),
+					Finality: &Finality{
+						Level: FinalityLevelFinalized,
+					},
+				},
+			},
+		},
+		"bitcoin network profile": {
+			initial: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:  ChainFamilyBitcoin,
+					ChainID: "main",
+				},
+			},
+			expected: BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:       ChainFamilyBitcoin,
+					ChainID:      "main",
+					Mode:         IngestionModePolling,
+					PollInterval: &metav1.Duration{Duration: time.Minute},
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(6),
+					},
+				},
 			},
 		},
 	}
@@ -44,3 +188,46 @@ func TestBlockchainSourceDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockchainSourceDefaultsFromConfigMap(t *testing.T) {
+	networks, err := config.NewNetworksConfigFromMap(map[string]string{
+		"evm.1":     "finality: finalized",
+		"evm.31337": "finality: latest\npollInterval: 1s",
+	})
+	if err != nil {
+		t.Fatalf("NewNetworksConfigFromMap() = %v", err)
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{Networks: networks})
+
+	for chainID, want := range map[string]BlockchainSourceSpec{
+		"1": {
+			Family:  ChainFamilyEVM,
+			ChainID: "1",
+			Mode:    IngestionModePolling,
+			Finality: &Finality{
+				Level: FinalityLevelFinalized,
+			},
+		},
+		"31337": {
+			Family:       ChainFamilyEVM,
+			ChainID:      "31337",
+			Mode:         IngestionModePolling,
+			PollInterval: &metav1.Duration{Duration: time.Second},
+			Finality: &Finality{
+				Level: FinalityLevelLatest,
+			},
+		},
+	} {
+		t.Run(chainID, func(t *testing.T) {
+			got := BlockchainSource{Spec: BlockchainSourceSpec{ChainID: chainID}}
+			got.SetDefaults(ctx)
+			if diff := cmp.Diff(want, got.Spec); diff != "" {
+				t.Fatalf("Unexpected defaults (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func ptrInt64(i int64) *int64 {
+	return &i
+}
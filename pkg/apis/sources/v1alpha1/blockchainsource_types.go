@@ -18,7 +18,9 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,6 +29,8 @@ import (
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/webhook/resourcesemantics"
+
+	"knative.dev/eventing-blockchain/pkg/filter"
 )
 
 // Check that BlockchainSource can be validated and can be defaulted.
@@ -34,6 +38,9 @@ var _ runtime.Object = (*BlockchainSource)(nil)
 
 var _ resourcesemantics.GenericCRD = (*BlockchainSource)(nil)
 
+// Check that BlockchainSource can be converted by the conversion webhook.
+var _ apis.Convertible = (*BlockchainSource)(nil)
+
 // Check that the type conforms to the duck Knative Resource shape.
 var _ duckv1.KRShaped = (*BlockchainSource)(nil)
 
@@ -47,41 +54,121 @@ type BlockchainSourceSpec struct {
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 
-	// OwnerAndRepository is the GitHub owner/org and repository to
-	// receive events from. The repository may be left off to receive
-	// events from an entire organization.
-	// Examples:
-	//  myuser/project
-	//  myorganization
-	// +kubebuilder:validation:MinLength=1
-	OwnerAndRepository string `json:"ownerAndRepository"`
+	// Network is the name of the BlockchainNetwork the source reads. The
+	// network provides the family, chain ID and endpoints of the chain, as
+	// well as the defaults of the poll interval, log range and finality.
+	// Either the network or the endpoints must be set.
+	// +optional
+	Network string `json:"network,omitempty"`
+
+	// Family is the family of the chain, which determines the protocol
+	// spoken with its nodes and the events it produces. Defaults to evm,
+	// or to the family of the network. It must match the family of the
+	// network when both are set.
+	// +optional
+	// +kubebuilder:validation:Enum=evm,bitcoin,fabric,tendermint,solana,beacon
+	Family ChainFamily `json:"family,omitempty"`
+
+	// ChainID identifies the network the source reads, in the format of
+	// its family: the EIP-155 chain ID of EVM chains ("1" for Ethereum
+	// mainnet), the chain name bitcoind reports ("main"), the chain ID of
+	// Tendermint chains ("cosmoshub-4"), the CAIP-2 reference or name of
+	// Solana clusters ("mainnet-beta"), or the chain ID of the deposit
+	// contract of beacon chains. Endpoints serving another network are not
+	// used. Any network is accepted when not set. Fabric sources read the
+	// channel it names, and require it. It must match the chain ID of the
+	// network when both are set.
+	// +optional
+	ChainID string `json:"chainID,omitempty"`
+
+	// Endpoints are the JSON-RPC endpoints of the nodes of the chain. The
+	// source uses the healthy endpoint with the lowest priority value, and
+	// fails over to the others when it goes down. Every endpoint must serve
+	// the same chain. EVM endpoints are WebSocket URLs when streaming;
+	// other families take the URLs their nodes serve in either mode: HTTP
+	// for bitcoin, Solana and beacon nodes, WebSocket for Tendermint nodes
+	// and gRPC for Fabric peers.
+	// +optional
+	Endpoints []RPCEndpoint `json:"endpoints,omitempty"`
+
+	// Mode is how the source learns about new blocks. "polling" queries
+	// the node periodically, "streaming" subscribes to new blocks over a
+	// WebSocket connection, or to the ZMQ notifications of bitcoind.
+	// Fabric peers, Tendermint, Solana and beacon nodes always stream their
+	// events.
+	// Defaults to polling.
+	// +optional
+	// +kubebuilder:validation:Enum=polling,streaming
+	Mode IngestionMode `json:"mode,omitempty"`
+
+	// PollInterval is how often nodes are polled for new blocks in polling
+	// mode. Defaults to the interval of the network profile, if any.
+	// +optional
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+
+	// MaxLogRange is the largest number of blocks whose contract logs are
+	// requested at once, for nodes limiting the range of log queries.
+	// Defaults to the range of the network profile, if any.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxLogRange *int64 `json:"maxLogRange,omitempty"`
+
+	// Contracts subscribes to the logs emitted by smart contracts of an EVM
+	// chain. When set, the source emits one event per matching log instead
+	// of one event per block.
+	// +optional
+	Contracts *ContractSubscription `json:"contracts,omitempty"`
+
+	// Bitcoin holds the settings of sources reading a chain of the bitcoin
+	// family.
+	// +optional
+	Bitcoin *BitcoinOptions `json:"bitcoin,omitempty"`
+
+	// Fabric holds the settings of sources reading a Hyperledger Fabric
+	// channel.
+	// +optional
+	Fabric *FabricOptions `json:"fabric,omitempty"`
 
-	// EventType is the type of event to receive from GitHub. These
-	// correspond to the "Webhook event name" values listed at
-	// https://developer.github.com/v3/activity/events/types/ - ie
-	// "pull_request"
-	// +kubebuilder:validation:MinItems=1
-	// +kubebuilder:validation:Enum=check_suite,commit_comment,create,delete,deployment,deployment_status,fork,gollum,installation,integration_installation,issue_comment,issues,label,member,membership,milestone,organization,org_block,page_build,ping,project_card,project_column,project,public,pull_request,pull_request_review,pull_request_review_comment,push,release,repository,status,team,team_add,watch
-	EventTypes []string `json:"eventTypes"`
+	// Tendermint holds the settings of sources reading a chain built on
+	// Tendermint or CometBFT.
+	// +optional
+	Tendermint *TendermintOptions `json:"tendermint,omitempty"`
+
+	// Solana holds the settings of sources reading a Solana cluster.
+	// +optional
+	Solana *SolanaOptions `json:"solana,omitempty"`
 
-	// AccessToken is the Kubernetes secret containing the GitHub
-	// access token
-	AccessToken SecretValueFromSource `json:"accessToken"`
+	// Beacon holds the settings of sources reading the beacon chain of an
+	// Ethereum consensus layer.
+	// +optional
+	Beacon *BeaconOptions `json:"beacon,omitempty"`
 
-	// SecretToken is the Kubernetes secret containing the GitHub
-	// secret token
-	SecretToken SecretValueFromSource `json:"secretToken"`
+	// Filters are expressions the receive adapter evaluates on every event
+	// before delivering it. Events are only delivered when they pass all
+	// the filters, so that the sink does not receive events it has no
+	// interest in. Reorg and retraction events are not filtered, as they
+	// are only emitted about events that were delivered.
+	// +optional
+	Filters []EventFilter `json:"filters,omitempty"`
+
+	// Finality is how final a block must be before the events it produces
+	// are emitted. Defaults to emitting blocks as soon as they are known.
+	// +optional
+	Finality *Finality `json:"finality,omitempty"`
 
-	// API URL if using blockchain enterprise (default https://api.github.com)
+	// StartBlock is the first block to emit events for. Past blocks are
+	// backfilled before following new ones. Defaults to the block
+	// following the head of the chain when the source starts. It is
+	// ignored once the source has made progress.
 	// +optional
-	BlockchainAPIURL string `json:"blockchainAPIURL,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	StartBlock *int64 `json:"startBlock,omitempty"`
 
-	// Secure can be set to true to configure the webhook to use https,
-	// or false to use http.  Omitting it relies on the scheme of the
-	// Knative Service created (e.g. if auto-TLS is enabled it should
-	// do the right thing).
+	// EndBlock is the last block to emit events for. The source stops once
+	// it is emitted. Blocks are followed indefinitely when not set.
 	// +optional
-	Secure *bool `json:"secure,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	EndBlock *int64 `json:"endBlock,omitempty"`
 
 	// inherits duck/v1 SourceSpec, which currently provides:
 	// * Sink - a reference to an object that will resolve to a domain name or
@@ -91,6 +178,351 @@ type BlockchainSourceSpec struct {
 	duckv1.SourceSpec `json:",inline"`
 }
 
+// ChainFamily is a family of chains sharing the same node protocol.
+type ChainFamily string
+
+const (
+	// ChainFamilyEVM are the chains running the Ethereum Virtual Machine,
+	// whose nodes speak the Ethereum JSON-RPC API.
+	ChainFamilyEVM ChainFamily = "evm"
+
+	// ChainFamilyBitcoin are Bitcoin and the chains whose nodes speak the
+	// Bitcoin Core JSON-RPC API.
+	ChainFamilyBitcoin ChainFamily = "bitcoin"
+
+	// ChainFamilyFabric are Hyperledger Fabric channels.
+	ChainFamilyFabric ChainFamily = "fabric"
+
+	// ChainFamilyTendermint are the chains built on Tendermint or CometBFT,
+	// such as Cosmos SDK chains.
+	ChainFamilyTendermint ChainFamily = "tendermint"
+
+	// ChainFamilySolana are Solana clusters.
+	ChainFamilySolana ChainFamily = "solana"
+
+	// ChainFamilyBeacon are the beacon chains of the Ethereum consensus
+	// layer, whose nodes serve the Beacon API.
+	ChainFamilyBeacon ChainFamily = "beacon"
+)
+
+// RPCEndpoint is a JSON-RPC endpoint of a node.
+type RPCEndpoint struct {
+	// URL is the URL of the endpoint.
+	URL string `json:"url"`
+
+	// Priority orders the endpoints, lower values first. Endpoints of the
+	// same priority are ordered by health. Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Priority int32 `json:"priority,omitempty"`
+
+	// Credentials is the Kubernetes secret containing the value of the
+	// Authorization header sent to the endpoint, e.g. "Bearer <token>".
+	// +optional
+	Credentials *SecretValueFromSource `json:"credentials,omitempty"`
+}
+
+// IngestionMode is how a BlockchainSource ingests new blocks.
+type IngestionMode string
+
+const (
+	// IngestionModePolling periodically polls the node for new blocks.
+	IngestionModePolling IngestionMode = "polling"
+
+	// IngestionModeStreaming subscribes to new blocks and reconnects
+	// whenever the subscription is lost, without missing blocks.
+	IngestionModeStreaming IngestionMode = "streaming"
+)
+
+// FinalityLevel is how final a block is.
+type FinalityLevel string
+
+const (
+	// FinalityLevelLatest blocks are the ones the node currently sees as
+	// the head of the chain, which reorgs may still orphan.
+	FinalityLevelLatest FinalityLevel = "latest"
+
+	// FinalityLevelConfirmed blocks have a given number of blocks built on
+	// top of them.
+	FinalityLevelConfirmed FinalityLevel = "confirmed"
+
+	// FinalityLevelSafe blocks are the ones the node reports under the
+	// "safe" block tag, unlikely to be orphaned.
+	FinalityLevelSafe FinalityLevel = "safe"
+
+	// FinalityLevelFinalized blocks are the ones the node reports under
+	// the "finalized" block tag, which cannot be orphaned.
+	FinalityLevelFinalized FinalityLevel = "finalized"
+)
+
+// DefaultConfirmations is the number of confirmations of the confirmed
+// finality level when none is given.
+const DefaultConfirmations = 12
+
+// MaxConfirmations is the deepest confirmation depth, far beyond the depth
+// of the reorganizations of any chain.
+const MaxConfirmations = 1024
+
+// Finality controls when the events of a block are emitted.
+type Finality struct {
+	// Level is the finality a block must reach before its events are
+	// emitted. Defaults to latest.
+	// +optional
+	// +kubebuilder:validation:Enum=latest,confirmed,safe,finalized
+	Level FinalityLevel `json:"level,omitempty"`
+
+	// Confirmations is the number of blocks that must be built on top of a
+	// block before its events are emitted, with the confirmed level.
+	// Defaults to 12.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1024
+	Confirmations *int64 `json:"confirmations,omitempty"`
+}
+
+// ContractSubscription selects the smart contract logs to receive.
+type ContractSubscription struct {
+	// Addresses are the addresses of the contracts to receive logs from.
+	// Logs of all contracts are received when empty.
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+
+	// EventSignatures are the events to receive, either as a canonical
+	// signature, e.g. "Transfer(address,address,uint256)", as the name of
+	// an event declared by the ABI, or as the 0x-prefixed hash of a
+	// signature. All events are received when empty.
+	// +optional
+	EventSignatures []string `json:"eventSignatures,omitempty"`
+
+	// Topics filters logs by topic, position by position, with the same
+	// semantics as the eth_getLogs filter: an empty position matches any
+	// topic, otherwise one of the listed topics must match. The first
+	// position is overridden by EventSignatures when set.
+	// +optional
+	Topics [][]string `json:"topics,omitempty"`
+
+	// ABI is the contract ABI used to decode logs. Logs are emitted with
+	// their raw topics and data when it is not set or does not declare
+	// their event.
+	// +optional
+	ABI *ContractABI `json:"abi,omitempty"`
+}
+
+// ContractABI is the JSON description of a contract ABI, given either inline
+// or by reference to a ConfigMap key.
+type ContractABI struct {
+	// Inline is the ABI JSON.
+	// +optional
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMapKeyRef selects the ConfigMap key holding the ABI JSON.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// BitcoinOptions are the settings of sources reading a chain of the bitcoin
+// family. Such sources emit an event per block, with its transactions
+// decoded, and an event per transaction entering the mempool of the node.
+type BitcoinOptions struct {
+	// ZMQEndpoint is the ZeroMQ endpoint bitcoind publishes its hashblock
+	// and rawtx notifications on, as set by its -zmqpubhashblock and
+	// -zmqpubrawtx options, e.g. "tcp://bitcoind:28332". Streaming sources
+	// subscribe to it, and read the notified blocks and transactions from
+	// the endpoints. It is required in streaming mode. Polling sources
+	// read the endpoints only.
+	// +optional
+	ZMQEndpoint string `json:"zmqEndpoint,omitempty"`
+}
+
+// FabricBlockType is the kind of blocks a Fabric peer delivers.
+type FabricBlockType string
+
+const (
+	// FabricBlockTypeFull blocks hold the transactions with their
+	// creators, timestamps and chaincode event payloads. Reading them
+	// requires the Blocks ACL of the channel.
+	FabricBlockTypeFull FabricBlockType = "full"
+
+	// FabricBlockTypeFiltered blocks only hold the IDs and validation
+	// codes of the transactions, and their chaincode events without
+	// payload. Reading them requires the less privileged FilteredBlocks
+	// ACL.
+	FabricBlockTypeFiltered FabricBlockType = "filtered"
+)
+
+// FabricOptions are the settings of sources reading a Hyperledger Fabric
+// channel from the Deliver service of its peers. Such sources emit an event
+// per transaction, with its validation code, or an event per chaincode
+// event when subscribed to chaincode events.
+type FabricOptions struct {
+	// BlockType is the kind of blocks read from the peers. Defaults to
+	// full.
+	// +optional
+	// +kubebuilder:validation:Enum=full,filtered
+	BlockType FabricBlockType `json:"blockType,omitempty"`
+
+	// Identity is the client identity the blocks are requested with.
+	Identity FabricIdentity `json:"identity"`
+
+	// TLSRootCertificate is the Kubernetes secret containing the PEM
+	// encoded certificates of the TLS CAs of the peers, for grpcs
+	// endpoints. The system roots are used when not set.
+	// +optional
+	TLSRootCertificate *SecretValueFromSource `json:"tlsRootCertificate,omitempty"`
+
+	// ChaincodeEvents subscribes to the events set by chaincodes. When
+	// set, the source emits one event per matching chaincode event of
+	// valid transactions instead of one event per transaction.
+	// +optional
+	ChaincodeEvents []ChaincodeEventSubscription `json:"chaincodeEvents,omitempty"`
+}
+
+// FabricIdentity is a client identity enrolled with a membership service
+// provider of the network.
+type FabricIdentity struct {
+	// MSPID is the Kubernetes secret containing the ID of the membership
+	// service provider the identity belongs to, e.g. "Org1MSP".
+	MSPID SecretValueFromSource `json:"mspID"`
+
+	// Certificate is the Kubernetes secret containing the PEM encoded
+	// certificate of the identity.
+	Certificate SecretValueFromSource `json:"certificate"`
+
+	// PrivateKey is the Kubernetes secret containing the PEM encoded
+	// private key of the identity.
+	PrivateKey SecretValueFromSource `json:"privateKey"`
+}
+
+// ChaincodeEventSubscription selects the events of a chaincode to receive.
+type ChaincodeEventSubscription struct {
+	// ChaincodeName is the name of the chaincode setting the events.
+	ChaincodeName string `json:"chaincodeName"`
+
+	// EventName is a regular expression the full name of the events must
+	// match, e.g. "Transfer|Approval". All events of the chaincode are
+	// received when empty.
+	// +optional
+	EventName string `json:"eventName,omitempty"`
+}
+
+// TendermintOptions are the settings of sources reading a chain built on
+// Tendermint or CometBFT. Such sources subscribe to the events of the nodes
+// matching their queries, and emit an event per NewBlock or Tx event, with
+// the attributes of its ABCI events decoded. Blocks missed while
+// disconnected are read back from the nodes, and matched against the
+// queries by the source.
+type TendermintOptions struct {
+	// Queries select the events to emit, with the syntax of the queries of
+	// the subscribe method of the nodes, e.g.
+	// "tm.event='Tx' AND transfer.recipient='cosmos1...'". Every query must
+	// select either NewBlock or Tx events with a tm.event condition.
+	// Defaults to "tm.event='NewBlock'".
+	// +optional
+	Queries []string `json:"queries,omitempty"`
+}
+
+// SolanaOptions are the settings of sources reading a Solana cluster. Such
+// sources subscribe to the PubSub endpoint of the nodes, and emit an event
+// per transaction mentioning one of the Mentions, with its logs and the
+// details getTransaction returns, an event per change of one of the
+// Accounts, and an event per slot when Slots is set. The finality level
+// selects the commitment of the subscriptions: latest for processed,
+// confirmed or finalized. The transactions mentioning the Mentions while
+// the source was disconnected are read back from the nodes.
+type SolanaOptions struct {
+	// Mentions are the base58 addresses of the accounts, usually programs,
+	// whose transactions are emitted with their logs.
+	// +optional
+	Mentions []string `json:"mentions,omitempty"`
+
+	// Accounts are the base58 addresses of the accounts whose changes are
+	// emitted.
+	// +optional
+	Accounts []string `json:"accounts,omitempty"`
+
+	// Slots emits an event per slot the node processes. Defaults to true
+	// when neither Mentions nor Accounts are set.
+	// +optional
+	Slots bool `json:"slots,omitempty"`
+}
+
+// BeaconTopic is a topic of the event stream of beacon nodes.
+type BeaconTopic string
+
+const (
+	// BeaconTopicHead events report the new head of the chain.
+	BeaconTopicHead BeaconTopic = "head"
+
+	// BeaconTopicBlock events report the blocks the node imports.
+	BeaconTopicBlock BeaconTopic = "block"
+
+	// BeaconTopicFinalizedCheckpoint events report the finalized epochs.
+	BeaconTopicFinalizedCheckpoint BeaconTopic = "finalized_checkpoint"
+
+	// BeaconTopicChainReorg events report the reorgs of the head.
+	BeaconTopicChainReorg BeaconTopic = "chain_reorg"
+
+	// BeaconTopicVoluntaryExit events report the voluntary exits of
+	// validators the node receives.
+	BeaconTopicVoluntaryExit BeaconTopic = "voluntary_exit"
+)
+
+// BeaconTopics are the topics of the event stream of beacon nodes, in the
+// order the source subscribes to them by default.
+var BeaconTopics = []BeaconTopic{
+	BeaconTopicHead,
+	BeaconTopicBlock,
+	BeaconTopicFinalizedCheckpoint,
+	BeaconTopicChainReorg,
+	BeaconTopicVoluntaryExit,
+}
+
+// BeaconOptions are the settings of sources reading a beacon chain. Such
+// sources subscribe to the /eth/v1/events stream of the nodes, and emit an
+// event per event of the Topics. The head and block events of the slots
+// missed while disconnected are read back from the block headers, and the
+// newest finalized checkpoint from the finality checkpoints of the head
+// state. The reorgs and voluntary exits notified while disconnected are
+// not recovered. The start and end blocks are slots.
+type BeaconOptions struct {
+	// Topics are the topics of the events to emit. Defaults to all of
+	// them.
+	// +optional
+	Topics []BeaconTopic `json:"topics,omitempty"`
+}
+
+// EventFilter is an expression events must satisfy to be delivered, written
+// in either CloudEvents SQL or CEL. Exactly one of CESQL and CEL must be set.
+// Events the expression cannot be evaluated on, for instance because they
+// lack an attribute it reads, are not delivered.
+type EventFilter struct {
+	// Name identifies the filter in the metrics of the events it drops.
+	// Defaults to the index of the filter.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// CESQL is a CloudEvents SQL expression, evaluated on the context
+	// attributes and the extensions of events, e.g.
+	// "eventname = 'Transfer' AND toaddr = '0x...'".
+	// +optional
+	CESQL string `json:"cesql,omitempty"`
+
+	// CEL is a CEL expression, evaluated on the context attributes and the
+	// extensions of events, in the event variable, and on their data
+	// decoded from JSON, in the data variable, e.g.
+	// `event.eventname == "Transfer" && int(data.args.value) > 1000000`.
+	// +optional
+	CEL string `json:"cel,omitempty"`
+}
+
+// Parse parses the expression of the filter.
+func (f *EventFilter) Parse() (filter.Filter, error) {
+	if f.CEL != "" {
+		return filter.ParseCEL(f.CEL)
+	}
+	return filter.ParseCESQL(f.CESQL)
+}
+
 // SecretValueFromSource represents the source of a secret value
 type SecretValueFromSource struct {
 	// The Secret key to select from.
@@ -98,25 +530,165 @@ type SecretValueFromSource struct {
 }
 
 const (
-	// GitHubEventTypePrefix is what all GitHub event types get
-	// prefixed with when converting to CloudEvents.
-	GitHubEventTypePrefix = "dev.knative.source.github"
+	// BlockchainEventTypePrefix is what all the types of the events
+	// emitted by a BlockchainSource get prefixed with.
+	BlockchainEventTypePrefix = "dev.knative.source.blockchain"
+
+	// BlockchainContractSourceSegment separates the chain from the address
+	// of a contract in the source of the events of the contract.
+	BlockchainContractSourceSegment = "contract"
+
+	// BlockchainChaincodeSourceSegment separates the channel from the name
+	// of a Fabric chaincode in the source of the events of the chaincode.
+	BlockchainChaincodeSourceSegment = "chaincode"
+
+	// BlockchainAccountSourceSegment separates the cluster from the address
+	// of a Solana account in the source of the events of the account.
+	BlockchainAccountSourceSegment = "account"
+
+	// BlockchainEventSchemaPrefix is where the JSON schemas of the data of
+	// the events emitted by a BlockchainSource are published, from the
+	// docs/schemas directory of the repository.
+	BlockchainEventSchemaPrefix = "https://raw.githubusercontent.com/knative-extensions/eventing-blockchain/main/docs/schemas"
+)
+
+// BlockchainEventKind is what an event emitted by a BlockchainSource is
+// about.
+type BlockchainEventKind string
+
+const (
+	// BlockchainEventKindBlock events are emitted for blocks.
+	BlockchainEventKindBlock BlockchainEventKind = "block"
+
+	// BlockchainEventKindTransaction events are emitted for transactions.
+	BlockchainEventKindTransaction BlockchainEventKind = "transaction"
+
+	// BlockchainEventKindLog events are emitted for the logs of smart
+	// contracts.
+	BlockchainEventKindLog BlockchainEventKind = "log"
+
+	// BlockchainEventKindChaincodeEvent events are emitted for the events
+	// set by Fabric chaincodes.
+	BlockchainEventKindChaincodeEvent BlockchainEventKind = "chaincodeevent"
+
+	// BlockchainEventKindSlot events are emitted for the slots of a Solana
+	// cluster.
+	BlockchainEventKindSlot BlockchainEventKind = "slot"
+
+	// BlockchainEventKindAccount events are emitted for the changes of
+	// Solana accounts.
+	BlockchainEventKindAccount BlockchainEventKind = "account"
+
+	// BlockchainEventKindHead events are emitted when the head of a beacon
+	// chain changes.
+	BlockchainEventKindHead BlockchainEventKind = "head"
 
-	// GitHubEventSourcePrefix is what all GitHub event sources get
-	// prefixed with when converting to CloudEvents.
-	GitHubEventSourcePrefix = "https://github.com"
+	// BlockchainEventKindFinalizedCheckpoint events are emitted when an
+	// epoch of a beacon chain is finalized.
+	BlockchainEventKindFinalizedCheckpoint BlockchainEventKind = "finalizedcheckpoint"
+
+	// BlockchainEventKindVoluntaryExit events are emitted for the voluntary
+	// exits of the validators of a beacon chain.
+	BlockchainEventKindVoluntaryExit BlockchainEventKind = "voluntaryexit"
+
+	// BlockchainEventKindReorg events are emitted when blocks that events
+	// were emitted for are orphaned, or when a beacon node reports a reorg
+	// of its head.
+	BlockchainEventKindReorg BlockchainEventKind = "reorg"
+
+	// BlockchainEventKindRetracted events retract an event emitted for an
+	// orphaned block.
+	BlockchainEventKindRetracted BlockchainEventKind = "retracted"
 )
 
-// GitHubEventType returns an event type emitted by a BlockchainSource suitable for
-// the value of a CloudEvent's "type" context attribute.
-func GitHubEventType(ghEventType string) string {
-	return fmt.Sprintf("%s.%s", GitHubEventTypePrefix, ghEventType)
+// BlockchainEventType returns the type of the events of a kind emitted by a
+// BlockchainSource reading a chain of the given family, suitable for the
+// value of a CloudEvent's "type" context attribute, such as
+// "dev.knative.source.blockchain.evm.log".
+func BlockchainEventType(family ChainFamily, kind BlockchainEventKind) string {
+	return fmt.Sprintf("%s.%s.%s", BlockchainEventTypePrefix, family, kind)
+}
+
+// BlockchainEventSchema returns the URL of the JSON schema of the data of the
+// events of a kind emitted for chains of the given family, such as
+// BlockchainEventSchemaPrefix + "/evm/log.json".
+func BlockchainEventSchema(family ChainFamily, kind BlockchainEventKind) string {
+	return fmt.Sprintf("%s/%s/%s.json", BlockchainEventSchemaPrefix, family, kind)
+}
+
+// CAIP2Namespace returns the CAIP-2 namespace of the chains of the family.
+// Fabric, which has no registered namespace, uses its family name.
+func (f ChainFamily) CAIP2Namespace() string {
+	switch f {
+	case ChainFamilyEVM:
+		return "eip155"
+	case ChainFamilyBitcoin:
+		return "bip122"
+	case ChainFamilyTendermint:
+		return "cosmos"
+	default:
+		return string(f)
+	}
+}
+
+// bitcoinChainReferences are the CAIP-2 references of the chains bitcoind
+// knows, the beginning of the hash of their genesis block, by the name it
+// reports them under.
+var bitcoinChainReferences = map[string]string{
+	"main":     "000000000019d6689c085ae165831e93",
+	"test":     "000000000933ea01ad0ee984209779ba",
+	"testnet4": "00000000da84f2bafbbc53dee25a72ae",
+	"signet":   "00000008819873e925422c1ff0f99f7c",
+	"regtest":  "0f9188f13cb7b2c71f2a335e3a4fc328",
+}
+
+// solanaChainReferences are the CAIP-2 references of the public Solana
+// clusters, the beginning of their genesis hash, by their name.
+var solanaChainReferences = map[string]string{
+	"mainnet-beta": "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+	"devnet":       "EtWTRABZaYq6iMfeYKouRu166VU2xqa1",
+	"testnet":      "4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z",
+}
+
+// BlockchainEventSource returns the CAIP-2 identifier of a chain, such as
+// "eip155:1" for Ethereum mainnet, suitable for the value of a CloudEvent's
+// "source" context attribute. Bitcoin chains may be given by the name
+// bitcoind reports, such as "main" for "bip122:000000000019d6689c085ae165831e93",
+// and Solana clusters by their name, such as "mainnet-beta" for
+// "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp".
+func BlockchainEventSource(family ChainFamily, chainID string) string {
+	if ref, ok := bitcoinChainReferences[chainID]; ok && family == ChainFamilyBitcoin {
+		chainID = ref
+	}
+	if ref, ok := solanaChainReferences[chainID]; ok && family == ChainFamilySolana {
+		chainID = ref
+	}
+	return family.CAIP2Namespace() + ":" + chainID
+}
+
+// BlockchainContractEventSource returns the source of the events of a smart
+// contract, the CAIP-2 identifier of its chain followed by its address in
+// lowercase, such as "eip155:1/contract/0xdac17f958d2ee523a2206206994597c13d831ec7",
+// so that triggers can filter on the contract with an exact match.
+func BlockchainContractEventSource(chainSource, address string) string {
+	return fmt.Sprintf("%s/%s/%s", chainSource, BlockchainContractSourceSegment, strings.ToLower(address))
 }
 
-// GitHubEventSource returns a unique representation of a BlockchainSource suitable
-// for the value of a CloudEvent's "source" context attribute.
-func GitHubEventSource(ownerAndRepo string) string {
-	return fmt.Sprintf("%s/%s", GitHubEventSourcePrefix, ownerAndRepo)
+// BlockchainChaincodeEventSource returns the source of the events set by a
+// Fabric chaincode, the identifier of its channel followed by its name, such
+// as "fabric:mychannel/chaincode/basic". Chaincode names are case
+// sensitive, so they are kept as is.
+func BlockchainChaincodeEventSource(chainSource, chaincodeName string) string {
+	return fmt.Sprintf("%s/%s/%s", chainSource, BlockchainChaincodeSourceSegment, chaincodeName)
+}
+
+// BlockchainAccountEventSource returns the source of the events about a
+// Solana account, the CAIP-2 identifier of its cluster followed by its
+// address, such as
+// "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/account/TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA".
+// Base58 addresses are case sensitive, so they are kept as is.
+func BlockchainAccountEventSource(chainSource, address string) string {
+	return fmt.Sprintf("%s/%s/%s", chainSource, BlockchainAccountSourceSegment, address)
 }
 
 const (
@@ -132,25 +704,36 @@ const (
 	// BlockchainSource has been configured with a sink target.
 	BlockchainSourceConditionSinkProvided apis.ConditionType = "SinkProvided"
 
-	// BlockchainSourceConditionWebhookConfigured has a status True when the
-	// BlockchainSource has been configured with a webhook.
-	BlockchainSourceConditionWebhookConfigured apis.ConditionType = "WebhookConfigured"
+	// BlockchainSourceConditionAdapterDeployed has status True when the
+	// BlockchainSource receive adapter Deployment is available.
+	BlockchainSourceConditionAdapterDeployed apis.ConditionType = "AdapterDeployed"
+
+	// BlockchainSourceConditionEndpointReachable has status True when the
+	// receive adapter could reach an RPC endpoint of the chain.
+	BlockchainSourceConditionEndpointReachable apis.ConditionType = "EndpointReachable"
 
-	// GitHubServiceconditiondeployed has status True when then
-	// BlockchainSource Service has been deployed
-	//	GitHubServiceConditionDeployed apis.ConditionType = "Deployed"
+	// BlockchainSourceConditionChainIDVerified has status True when the
+	// RPC endpoints serve the chain ID given in the spec.
+	BlockchainSourceConditionChainIDVerified apis.ConditionType = "ChainIDVerified"
 
-	// BlockchainSourceReconciled has status True when the
-	// BlockchainSource has been properly reconciled
-	GitHub
+	// BlockchainSourceConditionNetworkReady has status True when the
+	// BlockchainNetwork referenced by the BlockchainSource is ready, or
+	// when the source does not reference one.
+	BlockchainSourceConditionNetworkReady apis.ConditionType = "NetworkReady"
+
+	// BlockchainSourceConditionCaughtUp has status True when the receive
+	// adapter emitted the events of every block final enough. It does not
+	// affect readiness, a source backfilling past blocks is ready.
+	BlockchainSourceConditionCaughtUp apis.ConditionType = "CaughtUp"
 )
 
 var BlockchainSourceCondSet = apis.NewLivingConditionSet(
+	BlockchainSourceConditionNetworkReady,
 	BlockchainSourceConditionSecretsProvided,
 	BlockchainSourceConditionSinkProvided,
-	BlockchainSourceConditionWebhookConfigured)
-
-//	GitHubServiceConditionDeployed)
+	BlockchainSourceConditionAdapterDeployed,
+	BlockchainSourceConditionEndpointReachable,
+	BlockchainSourceConditionChainIDVerified)
 
 // BlockchainSourceStatus defines the observed state of BlockchainSource
 type BlockchainSourceStatus struct {
@@ -163,8 +746,78 @@ type BlockchainSourceStatus struct {
 	//   Source.
 	duckv1.SourceStatus `json:",inline"`
 
-	// WebhookIDKey is the ID of the webhook registered with GitHub
-	WebhookIDKey string `json:"webhookIDKey,omitempty"`
+	// Checkpoint is the position in the chain up to which the receive
+	// adapter has delivered events, and from which it resumes after a
+	// restart.
+	// +optional
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+
+	// Ingestion reports how the receive adapter keeps up with the chain,
+	// as of its last checkpoint.
+	// +optional
+	Ingestion *IngestionStatus `json:"ingestion,omitempty"`
+}
+
+// IngestionStatus reports how the receive adapter keeps up with the chain.
+type IngestionStatus struct {
+	// ObservedChainID is the chain ID reported by the nodes the receive
+	// adapter reads from.
+	// +optional
+	ObservedChainID string `json:"observedChainID,omitempty"`
+
+	// HeadBlock is the number of the newest block, or slot, seen by the
+	// receive adapter.
+	HeadBlock int64 `json:"headBlock"`
+
+	// LastEmittedBlock is the number of the last block, or slot, whose
+	// events were emitted.
+	LastEmittedBlock int64 `json:"lastEmittedBlock"`
+
+	// LagBlocks is the number of blocks between the last emitted block and
+	// the head. It includes the blocks held back until they reach the
+	// requested finality level.
+	LagBlocks int64 `json:"lagBlocks"`
+
+	// LagSeconds is how long before the last checkpoint the last emitted
+	// block was produced, when known.
+	// +optional
+	LagSeconds *int64 `json:"lagSeconds,omitempty"`
+
+	// ActiveEndpoint is the RPC endpoint the receive adapter last read
+	// from, without the path and query of its URL.
+	// +optional
+	ActiveEndpoint string `json:"activeEndpoint,omitempty"`
+
+	// EmittedEvents counts the events delivered to the sink by CloudEvent
+	// type.
+	// +optional
+	EmittedEvents map[string]int64 `json:"emittedEvents,omitempty"`
+}
+
+// Checkpoint is the position in the chain up to which events have been
+// delivered.
+type Checkpoint struct {
+	// BlockNumber is the number of the last block whose events were all
+	// delivered, or of the block being delivered when LogIndex is set.
+	BlockNumber int64 `json:"blockNumber"`
+
+	// BlockHash is the hash of the block.
+	// +optional
+	BlockHash string `json:"blockHash,omitempty"`
+
+	// LogIndex is the index of the last delivered log of a block whose
+	// logs were partly delivered.
+	// +optional
+	LogIndex *int64 `json:"logIndex,omitempty"`
+
+	// Epoch is the last finalized epoch whose event was delivered, for
+	// beacon chains, whose block number is a slot.
+	// +optional
+	Epoch *int64 `json:"epoch,omitempty"`
+
+	// LastUpdateTime is when the checkpoint was saved.
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
 func (*BlockchainSource) GetGroupVersionKind() schema.GroupVersionKind {
@@ -196,6 +849,51 @@ func (s *BlockchainSourceStatus) InitializeConditions() {
 	BlockchainSourceCondSet.Manage(s).InitializeConditions()
 }
 
+// MarkNoNetwork sets the condition that the source does not reference a
+// network, and so does not depend on one.
+func (s *BlockchainSourceStatus) MarkNoNetwork() {
+	BlockchainSourceCondSet.Manage(s).MarkTrueWithReason(BlockchainSourceConditionNetworkReady,
+		"NoNetwork", "The source does not reference a BlockchainNetwork.")
+}
+
+// MarkNetworkNotFound sets the condition that the network referenced by
+// the source does not exist.
+func (s *BlockchainSourceStatus) MarkNetworkNotFound(name string) {
+	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionNetworkReady,
+		"NetworkNotFound", "The BlockchainNetwork %q does not exist.", name)
+}
+
+// MarkNetworkNotAllowed sets the condition that the network referenced by
+// the source has credentials the namespace of the source may not use.
+func (s *BlockchainSourceStatus) MarkNetworkNotAllowed(name, namespace string) {
+	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionNetworkReady,
+		"NetworkNotAllowed", "The BlockchainNetwork %q does not allow namespace %q to use its credentials.", name, namespace)
+}
+
+// MarkNetworkMismatch sets the condition that the network referenced by the
+// source is not the chain the source expects.
+func (s *BlockchainSourceStatus) MarkNetworkMismatch(messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionNetworkReady,
+		"NetworkMismatch", messageFormat, messageA...)
+}
+
+// PropagateNetworkStatus sets the condition about the network referenced by
+// the source from the readiness of the network.
+func (s *BlockchainSourceStatus) PropagateNetworkStatus(ns *BlockchainNetworkStatus) {
+	cond := ns.GetCondition(BlockchainNetworkConditionReady)
+	switch {
+	case cond == nil:
+		BlockchainSourceCondSet.Manage(s).MarkUnknown(BlockchainSourceConditionNetworkReady,
+			"NetworkNotReconciled", "The BlockchainNetwork has not been reconciled yet.")
+	case cond.IsTrue():
+		BlockchainSourceCondSet.Manage(s).MarkTrue(BlockchainSourceConditionNetworkReady)
+	case cond.IsFalse():
+		BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionNetworkReady, cond.Reason, cond.Message)
+	default:
+		BlockchainSourceCondSet.Manage(s).MarkUnknown(BlockchainSourceConditionNetworkReady, cond.Reason, cond.Message)
+	}
+}
+
 // MarkSecrets sets the condition that the source has a valid spec
 func (s *BlockchainSourceStatus) MarkSecrets() {
 	BlockchainSourceCondSet.Manage(s).MarkTrue(BlockchainSourceConditionSecretsProvided)
@@ -222,24 +920,86 @@ func (s *BlockchainSourceStatus) MarkNoSink(reason, messageFormat string, messag
 	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionSinkProvided, reason, messageFormat, messageA...)
 }
 
-// MarkWebhookConfigured sets the condition that the source has set its webhook configured.
-func (s *BlockchainSourceStatus) MarkWebhookConfigured() {
-	BlockchainSourceCondSet.Manage(s).MarkTrue(BlockchainSourceConditionWebhookConfigured)
+// MarkServiceDeployed sets the condition that the receive adapter has been
+// deployed from the availability of its Deployment.
+func (s *BlockchainSourceStatus) MarkServiceDeployed(d *appsv1.Deployment) {
+	for _, cond := range d.Status.Conditions {
+		if cond.Type != appsv1.DeploymentAvailable {
+			continue
+		}
+		switch cond.Status {
+		case corev1.ConditionTrue:
+			BlockchainSourceCondSet.Manage(s).MarkTrue(BlockchainSourceConditionAdapterDeployed)
+		case corev1.ConditionFalse:
+			BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionAdapterDeployed, cond.Reason, cond.Message)
+		default:
+			BlockchainSourceCondSet.Manage(s).MarkUnknown(BlockchainSourceConditionAdapterDeployed, cond.Reason, cond.Message)
+		}
+		return
+	}
+	BlockchainSourceCondSet.Manage(s).MarkUnknown(BlockchainSourceConditionAdapterDeployed,
+		"ServiceDeploymentUnavailable", "The Deployment '%s' is unavailable.", d.Name)
+}
+
+// MarkNoServiceDeployed sets the condition that the receive adapter could
+// not be deployed.
+func (s *BlockchainSourceStatus) MarkNoServiceDeployed(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionAdapterDeployed, reason, messageFormat, messageA...)
+}
+
+// MarkEndpointReachable sets the condition that the receive adapter could
+// reach an RPC endpoint.
+func (s *BlockchainSourceStatus) MarkEndpointReachable() {
+	BlockchainSourceCondSet.Manage(s).MarkTrue(BlockchainSourceConditionEndpointReachable)
+}
+
+// MarkEndpointUnreachable sets the condition that the receive adapter could
+// not reach any RPC endpoint.
+func (s *BlockchainSourceStatus) MarkEndpointUnreachable(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionEndpointReachable, reason, messageFormat, messageA...)
+}
+
+// MarkEndpointReachabilityUnknown sets the condition that whether the
+// receive adapter can reach an RPC endpoint is not known yet.
+func (s *BlockchainSourceStatus) MarkEndpointReachabilityUnknown(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkUnknown(BlockchainSourceConditionEndpointReachable, reason, messageFormat, messageA...)
+}
+
+// MarkChainIDVerified sets the condition that the RPC endpoints serve the
+// expected chain.
+func (s *BlockchainSourceStatus) MarkChainIDVerified() {
+	BlockchainSourceCondSet.Manage(s).MarkTrue(BlockchainSourceConditionChainIDVerified)
 }
 
-// MarkWebhookNotConfigured sets the condition that the source does not have its webhook configured.
-func (s *BlockchainSourceStatus) MarkWebhookNotConfigured(reason, messageFormat string, messageA ...interface{}) {
-	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionWebhookConfigured, reason, messageFormat, messageA...)
+// MarkChainIDMismatch sets the condition that the RPC endpoints serve
+// another chain than the expected one.
+func (s *BlockchainSourceStatus) MarkChainIDMismatch(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionChainIDVerified, reason, messageFormat, messageA...)
 }
 
-// MarkDeployed sets the condition that the source has been deployed.
-//func (s *BlockchainSourceStatus) MarkServiceDeployed(d *appsv1.Deployment) {
-//	if duckv1.DeploymentIsAvailable(&d.Status, false) {
-//		BlockchainSourceCondSet.Manage(s).MarkTrue(GitHubServiceConditionDeployed)
-//	} else {
-//		BlockchainSourceCondSet.Manage(s).MarkFalse(GitHubServiceConditionDeployed, "ServiceDeploymentUnavailable", "The Deployment '%s' is unavailable.", d.Name)
-//	}
-//}
+// MarkChainIDVerificationUnknown sets the condition that the chain served by
+// the RPC endpoints is not known yet.
+func (s *BlockchainSourceStatus) MarkChainIDVerificationUnknown(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkUnknown(BlockchainSourceConditionChainIDVerified, reason, messageFormat, messageA...)
+}
+
+// MarkCaughtUp sets the condition that the receive adapter emitted the
+// events of every block final enough.
+func (s *BlockchainSourceStatus) MarkCaughtUp() {
+	BlockchainSourceCondSet.Manage(s).MarkTrue(BlockchainSourceConditionCaughtUp)
+}
+
+// MarkNotCaughtUp sets the condition that the receive adapter lags behind
+// the blocks final enough to be emitted.
+func (s *BlockchainSourceStatus) MarkNotCaughtUp(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkFalse(BlockchainSourceConditionCaughtUp, reason, messageFormat, messageA...)
+}
+
+// MarkCaughtUpUnknown sets the condition that whether the receive adapter
+// keeps up with the chain is not known yet.
+func (s *BlockchainSourceStatus) MarkCaughtUpUnknown(reason, messageFormat string, messageA ...interface{}) {
+	BlockchainSourceCondSet.Manage(s).MarkUnknown(BlockchainSourceConditionCaughtUp, reason, messageFormat, messageA...)
+}
 
 // +genclient
 // +genreconciler
@@ -249,6 +1009,15 @@ func (s *BlockchainSourceStatus) MarkWebhookNotConfigured(reason, messageFormat
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
 // +kubebuilder:categories=all,knative,eventing,sources
+// +kubebuilder:printcolumn:name="Family",type="string",JSONPath=".spec.family"
+// +kubebuilder:printcolumn:name="Chain ID",type="string",JSONPath=".spec.chainID"
+// +kubebuilder:printcolumn:name="Network",type="string",JSONPath=".spec.network",priority=1
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Head",type="integer",JSONPath=".status.ingestion.headBlock"
+// +kubebuilder:printcolumn:name="Emitted",type="integer",JSONPath=".status.ingestion.lastEmittedBlock"
+// +kubebuilder:printcolumn:name="Lag",type="integer",JSONPath=".status.ingestion.lagBlocks"
+// +kubebuilder:printcolumn:name="Checkpoint",type="integer",JSONPath=".status.checkpoint.blockNumber",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type BlockchainSource struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -21,7 +21,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/apis/duck"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
@@ -29,6 +31,43 @@ import (
 
 var _ = duck.VerifyType(&BlockchainSource{}, &duckv1.Conditions{})
 
+var (
+	availableDeployment = &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "adapter"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentAvailable,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+
+	unavailableDeployment = &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "adapter"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentAvailable,
+				Status:  corev1.ConditionFalse,
+				Reason:  "MinimumReplicasUnavailable",
+				Message: "Deployment does not have minimum availability.",
+			}},
+		},
+	}
+)
+
+// readyStatus returns a status with every condition Ready depends on True.
+func readyStatus() *BlockchainSourceStatus {
+	s := &BlockchainSourceStatus{}
+	s.InitializeConditions()
+	s.MarkSink(apis.HTTP("example"))
+	s.MarkNoNetwork()
+	s.MarkSecrets()
+	s.MarkServiceDeployed(availableDeployment)
+	s.MarkEndpointReachable()
+	s.MarkChainIDVerified()
+	return s
+}
+
 func TestBlockchainSourceGetConditionSet(t *testing.T) {
 	r := &BlockchainSource{}
 
@@ -81,89 +120,168 @@ func TestBlockchainSourceStatusIsReady(t *testing.T) {
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
+			s.MarkNoNetwork()
 			s.MarkSecrets()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
 			return s
 		}(),
 		want: false,
 	}, {
-		name: "mark webhook",
+		name: "mark deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
-			s.MarkSecrets()
+			s.MarkServiceDeployed(availableDeployment)
 			return s
 		}(),
 		want: false,
 	}, {
-		name: "mark sink, secrets, webhook",
+		name: "mark sink, secrets, deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			return s
 		}(),
 		want: true,
 	}, {
-		name: "mark sink, secrets, webhook, then no sink",
+		name: "mark sink, secrets, deployed, then no sink",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			s.MarkNoSink("Testing", "")
 			return s
 		}(),
 		want: false,
 	}, {
-		name: "mark sink, secrets, webhook, then no secrets",
+		name: "mark sink, secrets, deployed, then no secrets",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			s.MarkNoSecrets("Testing", "")
 			return s
 		}(),
 		want: false,
 	}, {
-		name: "mark sink, secrets, webhook, then no webhook",
+		name: "mark sink, secrets, deployed, then no deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
-			s.MarkWebhookNotConfigured("Testing", "")
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
+			s.MarkNoServiceDeployed("Testing", "")
 			return s
 		}(),
 		want: false,
 	}, {
-		name: "mark sink nil, secrets, webhook",
+		name: "mark sink nil, secrets, deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(nil)
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			return s
 		}(),
 		want: false,
 	}, {
-		name: "mark sink nil, secrets, webhook, then sink",
+		name: "mark sink nil, secrets, deployed, then sink",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(nil)
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			s.MarkSink(apis.HTTP("example"))
 			return s
 		}(),
 		want: true,
+	}, {
+		name: "ready, then chain ID mismatch",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkChainIDMismatch("ChainIDMismatch", "")
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "ready, then endpoint unreachable",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkEndpointUnreachable("EndpointUnauthorized", "")
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "ready, not caught up",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkNotCaughtUp("Backfilling", "")
+			return s
+		}(),
+		want: true,
+	}, {
+		name: "ready, then network not found",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkNetworkNotFound("mainnet")
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "ready, network ready",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.PropagateNetworkStatus(readyNetworkStatus())
+			return s
+		}(),
+		want: true,
+	}, {
+		name: "ready, network not reconciled",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.PropagateNetworkStatus(&BlockchainNetworkStatus{})
+			return s
+		}(),
+		want: false,
+	}, {
+		name: "ready, network unreachable",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			ns := readyNetworkStatus()
+			ns.MarkEndpointsUnreachable("EndpointsUnreachable", "")
+			s.PropagateNetworkStatus(ns)
+			return s
+		}(),
+		want: false,
 	}}
 
 	for _, test := range tests {
@@ -217,7 +335,10 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
+			s.MarkNoNetwork()
 			s.MarkSecrets()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
 			return s
 		}(),
 		condQuery: BlockchainSourceConditionReady,
@@ -226,11 +347,11 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Status: corev1.ConditionUnknown,
 		},
 	}, {
-		name: "mark webhook",
+		name: "mark deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
-			s.MarkWebhookConfigured()
+			s.MarkServiceDeployed(availableDeployment)
 			return s
 		}(),
 		condQuery: BlockchainSourceConditionReady,
@@ -239,13 +360,16 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Status: corev1.ConditionUnknown,
 		},
 	}, {
-		name: "mark sink, secrets, webhook",
+		name: "mark sink, secrets, deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			return s
 		}(),
 		condQuery: BlockchainSourceConditionReady,
@@ -254,13 +378,16 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Status: corev1.ConditionTrue,
 		},
 	}, {
-		name: "mark sink, secrets, webhook, then no sink",
+		name: "mark sink, secrets, deployed, then no sink",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			s.MarkNoSink("Testing", "hi%s", "")
 			return s
 		}(),
@@ -272,13 +399,16 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Message: "hi",
 		},
 	}, {
-		name: "mark sink, secrets, webhook, then no secrets",
+		name: "mark sink, secrets, deployed, then no secrets",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			s.MarkNoSecrets("Testing", "hi%s", "")
 			return s
 		}(),
@@ -290,14 +420,17 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Message: "hi",
 		},
 	}, {
-		name: "mark sink, secrets, webhook, then no webhook",
+		name: "mark sink, secrets, deployed, then no deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
-			s.MarkWebhookNotConfigured("Testing", "hi%s", "")
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
+			s.MarkNoServiceDeployed("Testing", "hi%s", "")
 			return s
 		}(),
 		condQuery: BlockchainSourceConditionReady,
@@ -308,13 +441,16 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Message: "hi",
 		},
 	}, {
-		name: "mark sink nil, secrets, webhook",
+		name: "mark sink nil, secrets, deployed",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(nil)
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			return s
 		}(),
 		condQuery: BlockchainSourceConditionReady,
@@ -325,13 +461,16 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Message: "Sink has resolved to empty.",
 		},
 	}, {
-		name: "mark sink nil, secrets, webhook, then sink",
+		name: "mark sink nil, secrets, deployed, then sink",
 		s: func() *BlockchainSourceStatus {
 			s := &BlockchainSourceStatus{}
 			s.InitializeConditions()
 			s.MarkSink(nil)
+			s.MarkNoNetwork()
 			s.MarkSecrets()
-			s.MarkWebhookConfigured()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(availableDeployment)
 			s.MarkSink(apis.HTTP("example"))
 			return s
 		}(),
@@ -340,6 +479,101 @@ func TestBlockchainSourceStatusGetCondition(t *testing.T) {
 			Type:   BlockchainSourceConditionReady,
 			Status: corev1.ConditionTrue,
 		},
+	}, {
+		name: "mark sink, secrets, unavailable deployment",
+		s: func() *BlockchainSourceStatus {
+			s := &BlockchainSourceStatus{}
+			s.InitializeConditions()
+			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
+			s.MarkSecrets()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(unavailableDeployment)
+			return s
+		}(),
+		condQuery: BlockchainSourceConditionAdapterDeployed,
+		want: &apis.Condition{
+			Type:    BlockchainSourceConditionAdapterDeployed,
+			Status:  corev1.ConditionFalse,
+			Reason:  "MinimumReplicasUnavailable",
+			Message: "Deployment does not have minimum availability.",
+		},
+	}, {
+		name: "mark sink, secrets, deployment without status",
+		s: func() *BlockchainSourceStatus {
+			s := &BlockchainSourceStatus{}
+			s.InitializeConditions()
+			s.MarkSink(apis.HTTP("example"))
+			s.MarkNoNetwork()
+			s.MarkSecrets()
+			s.MarkEndpointReachable()
+			s.MarkChainIDVerified()
+			s.MarkServiceDeployed(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "adapter"}})
+			return s
+		}(),
+		condQuery: BlockchainSourceConditionReady,
+		want: &apis.Condition{
+			Type:    BlockchainSourceConditionReady,
+			Status:  corev1.ConditionUnknown,
+			Reason:  "ServiceDeploymentUnavailable",
+			Message: "The Deployment 'adapter' is unavailable.",
+		},
+	}, {
+		name: "chain ID mismatch",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkChainIDMismatch("ChainIDMismatch", "The endpoints serve chain %d instead of %d.", 5, 1)
+			return s
+		}(),
+		condQuery: BlockchainSourceConditionReady,
+		want: &apis.Condition{
+			Type:    BlockchainSourceConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  "ChainIDMismatch",
+			Message: "The endpoints serve chain 5 instead of 1.",
+		},
+	}, {
+		name: "endpoint reachability unknown",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkEndpointReachabilityUnknown("AdapterNotReported", "hi%s", "")
+			return s
+		}(),
+		condQuery: BlockchainSourceConditionReady,
+		want: &apis.Condition{
+			Type:    BlockchainSourceConditionReady,
+			Status:  corev1.ConditionUnknown,
+			Reason:  "AdapterNotReported",
+			Message: "hi",
+		},
+	}, {
+		name: "not caught up",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkNotCaughtUp("Backfilling", "%d blocks behind", 12)
+			return s
+		}(),
+		condQuery: BlockchainSourceConditionCaughtUp,
+		want: &apis.Condition{
+			Type:    BlockchainSourceConditionCaughtUp,
+			Status:  corev1.ConditionFalse,
+			Reason:  "Backfilling",
+			Message: "12 blocks behind",
+		},
+	}, {
+		name: "caught up",
+		s: func() *BlockchainSourceStatus {
+			s := readyStatus()
+			s.MarkCaughtUpUnknown("AdapterNotReported", "")
+			s.MarkCaughtUp()
+			return s
+		}(),
+		condQuery: BlockchainSourceConditionCaughtUp,
+		want: &apis.Condition{
+			Type:   BlockchainSourceConditionCaughtUp,
+			Status: corev1.ConditionTrue,
+		},
 	}}
 
 	for _, test := range tests {
@@ -361,3 +595,66 @@ func TestBlockchainSource_GetGroupVersionKind(t *testing.T) {
 		t.Errorf("Should be 'BlockchainSource'.")
 	}
 }
+
+func TestBlockchainEventType(t *testing.T) {
+	for _, tc := range []struct {
+		family ChainFamily
+		kind   BlockchainEventKind
+		want   string
+	}{
+		{ChainFamilyEVM, BlockchainEventKindLog, "dev.knative.source.blockchain.evm.log"},
+		{ChainFamilyEVM, BlockchainEventKindBlock, "dev.knative.source.blockchain.evm.block"},
+		{ChainFamilyBitcoin, BlockchainEventKindTransaction, "dev.knative.source.blockchain.bitcoin.transaction"},
+		{ChainFamilyBeacon, BlockchainEventKindFinalizedCheckpoint, "dev.knative.source.blockchain.beacon.finalizedcheckpoint"},
+	} {
+		if got := BlockchainEventType(tc.family, tc.kind); got != tc.want {
+			t.Errorf("BlockchainEventType(%s, %s) = %s, want %s", tc.family, tc.kind, got, tc.want)
+		}
+	}
+}
+
+func TestBlockchainEventSchema(t *testing.T) {
+	got := BlockchainEventSchema(ChainFamilyEVM, BlockchainEventKindRetracted)
+	if want := "https://raw.githubusercontent.com/knative-extensions/eventing-blockchain/main/docs/schemas/evm/retracted.json"; got != want {
+		t.Errorf("BlockchainEventSchema() = %s, want %s", got, want)
+	}
+}
+
+func TestBlockchainEventSource(t *testing.T) {
+	for _, tc := range []struct {
+		family  ChainFamily
+		chainID string
+		want    string
+	}{
+		{ChainFamilyEVM, "1", "eip155:1"},
+		{ChainFamilyBitcoin, "000000000019d6689c085ae165831e93", "bip122:000000000019d6689c085ae165831e93"},
+		{ChainFamilyBitcoin, "main", "bip122:000000000019d6689c085ae165831e93"},
+		{ChainFamilyBitcoin, "regtest", "bip122:0f9188f13cb7b2c71f2a335e3a4fc328"},
+		{ChainFamilyEVM, "main", "eip155:main"},
+		{ChainFamilyTendermint, "cosmoshub-4", "cosmos:cosmoshub-4"},
+		{ChainFamilySolana, "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"},
+		{ChainFamilySolana, "devnet", "solana:EtWTRABZaYq6iMfeYKouRu166VU2xqa1"},
+		{ChainFamilyEVM, "devnet", "eip155:devnet"},
+		{ChainFamilyBeacon, "1", "beacon:1"},
+		{ChainFamilyFabric, "mychannel", "fabric:mychannel"},
+	} {
+		if got := BlockchainEventSource(tc.family, tc.chainID); got != tc.want {
+			t.Errorf("BlockchainEventSource(%s, %s) = %s, want %s", tc.family, tc.chainID, got, tc.want)
+		}
+	}
+
+	got := BlockchainContractEventSource("eip155:1", "0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	if want := "eip155:1/contract/0xdac17f958d2ee523a2206206994597c13d831ec7"; got != want {
+		t.Errorf("BlockchainContractEventSource() = %s, want %s", got, want)
+	}
+
+	got = BlockchainAccountEventSource("solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+	if want := "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/account/TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"; got != want {
+		t.Errorf("BlockchainAccountEventSource() = %s, want %s", got, want)
+	}
+
+	got = BlockchainChaincodeEventSource("fabric:mychannel", "assetTransfer")
+	if want := "fabric:mychannel/chaincode/assetTransfer"; got != want {
+		t.Errorf("BlockchainChaincodeEventSource() = %s, want %s", got, want)
+	}
+}
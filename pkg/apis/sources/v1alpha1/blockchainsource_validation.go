@@ -18,10 +18,24 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"knative.dev/pkg/apis"
+
+	"knative.dev/eventing-blockchain/pkg/evm"
+	"knative.dev/eventing-blockchain/pkg/solana"
+	"knative.dev/eventing-blockchain/pkg/tendermint"
 )
 
+// maxTopics is the number of topics a log has at most.
+const maxTopics = 4
+
 func (g *BlockchainSource) Validate(ctx context.Context) *apis.FieldError {
 	return g.Spec.Validate(ctx).ViaField("spec")
 }
@@ -29,10 +43,590 @@ func (g *BlockchainSource) Validate(ctx context.Context) *apis.FieldError {
 func (gs *BlockchainSourceSpec) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
 
-	// TODO: there are more requirements for BlockchainSource. Add them here.
+	// The family of a source of a network defaults to the one of the
+	// network, which only the controller knows: it checks the settings
+	// specific to the family then.
+	family := gs.Family
+	if family == "" && gs.Network == "" {
+		family = ChainFamilyEVM
+	}
+	v, known := familyValidators[family]
+	switch {
+	case known:
+		errs = errs.Also(v.validate(ctx, gs))
+		if gs.Contracts != nil && !v.contracts {
+			errs = errs.Also(apis.ErrDisallowedFields("contracts"))
+		}
+	case family != "":
+		errs = errs.Also(apis.ErrInvalidValue(gs.Family, "family"))
+	}
+	if family != "" {
+		for _, o := range gs.familyOptions() {
+			if o.set && o.family != family {
+				errs = errs.Also(apis.ErrDisallowedFields(string(o.family)))
+			}
+		}
+	}
+
+	switch gs.Mode {
+	case "", IngestionModePolling, IngestionModeStreaming:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(gs.Mode, "mode"))
+	}
+
+	switch {
+	case gs.Network != "" && len(gs.Endpoints) > 0:
+		errs = errs.Also(apis.ErrMultipleOneOf("network", "endpoints"))
+	case gs.Network == "" && len(gs.Endpoints) == 0:
+		errs = errs.Also(apis.ErrMissingOneOf("network", "endpoints"))
+	}
+	if gs.PollInterval != nil && gs.PollInterval.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(gs.PollInterval.Duration.String(), "pollInterval", "poll interval must be positive"))
+	}
+	if gs.MaxLogRange != nil && *gs.MaxLogRange < 1 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*gs.MaxLogRange, 1, math.MaxInt64, "maxLogRange"))
+	}
+
+	for i := range gs.Endpoints {
+		errs = errs.Also(v.validateEndpoint(ctx, gs.Mode, &gs.Endpoints[i]).ViaFieldIndex("endpoints", i))
+	}
+
+	if gs.Finality != nil {
+		errs = errs.Also(gs.Finality.Validate(ctx).ViaField("finality"))
+	}
+
+	if gs.Contracts != nil {
+		errs = errs.Also(gs.Contracts.Validate(ctx).ViaField("contracts"))
+	}
+
+	names := make(map[string]bool, len(gs.Filters))
+	for i := range gs.Filters {
+		f := &gs.Filters[i]
+		errs = errs.Also(f.Validate(ctx).ViaFieldIndex("filters", i))
+		if f.Name == "" {
+			continue
+		}
+		if names[f.Name] {
+			errs = errs.Also(apis.ErrInvalidValue(f.Name, apis.CurrentField,
+				"filter names must be unique").ViaField("name").ViaFieldIndex("filters", i))
+		}
+		names[f.Name] = true
+	}
+
+	if gs.StartBlock != nil && *gs.StartBlock < 0 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*gs.StartBlock, 0, math.MaxInt64, "startBlock"))
+	}
+	if gs.EndBlock != nil {
+		start := int64(0)
+		if gs.StartBlock != nil && *gs.StartBlock > 0 {
+			start = *gs.StartBlock
+		}
+		if *gs.EndBlock < start {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(*gs.EndBlock, start, math.MaxInt64, "endBlock"))
+		}
+	}
 
 	// Validate sink
 	errs = errs.Also(gs.Sink.Validate(ctx).ViaField("sink"))
 
 	return errs
 }
+
+// familyValidator checks the settings specific to the chains of a family.
+type familyValidator struct {
+	// validate checks the settings of a source of the family, other than
+	// its endpoints.
+	validate func(ctx context.Context, gs *BlockchainSourceSpec) *apis.FieldError
+	// contracts is set for the families whose sources may subscribe to
+	// contract logs.
+	contracts bool
+	// schemes are the schemes the URLs of the endpoints may have in any
+	// mode, or nil when they depend on the mode. nodes names the nodes in
+	// errors.
+	schemes []string
+	nodes   string
+}
+
+// familyValidators are the validators of the families, by family.
+var familyValidators = map[ChainFamily]familyValidator{
+	ChainFamilyEVM: {
+		validate:  validateEVMSpec,
+		contracts: true,
+	},
+	ChainFamilyBitcoin: {
+		validate: validateBitcoinSpec,
+		// Streaming sources read blocks from the endpoints as well.
+		schemes: httpSchemes,
+		nodes:   "bitcoin nodes",
+	},
+	ChainFamilyFabric: {
+		validate: validateFabricSpec,
+		schemes:  grpcSchemes,
+		nodes:    "fabric peers",
+	},
+	ChainFamilyTendermint: {
+		validate: validateTendermintSpec,
+		// Nodes are always read from their /websocket endpoint.
+		schemes: webSocketSchemes,
+		nodes:   "tendermint nodes",
+	},
+	ChainFamilySolana: {
+		validate: validateSolanaSpec,
+		// The PubSub endpoint is derived from the HTTP one.
+		schemes: httpSchemes,
+		nodes:   "solana nodes",
+	},
+	ChainFamilyBeacon: {
+		validate: validateBeaconSpec,
+		schemes:  httpSchemes,
+		nodes:    "beacon nodes",
+	},
+}
+
+// validateEndpoint checks an endpoint of a source in the given mode.
+func (v *familyValidator) validateEndpoint(ctx context.Context, mode IngestionMode, e *RPCEndpoint) *apis.FieldError {
+	if v.schemes == nil {
+		return e.Validate(ctx, mode)
+	}
+	return e.validate(ctx, v.schemes,
+		fmt.Sprintf("URL scheme must be %s or %s for %s", v.schemes[0], v.schemes[1], v.nodes))
+}
+
+// familyOption tells whether the options of a family are set.
+type familyOption struct {
+	family ChainFamily
+	set    bool
+}
+
+// familyOptions returns whether the options of each family, in the field
+// named after it, are set.
+func (gs *BlockchainSourceSpec) familyOptions() []familyOption {
+	return []familyOption{
+		{ChainFamilyBitcoin, gs.Bitcoin != nil},
+		{ChainFamilyFabric, gs.Fabric != nil},
+		{ChainFamilyTendermint, gs.Tendermint != nil},
+		{ChainFamilySolana, gs.Solana != nil},
+		{ChainFamilyBeacon, gs.Beacon != nil},
+	}
+}
+
+func validateEVMSpec(ctx context.Context, gs *BlockchainSourceSpec) *apis.FieldError {
+	if gs.ChainID == "" {
+		return nil
+	}
+	if _, err := strconv.ParseUint(gs.ChainID, 10, 64); err != nil {
+		return apis.ErrInvalidValue(gs.ChainID, "chainID", "EVM chain IDs must be decimal integers")
+	}
+	return nil
+}
+
+func validateBitcoinSpec(ctx context.Context, gs *BlockchainSourceSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	if gs.Mode == IngestionModeStreaming && (gs.Bitcoin == nil || gs.Bitcoin.ZMQEndpoint == "") {
+		errs = errs.Also(apis.ErrMissingField("bitcoin.zmqEndpoint"))
+	}
+	if gs.Bitcoin != nil {
+		errs = errs.Also(gs.Bitcoin.Validate(ctx).ViaField("bitcoin"))
+	}
+	if gs.Finality != nil && (gs.Finality.Level == FinalityLevelSafe || gs.Finality.Level == FinalityLevelFinalized) {
+		errs = errs.Also(apis.ErrInvalidValue(gs.Finality.Level, "finality.level",
+			"bitcoin blocks are only final after a number of confirmations"))
+	}
+	return errs
+}
+
+func validateFabricSpec(ctx context.Context, gs *BlockchainSourceSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	if gs.ChainID == "" && gs.Network == "" {
+		errs = errs.Also(apis.ErrMissingField("chainID"))
+	}
+	if gs.Fabric == nil {
+		errs = errs.Also(apis.ErrMissingField("fabric"))
+	} else {
+		errs = errs.Also(gs.Fabric.Validate(ctx).ViaField("fabric"))
+	}
+	if gs.Finality != nil && (gs.Finality.Level == FinalityLevelConfirmed || gs.Finality.Level == FinalityLevelSafe) {
+		errs = errs.Also(apis.ErrInvalidValue(gs.Finality.Level, "finality.level",
+			"fabric blocks are final once committed"))
+	}
+	for i := range gs.Endpoints {
+		if gs.Endpoints[i].Credentials != nil {
+			// Peers authenticate the identity signing the requests.
+			errs = errs.Also(apis.ErrDisallowedFields("credentials").ViaFieldIndex("endpoints", i))
+		}
+	}
+	return errs
+}
+
+func validateTendermintSpec(ctx context.Context, gs *BlockchainSourceSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	if gs.Tendermint != nil {
+		errs = errs.Also(gs.Tendermint.Validate(ctx).ViaField("tendermint"))
+	}
+	if gs.Finality != nil && (gs.Finality.Level == FinalityLevelConfirmed || gs.Finality.Level == FinalityLevelSafe) {
+		errs = errs.Also(apis.ErrInvalidValue(gs.Finality.Level, "finality.level",
+			"tendermint blocks are final once committed"))
+	}
+	return errs
+}
+
+func validateSolanaSpec(ctx context.Context, gs *BlockchainSourceSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	if gs.Solana != nil {
+		errs = errs.Also(gs.Solana.Validate(ctx).ViaField("solana"))
+	}
+	if gs.Finality != nil && gs.Finality.Level == FinalityLevelSafe {
+		errs = errs.Also(apis.ErrInvalidValue(gs.Finality.Level, "finality.level",
+			"solana commitment levels are processed (latest), confirmed and finalized"))
+	}
+	if gs.Finality != nil && gs.Finality.Confirmations != nil {
+		errs = errs.Also(apis.ErrDisallowedFields("finality.confirmations"))
+	}
+	return errs
+}
+
+func validateBeaconSpec(ctx context.Context, gs *BlockchainSourceSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	if gs.ChainID != "" {
+		errs = errs.Also(validateBeaconChainID(gs.ChainID))
+	}
+	if gs.Beacon != nil {
+		errs = errs.Also(gs.Beacon.Validate(ctx).ViaField("beacon"))
+	}
+	if gs.Finality != nil && gs.Finality.Level != "" && gs.Finality.Level != FinalityLevelLatest {
+		errs = errs.Also(apis.ErrInvalidValue(gs.Finality.Level, "finality.level",
+			"beacon events are emitted as the node notifies them, finality is reported by finalized_checkpoint events"))
+	}
+	return errs
+}
+
+// validateBeaconChainID checks the chain ID of a beacon chain, the one of its
+// deposit contract.
+func validateBeaconChainID(chainID string) *apis.FieldError {
+	if _, err := strconv.ParseUint(chainID, 10, 64); err != nil {
+		return apis.ErrInvalidValue(chainID, "chainID", "beacon chain IDs are the decimal chain IDs of their deposit contract")
+	}
+	return nil
+}
+
+// httpSchemes are the schemes of the URLs of HTTP endpoints.
+var httpSchemes = []string{"http", "https"}
+
+// webSocketSchemes are the schemes of the URLs of WebSocket endpoints.
+var webSocketSchemes = []string{"ws", "wss"}
+
+// grpcSchemes are the schemes of the URLs of gRPC endpoints.
+var grpcSchemes = []string{"grpc", "grpcs"}
+
+func (e *RPCEndpoint) Validate(ctx context.Context, mode IngestionMode) *apis.FieldError {
+	schemes := httpSchemes
+	if mode == IngestionModeStreaming {
+		schemes = webSocketSchemes
+	}
+	return e.validate(ctx, schemes,
+		fmt.Sprintf("URL scheme must be %s or %s in %s mode", schemes[0], schemes[1], modeOrDefault(mode)))
+}
+
+// validate checks the endpoint, whose URL must have one of the given
+// schemes.
+func (e *RPCEndpoint) validate(ctx context.Context, schemes []string, schemeDetails string) *apis.FieldError {
+	var errs *apis.FieldError
+
+	if e.URL == "" {
+		errs = errs.Also(apis.ErrMissingField("url"))
+	} else if u, err := url.Parse(e.URL); err != nil || u.Host == "" {
+		errs = errs.Also(apis.ErrInvalidValue(e.URL, "url"))
+	} else if !hasScheme(u, schemes) {
+		errs = errs.Also(apis.ErrInvalidValue(e.URL, "url", schemeDetails))
+	}
+
+	if e.Priority < 0 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(e.Priority, 0, math.MaxInt32, "priority"))
+	}
+
+	if e.Credentials != nil {
+		errs = errs.Also(e.Credentials.Validate(ctx).ViaField("credentials"))
+	}
+
+	return errs
+}
+
+func (s *SecretValueFromSource) Validate(ctx context.Context) *apis.FieldError {
+	if s.SecretKeyRef == nil {
+		return apis.ErrMissingField("secretKeyRef")
+	}
+	return validateKeyRef(s.SecretKeyRef.Name, s.SecretKeyRef.Key).ViaField("secretKeyRef")
+}
+
+// validateKeyRef checks that a reference to a key of a Secret or ConfigMap
+// names both.
+func validateKeyRef(name, key string) *apis.FieldError {
+	var errs *apis.FieldError
+	if name == "" {
+		errs = errs.Also(apis.ErrMissingField("name"))
+	}
+	if key == "" {
+		errs = errs.Also(apis.ErrMissingField("key"))
+	}
+	return errs
+}
+
+func hasScheme(u *url.URL, schemes []string) bool {
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+func modeOrDefault(mode IngestionMode) IngestionMode {
+	if mode == "" {
+		return IngestionModePolling
+	}
+	return mode
+}
+
+func (f *Finality) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	switch f.Level {
+	case "", FinalityLevelLatest, FinalityLevelConfirmed, FinalityLevelSafe, FinalityLevelFinalized:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(f.Level, "level"))
+	}
+
+	if f.Confirmations != nil {
+		if f.Level != FinalityLevelConfirmed {
+			errs = errs.Also(apis.ErrDisallowedFields("confirmations"))
+		} else if *f.Confirmations < 1 || *f.Confirmations > MaxConfirmations {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(*f.Confirmations, 1, MaxConfirmations, "confirmations"))
+		}
+	}
+
+	return errs
+}
+
+func (cs *ContractSubscription) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	for i, addr := range cs.Addresses {
+		if _, err := evm.ParseAddress(addr); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(addr, "addresses", i))
+		} else if !isSingleCase(addr[2:]) && !evm.IsChecksumAddress(addr) {
+			errs = errs.Also(apis.ErrInvalidValue(addr, apis.CurrentField,
+				"mixed-case address does not match its EIP-55 checksum").ViaFieldIndex("addresses", i))
+		}
+	}
+
+	var abi *evm.ABI
+	if cs.ABI != nil {
+		var fe *apis.FieldError
+		abi, fe = cs.ABI.parse()
+		errs = errs.Also(fe.ViaField("abi"))
+	}
+
+	for i, sig := range cs.EventSignatures {
+		switch {
+		case strings.HasPrefix(sig, "0x"):
+			if !isTopic(sig) {
+				errs = errs.Also(apis.ErrInvalidValue(sig, apis.CurrentField,
+					"event hashes must be 32 bytes of hex").ViaFieldIndex("eventSignatures", i))
+			}
+		case strings.Contains(sig, "("):
+			if !strings.HasSuffix(sig, ")") {
+				errs = errs.Also(apis.ErrInvalidArrayValue(sig, "eventSignatures", i))
+			}
+		case cs.ABI == nil:
+			errs = errs.Also(apis.ErrInvalidValue(sig, apis.CurrentField,
+				"event names require an ABI").ViaFieldIndex("eventSignatures", i))
+		case abi != nil && abi.EventByName(sig) == nil:
+			errs = errs.Also(apis.ErrInvalidValue(sig, apis.CurrentField,
+				"event is not declared by the ABI").ViaFieldIndex("eventSignatures", i))
+		}
+	}
+
+	if len(cs.Topics) > maxTopics {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(len(cs.Topics), 0, maxTopics, "topics"))
+	}
+	for i, position := range cs.Topics {
+		for j, topic := range position {
+			if !isTopic(topic) {
+				errs = errs.Also(apis.ErrInvalidValue(topic, apis.CurrentField,
+					"topics must be 32 bytes of hex").ViaIndex(j).ViaFieldIndex("topics", i))
+			}
+		}
+	}
+
+	return errs
+}
+
+func (b *BitcoinOptions) Validate(ctx context.Context) *apis.FieldError {
+	if b.ZMQEndpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(b.ZMQEndpoint)
+	if err != nil || u.Scheme != "tcp" || u.Hostname() == "" || u.Port() == "" {
+		return apis.ErrInvalidValue(b.ZMQEndpoint, "zmqEndpoint", "ZMQ endpoints must be tcp://host:port")
+	}
+	return nil
+}
+
+func (f *FabricOptions) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+
+	switch f.BlockType {
+	case "", FabricBlockTypeFull, FabricBlockTypeFiltered:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(f.BlockType, "blockType"))
+	}
+
+	errs = errs.Also(f.Identity.MSPID.Validate(ctx).ViaField("identity", "mspID"))
+	errs = errs.Also(f.Identity.Certificate.Validate(ctx).ViaField("identity", "certificate"))
+	errs = errs.Also(f.Identity.PrivateKey.Validate(ctx).ViaField("identity", "privateKey"))
+	if f.TLSRootCertificate != nil {
+		errs = errs.Also(f.TLSRootCertificate.Validate(ctx).ViaField("tlsRootCertificate"))
+	}
+
+	for i := range f.ChaincodeEvents {
+		errs = errs.Also(f.ChaincodeEvents[i].Validate(ctx).ViaFieldIndex("chaincodeEvents", i))
+	}
+	return errs
+}
+
+func (s *ChaincodeEventSubscription) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	if s.ChaincodeName == "" {
+		errs = errs.Also(apis.ErrMissingField("chaincodeName"))
+	}
+	if _, err := regexp.Compile(s.EventName); err != nil {
+		errs = errs.Also(&apis.FieldError{
+			Message: "invalid regular expression",
+			Paths:   []string{"eventName"},
+			Details: err.Error(),
+		})
+	}
+	return errs
+}
+
+func (t *TendermintOptions) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, query := range t.Queries {
+		q, err := tendermint.ParseQuery(query)
+		if err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: "invalid query",
+				Paths:   []string{apis.CurrentField},
+				Details: err.Error(),
+			}).ViaFieldIndex("queries", i)
+			continue
+		}
+		switch q.EventType() {
+		case tendermint.EventNewBlock, tendermint.EventTx:
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(query, apis.CurrentField,
+				"queries must select NewBlock or Tx events with a tm.event condition").ViaFieldIndex("queries", i))
+		}
+	}
+	return errs
+}
+
+func (s *SolanaOptions) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, addr := range s.Mentions {
+		if _, err := solana.ParseAddress(addr); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(addr, "mentions", i))
+		}
+	}
+	for i, addr := range s.Accounts {
+		if _, err := solana.ParseAddress(addr); err != nil {
+			errs = errs.Also(apis.ErrInvalidArrayValue(addr, "accounts", i))
+		}
+	}
+	return errs
+}
+
+func (b *BeaconOptions) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	seen := make(map[BeaconTopic]bool, len(b.Topics))
+	for i, topic := range b.Topics {
+		switch {
+		case !isBeaconTopic(topic):
+			errs = errs.Also(apis.ErrInvalidArrayValue(topic, "topics", i))
+		case seen[topic]:
+			errs = errs.Also(apis.ErrInvalidValue(topic, apis.CurrentField,
+				"topics must be unique").ViaFieldIndex("topics", i))
+		}
+		seen[topic] = true
+	}
+	return errs
+}
+
+func isBeaconTopic(topic BeaconTopic) bool {
+	for _, t := range BeaconTopics {
+		if topic == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *EventFilter) Validate(ctx context.Context) *apis.FieldError {
+	switch {
+	case f.CESQL == "" && f.CEL == "":
+		return apis.ErrMissingOneOf("cesql", "cel")
+	case f.CESQL != "" && f.CEL != "":
+		return apis.ErrMultipleOneOf("cesql", "cel")
+	}
+
+	if _, err := f.Parse(); err != nil {
+		field := "cesql"
+		if f.CEL != "" {
+			field = "cel"
+		}
+		return &apis.FieldError{
+			Message: "invalid expression",
+			Paths:   []string{field},
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
+
+// parse parses the inline ABI, returning nil when it is read from a
+// ConfigMap.
+func (a *ContractABI) parse() (*evm.ABI, *apis.FieldError) {
+	if a.Inline == "" && a.ConfigMapKeyRef == nil {
+		return nil, apis.ErrMissingOneOf("inline", "configMapKeyRef")
+	}
+	if a.Inline != "" && a.ConfigMapKeyRef != nil {
+		return nil, apis.ErrMultipleOneOf("inline", "configMapKeyRef")
+	}
+	if a.ConfigMapKeyRef != nil {
+		return nil, validateKeyRef(a.ConfigMapKeyRef.Name, a.ConfigMapKeyRef.Key).ViaField("configMapKeyRef")
+	}
+	abi, err := evm.ParseABI([]byte(a.Inline))
+	if err != nil {
+		return nil, &apis.FieldError{
+			Message: "invalid ABI",
+			Paths:   []string{"inline"},
+			Details: err.Error(),
+		}
+	}
+	return abi, nil
+}
+
+// isTopic reports whether s is a 0x-prefixed 32-byte hex string.
+func isTopic(s string) bool {
+	if len(s) != 2+2*32 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
+
+// isSingleCase reports whether the letters of s are all lowercase or all
+// uppercase, in which case an address carries no EIP-55 checksum.
+func isSingleCase(s string) bool {
+	return s == strings.ToLower(s) || s == strings.ToUpper(s)
+}
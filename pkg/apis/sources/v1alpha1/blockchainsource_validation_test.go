@@ -18,14 +18,43 @@ package v1alpha1
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/webhook/resourcesemantics"
 
 	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"knative.dev/eventing-blockchain/pkg/filter"
+)
+
+var (
+	testEndpoints   = []RPCEndpoint{{URL: "https://mainnet.example.com"}}
+	wsTestEndpoints = []RPCEndpoint{{URL: "wss://mainnet.example.com"}}
 )
 
+func testFabricOptions() *FabricOptions {
+	ref := func(key string) SecretValueFromSource {
+		return SecretValueFromSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "org1-user1"},
+				Key:                  key,
+			},
+		}
+	}
+	return &FabricOptions{
+		Identity: FabricIdentity{
+			MSPID:       ref("mspid"),
+			Certificate: ref("cert.pem"),
+			PrivateKey:  ref("key.pem"),
+		},
+	}
+}
+
 func TestBlockchainSourceValidation(t *testing.T) {
 	testCases := map[string]struct {
 		cr   resourcesemantics.GenericCRD
@@ -39,9 +68,890 @@ func TestBlockchainSourceValidation(t *testing.T) {
 				var errs *apis.FieldError
 				fe := apis.ErrGeneric("expected at least one, got none", "ref", "uri").ViaField("spec.sink")
 				errs = errs.Also(fe)
+				errs = errs.Also(apis.ErrMissingOneOf("spec.network", "spec.endpoints"))
+				return errs
+			}(),
+		},
+		"network": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Network: "cosmoshub",
+					ChainID: "cosmoshub-4",
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"network and endpoints": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Network:   "mainnet",
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMultipleOneOf("spec.network", "spec.endpoints"),
+		},
+		"invalid family": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    "cardano",
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("cardano", "spec.family"),
+		},
+		"evm chain": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyEVM,
+					ChainID:   "11155111",
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"invalid evm chain ID": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					ChainID:   "mainnet",
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("mainnet", "spec.chainID", "EVM chain IDs must be decimal integers"),
+		},
+		"contracts on a non-evm chain": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyTendermint,
+					ChainID:   "cosmoshub-4",
+					Contracts: &ContractSubscription{},
+					Endpoints: wsTestEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.contracts"),
+		},
+		"streaming bitcoin": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyBitcoin,
+					ChainID:   "main",
+					Mode:      IngestionModeStreaming,
+					Endpoints: testEndpoints,
+					Bitcoin:   &BitcoinOptions{ZMQEndpoint: "tcp://bitcoind:28332"},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"streaming bitcoin without zmq": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyBitcoin,
+					Mode:      IngestionModeStreaming,
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMissingField("spec.bitcoin.zmqEndpoint"),
+		},
+		"bitcoin over websocket": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyBitcoin,
+					Mode:      IngestionModeStreaming,
+					Endpoints: []RPCEndpoint{{URL: "wss://bitcoind.example.com"}},
+					Bitcoin:   &BitcoinOptions{ZMQEndpoint: "tcp://bitcoind:28332"},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("wss://bitcoind.example.com", "spec.endpoints[0].url",
+				"URL scheme must be http or https for bitcoin nodes"),
+		},
+		"invalid zmq endpoint": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyBitcoin,
+					Endpoints: testEndpoints,
+					Bitcoin:   &BitcoinOptions{ZMQEndpoint: "ipc:///var/run/bitcoind"},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("ipc:///var/run/bitcoind", "spec.bitcoin.zmqEndpoint",
+				"ZMQ endpoints must be tcp://host:port"),
+		},
+		"finalized bitcoin blocks": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyBitcoin,
+					Endpoints: testEndpoints,
+					Finality:  &Finality{Level: FinalityLevelFinalized},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue(FinalityLevelFinalized, "spec.finality.level",
+				"bitcoin blocks are only final after a number of confirmations"),
+		},
+		"bitcoin options on an evm chain": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyEVM,
+					Endpoints: testEndpoints,
+					Bitcoin:   &BitcoinOptions{ZMQEndpoint: "tcp://bitcoind:28332"},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.bitcoin"),
+		},
+		"fabric": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyFabric,
+					ChainID:   "mychannel",
+					Endpoints: []RPCEndpoint{{URL: "grpcs://peer0.org1.example.com:7051"}},
+					Fabric:    testFabricOptions(),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"fabric without channel nor options": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyFabric,
+					Endpoints: []RPCEndpoint{{URL: "grpc://peer0:7051"}},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMissingField("spec.chainID", "spec.fabric"),
+		},
+		"fabric over http": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:  ChainFamilyFabric,
+					ChainID: "mychannel",
+					Endpoints: []RPCEndpoint{{
+						URL: "https://peer0:7051",
+						Credentials: &SecretValueFromSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "peer"},
+								Key:                  "token",
+							},
+						},
+					}},
+					Fabric: testFabricOptions(),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("https://peer0:7051", "spec.endpoints[0].url",
+				"URL scheme must be grpc or grpcs for fabric peers").Also(
+				apis.ErrDisallowedFields("spec.endpoints[0].credentials")),
+		},
+		"invalid fabric options": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyFabric,
+					ChainID:   "mychannel",
+					Endpoints: []RPCEndpoint{{URL: "grpc://peer0:7051"}},
+					Finality:  &Finality{Level: FinalityLevelConfirmed},
+					Fabric: &FabricOptions{
+						BlockType: "private",
+						Identity: FabricIdentity{
+							MSPID:       SecretValueFromSource{},
+							Certificate: testFabricOptions().Identity.Certificate,
+							PrivateKey:  testFabricOptions().Identity.PrivateKey,
+						},
+						ChaincodeEvents: []ChaincodeEventSubscription{
+							{ChaincodeName: "basic", EventName: "Transfer|Approval"},
+							{EventName: "Transfer("},
+						},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: func() *apis.FieldError {
+				var errs *apis.FieldError
+				errs = errs.Also(apis.ErrInvalidValue("private", "spec.fabric.blockType"))
+				errs = errs.Also(apis.ErrMissingField("spec.fabric.identity.mspID.secretKeyRef"))
+				errs = errs.Also(apis.ErrMissingField("spec.fabric.chaincodeEvents[1].chaincodeName"))
+				errs = errs.Also(&apis.FieldError{
+					Message: "invalid regular expression",
+					Paths:   []string{"spec.fabric.chaincodeEvents[1].eventName"},
+					Details: "error parsing regexp: missing closing ): `Transfer(`",
+				})
+				errs = errs.Also(apis.ErrInvalidValue(FinalityLevelConfirmed, "spec.finality.level",
+					"fabric blocks are final once committed"))
+				return errs
+			}(),
+		},
+		"fabric options on an evm chain": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyEVM,
+					Endpoints: testEndpoints,
+					Fabric:    testFabricOptions(),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.fabric"),
+		},
+		"tendermint": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyTendermint,
+					ChainID:   "cosmoshub-4",
+					Endpoints: []RPCEndpoint{{URL: "wss://rpc.cosmos.example.com/websocket"}},
+					Tendermint: &TendermintOptions{
+						Queries: []string{"tm.event='Tx' AND transfer.recipient='cosmos1bob'", "tm.event = 'NewBlock'"},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"invalid tendermint options": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyTendermint,
+					Endpoints: []RPCEndpoint{{URL: "https://rpc.cosmos.example.com"}},
+					Finality:  &Finality{Level: FinalityLevelSafe},
+					Tendermint: &TendermintOptions{
+						Queries: []string{"tm.event='Tx' OR tm.event='NewBlock'", "transfer.recipient='cosmos1bob'"},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: func() *apis.FieldError {
+				var errs *apis.FieldError
+				errs = errs.Also(&apis.FieldError{
+					Message: "invalid query",
+					Paths:   []string{"spec.tendermint.queries[0]"},
+					Details: `invalid query "tm.event='Tx' OR tm.event='NewBlock'": expected AND at offset 14`,
+				})
+				errs = errs.Also(apis.ErrInvalidValue("transfer.recipient='cosmos1bob'", "spec.tendermint.queries[1]",
+					"queries must select NewBlock or Tx events with a tm.event condition"))
+				errs = errs.Also(apis.ErrInvalidValue(FinalityLevelSafe, "spec.finality.level",
+					"tendermint blocks are final once committed"))
+				errs = errs.Also(apis.ErrInvalidValue("https://rpc.cosmos.example.com", "spec.endpoints[0].url",
+					"URL scheme must be ws or wss for tendermint nodes"))
+				return errs
+			}(),
+		},
+		"tendermint options on an evm chain": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints:  testEndpoints,
+					Tendermint: &TendermintOptions{},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.tendermint"),
+		},
+		"solana": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilySolana,
+					ChainID:   "mainnet-beta",
+					Endpoints: []RPCEndpoint{{URL: "https://api.mainnet-beta.solana.com"}},
+					Finality:  &Finality{Level: FinalityLevelFinalized},
+					Solana: &SolanaOptions{
+						Mentions: []string{"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"},
+						Accounts: []string{"Vote111111111111111111111111111111111111111"},
+						Slots:    true,
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"invalid solana options": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilySolana,
+					Endpoints: []RPCEndpoint{{URL: "wss://api.mainnet-beta.solana.com"}},
+					Finality:  &Finality{Level: FinalityLevelSafe},
+					Solana: &SolanaOptions{
+						Mentions: []string{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+						Accounts: []string{"Vote1111"},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: func() *apis.FieldError {
+				var errs *apis.FieldError
+				errs = errs.Also(apis.ErrInvalidArrayValue("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "spec.solana.mentions", 0))
+				errs = errs.Also(apis.ErrInvalidArrayValue("Vote1111", "spec.solana.accounts", 0))
+				errs = errs.Also(apis.ErrInvalidValue(FinalityLevelSafe, "spec.finality.level",
+					"solana commitment levels are processed (latest), confirmed and finalized"))
+				errs = errs.Also(apis.ErrInvalidValue("wss://api.mainnet-beta.solana.com", "spec.endpoints[0].url",
+					"URL scheme must be http or https for solana nodes"))
+				return errs
+			}(),
+		},
+		"solana confirmations": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilySolana,
+					Endpoints: []RPCEndpoint{{URL: "https://api.mainnet-beta.solana.com"}},
+					Finality:  &Finality{Level: FinalityLevelConfirmed, Confirmations: ptrInt64(32)},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.finality.confirmations"),
+		},
+		"solana options on an evm chain": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Solana:    &SolanaOptions{},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.solana"),
+		},
+		"beacon": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyBeacon,
+					ChainID:   "1",
+					Endpoints: []RPCEndpoint{{URL: "http://beacon:5052"}},
+					Beacon: &BeaconOptions{
+						Topics: []BeaconTopic{BeaconTopicHead, BeaconTopicFinalizedCheckpoint},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"invalid beacon options": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Family:    ChainFamilyBeacon,
+					ChainID:   "mainnet",
+					Endpoints: []RPCEndpoint{{URL: "ws://beacon:5052"}},
+					Finality:  &Finality{Level: FinalityLevelFinalized},
+					Beacon: &BeaconOptions{
+						Topics: []BeaconTopic{BeaconTopicHead, "blob_sidecar", BeaconTopicHead},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: func() *apis.FieldError {
+				var errs *apis.FieldError
+				errs = errs.Also(apis.ErrInvalidValue("mainnet", "spec.chainID",
+					"beacon chain IDs are the decimal chain IDs of their deposit contract"))
+				errs = errs.Also(apis.ErrInvalidArrayValue("blob_sidecar", "spec.beacon.topics", 1))
+				errs = errs.Also(apis.ErrInvalidValue(BeaconTopicHead, apis.CurrentField,
+					"topics must be unique").ViaFieldIndex("spec.beacon.topics", 2))
+				errs = errs.Also(apis.ErrInvalidValue(FinalityLevelFinalized, "spec.finality.level",
+					"beacon events are emitted as the node notifies them, finality is reported by finalized_checkpoint events"))
+				errs = errs.Also(apis.ErrInvalidValue("ws://beacon:5052", "spec.endpoints[0].url",
+					"URL scheme must be http or https for beacon nodes"))
 				return errs
 			}(),
 		},
+		"beacon options on an evm chain": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Beacon:    &BeaconOptions{},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.beacon"),
+		},
+		"invalid mode": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Mode:      "pushing",
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("pushing", "spec.mode"),
+		},
+		"streaming mode": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Mode:      IngestionModeStreaming,
+					Endpoints: wsTestEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"invalid finality level": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Finality:  &Finality{Level: "justified"},
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("justified", "spec.finality.level"),
+		},
+		"confirmations without confirmed level": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Finality: &Finality{
+						Level:         FinalityLevelSafe,
+						Confirmations: ptrInt64(3),
+					},
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrDisallowedFields("spec.finality.confirmations"),
+		},
+		"no confirmations": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(0),
+					},
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrOutOfBoundsValue(0, 1, MaxConfirmations, "spec.finality.confirmations"),
+		},
+		"confirmed finality": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(12),
+					},
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"contract abi without source": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Contracts: &ContractSubscription{
+						ABI: &ContractABI{},
+					},
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMissingOneOf("inline", "configMapKeyRef").ViaField("spec.contracts.abi"),
+		},
+		"contract abi with both sources": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Contracts: &ContractSubscription{
+						ABI: &ContractABI{
+							Inline:          "[]",
+							ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "abi.json"},
+						},
+					},
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMultipleOneOf("inline", "configMapKeyRef").ViaField("spec.contracts.abi"),
+		},
+		"contract subscription": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Contracts: &ContractSubscription{
+						Addresses:       []string{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+						EventSignatures: []string{"Transfer(address,address,uint256)"},
+						ABI: &ContractABI{
+							ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "abis"},
+								Key:                  "abi.json",
+							},
+						},
+					},
+					Endpoints: testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"negative start block": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					StartBlock: ptrInt64(-1),
+					Endpoints:  testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrOutOfBoundsValue(-1, 0, math.MaxInt64, "spec.startBlock"),
+		},
+		"end block before start block": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					StartBlock: ptrInt64(100),
+					EndBlock:   ptrInt64(99),
+					Endpoints:  testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrOutOfBoundsValue(99, 100, math.MaxInt64, "spec.endBlock"),
+		},
+		"block range": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					StartBlock: ptrInt64(100),
+					EndBlock:   ptrInt64(100),
+					Endpoints:  testEndpoints,
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"endpoints": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: []RPCEndpoint{{
+						URL: "https://mainnet.example.com",
+					}, {
+						URL:      "http://node.default.svc:8545",
+						Priority: 1,
+						Credentials: &SecretValueFromSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "node"},
+								Key:                  "authorization",
+							},
+						},
+					}},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+		},
+		"invalid endpoints": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: []RPCEndpoint{{
+						Priority: -1,
+					}, {
+						URL:         "://",
+						Credentials: &SecretValueFromSource{},
+					}},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMissingField("spec.endpoints[0].url").
+				Also(apis.ErrOutOfBoundsValue(-1, 0, math.MaxInt32, "spec.endpoints[0].priority")).
+				Also(apis.ErrInvalidValue("://", "spec.endpoints[1].url")).
+				Also(apis.ErrMissingField("spec.endpoints[1].credentials.secretKeyRef")),
+		},
+		"http endpoint when streaming": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Mode:      IngestionModeStreaming,
+					Endpoints: []RPCEndpoint{{URL: "https://mainnet.example.com"}},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("https://mainnet.example.com", "spec.endpoints[0].url",
+				"URL scheme must be ws or wss in streaming mode"),
+		},
+		"endpoint credentials without key": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: []RPCEndpoint{{
+						URL: "https://mainnet.example.com",
+						Credentials: &SecretValueFromSource{
+							SecretKeyRef: &corev1.SecretKeySelector{},
+						},
+					}},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMissingField("spec.endpoints[0].credentials.secretKeyRef.name").
+				Also(apis.ErrMissingField("spec.endpoints[0].credentials.secretKeyRef.key")),
+		},
+		"too many confirmations": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Finality: &Finality{
+						Level:         FinalityLevelConfirmed,
+						Confirmations: ptrInt64(MaxConfirmations + 1),
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrOutOfBoundsValue(MaxConfirmations+1, 1, MaxConfirmations, "spec.finality.confirmations"),
+		},
+		"contract addresses": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Contracts: &ContractSubscription{
+						Addresses: []string{
+							"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+							"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+							"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+							"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD",
+							"0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea",
+							"5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+						},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "spec.contracts.addresses[3]",
+				"mixed-case address does not match its EIP-55 checksum").
+				Also(apis.ErrInvalidArrayValue("0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", "spec.contracts.addresses", 4)).
+				Also(apis.ErrInvalidArrayValue("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "spec.contracts.addresses", 5)),
+		},
+		"filters": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Filters: []EventFilter{{
+						Name:  "transfers",
+						CESQL: "eventname = 'Transfer'",
+					}, {
+						CEL: `int(data.args.value) > 1000000`,
+					}},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: nil,
+		},
+		"invalid filters": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Filters: []EventFilter{{
+						Name: "transfers",
+					}, {
+						CESQL: "eventname = 'Transfer'",
+						CEL:   `event.eventname == "Transfer"`,
+					}, {
+						Name: "transfers",
+						CEL:  `event.eventname ==`,
+					}},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMissingOneOf("spec.filters[0].cesql", "spec.filters[0].cel").
+				Also(apis.ErrMultipleOneOf("spec.filters[1].cesql", "spec.filters[1].cel")).
+				Also(&apis.FieldError{
+					Message: "invalid expression",
+					Paths:   []string{"spec.filters[2].cel"},
+					Details: func() string {
+						_, err := filter.ParseCEL(`event.eventname ==`)
+						return err.Error()
+					}(),
+				}).
+				Also(apis.ErrInvalidValue("transfers", "spec.filters[2].name", "filter names must be unique")),
+		},
+		"contract topics": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Contracts: &ContractSubscription{
+						EventSignatures: []string{
+							"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+							"0xddf252ad",
+						},
+						Topics: [][]string{
+							nil,
+							{"0x0000000000000000000000005aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x1234"},
+						},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("0xddf252ad", "spec.contracts.eventSignatures[1]", "event hashes must be 32 bytes of hex").
+				Also(apis.ErrInvalidValue("0x1234", "spec.contracts.topics[1][1]", "topics must be 32 bytes of hex")),
+		},
+		"too many topics": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Contracts: &ContractSubscription{
+						Topics: [][]string{nil, nil, nil, nil, nil},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrOutOfBoundsValue(5, 0, 4, "spec.contracts.topics"),
+		},
+		"event names": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Contracts: &ContractSubscription{
+						EventSignatures: []string{"Transfer", "Approval", "Transfer(address"},
+						ABI: &ContractABI{
+							Inline: `[{"type": "event", "name": "Transfer", "inputs": [{"type": "address", "indexed": true}]}]`,
+						},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("Approval", "spec.contracts.eventSignatures[1]", "event is not declared by the ABI").
+				Also(apis.ErrInvalidArrayValue("Transfer(address", "spec.contracts.eventSignatures", 2)),
+		},
+		"event name without abi": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Contracts: &ContractSubscription{
+						EventSignatures: []string{"Transfer"},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("Transfer", "spec.contracts.eventSignatures[0]", "event names require an ABI"),
+		},
+		"invalid abi": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Contracts: &ContractSubscription{
+						ABI: &ContractABI{
+							Inline: `[{"type": "event", "name": "E", "inputs": [{"type": "uint7"}]}]`,
+						},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: &apis.FieldError{
+				Message: "invalid ABI",
+				Paths:   []string{"spec.contracts.abi.inline"},
+				Details: `invalid ABI: event E input 0: invalid type "uint7"`,
+			},
+		},
+		"abi config map without name": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints: testEndpoints,
+					Contracts: &ContractSubscription{
+						ABI: &ContractABI{
+							ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "abi.json"},
+						},
+					},
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrMissingField("spec.contracts.abi.configMapKeyRef.name"),
+		},
+		"invalid polling settings": {
+			cr: &BlockchainSource{
+				Spec: BlockchainSourceSpec{
+					Endpoints:    testEndpoints,
+					PollInterval: &metav1.Duration{},
+					MaxLogRange:  ptrInt64(0),
+					SourceSpec: duckv1.SourceSpec{
+						Sink: duckv1.Destination{URI: apis.HTTP("example")},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("0s", "spec.pollInterval", "poll interval must be positive").
+				Also(apis.ErrOutOfBoundsValue(0, 1, math.MaxInt64, "spec.maxLogRange")),
+		},
 	}
 
 	for n, test := range testCases {
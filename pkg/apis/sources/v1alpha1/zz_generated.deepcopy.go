@@ -22,10 +22,174 @@ limitations under the License.
 package v1alpha1
 
 import (
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BeaconOptions) DeepCopyInto(out *BeaconOptions) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]BeaconTopic, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BeaconOptions.
+func (in *BeaconOptions) DeepCopy() *BeaconOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(BeaconOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BitcoinOptions) DeepCopyInto(out *BitcoinOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitcoinOptions.
+func (in *BitcoinOptions) DeepCopy() *BitcoinOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(BitcoinOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockchainNetwork) DeepCopyInto(out *BlockchainNetwork) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockchainNetwork.
+func (in *BlockchainNetwork) DeepCopy() *BlockchainNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockchainNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlockchainNetwork) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockchainNetworkList) DeepCopyInto(out *BlockchainNetworkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BlockchainNetwork, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockchainNetworkList.
+func (in *BlockchainNetworkList) DeepCopy() *BlockchainNetworkList {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockchainNetworkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlockchainNetworkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockchainNetworkSpec) DeepCopyInto(out *BlockchainNetworkSpec) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]RPCEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxLogRange != nil {
+		in, out := &in.MaxLogRange, &out.MaxLogRange
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Finality != nil {
+		in, out := &in.Finality, &out.Finality
+		*out = new(Finality)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockchainNetworkSpec.
+func (in *BlockchainNetworkSpec) DeepCopy() *BlockchainNetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockchainNetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockchainNetworkStatus) DeepCopyInto(out *BlockchainNetworkStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]EndpointStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockchainNetworkStatus.
+func (in *BlockchainNetworkStatus) DeepCopy() *BlockchainNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockchainNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BlockchainSource) DeepCopyInto(out *BlockchainSource) {
 	*out = *in
@@ -90,16 +254,71 @@ func (in *BlockchainSourceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BlockchainSourceSpec) DeepCopyInto(out *BlockchainSourceSpec) {
 	*out = *in
-	if in.EventTypes != nil {
-		in, out := &in.EventTypes, &out.EventTypes
-		*out = make([]string, len(*in))
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]RPCEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxLogRange != nil {
+		in, out := &in.MaxLogRange, &out.MaxLogRange
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Contracts != nil {
+		in, out := &in.Contracts, &out.Contracts
+		*out = new(ContractSubscription)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bitcoin != nil {
+		in, out := &in.Bitcoin, &out.Bitcoin
+		*out = new(BitcoinOptions)
+		**out = **in
+	}
+	if in.Fabric != nil {
+		in, out := &in.Fabric, &out.Fabric
+		*out = new(FabricOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tendermint != nil {
+		in, out := &in.Tendermint, &out.Tendermint
+		*out = new(TendermintOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Solana != nil {
+		in, out := &in.Solana, &out.Solana
+		*out = new(SolanaOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Beacon != nil {
+		in, out := &in.Beacon, &out.Beacon
+		*out = new(BeaconOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = make([]EventFilter, len(*in))
 		copy(*out, *in)
 	}
-	in.AccessToken.DeepCopyInto(&out.AccessToken)
-	in.SecretToken.DeepCopyInto(&out.SecretToken)
-	if in.Secure != nil {
-		in, out := &in.Secure, &out.Secure
-		*out = new(bool)
+	if in.Finality != nil {
+		in, out := &in.Finality, &out.Finality
+		*out = new(Finality)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartBlock != nil {
+		in, out := &in.StartBlock, &out.StartBlock
+		*out = new(int64)
+		**out = **in
+	}
+	if in.EndBlock != nil {
+		in, out := &in.EndBlock, &out.EndBlock
+		*out = new(int64)
 		**out = **in
 	}
 	in.SourceSpec.DeepCopyInto(&out.SourceSpec)
@@ -120,6 +339,16 @@ func (in *BlockchainSourceSpec) DeepCopy() *BlockchainSourceSpec {
 func (in *BlockchainSourceStatus) DeepCopyInto(out *BlockchainSourceStatus) {
 	*out = *in
 	in.SourceStatus.DeepCopyInto(&out.SourceStatus)
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		*out = new(Checkpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingestion != nil {
+		in, out := &in.Ingestion, &out.Ingestion
+		*out = new(IngestionStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -133,12 +362,269 @@ func (in *BlockchainSourceStatus) DeepCopy() *BlockchainSourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChaincodeEventSubscription) DeepCopyInto(out *ChaincodeEventSubscription) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChaincodeEventSubscription.
+func (in *ChaincodeEventSubscription) DeepCopy() *ChaincodeEventSubscription {
+	if in == nil {
+		return nil
+	}
+	out := new(ChaincodeEventSubscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Checkpoint) DeepCopyInto(out *Checkpoint) {
+	*out = *in
+	if in.LogIndex != nil {
+		in, out := &in.LogIndex, &out.LogIndex
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Epoch != nil {
+		in, out := &in.Epoch, &out.Epoch
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Checkpoint.
+func (in *Checkpoint) DeepCopy() *Checkpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(Checkpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContractABI) DeepCopyInto(out *ContractABI) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContractABI.
+func (in *ContractABI) DeepCopy() *ContractABI {
+	if in == nil {
+		return nil
+	}
+	out := new(ContractABI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContractSubscription) DeepCopyInto(out *ContractSubscription) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EventSignatures != nil {
+		in, out := &in.EventSignatures, &out.EventSignatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.ABI != nil {
+		in, out := &in.ABI, &out.ABI
+		*out = new(ContractABI)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContractSubscription.
+func (in *ContractSubscription) DeepCopy() *ContractSubscription {
+	if in == nil {
+		return nil
+	}
+	out := new(ContractSubscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointStatus) DeepCopyInto(out *EndpointStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointStatus.
+func (in *EndpointStatus) DeepCopy() *EndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventFilter) DeepCopyInto(out *EventFilter) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventFilter.
+func (in *EventFilter) DeepCopy() *EventFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(EventFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricIdentity) DeepCopyInto(out *FabricIdentity) {
+	*out = *in
+	in.MSPID.DeepCopyInto(&out.MSPID)
+	in.Certificate.DeepCopyInto(&out.Certificate)
+	in.PrivateKey.DeepCopyInto(&out.PrivateKey)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricIdentity.
+func (in *FabricIdentity) DeepCopy() *FabricIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FabricOptions) DeepCopyInto(out *FabricOptions) {
+	*out = *in
+	in.Identity.DeepCopyInto(&out.Identity)
+	if in.TLSRootCertificate != nil {
+		in, out := &in.TLSRootCertificate, &out.TLSRootCertificate
+		*out = new(SecretValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChaincodeEvents != nil {
+		in, out := &in.ChaincodeEvents, &out.ChaincodeEvents
+		*out = make([]ChaincodeEventSubscription, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FabricOptions.
+func (in *FabricOptions) DeepCopy() *FabricOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(FabricOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Finality) DeepCopyInto(out *Finality) {
+	*out = *in
+	if in.Confirmations != nil {
+		in, out := &in.Confirmations, &out.Confirmations
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Finality.
+func (in *Finality) DeepCopy() *Finality {
+	if in == nil {
+		return nil
+	}
+	out := new(Finality)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestionStatus) DeepCopyInto(out *IngestionStatus) {
+	*out = *in
+	if in.LagSeconds != nil {
+		in, out := &in.LagSeconds, &out.LagSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.EmittedEvents != nil {
+		in, out := &in.EmittedEvents, &out.EmittedEvents
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestionStatus.
+func (in *IngestionStatus) DeepCopy() *IngestionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RPCEndpoint) DeepCopyInto(out *RPCEndpoint) {
+	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(SecretValueFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RPCEndpoint.
+func (in *RPCEndpoint) DeepCopy() *RPCEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(RPCEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretValueFromSource) DeepCopyInto(out *SecretValueFromSource) {
 	*out = *in
 	if in.SecretKeyRef != nil {
 		in, out := &in.SecretKeyRef, &out.SecretKeyRef
-		*out = new(v1.SecretKeySelector)
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 	return
@@ -153,3 +639,50 @@ func (in *SecretValueFromSource) DeepCopy() *SecretValueFromSource {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolanaOptions) DeepCopyInto(out *SolanaOptions) {
+	*out = *in
+	if in.Mentions != nil {
+		in, out := &in.Mentions, &out.Mentions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolanaOptions.
+func (in *SolanaOptions) DeepCopy() *SolanaOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolanaOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TendermintOptions) DeepCopyInto(out *TendermintOptions) {
+	*out = *in
+	if in.Queries != nil {
+		in, out := &in.Queries, &out.Queries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TendermintOptions.
+func (in *TendermintOptions) DeepCopy() *TendermintOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TendermintOptions)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package beacon implements the parts of the Beacon API of Ethereum
+// consensus layer nodes needed to follow a beacon chain: the resources
+// describing the chain and its blocks, and the server-sent events of the
+// /eth/v1/events stream.
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// HTTPError is returned when a node answers a request with an unexpected
+// HTTP status, e.g. when credentials are missing or a resource is unknown.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err tells that the requested resource does not
+// exist, such as the header of a slot without block.
+func IsNotFound(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHeader sets an HTTP header on every request, e.g. to pass credentials.
+func WithHeader(name, value string) Option {
+	return func(c *Client) {
+		c.header.Set(name, value)
+	}
+}
+
+// Client reads the Beacon API of a node.
+type Client struct {
+	url        string
+	header     http.Header
+	httpClient *http.Client
+}
+
+// NewClient returns a Client reading the Beacon API served at the given base
+// URL, e.g. http://localhost:5052.
+func NewClient(url string, opts ...Option) *Client {
+	c := &Client{
+		url:        strings.TrimSuffix(url, "/"),
+		header:     make(http.Header),
+		httpClient: cleanhttp.DefaultPooledClient(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// response is the envelope of the resources the Beacon API returns.
+type response struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// Get reads the resource at the given path, e.g. /eth/v1/beacon/genesis, and
+// unmarshals its data into result.
+func (c *Client) Get(ctx context.Context, path string, result interface{}) error {
+	resp, err := c.do(ctx, path, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var r response
+	if err := json.Unmarshal(body, &r); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	if err := json.Unmarshal(r.Data, result); err != nil {
+		return fmt.Errorf("failed to unmarshal the data of %s: %w", path, err)
+	}
+	return nil
+}
+
+// do sends a GET request for path, and returns the response when its status
+// is 200.
+func (c *Client) do(ctx context.Context, path, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request for %s failed: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("request for %s failed with %w", path, &HTTPError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))})
+	}
+	return resp, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package beacon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGet(t *testing.T) {
+	testCases := map[string]struct {
+		handler      http.HandlerFunc
+		want         *BlockHeader
+		wantErr      bool
+		wantNotFound bool
+	}{
+		"header": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/eth/v1/beacon/headers/head" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Authorization header = %q, want Bearer token", got)
+				}
+				w.Write([]byte(`{"execution_optimistic":false,"finalized":false,"data":{"root":"0xaa","canonical":true,` +
+					`"header":{"message":{"slot":"7","proposer_index":"3","parent_root":"0xbb","state_root":"0xcc","body_root":"0xdd"},"signature":"0xee"}}}`))
+			},
+			want: &BlockHeader{
+				Root:      "0xaa",
+				Canonical: true,
+				Header: SignedBeaconBlockHeader{
+					Message: BeaconBlockHeader{
+						Slot:          7,
+						ProposerIndex: 3,
+						ParentRoot:    "0xbb",
+						StateRoot:     "0xcc",
+						BodyRoot:      "0xdd",
+					},
+					Signature: "0xee",
+				},
+			},
+		},
+		"not found": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"code":404,"message":"NOT_FOUND: beacon block at slot 7"}`))
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		"unauthorized": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			wantErr: true,
+		},
+		"malformed response": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`not json`))
+			},
+			wantErr: true,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			got, err := NewClient(server.URL+"/", WithHeader("Authorization", "Bearer token")).Header(context.Background(), "head")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Header() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if IsNotFound(err) != tc.wantNotFound {
+				t.Errorf("IsNotFound(%v) = %v, want %v", err, !tc.wantNotFound, tc.wantNotFound)
+			}
+			if err != nil {
+				var httpErr *HTTPError
+				if n != "malformed response" && !errors.As(err, &httpErr) {
+					t.Errorf("Header() error = %v, want an HTTP error", err)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected header (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestSpec(t *testing.T) {
+	testCases := map[string]struct {
+		body    string
+		want    *Spec
+		wantErr bool
+	}{
+		"mainnet": {
+			body: `{"data":{"CONFIG_NAME":"mainnet","SECONDS_PER_SLOT":"12","SLOTS_PER_EPOCH":"32","DEPOSIT_CHAIN_ID":"1"}}`,
+			want: &Spec{SecondsPerSlot: 12, SlotsPerEpoch: 32},
+		},
+		"incomplete": {
+			body:    `{"data":{"CONFIG_NAME":"mainnet","SECONDS_PER_SLOT":"12"}}`,
+			wantErr: true,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			got, err := NewClient(server.URL).Spec(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Spec() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected spec (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
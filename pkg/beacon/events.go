@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package beacon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Topics of the events a node streams.
+const (
+	// TopicHead events are sent when the head of the chain changes.
+	TopicHead = "head"
+	// TopicBlock events are sent when a block is imported, whether it
+	// becomes the head or not.
+	TopicBlock = "block"
+	// TopicFinalizedCheckpoint events are sent when an epoch is finalized.
+	TopicFinalizedCheckpoint = "finalized_checkpoint"
+	// TopicChainReorg events are sent when the head changes to a block
+	// that does not descend from the previous head.
+	TopicChainReorg = "chain_reorg"
+	// TopicVoluntaryExit events are sent when a voluntary exit is
+	// received, before it is included in a block.
+	TopicVoluntaryExit = "voluntary_exit"
+)
+
+// maxEventSize bounds the size of the lines of the event stream.
+const maxEventSize = 1 << 20
+
+// Event is an event of the /eth/v1/events stream.
+type Event struct {
+	// Topic is the topic of the event, the name of the server-sent event.
+	Topic string
+	// Data is the JSON encoded data of the event.
+	Data json.RawMessage
+}
+
+// EventStream reads the events of the /eth/v1/events stream of a node.
+type EventStream struct {
+	body    io.Closer
+	scanner *bufio.Scanner
+}
+
+// Events opens the event stream of the node, for the given topics. The
+// stream is closed when ctx is done.
+func (c *Client) Events(ctx context.Context, topics []string) (*EventStream, error) {
+	path := "/eth/v1/events?topics=" + url.QueryEscape(strings.Join(topics, ","))
+	resp, err := c.do(ctx, path, "text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	return &EventStream{body: resp.Body, scanner: scanner}, nil
+}
+
+// Next returns the next event of the stream. It returns io.EOF once the node
+// ended the stream.
+func (s *EventStream) Next() (*Event, error) {
+	var (
+		event Event
+		data  []string
+	)
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event, if it has data.
+			if len(data) > 0 {
+				event.Data = json.RawMessage(strings.Join(data, "\n"))
+				return &event, nil
+			}
+			event = Event{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comments keep the connection alive.
+			continue
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			event.Topic = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Close closes the stream.
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
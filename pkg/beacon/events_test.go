@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("topics"); got != "head,finalized_checkpoint" {
+			t.Errorf("topics = %q, want head,finalized_checkpoint", got)
+		}
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("Accept header = %q, want text/event-stream", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, ": keep-alive\n\n"+
+			"event: head\n"+
+			"data: {\"slot\":\"10\",\"block\":\"0xaa\"}\n\n"+
+			"id: 1\n"+
+			"event:finalized_checkpoint\n"+
+			"data: {\"epoch\":\n"+
+			"data: \"2\"}\n\n"+
+			"event: head\n\n")
+	}))
+	defer server.Close()
+
+	stream, err := NewClient(server.URL).Events(context.Background(), []string{TopicHead, TopicFinalizedCheckpoint})
+	if err != nil {
+		t.Fatalf("Events() = %v", err)
+	}
+	defer stream.Close()
+
+	want := []Event{
+		{Topic: TopicHead, Data: json.RawMessage(`{"slot":"10","block":"0xaa"}`)},
+		// Data spread over several lines is joined with newlines.
+		{Topic: TopicFinalizedCheckpoint, Data: json.RawMessage("{\"epoch\":\n\"2\"}")},
+	}
+	for i, w := range want {
+		got, err := stream.Next()
+		if err != nil {
+			t.Fatalf("Next() %d = %v", i, err)
+		}
+		if diff := cmp.Diff(w, *got); diff != "" {
+			t.Errorf("unexpected event %d (-want, +got) = %v", i, diff)
+		}
+	}
+	// Events without data are not dispatched.
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Next() at the end of the stream = %v, want EOF", err)
+	}
+}
+
+func TestEventsRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":400,"message":"Invalid topic: blob"}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(server.URL).Events(context.Background(), []string{"blob"}); err == nil {
+		t.Error("Events() = nil, want an error")
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package beacon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Genesis describes the genesis of a beacon chain.
+type Genesis struct {
+	// GenesisTime is the Unix time of the start of slot 0.
+	GenesisTime           int64  `json:"genesis_time,string"`
+	GenesisValidatorsRoot string `json:"genesis_validators_root"`
+	GenesisForkVersion    string `json:"genesis_fork_version"`
+}
+
+// Spec holds the parameters of a beacon chain the adapter needs, among the
+// ones the node reports.
+type Spec struct {
+	SecondsPerSlot uint64 `json:"SECONDS_PER_SLOT,string"`
+	SlotsPerEpoch  uint64 `json:"SLOTS_PER_EPOCH,string"`
+}
+
+// Epoch returns the epoch a slot belongs to.
+func (s *Spec) Epoch(slot uint64) uint64 {
+	if s.SlotsPerEpoch == 0 {
+		return 0
+	}
+	return slot / s.SlotsPerEpoch
+}
+
+// SlotTime returns when a slot starts, given the genesis time of the chain.
+func (s *Spec) SlotTime(genesisTime int64, slot uint64) time.Time {
+	return time.Unix(genesisTime+int64(slot*s.SecondsPerSlot), 0).UTC()
+}
+
+// DepositContract describes the deposit contract of a beacon chain, on its
+// execution layer.
+type DepositContract struct {
+	// ChainID is the EIP-155 chain ID of the execution layer, which
+	// identifies the beacon chain as well, e.g. 1 for mainnet.
+	ChainID uint64 `json:"chain_id,string"`
+	Address string `json:"address"`
+}
+
+// BlockHeader is the header of a block, along with its root.
+type BlockHeader struct {
+	Root      string                  `json:"root"`
+	Canonical bool                    `json:"canonical"`
+	Header    SignedBeaconBlockHeader `json:"header"`
+}
+
+// SignedBeaconBlockHeader is a block header signed by its proposer.
+type SignedBeaconBlockHeader struct {
+	Message   BeaconBlockHeader `json:"message"`
+	Signature string            `json:"signature"`
+}
+
+// BeaconBlockHeader is the header of a block.
+type BeaconBlockHeader struct {
+	Slot          uint64 `json:"slot,string"`
+	ProposerIndex uint64 `json:"proposer_index,string"`
+	ParentRoot    string `json:"parent_root"`
+	StateRoot     string `json:"state_root"`
+	BodyRoot      string `json:"body_root"`
+}
+
+// FinalityCheckpoints are the justified and finalized checkpoints of a state.
+type FinalityCheckpoints struct {
+	PreviousJustified Checkpoint `json:"previous_justified"`
+	CurrentJustified  Checkpoint `json:"current_justified"`
+	Finalized         Checkpoint `json:"finalized"`
+}
+
+// Checkpoint is the block at the start of an epoch.
+type Checkpoint struct {
+	Epoch uint64 `json:"epoch,string"`
+	Root  string `json:"root"`
+}
+
+// HeadEvent is the data of head events.
+type HeadEvent struct {
+	Slot  uint64 `json:"slot,string"`
+	Block string `json:"block"`
+	State string `json:"state"`
+	// EpochTransition is set when the head is the first block of an epoch.
+	EpochTransition           bool   `json:"epoch_transition"`
+	PreviousDutyDependentRoot string `json:"previous_duty_dependent_root"`
+	CurrentDutyDependentRoot  string `json:"current_duty_dependent_root"`
+	ExecutionOptimistic       bool   `json:"execution_optimistic"`
+}
+
+// BlockEvent is the data of block events.
+type BlockEvent struct {
+	Slot                uint64 `json:"slot,string"`
+	Block               string `json:"block"`
+	ExecutionOptimistic bool   `json:"execution_optimistic"`
+}
+
+// FinalizedCheckpointEvent is the data of finalized_checkpoint events.
+type FinalizedCheckpointEvent struct {
+	Block               string `json:"block"`
+	State               string `json:"state"`
+	Epoch               uint64 `json:"epoch,string"`
+	ExecutionOptimistic bool   `json:"execution_optimistic"`
+}
+
+// ChainReorgEvent is the data of chain_reorg events.
+type ChainReorgEvent struct {
+	Slot                uint64 `json:"slot,string"`
+	Depth               uint64 `json:"depth,string"`
+	OldHeadBlock        string `json:"old_head_block"`
+	NewHeadBlock        string `json:"new_head_block"`
+	OldHeadState        string `json:"old_head_state"`
+	NewHeadState        string `json:"new_head_state"`
+	Epoch               uint64 `json:"epoch,string"`
+	ExecutionOptimistic bool   `json:"execution_optimistic"`
+}
+
+// SignedVoluntaryExit is the data of voluntary_exit events.
+type SignedVoluntaryExit struct {
+	Message   VoluntaryExit `json:"message"`
+	Signature string        `json:"signature"`
+}
+
+// VoluntaryExit is the request of a validator to exit from an epoch on.
+type VoluntaryExit struct {
+	Epoch          uint64 `json:"epoch,string"`
+	ValidatorIndex uint64 `json:"validator_index,string"`
+}
+
+// Genesis reads the genesis of the chain.
+func (c *Client) Genesis(ctx context.Context) (*Genesis, error) {
+	var g Genesis
+	if err := c.Get(ctx, "/eth/v1/beacon/genesis", &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Spec reads the parameters of the chain.
+func (c *Client) Spec(ctx context.Context) (*Spec, error) {
+	var s Spec
+	if err := c.Get(ctx, "/eth/v1/config/spec", &s); err != nil {
+		return nil, err
+	}
+	if s.SecondsPerSlot == 0 || s.SlotsPerEpoch == 0 {
+		return nil, errors.New("spec lacks SECONDS_PER_SLOT or SLOTS_PER_EPOCH")
+	}
+	return &s, nil
+}
+
+// DepositContract reads the deposit contract of the chain.
+func (c *Client) DepositContract(ctx context.Context) (*DepositContract, error) {
+	var d DepositContract
+	if err := c.Get(ctx, "/eth/v1/config/deposit_contract", &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Header reads the header of a block, given by its root, its slot, or one of
+// "head", "genesis" and "finalized". It fails with an error IsNotFound
+// reports when there is no such block, e.g. for a slot without block.
+func (c *Client) Header(ctx context.Context, blockID string) (*BlockHeader, error) {
+	var h BlockHeader
+	if err := c.Get(ctx, "/eth/v1/beacon/headers/"+blockID, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// FinalityCheckpoints reads the finality checkpoints of a state, given by
+// its root, its slot, or one of "head", "genesis", "finalized" and
+// "justified".
+func (c *Client) FinalityCheckpoints(ctx context.Context, stateID string) (*FinalityCheckpoints, error) {
+	var f FinalityCheckpoints
+	if err := c.Get(ctx, "/eth/v1/beacon/states/"+stateID+"/finality_checkpoints", &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package beacon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSpecEpoch(t *testing.T) {
+	s := &Spec{SecondsPerSlot: 12, SlotsPerEpoch: 32}
+	for slot, want := range map[uint64]uint64{0: 0, 31: 0, 32: 1, 8000000: 250000} {
+		if got := s.Epoch(slot); got != want {
+			t.Errorf("Epoch(%d) = %d, want %d", slot, got, want)
+		}
+	}
+}
+
+func TestSpecSlotTime(t *testing.T) {
+	s := &Spec{SecondsPerSlot: 12, SlotsPerEpoch: 32}
+	// The genesis time of mainnet.
+	want := time.Date(2020, 12, 1, 12, 0, 35, 0, time.UTC)
+	if got := s.SlotTime(1606824023, 1); !got.Equal(want) {
+		t.Errorf("SlotTime() = %s, want %s", got, want)
+	}
+}
+
+func TestUnmarshalEvents(t *testing.T) {
+	var reorg ChainReorgEvent
+	data := `{"slot":"200","depth":"2","old_head_block":"0xaa","new_head_block":"0xbb",` +
+		`"old_head_state":"0xcc","new_head_state":"0xdd","epoch":"6","execution_optimistic":false}`
+	if err := json.Unmarshal([]byte(data), &reorg); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	wantReorg := ChainReorgEvent{
+		Slot:         200,
+		Depth:        2,
+		OldHeadBlock: "0xaa",
+		NewHeadBlock: "0xbb",
+		OldHeadState: "0xcc",
+		NewHeadState: "0xdd",
+		Epoch:        6,
+	}
+	if diff := cmp.Diff(wantReorg, reorg); diff != "" {
+		t.Errorf("unexpected reorg (-want, +got) = %v", diff)
+	}
+
+	var exit SignedVoluntaryExit
+	data = `{"message":{"epoch":"1000","validator_index":"12345"},"signature":"0xee"}`
+	if err := json.Unmarshal([]byte(data), &exit); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	wantExit := SignedVoluntaryExit{Message: VoluntaryExit{Epoch: 1000, ValidatorIndex: 12345}, Signature: "0xee"}
+	if diff := cmp.Diff(wantExit, exit); diff != "" {
+		t.Errorf("unexpected exit (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bitcoin contains helpers to work with the data of Bitcoin and of
+// the chains sharing its transaction format.
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// errTruncated is returned for transactions shorter than their encoding
+// announces.
+var errTruncated = errors.New("truncated transaction")
+
+// Transaction is a transaction in the serialization of the P2P protocol, as
+// published by bitcoind over ZMQ.
+type Transaction struct {
+	Version  int32
+	Inputs   []Input
+	Outputs  []Output
+	LockTime uint32
+
+	// base is the serialization without the witnesses, from which the
+	// transaction ID is computed.
+	base []byte
+	// hasWitness is true for segwit transactions.
+	hasWitness bool
+}
+
+// Input is an input of a transaction, spending the output of a previous
+// transaction.
+type Input struct {
+	// PrevTxID and PrevIndex identify the spent output, the transaction ID
+	// being in the byte order of RPC and block explorers.
+	PrevTxID  string
+	PrevIndex uint32
+	ScriptSig []byte
+	Sequence  uint32
+	Witness   [][]byte
+}
+
+// Output is an output of a transaction.
+type Output struct {
+	// Value is the amount of the output, in satoshis.
+	Value        int64
+	ScriptPubKey []byte
+}
+
+// ParseTransaction decodes a serialized transaction.
+func ParseTransaction(raw []byte) (*Transaction, error) {
+	r := &reader{data: raw}
+	tx := &Transaction{}
+	tx.Version = int32(r.uint32())
+
+	// Segwit transactions have a marker and a flag where the number of
+	// inputs would be, which is never zero otherwise.
+	bodyStart := r.pos
+	if len(raw) > r.pos+1 && raw[r.pos] == 0 && raw[r.pos+1] == 1 {
+		tx.hasWitness = true
+		r.pos += 2
+		bodyStart = r.pos
+	}
+
+	nIn := r.varInt()
+	if r.err == nil && nIn > uint64(len(raw)) {
+		return nil, errTruncated
+	}
+	for i := uint64(0); i < nIn && r.err == nil; i++ {
+		in := Input{PrevTxID: hashString(r.bytes(32))}
+		in.PrevIndex = r.uint32()
+		in.ScriptSig = r.varBytes()
+		in.Sequence = r.uint32()
+		tx.Inputs = append(tx.Inputs, in)
+	}
+	nOut := r.varInt()
+	if r.err == nil && nOut > uint64(len(raw)) {
+		return nil, errTruncated
+	}
+	for i := uint64(0); i < nOut && r.err == nil; i++ {
+		out := Output{Value: int64(r.uint64())}
+		out.ScriptPubKey = r.varBytes()
+		tx.Outputs = append(tx.Outputs, out)
+	}
+	bodyEnd := r.pos
+
+	if tx.hasWitness {
+		for i := range tx.Inputs {
+			n := r.varInt()
+			if r.err == nil && n > uint64(len(raw)) {
+				return nil, errTruncated
+			}
+			for j := uint64(0); j < n && r.err == nil; j++ {
+				tx.Inputs[i].Witness = append(tx.Inputs[i].Witness, r.varBytes())
+			}
+		}
+	}
+	tx.LockTime = r.uint32()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.pos != len(raw) {
+		return nil, fmt.Errorf("%d trailing bytes after the transaction", len(raw)-r.pos)
+	}
+
+	tx.base = make([]byte, 0, 4+bodyEnd-bodyStart+4)
+	tx.base = append(tx.base, raw[:4]...)
+	tx.base = append(tx.base, raw[bodyStart:bodyEnd]...)
+	tx.base = append(tx.base, raw[len(raw)-4:]...)
+	return tx, nil
+}
+
+// TxID returns the ID of the transaction, in the byte order of RPC and
+// block explorers.
+func (tx *Transaction) TxID() string {
+	return hashString(doubleSHA256(tx.base))
+}
+
+// HasWitness reports whether the transaction is a segwit transaction.
+func (tx *Transaction) HasWitness() bool {
+	return tx.hasWitness
+}
+
+func doubleSHA256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// hashString returns a hash in hex, reversed the way Bitcoin displays them.
+func hashString(h []byte) string {
+	reversed := make([]byte, len(h))
+	for i, b := range h {
+		reversed[len(h)-1-i] = b
+	}
+	return hex.EncodeToString(reversed)
+}
+
+// reader reads the fields of a serialized transaction. The first error
+// is kept, reads after it return zero values.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data)-r.pos < n {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// varInt reads a CompactSize unsigned integer.
+func (r *reader) varInt() uint64 {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	switch b[0] {
+	case 0xfd:
+		if b := r.bytes(2); b != nil {
+			return uint64(binary.LittleEndian.Uint16(b))
+		}
+		return 0
+	case 0xfe:
+		return uint64(r.uint32())
+	case 0xff:
+		return r.uint64()
+	}
+	return uint64(b[0])
+}
+
+// varBytes reads bytes prefixed with their length.
+func (r *reader) varBytes() []byte {
+	n := r.varInt()
+	if r.err == nil && n > uint64(len(r.data)) {
+		r.err = errTruncated
+		return nil
+	}
+	return r.bytes(int(n))
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitcoin
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const (
+	// genesisCoinbase is the coinbase transaction of the genesis block of
+	// Bitcoin mainnet.
+	genesisCoinbase = "01000000" + // version
+		"01" + // inputs
+		"0000000000000000000000000000000000000000000000000000000000000000ffffffff" +
+		"4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73" +
+		"ffffffff" +
+		"01" + // outputs
+		"00f2052a01000000" +
+		"434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac" +
+		"00000000" // lock time
+	genesisCoinbaseID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+)
+
+// withWitness returns the genesis coinbase as a segwit transaction, whose
+// input has a witness.
+func withWitness() string {
+	body := genesisCoinbase[8 : len(genesisCoinbase)-8]
+	return genesisCoinbase[:8] + "0001" + body + "0102abcd" + genesisCoinbase[len(genesisCoinbase)-8:]
+}
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseTransaction(t *testing.T) {
+	testCases := map[string]struct {
+		raw         string
+		wantWitness [][]byte
+	}{
+		"legacy": {raw: genesisCoinbase},
+		"segwit": {raw: withWitness(), wantWitness: [][]byte{{0xab, 0xcd}}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			tx, err := ParseTransaction(decode(t, tc.raw))
+			if err != nil {
+				t.Fatalf("ParseTransaction() = %v", err)
+			}
+			// Witnesses are not part of the transaction ID.
+			if got := tx.TxID(); got != genesisCoinbaseID {
+				t.Errorf("TxID() = %s, want %s", got, genesisCoinbaseID)
+			}
+			if got, want := tx.HasWitness(), tc.wantWitness != nil; got != want {
+				t.Errorf("HasWitness() = %v, want %v", got, want)
+			}
+
+			want := &Transaction{
+				Version: 1,
+				Inputs: []Input{{
+					PrevTxID:  strings.Repeat("0", 64),
+					PrevIndex: 0xffffffff,
+					ScriptSig: decode(t, genesisCoinbase[84:238]),
+					Sequence:  0xffffffff,
+					Witness:   tc.wantWitness,
+				}},
+				Outputs: []Output{{
+					Value:        50 * 100000000,
+					ScriptPubKey: decode(t, genesisCoinbase[266:400]),
+				}},
+			}
+			if diff := cmp.Diff(want, tx, cmpopts.IgnoreUnexported(Transaction{})); diff != "" {
+				t.Errorf("unexpected transaction (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseTransactionErrors(t *testing.T) {
+	testCases := map[string]string{
+		"empty":             "",
+		"truncated":         genesisCoinbase[:100],
+		"no lock time":      genesisCoinbase[:len(genesisCoinbase)-8],
+		"trailing bytes":    genesisCoinbase + "00",
+		"too many inputs":   "01000000fe00000001",
+		"truncated witness": withWitness()[:len(withWitness())-12],
+	}
+
+	for n, raw := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if _, err := ParseTransaction(decode(t, raw)); err == nil {
+				t.Error("ParseTransaction() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checkpoint persists how far a receive adapter has delivered
+// events, so that it resumes from there after a restart.
+package checkpoint
+
+import (
+	"context"
+	"time"
+)
+
+// Checkpoint is the position in the chain up to which events have been
+// delivered.
+type Checkpoint struct {
+	// BlockNumber is the number of the last block whose events were all
+	// delivered, or of the block being delivered when LogIndex is set.
+	BlockNumber uint64 `json:"blockNumber"`
+	// BlockHash is the hash of the block, if known.
+	BlockHash string `json:"blockHash,omitempty"`
+	// LogIndex is the index of the last delivered log, or transaction, of
+	// a block whose events were partly delivered.
+	LogIndex *uint64 `json:"logIndex,omitempty"`
+	// Epoch is the last finalized epoch whose event was delivered, for
+	// beacon chains, whose block number is a slot.
+	Epoch *uint64 `json:"epoch,omitempty"`
+	// Time is when the checkpoint was saved.
+	Time time.Time `json:"time"`
+	// Progress reports how the adapter kept up with the chain when the
+	// checkpoint was saved.
+	Progress *Progress `json:"progress,omitempty"`
+}
+
+// Progress reports how a receive adapter keeps up with the chain, for the
+// controller to surface in the source status. It is not used to resume.
+type Progress struct {
+	// ChainID is the chain ID reported by the node.
+	ChainID string `json:"chainID,omitempty"`
+	// Head is the number of the newest block seen.
+	Head uint64 `json:"head"`
+	// Final is the number of the newest block final enough to be emitted.
+	Final uint64 `json:"final"`
+	// BlockTime is when the last processed block was produced, if known.
+	BlockTime *time.Time `json:"blockTime,omitempty"`
+	// Endpoint is the endpoint the adapter last read from, without the
+	// path and query of its URL.
+	Endpoint string `json:"endpoint,omitempty"`
+	// EmittedEvents counts the delivered events by CloudEvent type.
+	EmittedEvents map[string]uint64 `json:"emittedEvents,omitempty"`
+}
+
+// Reasons why a receive adapter could not connect to the chain.
+const (
+	// ReasonUnreachable is reported when no endpoint could be reached.
+	ReasonUnreachable = "EndpointUnreachable"
+	// ReasonUnauthorized is reported when an endpoint rejected the
+	// credentials of the adapter.
+	ReasonUnauthorized = "EndpointUnauthorized"
+	// ReasonChainIDMismatch is reported when the endpoints serve another
+	// chain than the expected one.
+	ReasonChainIDMismatch = "ChainIDMismatch"
+)
+
+// Connection reports whether a receive adapter could connect to the chain,
+// for the controller to surface in the source status.
+type Connection struct {
+	// ChainID is the chain ID reported by the node, once read.
+	ChainID string `json:"chainID,omitempty"`
+	// Reason tells why the adapter could not connect, empty when it did.
+	Reason string `json:"reason,omitempty"`
+	// Message details the reason.
+	Message string `json:"message,omitempty"`
+	// Time is when the report was saved.
+	Time time.Time `json:"time"`
+}
+
+// Reporter saves connection reports. Stores read by the controller
+// implement it.
+type Reporter interface {
+	// Report replaces the saved connection report.
+	Report(ctx context.Context, c *Connection) error
+}
+
+// Locker is implemented by the stores that only one replica may write to
+// at a time. The replica holding the lock is the one emitting events.
+type Locker interface {
+	// Lock blocks until the lock is acquired or ctx is done. The returned
+	// context is canceled when the lock is lost, then Lost reports it.
+	Lock(ctx context.Context) (context.Context, error)
+	// Lost reports whether the lock was lost since acquired.
+	Lost() bool
+	// Unlock releases the lock, so that another replica may take it over
+	// at once.
+	Unlock(ctx context.Context) error
+}
+
+// Store loads and saves checkpoints.
+type Store interface {
+	// Load returns the last saved checkpoint, or nil if none was saved.
+	Load(ctx context.Context) (*Checkpoint, error)
+	// Save replaces the saved checkpoint.
+	Save(ctx context.Context, cp *Checkpoint) error
+}
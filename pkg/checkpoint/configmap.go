@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// ConfigMapKey is the key of the ConfigMap data holding the checkpoint.
+	ConfigMapKey = "checkpoint"
+	// ConnectionConfigMapKey is the key of the ConfigMap data holding the
+	// connection report.
+	ConnectionConfigMapKey = "connection"
+)
+
+// configMapStore saves checkpoints and connection reports as JSON in a
+// ConfigMap, which the controller also reads to report them in the source
+// status.
+type configMapStore struct {
+	client corev1client.ConfigMapInterface
+	name   string
+}
+
+// NewConfigMapStore returns a Store saving checkpoints in the ConfigMap
+// with the given name, which is created if it does not exist.
+func NewConfigMapStore(client corev1client.ConfigMapInterface, name string) Store {
+	return &configMapStore{client: client, name: name}
+}
+
+func (s *configMapStore) Load(ctx context.Context) (*Checkpoint, error) {
+	cm, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint ConfigMap %s: %w", s.name, err)
+	}
+	return FromConfigMap(cm)
+}
+
+var _ Reporter = (*configMapStore)(nil)
+
+func (s *configMapStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	return s.put(ctx, ConfigMapKey, data)
+}
+
+func (s *configMapStore) Report(ctx context.Context, c *Connection) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connection report: %w", err)
+	}
+	return s.put(ctx, ConnectionConfigMapKey, data)
+}
+
+// put sets a key of the ConfigMap, creating the ConfigMap if needed. The
+// write is retried when the ConfigMap changed since it was read, e.g. when
+// the controller or a restarted adapter created it in between.
+func (s *configMapStore) put(ctx context.Context, key string, data []byte) error {
+	return retry.OnError(retry.DefaultRetry, isWriteConflict, func() error {
+		cm, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name},
+				Data:       map[string]string{key: string(data)},
+			}
+			if _, err := s.client.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create checkpoint ConfigMap %s: %w", s.name, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get checkpoint ConfigMap %s: %w", s.name, err)
+		}
+
+		cm = cm.DeepCopy()
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		cm.Data[key] = string(data)
+		if _, err := s.client.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update checkpoint ConfigMap %s: %w", s.name, err)
+		}
+		return nil
+	})
+}
+
+// isWriteConflict reports whether a write failed because the object changed
+// since it was read.
+func isWriteConflict(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+}
+
+// FromConfigMap returns the checkpoint saved in a ConfigMap, or nil if it
+// holds none.
+func FromConfigMap(cm *corev1.ConfigMap) (*Checkpoint, error) {
+	data, ok := cm.Data[ConfigMapKey]
+	if !ok {
+		return nil, nil
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal([]byte(data), cp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint ConfigMap %s: %w", cm.Name, err)
+	}
+	return cp, nil
+}
+
+// ConnectionFromConfigMap returns the connection report saved in a
+// ConfigMap, or nil if it holds none.
+func ConnectionFromConfigMap(cm *corev1.ConfigMap) (*Connection, error) {
+	data, ok := cm.Data[ConnectionConfigMapKey]
+	if !ok {
+		return nil, nil
+	}
+	c := &Connection{}
+	if err := json.Unmarshal([]byte(data), c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal connection report of ConfigMap %s: %w", cm.Name, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+func TestConfigMapStore(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	testStore(t, NewConfigMapStore(client.CoreV1().ConfigMaps("default"), "source-checkpoint"))
+
+	cm, err := client.CoreV1().ConfigMaps("default").Get(context.Background(), "source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	cp, err := FromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("FromConfigMap() = %v", err)
+	}
+	if cp.BlockNumber != 12 {
+		t.Errorf("BlockNumber = %d, want 12", cp.BlockNumber)
+	}
+}
+
+func TestConfigMapStoreKeepsOtherKeys(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "source-checkpoint"},
+		Data:       map[string]string{"other": "value"},
+	})
+	s := NewConfigMapStore(client.CoreV1().ConfigMaps("default"), "source-checkpoint")
+
+	if cp, err := s.Load(context.Background()); err != nil || cp != nil {
+		t.Fatalf("Load() = %v, %v, want nil, nil", cp, err)
+	}
+	if err := s.Save(context.Background(), &Checkpoint{BlockNumber: 1}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps("default").Get(context.Background(), "source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if cm.Data["other"] != "value" {
+		t.Errorf("other key = %q, want %q", cm.Data["other"], "value")
+	}
+}
+
+func TestConfigMapStoreReport(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	s := NewConfigMapStore(client.CoreV1().ConfigMaps("default"), "source-checkpoint")
+
+	want := &Connection{
+		ChainID: "5",
+		Reason:  ReasonChainIDMismatch,
+		Message: "node serves chain 5 instead of 1",
+		Time:    time.Unix(1600000000, 0).UTC(),
+	}
+	if err := s.(Reporter).Report(context.Background(), want); err != nil {
+		t.Fatalf("Report() = %v", err)
+	}
+	if err := s.Save(context.Background(), &Checkpoint{BlockNumber: 1}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps("default").Get(context.Background(), "source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	got, err := ConnectionFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("ConnectionFromConfigMap() = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected connection report (-want, +got) = %v", diff)
+	}
+}
+
+func TestConfigMapStoreRetriesConflicts(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "source-checkpoint"},
+	})
+	// The first update races with another writer.
+	conflicts := 0
+	client.PrependReactor("update", "configmaps", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(corev1.Resource("configmaps"), "source-checkpoint", errors.New("object was modified"))
+	})
+	s := NewConfigMapStore(client.CoreV1().ConfigMaps("default"), "source-checkpoint")
+
+	if err := s.Save(context.Background(), &Checkpoint{BlockNumber: 7}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	cp, err := s.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if cp == nil || cp.BlockNumber != 7 {
+		t.Errorf("Load() = %+v, want block 7", cp)
+	}
+	if conflicts != 1 {
+		t.Errorf("conflicts = %d, want 1", conflicts)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testStore saves two checkpoints in a store and checks that the last one
+// is loaded back.
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	cp, err := s.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if cp != nil {
+		t.Fatalf("Load() = %v before any save, want nil", cp)
+	}
+
+	index := uint64(3)
+	blockTime := time.Unix(1600000012, 0).UTC()
+	for _, want := range []*Checkpoint{{
+		BlockNumber: 10,
+		BlockHash:   "0xa",
+		Time:        time.Unix(1600000000, 0).UTC(),
+	}, {
+		BlockNumber: 12,
+		BlockHash:   "0xc",
+		LogIndex:    &index,
+		Time:        time.Unix(1600000024, 0).UTC(),
+		Progress: &Progress{
+			ChainID:       "1",
+			Head:          14,
+			Final:         12,
+			BlockTime:     &blockTime,
+			Endpoint:      "https://node.example.com",
+			EmittedEvents: map[string]uint64{"dev.knative.source.blockchain.evm.block": 12},
+		},
+	}} {
+		if err := s.Save(ctx, want); err != nil {
+			t.Fatalf("Save() = %v", err)
+		}
+		got, err := s.Load(ctx)
+		if err != nil {
+			t.Fatalf("Load() = %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected checkpoint (-want, +got) = %v", diff)
+		}
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	testStore(t, NewFileStore(filepath.Join(dir, "checkpoint.json")))
+
+	// No temporary file is left behind.
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("got %d files, want 1", len(files))
+	}
+}
+
+func TestFileStoreCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path).Load(context.Background()); err == nil {
+		t.Error("Load() = nil, wanted error")
+	}
+}
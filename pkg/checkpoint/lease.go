@@ -0,0 +1,278 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+// DefaultLeaseDuration is how long a replica holds the checkpoint lease
+// after its last write, before another replica may take it over.
+const DefaultLeaseDuration = time.Minute
+
+// ErrNotHolder is returned by the writes of a lease-guarded store while
+// another replica holds the lease.
+var ErrNotHolder = errors.New("checkpoint lease is held by another replica")
+
+// leaseStore guards the writes of a store with a coordination.k8s.io Lease,
+// so that only one replica of an adapter, or only the new pod of a restart,
+// emits events and writes checkpoints and connection reports. The lease is
+// renewed every period while locked, and on every write; another replica
+// takes it over once it was not renewed for the lease duration, or at once
+// when released.
+type leaseStore struct {
+	Store
+	leases   coordinationv1client.LeaseInterface
+	name     string
+	holder   string
+	duration time.Duration
+	period   time.Duration
+	now      func() time.Time
+
+	// leaseMu serializes the writes to the lease, so that a renewal does
+	// not conflict with the write of a checkpoint.
+	leaseMu sync.Mutex
+
+	mu sync.Mutex
+	// stop stops renewing the lease, while locked.
+	stop func()
+	lost bool
+}
+
+// NewLeaseStore returns a Store writing to store only while holding the
+// Lease with the given name, as holder. Loads are not guarded.
+func NewLeaseStore(store Store, leases coordinationv1client.LeaseInterface, name, holder string, duration time.Duration) Store {
+	return &leaseStore{
+		Store:    store,
+		leases:   leases,
+		name:     name,
+		holder:   holder,
+		duration: duration,
+		period:   duration / 4,
+		now:      time.Now,
+	}
+}
+
+var _ Locker = (*leaseStore)(nil)
+
+// Lock acquires the lease, trying again every period while another replica
+// holds it, then renews it every period until unlocked. The lease is lost
+// when another replica took it over, or when it could not be renewed for
+// the lease duration.
+func (s *leaseStore) Lock(ctx context.Context) (context.Context, error) {
+	for {
+		err := s.acquire(ctx)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrNotHolder) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.period):
+		}
+	}
+
+	held, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.lost = false
+	s.stop = func() {
+		cancel()
+		<-done
+	}
+	s.mu.Unlock()
+	go func() {
+		defer close(done)
+		s.renew(held, cancel)
+	}()
+	return held, nil
+}
+
+// renew renews the lease every period until ctx is done, and cancels it
+// once the lease is lost.
+func (s *leaseStore) renew(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+	renewed := s.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := s.acquire(ctx)
+		switch {
+		case err == nil:
+			renewed = s.now()
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, ErrNotHolder) || !s.now().Before(renewed.Add(s.duration)):
+			s.mu.Lock()
+			s.lost = true
+			s.mu.Unlock()
+			cancel()
+			return
+		}
+	}
+}
+
+func (s *leaseStore) Lost() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lost
+}
+
+// Unlock stops renewing the lease, and releases it if still held.
+func (s *leaseStore) Unlock(ctx context.Context) error {
+	s.mu.Lock()
+	stop := s.stop
+	s.stop = nil
+	s.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	lease, err := s.leases.Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint lease %s: %w", s.name, err)
+	}
+	if h := lease.Spec.HolderIdentity; h == nil || *h != s.holder {
+		return nil
+	}
+	lease = lease.DeepCopy()
+	lease.Spec.HolderIdentity, lease.Spec.RenewTime = nil, nil
+	_, err = s.leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return ErrNotHolder
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release checkpoint lease %s: %w", s.name, err)
+	}
+	return nil
+}
+
+func (s *leaseStore) Save(ctx context.Context, cp *Checkpoint) error {
+	if err := s.acquireUnlessLost(ctx); err != nil {
+		return err
+	}
+	return s.Store.Save(ctx, cp)
+}
+
+var _ Reporter = (*leaseStore)(nil)
+
+// Report saves a connection report if the guarded store takes them.
+func (s *leaseStore) Report(ctx context.Context, c *Connection) error {
+	reporter, ok := s.Store.(Reporter)
+	if !ok {
+		return nil
+	}
+	if err := s.acquireUnlessLost(ctx); err != nil {
+		return err
+	}
+	return reporter.Report(ctx, c)
+}
+
+// acquireUnlessLost acquires the lease for a write, unless it was lost
+// since locked: the replica then stops, without taking the lease back.
+func (s *leaseStore) acquireUnlessLost(ctx context.Context) error {
+	if s.Lost() {
+		return ErrNotHolder
+	}
+	return s.acquire(ctx)
+}
+
+// acquire creates or renews the lease, or takes it over once expired. It
+// fails with ErrNotHolder while another replica holds it, or when another
+// replica wrote it concurrently.
+func (s *leaseStore) acquire(ctx context.Context) error {
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	now := metav1.NewMicroTime(s.now())
+	seconds := int32(s.duration / time.Second)
+
+	lease, err := s.leases.Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: s.name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.holder,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		_, err := s.leases.Create(ctx, lease, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return ErrNotHolder
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create checkpoint lease %s: %w", s.name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint lease %s: %w", s.name, err)
+	}
+
+	lease = lease.DeepCopy()
+	spec := &lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity != s.holder {
+		if !s.expired(lease) {
+			return ErrNotHolder
+		}
+		transitions := int32(1)
+		if spec.LeaseTransitions != nil {
+			transitions = *spec.LeaseTransitions + 1
+		}
+		spec.HolderIdentity, spec.AcquireTime, spec.LeaseTransitions = &s.holder, &now, &transitions
+	}
+	spec.LeaseDurationSeconds, spec.RenewTime = &seconds, &now
+	_, err = s.leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return ErrNotHolder
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update checkpoint lease %s: %w", s.name, err)
+	}
+	return nil
+}
+
+// expired reports whether the holder of a lease did not renew it in time.
+func (s *leaseStore) expired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	return !s.now().Before(lease.Spec.RenewTime.Add(duration))
+}
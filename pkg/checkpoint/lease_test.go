@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLeaseStore(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	now := time.Unix(1600000000, 0)
+	newStore := func(holder string) Store {
+		s := NewLeaseStore(NewConfigMapStore(client.CoreV1().ConfigMaps("default"), "source-checkpoint"),
+			client.CoordinationV1().Leases("default"), "source-checkpoint", holder, DefaultLeaseDuration).(*leaseStore)
+		s.now = func() time.Time { return now }
+		return s
+	}
+	ctx := context.Background()
+	old, replacement := newStore("adapter-1"), newStore("adapter-2")
+
+	if err := old.Save(ctx, &Checkpoint{BlockNumber: 1}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	// The lease of the old pod is renewed on every write, the replacement
+	// neither saves checkpoints nor connection reports meanwhile.
+	now = now.Add(DefaultLeaseDuration / 2)
+	if err := old.Save(ctx, &Checkpoint{BlockNumber: 2}); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	now = now.Add(DefaultLeaseDuration / 2)
+	if err := replacement.Save(ctx, &Checkpoint{BlockNumber: 3}); !errors.Is(err, ErrNotHolder) {
+		t.Errorf("Save() by another replica = %v, want %v", err, ErrNotHolder)
+	}
+	if err := replacement.(Reporter).Report(ctx, &Connection{ChainID: "1"}); !errors.Is(err, ErrNotHolder) {
+		t.Errorf("Report() by another replica = %v, want %v", err, ErrNotHolder)
+	}
+	if cp, err := replacement.Load(ctx); err != nil || cp.BlockNumber != 2 {
+		t.Errorf("Load() = %+v, %v, want block 2", cp, err)
+	}
+
+	// The lease is taken over once the old pod stopped renewing it.
+	now = now.Add(DefaultLeaseDuration)
+	if err := replacement.Save(ctx, &Checkpoint{BlockNumber: 4}); err != nil {
+		t.Fatalf("Save() after expiry = %v", err)
+	}
+	if err := old.Save(ctx, &Checkpoint{BlockNumber: 3}); !errors.Is(err, ErrNotHolder) {
+		t.Errorf("Save() by the old holder = %v, want %v", err, ErrNotHolder)
+	}
+	if cp, err := old.Load(ctx); err != nil || cp.BlockNumber != 4 {
+		t.Errorf("Load() = %+v, %v, want block 4", cp, err)
+	}
+
+	lease, err := client.CoordinationV1().Leases("default").Get(ctx, "source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got := *lease.Spec.HolderIdentity; got != "adapter-2" {
+		t.Errorf("lease holder = %s, want adapter-2", got)
+	}
+	if got := *lease.Spec.LeaseTransitions; got != 1 {
+		t.Errorf("lease transitions = %d, want 1", got)
+	}
+}
+
+func TestLeaseStoreWithoutReports(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	s := NewLeaseStore(NewFileStore(t.TempDir()+"/checkpoint.json"), client.CoordinationV1().Leases("default"), "source-checkpoint", "adapter-1", DefaultLeaseDuration)
+
+	// Reports are dropped when the guarded store does not take them.
+	if err := s.(Reporter).Report(context.Background(), &Connection{ChainID: "1"}); err != nil {
+		t.Errorf("Report() = %v", err)
+	}
+}
+
+func TestLeaseStoreLock(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	leases := client.CoordinationV1().Leases("default")
+	newStore := func(holder string) *leaseStore {
+		s := NewLeaseStore(NewConfigMapStore(client.CoreV1().ConfigMaps("default"), "source-checkpoint"),
+			leases, "source-checkpoint", holder, DefaultLeaseDuration).(*leaseStore)
+		s.period = 10 * time.Millisecond
+		return s
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	old, replacement := newStore("adapter-1"), newStore("adapter-2")
+
+	if _, err := old.Lock(ctx); err != nil {
+		t.Fatalf("Lock() = %v", err)
+	}
+	// The replacement waits for the lease until the old pod releases it.
+	locked := make(chan context.Context)
+	go func() {
+		held, err := replacement.Lock(ctx)
+		if err != nil {
+			t.Errorf("Lock() = %v", err)
+		}
+		locked <- held
+	}()
+	select {
+	case <-locked:
+		t.Fatal("Lock() returned while another replica holds the lease")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := old.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock() = %v", err)
+	}
+	var held context.Context
+	select {
+	case held = <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock() did not return once the lease was released")
+	}
+	if err := old.Save(ctx, &Checkpoint{BlockNumber: 1}); !errors.Is(err, ErrNotHolder) {
+		t.Errorf("Save() by the old holder = %v, want %v", err, ErrNotHolder)
+	}
+
+	// The lease is lost once another replica takes it over.
+	lease, err := leases.Get(ctx, "source-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	other := "adapter-3"
+	lease.Spec.HolderIdentity = &other
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	select {
+	case <-held.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context not canceled once the lease was lost")
+	}
+	if !replacement.Lost() {
+		t.Error("Lost() = false, want true")
+	}
+	if err := replacement.Save(ctx, &Checkpoint{BlockNumber: 2}); !errors.Is(err, ErrNotHolder) {
+		t.Errorf("Save() after losing the lease = %v, want %v", err, ErrNotHolder)
+	}
+	if err := replacement.Unlock(ctx); err != nil {
+		t.Errorf("Unlock() = %v", err)
+	}
+	if lease, err := leases.Get(ctx, "source-checkpoint", metav1.GetOptions{}); err != nil || *lease.Spec.HolderIdentity != other {
+		t.Errorf("lease = %+v, %v, want it held by %s", lease, err, other)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	scheme "knative.dev/eventing-blockchain/pkg/client/clientset/versioned/scheme"
+)
+
+// BlockchainNetworksGetter has a method to return a BlockchainNetworkInterface.
+// A group's client should implement this interface.
+type BlockchainNetworksGetter interface {
+	BlockchainNetworks() BlockchainNetworkInterface
+}
+
+// BlockchainNetworkInterface has methods to work with BlockchainNetwork resources.
+type BlockchainNetworkInterface interface {
+	Create(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.CreateOptions) (*v1alpha1.BlockchainNetwork, error)
+	Update(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (*v1alpha1.BlockchainNetwork, error)
+	UpdateStatus(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (*v1alpha1.BlockchainNetwork, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.BlockchainNetwork, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.BlockchainNetworkList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.BlockchainNetwork, err error)
+	BlockchainNetworkExpansion
+}
+
+// blockchainNetworks implements BlockchainNetworkInterface
+type blockchainNetworks struct {
+	client rest.Interface
+}
+
+// newBlockchainNetworks returns a BlockchainNetworks
+func newBlockchainNetworks(c *SourcesV1alpha1Client) *blockchainNetworks {
+	return &blockchainNetworks{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the blockchainNetwork, and returns the corresponding blockchainNetwork object, and an error if there is any.
+func (c *blockchainNetworks) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.BlockchainNetwork, err error) {
+	result = &v1alpha1.BlockchainNetwork{}
+	err = c.client.Get().
+		Resource("blockchainnetworks").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of BlockchainNetworks that match those selectors.
+func (c *blockchainNetworks) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.BlockchainNetworkList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.BlockchainNetworkList{}
+	err = c.client.Get().
+		Resource("blockchainnetworks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested blockchainNetworks.
+func (c *blockchainNetworks) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("blockchainnetworks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a blockchainNetwork and creates it.  Returns the server's representation of the blockchainNetwork, and an error, if there is any.
+func (c *blockchainNetworks) Create(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.CreateOptions) (result *v1alpha1.BlockchainNetwork, err error) {
+	result = &v1alpha1.BlockchainNetwork{}
+	err = c.client.Post().
+		Resource("blockchainnetworks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(blockchainNetwork).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a blockchainNetwork and updates it. Returns the server's representation of the blockchainNetwork, and an error, if there is any.
+func (c *blockchainNetworks) Update(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (result *v1alpha1.BlockchainNetwork, err error) {
+	result = &v1alpha1.BlockchainNetwork{}
+	err = c.client.Put().
+		Resource("blockchainnetworks").
+		Name(blockchainNetwork.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(blockchainNetwork).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *blockchainNetworks) UpdateStatus(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (result *v1alpha1.BlockchainNetwork, err error) {
+	result = &v1alpha1.BlockchainNetwork{}
+	err = c.client.Put().
+		Resource("blockchainnetworks").
+		Name(blockchainNetwork.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(blockchainNetwork).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the blockchainNetwork and deletes it. Returns an error if one occurs.
+func (c *blockchainNetworks) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("blockchainnetworks").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *blockchainNetworks) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("blockchainnetworks").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched blockchainNetwork.
+func (c *blockchainNetworks) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.BlockchainNetwork, err error) {
+	result = &v1alpha1.BlockchainNetwork{}
+	err = c.client.Patch(pt).
+		Resource("blockchainnetworks").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
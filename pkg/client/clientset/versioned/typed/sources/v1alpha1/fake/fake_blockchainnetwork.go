@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+// FakeBlockchainNetworks implements BlockchainNetworkInterface
+type FakeBlockchainNetworks struct {
+	Fake *FakeSourcesV1alpha1
+}
+
+var blockchainnetworksResource = schema.GroupVersionResource{Group: "sources.knative.dev", Version: "v1alpha1", Resource: "blockchainnetworks"}
+
+var blockchainnetworksKind = schema.GroupVersionKind{Group: "sources.knative.dev", Version: "v1alpha1", Kind: "BlockchainNetwork"}
+
+// Get takes name of the blockchainNetwork, and returns the corresponding blockchainNetwork object, and an error if there is any.
+func (c *FakeBlockchainNetworks) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.BlockchainNetwork, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(blockchainnetworksResource, name), &v1alpha1.BlockchainNetwork{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BlockchainNetwork), err
+}
+
+// List takes label and field selectors, and returns the list of BlockchainNetworks that match those selectors.
+func (c *FakeBlockchainNetworks) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.BlockchainNetworkList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(blockchainnetworksResource, blockchainnetworksKind, opts), &v1alpha1.BlockchainNetworkList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.BlockchainNetworkList{ListMeta: obj.(*v1alpha1.BlockchainNetworkList).ListMeta}
+	for _, item := range obj.(*v1alpha1.BlockchainNetworkList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested blockchainNetworks.
+func (c *FakeBlockchainNetworks) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(blockchainnetworksResource, opts))
+}
+
+// Create takes the representation of a blockchainNetwork and creates it.  Returns the server's representation of the blockchainNetwork, and an error, if there is any.
+func (c *FakeBlockchainNetworks) Create(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.CreateOptions) (result *v1alpha1.BlockchainNetwork, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(blockchainnetworksResource, blockchainNetwork), &v1alpha1.BlockchainNetwork{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BlockchainNetwork), err
+}
+
+// Update takes the representation of a blockchainNetwork and updates it. Returns the server's representation of the blockchainNetwork, and an error, if there is any.
+func (c *FakeBlockchainNetworks) Update(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (result *v1alpha1.BlockchainNetwork, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(blockchainnetworksResource, blockchainNetwork), &v1alpha1.BlockchainNetwork{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BlockchainNetwork), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeBlockchainNetworks) UpdateStatus(ctx context.Context, blockchainNetwork *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (*v1alpha1.BlockchainNetwork, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(blockchainnetworksResource, "status", blockchainNetwork), &v1alpha1.BlockchainNetwork{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BlockchainNetwork), err
+}
+
+// Delete takes name of the blockchainNetwork and deletes it. Returns an error if one occurs.
+func (c *FakeBlockchainNetworks) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(blockchainnetworksResource, name, opts), &v1alpha1.BlockchainNetwork{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeBlockchainNetworks) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(blockchainnetworksResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.BlockchainNetworkList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched blockchainNetwork.
+func (c *FakeBlockchainNetworks) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.BlockchainNetwork, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(blockchainnetworksResource, name, pt, data, subresources...), &v1alpha1.BlockchainNetwork{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.BlockchainNetwork), err
+}
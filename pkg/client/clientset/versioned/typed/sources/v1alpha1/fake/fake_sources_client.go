@@ -28,6 +28,10 @@ type FakeSourcesV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeSourcesV1alpha1) BlockchainNetworks() v1alpha1.BlockchainNetworkInterface {
+	return &FakeBlockchainNetworks{c}
+}
+
 func (c *FakeSourcesV1alpha1) BlockchainSources(namespace string) v1alpha1.BlockchainSourceInterface {
 	return &FakeBlockchainSources{c, namespace}
 }
@@ -28,6 +28,7 @@ import (
 
 type SourcesV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	BlockchainNetworksGetter
 	BlockchainSourcesGetter
 }
 
@@ -36,6 +37,10 @@ type SourcesV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *SourcesV1alpha1Client) BlockchainNetworks() BlockchainNetworkInterface {
+	return newBlockchainNetworks(c)
+}
+
 func (c *SourcesV1alpha1Client) BlockchainSources(namespace string) BlockchainSourceInterface {
 	return newBlockchainSources(c, namespace)
 }
@@ -53,6 +53,8 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=sources.knative.dev, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("blockchainnetworks"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sources().V1alpha1().BlockchainNetworks().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("blockchainsources"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sources().V1alpha1().BlockchainSources().Informer()}, nil
 
@@ -32,59 +32,58 @@ import (
 	v1alpha1 "knative.dev/eventing-blockchain/pkg/client/listers/sources/v1alpha1"
 )
 
-// GitHubSourceInformer provides access to a shared informer and lister for
-// GitHubSources.
-type GitHubSourceInformer interface {
+// BlockchainNetworkInformer provides access to a shared informer and lister for
+// BlockchainNetworks.
+type BlockchainNetworkInformer interface {
 	Informer() cache.SharedIndexInformer
-	Lister() v1alpha1.GitHubSourceLister
+	Lister() v1alpha1.BlockchainNetworkLister
 }
 
-type gitHubSourceInformer struct {
+type blockchainNetworkInformer struct {
 	factory          internalinterfaces.SharedInformerFactory
 	tweakListOptions internalinterfaces.TweakListOptionsFunc
-	namespace        string
 }
 
-// NewGitHubSourceInformer constructs a new informer for GitHubSource type.
+// NewBlockchainNetworkInformer constructs a new informer for BlockchainNetwork type.
 // Always prefer using an informer factory to get a shared informer instead of getting an independent
 // one. This reduces memory footprint and number of connections to the server.
-func NewGitHubSourceInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
-	return NewFilteredGitHubSourceInformer(client, namespace, resyncPeriod, indexers, nil)
+func NewBlockchainNetworkInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredBlockchainNetworkInformer(client, resyncPeriod, indexers, nil)
 }
 
-// NewFilteredGitHubSourceInformer constructs a new informer for GitHubSource type.
+// NewFilteredBlockchainNetworkInformer constructs a new informer for BlockchainNetwork type.
 // Always prefer using an informer factory to get a shared informer instead of getting an independent
 // one. This reduces memory footprint and number of connections to the server.
-func NewFilteredGitHubSourceInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+func NewFilteredBlockchainNetworkInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
 	return cache.NewSharedIndexInformer(
 		&cache.ListWatch{
 			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.SourcesV1alpha1().GitHubSources(namespace).List(context.TODO(), options)
+				return client.SourcesV1alpha1().BlockchainNetworks().List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.SourcesV1alpha1().GitHubSources(namespace).Watch(context.TODO(), options)
+				return client.SourcesV1alpha1().BlockchainNetworks().Watch(context.TODO(), options)
 			},
 		},
-		&sourcesv1alpha1.GitHubSource{},
+		&sourcesv1alpha1.BlockchainNetwork{},
 		resyncPeriod,
 		indexers,
 	)
 }
 
-func (f *gitHubSourceInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
-	return NewFilteredGitHubSourceInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+func (f *blockchainNetworkInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredBlockchainNetworkInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
 }
 
-func (f *gitHubSourceInformer) Informer() cache.SharedIndexInformer {
-	return f.factory.InformerFor(&sourcesv1alpha1.GitHubSource{}, f.defaultInformer)
+func (f *blockchainNetworkInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&sourcesv1alpha1.BlockchainNetwork{}, f.defaultInformer)
 }
 
-func (f *gitHubSourceInformer) Lister() v1alpha1.GitHubSourceLister {
-	return v1alpha1.NewGitHubSourceLister(f.Informer().GetIndexer())
+func (f *blockchainNetworkInformer) Lister() v1alpha1.BlockchainNetworkLister {
+	return v1alpha1.NewBlockchainNetworkLister(f.Informer().GetIndexer())
 }
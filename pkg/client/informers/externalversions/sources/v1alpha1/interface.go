@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// BlockchainNetworks returns a BlockchainNetworkInformer.
+	BlockchainNetworks() BlockchainNetworkInformer
 	// BlockchainSources returns a BlockchainSourceInformer.
 	BlockchainSources() BlockchainSourceInformer
 }
@@ -39,6 +41,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// BlockchainNetworks returns a BlockchainNetworkInformer.
+func (v *version) BlockchainNetworks() BlockchainNetworkInformer {
+	return &blockchainNetworkInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // BlockchainSources returns a BlockchainSourceInformer.
 func (v *version) BlockchainSources() BlockchainSourceInformer {
 	return &blockchainSourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
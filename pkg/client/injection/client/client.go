@@ -111,6 +111,133 @@ func (w *wrapSourcesV1alpha1) RESTClient() rest.Interface {
 	panic("RESTClient called on dynamic client!")
 }
 
+func (w *wrapSourcesV1alpha1) BlockchainNetworks() typedsourcesv1alpha1.BlockchainNetworkInterface {
+	return &wrapSourcesV1alpha1BlockchainNetworkImpl{
+		dyn: w.dyn.Resource(schema.GroupVersionResource{
+			Group:    "sources.knative.dev",
+			Version:  "v1alpha1",
+			Resource: "blockchainnetworks",
+		}),
+	}
+}
+
+type wrapSourcesV1alpha1BlockchainNetworkImpl struct {
+	dyn dynamic.NamespaceableResourceInterface
+}
+
+var _ typedsourcesv1alpha1.BlockchainNetworkInterface = (*wrapSourcesV1alpha1BlockchainNetworkImpl)(nil)
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) Create(ctx context.Context, in *v1alpha1.BlockchainNetwork, opts v1.CreateOptions) (*v1alpha1.BlockchainNetwork, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "sources.knative.dev",
+		Version: "v1alpha1",
+		Kind:    "BlockchainNetwork",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Create(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.BlockchainNetwork{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return w.dyn.Delete(ctx, name, opts)
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	return w.dyn.DeleteCollection(ctx, opts, listOpts)
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.BlockchainNetwork, error) {
+	uo, err := w.dyn.Get(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.BlockchainNetwork{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.BlockchainNetworkList, error) {
+	uo, err := w.dyn.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.BlockchainNetworkList{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.BlockchainNetwork, err error) {
+	uo, err := w.dyn.Patch(ctx, name, pt, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.BlockchainNetwork{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) Update(ctx context.Context, in *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (*v1alpha1.BlockchainNetwork, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "sources.knative.dev",
+		Version: "v1alpha1",
+		Kind:    "BlockchainNetwork",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.Update(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.BlockchainNetwork{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) UpdateStatus(ctx context.Context, in *v1alpha1.BlockchainNetwork, opts v1.UpdateOptions) (*v1alpha1.BlockchainNetwork, error) {
+	in.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "sources.knative.dev",
+		Version: "v1alpha1",
+		Kind:    "BlockchainNetwork",
+	})
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return nil, err
+	}
+	uo, err := w.dyn.UpdateStatus(ctx, uo, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := &v1alpha1.BlockchainNetwork{}
+	if err := convert(uo, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapSourcesV1alpha1BlockchainNetworkImpl) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return nil, errors.New("NYI: Watch")
+}
+
 func (w *wrapSourcesV1alpha1) BlockchainSources(namespace string) typedsourcesv1alpha1.BlockchainSourceInterface {
 	return &wrapSourcesV1alpha1BlockchainSourceImpl{
 		dyn: w.dyn.Resource(schema.GroupVersionResource{
@@ -16,7 +16,7 @@ limitations under the License.
 
 // Code generated by injection-gen. DO NOT EDIT.
 
-package githubsource
+package blockchainnetwork
 
 import (
 	context "context"
@@ -45,7 +45,7 @@ type Key struct{}
 
 func withInformer(ctx context.Context) (context.Context, controller.Informer) {
 	f := factory.Get(ctx)
-	inf := f.Sources().V1alpha1().GitHubSources()
+	inf := f.Sources().V1alpha1().BlockchainNetworks()
 	return context.WithValue(ctx, Key{}, inf), inf.Informer()
 }
 
@@ -55,38 +55,32 @@ func withDynamicInformer(ctx context.Context) context.Context {
 }
 
 // Get extracts the typed informer from the context.
-func Get(ctx context.Context) v1alpha1.GitHubSourceInformer {
+func Get(ctx context.Context) v1alpha1.BlockchainNetworkInformer {
 	untyped := ctx.Value(Key{})
 	if untyped == nil {
 		logging.FromContext(ctx).Panic(
-			"Unable to fetch knative.dev/eventing-blockchain/pkg/client/informers/externalversions/sources/v1alpha1.GitHubSourceInformer from context.")
+			"Unable to fetch knative.dev/eventing-blockchain/pkg/client/informers/externalversions/sources/v1alpha1.BlockchainNetworkInformer from context.")
 	}
-	return untyped.(v1alpha1.GitHubSourceInformer)
+	return untyped.(v1alpha1.BlockchainNetworkInformer)
 }
 
 type wrapper struct {
 	client versioned.Interface
 
-	namespace string
-
 	resourceVersion string
 }
 
-var _ v1alpha1.GitHubSourceInformer = (*wrapper)(nil)
-var _ sourcesv1alpha1.GitHubSourceLister = (*wrapper)(nil)
+var _ v1alpha1.BlockchainNetworkInformer = (*wrapper)(nil)
+var _ sourcesv1alpha1.BlockchainNetworkLister = (*wrapper)(nil)
 
 func (w *wrapper) Informer() cache.SharedIndexInformer {
-	return cache.NewSharedIndexInformer(nil, &apissourcesv1alpha1.GitHubSource{}, 0, nil)
+	return cache.NewSharedIndexInformer(nil, &apissourcesv1alpha1.BlockchainNetwork{}, 0, nil)
 }
 
-func (w *wrapper) Lister() sourcesv1alpha1.GitHubSourceLister {
+func (w *wrapper) Lister() sourcesv1alpha1.BlockchainNetworkLister {
 	return w
 }
 
-func (w *wrapper) GitHubSources(namespace string) sourcesv1alpha1.GitHubSourceNamespaceLister {
-	return &wrapper{client: w.client, namespace: namespace, resourceVersion: w.resourceVersion}
-}
-
 // SetResourceVersion allows consumers to adjust the minimum resourceVersion
 // used by the underlying client.  It is not accessible via the standard
 // lister interface, but can be accessed through a user-defined interface and
@@ -95,8 +89,8 @@ func (w *wrapper) SetResourceVersion(resourceVersion string) {
 	w.resourceVersion = resourceVersion
 }
 
-func (w *wrapper) List(selector labels.Selector) (ret []*apissourcesv1alpha1.GitHubSource, err error) {
-	lo, err := w.client.SourcesV1alpha1().GitHubSources(w.namespace).List(context.TODO(), v1.ListOptions{
+func (w *wrapper) List(selector labels.Selector) (ret []*apissourcesv1alpha1.BlockchainNetwork, err error) {
+	lo, err := w.client.SourcesV1alpha1().BlockchainNetworks().List(context.TODO(), v1.ListOptions{
 		LabelSelector:   selector.String(),
 		ResourceVersion: w.resourceVersion,
 	})
@@ -109,8 +103,8 @@ func (w *wrapper) List(selector labels.Selector) (ret []*apissourcesv1alpha1.Git
 	return ret, nil
 }
 
-func (w *wrapper) Get(name string) (*apissourcesv1alpha1.GitHubSource, error) {
-	return w.client.SourcesV1alpha1().GitHubSources(w.namespace).Get(context.TODO(), name, v1.GetOptions{
+func (w *wrapper) Get(name string) (*apissourcesv1alpha1.BlockchainNetwork, error) {
+	return w.client.SourcesV1alpha1().BlockchainNetworks().Get(context.TODO(), name, v1.GetOptions{
 		ResourceVersion: w.resourceVersion,
 	})
 }
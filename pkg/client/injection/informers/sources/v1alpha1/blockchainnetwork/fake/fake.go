@@ -22,12 +22,12 @@ import (
 	context "context"
 
 	fake "knative.dev/eventing-blockchain/pkg/client/injection/informers/factory/fake"
-	githubsource "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/githubsource"
+	blockchainnetwork "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainnetwork"
 	controller "knative.dev/pkg/controller"
 	injection "knative.dev/pkg/injection"
 )
 
-var Get = githubsource.Get
+var Get = blockchainnetwork.Get
 
 func init() {
 	injection.Fake.RegisterInformer(withInformer)
@@ -35,6 +35,6 @@ func init() {
 
 func withInformer(ctx context.Context) (context.Context, controller.Informer) {
 	f := fake.Get(ctx)
-	inf := f.Sources().V1alpha1().GitHubSources()
-	return context.WithValue(ctx, githubsource.Key{}, inf), inf.Informer()
+	inf := f.Sources().V1alpha1().BlockchainNetworks()
+	return context.WithValue(ctx, blockchainnetwork.Key{}, inf), inf.Informer()
 }
@@ -55,7 +55,7 @@ func withInformer(ctx context.Context) (context.Context, []controller.Informer)
 	infs := []controller.Informer{}
 	for _, selector := range labelSelectors {
 		f := filtered.Get(ctx, selector)
-		inf := f.Sources().V1alpha1().GitHubSources()
+		inf := f.Sources().V1alpha1().BlockchainNetworks()
 		ctx = context.WithValue(ctx, Key{Selector: selector}, inf)
 		infs = append(infs, inf.Informer())
 	}
@@ -77,45 +77,39 @@ func withDynamicInformer(ctx context.Context) context.Context {
 }
 
 // Get extracts the typed informer from the context.
-func Get(ctx context.Context, selector string) v1alpha1.GitHubSourceInformer {
+func Get(ctx context.Context, selector string) v1alpha1.BlockchainNetworkInformer {
 	untyped := ctx.Value(Key{Selector: selector})
 	if untyped == nil {
 		logging.FromContext(ctx).Panicf(
-			"Unable to fetch knative.dev/eventing-blockchain/pkg/client/informers/externalversions/sources/v1alpha1.GitHubSourceInformer with selector %s from context.", selector)
+			"Unable to fetch knative.dev/eventing-blockchain/pkg/client/informers/externalversions/sources/v1alpha1.BlockchainNetworkInformer with selector %s from context.", selector)
 	}
-	return untyped.(v1alpha1.GitHubSourceInformer)
+	return untyped.(v1alpha1.BlockchainNetworkInformer)
 }
 
 type wrapper struct {
 	client versioned.Interface
 
-	namespace string
-
 	selector string
 }
 
-var _ v1alpha1.GitHubSourceInformer = (*wrapper)(nil)
-var _ sourcesv1alpha1.GitHubSourceLister = (*wrapper)(nil)
+var _ v1alpha1.BlockchainNetworkInformer = (*wrapper)(nil)
+var _ sourcesv1alpha1.BlockchainNetworkLister = (*wrapper)(nil)
 
 func (w *wrapper) Informer() cache.SharedIndexInformer {
-	return cache.NewSharedIndexInformer(nil, &apissourcesv1alpha1.GitHubSource{}, 0, nil)
+	return cache.NewSharedIndexInformer(nil, &apissourcesv1alpha1.BlockchainNetwork{}, 0, nil)
 }
 
-func (w *wrapper) Lister() sourcesv1alpha1.GitHubSourceLister {
+func (w *wrapper) Lister() sourcesv1alpha1.BlockchainNetworkLister {
 	return w
 }
 
-func (w *wrapper) GitHubSources(namespace string) sourcesv1alpha1.GitHubSourceNamespaceLister {
-	return &wrapper{client: w.client, namespace: namespace, selector: w.selector}
-}
-
-func (w *wrapper) List(selector labels.Selector) (ret []*apissourcesv1alpha1.GitHubSource, err error) {
+func (w *wrapper) List(selector labels.Selector) (ret []*apissourcesv1alpha1.BlockchainNetwork, err error) {
 	reqs, err := labels.ParseToRequirements(w.selector)
 	if err != nil {
 		return nil, err
 	}
 	selector = selector.Add(reqs...)
-	lo, err := w.client.SourcesV1alpha1().GitHubSources(w.namespace).List(context.TODO(), v1.ListOptions{
+	lo, err := w.client.SourcesV1alpha1().BlockchainNetworks().List(context.TODO(), v1.ListOptions{
 		LabelSelector: selector.String(),
 		// TODO(mattmoor): Incorporate resourceVersion bounds based on staleness criteria.
 	})
@@ -128,9 +122,9 @@ func (w *wrapper) List(selector labels.Selector) (ret []*apissourcesv1alpha1.Git
 	return ret, nil
 }
 
-func (w *wrapper) Get(name string) (*apissourcesv1alpha1.GitHubSource, error) {
+func (w *wrapper) Get(name string) (*apissourcesv1alpha1.BlockchainNetwork, error) {
 	// TODO(mattmoor): Check that the fetched object matches the selector.
-	return w.client.SourcesV1alpha1().GitHubSources(w.namespace).Get(context.TODO(), name, v1.GetOptions{
+	return w.client.SourcesV1alpha1().BlockchainNetworks().Get(context.TODO(), name, v1.GetOptions{
 		// TODO(mattmoor): Incorporate resourceVersion bounds based on staleness criteria.
 	})
 }
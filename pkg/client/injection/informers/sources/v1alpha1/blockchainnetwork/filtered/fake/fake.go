@@ -22,7 +22,7 @@ import (
 	context "context"
 
 	factoryfiltered "knative.dev/eventing-blockchain/pkg/client/injection/informers/factory/filtered"
-	filtered "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/githubsource/filtered"
+	filtered "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainnetwork/filtered"
 	controller "knative.dev/pkg/controller"
 	injection "knative.dev/pkg/injection"
 	logging "knative.dev/pkg/logging"
@@ -44,7 +44,7 @@ func withInformer(ctx context.Context) (context.Context, []controller.Informer)
 	infs := []controller.Informer{}
 	for _, selector := range labelSelectors {
 		f := factoryfiltered.Get(ctx, selector)
-		inf := f.Sources().V1alpha1().GitHubSources()
+		inf := f.Sources().V1alpha1().BlockchainNetworks()
 		ctx = context.WithValue(ctx, filtered.Key{Selector: selector}, inf)
 		infs = append(infs, inf.Informer())
 	}
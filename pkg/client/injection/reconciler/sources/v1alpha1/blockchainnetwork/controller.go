@@ -16,7 +16,7 @@ limitations under the License.
 
 // Code generated by injection-gen. DO NOT EDIT.
 
-package githubsource
+package blockchainnetwork
 
 import (
 	context "context"
@@ -34,7 +34,7 @@ import (
 	record "k8s.io/client-go/tools/record"
 	versionedscheme "knative.dev/eventing-blockchain/pkg/client/clientset/versioned/scheme"
 	client "knative.dev/eventing-blockchain/pkg/client/injection/client"
-	githubsource "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/githubsource"
+	blockchainnetwork "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainnetwork"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	controller "knative.dev/pkg/controller"
 	logging "knative.dev/pkg/logging"
@@ -43,8 +43,8 @@ import (
 )
 
 const (
-	defaultControllerAgentName = "githubsource-controller"
-	defaultFinalizerName       = "githubsources.sources.knative.dev"
+	defaultControllerAgentName = "blockchainnetwork-controller"
+	defaultFinalizerName       = "blockchainnetworks.sources.knative.dev"
 )
 
 // NewImpl returns a controller.Impl that handles queuing and feeding work from
@@ -59,9 +59,9 @@ func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsF
 		logger.Fatal("Up to one options function is supported, found: ", len(optionsFns))
 	}
 
-	githubsourceInformer := githubsource.Get(ctx)
+	blockchainnetworkInformer := blockchainnetwork.Get(ctx)
 
-	lister := githubsourceInformer.Lister()
+	lister := blockchainnetworkInformer.Lister()
 
 	var promoteFilterFunc func(obj interface{}) bool
 
@@ -98,7 +98,7 @@ func NewImpl(ctx context.Context, r Interface, optionsFns ...controller.OptionsF
 
 	logger = logger.With(
 		zap.String(logkey.ControllerType, ctrTypeName),
-		zap.String(logkey.Kind, "sources.knative.dev.GitHubSource"),
+		zap.String(logkey.Kind, "sources.knative.dev.BlockchainNetwork"),
 	)
 
 	impl := controller.NewContext(ctx, rec, controller.ControllerOptions{WorkQueueName: ctrTypeName, Logger: logger})
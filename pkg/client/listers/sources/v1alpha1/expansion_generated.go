@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+// BlockchainNetworkListerExpansion allows custom methods to be added to
+// BlockchainNetworkLister.
+type BlockchainNetworkListerExpansion interface{}
+
 // BlockchainSourceListerExpansion allows custom methods to be added to
 // BlockchainSourceLister.
 type BlockchainSourceListerExpansion interface{}
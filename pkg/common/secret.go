@@ -17,25 +17,23 @@ limitations under the License.
 package common
 
 import (
-	"context"
 	"errors"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 )
 
 // SecretFrom gets the value of the Secret key referenced by sel from the
-// Kubernetes cluster.
-func SecretFrom(ctx context.Context, secretCli clientcorev1.SecretInterface, sel *corev1.SecretKeySelector) (string, error) {
+// Secrets of a namespace.
+func SecretFrom(secrets corev1listers.SecretNamespaceLister, sel *corev1.SecretKeySelector) (string, error) {
 	if sel == nil {
 		return "", errors.New("missing Secret key selector")
 	}
 
-	secret, err := secretCli.Get(ctx, sel.Name, metav1.GetOptions{})
+	secret, err := secrets.Get(sel.Name)
 	if err != nil {
-		return "", fmt.Errorf("getting Secret from cluster: %w", err)
+		return "", fmt.Errorf("getting Secret: %w", err)
 	}
 
 	secretVal, ok := secret.Data[sel.Key]
@@ -0,0 +1,229 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ABI holds the events declared by a contract ABI. Functions, errors and
+// constructors are ignored.
+type ABI struct {
+	// Events are the declared events, in declaration order.
+	Events []*Event
+
+	byTopic map[string]*Event
+}
+
+// Event is an event declared by a contract ABI.
+type Event struct {
+	Name      string
+	Inputs    []Argument
+	Anonymous bool
+
+	signature string
+	topic     string
+}
+
+// Argument is an input of an event.
+type Argument struct {
+	Name    string
+	Type    *Type
+	Indexed bool
+}
+
+// abiEntry is an entry of the JSON description of a contract ABI.
+type abiEntry struct {
+	Type      string     `json:"type"`
+	Name      string     `json:"name"`
+	Inputs    []abiParam `json:"inputs"`
+	Anonymous bool       `json:"anonymous"`
+}
+
+type abiParam struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Indexed    bool       `json:"indexed"`
+	Components []abiParam `json:"components"`
+}
+
+// ParseABI parses the JSON description of a contract ABI, as produced by the
+// Solidity compiler.
+func ParseABI(data []byte) (*ABI, error) {
+	var entries []abiEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid ABI: %w", err)
+	}
+
+	abi := &ABI{byTopic: make(map[string]*Event)}
+	for _, entry := range entries {
+		if entry.Type != "event" {
+			continue
+		}
+		if entry.Name == "" {
+			return nil, errors.New("invalid ABI: event without a name")
+		}
+
+		event := &Event{
+			Name:      entry.Name,
+			Anonymous: entry.Anonymous,
+		}
+		types := make([]string, 0, len(entry.Inputs))
+		for i, in := range entry.Inputs {
+			t, err := newType(in.Type, in.Components)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ABI: event %s input %d: %w", entry.Name, i, err)
+			}
+			event.Inputs = append(event.Inputs, Argument{
+				Name:    in.Name,
+				Type:    t,
+				Indexed: in.Indexed,
+			})
+			types = append(types, t.String())
+		}
+		event.signature = fmt.Sprintf("%s(%s)", entry.Name, strings.Join(types, ","))
+		event.topic = EventTopic(event.signature)
+
+		abi.Events = append(abi.Events, event)
+		if !event.Anonymous {
+			abi.byTopic[event.topic] = event
+		}
+	}
+	return abi, nil
+}
+
+// EventByName returns the first event with the given name, or nil.
+func (a *ABI) EventByName(name string) *Event {
+	for _, e := range a.Events {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// EventByTopic returns the non-anonymous event identified by the first topic
+// of its logs, or nil.
+func (a *ABI) EventByTopic(topic string) *Event {
+	return a.byTopic[strings.ToLower(topic)]
+}
+
+// Signature returns the canonical signature of the event, e.g.
+// "Transfer(address,address,uint256)".
+func (e *Event) Signature() string {
+	return e.signature
+}
+
+// Topic returns the hash of the event signature, which is the first topic of
+// the logs of non-anonymous events.
+func (e *Event) Topic() string {
+	return e.topic
+}
+
+// Declaration returns the declaration of the event along with the names of
+// its arguments, e.g.
+// "Transfer(address indexed from, address indexed to, uint256 value)".
+func (e *Event) Declaration() string {
+	args := make([]string, 0, len(e.Inputs))
+	for _, in := range e.Inputs {
+		arg := in.Type.String()
+		if in.Indexed {
+			arg += " indexed"
+		}
+		if in.Name != "" {
+			arg += " " + in.Name
+		}
+		args = append(args, arg)
+	}
+	declaration := fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", "))
+	if e.Anonymous {
+		declaration += " anonymous"
+	}
+	return declaration
+}
+
+// DecodeLog decodes the topics and data of a log emitted for the event into
+// its named arguments. Unnamed arguments are named after their position, e.g.
+// "arg0". Indexed arguments of dynamic types are only known by their hash,
+// which is returned as is.
+func (e *Event) DecodeLog(topics []string, data string) (map[string]interface{}, error) {
+	if !e.Anonymous {
+		if len(topics) == 0 || !strings.EqualFold(topics[0], e.topic) {
+			return nil, fmt.Errorf("log is not a %s event", e.Name)
+		}
+		topics = topics[1:]
+	}
+
+	raw, err := decodeHex(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log data: %w", err)
+	}
+
+	var nonIndexed []*Type
+	for _, in := range e.Inputs {
+		if !in.Indexed {
+			nonIndexed = append(nonIndexed, in.Type)
+		}
+	}
+	values, err := decodeTuple(nonIndexed, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log data: %w", err)
+	}
+
+	args := make(map[string]interface{}, len(e.Inputs))
+	for i, in := range e.Inputs {
+		name := in.Name
+		if name == "" {
+			name = "arg" + strconv.Itoa(i)
+		}
+
+		if !in.Indexed {
+			args[name], values = values[0], values[1:]
+			continue
+		}
+
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("missing topic for indexed argument %s", name)
+		}
+		topic := topics[0]
+		topics = topics[1:]
+		if in.Type.isDynamic() || in.Type.Kind == ArrayKind || in.Type.Kind == TupleKind {
+			args[name] = strings.ToLower(topic)
+			continue
+		}
+		word, err := decodeHex(topic)
+		if err != nil || len(word) != wordSize {
+			return nil, fmt.Errorf("invalid topic %q for indexed argument %s", topic, name)
+		}
+		if args[name], err = decodeValue(in.Type, word); err != nil {
+			return nil, fmt.Errorf("invalid topic for indexed argument %s: %w", name, err)
+		}
+	}
+	return args, nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("%q is missing the 0x prefix", s)
+	}
+	return hex.DecodeString(s[2:])
+}
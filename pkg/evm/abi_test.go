@@ -0,0 +1,245 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testABI = `[
+  {"type": "function", "name": "transfer", "inputs": [{"name": "to", "type": "address"}]},
+  {"type": "event", "name": "Transfer", "anonymous": false, "inputs": [
+    {"name": "from", "type": "address", "indexed": true},
+    {"name": "to", "type": "address", "indexed": true},
+    {"name": "value", "type": "uint256", "indexed": false}
+  ]},
+  {"type": "event", "name": "Updated", "inputs": [
+    {"name": "tag", "type": "string", "indexed": true},
+    {"name": "delta", "type": "int8"},
+    {"name": "", "type": "string"},
+    {"name": "values", "type": "uint256[]"},
+    {"name": "item", "type": "tuple", "components": [
+      {"name": "owner", "type": "address"},
+      {"name": "payload", "type": "bytes"}
+    ]}
+  ]},
+  {"type": "event", "name": "Raw", "anonymous": true, "inputs": [
+    {"name": "ok", "type": "bool", "indexed": true},
+    {"name": "id", "type": "bytes4"}
+  ]}
+]`
+
+// words concatenates 32-byte words given as hex, left-padding each with
+// zeros unless it starts with "-", in which case it is right-padded.
+func words(ws ...string) string {
+	var b strings.Builder
+	b.WriteString("0x")
+	for _, w := range ws {
+		if strings.HasPrefix(w, "-") {
+			w = w[1:]
+			b.WriteString(w + strings.Repeat("0", 64-len(w)))
+		} else {
+			b.WriteString(strings.Repeat("0", 64-len(w)) + w)
+		}
+	}
+	return b.String()
+}
+
+func TestParseABI(t *testing.T) {
+	abi, err := ParseABI([]byte(testABI))
+	if err != nil {
+		t.Fatalf("ParseABI() = %v", err)
+	}
+	if len(abi.Events) != 3 {
+		t.Fatalf("got %d events, want 3", len(abi.Events))
+	}
+
+	transfer := abi.EventByName("Transfer")
+	if want := "Transfer(address,address,uint256)"; transfer.Signature() != want {
+		t.Errorf("Signature() = %s, want %s", transfer.Signature(), want)
+	}
+	if want := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"; transfer.Topic() != want {
+		t.Errorf("Topic() = %s, want %s", transfer.Topic(), want)
+	}
+	if got := abi.EventByTopic(strings.ToUpper(transfer.Topic())); got != transfer {
+		t.Errorf("EventByTopic() = %v, want Transfer", got)
+	}
+
+	if want := "Updated(string,int8,string,uint256[],(address,bytes))"; abi.EventByName("Updated").Signature() != want {
+		t.Errorf("Signature() = %s, want %s", abi.EventByName("Updated").Signature(), want)
+	}
+
+	if want := "Transfer(address indexed from, address indexed to, uint256 value)"; transfer.Declaration() != want {
+		t.Errorf("Declaration() = %s, want %s", transfer.Declaration(), want)
+	}
+	if want := "Updated(string indexed tag, int8 delta, string, uint256[] values, (address,bytes) item)"; abi.EventByName("Updated").Declaration() != want {
+		t.Errorf("Declaration() = %s, want %s", abi.EventByName("Updated").Declaration(), want)
+	}
+	if want := "Raw(bool indexed ok, bytes4 id) anonymous"; abi.EventByName("Raw").Declaration() != want {
+		t.Errorf("Declaration() = %s, want %s", abi.EventByName("Raw").Declaration(), want)
+	}
+
+	// Anonymous events cannot be found by topic.
+	raw := abi.EventByName("Raw")
+	if got := abi.EventByTopic(raw.Topic()); got != nil {
+		t.Errorf("EventByTopic() = %v for an anonymous event", got)
+	}
+}
+
+func TestParseABIErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not json":       `{`,
+		"unnamed event":  `[{"type": "event", "inputs": []}]`,
+		"unknown type":   `[{"type": "event", "name": "E", "inputs": [{"type": "fixed128x18"}]}]`,
+		"bad int size":   `[{"type": "event", "name": "E", "inputs": [{"type": "uint7"}]}]`,
+		"bad bytes size": `[{"type": "event", "name": "E", "inputs": [{"type": "bytes33"}]}]`,
+		"bad array":      `[{"type": "event", "name": "E", "inputs": [{"type": "uint256[x]"}]}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseABI([]byte(data)); err == nil {
+				t.Errorf("ParseABI() = nil, wanted error")
+			}
+		})
+	}
+}
+
+func TestDecodeLog(t *testing.T) {
+	abi, err := ParseABI([]byte(testABI))
+	if err != nil {
+		t.Fatalf("ParseABI() = %v", err)
+	}
+	tagHash := EventTopic("release")
+
+	tests := map[string]struct {
+		event  string
+		topics []string
+		data   string
+		want   map[string]interface{}
+	}{
+		"transfer": {
+			event: "Transfer",
+			topics: []string{
+				abi.EventByName("Transfer").Topic(),
+				words("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"),
+				words("fb6916095ca1df60bb79ce92ce3ea74c37c5d359"),
+			},
+			data: words("de0b6b3a7640000"),
+			want: map[string]interface{}{
+				"from":  "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+				"to":    "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+				"value": "1000000000000000000",
+			},
+		},
+		"dynamic types": {
+			event:  "Updated",
+			topics: []string{abi.EventByName("Updated").Topic(), tagHash},
+			data: words(
+				strings.Repeat("f", 63)+"e", // delta
+				"80",                        // offset of arg2
+				"c0",                        // offset of values
+				"120",                       // offset of item
+				"5", "-"+fmt.Sprintf("%x", "hello"),
+				"2", "1", "2",
+				"dbf03b407c01e7cd3cbea99509d93f8dddc8c6fb", "40", "2", "-beef",
+			),
+			want: map[string]interface{}{
+				"tag":    tagHash,
+				"delta":  "-2",
+				"arg2":   "hello",
+				"values": []interface{}{"1", "2"},
+				"item": map[string]interface{}{
+					"owner":   "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+					"payload": "0xbeef",
+				},
+			},
+		},
+		"anonymous": {
+			event:  "Raw",
+			topics: []string{words("1")},
+			data:   words("-12345678"),
+			want: map[string]interface{}{
+				"ok": true,
+				"id": "0x12345678",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := abi.EventByName(tc.event).DecodeLog(tc.topics, tc.data)
+			if err != nil {
+				t.Fatalf("DecodeLog() = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected arguments (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeLogErrors(t *testing.T) {
+	abi, err := ParseABI([]byte(testABI))
+	if err != nil {
+		t.Fatalf("ParseABI() = %v", err)
+	}
+	transfer := abi.EventByName("Transfer")
+	from := words("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+
+	tests := map[string]struct {
+		event  *Event
+		topics []string
+		data   string
+	}{
+		"wrong event": {
+			event:  transfer,
+			topics: []string{abi.EventByName("Updated").Topic(), from, from},
+			data:   words("1"),
+		},
+		"missing topic": {
+			event:  transfer,
+			topics: []string{transfer.Topic(), from},
+			data:   words("1"),
+		},
+		"short data": {
+			event:  transfer,
+			topics: []string{transfer.Topic(), from, from},
+			data:   "0x01",
+		},
+		"offset out of bounds": {
+			event:  abi.EventByName("Updated"),
+			topics: []string{abi.EventByName("Updated").Topic(), from},
+			data:   words("1", "ffff", "c0", "120"),
+		},
+		"invalid bool": {
+			event:  abi.EventByName("Raw"),
+			topics: []string{words("2")},
+			data:   words("1"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := tc.event.DecodeLog(tc.topics, tc.data); err == nil {
+				t.Errorf("DecodeLog() = nil, wanted error")
+			}
+		})
+	}
+}
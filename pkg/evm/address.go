@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// AddressLength is the length in bytes of an account address.
+const AddressLength = 20
+
+// ParseAddress parses a 0x-prefixed hex address, regardless of its case.
+func ParseAddress(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("address %q is missing the 0x prefix", s)
+	}
+	b, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", s, err)
+	}
+	if len(b) != AddressLength {
+		return nil, fmt.Errorf("invalid address %q: expected %d bytes, got %d", s, AddressLength, len(b))
+	}
+	return b, nil
+}
+
+// ChecksumAddress returns the EIP-55 mixed-case encoding of an address.
+func ChecksumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	hash := hex.EncodeToString(Keccak256([]byte(lower)))
+
+	out := make([]byte, len(lower))
+	for i := 0; i < len(lower); i++ {
+		c := lower[i]
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			c -= 'a' - 'A'
+		}
+		out[i] = c
+	}
+	return "0x" + string(out)
+}
+
+// IsChecksumAddress reports whether s is a valid address whose case matches
+// its EIP-55 checksum.
+func IsChecksumAddress(s string) bool {
+	addr, err := ParseAddress(s)
+	if err != nil {
+		return false
+	}
+	return ChecksumAddress(addr) == s
+}
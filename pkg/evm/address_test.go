@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	// Test vectors from EIP-55.
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		addr, err := ParseAddress(strings.ToLower(want))
+		if err != nil {
+			t.Fatalf("ParseAddress() = %v", err)
+		}
+		if got := ChecksumAddress(addr); got != want {
+			t.Errorf("ChecksumAddress() = %s, want %s", got, want)
+		}
+		if !IsChecksumAddress(want) {
+			t.Errorf("IsChecksumAddress(%s) = false", want)
+		}
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	for name, s := range map[string]string{
+		"missing prefix": "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"not hex":        "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaez",
+		"too short":      "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseAddress(s); err == nil {
+				t.Errorf("ParseAddress(%s) = nil, wanted error", s)
+			}
+		})
+	}
+
+	if IsChecksumAddress("0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed") {
+		t.Error("IsChecksumAddress() = true for a wrong checksum")
+	}
+}
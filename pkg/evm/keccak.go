@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package evm contains helpers to work with the data of EVM-compatible
+// chains: hashes, addresses and contract ABIs.
+package evm
+
+import (
+	"encoding/hex"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Keccak256 returns the Keccak-256 hash of the concatenation of data, as
+// used by the EVM (not the finalized SHA3-256).
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// EventTopic returns the topic identifying an event in logs, the
+// Keccak-256 hash of its canonical signature, e.g.
+// "Transfer(address,address,uint256)".
+func EventTopic(signature string) string {
+	return "0x" + hex.EncodeToString(Keccak256([]byte(signature)))
+}
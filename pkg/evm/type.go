@@ -0,0 +1,336 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evm
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// wordSize is the size in bytes of an ABI-encoded word.
+const wordSize = 32
+
+// Kind is the kind of an ABI type.
+type Kind int
+
+const (
+	UintKind Kind = iota
+	IntKind
+	AddressKind
+	BoolKind
+	FixedBytesKind
+	BytesKind
+	StringKind
+	SliceKind
+	ArrayKind
+	TupleKind
+)
+
+// Type is an ABI type.
+type Type struct {
+	Kind Kind
+	// Size is the number of bits of integers, the number of bytes of fixed
+	// size byte arrays and the length of fixed size arrays.
+	Size int
+	// Elem is the type of the elements of arrays and slices.
+	Elem *Type
+	// Components are the types of the fields of tuples, named after
+	// ComponentNames.
+	Components     []*Type
+	ComponentNames []string
+}
+
+// newType parses an ABI type such as "uint256", "address[]" or "tuple[2]".
+// components describe the fields of tuple types.
+func newType(s string, components []abiParam) (*Type, error) {
+	if strings.HasSuffix(s, "]") {
+		i := strings.LastIndex(s, "[")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid type %q", s)
+		}
+		elem, err := newType(s[:i], components)
+		if err != nil {
+			return nil, err
+		}
+		dim := s[i+1 : len(s)-1]
+		if dim == "" {
+			return &Type{Kind: SliceKind, Elem: elem}, nil
+		}
+		n, err := strconv.Atoi(dim)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid array length in type %q", s)
+		}
+		return &Type{Kind: ArrayKind, Size: n, Elem: elem}, nil
+	}
+
+	switch {
+	case s == "address":
+		return &Type{Kind: AddressKind}, nil
+	case s == "bool":
+		return &Type{Kind: BoolKind}, nil
+	case s == "string":
+		return &Type{Kind: StringKind}, nil
+	case s == "bytes":
+		return &Type{Kind: BytesKind}, nil
+	case s == "tuple":
+		t := &Type{Kind: TupleKind}
+		for i, c := range components {
+			ct, err := newType(c.Type, c.Components)
+			if err != nil {
+				return nil, fmt.Errorf("tuple component %d: %w", i, err)
+			}
+			t.Components = append(t.Components, ct)
+			t.ComponentNames = append(t.ComponentNames, c.Name)
+		}
+		return t, nil
+	case strings.HasPrefix(s, "bytes"):
+		n, err := strconv.Atoi(strings.TrimPrefix(s, "bytes"))
+		if err != nil || n < 1 || n > wordSize {
+			return nil, fmt.Errorf("invalid type %q", s)
+		}
+		return &Type{Kind: FixedBytesKind, Size: n}, nil
+	case strings.HasPrefix(s, "uint"):
+		n, err := intSize(strings.TrimPrefix(s, "uint"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q", s)
+		}
+		return &Type{Kind: UintKind, Size: n}, nil
+	case strings.HasPrefix(s, "int"):
+		n, err := intSize(strings.TrimPrefix(s, "int"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q", s)
+		}
+		return &Type{Kind: IntKind, Size: n}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", s)
+}
+
+// intSize parses the number of bits of an integer type, which defaults to
+// 256.
+func intSize(s string) (int, error) {
+	if s == "" {
+		return 256, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if n < 8 || n > 256 || n%8 != 0 {
+		return 0, errors.New("integer size must be a multiple of 8 between 8 and 256")
+	}
+	return n, nil
+}
+
+// String returns the canonical name of the type, as used in signatures.
+func (t *Type) String() string {
+	switch t.Kind {
+	case UintKind:
+		return "uint" + strconv.Itoa(t.Size)
+	case IntKind:
+		return "int" + strconv.Itoa(t.Size)
+	case AddressKind:
+		return "address"
+	case BoolKind:
+		return "bool"
+	case FixedBytesKind:
+		return "bytes" + strconv.Itoa(t.Size)
+	case BytesKind:
+		return "bytes"
+	case StringKind:
+		return "string"
+	case SliceKind:
+		return t.Elem.String() + "[]"
+	case ArrayKind:
+		return t.Elem.String() + "[" + strconv.Itoa(t.Size) + "]"
+	case TupleKind:
+		names := make([]string, 0, len(t.Components))
+		for _, c := range t.Components {
+			names = append(names, c.String())
+		}
+		return "(" + strings.Join(names, ",") + ")"
+	}
+	return ""
+}
+
+// isDynamic reports whether values of the type are encoded out of place,
+// behind an offset.
+func (t *Type) isDynamic() bool {
+	switch t.Kind {
+	case BytesKind, StringKind, SliceKind:
+		return true
+	case ArrayKind:
+		return t.Elem.isDynamic()
+	case TupleKind:
+		for _, c := range t.Components {
+			if c.isDynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize returns the number of bytes the type takes in the head of its
+// enclosing tuple.
+func (t *Type) headSize() int {
+	if t.isDynamic() {
+		return wordSize
+	}
+	switch t.Kind {
+	case ArrayKind:
+		return t.Size * t.Elem.headSize()
+	case TupleKind:
+		size := 0
+		for _, c := range t.Components {
+			size += c.headSize()
+		}
+		return size
+	}
+	return wordSize
+}
+
+// decodeTuple decodes the values of types encoded one after the other in
+// data. Offsets of dynamic values are relative to the start of data.
+func decodeTuple(types []*Type, data []byte) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(types))
+	pos := 0
+	for _, t := range types {
+		var (
+			v   interface{}
+			err error
+		)
+		if t.isDynamic() {
+			offset, oerr := readLength(data, pos)
+			if oerr != nil {
+				return nil, oerr
+			}
+			if offset > len(data) {
+				return nil, fmt.Errorf("offset %d out of bounds", offset)
+			}
+			v, err = decodeValue(t, data[offset:])
+			pos += wordSize
+		} else {
+			if pos+t.headSize() > len(data) {
+				return nil, errors.New("data too short")
+			}
+			v, err = decodeValue(t, data[pos:])
+			pos += t.headSize()
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// decodeValue decodes a value of type t starting at the beginning of data.
+// Integers are returned as decimal strings, addresses in their EIP-55 form
+// and byte arrays as 0x-prefixed hex.
+func decodeValue(t *Type, data []byte) (interface{}, error) {
+	switch t.Kind {
+	case SliceKind:
+		n, err := readLength(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		// Every element takes at least a word, which bounds the length.
+		if n > (len(data)-wordSize)/wordSize {
+			return nil, fmt.Errorf("slice length %d out of bounds", n)
+		}
+		return decodeTuple(repeat(t.Elem, n), data[wordSize:])
+	case ArrayKind:
+		return decodeTuple(repeat(t.Elem, t.Size), data)
+	case TupleKind:
+		values, err := decodeTuple(t.Components, data)
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]interface{}, len(values))
+		for i, v := range values {
+			name := t.ComponentNames[i]
+			if name == "" {
+				name = "arg" + strconv.Itoa(i)
+			}
+			fields[name] = v
+		}
+		return fields, nil
+	case BytesKind, StringKind:
+		n, err := readLength(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		if n > len(data)-wordSize {
+			return nil, fmt.Errorf("length %d out of bounds", n)
+		}
+		b := data[wordSize : wordSize+n]
+		if t.Kind == StringKind {
+			return string(b), nil
+		}
+		return "0x" + hex.EncodeToString(b), nil
+	}
+
+	if len(data) < wordSize {
+		return nil, errors.New("data too short")
+	}
+	word := data[:wordSize]
+	switch t.Kind {
+	case UintKind:
+		return new(big.Int).SetBytes(word).String(), nil
+	case IntKind:
+		v := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), wordSize*8))
+		}
+		return v.String(), nil
+	case AddressKind:
+		return ChecksumAddress(word[wordSize-AddressLength:]), nil
+	case BoolKind:
+		v := new(big.Int).SetBytes(word)
+		if v.BitLen() > 1 {
+			return nil, fmt.Errorf("invalid bool 0x%s", hex.EncodeToString(word))
+		}
+		return v.BitLen() == 1, nil
+	case FixedBytesKind:
+		return "0x" + hex.EncodeToString(word[:t.Size]), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// readLength reads the word at pos of data as a length or an offset.
+func readLength(data []byte, pos int) (int, error) {
+	if pos+wordSize > len(data) {
+		return 0, errors.New("data too short")
+	}
+	v := new(big.Int).SetBytes(data[pos : pos+wordSize])
+	if !v.IsInt64() || v.Int64() > int64(len(data)) {
+		return 0, fmt.Errorf("length or offset %s out of bounds", v)
+	}
+	return int(v.Int64()), nil
+}
+
+func repeat(t *Type, n int) []*Type {
+	types := make([]*Type, n)
+	for i := range types {
+		types[i] = t
+	}
+	return types
+}
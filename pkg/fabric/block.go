@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// BlockHeaderHash returns the hash of a block header, which the next block
+// refers to as its previous hash.
+func BlockHeaderHash(h *cb.BlockHeader) []byte {
+	der, err := asn1.Marshal(struct {
+		Number       *big.Int
+		PreviousHash []byte
+		DataHash     []byte
+	}{new(big.Int).SetUint64(h.GetNumber()), h.GetPreviousHash(), h.GetDataHash()})
+	if err != nil {
+		// Cannot happen: the structure only holds encodable types.
+		panic(err)
+	}
+	sum := sha256.Sum256(der)
+	return sum[:]
+}
+
+// validationCode returns the validation code of the i-th transaction of a
+// block, read from its transactions filter metadata.
+func validationCode(blk *cb.Block, i int) pb.TxValidationCode {
+	metadata := blk.GetMetadata().GetMetadata()
+	if len(metadata) <= int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return pb.TxValidationCode_NOT_VALIDATED
+	}
+	filter := metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	if i >= len(filter) {
+		return pb.TxValidationCode_NOT_VALIDATED
+	}
+	return pb.TxValidationCode(filter[i])
+}
+
+// BlockTransactions decodes the transactions of a block. Chaincode events
+// are only decoded from endorser transactions. Peers commit malformed
+// transactions as invalid, so only the index and validation code of those
+// are returned.
+func BlockTransactions(blk *cb.Block) ([]Transaction, error) {
+	data := blk.GetData().GetData()
+	txs := make([]Transaction, 0, len(data))
+	for i, env := range data {
+		code := validationCode(blk, i)
+		tx, err := parseEnvelope(env)
+		switch {
+		case err != nil && code == pb.TxValidationCode_VALID:
+			return nil, fmt.Errorf("transaction %d of block %d: %w", i, blk.GetHeader().GetNumber(), err)
+		case err != nil:
+			tx = &Transaction{}
+		}
+		tx.Index = i
+		tx.ValidationCode = code
+		txs = append(txs, *tx)
+	}
+	return txs, nil
+}
+
+// NewBlock assembles a block from transactions, as the ordering service
+// and committing peers would. Transaction signatures are left empty.
+func NewBlock(number uint64, previousHash []byte, txs []Transaction) *cb.Block {
+	blk := &cb.Block{
+		Header: &cb.BlockHeader{
+			Number:       number,
+			PreviousHash: previousHash,
+		},
+		Data: &cb.BlockData{},
+		Metadata: &cb.BlockMetadata{
+			Metadata: make([][]byte, len(cb.BlockMetadataIndex_name)),
+		},
+	}
+
+	filter := make([]byte, len(txs))
+	var joined []byte
+	for i := range txs {
+		env := txs[i].envelope()
+		blk.Data.Data = append(blk.Data.Data, env)
+		joined = append(joined, env...)
+		filter[i] = byte(txs[i].ValidationCode)
+	}
+	sum := sha256.Sum256(joined)
+	blk.Header.DataHash = sum[:]
+	blk.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = filter
+	return blk
+}
+
+// FilterBlock returns a block as the DeliverFiltered service streams it:
+// the identifiers and validation codes of transactions, and their
+// chaincode events without payloads.
+func FilterBlock(blk *cb.Block, channelID string) (*pb.FilteredBlock, error) {
+	txs, err := BlockTransactions(blk)
+	if err != nil {
+		return nil, err
+	}
+
+	fb := &pb.FilteredBlock{
+		ChannelId: channelID,
+		Number:    blk.GetHeader().GetNumber(),
+	}
+	for _, tx := range txs {
+		ftx := &pb.FilteredTransaction{
+			Txid:             tx.TxID,
+			Type:             tx.Type,
+			TxValidationCode: tx.ValidationCode,
+		}
+		if len(tx.ChaincodeEvents) > 0 {
+			actions := &pb.FilteredTransactionActions{}
+			for _, ev := range tx.ChaincodeEvents {
+				actions.ChaincodeActions = append(actions.ChaincodeActions, &pb.FilteredChaincodeAction{
+					ChaincodeEvent: &pb.ChaincodeEvent{
+						ChaincodeId: ev.ChaincodeId,
+						TxId:        ev.TxId,
+						EventName:   ev.EventName,
+					},
+				})
+			}
+			ftx.Data = &pb.FilteredTransaction_TransactionActions{TransactionActions: actions}
+		}
+		fb.FilteredTransactions = append(fb.FilteredTransactions, ftx)
+	}
+	return fb, nil
+}
+
+// FilteredTransactions returns the transactions of a filtered block.
+func FilteredTransactions(fb *pb.FilteredBlock) []Transaction {
+	txs := make([]Transaction, 0, len(fb.GetFilteredTransactions()))
+	for i, ftx := range fb.GetFilteredTransactions() {
+		tx := Transaction{
+			Index:          i,
+			Type:           ftx.Type,
+			TxID:           ftx.Txid,
+			ChannelID:      fb.ChannelId,
+			ValidationCode: ftx.TxValidationCode,
+		}
+		for _, action := range ftx.GetTransactionActions().GetChaincodeActions() {
+			if ev := action.GetChaincodeEvent(); ev != nil {
+				tx.ChaincodeEvents = append(tx.ChaincodeEvents, ev)
+			}
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
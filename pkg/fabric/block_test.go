@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func testTransactions() []Transaction {
+	return []Transaction{{
+		Type:           cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:           "tx1",
+		ChannelID:      "mychannel",
+		Timestamp:      time.Unix(1700000000, 0).UTC(),
+		CreatorMSPID:   "Org1MSP",
+		ValidationCode: pb.TxValidationCode_VALID,
+		ChaincodeEvents: []*pb.ChaincodeEvent{{
+			ChaincodeId: "basic",
+			TxId:        "tx1",
+			EventName:   "CreateAsset",
+			Payload:     []byte(`{"ID":"asset1"}`),
+		}, {
+			ChaincodeId: "token",
+			TxId:        "tx1",
+			EventName:   "Transfer",
+			Payload:     []byte(`{"amount":1}`),
+		}},
+	}, {
+		Type:           cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:           "tx2",
+		ChannelID:      "mychannel",
+		Timestamp:      time.Unix(1700000001, 0).UTC(),
+		CreatorMSPID:   "Org2MSP",
+		ValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT,
+	}, {
+		Type:           cb.HeaderType_CONFIG,
+		ChannelID:      "mychannel",
+		Timestamp:      time.Unix(1700000002, 0).UTC(),
+		CreatorMSPID:   "OrdererMSP",
+		ValidationCode: pb.TxValidationCode_VALID,
+	}}
+}
+
+func TestBlockTransactions(t *testing.T) {
+	txs := testTransactions()
+	blk := NewBlock(7, []byte("parent"), txs)
+
+	b, err := proto.Marshal(blk)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	got := &cb.Block{}
+	if err := proto.Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if got.Header.Number != 7 || !bytes.Equal(got.Header.PreviousHash, []byte("parent")) {
+		t.Errorf("Header = %v, want number 7 chained to parent", got.Header)
+	}
+
+	gotTxs, err := BlockTransactions(got)
+	if err != nil {
+		t.Fatalf("BlockTransactions() = %v", err)
+	}
+	for i := range txs {
+		txs[i].Index = i
+	}
+	if diff := cmp.Diff(txs, gotTxs, cmpopts.EquateEmpty(), protocmp.Transform()); diff != "" {
+		t.Errorf("BlockTransactions() (-want, +got) = %s", diff)
+	}
+}
+
+func TestBlockHeaderHash(t *testing.T) {
+	h := &cb.BlockHeader{Number: 1, PreviousHash: []byte{1}, DataHash: []byte{2}}
+	// The header hash is the SHA-256 digest of the DER encoded sequence of
+	// the number, the previous hash and the data hash.
+	der := []byte{
+		0x30, 0x09, // sequence
+		0x02, 0x01, 0x01, // number
+		0x04, 0x01, 0x01, // previous_hash
+		0x04, 0x01, 0x02, // data_hash
+	}
+	want := sha256.Sum256(der)
+	if got := BlockHeaderHash(h); !bytes.Equal(got, want[:]) {
+		t.Errorf("BlockHeaderHash() = %x, want %x", got, want)
+	}
+
+	// Numbers above 2^63 stay positive.
+	h.Number = ^uint64(0)
+	der, err := asn1.Marshal(struct {
+		Number       *big.Int
+		PreviousHash []byte
+		DataHash     []byte
+	}{new(big.Int).SetUint64(h.Number), h.PreviousHash, h.DataHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = sha256.Sum256(der)
+	if got := BlockHeaderHash(h); !bytes.Equal(got, want[:]) {
+		t.Errorf("BlockHeaderHash() = %x, want %x", got, want)
+	}
+}
+
+func TestBlockTransactionsMalformed(t *testing.T) {
+	blk := NewBlock(1, nil, testTransactions()[:1])
+	blk.Data.Data[0] = []byte{0xff}
+
+	// Malformed transactions are committed as invalid.
+	blk.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER][0] = byte(pb.TxValidationCode_BAD_PAYLOAD)
+	txs, err := BlockTransactions(blk)
+	if err != nil {
+		t.Fatalf("BlockTransactions() = %v", err)
+	}
+	want := []Transaction{{ValidationCode: pb.TxValidationCode_BAD_PAYLOAD}}
+	if diff := cmp.Diff(want, txs); diff != "" {
+		t.Errorf("BlockTransactions() (-want, +got) = %s", diff)
+	}
+
+	blk.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER][0] = byte(pb.TxValidationCode_VALID)
+	if _, err := BlockTransactions(blk); err == nil {
+		t.Error("BlockTransactions() = nil, want an error for a valid malformed transaction")
+	}
+}
+
+func TestBlockTransactionsNotValidated(t *testing.T) {
+	blk := NewBlock(1, nil, testTransactions()[:1])
+	blk.Metadata = nil
+	txs, err := BlockTransactions(blk)
+	if err != nil {
+		t.Fatalf("BlockTransactions() = %v", err)
+	}
+	if got := txs[0].ValidationCode; got != pb.TxValidationCode_NOT_VALIDATED {
+		t.Errorf("ValidationCode = %s, want NOT_VALIDATED", got)
+	}
+}
+
+func TestFilterBlock(t *testing.T) {
+	blk := NewBlock(7, nil, testTransactions())
+	fb, err := FilterBlock(blk, "mychannel")
+	if err != nil {
+		t.Fatalf("FilterBlock() = %v", err)
+	}
+
+	b, err := proto.Marshal(fb)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	got := &pb.FilteredBlock{}
+	if err := proto.Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := []Transaction{{
+		Index:          0,
+		Type:           cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:           "tx1",
+		ChannelID:      "mychannel",
+		ValidationCode: pb.TxValidationCode_VALID,
+		ChaincodeEvents: []*pb.ChaincodeEvent{
+			{ChaincodeId: "basic", TxId: "tx1", EventName: "CreateAsset"},
+			{ChaincodeId: "token", TxId: "tx1", EventName: "Transfer"},
+		},
+	}, {
+		Index:          1,
+		Type:           cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:           "tx2",
+		ChannelID:      "mychannel",
+		ValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT,
+	}, {
+		Index:          2,
+		Type:           cb.HeaderType_CONFIG,
+		ChannelID:      "mychannel",
+		ValidationCode: pb.TxValidationCode_VALID,
+	}}
+	if got.Number != 7 {
+		t.Errorf("Number = %d, want 7", got.Number)
+	}
+	if diff := cmp.Diff(want, FilteredTransactions(got), cmpopts.EquateEmpty(), protocmp.Transform()); diff != "" {
+		t.Errorf("FilteredTransactions() (-want, +got) = %s", diff)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fabric contains a minimal client of the Deliver service of
+// Hyperledger Fabric peers, and decodes the transactions and chaincode
+// events of the blocks it streams.
+package fabric
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	ab "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// deliverNonceSize is the size of the nonces of seek requests.
+const deliverNonceSize = 24
+
+// maxDeliverResponseSize bounds the size of the blocks received.
+const maxDeliverResponseSize = 100 << 20
+
+// StatusError is returned when a peer ends a Deliver stream with another
+// status than SUCCESS.
+type StatusError struct {
+	Status cb.Status
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("deliver failed with status %s", e.Status)
+}
+
+// Dial connects to the gRPC endpoint of a peer, given as a grpc:// URL or
+// as a grpcs:// URL for TLS. The TLS certificate of the peer is verified
+// against the given PEM encoded root certificates, or against the system
+// ones when nil. The connection is established lazily.
+func Dial(ctx context.Context, rawURL string, tlsRootCertPEM []byte) (*grpc.ClientConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in %q", rawURL)
+	}
+
+	var creds credentials.TransportCredentials
+	switch u.Scheme {
+	case "grpc":
+		creds = insecure.NewCredentials()
+	case "grpcs":
+		cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+		if len(tlsRootCertPEM) > 0 {
+			cfg.RootCAs = x509.NewCertPool()
+			if !cfg.RootCAs.AppendCertsFromPEM(tlsRootCertPEM) {
+				return nil, errors.New("no certificate found in the TLS root certificates")
+			}
+		}
+		creds = credentials.NewTLS(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, expected grpc or grpcs", u.Scheme)
+	}
+
+	return grpc.DialContext(ctx, u.Host,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxDeliverResponseSize)),
+	)
+}
+
+// SeekNewest returns the position of the newest block of a channel.
+func SeekNewest() *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}
+}
+
+// SeekOldest returns the position of the genesis block of a channel.
+func SeekOldest() *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}}
+}
+
+// SeekSpecified returns the position of the given block.
+func SeekSpecified(number uint64) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: number}}}
+}
+
+// NewSeekEnvelope returns the signed request of the blocks of a channel
+// sent over Deliver streams.
+func NewSeekEnvelope(signer *Signer, channelID string, seek *ab.SeekInfo) (*cb.Envelope, error) {
+	nonce := make([]byte, deliverNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	creator := signer.Creator()
+	txID := sha256.Sum256(append(append([]byte(nil), nonce...), creator...))
+
+	ch, err := proto.Marshal(&cb.ChannelHeader{
+		Type:      int32(cb.HeaderType_DELIVER_SEEK_INFO),
+		Timestamp: timestamppb.Now(),
+		ChannelId: channelID,
+		TxId:      hex.EncodeToString(txID[:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sh, err := proto.Marshal(&cb.SignatureHeader{
+		Creator: creator,
+		Nonce:   nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(seek)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(&cb.Payload{
+		Header: &cb.Header{
+			ChannelHeader:   ch,
+			SignatureHeader: sh,
+		},
+		Data: data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signer.Sign(payload)
+	if err != nil {
+		return nil, fmt.Errorf("signing seek request: %w", err)
+	}
+	return &cb.Envelope{Payload: payload, Signature: sig}, nil
+}
+
+// deliverClient is the client side of the Deliver and DeliverFiltered
+// streams.
+type deliverClient interface {
+	Send(*cb.Envelope) error
+	Recv() (*pb.DeliverResponse, error)
+	CloseSend() error
+}
+
+// Stream is a Deliver stream returning blocks of a channel.
+type Stream struct {
+	stream deliverClient
+}
+
+// Deliver opens a Deliver stream requesting the blocks of a channel
+// described by seek, as full blocks or as filtered blocks.
+func Deliver(ctx context.Context, conn grpc.ClientConnInterface, signer *Signer, channelID string, seek *ab.SeekInfo, filtered bool) (*Stream, error) {
+	env, err := NewSeekEnvelope(signer, channelID, seek)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewDeliverClient(conn)
+	var s deliverClient
+	if filtered {
+		s, err = client.DeliverFiltered(ctx)
+	} else {
+		s, err = client.Deliver(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Send(env); err != nil {
+		return nil, err
+	}
+	if err := s.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &Stream{stream: s}, nil
+}
+
+// Recv returns the next response carrying a block or a filtered block. It
+// returns io.EOF once the requested blocks are all delivered, and a
+// *StatusError when the peer refuses the request.
+func (s *Stream) Recv() (*pb.DeliverResponse, error) {
+	for {
+		resp, err := s.stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		switch t := resp.Type.(type) {
+		case *pb.DeliverResponse_Block, *pb.DeliverResponse_FilteredBlock:
+			return resp, nil
+		case *pb.DeliverResponse_Status:
+			if t.Status == cb.Status_SUCCESS {
+				return nil, io.EOF
+			}
+			return nil, &StatusError{Status: t.Status}
+		}
+	}
+}
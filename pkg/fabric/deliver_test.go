@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	ab "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"knative.dev/eventing-blockchain/pkg/fabric"
+	"knative.dev/eventing-blockchain/pkg/fabric/fabrictest"
+)
+
+const (
+	testChannel = "mychannel"
+	testMSPID   = "Org1MSP"
+)
+
+func newTestSigner(t *testing.T, mspID string) *fabric.Signer {
+	t.Helper()
+	cert, key := fabrictest.NewIdentity(t, "user1")
+	s, err := fabric.NewSigner(mspID, cert, key)
+	if err != nil {
+		t.Fatalf("NewSigner() = %v", err)
+	}
+	return s
+}
+
+func deliver(t *testing.T, peer *fabrictest.Peer, signer *fabric.Signer, channel string, seek *ab.SeekInfo, filtered bool) *fabric.Stream {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	conn, err := fabric.Dial(ctx, peer.Endpoint(), nil)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	s, err := fabric.Deliver(ctx, conn, signer, channel, seek, filtered)
+	if err != nil {
+		t.Fatalf("Deliver() = %v", err)
+	}
+	return s
+}
+
+func TestDeliver(t *testing.T) {
+	peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+	tx := fabric.Transaction{
+		Type:           cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:           "tx1",
+		ChannelID:      testChannel,
+		CreatorMSPID:   testMSPID,
+		ValidationCode: pb.TxValidationCode_VALID,
+		ChaincodeEvents: []*pb.ChaincodeEvent{{
+			ChaincodeId: "basic",
+			TxId:        "tx1",
+			EventName:   "CreateAsset",
+			Payload:     []byte("asset1"),
+		}},
+	}
+	peer.AddBlock(tx)
+	peer.AddBlock()
+
+	seek := &ab.SeekInfo{Start: fabric.SeekSpecified(1), Stop: fabric.SeekSpecified(2)}
+	s := deliver(t, peer, newTestSigner(t, testMSPID), testChannel, seek, false)
+
+	var numbers []uint64
+	for {
+		resp, err := s.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() = %v", err)
+		}
+		blk := resp.GetBlock()
+		numbers = append(numbers, blk.GetHeader().GetNumber())
+		if blk.GetHeader().GetNumber() == 1 {
+			txs, err := fabric.BlockTransactions(blk)
+			if err != nil {
+				t.Fatalf("BlockTransactions() = %v", err)
+			}
+			if got := txs[0].ChaincodeEvents; len(got) != 1 || string(got[0].Payload) != "asset1" {
+				t.Errorf("ChaincodeEvents = %+v, want the event of tx1 with its payload", got)
+			}
+		}
+	}
+	if diff := cmp.Diff([]uint64{1, 2}, numbers); diff != "" {
+		t.Errorf("Blocks (-want, +got) = %s", diff)
+	}
+
+	want := []fabrictest.Request{{Seek: seek, MSPID: testMSPID}}
+	if diff := cmp.Diff(want, peer.Requests(), protocmp.Transform()); diff != "" {
+		t.Errorf("Requests (-want, +got) = %s", diff)
+	}
+}
+
+func TestDeliverFiltered(t *testing.T) {
+	peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+	seek := &ab.SeekInfo{Start: fabric.SeekNewest(), Stop: fabric.SeekSpecified(^uint64(0))}
+	s := deliver(t, peer, newTestSigner(t, testMSPID), testChannel, seek, true)
+
+	// The newest block is delivered first, then blocks as they are added.
+	resp, err := s.Recv()
+	if err != nil {
+		t.Fatalf("Recv() = %v", err)
+	}
+	if fb := resp.GetFilteredBlock(); fb == nil || fb.Number != 0 {
+		t.Fatalf("Recv() = %v, want filtered block 0", resp)
+	}
+
+	peer.AddBlock(fabric.Transaction{
+		Type:           cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:           "tx1",
+		ChannelID:      testChannel,
+		ValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT,
+	})
+	resp, err = s.Recv()
+	if err != nil {
+		t.Fatalf("Recv() = %v", err)
+	}
+	want := []fabric.Transaction{{
+		Type:           cb.HeaderType_ENDORSER_TRANSACTION,
+		TxID:           "tx1",
+		ChannelID:      testChannel,
+		ValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT,
+	}}
+	if diff := cmp.Diff(want, fabric.FilteredTransactions(resp.GetFilteredBlock()), protocmp.Transform()); diff != "" {
+		t.Errorf("FilteredTransactions() (-want, +got) = %s", diff)
+	}
+}
+
+func TestDeliverRefused(t *testing.T) {
+	peer := fabrictest.NewPeer(t, testChannel, testMSPID)
+	seek := &ab.SeekInfo{Start: fabric.SeekOldest(), Stop: fabric.SeekNewest()}
+
+	testCases := map[string]struct {
+		mspID      string
+		channel    string
+		wantStatus cb.Status
+	}{
+		"unknown channel": {
+			mspID:      testMSPID,
+			channel:    "other",
+			wantStatus: cb.Status_NOT_FOUND,
+		},
+		"unauthorized MSP": {
+			mspID:      "Org2MSP",
+			channel:    testChannel,
+			wantStatus: cb.Status_FORBIDDEN,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			s := deliver(t, peer, newTestSigner(t, tc.mspID), tc.channel, seek, false)
+			_, err := s.Recv()
+			var se *fabric.StatusError
+			if !errors.As(err, &se) || se.Status != tc.wantStatus {
+				t.Errorf("Recv() = %v, want status %s", err, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDial(t *testing.T) {
+	testCases := map[string]struct {
+		url      string
+		rootCert []byte
+		wantErr  bool
+	}{
+		"plaintext":          {url: "grpc://peer0:7051"},
+		"tls":                {url: "grpcs://peer0:7051"},
+		"tls root cert":      {url: "grpcs://peer0:7051", rootCert: []byte("not a certificate"), wantErr: true},
+		"unsupported scheme": {url: "https://peer0:7051", wantErr: true},
+		"missing host":       {url: "grpc://", wantErr: true},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			conn, err := fabric.Dial(context.Background(), tc.url, tc.rootCert)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Dial() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if conn != nil {
+				conn.Close()
+			}
+		})
+	}
+}
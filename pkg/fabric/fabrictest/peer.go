@@ -0,0 +1,285 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fabrictest provides a Fabric peer for tests, serving the blocks
+// of a channel over the Deliver service.
+package fabrictest
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	ab "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"knative.dev/eventing-blockchain/pkg/fabric"
+)
+
+// Request is a seek request received by the peer.
+type Request struct {
+	Seek     *ab.SeekInfo
+	Filtered bool
+	MSPID    string
+}
+
+// Peer is a Fabric peer listening on the loopback interface. Its ledger
+// starts with a genesis block holding a config transaction.
+type Peer struct {
+	t         *testing.T
+	channelID string
+	mspID     string
+	listener  net.Listener
+	server    *grpc.Server
+
+	mu       sync.Mutex
+	blocks   []*cb.Block
+	added    chan struct{}
+	closed   chan struct{}
+	requests []Request
+}
+
+// NewPeer starts a peer serving a channel to the clients of an MSP,
+// stopped at the end of the test.
+func NewPeer(t *testing.T, channelID, mspID string) *Peer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+
+	p := &Peer{
+		t:         t,
+		channelID: channelID,
+		mspID:     mspID,
+		listener:  l,
+		server:    grpc.NewServer(),
+		added:     make(chan struct{}),
+		closed:    make(chan struct{}),
+	}
+	p.blocks = []*cb.Block{fabric.NewBlock(0, nil, []fabric.Transaction{{
+		Type:           cb.HeaderType_CONFIG,
+		ChannelID:      channelID,
+		Timestamp:      time.Unix(1700000000, 0).UTC(),
+		ValidationCode: pb.TxValidationCode_VALID,
+	}})}
+
+	pb.RegisterDeliverServer(p.server, deliverServer{p: p})
+
+	go func() {
+		_ = p.server.Serve(l)
+	}()
+	t.Cleanup(p.server.Stop)
+	return p
+}
+
+// Endpoint returns the grpc://host:port endpoint of the peer.
+func (p *Peer) Endpoint() string {
+	return "grpc://" + p.listener.Addr().String()
+}
+
+// AddBlock commits a block made of the given transactions.
+func (p *Peer) AddBlock(txs ...fabric.Transaction) *cb.Block {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	parent := p.blocks[len(p.blocks)-1]
+	blk := fabric.NewBlock(uint64(len(p.blocks)), fabric.BlockHeaderHash(parent.Header), txs)
+	p.blocks = append(p.blocks, blk)
+	close(p.added)
+	p.added = make(chan struct{})
+	return blk
+}
+
+// Block returns a committed block.
+func (p *Peer) Block(number uint64) *cb.Block {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.blocks[number]
+}
+
+// Requests returns the seek requests received so far.
+func (p *Peer) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
+
+// Disconnect aborts the current Deliver streams.
+func (p *Peer) Disconnect() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	close(p.closed)
+	p.closed = make(chan struct{})
+}
+
+// block returns the given block once committed, or nil when the stream
+// ends first.
+func (p *Peer) block(stream grpc.ServerStream, closed chan struct{}, number uint64) *cb.Block {
+	for {
+		p.mu.Lock()
+		if number < uint64(len(p.blocks)) {
+			blk := p.blocks[number]
+			p.mu.Unlock()
+			return blk
+		}
+		added := p.added
+		p.mu.Unlock()
+
+		select {
+		case <-added:
+		case <-closed:
+			return nil
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// deliverServer serves the Deliver service of a peer.
+type deliverServer struct {
+	pb.UnimplementedDeliverServer
+	p *Peer
+}
+
+// Deliver implements pb.DeliverServer.
+func (s deliverServer) Deliver(stream pb.Deliver_DeliverServer) error {
+	return s.p.deliver(stream, false)
+}
+
+// DeliverFiltered implements pb.DeliverServer.
+func (s deliverServer) DeliverFiltered(stream pb.Deliver_DeliverFilteredServer) error {
+	return s.p.deliver(stream, true)
+}
+
+// deliverStream is the server side of the Deliver and DeliverFiltered
+// streams.
+type deliverStream interface {
+	grpc.ServerStream
+	Send(*pb.DeliverResponse) error
+	Recv() (*cb.Envelope, error)
+}
+
+// statusResponse returns the response ending a stream with a status.
+func statusResponse(st cb.Status) *pb.DeliverResponse {
+	return &pb.DeliverResponse{Type: &pb.DeliverResponse_Status{Status: st}}
+}
+
+func (p *Peer) deliver(stream deliverStream, filtered bool) error {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+
+	env, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	req, st := p.authorize(env)
+	if st != cb.Status_SUCCESS {
+		return stream.Send(statusResponse(st))
+	}
+	req.Filtered = filtered
+	p.mu.Lock()
+	p.requests = append(p.requests, *req)
+	newest := uint64(len(p.blocks) - 1)
+	p.mu.Unlock()
+
+	start := position(req.Seek.Start, newest)
+	stop := position(req.Seek.Stop, newest)
+	if start > stop {
+		return stream.Send(statusResponse(cb.Status_BAD_REQUEST))
+	}
+
+	for n := start; n <= stop; n++ {
+		if req.Seek.Behavior == ab.SeekInfo_FAIL_IF_NOT_READY && n > p.newest() {
+			return stream.Send(statusResponse(cb.Status_NOT_FOUND))
+		}
+		blk := p.block(stream, closed, n)
+		if blk == nil {
+			return status.Error(codes.Unavailable, "peer disconnected")
+		}
+
+		resp := &pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: blk}}
+		if filtered {
+			fb, err := fabric.FilterBlock(blk, p.channelID)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			resp = &pb.DeliverResponse{Type: &pb.DeliverResponse_FilteredBlock{FilteredBlock: fb}}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		if n == ^uint64(0) {
+			break
+		}
+	}
+	return stream.Send(statusResponse(cb.Status_SUCCESS))
+}
+
+func (p *Peer) newest() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return uint64(len(p.blocks) - 1)
+}
+
+// authorize checks a seek request the way peers do: it must be signed by
+// a client of the MSP of the peer and target its channel.
+func (p *Peer) authorize(env *cb.Envelope) (*Request, cb.Status) {
+	payload := &cb.Payload{}
+	if err := proto.Unmarshal(env.Payload, payload); err != nil || payload.Header == nil {
+		return nil, cb.Status_BAD_REQUEST
+	}
+	ch := &cb.ChannelHeader{}
+	sh := &cb.SignatureHeader{}
+	seek := &ab.SeekInfo{}
+	if proto.Unmarshal(payload.Header.ChannelHeader, ch) != nil ||
+		proto.Unmarshal(payload.Header.SignatureHeader, sh) != nil ||
+		proto.Unmarshal(payload.Data, seek) != nil ||
+		ch.Type != int32(cb.HeaderType_DELIVER_SEEK_INFO) {
+		return nil, cb.Status_BAD_REQUEST
+	}
+	if ch.ChannelId != p.channelID {
+		return nil, cb.Status_NOT_FOUND
+	}
+
+	creator := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(sh.Creator, creator); err != nil {
+		return nil, cb.Status_BAD_REQUEST
+	}
+	if creator.Mspid != p.mspID || fabric.Verify(sh.Creator, env.Payload, env.Signature) != nil {
+		return nil, cb.Status_FORBIDDEN
+	}
+	return &Request{Seek: seek, MSPID: creator.Mspid}, cb.Status_SUCCESS
+}
+
+// position returns the block number of a seek position.
+func position(pos *ab.SeekPosition, newest uint64) uint64 {
+	switch t := pos.GetType().(type) {
+	case *ab.SeekPosition_Specified:
+		return t.Specified.GetNumber()
+	case *ab.SeekPosition_Oldest:
+		return 0
+	default:
+		return newest
+	}
+}
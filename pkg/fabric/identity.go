@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Signer signs the requests of a client identity enrolled with a
+// membership service provider (MSP) of the network.
+type Signer struct {
+	mspID   string
+	creator []byte
+	key     crypto.Signer
+}
+
+// NewSigner returns a signer for the identity of the given MSP, holding
+// the PEM encoded certificate and private key. Keys are ECDSA, as issued
+// by Fabric CAs, or Ed25519.
+func NewSigner(mspID string, certPEM, keyPEM []byte) (*Signer, error) {
+	if mspID == "" {
+		return nil, errors.New("missing MSP ID")
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(cert.PublicKey) {
+		return nil, errors.New("private key does not match the certificate")
+	}
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(block),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid MSP ID: %w", err)
+	}
+
+	return &Signer{
+		mspID:   mspID,
+		creator: creator,
+		key:     key,
+	}, nil
+}
+
+// parsePrivateKey decodes a PEM encoded PKCS #8 or SEC 1 private key.
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		switch key := key.(type) {
+		case *ecdsa.PrivateKey:
+			return key, nil
+		case ed25519.PrivateKey:
+			return key, nil
+		default:
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return key, nil
+}
+
+// MSPID returns the ID of the MSP of the identity.
+func (s *Signer) MSPID() string {
+	return s.mspID
+}
+
+// Creator returns the encoded identity, as set in signature headers.
+func (s *Signer) Creator() []byte {
+	return s.creator
+}
+
+// Sign signs a message. ECDSA signatures are computed over the SHA-256
+// digest of the message and normalized to a low S, which peers require.
+func (s *Signer) Sign(msg []byte) ([]byte, error) {
+	key, ok := s.key.(*ecdsa.PrivateKey)
+	if !ok {
+		return s.key.Sign(rand.Reader, msg, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(msg)
+	r, sig, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ecdsaSignature{R: r, S: toLowS(key.Curve, sig)})
+}
+
+// Verify checks the signature of a message against the creator of a
+// signature header, the way peers authenticate clients.
+func Verify(creator, msg, sig []byte) error {
+	id := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creator, id); err != nil {
+		return unmarshalError("creator", err)
+	}
+	block, _ := pem.Decode(id.IdBytes)
+	if block == nil {
+		return errors.New("creator certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid creator certificate: %w", err)
+	}
+
+	switch pub := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		var es ecdsaSignature
+		if _, err := asn1.Unmarshal(sig, &es); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+		if es.S.Cmp(halfOrder(pub.Curve)) > 0 {
+			return errors.New("signature S is not low")
+		}
+		digest := sha256.Sum256(msg)
+		if !ecdsa.Verify(pub, digest[:], es.R, es.S) {
+			return errors.New("signature verification failed")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, msg, sig) {
+			return errors.New("signature verification failed")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return nil
+}
+
+// ecdsaSignature is the ASN.1 encoding of ECDSA signatures.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// halfOrder returns half of the order of a curve, the highest low S.
+func halfOrder(curve elliptic.Curve) *big.Int {
+	return new(big.Int).Rsh(curve.Params().N, 1)
+}
+
+// toLowS returns the low S of the two equivalent values of an ECDSA
+// signature.
+func toLowS(curve elliptic.Curve, s *big.Int) *big.Int {
+	if s.Cmp(halfOrder(curve)) > 0 {
+		return new(big.Int).Sub(curve.Params().N, s)
+	}
+	return s
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+
+	"knative.dev/eventing-blockchain/pkg/fabric"
+	"knative.dev/eventing-blockchain/pkg/fabric/fabrictest"
+)
+
+func TestSigner(t *testing.T) {
+	cert, key := fabrictest.NewIdentity(t, "user1")
+	s, err := fabric.NewSigner("Org1MSP", cert, key)
+	if err != nil {
+		t.Fatalf("NewSigner() = %v", err)
+	}
+
+	id := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(s.Creator(), id); err != nil {
+		t.Fatalf("Unmarshal(Creator()) = %v", err)
+	}
+	if id.Mspid != "Org1MSP" || string(id.IdBytes) != string(cert) {
+		t.Errorf("Creator() = %v, want the certificate of Org1MSP", id)
+	}
+
+	// Signatures are randomized, so that some have a high S unless
+	// normalized.
+	for i := 0; i < 32; i++ {
+		sig, err := s.Sign([]byte("msg"))
+		if err != nil {
+			t.Fatalf("Sign() = %v", err)
+		}
+		if err := fabric.Verify(s.Creator(), []byte("msg"), sig); err != nil {
+			t.Fatalf("Verify() = %v", err)
+		}
+	}
+
+	sig, _ := s.Sign([]byte("msg"))
+	if err := fabric.Verify(s.Creator(), []byte("other"), sig); err == nil {
+		t.Error("Verify() = nil for another message, want an error")
+	}
+}
+
+func TestVerifyHighS(t *testing.T) {
+	cert, keyPEM := fabrictest.NewIdentity(t, "user1")
+	s, err := fabric.NewSigner("Org1MSP", cert, keyPEM)
+	if err != nil {
+		t.Fatalf("NewSigner() = %v", err)
+	}
+	sig, err := s.Sign([]byte("msg"))
+	if err != nil {
+		t.Fatalf("Sign() = %v", err)
+	}
+
+	var es struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &es); err != nil {
+		t.Fatal(err)
+	}
+	es.S.Sub(elliptic.P256().Params().N, es.S)
+	high, err := asn1.Marshal(es)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fabric.Verify(s.Creator(), []byte("msg"), high); err == nil {
+		t.Error("Verify() = nil for a high S, want an error")
+	}
+}
+
+func TestNewSignerKeys(t *testing.T) {
+	cert, key := fabrictest.NewIdentity(t, "user1")
+	_, otherKey := fabrictest.NewIdentity(t, "user2")
+
+	block, _ := pem.Decode(key)
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(parsed.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1Key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})
+
+	edCert, edKey := ed25519Identity(t)
+
+	testCases := map[string]struct {
+		mspID   string
+		cert    []byte
+		key     []byte
+		wantErr bool
+	}{
+		"pkcs8":               {mspID: "Org1MSP", cert: cert, key: key},
+		"sec1":                {mspID: "Org1MSP", cert: cert, key: sec1Key},
+		"ed25519":             {mspID: "Org1MSP", cert: edCert, key: edKey},
+		"key of another cert": {mspID: "Org1MSP", cert: cert, key: otherKey, wantErr: true},
+		"missing MSP ID":      {cert: cert, key: key, wantErr: true},
+		"certificate not PEM": {mspID: "Org1MSP", cert: []byte("cert"), key: key, wantErr: true},
+		"private key not PEM": {mspID: "Org1MSP", cert: cert, key: []byte("key"), wantErr: true},
+		"certificate as key":  {mspID: "Org1MSP", cert: cert, key: cert, wantErr: true},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			s, err := fabric.NewSigner(tc.mspID, tc.cert, tc.key)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("NewSigner() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			sig, err := s.Sign([]byte("msg"))
+			if err != nil {
+				t.Fatalf("Sign() = %v", err)
+			}
+			if err := fabric.Verify(s.Creator(), []byte("msg"), sig); err != nil {
+				t.Errorf("Verify() = %v", err)
+			}
+		})
+	}
+}
+
+func ed25519Identity(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, pub, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+}
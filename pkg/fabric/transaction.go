@@ -0,0 +1,180 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Transaction is a transaction of a block, decoded from either a full or
+// a filtered block. Filtered blocks leave Timestamp, CreatorMSPID and the
+// payloads of chaincode events empty.
+type Transaction struct {
+	// Index is the position of the transaction in its block.
+	Index           int
+	Type            cb.HeaderType
+	TxID            string
+	ChannelID       string
+	Timestamp       time.Time
+	CreatorMSPID    string
+	ValidationCode  pb.TxValidationCode
+	ChaincodeEvents []*pb.ChaincodeEvent
+}
+
+// parseEnvelope decodes the headers of an encoded envelope and, for
+// endorser transactions, the chaincode events it sets.
+func parseEnvelope(b []byte) (*Transaction, error) {
+	env := &cb.Envelope{}
+	if err := proto.Unmarshal(b, env); err != nil {
+		return nil, unmarshalError("envelope", err)
+	}
+	payload := &cb.Payload{}
+	if err := proto.Unmarshal(env.Payload, payload); err != nil {
+		return nil, unmarshalError("payload", err)
+	}
+	if payload.Header == nil {
+		return nil, errors.New("payload without header")
+	}
+
+	ch := &cb.ChannelHeader{}
+	if err := proto.Unmarshal(payload.Header.ChannelHeader, ch); err != nil {
+		return nil, unmarshalError("channel header", err)
+	}
+	sh := &cb.SignatureHeader{}
+	if err := proto.Unmarshal(payload.Header.SignatureHeader, sh); err != nil {
+		return nil, unmarshalError("signature header", err)
+	}
+	creator := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(sh.Creator, creator); err != nil {
+		return nil, unmarshalError("creator", err)
+	}
+
+	tx := &Transaction{
+		Type:         cb.HeaderType(ch.Type),
+		TxID:         ch.TxId,
+		ChannelID:    ch.ChannelId,
+		CreatorMSPID: creator.Mspid,
+	}
+	if ch.Timestamp != nil {
+		tx.Timestamp = ch.Timestamp.AsTime()
+	}
+	if tx.Type == cb.HeaderType_ENDORSER_TRANSACTION {
+		events, err := parseChaincodeEvents(payload.Data)
+		if err != nil {
+			return nil, err
+		}
+		tx.ChaincodeEvents = events
+	}
+	return tx, nil
+}
+
+// parseChaincodeEvents decodes the chaincode events of the actions of an
+// endorser transaction, following peer.Transaction down to
+// peer.ChaincodeAction.
+func parseChaincodeEvents(b []byte) ([]*pb.ChaincodeEvent, error) {
+	ptx := &pb.Transaction{}
+	if err := proto.Unmarshal(b, ptx); err != nil {
+		return nil, unmarshalError("endorser transaction", err)
+	}
+
+	var events []*pb.ChaincodeEvent
+	for i, action := range ptx.Actions {
+		actionPayload := &pb.ChaincodeActionPayload{}
+		if err := proto.Unmarshal(action.Payload, actionPayload); err != nil {
+			return nil, unmarshalError(fmt.Sprintf("payload of action %d", i), err)
+		}
+		response := &pb.ProposalResponsePayload{}
+		if err := proto.Unmarshal(actionPayload.GetAction().GetProposalResponsePayload(), response); err != nil {
+			return nil, unmarshalError(fmt.Sprintf("proposal response of action %d", i), err)
+		}
+		ccAction := &pb.ChaincodeAction{}
+		if err := proto.Unmarshal(response.Extension, ccAction); err != nil {
+			return nil, unmarshalError(fmt.Sprintf("chaincode action %d", i), err)
+		}
+		if len(ccAction.Events) == 0 {
+			continue
+		}
+		ev := &pb.ChaincodeEvent{}
+		if err := proto.Unmarshal(ccAction.Events, ev); err != nil {
+			return nil, unmarshalError(fmt.Sprintf("chaincode event of action %d", i), err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// unmarshalError describes which message failed to decode.
+func unmarshalError(msg string, err error) error {
+	return fmt.Errorf("invalid %s: %w", msg, err)
+}
+
+// envelope encodes the transaction as an unsigned envelope, with one
+// transaction action per chaincode event.
+func (tx *Transaction) envelope() []byte {
+	sh := mustMarshal(&cb.SignatureHeader{
+		Creator: mustMarshal(&msp.SerializedIdentity{Mspid: tx.CreatorMSPID}),
+	})
+
+	ptx := &pb.Transaction{}
+	for _, ev := range tx.ChaincodeEvents {
+		response := &pb.ProposalResponsePayload{
+			Extension: mustMarshal(&pb.ChaincodeAction{Events: mustMarshal(ev)}),
+		}
+		actionPayload := &pb.ChaincodeActionPayload{
+			Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: mustMarshal(response)},
+		}
+		ptx.Actions = append(ptx.Actions, &pb.TransactionAction{
+			Header:  sh,
+			Payload: mustMarshal(actionPayload),
+		})
+	}
+
+	ch := &cb.ChannelHeader{
+		Type:      int32(tx.Type),
+		ChannelId: tx.ChannelID,
+		TxId:      tx.TxID,
+	}
+	if !tx.Timestamp.IsZero() {
+		ch.Timestamp = timestamppb.New(tx.Timestamp)
+	}
+	payload := &cb.Payload{
+		Header: &cb.Header{
+			ChannelHeader:   mustMarshal(ch),
+			SignatureHeader: sh,
+		},
+		Data: mustMarshal(ptx),
+	}
+	return mustMarshal(&cb.Envelope{Payload: mustMarshal(payload)})
+}
+
+// mustMarshal encodes a message assembled by this package, which only
+// fails on strings that are not valid UTF-8.
+func mustMarshal(m proto.Message) []byte {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fabric
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	testCases := map[string]struct {
+		tx Transaction
+	}{
+		"endorser transaction with events": {
+			tx: testTransactions()[0],
+		},
+		"endorser transaction without events": {
+			tx: testTransactions()[1],
+		},
+		"config transaction": {
+			tx: testTransactions()[2],
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, err := parseEnvelope(tc.tx.envelope())
+			if err != nil {
+				t.Fatalf("parseEnvelope() = %v", err)
+			}
+			if got.TxID != tc.tx.TxID || got.Type != tc.tx.Type || got.CreatorMSPID != tc.tx.CreatorMSPID {
+				t.Errorf("parseEnvelope() = %+v, want headers of %+v", got, tc.tx)
+			}
+			if !got.Timestamp.Equal(tc.tx.Timestamp) {
+				t.Errorf("Timestamp = %v, want %v", got.Timestamp, tc.tx.Timestamp)
+			}
+			if diff := cmp.Diff(tc.tx.ChaincodeEvents, got.ChaincodeEvents, cmpopts.EquateEmpty(), protocmp.Transform()); diff != "" {
+				t.Errorf("ChaincodeEvents (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseEnvelopeWithoutHeader(t *testing.T) {
+	env := &cb.Envelope{Payload: mustMarshal(&cb.Payload{Data: []byte("data")})}
+	if _, err := parseEnvelope(mustMarshal(env)); err == nil {
+		t.Error("parseEnvelope() = nil, want an error")
+	}
+}
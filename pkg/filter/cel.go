@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+const (
+	// celEventVariable is the CEL variable holding the context attributes
+	// and the extensions of the event, by name.
+	celEventVariable = "event"
+	// celDataVariable is the CEL variable holding the data of the event,
+	// decoded when it is JSON, and null otherwise.
+	celDataVariable = "data"
+)
+
+// celEnv declares the variables of CEL expressions.
+var celEnv, celEnvErr = cel.NewEnv(cel.Declarations(
+	decls.NewVar(celEventVariable, decls.NewMapType(decls.String, decls.Dyn)),
+	decls.NewVar(celDataVariable, decls.Dyn),
+))
+
+// celFilter is a CEL expression, evaluated on the attributes and the decoded
+// data of events.
+type celFilter struct {
+	program cel.Program
+}
+
+// ParseCEL parses a CEL expression, such as
+// `event.eventname == "Transfer" && int(data.args.value) > 1000000`.
+// Expressions must evaluate to a boolean.
+func ParseCEL(expression string) (Filter, error) {
+	if celEnvErr != nil {
+		return nil, celEnvErr
+	}
+	ast, issues := celEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if t := ast.ResultType(); !isBoolOrDyn(t) {
+		return nil, fmt.Errorf("expression must evaluate to a boolean, not to %v", t)
+	}
+	program, err := celEnv.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &celFilter{program: program}, nil
+}
+
+func isBoolOrDyn(t *exprpb.Type) bool {
+	return t.GetPrimitive() == exprpb.Type_BOOL || t.GetDyn() != nil
+}
+
+func (f *celFilter) Match(event cloudevents.Event) (bool, error) {
+	data, err := celData(event)
+	if err != nil {
+		return false, err
+	}
+	result, _, err := f.program.Eval(map[string]interface{}{
+		celEventVariable: celAttributes(event),
+		celDataVariable:  data,
+	})
+	if err != nil {
+		return false, err
+	}
+	match, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %v, not to a boolean", result.Type())
+	}
+	return match, nil
+}
+
+// celAttributes returns the context attributes set on an event, along with
+// its extensions.
+func celAttributes(event cloudevents.Event) map[string]interface{} {
+	attrs := make(map[string]interface{}, 8+len(event.Extensions()))
+	for name, value := range event.Extensions() {
+		attrs[name] = value
+	}
+	attrs["specversion"] = event.SpecVersion()
+	attrs["id"] = event.ID()
+	attrs["source"] = event.Source()
+	attrs["type"] = event.Type()
+	if subject := event.Subject(); subject != "" {
+		attrs["subject"] = subject
+	}
+	if t := event.Time(); !t.IsZero() {
+		attrs["time"] = t
+	}
+	if contentType := event.DataContentType(); contentType != "" {
+		attrs["datacontenttype"] = contentType
+	}
+	if schema := event.DataSchema(); schema != "" {
+		attrs["dataschema"] = schema
+	}
+	return attrs
+}
+
+// celData decodes the data of an event when it is JSON. Integral numbers are
+// decoded as integers, so that they compare with integer literals.
+func celData(event cloudevents.Event) (interface{}, error) {
+	contentType := event.DataMediaType()
+	if len(event.Data()) == 0 || contentType != "" && !isJSON(contentType) {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(event.Data()))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode event data: %w", err)
+	}
+	return convertNumbers(data), nil
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == cloudevents.ApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// convertNumbers replaces the JSON numbers of a decoded value by integers or
+// by floats.
+func convertNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = convertNumbers(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = convertNumbers(elem)
+		}
+	}
+	return value
+}
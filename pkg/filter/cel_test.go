@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestParseCEL(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       bool
+		wantErr    bool
+	}{{
+		expression: `event.eventname == "Transfer"`,
+		want:       true,
+	}, {
+		expression: `event.type.endsWith(".evm.log") && event.subject == "19000000"`,
+		want:       true,
+	}, {
+		expression: `data.args.to == event.toaddr`,
+		want:       true,
+	}, {
+		expression: `int(data.args.value) > 1000000`,
+		want:       true,
+	}, {
+		expression: `data.logIndex == 3`,
+		want:       true,
+	}, {
+		expression: `event.blocknumber < 19000000`,
+		want:       false,
+	}, {
+		expression: `"fromaddr" in event && event.fromaddr == data.args.from`,
+		want:       false,
+	}, {
+		expression: `event.fromaddr == data.args.from`,
+		wantErr:    true,
+	}, {
+		expression: `data.args.value`,
+		wantErr:    true,
+	}}
+
+	event := transferEvent(t)
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			f, err := ParseCEL(tt.expression)
+			if err != nil {
+				t.Fatal("ParseCEL() =", err)
+			}
+			got, err := f.Match(event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCELInvalid(t *testing.T) {
+	for _, expression := range []string{
+		`event.eventname ==`,
+		`block.number > 1`,
+		`event.type + "x"`,
+		`1 + 1`,
+	} {
+		if _, err := ParseCEL(expression); err == nil {
+			t.Errorf("ParseCEL(%q) = nil error, want one", expression)
+		}
+	}
+}
+
+func TestCELIgnoresNonJSONData(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetID("1")
+	event.SetType("example")
+	event.SetSource("example")
+	if err := event.SetData(cloudevents.TextPlain, "not json"); err != nil {
+		t.Fatal("SetData() =", err)
+	}
+
+	f, err := ParseCEL(`data == null`)
+	if err != nil {
+		t.Fatal("ParseCEL() =", err)
+	}
+	if got, err := f.Match(event); err != nil || !got {
+		t.Errorf("Match() = %v, %v, want true", got, err)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package filter evaluates CloudEvents SQL and CEL expressions on events, so
+// that a receive adapter only delivers the events its sink is interested in.
+package filter
+
+import (
+	"fmt"
+
+	cesql "github.com/cloudevents/sdk-go/sql/v2"
+	cesqlparser "github.com/cloudevents/sdk-go/sql/v2/parser"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Filter selects events.
+type Filter interface {
+	// Match reports whether an event passes the filter. An event the
+	// expression cannot be evaluated on, for instance because it lacks an
+	// attribute, does not pass it, and the error tells why.
+	Match(event cloudevents.Event) (bool, error)
+}
+
+// cesqlFilter is a CloudEvents SQL expression, evaluated on the context
+// attributes and the extensions of events.
+type cesqlFilter struct {
+	expression cesql.Expression
+}
+
+// ParseCESQL parses a CloudEvents SQL expression, such as
+// "eventname = 'Transfer' AND toaddr = '0x...'".
+func ParseCESQL(expression string) (f Filter, err error) {
+	// The parser panics on some malformed expressions.
+	defer func() {
+		if r := recover(); r != nil {
+			f, err = nil, fmt.Errorf("syntax error: %v", r)
+		}
+	}()
+
+	expr, err := cesqlparser.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &cesqlFilter{expression: expr}, nil
+}
+
+func (f *cesqlFilter) Match(event cloudevents.Event) (bool, error) {
+	result, err := f.expression.Evaluate(event)
+	if err != nil {
+		return false, err
+	}
+	match, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %T, not to a boolean", result)
+	}
+	return match, nil
+}
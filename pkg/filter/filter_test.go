@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func transferEvent(t *testing.T) cloudevents.Event {
+	t.Helper()
+	event := cloudevents.NewEvent()
+	event.SetID("0x7e-0")
+	event.SetType("dev.knative.source.blockchain.evm.log")
+	event.SetSource("eip155:1/contract/0xdac17f958d2ee523a2206206994597c13d831ec7")
+	event.SetSubject("19000000")
+	event.SetExtension("eventname", "Transfer")
+	event.SetExtension("blocknumber", int32(19000000))
+	event.SetExtension("toaddr", "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359")
+	if err := event.SetData(cloudevents.ApplicationJSON, map[string]interface{}{
+		"event": "Transfer",
+		"args": map[string]interface{}{
+			"from":  "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+			"to":    "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359",
+			"value": "2500000",
+		},
+		"logIndex": 3,
+	}); err != nil {
+		t.Fatal("SetData() =", err)
+	}
+	return event
+}
+
+func TestParseCESQL(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       bool
+		wantErr    bool
+	}{{
+		expression: "eventname = 'Transfer'",
+		want:       true,
+	}, {
+		expression: "eventname = 'Approval'",
+		want:       false,
+	}, {
+		expression: "eventname = 'Transfer' AND toaddr = '0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359'",
+		want:       true,
+	}, {
+		expression: "blocknumber >= 19000000",
+		want:       true,
+	}, {
+		expression: "type LIKE '%.evm.log' AND source LIKE 'eip155:1/%'",
+		want:       true,
+	}, {
+		expression: "fromaddr = '0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed'",
+		wantErr:    true,
+	}, {
+		expression: "subject",
+		wantErr:    true,
+	}}
+
+	event := transferEvent(t)
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			f, err := ParseCESQL(tt.expression)
+			if err != nil {
+				t.Fatal("ParseCESQL() =", err)
+			}
+			got, err := f.Match(event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCESQLInvalid(t *testing.T) {
+	for _, expression := range []string{
+		"eventname = ",
+		"eventname = 'Transfer' AND",
+		"(eventname = 'Transfer'",
+	} {
+		if _, err := ParseCESQL(expression); err == nil {
+			t.Errorf("ParseCESQL(%q) = nil error, want one", expression)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonrpc contains a minimal JSON-RPC 2.0 client used to talk to
+// blockchain nodes.
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+const version = "2.0"
+
+// request is a JSON-RPC 2.0 request object.
+type request struct {
+	Version string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// response is a JSON-RPC 2.0 response object.
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is an error object returned by a JSON-RPC server.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// HTTPError is returned when a server answers a request, or a WebSocket
+// handshake, with an unexpected HTTP status, e.g. when credentials are
+// missing.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Body)
+}
+
+// Option configures a Client or a WSClient.
+type Option func(*options)
+
+type options struct {
+	header http.Header
+}
+
+// WithHeader sets an HTTP header on every request, or on the WebSocket
+// handshake, e.g. to pass credentials.
+func WithHeader(name, value string) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set(name, value)
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Client calls methods on a JSON-RPC 2.0 server over HTTP.
+type Client struct {
+	url        string
+	header     http.Header
+	httpClient *http.Client
+	nextID     uint64
+}
+
+// NewClient returns a Client that sends requests to the given URL.
+func NewClient(url string, opts ...Option) *Client {
+	return &Client{
+		url:        url,
+		header:     newOptions(opts).header,
+		httpClient: cleanhttp.DefaultPooledClient(),
+	}
+}
+
+// Call invokes the given method with params and unmarshals the result
+// into result, which may be nil if the caller is not interested in it.
+func (c *Client) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(&request{
+		Version: version,
+		ID:      atomic.AddUint64(&c.nextID, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s request failed with %w", method, &HTTPError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(respBody))})
+	}
+
+	return decodeResponse(respBody, method, result)
+}
+
+func decodeResponse(body []byte, method string, result interface{}) error {
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", method, err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal %s result: %w", method, err)
+	}
+	return nil
+}
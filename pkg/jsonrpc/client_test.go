@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCall(t *testing.T) {
+	testCases := map[string]struct {
+		handler   http.HandlerFunc
+		want      string
+		wantErr   bool
+		wantRPCEr *Error
+	}{
+		"result": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var req request
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatal(err)
+				}
+				if req.Method != "eth_blockNumber" || req.Version != version {
+					t.Errorf("unexpected request %+v", req)
+				}
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+			},
+			want: "0x10",
+		},
+		"rpc error": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+			},
+			wantErr:   true,
+			wantRPCEr: &Error{Code: -32601, Message: "method not found"},
+		},
+		"http error": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			wantErr: true,
+		},
+		"malformed response": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`not json`))
+			},
+			wantErr: true,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			var got string
+			err := NewClient(server.URL).Call(context.Background(), &got, "eth_blockNumber")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Call() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantRPCEr != nil {
+				var rpcErr *Error
+				if !errors.As(err, &rpcErr) {
+					t.Fatalf("Call() error = %v, want a JSON-RPC error", err)
+				}
+				if diff := cmp.Diff(tc.wantRPCEr, rpcErr); diff != "" {
+					t.Errorf("unexpected error (-want, +got) = %v", diff)
+				}
+			}
+			if got != tc.want {
+				t.Errorf("Call() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCallWithHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, WithHeader("Authorization", "Bearer secret"))
+	if err := c.Call(context.Background(), nil, "eth_chainId"); err != nil {
+		t.Errorf("Call() = %v", err)
+	}
+	err := NewClient(server.URL).Call(context.Background(), nil, "eth_chainId")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Call() = %v without credentials, want an HTTPError with status 401", err)
+	}
+}
@@ -0,0 +1,420 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// pingInterval is how often the connection is checked for liveness.
+	pingInterval = 30 * time.Second
+	// pongWait is how long to wait for any message, including the reply to
+	// a ping, before considering the connection dead.
+	pongWait = 2 * pingInterval
+	// maxQueuedNotifications is the number of notifications buffered for a
+	// subscription whose reader is lagging behind before the connection is
+	// dropped.
+	maxQueuedNotifications = 10000
+)
+
+// ErrClosed is returned for calls made on a closed WSClient.
+var ErrClosed = errors.New("websocket connection closed")
+
+// message is either a response to a request, or a notification sent by the
+// server for a subscription.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// notificationParams holds the params of a subscription notification.
+type notificationParams struct {
+	Subscription json.RawMessage `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// WSClient calls methods and receives subscription notifications from a
+// JSON-RPC 2.0 server over a single WebSocket connection.
+type WSClient struct {
+	conn *websocket.Conn
+
+	// writeMu serializes writes, which the connection does not support
+	// concurrently.
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	nextID        uint64
+	pending       map[uint64]*pendingCall
+	subscriptions map[string]*Subscription
+	// requestSubscriptions are the subscriptions notified through
+	// responses to their request, by request ID.
+	requestSubscriptions map[uint64]*Subscription
+	err                  error
+
+	done chan struct{}
+}
+
+// DialWebSocket connects to the JSON-RPC server listening at url.
+func DialWebSocket(ctx context.Context, url string, opts ...Option) (*WSClient, error) {
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, url, newOptions(opts).header)
+	if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to dial %s: %w", url, &HTTPError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", url, err)
+	}
+
+	c := &WSClient{
+		conn:                 conn,
+		pending:              make(map[uint64]*pendingCall),
+		subscriptions:        make(map[string]*Subscription),
+		requestSubscriptions: make(map[uint64]*Subscription),
+		done:                 make(chan struct{}),
+	}
+
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	go c.readLoop()
+	go c.pingLoop()
+	return c, nil
+}
+
+// Done returns a channel that is closed once the connection is closed.
+func (c *WSClient) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection was closed, or nil while it is
+// still open.
+func (c *WSClient) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection. All pending calls fail and all subscriptions
+// stop receiving notifications.
+func (c *WSClient) Close() error {
+	c.closeWithError(ErrClosed)
+	return nil
+}
+
+// Call invokes the given method with params and unmarshals the result
+// into result, which may be nil if the caller is not interested in it.
+func (c *WSClient) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	resp, err := c.roundTrip(ctx, method, params, nil)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal %s result: %w", method, err)
+	}
+	return nil
+}
+
+// Subscribe invokes method, which must return a subscription ID (e.g.
+// eth_subscribe), and delivers the result of every notification sent for
+// that subscription to ch, in order.
+func (c *WSClient) Subscribe(ctx context.Context, ch chan<- json.RawMessage, method string, params ...interface{}) (*Subscription, error) {
+	return c.subscribe(ctx, ch, false, method, params)
+}
+
+// SubscribeResponses invokes method, and delivers the result of every
+// further response sent with the ID of the request to ch, in order. This is
+// how the subscribe method of CometBFT notifies events. An error response
+// closes the connection, as the server then cancelled the subscription.
+func (c *WSClient) SubscribeResponses(ctx context.Context, ch chan<- json.RawMessage, method string, params ...interface{}) (*Subscription, error) {
+	return c.subscribe(ctx, ch, true, method, params)
+}
+
+func (c *WSClient) subscribe(ctx context.Context, ch chan<- json.RawMessage, byRequest bool, method string, params []interface{}) (*Subscription, error) {
+	sub := &Subscription{
+		ch:        ch,
+		notify:    make(chan struct{}, 1),
+		done:      c.done,
+		byRequest: byRequest,
+	}
+	resp, err := c.roundTrip(ctx, method, params, sub)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return sub, nil
+}
+
+// pendingCall is a request waiting for its response.
+type pendingCall struct {
+	resp chan *message
+	// sub is registered as soon as a successful response is read, so that
+	// no notification sent right after it is missed.
+	sub *Subscription
+}
+
+func (c *WSClient) roundTrip(ctx context.Context, method string, params []interface{}, sub *Subscription) (*message, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	call := &pendingCall{
+		resp: make(chan *message, 1),
+		sub:  sub,
+	}
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = call
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	c.writeMu.Lock()
+	err := c.conn.WriteJSON(&request{
+		Version: version,
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+	c.writeMu.Unlock()
+	if err != nil {
+		c.closeWithError(err)
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
+	}
+
+	select {
+	case resp := <-call.resp:
+		return resp, nil
+	case <-c.done:
+		return nil, c.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *WSClient) readLoop() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.closeWithError(err)
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.closeWithError(fmt.Errorf("failed to unmarshal message: %w", err))
+			return
+		}
+		if err := c.dispatch(&msg); err != nil {
+			c.closeWithError(err)
+			return
+		}
+	}
+}
+
+func (c *WSClient) dispatch(msg *message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(msg.ID) > 0 && string(msg.ID) != "null" {
+		id, ok := parseID(msg.ID)
+		if !ok {
+			// Not a request made through this client.
+			return nil
+		}
+		call, ok := c.pending[id]
+		if !ok {
+			sub, ok := c.requestSubscriptions[id]
+			if !ok {
+				return nil
+			}
+			if msg.Error != nil {
+				return fmt.Errorf("subscription %s: %w", sub.ID, msg.Error)
+			}
+			return sub.enqueue(msg.Result)
+		}
+		// Further responses with the same ID are notifications.
+		delete(c.pending, id)
+		if call.sub != nil && msg.Error == nil {
+			if call.sub.byRequest {
+				call.sub.ID = strconv.FormatUint(id, 10)
+				c.requestSubscriptions[id] = call.sub
+			} else {
+				call.sub.ID = string(bytes.TrimSpace(msg.Result))
+				c.subscriptions[call.sub.ID] = call.sub
+			}
+			go call.sub.forward()
+		}
+		call.resp <- msg
+		return nil
+	}
+
+	if msg.Method == "" {
+		return nil
+	}
+	var params notificationParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return fmt.Errorf("failed to unmarshal %s notification: %w", msg.Method, err)
+	}
+	sub, ok := c.subscriptions[string(bytes.TrimSpace(params.Subscription))]
+	if !ok {
+		// Not a subscription made through this client.
+		return nil
+	}
+	return sub.enqueue(params.Result)
+}
+
+// parseID returns the request ID of a response. Older Tendermint versions
+// suffix the ID of the subscribe request with #event in the responses
+// notifying events.
+func parseID(raw json.RawMessage) (uint64, bool) {
+	var id uint64
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id, true
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimSuffix(s, "#event"), 10, 64)
+	return id, err == nil
+}
+
+func (c *WSClient) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.writeMu.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingInterval))
+			c.writeMu.Unlock()
+			if err != nil {
+				c.closeWithError(err)
+				return
+			}
+		}
+	}
+}
+
+func (c *WSClient) closeWithError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+// Subscription receives the notifications sent by the server for a single
+// subscription.
+type Subscription struct {
+	// ID is the subscription ID returned by the server, or the ID of the
+	// request of subscriptions notified through responses.
+	ID string
+	// byRequest is set for subscriptions notified through responses.
+	byRequest bool
+
+	ch     chan<- json.RawMessage
+	done   <-chan struct{}
+	notify chan struct{}
+
+	mu    sync.Mutex
+	queue []json.RawMessage
+}
+
+// enqueue buffers a notification so that a slow reader never blocks the
+// responses to calls made on the same connection.
+func (s *Subscription) enqueue(result json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) >= maxQueuedNotifications {
+		return fmt.Errorf("subscription %s: too many queued notifications", s.ID)
+	}
+	s.queue = append(s.queue, result)
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *Subscription) forward() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.notify:
+		}
+
+		for {
+			s.mu.Lock()
+			if len(s.queue) == 0 {
+				s.mu.Unlock()
+				break
+			}
+			next := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+
+			select {
+			case s.ch <- next:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
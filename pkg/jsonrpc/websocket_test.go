@@ -0,0 +1,258 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+)
+
+// newWebSocketServer starts a server answering every request with the
+// response built by handle. Notifications for a successful subscription are
+// sent right after its response, as eth_subscription requests, or as
+// responses to subscribe requests the way CometBFT does. Notifications that
+// are errors are sent as error responses. Connections are closed when
+// disconnect is closed.
+func newWebSocketServer(t *testing.T, disconnect <-chan struct{}, handle func(req *request) (interface{}, *Error), notifications ...interface{}) (*httptest.Server, string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		go func() {
+			<-disconnect
+			conn.Close()
+		}()
+
+		for {
+			var req request
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			result, rpcErr := handle(&req)
+			resp := map[string]interface{}{"jsonrpc": version, "id": req.ID}
+			if rpcErr != nil {
+				resp["error"] = rpcErr
+			} else {
+				resp["result"] = result
+			}
+			conn.WriteJSON(resp)
+
+			if req.Method == "eth_subscribe" && rpcErr == nil {
+				for _, n := range notifications {
+					conn.WriteJSON(map[string]interface{}{
+						"jsonrpc": version,
+						"method":  "eth_subscription",
+						"params": map[string]interface{}{
+							"subscription": result,
+							"result":       n,
+						},
+					})
+				}
+			}
+			if req.Method == "subscribe" && rpcErr == nil {
+				for i, n := range notifications {
+					notification := map[string]interface{}{"jsonrpc": version, "id": req.ID}
+					if i%2 == 1 {
+						notification["id"] = fmt.Sprintf("%d#event", req.ID)
+					}
+					if err, ok := n.(*Error); ok {
+						notification["error"] = err
+					} else {
+						notification["result"] = n
+					}
+					conn.WriteJSON(notification)
+				}
+			}
+		}
+	}))
+	return server, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestWSClientCall(t *testing.T) {
+	disconnect := make(chan struct{})
+	defer close(disconnect)
+	server, url := newWebSocketServer(t, disconnect, func(req *request) (interface{}, *Error) {
+		if req.Method == "eth_chainId" {
+			return "0x1", nil
+		}
+		return nil, &Error{Code: -32601, Message: "method not found"}
+	})
+	defer server.Close()
+
+	c, err := DialWebSocket(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var got string
+	if err := c.Call(context.Background(), &got, "eth_chainId"); err != nil {
+		t.Fatalf("Call() = %v", err)
+	}
+	if got != "0x1" {
+		t.Errorf("Call() = %q, want %q", got, "0x1")
+	}
+
+	var rpcErr *Error
+	if err := c.Call(context.Background(), nil, "eth_unknown"); !errors.As(err, &rpcErr) {
+		t.Errorf("Call() = %v, want a JSON-RPC error", err)
+	}
+}
+
+func TestDialWebSocketUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := DialWebSocket(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"))
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized || httpErr.Body != "invalid API key" {
+		t.Errorf("DialWebSocket() = %v, want an HTTPError with status 401", err)
+	}
+}
+
+func TestWSClientSubscribe(t *testing.T) {
+	disconnect := make(chan struct{})
+	server, url := newWebSocketServer(t, disconnect, func(req *request) (interface{}, *Error) {
+		return "0xcd0c3e8af590364c09d0fa6a1210faf5", nil
+	}, "0x1", "0x2", "0x3")
+	defer server.Close()
+
+	c, err := DialWebSocket(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ch := make(chan json.RawMessage)
+	sub, err := c.Subscribe(context.Background(), ch, "eth_subscribe", "newHeads")
+	if err != nil {
+		t.Fatalf("Subscribe() = %v", err)
+	}
+	if want := `"0xcd0c3e8af590364c09d0fa6a1210faf5"`; sub.ID != want {
+		t.Errorf("subscription ID = %s, want %s", sub.ID, want)
+	}
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case raw := <-ch:
+			got = append(got, string(raw))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for notifications, got %v", got)
+		}
+	}
+	if diff := cmp.Diff([]string{`"0x1"`, `"0x2"`, `"0x3"`}, got); diff != "" {
+		t.Errorf("unexpected notifications (-want, +got) = %v", diff)
+	}
+
+	// Losing the connection is reported.
+	close(disconnect)
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the connection to close")
+	}
+	if c.Err() == nil {
+		t.Error("Err() = nil after the connection was lost")
+	}
+	if err := c.Call(context.Background(), nil, "eth_chainId"); err == nil {
+		t.Error("Call() = nil on a closed connection")
+	}
+}
+
+func TestWSClientSubscribeResponses(t *testing.T) {
+	disconnect := make(chan struct{})
+	defer close(disconnect)
+	server, url := newWebSocketServer(t, disconnect, func(req *request) (interface{}, *Error) {
+		return map[string]interface{}{}, nil
+	}, map[string]int{"height": 1}, map[string]int{"height": 2})
+	defer server.Close()
+
+	c, err := DialWebSocket(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ch := make(chan json.RawMessage)
+	sub, err := c.SubscribeResponses(context.Background(), ch, "subscribe", "tm.event='NewBlock'")
+	if err != nil {
+		t.Fatalf("SubscribeResponses() = %v", err)
+	}
+	if sub.ID != "1" {
+		t.Errorf("subscription ID = %s, want 1", sub.ID)
+	}
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case raw := <-ch:
+			got = append(got, string(raw))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for notifications, got %v", got)
+		}
+	}
+	if diff := cmp.Diff([]string{`{"height":1}`, `{"height":2}`}, got); diff != "" {
+		t.Errorf("unexpected notifications (-want, +got) = %v", diff)
+	}
+
+}
+
+func TestWSClientSubscribeResponsesCancelled(t *testing.T) {
+	disconnect := make(chan struct{})
+	defer close(disconnect)
+	server, url := newWebSocketServer(t, disconnect, func(req *request) (interface{}, *Error) {
+		return map[string]interface{}{}, nil
+	}, &Error{Code: -32000, Message: "subscription was cancelled"})
+	defer server.Close()
+
+	c, err := DialWebSocket(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.SubscribeResponses(context.Background(), make(chan json.RawMessage), "subscribe", "tm.event='NewBlock'"); err != nil {
+		t.Fatalf("SubscribeResponses() = %v", err)
+	}
+
+	// The server cancelling the subscription closes the connection.
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the connection to close")
+	}
+	var rpcErr *Error
+	if !errors.As(c.Err(), &rpcErr) {
+		t.Errorf("Err() = %v, want the JSON-RPC error", c.Err())
+	}
+}
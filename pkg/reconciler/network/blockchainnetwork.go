@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	blockchainnetworkreconciler "knative.dev/eventing-blockchain/pkg/client/injection/reconciler/sources/v1alpha1/blockchainnetwork"
+	"knative.dev/eventing-blockchain/pkg/common"
+)
+
+const (
+	// defaultProbeInterval is how often the endpoints of a network are
+	// probed.
+	defaultProbeInterval = time.Minute
+
+	// probeTimeout bounds the probe of an endpoint.
+	probeTimeout = 10 * time.Second
+)
+
+// Reconciler reconciles a BlockchainNetwork object, probing its endpoints
+// once for all the sources referencing it.
+type Reconciler struct {
+	secretLister corev1listers.SecretLister
+
+	// probers probe the endpoints of the networks of each family.
+	probers map[sourcesv1alpha1.ChainFamily]prober
+
+	probeInterval time.Duration
+}
+
+// Check that our Reconciler implements Interface
+var _ blockchainnetworkreconciler.Interface = (*Reconciler)(nil)
+
+// ReconcileKind implements Interface.ReconcileKind.
+func (r *Reconciler) ReconcileKind(ctx context.Context, network *sourcesv1alpha1.BlockchainNetwork) pkgreconciler.Event {
+	probe, ok := r.probers[network.Spec.Family]
+	if !ok {
+		// Sources find out whether the endpoints work when they connect.
+		network.Status.Endpoints = nil
+		network.Status.MarkNotProbed("NotProbed", "Endpoints of %s chains are not probed.", network.Spec.Family)
+		return nil
+	}
+
+	statuses := make([]sourcesv1alpha1.EndpointStatus, 0, len(network.Spec.Endpoints))
+	var reachable, mismatched []string
+	var lastErr string
+	for i, e := range network.Spec.Endpoints {
+		status := sourcesv1alpha1.EndpointStatus{Name: endpointName(e.URL)}
+
+		chainID, head, err := r.probeEndpoint(ctx, probe, e)
+		switch {
+		case err != nil:
+			logging.FromContext(ctx).Infow("Endpoint probe failed", zap.Int("endpoint", i), zap.String("name", status.Name), zap.Error(err))
+			status.Message = err.Error()
+			lastErr = fmt.Sprintf("%s: %v", status.Name, err)
+		case chainID != network.Spec.ChainID:
+			status.ChainID, status.HeadBlock = chainID, head
+			status.Message = fmt.Sprintf("serves chain %s instead of %s", chainID, network.Spec.ChainID)
+			reachable = append(reachable, status.Name)
+			mismatched = append(mismatched, fmt.Sprintf("%s serves chain %s", status.Name, chainID))
+		default:
+			status.ChainID, status.HeadBlock = chainID, head
+			status.Healthy = true
+			reachable = append(reachable, status.Name)
+		}
+		statuses = append(statuses, status)
+	}
+	network.Status.Endpoints = statuses
+
+	if len(reachable) > 0 {
+		network.Status.MarkEndpointsReachable()
+	} else {
+		network.Status.MarkEndpointsUnreachable("EndpointsUnreachable", "No endpoint answered, last error: %s", lastErr)
+	}
+
+	switch {
+	case len(mismatched) > 0:
+		network.Status.MarkChainIDMismatch("ChainIDMismatch", "Endpoints serve another chain than %s: %s.",
+			network.Spec.ChainID, strings.Join(mismatched, ", "))
+	case len(reachable) == 0:
+		network.Status.MarkChainIDVerificationUnknown("EndpointsUnreachable", "No endpoint answered.")
+	default:
+		network.Status.MarkChainIDVerified()
+	}
+
+	// Endpoints go up and down without the network changing.
+	return controller.NewRequeueAfter(r.probeInterval)
+}
+
+// probeEndpoint probes an endpoint with its credentials, which are Secrets
+// of the namespace of the controller.
+func (r *Reconciler) probeEndpoint(ctx context.Context, probe prober, e sourcesv1alpha1.RPCEndpoint) (string, int64, error) {
+	var credentials string
+	if e.Credentials != nil {
+		var err error
+		secrets := r.secretLister.Secrets(system.Namespace())
+		if credentials, err = common.SecretFrom(secrets, e.Credentials.SecretKeyRef); err != nil {
+			return "", 0, fmt.Errorf("reading credentials: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	return probe(ctx, e.URL, credentials)
+}
+
+// endpointName identifies an endpoint without the path and query of its
+// URL, which often hold an API key.
+func endpointName(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret/fake"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
+	_ "knative.dev/pkg/system/testing"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+// newNode returns an EVM node serving the given chain ID, which answers
+// only requests carrying the given Authorization header when not empty.
+func newNode(t *testing.T, chainID, authorization string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authorization != "" && r.Header.Get("Authorization") != authorization {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var result string
+		switch req.Method {
+		case "eth_chainId":
+			result = chainID
+		case "eth_blockNumber":
+			result = "0x2a"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestReconciler(t *testing.T) (context.Context, *Reconciler) {
+	ctx, _ := SetupFakeContext(t)
+	return ctx, &Reconciler{
+		secretLister: secretinformer.Get(ctx).Lister(),
+		probers: map[sourcesv1alpha1.ChainFamily]prober{
+			sourcesv1alpha1.ChainFamilyEVM: probeEVM,
+		},
+		probeInterval: time.Minute,
+	}
+}
+
+func newTestNetwork(urls ...string) *sourcesv1alpha1.BlockchainNetwork {
+	network := &sourcesv1alpha1.BlockchainNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "mainnet"},
+		Spec: sourcesv1alpha1.BlockchainNetworkSpec{
+			Family:  sourcesv1alpha1.ChainFamilyEVM,
+			ChainID: "1",
+		},
+	}
+	for _, u := range urls {
+		network.Spec.Endpoints = append(network.Spec.Endpoints, sourcesv1alpha1.RPCEndpoint{URL: u})
+	}
+	network.Status.InitializeConditions()
+	return network
+}
+
+func TestReconcileKind(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	if err := secretinformer.Get(ctx).Informer().GetIndexer().Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider", Namespace: system.Namespace()},
+		Data:       map[string][]byte{"authorization": []byte("Bearer token")},
+	}); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+
+	node := newNode(t, "0x1", "")
+	provider := newNode(t, "0x1", "Bearer token")
+	down := newNode(t, "0x1", "")
+	down.Close()
+
+	network := newTestNetwork(node.URL, provider.URL, down.URL)
+	network.Spec.Endpoints[1].Credentials = &sourcesv1alpha1.SecretValueFromSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "provider"},
+			Key:                  "authorization",
+		},
+	}
+
+	err := r.ReconcileKind(ctx, network)
+	if ok, after := controller.IsRequeueKey(err); !ok || after != time.Minute {
+		t.Errorf("ReconcileKind() = %v, want a requeue after a minute", err)
+	}
+
+	if !network.Status.IsReady() {
+		t.Errorf("Ready = %v, want True", network.Status.GetCondition(sourcesv1alpha1.BlockchainNetworkConditionReady))
+	}
+	want := []sourcesv1alpha1.EndpointStatus{
+		{Name: endpointName(node.URL), Healthy: true, ChainID: "1", HeadBlock: 42},
+		{Name: endpointName(provider.URL), Healthy: true, ChainID: "1", HeadBlock: 42},
+		{Name: endpointName(down.URL)},
+	}
+	got := network.Status.Endpoints
+	if len(got) == 3 {
+		if got[2].Message == "" {
+			t.Error("Message of the endpoint down is empty, want the probe error")
+		}
+		got[2].Message = ""
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Endpoints (-want, +got) = %s", diff)
+	}
+}
+
+func TestReconcileKindUnhealthy(t *testing.T) {
+	down := newNode(t, "0x1", "")
+	down.Close()
+
+	tests := map[string]struct {
+		urls            []string
+		reachable       bool
+		chainIDVerified bool
+		reason          string
+	}{
+		"unreachable": {
+			urls:   []string{down.URL},
+			reason: "EndpointsUnreachable",
+		},
+		"unauthorized": {
+			urls:   []string{newNode(t, "0x1", "Bearer token").URL},
+			reason: "EndpointsUnreachable",
+		},
+		"chain ID mismatch": {
+			urls:      []string{newNode(t, "0x1", "").URL, newNode(t, "0xaa36a7", "").URL},
+			reachable: true,
+			reason:    "ChainIDMismatch",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, r := newTestReconciler(t)
+			network := newTestNetwork(test.urls...)
+
+			if ok, _ := controller.IsRequeueKey(r.ReconcileKind(ctx, network)); !ok {
+				t.Error("ReconcileKind() did not requeue")
+			}
+
+			if got := network.Status.GetCondition(sourcesv1alpha1.BlockchainNetworkConditionEndpointsReachable).IsTrue(); got != test.reachable {
+				t.Errorf("EndpointsReachable = %v, want %v", got, test.reachable)
+			}
+			cond := network.Status.GetCondition(sourcesv1alpha1.BlockchainNetworkConditionReady)
+			if !cond.IsFalse() && !cond.IsUnknown() || cond.Reason != test.reason {
+				t.Errorf("Ready = %v, want not True with reason %s", cond, test.reason)
+			}
+		})
+	}
+}
+
+func TestReconcileKindNotProbed(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	network := newTestNetwork("grpcs://peer0.org1.example.com:7051")
+	network.Spec.Family = sourcesv1alpha1.ChainFamilyFabric
+	network.Spec.ChainID = "mychannel"
+
+	if err := r.ReconcileKind(ctx, network); err != nil {
+		t.Errorf("ReconcileKind() = %v", err)
+	}
+	if !network.Status.IsReady() {
+		t.Errorf("Ready = %v, want True", network.Status.GetCondition(sourcesv1alpha1.BlockchainNetworkConditionReady))
+	}
+	for _, c := range []apis.ConditionType{
+		sourcesv1alpha1.BlockchainNetworkConditionEndpointsReachable,
+		sourcesv1alpha1.BlockchainNetworkConditionChainIDVerified,
+	} {
+		if cond := network.Status.GetCondition(c); !cond.IsTrue() || cond.Reason != "NotProbed" {
+			t.Errorf("condition %s = %v, want True with reason NotProbed", c, cond)
+		}
+	}
+	if network.Status.Endpoints != nil {
+		t.Errorf("Endpoints = %v, want none", network.Status.Endpoints)
+	}
+}
+
+func TestReconcileKindCustomProber(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	r.probers[sourcesv1alpha1.ChainFamilyEVM] = func(ctx context.Context, url, credentials string) (string, int64, error) {
+		if url == "https://a.example.com/key" {
+			return "", 0, errors.New("connection refused")
+		}
+		return "1", 7, nil
+	}
+	network := newTestNetwork("https://a.example.com/key", "https://b.example.com/key")
+
+	_ = r.ReconcileKind(ctx, network)
+
+	want := []sourcesv1alpha1.EndpointStatus{
+		{Name: "https://a.example.com", Message: "connection refused"},
+		{Name: "https://b.example.com", Healthy: true, ChainID: "1", HeadBlock: 7},
+	}
+	if diff := cmp.Diff(want, network.Status.Endpoints); diff != "" {
+		t.Errorf("Endpoints (-want, +got) = %s", diff)
+	}
+	if !network.Status.IsReady() {
+		t.Errorf("Ready = %v, want True with a healthy endpoint", network.Status.GetCondition(sourcesv1alpha1.BlockchainNetworkConditionReady))
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	blockchainnetworkinformer "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainnetwork"
+	blockchainnetworkreconciler "knative.dev/eventing-blockchain/pkg/client/injection/reconciler/sources/v1alpha1/blockchainnetwork"
+)
+
+// NewController initializes the controller and is called by the generated code
+// Registers event handlers to enqueue events
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+
+	blockchainNetworkInformer := blockchainnetworkinformer.Get(ctx)
+
+	r := &Reconciler{
+		secretLister: secretinformer.Get(ctx).Lister(),
+		probers: map[sourcesv1alpha1.ChainFamily]prober{
+			sourcesv1alpha1.ChainFamilyEVM:        probeEVM,
+			sourcesv1alpha1.ChainFamilyBitcoin:    probeBitcoin,
+			sourcesv1alpha1.ChainFamilyTendermint: probeTendermint,
+			sourcesv1alpha1.ChainFamilySolana:     probeSolana,
+			sourcesv1alpha1.ChainFamilyBeacon:     probeBeacon,
+		},
+		probeInterval: defaultProbeInterval,
+	}
+
+	impl := blockchainnetworkreconciler.NewImpl(ctx, r)
+
+	blockchainNetworkInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	return impl
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"knative.dev/eventing-blockchain/pkg/beacon"
+	"knative.dev/eventing-blockchain/pkg/jsonrpc"
+	"knative.dev/eventing-blockchain/pkg/solana"
+	"knative.dev/eventing-blockchain/pkg/tendermint"
+)
+
+// prober probes an endpoint, returning the chain ID it serves and the
+// number of its newest block.
+type prober func(ctx context.Context, url, credentials string) (chainID string, head int64, err error)
+
+// rpcCall calls a JSON-RPC method.
+type rpcCall func(ctx context.Context, result interface{}, method string, params ...interface{}) error
+
+// dialJSONRPC connects to a JSON-RPC endpoint over HTTP, or over WebSocket
+// for ws and wss URLs. The returned function closes the connection.
+func dialJSONRPC(ctx context.Context, endpoint, credentials string) (rpcCall, func(), error) {
+	var opts []jsonrpc.Option
+	if credentials != "" {
+		opts = append(opts, jsonrpc.WithHeader("Authorization", credentials))
+	}
+
+	if strings.HasPrefix(endpoint, "ws://") || strings.HasPrefix(endpoint, "wss://") {
+		ws, err := jsonrpc.DialWebSocket(ctx, endpoint, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ws.Call, func() { ws.Close() }, nil
+	}
+	return jsonrpc.NewClient(endpoint, opts...).Call, func() {}, nil
+}
+
+// probeEVM reads the chain ID and the head block number of an EVM node.
+func probeEVM(ctx context.Context, endpoint, credentials string) (string, int64, error) {
+	call, closeConn, err := dialJSONRPC(ctx, endpoint, credentials)
+	if err != nil {
+		return "", 0, err
+	}
+	defer closeConn()
+
+	var chainID, head string
+	if err := call(ctx, &chainID, "eth_chainId"); err != nil {
+		return "", 0, err
+	}
+	if err := call(ctx, &head, "eth_blockNumber"); err != nil {
+		return "", 0, err
+	}
+
+	id, err := parseQuantity(chainID)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid chain ID %q: %w", chainID, err)
+	}
+	n, err := parseQuantity(head)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid block number %q: %w", head, err)
+	}
+	return strconv.FormatUint(id, 10), int64(n), nil
+}
+
+// parseQuantity parses a hex-encoded JSON-RPC quantity.
+func parseQuantity(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, errors.New("missing 0x prefix")
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
+
+// probeBitcoin reads the name of the chain, e.g. main, and the height of
+// the head of a bitcoind node.
+func probeBitcoin(ctx context.Context, endpoint, credentials string) (string, int64, error) {
+	call, closeConn, err := dialJSONRPC(ctx, endpoint, credentials)
+	if err != nil {
+		return "", 0, err
+	}
+	defer closeConn()
+
+	var info struct {
+		Chain  string `json:"chain"`
+		Blocks int64  `json:"blocks"`
+	}
+	if err := call(ctx, &info, "getblockchaininfo"); err != nil {
+		return "", 0, err
+	}
+	if info.Chain == "" {
+		return "", 0, errors.New("no chain reported")
+	}
+	return info.Chain, info.Blocks, nil
+}
+
+// probeTendermint reads the chain ID and the height of the latest block of
+// a Tendermint or CometBFT node from its status.
+func probeTendermint(ctx context.Context, endpoint, credentials string) (string, int64, error) {
+	call, closeConn, err := dialJSONRPC(ctx, endpoint, credentials)
+	if err != nil {
+		return "", 0, err
+	}
+	defer closeConn()
+
+	var status tendermint.Status
+	if err := call(ctx, &status, "status"); err != nil {
+		return "", 0, err
+	}
+	if status.NodeInfo.Network == "" {
+		return "", 0, errors.New("no chain ID reported")
+	}
+	return status.NodeInfo.Network, status.SyncInfo.LatestBlockHeight, nil
+}
+
+// probeSolana reads the CAIP-2 reference of the cluster, derived from its
+// genesis hash, and the newest slot of a Solana node.
+func probeSolana(ctx context.Context, endpoint, credentials string) (string, int64, error) {
+	call, closeConn, err := dialJSONRPC(ctx, endpoint, credentials)
+	if err != nil {
+		return "", 0, err
+	}
+	defer closeConn()
+
+	var genesisHash string
+	if err := call(ctx, &genesisHash, "getGenesisHash"); err != nil {
+		return "", 0, err
+	}
+	if genesisHash == "" {
+		return "", 0, errors.New("no genesis hash reported")
+	}
+	var slot int64
+	if err := call(ctx, &slot, "getSlot"); err != nil {
+		return "", 0, err
+	}
+	return solana.ChainReference(genesisHash), slot, nil
+}
+
+// probeBeacon reads the chain ID of the deposit contract and the slot of
+// the head of a beacon node.
+func probeBeacon(ctx context.Context, endpoint, credentials string) (string, int64, error) {
+	var opts []beacon.Option
+	if credentials != "" {
+		opts = append(opts, beacon.WithHeader("Authorization", credentials))
+	}
+	api := beacon.NewClient(endpoint, opts...)
+
+	deposit, err := api.DepositContract(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	head, err := api.Header(ctx, "head")
+	if err != nil {
+		return "", 0, err
+	}
+	return strconv.FormatUint(deposit.ChainID, 10), int64(head.Header.Message.Slot), nil
+}
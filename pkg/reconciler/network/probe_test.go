@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newRPCNode returns a JSON-RPC node answering each method with the given
+// result.
+func newRPCNode(t *testing.T, results map[string]interface{}) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		result, ok := results[req.Method]
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32601, "message": "Method not found"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newBeaconNode returns a beacon node answering the given paths.
+func newBeaconNode(t *testing.T, responses map[string]interface{}) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProbers(t *testing.T) {
+	tests := map[string]struct {
+		probe       prober
+		node        func(t *testing.T) *httptest.Server
+		wantChainID string
+		wantHead    int64
+		wantErr     bool
+	}{
+		"bitcoin": {
+			probe: probeBitcoin,
+			node: func(t *testing.T) *httptest.Server {
+				return newRPCNode(t, map[string]interface{}{
+					"getblockchaininfo": map[string]interface{}{"chain": "main", "blocks": 840000},
+				})
+			},
+			wantChainID: "main",
+			wantHead:    840000,
+		},
+		"bitcoin without chain": {
+			probe: probeBitcoin,
+			node: func(t *testing.T) *httptest.Server {
+				return newRPCNode(t, map[string]interface{}{
+					"getblockchaininfo": map[string]interface{}{"blocks": 1},
+				})
+			},
+			wantErr: true,
+		},
+		"tendermint": {
+			probe: probeTendermint,
+			node: func(t *testing.T) *httptest.Server {
+				return newRPCNode(t, map[string]interface{}{
+					"status": map[string]interface{}{
+						"node_info": map[string]interface{}{"network": "cosmoshub-4", "version": "0.37.4"},
+						"sync_info": map[string]interface{}{"latest_block_height": "19000000"},
+					},
+				})
+			},
+			wantChainID: "cosmoshub-4",
+			wantHead:    19000000,
+		},
+		"solana": {
+			probe: probeSolana,
+			node: func(t *testing.T) *httptest.Server {
+				return newRPCNode(t, map[string]interface{}{
+					"getGenesisHash": "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d",
+					"getSlot":        250000000,
+				})
+			},
+			wantChainID: "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+			wantHead:    250000000,
+		},
+		"solana method not found": {
+			probe: probeSolana,
+			node: func(t *testing.T) *httptest.Server {
+				return newRPCNode(t, map[string]interface{}{})
+			},
+			wantErr: true,
+		},
+		"beacon": {
+			probe: probeBeacon,
+			node: func(t *testing.T) *httptest.Server {
+				return newBeaconNode(t, map[string]interface{}{
+					"/eth/v1/config/deposit_contract": map[string]interface{}{
+						"chain_id": "1", "address": "0x00000000219ab540356cbb839cbe05303d7705fa",
+					},
+					"/eth/v1/beacon/headers/head": map[string]interface{}{
+						"root":      "0x01",
+						"canonical": true,
+						"header": map[string]interface{}{
+							"message": map[string]interface{}{"slot": "9000000"},
+						},
+					},
+				})
+			},
+			wantChainID: "1",
+			wantHead:    9000000,
+		},
+		"beacon without head": {
+			probe: probeBeacon,
+			node: func(t *testing.T) *httptest.Server {
+				return newBeaconNode(t, map[string]interface{}{
+					"/eth/v1/config/deposit_contract": map[string]interface{}{"chain_id": "1"},
+				})
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			node := test.node(t)
+			chainID, head, err := test.probe(context.Background(), node.URL, "")
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("probe() = %s, %d, want an error", chainID, head)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("probe() = %v", err)
+			}
+			if chainID != test.wantChainID || head != test.wantHead {
+				t.Errorf("probe() = %s, %d, want %s, %d", chainID, head, test.wantChainID, test.wantHead)
+			}
+		})
+	}
+}
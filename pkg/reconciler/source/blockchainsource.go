@@ -0,0 +1,385 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/tracker"
+
+	eventingclientset "knative.dev/eventing/pkg/client/clientset/versioned"
+	eventinglisters "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	blockchainsourcereconciler "knative.dev/eventing-blockchain/pkg/client/injection/reconciler/sources/v1alpha1/blockchainsource"
+	sourceslisters "knative.dev/eventing-blockchain/pkg/client/listers/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/common"
+	"knative.dev/eventing-blockchain/pkg/reconciler/source/resources"
+)
+
+const (
+	// Name of the corev1.Events emitted from the reconciliation process
+	blockchainSourceDeploymentCreated = "BlockchainSourceDeploymentCreated"
+	blockchainSourceDeploymentUpdated = "BlockchainSourceDeploymentUpdated"
+
+	component = "blockchainsource"
+
+	// reasonAdapterNotReported is the reason of the conditions set from the
+	// reports of a receive adapter that did not report yet.
+	reasonAdapterNotReported = "AdapterNotReported"
+)
+
+func newWarningSinkNotFound(sink *duckv1.Destination) pkgreconciler.Event {
+	b, _ := json.Marshal(sink)
+	return pkgreconciler.NewEvent(corev1.EventTypeWarning, "SinkNotFound", "Sink not found: %s", string(b))
+}
+
+// Reconciler reconciles a BlockchainSource object
+type Reconciler struct {
+	kubeClientSet    kubernetes.Interface
+	deploymentLister appsv1listers.DeploymentLister
+	configMapLister  corev1listers.ConfigMapLister
+	secretLister     corev1listers.SecretLister
+	networkLister    sourceslisters.BlockchainNetworkLister
+
+	eventingClientSet eventingclientset.Interface
+	eventTypeLister   eventinglisters.EventTypeLister
+
+	// tracker reconciles sources again when the network they reference
+	// changes.
+	tracker tracker.Interface
+
+	receiveAdapterImage string
+
+	sinkResolver *resolver.URIResolver
+
+	configs reconcilersource.ConfigAccessor
+}
+
+// Check that our Reconciler implements Interface
+var _ blockchainsourcereconciler.Interface = (*Reconciler)(nil)
+
+// ReconcileKind implements Interface.ReconcileKind.
+func (r *Reconciler) ReconcileKind(ctx context.Context, source *sourcesv1alpha1.BlockchainSource) pkgreconciler.Event {
+	dest := source.Spec.Sink.DeepCopy()
+	if dest.Ref != nil {
+		// To call URIFromDestination(), dest.Ref must have a Namespace. If there is
+		// no Namespace defined in dest.Ref, we will use the Namespace of the source
+		// as the Namespace of dest.Ref.
+		if dest.Ref.Namespace == "" {
+			dest.Ref.Namespace = source.GetNamespace()
+		}
+	}
+
+	sinkURI, err := r.sinkResolver.URIFromDestinationV1(ctx, *dest, source)
+	if err != nil {
+		source.Status.MarkNoSink("NotFound", "")
+		return newWarningSinkNotFound(dest)
+	}
+	source.Status.MarkSink(sinkURI)
+
+	spec, err := r.reconcileNetwork(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkSecrets(ctx, source, spec); err != nil {
+		return err
+	}
+
+	if err := r.reconcileCheckpoint(ctx, source); err != nil {
+		logging.FromContext(ctx).Errorw("Unable to reconcile the checkpoint ConfigMap", zap.Error(err))
+		return err
+	}
+
+	ra, err := r.createReceiveAdapter(ctx, source, spec, sinkURI.String())
+	if err != nil {
+		logging.FromContext(ctx).Errorw("Unable to create the receive adapter", zap.Error(err))
+		source.Status.MarkNoServiceDeployed("DeploymentFailed", "Unable to create the receive adapter: %v", err)
+		return err
+	}
+	source.Status.MarkServiceDeployed(ra)
+
+	if err := r.reconcileEventTypes(ctx, source, spec); err != nil {
+		logging.FromContext(ctx).Errorw("Unable to reconcile the event types", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// checkSecrets verifies that the Secret keys referenced by the spec the
+// receive adapter runs with exist, since it would not start otherwise.
+func (r *Reconciler) checkSecrets(ctx context.Context, src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec) error {
+	secrets := r.secretLister.Secrets(src.Namespace)
+	for i, e := range spec.Endpoints {
+		if e.Credentials == nil {
+			continue
+		}
+		// The credentials of the network are copied by the reconciler
+		// itself, which the lister may not have seen yet.
+		if ref := e.Credentials.SecretKeyRef; ref != nil && ref.Name == resources.NetworkCredentialsSecretName(src) {
+			continue
+		}
+		if _, err := common.SecretFrom(secrets, e.Credentials.SecretKeyRef); err != nil {
+			src.Status.MarkNoSecrets("SecretNotFound", "Credentials of endpoint %d: %v", i, err)
+			return fmt.Errorf("getting credentials of endpoint %d: %w", i, err)
+		}
+	}
+	if spec.Fabric != nil {
+		identity := []struct {
+			name  string
+			value *sourcesv1alpha1.SecretValueFromSource
+		}{
+			{"MSP ID", &spec.Fabric.Identity.MSPID},
+			{"certificate", &spec.Fabric.Identity.Certificate},
+			{"private key", &spec.Fabric.Identity.PrivateKey},
+			{"TLS root certificate", spec.Fabric.TLSRootCertificate},
+		}
+		for _, s := range identity {
+			if s.value == nil {
+				continue
+			}
+			if _, err := common.SecretFrom(secrets, s.value.SecretKeyRef); err != nil {
+				src.Status.MarkNoSecrets("SecretNotFound", "Fabric identity %s: %v", s.name, err)
+				return fmt.Errorf("getting fabric identity %s: %w", s.name, err)
+			}
+		}
+	}
+	src.Status.MarkSecrets()
+	return nil
+}
+
+// reconcileCheckpoint makes sure the ConfigMap the receive adapter saves its
+// checkpoint in exists and is owned by the source, and reports the saved
+// checkpoint, along with the progress and the connection report saved with
+// it, in the source status.
+func (r *Reconciler) reconcileCheckpoint(ctx context.Context, src *sourcesv1alpha1.BlockchainSource) error {
+	expected := resources.MakeCheckpointConfigMap(src)
+
+	cm, err := r.configMapLister.ConfigMaps(src.Namespace).Get(expected.Name)
+	if apierrors.IsNotFound(err) {
+		cm, err = r.kubeClientSet.CoreV1().ConfigMaps(src.Namespace).Create(ctx, expected, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("error creating checkpoint ConfigMap: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("error getting checkpoint ConfigMap: %w", err)
+	} else if !metav1.IsControlledBy(cm, src) {
+		return fmt.Errorf("configmap %q is not owned by BlockchainSource %q", cm.Name, src.Name)
+	}
+
+	if cp, err := checkpoint.FromConfigMap(cm); err != nil {
+		// A corrupted checkpoint is ignored by the receive adapter as well.
+		logging.FromContext(ctx).Warnw("Unable to read the checkpoint", zap.Error(err))
+	} else {
+		src.Status.Checkpoint = checkpointStatus(cp)
+		src.Status.Ingestion = ingestionStatus(cp)
+		markCaughtUp(src, cp)
+	}
+
+	conn, err := checkpoint.ConnectionFromConfigMap(cm)
+	if err != nil {
+		logging.FromContext(ctx).Warnw("Unable to read the connection report", zap.Error(err))
+		conn = nil
+	}
+	markConnection(src, conn)
+	return nil
+}
+
+// markConnection sets the conditions about the RPC endpoints from the last
+// connection report of the receive adapter.
+func markConnection(src *sourcesv1alpha1.BlockchainSource, c *checkpoint.Connection) {
+	if c == nil {
+		src.Status.MarkEndpointReachabilityUnknown(reasonAdapterNotReported, "The receive adapter has not reached an endpoint yet.")
+		src.Status.MarkChainIDVerificationUnknown(reasonAdapterNotReported, "The receive adapter has not read the chain ID yet.")
+		return
+	}
+
+	switch c.Reason {
+	case "", checkpoint.ReasonChainIDMismatch:
+		// Reading the chain ID of a node takes reaching it.
+		src.Status.MarkEndpointReachable()
+	default:
+		src.Status.MarkEndpointUnreachable(c.Reason, "%s", c.Message)
+	}
+
+	switch {
+	case c.Reason == checkpoint.ReasonChainIDMismatch:
+		src.Status.MarkChainIDMismatch(c.Reason, "%s", c.Message)
+	case c.ChainID == "":
+		src.Status.MarkChainIDVerificationUnknown(c.Reason, "The chain ID could not be read: %s", c.Message)
+	case src.Spec.ChainID != "" && c.ChainID != src.Spec.ChainID:
+		src.Status.MarkChainIDMismatch(checkpoint.ReasonChainIDMismatch, "The endpoints serve chain %s instead of %s.", c.ChainID, src.Spec.ChainID)
+	default:
+		src.Status.MarkChainIDVerified()
+	}
+}
+
+// markCaughtUp sets whether the receive adapter emitted the events of every
+// block final enough, as of its last checkpoint.
+func markCaughtUp(src *sourcesv1alpha1.BlockchainSource, cp *checkpoint.Checkpoint) {
+	if cp == nil || cp.Progress == nil {
+		src.Status.MarkCaughtUpUnknown(reasonAdapterNotReported, "The receive adapter has not reported its progress yet.")
+		return
+	}
+	delivered := cp.BlockNumber
+	if cp.LogIndex != nil && delivered > 0 {
+		// The logs of the block are partly delivered.
+		delivered--
+	}
+	if delivered >= cp.Progress.Final {
+		src.Status.MarkCaughtUp()
+		return
+	}
+	src.Status.MarkNotCaughtUp("Behind", "The receive adapter is %d blocks behind the newest block final enough to be emitted.",
+		cp.Progress.Final-delivered)
+}
+
+func checkpointStatus(cp *checkpoint.Checkpoint) *sourcesv1alpha1.Checkpoint {
+	if cp == nil {
+		return nil
+	}
+	status := &sourcesv1alpha1.Checkpoint{
+		BlockNumber: int64(cp.BlockNumber),
+		BlockHash:   cp.BlockHash,
+	}
+	if cp.LogIndex != nil {
+		logIndex := int64(*cp.LogIndex)
+		status.LogIndex = &logIndex
+	}
+	if cp.Epoch != nil {
+		epoch := int64(*cp.Epoch)
+		status.Epoch = &epoch
+	}
+	if !cp.Time.IsZero() {
+		t := metav1.NewTime(cp.Time)
+		status.LastUpdateTime = &t
+	}
+	return status
+}
+
+func ingestionStatus(cp *checkpoint.Checkpoint) *sourcesv1alpha1.IngestionStatus {
+	if cp == nil || cp.Progress == nil {
+		return nil
+	}
+	p := cp.Progress
+	status := &sourcesv1alpha1.IngestionStatus{
+		ObservedChainID:  p.ChainID,
+		HeadBlock:        int64(p.Head),
+		LastEmittedBlock: int64(cp.BlockNumber),
+		ActiveEndpoint:   p.Endpoint,
+	}
+	if p.Head > cp.BlockNumber {
+		status.LagBlocks = int64(p.Head - cp.BlockNumber)
+	}
+	if p.BlockTime != nil && !cp.Time.IsZero() {
+		lag := int64(cp.Time.Sub(*p.BlockTime) / time.Second)
+		if lag < 0 {
+			// Clocks of the node and of the receive adapter may differ.
+			lag = 0
+		}
+		status.LagSeconds = &lag
+	}
+	if len(p.EmittedEvents) > 0 {
+		status.EmittedEvents = make(map[string]int64, len(p.EmittedEvents))
+		for t, n := range p.EmittedEvents {
+			status.EmittedEvents[t] = int64(n)
+		}
+	}
+	return status
+}
+
+// createReceiveAdapter makes sure the receive adapter Deployment runs with
+// the given spec, which completes the one of the source with its network.
+func (r *Reconciler) createReceiveAdapter(ctx context.Context, src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec, sinkURI string) (*appsv1.Deployment, error) {
+	adapterSource := src.DeepCopy()
+	adapterSource.Spec = *spec
+	adapterArgs := resources.ReceiveAdapterArgs{
+		Image:   r.receiveAdapterImage,
+		Source:  adapterSource,
+		Labels:  resources.Labels(src.Name),
+		SinkURI: sinkURI,
+		Configs: r.configs,
+	}
+	expected, err := resources.MakeReceiveAdapter(&adapterArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	ra, err := r.deploymentLister.Deployments(src.Namespace).Get(expected.Name)
+	if apierrors.IsNotFound(err) {
+		ra, err = r.kubeClientSet.AppsV1().Deployments(src.Namespace).Create(ctx, expected, metav1.CreateOptions{})
+		msg := "Deployment created"
+		if err != nil {
+			msg = fmt.Sprint("Deployment created, error:", err)
+		}
+		controller.GetEventRecorder(ctx).Eventf(src, corev1.EventTypeNormal, blockchainSourceDeploymentCreated, "%s", msg)
+		return ra, err
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting receive adapter: %w", err)
+	} else if !metav1.IsControlledBy(ra, src) {
+		return nil, fmt.Errorf("deployment %q is not owned by BlockchainSource %q", ra.Name, src.Name)
+	} else if podSpecChanged(ra.Spec.Template.Spec, expected.Spec.Template.Spec) {
+		ra = ra.DeepCopy()
+		ra.Spec.Template.Spec = expected.Spec.Template.Spec
+		if ra, err = r.kubeClientSet.AppsV1().Deployments(src.Namespace).Update(ctx, ra, metav1.UpdateOptions{}); err != nil {
+			return ra, err
+		}
+		controller.GetEventRecorder(ctx).Eventf(src, corev1.EventTypeNormal, blockchainSourceDeploymentUpdated, "Deployment %q updated", ra.Name)
+		return ra, nil
+	} else {
+		logging.FromContext(ctx).Debugw("Reusing existing receive adapter", zap.Any("receiveAdapter", ra))
+	}
+	return ra, nil
+}
+
+func podSpecChanged(oldPodSpec corev1.PodSpec, newPodSpec corev1.PodSpec) bool {
+	if !equality.Semantic.DeepDerivative(newPodSpec, oldPodSpec) {
+		return true
+	}
+	if len(oldPodSpec.Containers) != len(newPodSpec.Containers) {
+		return true
+	}
+	for i := range newPodSpec.Containers {
+		// Removed env vars are not detected by DeepDerivative.
+		if !equality.Semantic.DeepEqual(newPodSpec.Containers[i].Env, oldPodSpec.Containers[i].Env) {
+			return true
+		}
+	}
+	return false
+}
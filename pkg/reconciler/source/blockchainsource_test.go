@@ -0,0 +1,533 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	fakeeventingclient "knative.dev/eventing/pkg/client/injection/client/fake"
+	eventtypeinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype/fake"
+	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/client/injection/ducks/duck/v1/addressable"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	deploymentinformer "knative.dev/pkg/client/injection/kube/informers/apps/v1/deployment/fake"
+	configmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret/fake"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/resolver"
+	"knative.dev/pkg/tracker"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/checkpoint"
+	blockchainnetworkinformer "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainnetwork"
+	"knative.dev/eventing-blockchain/pkg/reconciler/source/resources"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+const (
+	testNS     = "testnamespace"
+	sourceName = "test-blockchain-source"
+	sourceUID  = "1234"
+	sinkURI    = "http://sink.example.com"
+)
+
+func newTestSource() *sourcesv1alpha1.BlockchainSource {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sourceName,
+			Namespace: testNS,
+			UID:       sourceUID,
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Family:  sourcesv1alpha1.ChainFamilyEVM,
+			ChainID: "1",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{
+				URL: "https://node.example.com",
+				Credentials: &sourcesv1alpha1.SecretValueFromSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "node-secret"},
+						Key:                  "authorization",
+					},
+				},
+			}},
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{URI: apis.HTTP("sink.example.com")},
+			},
+		},
+	}
+	src.Status.InitializeConditions()
+	return src
+}
+
+func newTestSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-secret",
+			Namespace: testNS,
+		},
+		Data: map[string][]byte{"authorization": []byte("Bearer token")},
+	}
+}
+
+func newTestReconciler(t *testing.T, objs ...*corev1.ConfigMap) (context.Context, *Reconciler) {
+	ctx, _ := SetupFakeContext(t)
+	ctx = addressable.WithDuck(ctx)
+	ctx = controller.WithEventRecorder(ctx, record.NewFakeRecorder(10))
+
+	configMaps := configmapinformer.Get(ctx)
+	for _, cm := range objs {
+		if err := configMaps.Informer().GetIndexer().Add(cm); err != nil {
+			t.Fatalf("Add() = %v", err)
+		}
+	}
+
+	return ctx, &Reconciler{
+		kubeClientSet:       fakekubeclient.Get(ctx),
+		deploymentLister:    deploymentinformer.Get(ctx).Lister(),
+		configMapLister:     configMaps.Lister(),
+		secretLister:        secretinformer.Get(ctx).Lister(),
+		networkLister:       blockchainnetworkinformer.Get(ctx).Lister(),
+		eventingClientSet:   fakeeventingclient.Get(ctx),
+		eventTypeLister:     eventtypeinformer.Get(ctx).Lister(),
+		tracker:             tracker.New(func(types.NamespacedName) {}, 0),
+		receiveAdapterImage: "test-image",
+		sinkResolver:        resolver.NewURIResolverFromTracker(ctx, tracker.New(func(types.NamespacedName) {}, 0)),
+		configs:             &reconcilersource.EmptyVarsGenerator{},
+	}
+}
+
+// addSecret adds a Secret to both the client and the lister.
+func addSecret(ctx context.Context, t *testing.T, secret *corev1.Secret) {
+	t.Helper()
+	if _, err := fakekubeclient.Get(ctx).CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := secretinformer.Get(ctx).Informer().GetIndexer().Add(secret); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+}
+
+// addDeployment adds a Deployment to both the client and the lister.
+func addDeployment(ctx context.Context, t *testing.T, d *appsv1.Deployment) {
+	t.Helper()
+	if _, err := fakekubeclient.Get(ctx).AppsV1().Deployments(d.Namespace).Create(ctx, d, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := deploymentinformer.Get(ctx).Informer().GetIndexer().Add(d); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+}
+
+func TestReconcileKind(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	kube := fakekubeclient.Get(ctx)
+	addSecret(ctx, t, newTestSecret())
+
+	src := newTestSource()
+	if err := r.ReconcileKind(ctx, src); err != nil {
+		t.Fatalf("ReconcileKind() = %v", err)
+	}
+
+	if got := src.Status.SinkURI.String(); got != sinkURI {
+		t.Errorf("SinkURI = %s, want %s", got, sinkURI)
+	}
+	for _, c := range []apis.ConditionType{
+		sourcesv1alpha1.BlockchainSourceConditionNetworkReady,
+		sourcesv1alpha1.BlockchainSourceConditionSecretsProvided,
+		sourcesv1alpha1.BlockchainSourceConditionSinkProvided,
+	} {
+		if !src.Status.GetCondition(c).IsTrue() {
+			t.Errorf("condition %s = %v, want True", c, src.Status.GetCondition(c))
+		}
+	}
+	if cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionAdapterDeployed); !cond.IsUnknown() {
+		t.Errorf("condition AdapterDeployed = %v, want Unknown until the Deployment is available", cond)
+	}
+	for _, c := range []apis.ConditionType{
+		sourcesv1alpha1.BlockchainSourceConditionEndpointReachable,
+		sourcesv1alpha1.BlockchainSourceConditionChainIDVerified,
+		sourcesv1alpha1.BlockchainSourceConditionCaughtUp,
+	} {
+		if cond := src.Status.GetCondition(c); !cond.IsUnknown() || cond.Reason != reasonAdapterNotReported {
+			t.Errorf("condition %s = %v, want Unknown until the receive adapter reports", c, cond)
+		}
+	}
+
+	ra, err := kube.AppsV1().Deployments(testNS).Get(ctx, resources.DeploymentName(src), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("receive adapter not created: %v", err)
+	}
+	if !metav1.IsControlledBy(ra, src) {
+		t.Error("receive adapter not controlled by the source")
+	}
+	if got := ra.Spec.Template.Spec.Containers[0].Image; got != "test-image" {
+		t.Errorf("receive adapter image = %s, want test-image", got)
+	}
+
+	cm, err := kube.CoreV1().ConfigMaps(testNS).Get(ctx, resources.CheckpointConfigMapName(src), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("checkpoint ConfigMap not created: %v", err)
+	}
+	if !metav1.IsControlledBy(cm, src) {
+		t.Error("checkpoint ConfigMap not controlled by the source")
+	}
+	if src.Status.Checkpoint != nil {
+		t.Errorf("Checkpoint = %v, want nil", src.Status.Checkpoint)
+	}
+}
+
+func TestReconcileKindMissingSecret(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+
+	src := newTestSource()
+	if err := r.ReconcileKind(ctx, src); err == nil {
+		t.Fatal("ReconcileKind() = nil, want an error")
+	}
+
+	cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionSecretsProvided)
+	if !cond.IsFalse() || cond.Reason != "SecretNotFound" {
+		t.Errorf("condition SecretsProvided = %v, want False with reason SecretNotFound", cond)
+	}
+	if _, err := fakekubeclient.Get(ctx).AppsV1().Deployments(testNS).Get(ctx, resources.DeploymentName(src), metav1.GetOptions{}); err == nil {
+		t.Error("receive adapter created despite the missing secret")
+	}
+}
+
+func TestReconcileKindMissingFabricIdentity(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "org1-user1", Namespace: testNS},
+		Data: map[string][]byte{
+			"mspid":    []byte("Org1MSP"),
+			"cert.pem": []byte("cert"),
+		},
+	}
+	addSecret(ctx, t, secret)
+
+	ref := func(key string) sourcesv1alpha1.SecretValueFromSource {
+		return sourcesv1alpha1.SecretValueFromSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "org1-user1"},
+				Key:                  key,
+			},
+		}
+	}
+	src := newTestSource()
+	src.Spec.Family = sourcesv1alpha1.ChainFamilyFabric
+	src.Spec.ChainID = "mychannel"
+	src.Spec.Endpoints = []sourcesv1alpha1.RPCEndpoint{{URL: "grpcs://peer0:7051"}}
+	src.Spec.Fabric = &sourcesv1alpha1.FabricOptions{
+		Identity: sourcesv1alpha1.FabricIdentity{
+			MSPID:       ref("mspid"),
+			Certificate: ref("cert.pem"),
+			PrivateKey:  ref("key.pem"),
+		},
+	}
+	if err := r.ReconcileKind(ctx, src); err == nil {
+		t.Fatal("ReconcileKind() = nil, want an error")
+	}
+
+	cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionSecretsProvided)
+	if !cond.IsFalse() || cond.Reason != "SecretNotFound" {
+		t.Errorf("condition SecretsProvided = %v, want False with reason SecretNotFound", cond)
+	}
+}
+
+func TestReconcileKindUpdatesReceiveAdapter(t *testing.T) {
+	src := newTestSource()
+	cp := &checkpoint.Checkpoint{BlockNumber: 42, BlockHash: "0x2a", Progress: &checkpoint.Progress{Head: 42, Final: 42}}
+	cm := resources.MakeCheckpointConfigMap(src)
+	data, err := json.Marshal(cp)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	conn, err := json.Marshal(&checkpoint.Connection{ChainID: "1"})
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	cm.Data = map[string]string{
+		checkpoint.ConfigMapKey:           string(data),
+		checkpoint.ConnectionConfigMapKey: string(conn),
+	}
+
+	ctx, r := newTestReconciler(t, cm)
+	kube := fakekubeclient.Get(ctx)
+	addSecret(ctx, t, newTestSecret())
+	existing := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       testNS,
+			Name:            resources.DeploymentName(src),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(src)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "receive-adapter", Image: "old-image"}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentAvailable,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+	addDeployment(ctx, t, existing)
+
+	if err := r.ReconcileKind(ctx, src); err != nil {
+		t.Fatalf("ReconcileKind() = %v", err)
+	}
+	if !src.Status.IsReady() {
+		t.Errorf("source not ready: %v", src.Status.Conditions)
+	}
+	if cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionCaughtUp); !cond.IsTrue() {
+		t.Errorf("condition CaughtUp = %v, want True", cond)
+	}
+
+	ra, err := kube.AppsV1().Deployments(testNS).Get(ctx, existing.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got := ra.Spec.Template.Spec.Containers[0].Image; got != "test-image" {
+		t.Errorf("receive adapter image = %s, want test-image", got)
+	}
+
+	want := &sourcesv1alpha1.Checkpoint{BlockNumber: int64(cp.BlockNumber), BlockHash: cp.BlockHash}
+	if diff := cmp.Diff(want, src.Status.Checkpoint); diff != "" {
+		t.Errorf("unexpected checkpoint (-want, +got) = %v", diff)
+	}
+}
+
+func TestReconcileKindNotOwnedReceiveAdapter(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	addSecret(ctx, t, newTestSecret())
+
+	src := newTestSource()
+	existing := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      resources.DeploymentName(src),
+		},
+	}
+	addDeployment(ctx, t, existing)
+
+	if err := r.ReconcileKind(ctx, src); err == nil {
+		t.Fatal("ReconcileKind() = nil, want an error")
+	}
+	cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionAdapterDeployed)
+	if !cond.IsFalse() {
+		t.Errorf("condition AdapterDeployed = %v, want False", cond)
+	}
+}
+
+func TestMarkConnection(t *testing.T) {
+	for name, tc := range map[string]struct {
+		conn      *checkpoint.Connection
+		reachable corev1.ConditionStatus
+		verified  corev1.ConditionStatus
+		reason    string
+	}{
+		"connected": {
+			conn:      &checkpoint.Connection{ChainID: "1"},
+			reachable: corev1.ConditionTrue,
+			verified:  corev1.ConditionTrue,
+		},
+		"mismatch reported by the adapter": {
+			conn: &checkpoint.Connection{
+				ChainID: "5",
+				Reason:  checkpoint.ReasonChainIDMismatch,
+				Message: "node serves chain 5 instead of 1",
+			},
+			reachable: corev1.ConditionTrue,
+			verified:  corev1.ConditionFalse,
+			reason:    checkpoint.ReasonChainIDMismatch,
+		},
+		"chain ID changed in the spec": {
+			conn:      &checkpoint.Connection{ChainID: "5"},
+			reachable: corev1.ConditionTrue,
+			verified:  corev1.ConditionFalse,
+			reason:    checkpoint.ReasonChainIDMismatch,
+		},
+		"unauthorized": {
+			conn: &checkpoint.Connection{
+				Reason:  checkpoint.ReasonUnauthorized,
+				Message: "eth_chainId request failed with status 401: invalid API key",
+			},
+			reachable: corev1.ConditionFalse,
+			verified:  corev1.ConditionUnknown,
+			reason:    checkpoint.ReasonUnauthorized,
+		},
+		"unreachable after verifying the chain": {
+			conn: &checkpoint.Connection{
+				ChainID: "1",
+				Reason:  checkpoint.ReasonUnreachable,
+			},
+			reachable: corev1.ConditionFalse,
+			verified:  corev1.ConditionTrue,
+			reason:    checkpoint.ReasonUnreachable,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := newTestSource()
+			markConnection(src, tc.conn)
+
+			reachable := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionEndpointReachable)
+			verified := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionChainIDVerified)
+			if reachable.Status != tc.reachable {
+				t.Errorf("condition EndpointReachable = %v, want %s", reachable, tc.reachable)
+			}
+			if verified.Status != tc.verified {
+				t.Errorf("condition ChainIDVerified = %v, want %s", verified, tc.verified)
+			}
+			if tc.reason == "" {
+				return
+			}
+			if got := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionReady).Reason; got != tc.reason {
+				t.Errorf("Ready reason = %q, want %q", got, tc.reason)
+			}
+		})
+	}
+}
+
+func TestMarkCaughtUp(t *testing.T) {
+	index := uint64(3)
+	for name, tc := range map[string]struct {
+		cp   *checkpoint.Checkpoint
+		want corev1.ConditionStatus
+	}{
+		"no progress": {
+			cp:   &checkpoint.Checkpoint{BlockNumber: 42},
+			want: corev1.ConditionUnknown,
+		},
+		"caught up": {
+			cp:   &checkpoint.Checkpoint{BlockNumber: 42, Progress: &checkpoint.Progress{Head: 54, Final: 42}},
+			want: corev1.ConditionTrue,
+		},
+		"backfilling": {
+			cp:   &checkpoint.Checkpoint{BlockNumber: 30, Progress: &checkpoint.Progress{Head: 54, Final: 42}},
+			want: corev1.ConditionFalse,
+		},
+		"logs of the final block partly delivered": {
+			cp:   &checkpoint.Checkpoint{BlockNumber: 42, LogIndex: &index, Progress: &checkpoint.Progress{Head: 54, Final: 42}},
+			want: corev1.ConditionFalse,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := newTestSource()
+			markCaughtUp(src, tc.cp)
+			if got := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionCaughtUp); got.Status != tc.want {
+				t.Errorf("condition CaughtUp = %v, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckpointStatus(t *testing.T) {
+	saved := time.Unix(1600000100, 0)
+	index, epoch := uint64(3), uint64(250000)
+	statusIndex, statusEpoch := int64(3), int64(250000)
+	updated := metav1.NewTime(saved)
+	for name, tc := range map[string]struct {
+		cp   *checkpoint.Checkpoint
+		want *sourcesv1alpha1.Checkpoint
+	}{
+		"no checkpoint": {},
+		"block": {
+			cp:   &checkpoint.Checkpoint{BlockNumber: 42, BlockHash: "0xaa", LogIndex: &index, Time: saved},
+			want: &sourcesv1alpha1.Checkpoint{BlockNumber: 42, BlockHash: "0xaa", LogIndex: &statusIndex, LastUpdateTime: &updated},
+		},
+		"slot and epoch": {
+			cp:   &checkpoint.Checkpoint{BlockNumber: 8000031, Epoch: &epoch},
+			want: &sourcesv1alpha1.Checkpoint{BlockNumber: 8000031, Epoch: &statusEpoch},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, checkpointStatus(tc.cp)); diff != "" {
+				t.Errorf("unexpected checkpoint status (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestIngestionStatus(t *testing.T) {
+	saved := time.Unix(1600000100, 0)
+	produced := time.Unix(1600000076, 0)
+	lag := int64(24)
+	for name, tc := range map[string]struct {
+		cp   *checkpoint.Checkpoint
+		want *sourcesv1alpha1.IngestionStatus
+	}{
+		"no checkpoint": {},
+		"no progress": {
+			cp: &checkpoint.Checkpoint{BlockNumber: 42, Time: saved},
+		},
+		"behind the head": {
+			cp: &checkpoint.Checkpoint{
+				BlockNumber: 42,
+				Time:        saved,
+				Progress: &checkpoint.Progress{
+					ChainID:       "1",
+					Head:          54,
+					BlockTime:     &produced,
+					Endpoint:      "https://node.example.com",
+					EmittedEvents: map[string]uint64{"dev.knative.source.blockchain.evm.block": 40},
+				},
+			},
+			want: &sourcesv1alpha1.IngestionStatus{
+				ObservedChainID:  "1",
+				HeadBlock:        54,
+				LastEmittedBlock: 42,
+				LagBlocks:        12,
+				LagSeconds:       &lag,
+				ActiveEndpoint:   "https://node.example.com",
+				EmittedEvents:    map[string]int64{"dev.knative.source.blockchain.evm.block": 40},
+			},
+		},
+		"unknown block time": {
+			cp: &checkpoint.Checkpoint{
+				BlockNumber: 42,
+				Time:        saved,
+				Progress:    &checkpoint.Progress{Head: 42},
+			},
+			want: &sourcesv1alpha1.IngestionStatus{
+				HeadBlock:        42,
+				LastEmittedBlock: 42,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ingestionStatus(tc.cp)); diff != "" {
+				t.Errorf("unexpected ingestion status (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
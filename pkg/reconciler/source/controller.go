@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	"github.com/kelseyhightower/envconfig"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/resolver"
+
+	eventingclient "knative.dev/eventing/pkg/client/injection/client"
+	eventtypeinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype"
+	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	deploymentinformer "knative.dev/pkg/client/injection/kube/informers/apps/v1/deployment"
+	configmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	blockchainnetworkinformer "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainnetwork"
+	blockchainsourceinformer "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainsource"
+	blockchainsourcereconciler "knative.dev/eventing-blockchain/pkg/client/injection/reconciler/sources/v1alpha1/blockchainsource"
+)
+
+// envConfig will be used to extract the required environment variables using
+// github.com/kelseyhightower/envconfig. If this configuration cannot be extracted, then
+// NewController will panic.
+type envConfig struct {
+	Image string `envconfig:"BLOCKCHAIN_RA_IMAGE" required:"true"`
+}
+
+// NewController initializes the controller and is called by the generated code
+// Registers event handlers to enqueue events
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+
+	deploymentInformer := deploymentinformer.Get(ctx)
+	configMapInformer := configmapinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
+	blockchainSourceInformer := blockchainsourceinformer.Get(ctx)
+	blockchainNetworkInformer := blockchainnetworkinformer.Get(ctx)
+	eventTypeInformer := eventtypeinformer.Get(ctx)
+
+	r := &Reconciler{
+		kubeClientSet:     kubeclient.Get(ctx),
+		deploymentLister:  deploymentInformer.Lister(),
+		configMapLister:   configMapInformer.Lister(),
+		secretLister:      secretInformer.Lister(),
+		networkLister:     blockchainNetworkInformer.Lister(),
+		eventingClientSet: eventingclient.Get(ctx),
+		eventTypeLister:   eventTypeInformer.Lister(),
+		configs:           reconcilersource.WatchConfigurations(ctx, component, cmw),
+	}
+
+	env := &envConfig{}
+	if err := envconfig.Process("", env); err != nil {
+		logging.FromContext(ctx).Panicf("unable to process BlockchainSource's required environment variables: %v", err)
+	}
+	r.receiveAdapterImage = env.Image
+
+	impl := blockchainsourcereconciler.NewImpl(ctx, r)
+
+	r.sinkResolver = resolver.NewURIResolverFromTracker(ctx, impl.Tracker)
+	r.tracker = impl.Tracker
+
+	blockchainSourceInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	deploymentInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&sourcesv1alpha1.BlockchainSource{}),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	// The receive adapter saves its checkpoint in a ConfigMap owned by the
+	// source, which is reported in the source status.
+	configMapInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&sourcesv1alpha1.BlockchainSource{}),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	// The credentials of the network of a source are copied to a Secret
+	// owned by the source, which is recreated should it be deleted.
+	secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&sourcesv1alpha1.BlockchainSource{}),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	// The EventTypes of a source are recreated should they be deleted.
+	eventTypeInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&sourcesv1alpha1.BlockchainSource{}),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	// Sources share the endpoints and the health of the network they
+	// reference.
+	blockchainNetworkInformer.Informer().AddEventHandler(controller.HandleAll(
+		controller.EnsureTypeMeta(
+			r.tracker.OnChanged,
+			sourcesv1alpha1.SchemeGroupVersion.WithKind("BlockchainNetwork"),
+		),
+	))
+
+	return impl
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/pkg/logging"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/evm"
+	"knative.dev/eventing-blockchain/pkg/reconciler/source/resources"
+)
+
+// reconcileEventTypes makes sure there is an EventType for every type of
+// event the receive adapter emits when running with the given spec, so that
+// consumers can discover them, and deletes the EventTypes of the events it
+// no longer emits.
+func (r *Reconciler) reconcileEventTypes(ctx context.Context, src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec) error {
+	chainID := spec.ChainID
+	if chainID == "" && src.Status.Ingestion != nil {
+		chainID = src.Status.Ingestion.ObservedChainID
+	}
+
+	expected := make(map[string]*v1beta1.EventType)
+	for _, args := range resources.EventTypes(src, spec, chainID, r.contractABI(ctx, src, spec)) {
+		et := resources.MakeEventType(&args)
+		expected[et.Name] = et
+	}
+
+	current, err := r.eventTypeLister.EventTypes(src.Namespace).List(labels.SelectorFromSet(resources.Labels(src.Name)))
+	if err != nil {
+		return fmt.Errorf("listing event types: %w", err)
+	}
+
+	eventTypes := r.eventingClientSet.EventingV1beta1().EventTypes(src.Namespace)
+	for _, et := range current {
+		if !metav1.IsControlledBy(et, src) {
+			continue
+		}
+		want, ok := expected[et.Name]
+		if !ok {
+			if err := eventTypes.Delete(ctx, et.Name, metav1.DeleteOptions{}); err != nil {
+				return fmt.Errorf("deleting event type %q: %w", et.Name, err)
+			}
+			continue
+		}
+		delete(expected, et.Name)
+		if equality.Semantic.DeepEqual(want.Spec, et.Spec) {
+			continue
+		}
+		et = et.DeepCopy()
+		et.Spec = want.Spec
+		if _, err := eventTypes.Update(ctx, et, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating event type %q: %w", et.Name, err)
+		}
+	}
+
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := eventTypes.Create(ctx, expected[name], metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("creating event type %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// contractABI returns the ABI the receive adapter decodes contract logs
+// with, or nil when there is none or it cannot be read.
+func (r *Reconciler) contractABI(ctx context.Context, src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec) *evm.ABI {
+	if spec.Contracts == nil || spec.Contracts.ABI == nil {
+		return nil
+	}
+
+	data := spec.Contracts.ABI.Inline
+	if ref := spec.Contracts.ABI.ConfigMapKeyRef; ref != nil {
+		cm, err := r.configMapLister.ConfigMaps(src.Namespace).Get(ref.Name)
+		if err != nil {
+			logging.FromContext(ctx).Warnw("Unable to get the ABI ConfigMap", zap.String("name", ref.Name), zap.Error(err))
+			return nil
+		}
+		data = cm.Data[ref.Key]
+	}
+
+	abi, err := evm.ParseABI([]byte(data))
+	if err != nil {
+		logging.FromContext(ctx).Warnw("Unable to parse the ABI", zap.Error(err))
+		return nil
+	}
+	return abi
+}
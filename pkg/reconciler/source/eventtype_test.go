@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	fakeeventingclient "knative.dev/eventing/pkg/client/injection/client/fake"
+	eventtypeinformer "knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/eventtype/fake"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/reconciler/source/resources"
+)
+
+const testABI = `[{"type": "event", "name": "Transfer", "inputs": [
+  {"name": "from", "type": "address", "indexed": true},
+  {"name": "to", "type": "address", "indexed": true},
+  {"name": "value", "type": "uint256"}
+]}]`
+
+// listEventTypes returns the types and descriptions of the EventTypes of
+// the test namespace, sorted.
+func listEventTypes(ctx context.Context, t *testing.T) []string {
+	t.Helper()
+	list, err := fakeeventingclient.Get(ctx).EventingV1beta1().EventTypes(testNS).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	var got []string
+	for _, et := range list.Items {
+		got = append(got, et.Spec.Type+": "+et.Spec.Description)
+	}
+	sort.Strings(got)
+	return got
+}
+
+// addEventType adds an EventType to both the client and the lister.
+func addEventType(ctx context.Context, t *testing.T, et *v1beta1.EventType) {
+	t.Helper()
+	if _, err := fakeeventingclient.Get(ctx).EventingV1beta1().EventTypes(et.Namespace).Create(ctx, et, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := eventtypeinformer.Get(ctx).Informer().GetIndexer().Add(et); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+}
+
+func TestReconcileEventTypes(t *testing.T) {
+	abiConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "token-abi", Namespace: testNS},
+		Data:       map[string]string{"abi.json": testABI},
+	}
+	ctx, r := newTestReconciler(t, abiConfigMap)
+
+	src := newTestSource()
+	src.Spec.Contracts = &sourcesv1alpha1.ContractSubscription{
+		EventSignatures: []string{"Transfer"},
+		ABI: &sourcesv1alpha1.ContractABI{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "token-abi"},
+				Key:                  "abi.json",
+			},
+		},
+	}
+
+	// An EventType of a type no longer emitted, one whose schema changed
+	// and one the source does not own.
+	stale := resources.MakeEventType(&resources.EventTypeArgs{
+		Source:      src,
+		CeType:      sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindBlock),
+		CeSource:    "eip155:1",
+		Description: "New block of the chain.",
+	})
+	addEventType(ctx, t, stale)
+	reorgs := resources.EventTypes(src, &src.Spec, "1", nil)[1]
+	if reorgs.CeType != sourcesv1alpha1.BlockchainEventType(sourcesv1alpha1.ChainFamilyEVM, sourcesv1alpha1.BlockchainEventKindReorg) {
+		t.Fatalf("CeType = %s, want the reorg type", reorgs.CeType)
+	}
+	changed := resources.MakeEventType(&reorgs)
+	changed.Spec.Schema = nil
+	addEventType(ctx, t, changed)
+	other := stale.DeepCopy()
+	other.Name = "other"
+	other.OwnerReferences = nil
+	addEventType(ctx, t, other)
+
+	if err := r.reconcileEventTypes(ctx, src, &src.Spec); err != nil {
+		t.Fatalf("reconcileEventTypes() = %v", err)
+	}
+
+	want := []string{
+		"dev.knative.source.blockchain.evm.block: New block of the chain.",
+		"dev.knative.source.blockchain.evm.log: Contract log of the Transfer(address indexed from, address indexed to, uint256 value) event, decoded with the ABI.",
+		"dev.knative.source.blockchain.evm.reorg: Reorg that orphaned blocks events were emitted for.",
+		"dev.knative.source.blockchain.evm.retracted: Retraction of an event emitted for a block orphaned by a reorg.",
+	}
+	if diff := cmp.Diff(want, listEventTypes(ctx, t)); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+
+	client := fakeeventingclient.Get(ctx).EventingV1beta1().EventTypes(testNS)
+	if _, err := client.Get(ctx, stale.Name, metav1.GetOptions{}); err == nil {
+		t.Error("stale EventType not deleted")
+	}
+	if _, err := client.Get(ctx, other.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("EventType not owned by the source deleted: %v", err)
+	}
+	updated, err := client.Get(ctx, changed.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if updated.Spec.Schema == nil {
+		t.Error("EventType schema not updated")
+	}
+}
+
+func TestReconcileEventTypesObservedChainID(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+
+	src := newTestSource()
+	src.Spec.ChainID = ""
+	src.Status.Ingestion = &sourcesv1alpha1.IngestionStatus{ObservedChainID: "5"}
+
+	if err := r.reconcileEventTypes(ctx, src, &src.Spec); err != nil {
+		t.Fatalf("reconcileEventTypes() = %v", err)
+	}
+
+	list, err := fakeeventingclient.Get(ctx).EventingV1beta1().EventTypes(testNS).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	if len(list.Items) != 3 {
+		t.Fatalf("got %d EventTypes, want 3", len(list.Items))
+	}
+	for _, et := range list.Items {
+		if got, want := et.Spec.Source.String(), "eip155:5"; got != want {
+			t.Errorf("Source = %s, want %s", got, want)
+		}
+	}
+}
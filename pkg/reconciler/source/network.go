@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
+	"knative.dev/pkg/tracker"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/common"
+	"knative.dev/eventing-blockchain/pkg/reconciler/source/resources"
+)
+
+func newWarningNetworkNotFound(name string) pkgreconciler.Event {
+	return pkgreconciler.NewEvent(corev1.EventTypeWarning, "NetworkNotFound", "BlockchainNetwork not found: %s", name)
+}
+
+func newWarningNetworkMismatch(format string, args ...interface{}) pkgreconciler.Event {
+	return pkgreconciler.NewEvent(corev1.EventTypeWarning, "NetworkMismatch", format, args...)
+}
+
+func newWarningNetworkNotAllowed(name, namespace string) pkgreconciler.Event {
+	return pkgreconciler.NewEvent(corev1.EventTypeWarning, "NetworkNotAllowed",
+		"BlockchainNetwork %s does not allow namespace %s to use its credentials", name, namespace)
+}
+
+// reconcileNetwork returns the spec the receive adapter of the source runs
+// with, completed with the chain, endpoints and defaults of the network the
+// source references, if any. The health of the network, probed once for all
+// the sources referencing it, is reported in the source status.
+func (r *Reconciler) reconcileNetwork(ctx context.Context, src *sourcesv1alpha1.BlockchainSource) (*sourcesv1alpha1.BlockchainSourceSpec, error) {
+	if src.Spec.Network == "" {
+		src.Status.MarkNoNetwork()
+		if err := r.deleteNetworkCredentials(ctx, src); err != nil {
+			return nil, err
+		}
+		return &src.Spec, nil
+	}
+
+	// Sources are reconciled again when the network changes, or shows up.
+	if err := r.tracker.TrackReference(tracker.Reference{
+		APIVersion: sourcesv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "BlockchainNetwork",
+		Name:       src.Spec.Network,
+	}, src); err != nil {
+		return nil, fmt.Errorf("tracking network %q: %w", src.Spec.Network, err)
+	}
+
+	network, err := r.networkLister.Get(src.Spec.Network)
+	if apierrors.IsNotFound(err) {
+		src.Status.MarkNetworkNotFound(src.Spec.Network)
+		return nil, newWarningNetworkNotFound(src.Spec.Network)
+	} else if err != nil {
+		return nil, fmt.Errorf("getting network %q: %w", src.Spec.Network, err)
+	}
+
+	switch {
+	case src.Spec.Family != "" && src.Spec.Family != network.Spec.Family:
+		src.Status.MarkNetworkMismatch("The source reads a %s chain, the BlockchainNetwork %q is a %s chain.",
+			src.Spec.Family, network.Name, network.Spec.Family)
+		return nil, newWarningNetworkMismatch("Family %s of the source does not match network %q", src.Spec.Family, network.Name)
+	case src.Spec.ChainID != "" && src.Spec.ChainID != network.Spec.ChainID:
+		src.Status.MarkNetworkMismatch("The source reads chain %s, the BlockchainNetwork %q is chain %s.",
+			src.Spec.ChainID, network.Name, network.Spec.ChainID)
+		return nil, newWarningNetworkMismatch("Chain ID %s of the source does not match network %q", src.Spec.ChainID, network.Name)
+	}
+
+	// Credentials are only copied to the namespaces the network allows.
+	if hasCredentials(network) && !allowsNamespace(network, src.Namespace) {
+		src.Status.MarkNetworkNotAllowed(network.Name, src.Namespace)
+		if err := r.deleteNetworkCredentials(ctx, src); err != nil {
+			return nil, err
+		}
+		return nil, newWarningNetworkNotAllowed(network.Name, src.Namespace)
+	}
+
+	spec := resources.NetworkSpec(src, network)
+	if len(spec.Endpoints) == 0 {
+		src.Status.MarkNetworkMismatch("The BlockchainNetwork %q has no endpoint usable in %s mode.", network.Name, src.Spec.Mode)
+		return nil, newWarningNetworkMismatch("Network %q has no endpoint usable in %s mode", network.Name, src.Spec.Mode)
+	}
+	src.Status.PropagateNetworkStatus(&network.Status)
+
+	if err := r.reconcileNetworkCredentials(ctx, src, network); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// reconcileNetworkCredentials copies the credentials of the endpoints of
+// the network, kept in the namespace of the controller, to a Secret of the
+// namespace of the source the receive adapter can read.
+func (r *Reconciler) reconcileNetworkCredentials(ctx context.Context, src *sourcesv1alpha1.BlockchainSource, network *sourcesv1alpha1.BlockchainNetwork) error {
+	data := make(map[string][]byte)
+	secrets := r.secretLister.Secrets(system.Namespace())
+	for i, e := range network.Spec.Endpoints {
+		if e.Credentials == nil {
+			continue
+		}
+		value, err := common.SecretFrom(secrets, e.Credentials.SecretKeyRef)
+		if err != nil {
+			src.Status.MarkNoSecrets("SecretNotFound", "Credentials of endpoint %d of network %q: %v", i, network.Name, err)
+			return fmt.Errorf("getting credentials of endpoint %d of network %q: %w", i, network.Name, err)
+		}
+		data[resources.NetworkCredentialsKey(i)] = []byte(value)
+	}
+	if len(data) == 0 {
+		return r.deleteNetworkCredentials(ctx, src)
+	}
+
+	expected := resources.MakeNetworkCredentialsSecret(src, data)
+	secret, err := r.secretLister.Secrets(src.Namespace).Get(expected.Name)
+	if apierrors.IsNotFound(err) {
+		if _, err := r.kubeClientSet.CoreV1().Secrets(src.Namespace).Create(ctx, expected, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating network credentials Secret: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error getting network credentials Secret: %w", err)
+	} else if !metav1.IsControlledBy(secret, src) {
+		return fmt.Errorf("secret %q is not owned by BlockchainSource %q", secret.Name, src.Name)
+	} else if !sameData(secret.Data, data) {
+		secret = secret.DeepCopy()
+		secret.Data = data
+		if _, err := r.kubeClientSet.CoreV1().Secrets(src.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error updating network credentials Secret: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteNetworkCredentials deletes the copy of the credentials of a network
+// the source no longer uses, if any.
+func (r *Reconciler) deleteNetworkCredentials(ctx context.Context, src *sourcesv1alpha1.BlockchainSource) error {
+	name := resources.NetworkCredentialsSecretName(src)
+	secret, err := r.secretLister.Secrets(src.Namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error getting network credentials Secret: %w", err)
+	} else if !metav1.IsControlledBy(secret, src) {
+		return nil
+	}
+	err = r.kubeClientSet.CoreV1().Secrets(src.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting network credentials Secret: %w", err)
+	}
+	return nil
+}
+
+// hasCredentials tells whether an endpoint of the network has credentials.
+func hasCredentials(network *sourcesv1alpha1.BlockchainNetwork) bool {
+	for _, e := range network.Spec.Endpoints {
+		if e.Credentials != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsNamespace tells whether the network allows the sources of a
+// namespace to use its credentials.
+func allowsNamespace(network *sourcesv1alpha1.BlockchainNetwork, namespace string) bool {
+	for _, ns := range network.Spec.AllowedNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func sameData(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !bytes.Equal(v, w) {
+			return false
+		}
+	}
+	return true
+}
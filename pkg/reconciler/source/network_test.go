@@ -0,0 +1,312 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret/fake"
+	"knative.dev/pkg/system"
+	_ "knative.dev/pkg/system/testing"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	blockchainnetworkinformer "knative.dev/eventing-blockchain/pkg/client/injection/informers/sources/v1alpha1/blockchainnetwork"
+	"knative.dev/eventing-blockchain/pkg/reconciler/source/resources"
+)
+
+func newTestNetwork() *sourcesv1alpha1.BlockchainNetwork {
+	network := &sourcesv1alpha1.BlockchainNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "mainnet"},
+		Spec: sourcesv1alpha1.BlockchainNetworkSpec{
+			Family:  sourcesv1alpha1.ChainFamilyEVM,
+			ChainID: "1",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{
+				URL: "wss://node.example.com",
+			}, {
+				URL: "https://node.example.com",
+				Credentials: &sourcesv1alpha1.SecretValueFromSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "provider"},
+						Key:                  "authorization",
+					},
+				},
+			}},
+			AllowedNamespaces: []string{testNS},
+		},
+	}
+	network.Status.InitializeConditions()
+	network.Status.MarkEndpointsReachable()
+	network.Status.MarkChainIDVerified()
+	return network
+}
+
+func newTestNetworkSource() *sourcesv1alpha1.BlockchainSource {
+	src := newTestSource()
+	src.Spec.Family = ""
+	src.Spec.ChainID = ""
+	src.Spec.Endpoints = nil
+	src.Spec.Network = "mainnet"
+	src.Spec.Mode = sourcesv1alpha1.IngestionModePolling
+	return src
+}
+
+func addNetwork(ctx context.Context, t *testing.T, network *sourcesv1alpha1.BlockchainNetwork) {
+	t.Helper()
+	if err := blockchainnetworkinformer.Get(ctx).Informer().GetIndexer().Add(network); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+}
+
+func TestReconcileKindWithNetwork(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	addNetwork(ctx, t, newTestNetwork())
+	kube := fakekubeclient.Get(ctx)
+	addSecret(ctx, t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider", Namespace: system.Namespace()},
+		Data:       map[string][]byte{"authorization": []byte("Bearer token")},
+	})
+
+	src := newTestNetworkSource()
+	if err := r.ReconcileKind(ctx, src); err != nil {
+		t.Fatalf("ReconcileKind() = %v", err)
+	}
+
+	if cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionNetworkReady); !cond.IsTrue() {
+		t.Errorf("condition NetworkReady = %v, want True", cond)
+	}
+	if cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionSecretsProvided); !cond.IsTrue() {
+		t.Errorf("condition SecretsProvided = %v, want True", cond)
+	}
+
+	secret, err := kube.CoreV1().Secrets(testNS).Get(ctx, resources.NetworkCredentialsSecretName(src), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("network credentials not copied: %v", err)
+	}
+	if !metav1.IsControlledBy(secret, src) {
+		t.Error("network credentials Secret not controlled by the source")
+	}
+	if got, want := string(secret.Data[resources.NetworkCredentialsKey(1)]), "Bearer token"; got != want {
+		t.Errorf("copied credentials = %q, want %q", got, want)
+	}
+
+	ra, err := kube.AppsV1().Deployments(testNS).Get(ctx, resources.DeploymentName(src), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("receive adapter not created: %v", err)
+	}
+	env := make(map[string]corev1.EnvVar)
+	for _, e := range ra.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	if got, want := env["BLOCKCHAIN_CHAIN_ID"].Value, "1"; got != want {
+		t.Errorf("BLOCKCHAIN_CHAIN_ID = %q, want %q", got, want)
+	}
+	if got, want := env["BLOCKCHAIN_ENDPOINTS"].Value, `[{"url":"https://node.example.com"}]`; got != want {
+		t.Errorf("BLOCKCHAIN_ENDPOINTS = %s, want %s", got, want)
+	}
+	if ref := env["BLOCKCHAIN_ENDPOINT_0_CREDENTIALS"].ValueFrom; ref == nil || ref.SecretKeyRef == nil ||
+		ref.SecretKeyRef.Name != resources.NetworkCredentialsSecretName(src) || ref.SecretKeyRef.Key != resources.NetworkCredentialsKey(1) {
+		t.Errorf("BLOCKCHAIN_ENDPOINT_0_CREDENTIALS = %v, want a reference to the copied credentials", ref)
+	}
+
+	// Rotated credentials are copied again.
+	secrets := secretinformer.Get(ctx).Informer().GetIndexer()
+	if err := secrets.Add(secret); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+	if err := secrets.Update(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider", Namespace: system.Namespace()},
+		Data:       map[string][]byte{"authorization": []byte("Bearer rotated")},
+	}); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	if err := r.reconcileNetworkCredentials(ctx, src, newTestNetwork()); err != nil {
+		t.Fatalf("reconcileNetworkCredentials() = %v", err)
+	}
+	secret, err = kube.CoreV1().Secrets(testNS).Get(ctx, resources.NetworkCredentialsSecretName(src), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got, want := string(secret.Data[resources.NetworkCredentialsKey(1)]), "Bearer rotated"; got != want {
+		t.Errorf("copied credentials = %q, want %q", got, want)
+	}
+}
+
+func TestReconcileKindNetworkErrors(t *testing.T) {
+	tests := map[string]struct {
+		network   *sourcesv1alpha1.BlockchainNetwork
+		source    func(*sourcesv1alpha1.BlockchainSource)
+		condition apis.ConditionType
+		reason    string
+	}{
+		"network not found": {
+			condition: sourcesv1alpha1.BlockchainSourceConditionNetworkReady,
+			reason:    "NetworkNotFound",
+		},
+		"chain ID mismatch": {
+			network: newTestNetwork(),
+			source: func(src *sourcesv1alpha1.BlockchainSource) {
+				src.Spec.ChainID = "11155111"
+			},
+			condition: sourcesv1alpha1.BlockchainSourceConditionNetworkReady,
+			reason:    "NetworkMismatch",
+		},
+		"family mismatch": {
+			network: newTestNetwork(),
+			source: func(src *sourcesv1alpha1.BlockchainSource) {
+				src.Spec.Family = sourcesv1alpha1.ChainFamilySolana
+			},
+			condition: sourcesv1alpha1.BlockchainSourceConditionNetworkReady,
+			reason:    "NetworkMismatch",
+		},
+		"no endpoint for the mode": {
+			network: func() *sourcesv1alpha1.BlockchainNetwork {
+				network := newTestNetwork()
+				network.Spec.Endpoints = network.Spec.Endpoints[:1]
+				return network
+			}(),
+			condition: sourcesv1alpha1.BlockchainSourceConditionNetworkReady,
+			reason:    "NetworkMismatch",
+		},
+		"namespace not allowed": {
+			network: func() *sourcesv1alpha1.BlockchainNetwork {
+				network := newTestNetwork()
+				network.Spec.AllowedNamespaces = []string{"other"}
+				return network
+			}(),
+			condition: sourcesv1alpha1.BlockchainSourceConditionNetworkReady,
+			reason:    "NetworkNotAllowed",
+		},
+		"missing network credentials": {
+			network:   newTestNetwork(),
+			condition: sourcesv1alpha1.BlockchainSourceConditionSecretsProvided,
+			reason:    "SecretNotFound",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, r := newTestReconciler(t)
+			if test.network != nil {
+				addNetwork(ctx, t, test.network)
+			}
+			src := newTestNetworkSource()
+			if test.source != nil {
+				test.source(src)
+			}
+
+			if err := r.ReconcileKind(ctx, src); err == nil {
+				t.Fatal("ReconcileKind() = nil, want an error")
+			}
+
+			if cond := src.Status.GetCondition(test.condition); !cond.IsFalse() || cond.Reason != test.reason {
+				t.Errorf("condition %s = %v, want False with reason %s", test.condition, cond, test.reason)
+			}
+			if _, err := fakekubeclient.Get(ctx).AppsV1().Deployments(testNS).Get(ctx, resources.DeploymentName(src), metav1.GetOptions{}); err == nil {
+				t.Error("receive adapter created despite the network error")
+			}
+		})
+	}
+}
+
+func TestReconcileKindPropagatesNetworkStatus(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	network := newTestNetwork()
+	network.Spec.Endpoints[1].Credentials = nil
+	network.Status.MarkEndpointsUnreachable("EndpointsUnreachable", "No endpoint answered.")
+	addNetwork(ctx, t, network)
+
+	src := newTestNetworkSource()
+	if err := r.ReconcileKind(ctx, src); err != nil {
+		t.Fatalf("ReconcileKind() = %v", err)
+	}
+
+	cond := src.Status.GetCondition(sourcesv1alpha1.BlockchainSourceConditionNetworkReady)
+	if !cond.IsFalse() || cond.Reason != "EndpointsUnreachable" || cond.Message != "No endpoint answered." {
+		t.Errorf("condition NetworkReady = %v, want the Ready condition of the network", cond)
+	}
+}
+
+func TestReconcileNetworkDeletesCredentials(t *testing.T) {
+	tests := map[string]struct {
+		network func() *sourcesv1alpha1.BlockchainNetwork
+		source  func(*sourcesv1alpha1.BlockchainSource)
+	}{
+		"network without credentials": {
+			network: func() *sourcesv1alpha1.BlockchainNetwork {
+				network := newTestNetwork()
+				network.Spec.Endpoints[1].Credentials = nil
+				return network
+			},
+		},
+		"namespace no longer allowed": {
+			network: func() *sourcesv1alpha1.BlockchainNetwork {
+				network := newTestNetwork()
+				network.Spec.AllowedNamespaces = nil
+				return network
+			},
+		},
+		"source without network": {
+			network: newTestNetwork,
+			source: func(src *sourcesv1alpha1.BlockchainSource) {
+				src.Spec.Network = ""
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, r := newTestReconciler(t)
+			addNetwork(ctx, t, test.network())
+			src := newTestNetworkSource()
+			if test.source != nil {
+				test.source(src)
+			}
+			addSecret(ctx, t, resources.MakeNetworkCredentialsSecret(src, map[string][]byte{
+				resources.NetworkCredentialsKey(1): []byte("Bearer token"),
+			}))
+
+			_, _ = r.reconcileNetwork(ctx, src)
+
+			_, err := fakekubeclient.Get(ctx).CoreV1().Secrets(testNS).Get(ctx, resources.NetworkCredentialsSecretName(src), metav1.GetOptions{})
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("Get() = %v, want the copied credentials deleted", err)
+			}
+		})
+	}
+}
+
+func TestReconcileNetworkKeepsSecretNotOwned(t *testing.T) {
+	ctx, r := newTestReconciler(t)
+	src := newTestSource()
+	addSecret(ctx, t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: resources.NetworkCredentialsSecretName(src), Namespace: testNS},
+	})
+
+	if _, err := r.reconcileNetwork(ctx, src); err != nil {
+		t.Fatalf("reconcileNetwork() = %v", err)
+	}
+	if _, err := fakekubeclient.Get(ctx).CoreV1().Secrets(testNS).Get(ctx, resources.NetworkCredentialsSecretName(src), metav1.GetOptions{}); err != nil {
+		t.Errorf("Get() = %v, want the Secret not owned by the source kept", err)
+	}
+}
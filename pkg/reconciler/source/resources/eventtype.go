@@ -0,0 +1,331 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"crypto/md5" //nolint:gosec // No strong cryptography needed.
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/evm"
+	"knative.dev/eventing-blockchain/pkg/tendermint"
+)
+
+// EventTypeArgs are the arguments needed to create an EventType of a
+// BlockchainSource.
+type EventTypeArgs struct {
+	Source      *sourcesv1alpha1.BlockchainSource
+	CeType      string
+	CeSource    string
+	CeSchema    string
+	Description string
+}
+
+// MakeEventType generates (but does not insert into K8s) an EventType owned
+// by the source, named after the hash of its attributes.
+func MakeEventType(args *EventTypeArgs) *v1beta1.EventType {
+	hash := md5.Sum([]byte(args.CeType + args.CeSource + args.CeSchema + args.Description)) //nolint:gosec // No strong cryptography needed.
+	et := &v1beta1.EventType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kmeta.ChildName(args.Source.Name+"-", fmt.Sprintf("%x", hash)),
+			Namespace: args.Source.Namespace,
+			Labels:    Labels(args.Source.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				*kmeta.NewControllerRef(args.Source),
+			},
+		},
+		Spec: v1beta1.EventTypeSpec{
+			Type:        args.CeType,
+			Description: args.Description,
+		},
+	}
+	if args.CeSource != "" {
+		et.Spec.Source, _ = apis.ParseURL(args.CeSource)
+	}
+	if args.CeSchema != "" {
+		et.Spec.Schema, _ = apis.ParseURL(args.CeSchema)
+	}
+	if ref := args.Source.Spec.Sink.Ref; ref != nil && ref.Kind == "Broker" {
+		et.Spec.Broker = ref.Name
+	}
+	return et
+}
+
+// EventTypes returns the arguments of the EventTypes of the events the
+// receive adapter of a source emits when running with the given spec. The
+// chain ID is the one of the chain the source reads, empty when not known
+// yet. The ABI decodes contract logs, when given.
+func EventTypes(src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec, chainID string, abi *evm.ABI) []EventTypeArgs {
+	switch spec.Family {
+	case "", sourcesv1alpha1.ChainFamilyEVM:
+		return evmEventTypes(src, spec, chainID, abi)
+	case sourcesv1alpha1.ChainFamilyBitcoin:
+		return bitcoinEventTypes(src, chainID)
+	case sourcesv1alpha1.ChainFamilyFabric:
+		return fabricEventTypes(src, spec, chainID)
+	case sourcesv1alpha1.ChainFamilyTendermint:
+		return tendermintEventTypes(src, spec, chainID)
+	case sourcesv1alpha1.ChainFamilySolana:
+		return solanaEventTypes(src, spec, chainID)
+	case sourcesv1alpha1.ChainFamilyBeacon:
+		return beaconEventTypes(src, spec, chainID)
+	default:
+		// There is no receive adapter for other families yet.
+		return nil
+	}
+}
+
+func evmEventTypes(src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec, chainID string, abi *evm.ABI) []EventTypeArgs {
+	var ets []EventTypeArgs
+	add := func(kind sourcesv1alpha1.BlockchainEventKind, ceSource, description string) {
+		ets = append(ets, eventTypeArgs(src, sourcesv1alpha1.ChainFamilyEVM, kind, ceSource, description))
+	}
+
+	chainSource := chainEventSource(sourcesv1alpha1.ChainFamilyEVM, chainID)
+
+	sources := []string{chainSource}
+	if spec.Contracts == nil {
+		add(sourcesv1alpha1.BlockchainEventKindBlock, chainSource, "New block of the chain.")
+	} else {
+		sources = contractSources(chainSource, spec.Contracts.Addresses)
+		events, raw := abiEvents(spec.Contracts, abi)
+		for _, ceSource := range sources {
+			for _, e := range events {
+				add(sourcesv1alpha1.BlockchainEventKindLog, ceSource, fmt.Sprintf("Contract log of the %s event, decoded with the ABI.", e.Declaration()))
+			}
+			if raw {
+				add(sourcesv1alpha1.BlockchainEventKindLog, ceSource, "Contract log of an event the ABI does not declare, with its raw topics and data.")
+			}
+		}
+	}
+
+	add(sourcesv1alpha1.BlockchainEventKindReorg, chainSource, "Reorg that orphaned blocks events were emitted for.")
+	for _, ceSource := range sources {
+		add(sourcesv1alpha1.BlockchainEventKindRetracted, ceSource, "Retraction of an event emitted for a block orphaned by a reorg.")
+	}
+	return ets
+}
+
+func bitcoinEventTypes(src *sourcesv1alpha1.BlockchainSource, chainID string) []EventTypeArgs {
+	chainSource := chainEventSource(sourcesv1alpha1.ChainFamilyBitcoin, chainID)
+	return []EventTypeArgs{
+		eventTypeArgs(src, sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindBlock, chainSource,
+			"New block of the chain, with the inputs and outputs of its transactions decoded."),
+		eventTypeArgs(src, sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindTransaction, chainSource,
+			"Transaction entering the mempool of the node, with its inputs and outputs decoded."),
+		eventTypeArgs(src, sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindReorg, chainSource,
+			"Reorg that orphaned blocks events were emitted for."),
+		eventTypeArgs(src, sourcesv1alpha1.ChainFamilyBitcoin, sourcesv1alpha1.BlockchainEventKindRetracted, chainSource,
+			"Retraction of an event emitted for a block orphaned by a reorg."),
+	}
+}
+
+func fabricEventTypes(src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec, chainID string) []EventTypeArgs {
+	chainSource := chainEventSource(sourcesv1alpha1.ChainFamilyFabric, chainID)
+	if spec.Fabric == nil || len(spec.Fabric.ChaincodeEvents) == 0 {
+		return []EventTypeArgs{
+			eventTypeArgs(src, sourcesv1alpha1.ChainFamilyFabric, sourcesv1alpha1.BlockchainEventKindTransaction, chainSource,
+				"Transaction committed to the channel, with its validation code."),
+		}
+	}
+
+	ets := make([]EventTypeArgs, 0, len(spec.Fabric.ChaincodeEvents))
+	for _, sub := range spec.Fabric.ChaincodeEvents {
+		ceSource := ""
+		if chainSource != "" {
+			ceSource = sourcesv1alpha1.BlockchainChaincodeEventSource(chainSource, sub.ChaincodeName)
+		}
+		description := fmt.Sprintf("Event set by the %s chaincode in a valid transaction.", sub.ChaincodeName)
+		if sub.EventName != "" {
+			description = fmt.Sprintf("Event matching %q set by the %s chaincode in a valid transaction.", sub.EventName, sub.ChaincodeName)
+		}
+		ets = append(ets, eventTypeArgs(src, sourcesv1alpha1.ChainFamilyFabric, sourcesv1alpha1.BlockchainEventKindChaincodeEvent, ceSource, description))
+	}
+	return ets
+}
+
+func tendermintEventTypes(src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec, chainID string) []EventTypeArgs {
+	blocks, txs := true, false
+	if spec.Tendermint != nil && len(spec.Tendermint.Queries) > 0 {
+		blocks = false
+		for _, s := range spec.Tendermint.Queries {
+			q, err := tendermint.ParseQuery(s)
+			if err != nil {
+				continue
+			}
+			switch q.EventType() {
+			case tendermint.EventNewBlock:
+				blocks = true
+			case tendermint.EventTx:
+				txs = true
+			}
+		}
+	}
+
+	chainSource := chainEventSource(sourcesv1alpha1.ChainFamilyTendermint, chainID)
+	var ets []EventTypeArgs
+	if blocks {
+		ets = append(ets, eventTypeArgs(src, sourcesv1alpha1.ChainFamilyTendermint, sourcesv1alpha1.BlockchainEventKindBlock, chainSource,
+			"New block of the chain, with the attributes of its ABCI events decoded."))
+	}
+	if txs {
+		ets = append(ets, eventTypeArgs(src, sourcesv1alpha1.ChainFamilyTendermint, sourcesv1alpha1.BlockchainEventKindTransaction, chainSource,
+			"Transaction included in a block, with its result and the attributes of its ABCI events decoded."))
+	}
+	return ets
+}
+
+func solanaEventTypes(src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec, chainID string) []EventTypeArgs {
+	opts := spec.Solana
+	if opts == nil {
+		opts = &sourcesv1alpha1.SolanaOptions{}
+	}
+	var ets []EventTypeArgs
+	add := func(kind sourcesv1alpha1.BlockchainEventKind, ceSource, description string) {
+		ets = append(ets, eventTypeArgs(src, sourcesv1alpha1.ChainFamilySolana, kind, ceSource, description))
+	}
+	accountSource := func(chainSource, address string) string {
+		if chainSource == "" {
+			return ""
+		}
+		return sourcesv1alpha1.BlockchainAccountEventSource(chainSource, address)
+	}
+
+	chainSource := chainEventSource(sourcesv1alpha1.ChainFamilySolana, chainID)
+	for _, addr := range opts.Mentions {
+		add(sourcesv1alpha1.BlockchainEventKindLog, accountSource(chainSource, addr),
+			fmt.Sprintf("Transaction mentioning %s, with its logs and details.", addr))
+	}
+	for _, addr := range opts.Accounts {
+		add(sourcesv1alpha1.BlockchainEventKindAccount, accountSource(chainSource, addr),
+			fmt.Sprintf("Change of the account %s, with its new state.", addr))
+	}
+	if opts.Slots || len(opts.Mentions) == 0 && len(opts.Accounts) == 0 {
+		add(sourcesv1alpha1.BlockchainEventKindSlot, chainSource, "Slot processed by the node.")
+	}
+	return ets
+}
+
+// beaconEventKinds are the kinds of the events emitted for the topics of the
+// event stream of beacon nodes, with their description.
+var beaconEventKinds = map[sourcesv1alpha1.BeaconTopic]struct {
+	kind        sourcesv1alpha1.BlockchainEventKind
+	description string
+}{
+	sourcesv1alpha1.BeaconTopicHead:                {sourcesv1alpha1.BlockchainEventKindHead, "New head of the beacon chain."},
+	sourcesv1alpha1.BeaconTopicBlock:               {sourcesv1alpha1.BlockchainEventKindBlock, "Block imported by the node."},
+	sourcesv1alpha1.BeaconTopicFinalizedCheckpoint: {sourcesv1alpha1.BlockchainEventKindFinalizedCheckpoint, "Epoch finalized, with its checkpoint block."},
+	sourcesv1alpha1.BeaconTopicChainReorg:          {sourcesv1alpha1.BlockchainEventKindReorg, "Reorg of the head of the beacon chain."},
+	sourcesv1alpha1.BeaconTopicVoluntaryExit:       {sourcesv1alpha1.BlockchainEventKindVoluntaryExit, "Voluntary exit of a validator received by the node."},
+}
+
+func beaconEventTypes(src *sourcesv1alpha1.BlockchainSource, spec *sourcesv1alpha1.BlockchainSourceSpec, chainID string) []EventTypeArgs {
+	topics := sourcesv1alpha1.BeaconTopics
+	if spec.Beacon != nil && len(spec.Beacon.Topics) > 0 {
+		topics = spec.Beacon.Topics
+	}
+	chainSource := chainEventSource(sourcesv1alpha1.ChainFamilyBeacon, chainID)
+	ets := make([]EventTypeArgs, 0, len(topics))
+	for _, topic := range topics {
+		k := beaconEventKinds[topic]
+		ets = append(ets, eventTypeArgs(src, sourcesv1alpha1.ChainFamilyBeacon, k.kind, chainSource, k.description))
+	}
+	return ets
+}
+
+// eventTypeArgs returns the arguments of the EventType of the events of a
+// kind emitted by a source reading a chain of the given family.
+func eventTypeArgs(src *sourcesv1alpha1.BlockchainSource, family sourcesv1alpha1.ChainFamily, kind sourcesv1alpha1.BlockchainEventKind, ceSource, description string) EventTypeArgs {
+	return EventTypeArgs{
+		Source:      src,
+		CeType:      sourcesv1alpha1.BlockchainEventType(family, kind),
+		CeSource:    ceSource,
+		CeSchema:    sourcesv1alpha1.BlockchainEventSchema(family, kind),
+		Description: description,
+	}
+}
+
+// chainEventSource returns the source of the events of a chain, unknown
+// when its chain ID is.
+func chainEventSource(family sourcesv1alpha1.ChainFamily, chainID string) string {
+	if chainID == "" {
+		return ""
+	}
+	return sourcesv1alpha1.BlockchainEventSource(family, chainID)
+}
+
+// contractSources returns the sources of the events of the subscribed
+// contracts. The source is unknown when the chain is, or when the logs of
+// any contract are received.
+func contractSources(chainSource string, addresses []string) []string {
+	if chainSource == "" || len(addresses) == 0 {
+		return []string{""}
+	}
+	sources := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		sources = append(sources, sourcesv1alpha1.BlockchainContractEventSource(chainSource, addr))
+	}
+	return sources
+}
+
+// abiEvents returns the events of the ABI whose logs are received and
+// decoded, and whether logs of other events, emitted with their raw topics
+// and data, may be received as well.
+func abiEvents(contracts *sourcesv1alpha1.ContractSubscription, abi *evm.ABI) ([]*evm.Event, bool) {
+	if abi == nil {
+		return nil, true
+	}
+
+	var events []*evm.Event
+	if len(contracts.EventSignatures) == 0 {
+		for _, e := range abi.Events {
+			// Logs of anonymous events have no topic identifying them.
+			if !e.Anonymous {
+				events = append(events, e)
+			}
+		}
+		return events, true
+	}
+
+	raw := false
+	for _, sig := range contracts.EventSignatures {
+		var e *evm.Event
+		switch {
+		case strings.HasPrefix(sig, "0x"):
+			e = abi.EventByTopic(sig)
+		case strings.Contains(sig, "("):
+			e = abi.EventByTopic(evm.EventTopic(sig))
+		default:
+			if e = abi.EventByName(sig); e != nil && e.Anonymous {
+				e = nil
+			}
+		}
+		if e == nil {
+			raw = true
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, raw
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+	"knative.dev/eventing-blockchain/pkg/evm"
+)
+
+const (
+	tokenAddress = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	otherAddress = "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"
+
+	tokenABI = `[
+  {"type": "event", "name": "Transfer", "inputs": [
+    {"name": "from", "type": "address", "indexed": true},
+    {"name": "to", "type": "address", "indexed": true},
+    {"name": "value", "type": "uint256"}
+  ]},
+  {"type": "event", "name": "Approval", "inputs": [
+    {"name": "owner", "type": "address", "indexed": true},
+    {"name": "spender", "type": "address", "indexed": true},
+    {"name": "value", "type": "uint256"}
+  ]}
+]`
+)
+
+func newEventTypeSource() *sourcesv1alpha1.BlockchainSource {
+	return &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+			UID:       "1234",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Family: sourcesv1alpha1.ChainFamilyEVM,
+			SourceSpec: duckv1.SourceSpec{
+				Sink: duckv1.Destination{
+					Ref: &duckv1.KReference{
+						APIVersion: "eventing.knative.dev/v1",
+						Kind:       "Broker",
+						Name:       "default",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMakeEventType(t *testing.T) {
+	src := newEventTypeSource()
+	args := &EventTypeArgs{
+		Source:      src,
+		CeType:      "dev.knative.source.blockchain.evm.log",
+		CeSource:    "eip155:1/contract/0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		CeSchema:    "https://raw.githubusercontent.com/knative-extensions/eventing-blockchain/main/docs/schemas/evm/log.json",
+		Description: "Contract log.",
+	}
+
+	et := MakeEventType(args)
+	if got, want := et.Spec.Source.String(), args.CeSource; got != want {
+		t.Errorf("Source = %s, want %s", got, want)
+	}
+	if got, want := et.Spec.Schema.String(), args.CeSchema; got != want {
+		t.Errorf("Schema = %s, want %s", got, want)
+	}
+	if got, want := et.Spec.Broker, "default"; got != want {
+		t.Errorf("Broker = %s, want %s", got, want)
+	}
+	if !metav1.IsControlledBy(et, src) {
+		t.Error("EventType not controlled by the source")
+	}
+	if diff := cmp.Diff(Labels(src.Name), et.Labels); diff != "" {
+		t.Errorf("unexpected labels (-want, +got) = %v", diff)
+	}
+
+	if other := MakeEventType(args); other.Name != et.Name {
+		t.Errorf("Name = %s, then %s, want stable names", et.Name, other.Name)
+	}
+	args.Description = "Other contract log."
+	if other := MakeEventType(args); other.Name == et.Name {
+		t.Errorf("Name = %s for different event types", et.Name)
+	}
+
+	args.CeSource = ""
+	src.Spec.Sink = duckv1.Destination{}
+	et = MakeEventType(args)
+	if et.Spec.Source != nil || et.Spec.Broker != "" {
+		t.Errorf("Source, Broker = %v, %q, want none", et.Spec.Source, et.Spec.Broker)
+	}
+}
+
+// eventTypeKey summarizes the arguments of an EventType.
+type eventTypeKey struct {
+	Kind, Source, Description string
+}
+
+func eventTypeKeys(t *testing.T, family sourcesv1alpha1.ChainFamily, ets []EventTypeArgs) []eventTypeKey {
+	t.Helper()
+	keys := make([]eventTypeKey, 0, len(ets))
+	for _, et := range ets {
+		kind := sourcesv1alpha1.BlockchainEventKind(et.CeType[len(sourcesv1alpha1.BlockchainEventTypePrefix+"."+string(family)+"."):])
+		if want := sourcesv1alpha1.BlockchainEventType(family, kind); et.CeType != want {
+			t.Errorf("CeType = %s, want %s", et.CeType, want)
+		}
+		if want := sourcesv1alpha1.BlockchainEventSchema(family, kind); et.CeSchema != want {
+			t.Errorf("CeSchema = %s, want %s", et.CeSchema, want)
+		}
+		keys = append(keys, eventTypeKey{Kind: string(kind), Source: et.CeSource, Description: et.Description})
+	}
+	return keys
+}
+
+func TestEventTypesBlocks(t *testing.T) {
+	src := newEventTypeSource()
+
+	got := eventTypeKeys(t, sourcesv1alpha1.ChainFamilyEVM, EventTypes(src, &src.Spec, "1", nil))
+	want := []eventTypeKey{
+		{"block", "eip155:1", "New block of the chain."},
+		{"reorg", "eip155:1", "Reorg that orphaned blocks events were emitted for."},
+		{"retracted", "eip155:1", "Retraction of an event emitted for a block orphaned by a reorg."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+
+	// The source is not known until the chain ID is.
+	for _, et := range EventTypes(src, &src.Spec, "", nil) {
+		if et.CeSource != "" {
+			t.Errorf("CeSource = %s, want none without a chain ID", et.CeSource)
+		}
+	}
+}
+
+func TestEventTypesBitcoin(t *testing.T) {
+	src := newEventTypeSource()
+	src.Spec.Family = sourcesv1alpha1.ChainFamilyBitcoin
+
+	got := eventTypeKeys(t, sourcesv1alpha1.ChainFamilyBitcoin, EventTypes(src, &src.Spec, "main", nil))
+	const mainnet = "bip122:000000000019d6689c085ae165831e93"
+	want := []eventTypeKey{
+		{"block", mainnet, "New block of the chain, with the inputs and outputs of its transactions decoded."},
+		{"transaction", mainnet, "Transaction entering the mempool of the node, with its inputs and outputs decoded."},
+		{"reorg", mainnet, "Reorg that orphaned blocks events were emitted for."},
+		{"retracted", mainnet, "Retraction of an event emitted for a block orphaned by a reorg."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+}
+
+func TestEventTypesFabric(t *testing.T) {
+	src := newEventTypeSource()
+	src.Spec.Family = sourcesv1alpha1.ChainFamilyFabric
+
+	got := eventTypeKeys(t, sourcesv1alpha1.ChainFamilyFabric, EventTypes(src, &src.Spec, "mychannel", nil))
+	want := []eventTypeKey{
+		{"transaction", "fabric:mychannel", "Transaction committed to the channel, with its validation code."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+
+	src.Spec.Fabric = &sourcesv1alpha1.FabricOptions{
+		ChaincodeEvents: []sourcesv1alpha1.ChaincodeEventSubscription{
+			{ChaincodeName: "basic"},
+			{ChaincodeName: "token", EventName: "Transfer|Approval"},
+		},
+	}
+	got = eventTypeKeys(t, sourcesv1alpha1.ChainFamilyFabric, EventTypes(src, &src.Spec, "mychannel", nil))
+	want = []eventTypeKey{
+		{"chaincodeevent", "fabric:mychannel/chaincode/basic", "Event set by the basic chaincode in a valid transaction."},
+		{"chaincodeevent", "fabric:mychannel/chaincode/token", `Event matching "Transfer|Approval" set by the token chaincode in a valid transaction.`},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+}
+
+func TestEventTypesTendermint(t *testing.T) {
+	src := newEventTypeSource()
+	src.Spec.Family = sourcesv1alpha1.ChainFamilyTendermint
+
+	got := eventTypeKeys(t, sourcesv1alpha1.ChainFamilyTendermint, EventTypes(src, &src.Spec, "cosmoshub-4", nil))
+	want := []eventTypeKey{
+		{"block", "cosmos:cosmoshub-4", "New block of the chain, with the attributes of its ABCI events decoded."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+
+	src.Spec.Tendermint = &sourcesv1alpha1.TendermintOptions{
+		Queries: []string{"tm.event='Tx' AND transfer.recipient='cosmos1bob'", "tm.event='Tx' AND message.sender='cosmos1bob'"},
+	}
+	got = eventTypeKeys(t, sourcesv1alpha1.ChainFamilyTendermint, EventTypes(src, &src.Spec, "cosmoshub-4", nil))
+	want = []eventTypeKey{
+		{"transaction", "cosmos:cosmoshub-4", "Transaction included in a block, with its result and the attributes of its ABCI events decoded."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+}
+
+func TestEventTypesSolana(t *testing.T) {
+	src := newEventTypeSource()
+	src.Spec.Family = sourcesv1alpha1.ChainFamilySolana
+
+	got := eventTypeKeys(t, sourcesv1alpha1.ChainFamilySolana, EventTypes(src, &src.Spec, "mainnet-beta", nil))
+	want := []eventTypeKey{
+		{"slot", "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", "Slot processed by the node."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+
+	const (
+		token = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+		vote  = "Vote111111111111111111111111111111111111111"
+	)
+	src.Spec.Solana = &sourcesv1alpha1.SolanaOptions{
+		Mentions: []string{token},
+		Accounts: []string{vote},
+	}
+	got = eventTypeKeys(t, sourcesv1alpha1.ChainFamilySolana, EventTypes(src, &src.Spec, "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", nil))
+	want = []eventTypeKey{
+		{"log", "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/account/" + token, "Transaction mentioning " + token + ", with its logs and details."},
+		{"account", "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/account/" + vote, "Change of the account " + vote + ", with its new state."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+
+	got = eventTypeKeys(t, sourcesv1alpha1.ChainFamilySolana, EventTypes(src, &src.Spec, "", nil))
+	want = []eventTypeKey{
+		{"log", "", "Transaction mentioning " + token + ", with its logs and details."},
+		{"account", "", "Change of the account " + vote + ", with its new state."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+}
+
+func TestEventTypesBeacon(t *testing.T) {
+	src := newEventTypeSource()
+	src.Spec.Family = sourcesv1alpha1.ChainFamilyBeacon
+
+	got := eventTypeKeys(t, sourcesv1alpha1.ChainFamilyBeacon, EventTypes(src, &src.Spec, "1", nil))
+	want := []eventTypeKey{
+		{"head", "beacon:1", "New head of the beacon chain."},
+		{"block", "beacon:1", "Block imported by the node."},
+		{"finalizedcheckpoint", "beacon:1", "Epoch finalized, with its checkpoint block."},
+		{"reorg", "beacon:1", "Reorg of the head of the beacon chain."},
+		{"voluntaryexit", "beacon:1", "Voluntary exit of a validator received by the node."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+
+	src.Spec.Beacon = &sourcesv1alpha1.BeaconOptions{
+		Topics: []sourcesv1alpha1.BeaconTopic{sourcesv1alpha1.BeaconTopicFinalizedCheckpoint},
+	}
+	got = eventTypeKeys(t, sourcesv1alpha1.ChainFamilyBeacon, EventTypes(src, &src.Spec, "", nil))
+	want = []eventTypeKey{
+		{"finalizedcheckpoint", "", "Epoch finalized, with its checkpoint block."},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected event types (-want, +got) = %v", diff)
+	}
+}
+
+func TestEventTypesContracts(t *testing.T) {
+	abi, err := evm.ParseABI([]byte(tokenABI))
+	if err != nil {
+		t.Fatalf("ParseABI() = %v", err)
+	}
+
+	const (
+		tokenSource = "eip155:1/contract/0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+		otherSource = "eip155:1/contract/0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"
+
+		transfer  = "Contract log of the Transfer(address indexed from, address indexed to, uint256 value) event, decoded with the ABI."
+		approval  = "Contract log of the Approval(address indexed owner, address indexed spender, uint256 value) event, decoded with the ABI."
+		raw       = "Contract log of an event the ABI does not declare, with its raw topics and data."
+		reorg     = "Reorg that orphaned blocks events were emitted for."
+		retracted = "Retraction of an event emitted for a block orphaned by a reorg."
+	)
+
+	tests := map[string]struct {
+		contracts *sourcesv1alpha1.ContractSubscription
+		abi       *evm.ABI
+		want      []eventTypeKey
+	}{
+		"all events of the ABI": {
+			contracts: &sourcesv1alpha1.ContractSubscription{
+				Addresses: []string{tokenAddress},
+			},
+			abi: abi,
+			want: []eventTypeKey{
+				{"log", tokenSource, transfer},
+				{"log", tokenSource, approval},
+				{"log", tokenSource, raw},
+				{"reorg", "eip155:1", reorg},
+				{"retracted", tokenSource, retracted},
+			},
+		},
+		"selected events": {
+			contracts: &sourcesv1alpha1.ContractSubscription{
+				Addresses:       []string{tokenAddress, otherAddress},
+				EventSignatures: []string{"Transfer", abi.EventByName("Approval").Topic()},
+			},
+			abi: abi,
+			want: []eventTypeKey{
+				{"log", tokenSource, transfer},
+				{"log", tokenSource, approval},
+				{"log", otherSource, transfer},
+				{"log", otherSource, approval},
+				{"reorg", "eip155:1", reorg},
+				{"retracted", tokenSource, retracted},
+				{"retracted", otherSource, retracted},
+			},
+		},
+		"event missing from the ABI": {
+			contracts: &sourcesv1alpha1.ContractSubscription{
+				EventSignatures: []string{"Transfer(address,address,uint256)", "Deposit(address,uint256)"},
+			},
+			abi: abi,
+			want: []eventTypeKey{
+				{"log", "", transfer},
+				{"log", "", raw},
+				{"reorg", "eip155:1", reorg},
+				{"retracted", "", retracted},
+			},
+		},
+		"no ABI": {
+			contracts: &sourcesv1alpha1.ContractSubscription{
+				Addresses: []string{tokenAddress},
+			},
+			want: []eventTypeKey{
+				{"log", tokenSource, raw},
+				{"reorg", "eip155:1", reorg},
+				{"retracted", tokenSource, retracted},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := newEventTypeSource()
+			src.Spec.Contracts = tc.contracts
+
+			got := eventTypeKeys(t, sourcesv1alpha1.ChainFamilyEVM, EventTypes(src, &src.Spec, "1", tc.abi))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected event types (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/kmeta"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+// NetworkCredentialsSecretName returns the name of the Secret the
+// credentials of the network referenced by a BlockchainSource are copied to,
+// in the namespace of the source.
+func NetworkCredentialsSecretName(src *sourcesv1alpha1.BlockchainSource) string {
+	return kmeta.ChildName(src.Name, "-network-credentials")
+}
+
+// NetworkCredentialsKey returns the key of the credentials of the network
+// endpoint at index i in the Secret they are copied to.
+func NetworkCredentialsKey(i int) string {
+	return fmt.Sprintf("endpoint-%d", i)
+}
+
+// MakeNetworkCredentialsSecret generates (but does not insert into K8s) the
+// Secret holding the copy of the credentials of the network referenced by
+// the source, owned by the source so that it goes away along with it.
+func MakeNetworkCredentialsSecret(src *sourcesv1alpha1.BlockchainSource, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: src.Namespace,
+			Name:      NetworkCredentialsSecretName(src),
+			Labels:    Labels(src.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				*kmeta.NewControllerRef(src),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// NetworkSpec returns the spec of a BlockchainSource completed with the
+// chain, the endpoints and the defaults of the network it references. Only
+// the endpoints usable in the mode of the source are kept, whose
+// credentials are read from the copy made in the namespace of the source.
+func NetworkSpec(src *sourcesv1alpha1.BlockchainSource, network *sourcesv1alpha1.BlockchainNetwork) *sourcesv1alpha1.BlockchainSourceSpec {
+	spec := src.Spec.DeepCopy()
+	ns := &network.Spec
+
+	spec.Family = ns.Family
+	spec.ChainID = ns.ChainID
+
+	spec.Endpoints = nil
+	// Streaming bitcoin sources read blocks over HTTP, and are notified of
+	// them over ZMQ. Fabric peers are all read over gRPC, and tendermint
+	// sources always subscribe to the events of the nodes over WebSocket.
+	// Solana sources are given the HTTP endpoints, their PubSub endpoints
+	// being derived from them, and beacon sources stream the events of the
+	// nodes over HTTP.
+	webSocket := spec.Mode == sourcesv1alpha1.IngestionModeStreaming &&
+		ns.Family != sourcesv1alpha1.ChainFamilyBitcoin && ns.Family != sourcesv1alpha1.ChainFamilySolana &&
+		ns.Family != sourcesv1alpha1.ChainFamilyBeacon ||
+		ns.Family == sourcesv1alpha1.ChainFamilyTendermint
+	for i, e := range ns.Endpoints {
+		if ns.Family != sourcesv1alpha1.ChainFamilyFabric && isWebSocket(e.URL) != webSocket {
+			continue
+		}
+		endpoint := sourcesv1alpha1.RPCEndpoint{URL: e.URL, Priority: e.Priority}
+		if e.Credentials != nil {
+			endpoint.Credentials = &sourcesv1alpha1.SecretValueFromSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: NetworkCredentialsSecretName(src)},
+					Key:                  NetworkCredentialsKey(i),
+				},
+			}
+		}
+		spec.Endpoints = append(spec.Endpoints, endpoint)
+	}
+
+	if spec.PollInterval == nil && ns.PollInterval != nil {
+		spec.PollInterval = ns.PollInterval.DeepCopy()
+	}
+	if spec.MaxLogRange == nil && ns.MaxLogRange != nil {
+		maxLogRange := *ns.MaxLogRange
+		spec.MaxLogRange = &maxLogRange
+	}
+	if spec.Finality == nil && ns.Finality != nil {
+		spec.Finality = ns.Finality.DeepCopy()
+	}
+	return spec
+}
+
+func isWebSocket(u string) bool {
+	return strings.HasPrefix(u, "ws://") || strings.HasPrefix(u, "wss://")
+}
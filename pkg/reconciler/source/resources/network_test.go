@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/ptr"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+func TestNetworkSpec(t *testing.T) {
+	network := &sourcesv1alpha1.BlockchainNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "mainnet"},
+		Spec: sourcesv1alpha1.BlockchainNetworkSpec{
+			Family:  sourcesv1alpha1.ChainFamilyEVM,
+			ChainID: "1",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{
+				URL: "https://node.example.com",
+			}, {
+				URL: "wss://node.example.com",
+			}, {
+				URL:      "https://provider.example.com",
+				Priority: 1,
+				Credentials: &sourcesv1alpha1.SecretValueFromSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "provider"},
+						Key:                  "authorization",
+					},
+				},
+			}},
+			PollInterval: &metav1.Duration{Duration: 12 * time.Second},
+			MaxLogRange:  ptr.Int64(2000),
+			Finality: &sourcesv1alpha1.Finality{
+				Level:         sourcesv1alpha1.FinalityLevelConfirmed,
+				Confirmations: ptr.Int64(12),
+			},
+		},
+	}
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Network:     "mainnet",
+			Mode:        sourcesv1alpha1.IngestionModePolling,
+			MaxLogRange: ptr.Int64(500),
+		},
+	}
+
+	want := &sourcesv1alpha1.BlockchainSourceSpec{
+		Network: "mainnet",
+		Family:  sourcesv1alpha1.ChainFamilyEVM,
+		ChainID: "1",
+		Endpoints: []sourcesv1alpha1.RPCEndpoint{{
+			URL: "https://node.example.com",
+		}, {
+			URL:      "https://provider.example.com",
+			Priority: 1,
+			Credentials: &sourcesv1alpha1.SecretValueFromSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "source-name-network-credentials"},
+					Key:                  "endpoint-2",
+				},
+			},
+		}},
+		Mode:         sourcesv1alpha1.IngestionModePolling,
+		PollInterval: &metav1.Duration{Duration: 12 * time.Second},
+		MaxLogRange:  ptr.Int64(500),
+		Finality: &sourcesv1alpha1.Finality{
+			Level:         sourcesv1alpha1.FinalityLevelConfirmed,
+			Confirmations: ptr.Int64(12),
+		},
+	}
+	if diff := cmp.Diff(want, NetworkSpec(src, network)); diff != "" {
+		t.Errorf("NetworkSpec() (-want, +got) = %s", diff)
+	}
+
+	src.Spec.Mode = sourcesv1alpha1.IngestionModeStreaming
+	got := NetworkSpec(src, network)
+	if len(got.Endpoints) != 1 || got.Endpoints[0].URL != "wss://node.example.com" {
+		t.Errorf("Endpoints = %v, want the WebSocket endpoint when streaming", got.Endpoints)
+	}
+
+	network.Spec.Family, network.Spec.ChainID = sourcesv1alpha1.ChainFamilyBitcoin, "main"
+	got = NetworkSpec(src, network)
+	if len(got.Endpoints) != 2 || got.Endpoints[0].URL != "https://node.example.com" {
+		t.Errorf("Endpoints = %v, want the HTTP endpoints when streaming from bitcoin nodes", got.Endpoints)
+	}
+
+	network.Spec.Family, network.Spec.ChainID = sourcesv1alpha1.ChainFamilyFabric, "mychannel"
+	network.Spec.Endpoints = []sourcesv1alpha1.RPCEndpoint{{URL: "grpcs://peer0:7051"}, {URL: "grpc://peer1:7051"}}
+	got = NetworkSpec(src, network)
+	if len(got.Endpoints) != 2 {
+		t.Errorf("Endpoints = %v, want all the peers of a fabric network", got.Endpoints)
+	}
+
+	src.Spec.Mode = sourcesv1alpha1.IngestionModePolling
+	network.Spec.Family, network.Spec.ChainID = sourcesv1alpha1.ChainFamilyTendermint, "cosmoshub-4"
+	network.Spec.Endpoints = []sourcesv1alpha1.RPCEndpoint{{URL: "https://rpc.cosmos.example.com"}, {URL: "wss://rpc.cosmos.example.com/websocket"}}
+	got = NetworkSpec(src, network)
+	if len(got.Endpoints) != 1 || got.Endpoints[0].URL != "wss://rpc.cosmos.example.com/websocket" {
+		t.Errorf("Endpoints = %v, want the WebSocket endpoint of a tendermint network", got.Endpoints)
+	}
+
+	src.Spec.Mode = sourcesv1alpha1.IngestionModeStreaming
+	network.Spec.Family, network.Spec.ChainID = sourcesv1alpha1.ChainFamilySolana, "mainnet-beta"
+	network.Spec.Endpoints = []sourcesv1alpha1.RPCEndpoint{{URL: "https://api.mainnet-beta.solana.com"}, {URL: "wss://api.mainnet-beta.solana.com"}}
+	got = NetworkSpec(src, network)
+	if len(got.Endpoints) != 1 || got.Endpoints[0].URL != "https://api.mainnet-beta.solana.com" {
+		t.Errorf("Endpoints = %v, want the HTTP endpoint of a solana network", got.Endpoints)
+	}
+
+	network.Spec.Family, network.Spec.ChainID = sourcesv1alpha1.ChainFamilyBeacon, "1"
+	network.Spec.Endpoints = []sourcesv1alpha1.RPCEndpoint{{URL: "http://beacon:5052"}, {URL: "ws://geth:8546"}}
+	got = NetworkSpec(src, network)
+	if len(got.Endpoints) != 1 || got.Endpoints[0].URL != "http://beacon:5052" {
+		t.Errorf("Endpoints = %v, want the HTTP endpoint of a beacon network", got.Endpoints)
+	}
+}
+
+func TestMakeNetworkCredentialsSecret(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+			UID:       "1234",
+		},
+	}
+	secret := MakeNetworkCredentialsSecret(src, map[string][]byte{"endpoint-0": []byte("Bearer token")})
+
+	if got, want := secret.Name, "source-name-network-credentials"; got != want {
+		t.Errorf("Name = %s, want %s", got, want)
+	}
+	if got, want := secret.Namespace, "source-namespace"; got != want {
+		t.Errorf("Namespace = %s, want %s", got, want)
+	}
+	if !metav1.IsControlledBy(secret, src) {
+		t.Error("Secret not controlled by the source")
+	}
+}
@@ -0,0 +1,349 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+// ReceiveAdapterArgs are the arguments needed to create a BlockchainSource
+// Receive Adapter. Every field is required.
+type ReceiveAdapterArgs struct {
+	Image   string
+	Source  *sourcesv1alpha1.BlockchainSource
+	Labels  map[string]string
+	SinkURI string
+	Configs reconcilersource.ConfigAccessor
+}
+
+// endpoint is an RPC endpoint as passed to the receive adapter, whose
+// credentials are passed in their own environment variable.
+type endpoint struct {
+	URL      string `json:"url"`
+	Priority int32  `json:"priority,omitempty"`
+}
+
+// DeploymentName returns the name of the receive adapter Deployment of a
+// BlockchainSource.
+func DeploymentName(src *sourcesv1alpha1.BlockchainSource) string {
+	return kmeta.ChildName(fmt.Sprintf("blockchainsource-%s-", src.Name), string(src.GetUID()))
+}
+
+// CheckpointConfigMapName returns the name of the ConfigMap the receive
+// adapter of a BlockchainSource saves its checkpoint in.
+func CheckpointConfigMapName(src *sourcesv1alpha1.BlockchainSource) string {
+	return kmeta.ChildName(src.Name, "-checkpoint")
+}
+
+// MakeCheckpointConfigMap generates (but does not insert into K8s) the
+// ConfigMap the receive adapter saves its checkpoint in, owned by the
+// source so that the checkpoint goes away along with it.
+func MakeCheckpointConfigMap(src *sourcesv1alpha1.BlockchainSource) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: src.Namespace,
+			Name:      CheckpointConfigMapName(src),
+			Labels:    Labels(src.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				*kmeta.NewControllerRef(src),
+			},
+		},
+	}
+}
+
+// MakeReceiveAdapter generates (but does not insert into K8s) the Receive
+// Adapter Deployment for BlockchainSources.
+func MakeReceiveAdapter(args *ReceiveAdapterArgs) (*appsv1.Deployment, error) {
+	env, err := makeEnv(args)
+	if err != nil {
+		return nil, fmt.Errorf("error generating env vars: %w", err)
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: args.Source.Namespace,
+			Name:      DeploymentName(args.Source),
+			Labels:    args.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*kmeta.NewControllerRef(args.Source),
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: args.Labels,
+			},
+			// A single replica reads the chain, more would emit every
+			// event several times.
+			Replicas: ptr.Int32(1),
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: args.Labels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: args.Source.Spec.ServiceAccountName,
+					EnableServiceLinks: ptr.Bool(false),
+					Containers: []corev1.Container{{
+						Name:  "receive-adapter",
+						Image: args.Image,
+						Env:   env,
+						Ports: []corev1.ContainerPort{{
+							Name:          "metrics",
+							ContainerPort: 9090,
+						}},
+					}},
+				},
+			},
+		},
+	}, nil
+}
+
+func makeEnv(args *ReceiveAdapterArgs) ([]corev1.EnvVar, error) {
+	spec := &args.Source.Spec
+
+	envs := []corev1.EnvVar{{
+		Name:  adapter.EnvConfigSink,
+		Value: args.SinkURI,
+	}, {
+		Name: adapter.EnvConfigNamespace,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: "metadata.namespace",
+			},
+		},
+	}, {
+		Name:  adapter.EnvConfigName,
+		Value: args.Source.Name,
+	}, {
+		Name:  "METRICS_DOMAIN",
+		Value: "knative.dev/eventing",
+	}, {
+		Name:  "BLOCKCHAIN_CHECKPOINT_CONFIGMAP",
+		Value: CheckpointConfigMapName(args.Source),
+	}}
+
+	endpoints := make([]endpoint, 0, len(spec.Endpoints))
+	for i, e := range spec.Endpoints {
+		endpoints = append(endpoints, endpoint{URL: e.URL, Priority: e.Priority})
+		if e.Credentials != nil && e.Credentials.SecretKeyRef != nil {
+			envs = append(envs, corev1.EnvVar{
+				Name: fmt.Sprintf("BLOCKCHAIN_ENDPOINT_%d_CREDENTIALS", i),
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: e.Credentials.SecretKeyRef,
+				},
+			})
+		}
+	}
+	endpointsJSON, err := json.Marshal(endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal endpoints: %w", err)
+	}
+	envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_ENDPOINTS", Value: string(endpointsJSON)})
+
+	if spec.Family != "" {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_FAMILY", Value: string(spec.Family)})
+	}
+	if spec.ChainID != "" {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_CHAIN_ID", Value: spec.ChainID})
+	}
+
+	if spec.Mode != "" {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_MODE", Value: string(spec.Mode)})
+	}
+
+	if spec.PollInterval != nil {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_POLL_INTERVAL", Value: spec.PollInterval.Duration.String()})
+	}
+	if spec.MaxLogRange != nil {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_LOGS_CHUNK_SIZE", Value: strconv.FormatInt(*spec.MaxLogRange, 10)})
+	}
+
+	if spec.Contracts != nil {
+		contracts := spec.Contracts.DeepCopy()
+		abi := contracts.ABI
+		contracts.ABI = nil
+		contractsJSON, err := json.Marshal(contracts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal contract subscription: %w", err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_CONTRACTS", Value: string(contractsJSON)})
+
+		switch {
+		case abi == nil:
+		case abi.ConfigMapKeyRef != nil:
+			envs = append(envs, corev1.EnvVar{
+				Name: "BLOCKCHAIN_ABI",
+				ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: abi.ConfigMapKeyRef,
+				},
+			})
+		default:
+			envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_ABI", Value: abi.Inline})
+		}
+	}
+
+	if spec.Bitcoin != nil && spec.Bitcoin.ZMQEndpoint != "" {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_ZMQ_URL", Value: spec.Bitcoin.ZMQEndpoint})
+	}
+
+	if spec.Fabric != nil {
+		fabricEnvs, err := makeFabricEnv(spec.Fabric)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, fabricEnvs...)
+	}
+
+	if spec.Tendermint != nil && len(spec.Tendermint.Queries) > 0 {
+		queriesJSON, err := json.Marshal(spec.Tendermint.Queries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tendermint queries: %w", err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_TENDERMINT_QUERIES", Value: string(queriesJSON)})
+	}
+
+	if spec.Solana != nil {
+		solanaEnvs, err := makeSolanaEnv(spec.Solana)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, solanaEnvs...)
+	}
+
+	if spec.Beacon != nil && len(spec.Beacon.Topics) > 0 {
+		topicsJSON, err := json.Marshal(spec.Beacon.Topics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal beacon topics: %w", err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_BEACON_TOPICS", Value: string(topicsJSON)})
+	}
+
+	if len(spec.Filters) > 0 {
+		filtersJSON, err := json.Marshal(spec.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal filters: %w", err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_FILTERS", Value: string(filtersJSON)})
+	}
+
+	if spec.Finality != nil {
+		if spec.Finality.Level != "" {
+			envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_FINALITY", Value: string(spec.Finality.Level)})
+		}
+		if spec.Finality.Confirmations != nil {
+			envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_CONFIRMATIONS", Value: strconv.FormatInt(*spec.Finality.Confirmations, 10)})
+		}
+	}
+
+	if spec.StartBlock != nil {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_START_BLOCK", Value: strconv.FormatInt(*spec.StartBlock, 10)})
+	}
+	if spec.EndBlock != nil {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_END_BLOCK", Value: strconv.FormatInt(*spec.EndBlock, 10)})
+	}
+
+	envs = append(envs, args.Configs.ToEnvVars()...)
+
+	if spec.CloudEventOverrides != nil {
+		ceJson, err := json.Marshal(spec.CloudEventOverrides)
+		if err != nil {
+			return nil, fmt.Errorf("failure to marshal cloud event overrides %v: %w", spec.CloudEventOverrides, err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: adapter.EnvConfigCEOverrides, Value: string(ceJson)})
+	}
+	return envs, nil
+}
+
+// makeFabricEnv returns the environment variables of the settings of a
+// source reading a Fabric channel. The identity is read from its Secrets.
+func makeFabricEnv(opts *sourcesv1alpha1.FabricOptions) ([]corev1.EnvVar, error) {
+	var envs []corev1.EnvVar
+	if opts.BlockType != "" {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_FABRIC_BLOCK_TYPE", Value: string(opts.BlockType)})
+	}
+
+	secrets := []struct {
+		name  string
+		value *sourcesv1alpha1.SecretValueFromSource
+	}{
+		{"BLOCKCHAIN_FABRIC_MSP_ID", &opts.Identity.MSPID},
+		{"BLOCKCHAIN_FABRIC_CERT", &opts.Identity.Certificate},
+		{"BLOCKCHAIN_FABRIC_KEY", &opts.Identity.PrivateKey},
+		{"BLOCKCHAIN_FABRIC_TLS_ROOT_CERT", opts.TLSRootCertificate},
+	}
+	for _, s := range secrets {
+		if s.value == nil || s.value.SecretKeyRef == nil {
+			continue
+		}
+		envs = append(envs, corev1.EnvVar{
+			Name: s.name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: s.value.SecretKeyRef,
+			},
+		})
+	}
+
+	if len(opts.ChaincodeEvents) > 0 {
+		subscriptionsJSON, err := json.Marshal(opts.ChaincodeEvents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chaincode event subscriptions: %w", err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_FABRIC_CHAINCODE_EVENTS", Value: string(subscriptionsJSON)})
+	}
+	return envs, nil
+}
+
+func makeSolanaEnv(opts *sourcesv1alpha1.SolanaOptions) ([]corev1.EnvVar, error) {
+	var envs []corev1.EnvVar
+	addresses := []struct {
+		name  string
+		value []string
+	}{
+		{"BLOCKCHAIN_SOLANA_MENTIONS", opts.Mentions},
+		{"BLOCKCHAIN_SOLANA_ACCOUNTS", opts.Accounts},
+	}
+	for _, a := range addresses {
+		if len(a.value) == 0 {
+			continue
+		}
+		addressesJSON, err := json.Marshal(a.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal solana addresses: %w", err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: a.name, Value: string(addressesJSON)})
+	}
+
+	if opts.Slots {
+		envs = append(envs, corev1.EnvVar{Name: "BLOCKCHAIN_SOLANA_SLOTS", Value: "true"})
+	}
+	return envs, nil
+}
@@ -0,0 +1,516 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/ptr"
+
+	sourcesv1alpha1 "knative.dev/eventing-blockchain/pkg/apis/sources/v1alpha1"
+)
+
+func TestMakeReceiveAdapter(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+			UID:       "1234",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			ServiceAccountName: "source-svc-acct",
+			Family:             sourcesv1alpha1.ChainFamilyEVM,
+			ChainID:            "1",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{
+				URL: "https://node.example.com",
+				Credentials: &sourcesv1alpha1.SecretValueFromSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "node-secret"},
+						Key:                  "authorization",
+					},
+				},
+			}, {
+				URL:      "https://backup.example.com",
+				Priority: 1,
+			}},
+			Mode:         sourcesv1alpha1.IngestionModePolling,
+			PollInterval: &metav1.Duration{Duration: 12 * time.Second},
+			MaxLogRange:  ptr.Int64(2000),
+			Contracts: &sourcesv1alpha1.ContractSubscription{
+				Addresses: []string{"0x5fbdb2315678afecb367f032d93f642f64180aa3"},
+				ABI: &sourcesv1alpha1.ContractABI{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "abis"},
+						Key:                  "token.json",
+					},
+				},
+			},
+			Finality: &sourcesv1alpha1.Finality{
+				Level:         sourcesv1alpha1.FinalityLevelConfirmed,
+				Confirmations: ptr.Int64(6),
+			},
+			StartBlock: ptr.Int64(100),
+			SourceSpec: duckv1.SourceSpec{
+				CloudEventOverrides: &duckv1.CloudEventOverrides{
+					Extensions: map[string]string{"chain": "mainnet"},
+				},
+			},
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Image:   "test-image",
+		Source:  src,
+		Labels:  Labels(src.Name),
+		SinkURI: "http://sink.example.com",
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	labels := Labels(src.Name)
+	want := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "source-namespace",
+			Name:      DeploymentName(src),
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "sources.knative.dev/v1alpha1",
+				Kind:               "BlockchainSource",
+				Name:               "source-name",
+				UID:                "1234",
+				Controller:         ptr.Bool(true),
+				BlockOwnerDeletion: ptr.Bool(true),
+			}},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Replicas: ptr.Int32(1),
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: "source-svc-acct",
+					EnableServiceLinks: ptr.Bool(false),
+					Containers: []corev1.Container{{
+						Name:  "receive-adapter",
+						Image: "test-image",
+						Ports: []corev1.ContainerPort{{
+							Name:          "metrics",
+							ContainerPort: 9090,
+						}},
+						Env: []corev1.EnvVar{{
+							Name:  "K_SINK",
+							Value: "http://sink.example.com",
+						}, {
+							Name: "NAMESPACE",
+							ValueFrom: &corev1.EnvVarSource{
+								FieldRef: &corev1.ObjectFieldSelector{
+									FieldPath: "metadata.namespace",
+								},
+							},
+						}, {
+							Name:  "NAME",
+							Value: "source-name",
+						}, {
+							Name:  "METRICS_DOMAIN",
+							Value: "knative.dev/eventing",
+						}, {
+							Name:  "BLOCKCHAIN_CHECKPOINT_CONFIGMAP",
+							Value: CheckpointConfigMapName(src),
+						}, {
+							Name: "BLOCKCHAIN_ENDPOINT_0_CREDENTIALS",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "node-secret"},
+									Key:                  "authorization",
+								},
+							},
+						}, {
+							Name:  "BLOCKCHAIN_ENDPOINTS",
+							Value: `[{"url":"https://node.example.com"},{"url":"https://backup.example.com","priority":1}]`,
+						}, {
+							Name:  "BLOCKCHAIN_FAMILY",
+							Value: "evm",
+						}, {
+							Name:  "BLOCKCHAIN_CHAIN_ID",
+							Value: "1",
+						}, {
+							Name:  "BLOCKCHAIN_MODE",
+							Value: "polling",
+						}, {
+							Name:  "BLOCKCHAIN_POLL_INTERVAL",
+							Value: "12s",
+						}, {
+							Name:  "BLOCKCHAIN_LOGS_CHUNK_SIZE",
+							Value: "2000",
+						}, {
+							Name:  "BLOCKCHAIN_CONTRACTS",
+							Value: `{"addresses":["0x5fbdb2315678afecb367f032d93f642f64180aa3"]}`,
+						}, {
+							Name: "BLOCKCHAIN_ABI",
+							ValueFrom: &corev1.EnvVarSource{
+								ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "abis"},
+									Key:                  "token.json",
+								},
+							},
+						}, {
+							Name:  "BLOCKCHAIN_FINALITY",
+							Value: "confirmed",
+						}, {
+							Name:  "BLOCKCHAIN_CONFIRMATIONS",
+							Value: "6",
+						}, {
+							Name:  "BLOCKCHAIN_START_BLOCK",
+							Value: "100",
+						}, {
+							Name: "K_LOGGING_CONFIG",
+						}, {
+							Name: "K_METRICS_CONFIG",
+						}, {
+							Name: "K_TRACING_CONFIG",
+						}, {
+							Name:  "K_CE_OVERRIDES",
+							Value: `{"extensions":{"chain":"mainnet"}}`,
+						}},
+					}},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected deployment (-want, +got) = %v", diff)
+	}
+}
+
+func TestMakeReceiveAdapterInlineABI(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Contracts: &sourcesv1alpha1.ContractSubscription{
+				ABI: &sourcesv1alpha1.ContractABI{Inline: `[{"type":"event","name":"Transfer"}]`},
+			},
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Source:  src,
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	env := make(map[string]string)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if got, want := env["BLOCKCHAIN_ABI"], `[{"type":"event","name":"Transfer"}]`; got != want {
+		t.Errorf("BLOCKCHAIN_ABI = %s, want %s", got, want)
+	}
+	if got, want := env["BLOCKCHAIN_CONTRACTS"], `{}`; got != want {
+		t.Errorf("BLOCKCHAIN_CONTRACTS = %s, want %s", got, want)
+	}
+}
+
+func TestMakeReceiveAdapterFilters(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Filters: []sourcesv1alpha1.EventFilter{{
+				Name:  "transfers",
+				CESQL: "eventname = 'Transfer'",
+			}, {
+				CEL: `int(data.args.value) > 1000000`,
+			}},
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Source:  src,
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	env := make(map[string]string)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	want := `[{"name":"transfers","cesql":"eventname = 'Transfer'"},{"cel":"int(data.args.value) \u003e 1000000"}]`
+	if got := env["BLOCKCHAIN_FILTERS"]; got != want {
+		t.Errorf("BLOCKCHAIN_FILTERS = %s, want %s", got, want)
+	}
+}
+
+func TestMakeReceiveAdapterBitcoin(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Family:    sourcesv1alpha1.ChainFamilyBitcoin,
+			ChainID:   "main",
+			Mode:      sourcesv1alpha1.IngestionModeStreaming,
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{URL: "http://bitcoind:8332"}},
+			Bitcoin:   &sourcesv1alpha1.BitcoinOptions{ZMQEndpoint: "tcp://bitcoind:28332"},
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Source:  src,
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	env := make(map[string]string)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{
+		"BLOCKCHAIN_FAMILY":    "bitcoin",
+		"BLOCKCHAIN_CHAIN_ID":  "main",
+		"BLOCKCHAIN_MODE":      "streaming",
+		"BLOCKCHAIN_ENDPOINTS": `[{"url":"http://bitcoind:8332"}]`,
+		"BLOCKCHAIN_ZMQ_URL":   "tcp://bitcoind:28332",
+	} {
+		if got := env[name]; got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestMakeReceiveAdapterFabric(t *testing.T) {
+	ref := func(name, key string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  key,
+		}
+	}
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Family:    sourcesv1alpha1.ChainFamilyFabric,
+			ChainID:   "mychannel",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{URL: "grpcs://peer0:7051"}},
+			Fabric: &sourcesv1alpha1.FabricOptions{
+				BlockType: sourcesv1alpha1.FabricBlockTypeFiltered,
+				Identity: sourcesv1alpha1.FabricIdentity{
+					MSPID:       sourcesv1alpha1.SecretValueFromSource{SecretKeyRef: ref("org1-user1", "mspid")},
+					Certificate: sourcesv1alpha1.SecretValueFromSource{SecretKeyRef: ref("org1-user1", "cert.pem")},
+					PrivateKey:  sourcesv1alpha1.SecretValueFromSource{SecretKeyRef: ref("org1-user1", "key.pem")},
+				},
+				TLSRootCertificate: &sourcesv1alpha1.SecretValueFromSource{SecretKeyRef: ref("org1-tls", "ca.crt")},
+				ChaincodeEvents: []sourcesv1alpha1.ChaincodeEventSubscription{
+					{ChaincodeName: "basic", EventName: "Create.*"},
+				},
+			},
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Source:  src,
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	env := make(map[string]corev1.EnvVar)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	for name, want := range map[string]string{
+		"BLOCKCHAIN_FAMILY":                  "fabric",
+		"BLOCKCHAIN_CHAIN_ID":                "mychannel",
+		"BLOCKCHAIN_FABRIC_BLOCK_TYPE":       "filtered",
+		"BLOCKCHAIN_FABRIC_CHAINCODE_EVENTS": `[{"chaincodeName":"basic","eventName":"Create.*"}]`,
+	} {
+		if got := env[name].Value; got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	for name, want := range map[string]*corev1.SecretKeySelector{
+		"BLOCKCHAIN_FABRIC_MSP_ID":        ref("org1-user1", "mspid"),
+		"BLOCKCHAIN_FABRIC_CERT":          ref("org1-user1", "cert.pem"),
+		"BLOCKCHAIN_FABRIC_KEY":           ref("org1-user1", "key.pem"),
+		"BLOCKCHAIN_FABRIC_TLS_ROOT_CERT": ref("org1-tls", "ca.crt"),
+	} {
+		var got *corev1.SecretKeySelector
+		if e := env[name]; e.ValueFrom != nil {
+			got = e.ValueFrom.SecretKeyRef
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("%s (-want, +got) = %s", name, diff)
+		}
+	}
+}
+
+func TestMakeReceiveAdapterTendermint(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Family:    sourcesv1alpha1.ChainFamilyTendermint,
+			ChainID:   "cosmoshub-4",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{URL: "wss://rpc.cosmos.example.com/websocket"}},
+			Tendermint: &sourcesv1alpha1.TendermintOptions{
+				Queries: []string{"tm.event='Tx' AND transfer.recipient='cosmos1bob'"},
+			},
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Source:  src,
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	env := make(map[string]string)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{
+		"BLOCKCHAIN_FAMILY":             "tendermint",
+		"BLOCKCHAIN_CHAIN_ID":           "cosmoshub-4",
+		"BLOCKCHAIN_TENDERMINT_QUERIES": `["tm.event='Tx' AND transfer.recipient='cosmos1bob'"]`,
+	} {
+		if got := env[name]; got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestMakeReceiveAdapterSolana(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Family:    sourcesv1alpha1.ChainFamilySolana,
+			ChainID:   "mainnet-beta",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{URL: "https://api.mainnet-beta.solana.com"}},
+			Finality:  &sourcesv1alpha1.Finality{Level: sourcesv1alpha1.FinalityLevelConfirmed},
+			Solana: &sourcesv1alpha1.SolanaOptions{
+				Mentions: []string{"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"},
+				Slots:    true,
+			},
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Source:  src,
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	env := make(map[string]string)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{
+		"BLOCKCHAIN_FAMILY":          "solana",
+		"BLOCKCHAIN_CHAIN_ID":        "mainnet-beta",
+		"BLOCKCHAIN_FINALITY":        "confirmed",
+		"BLOCKCHAIN_SOLANA_MENTIONS": `["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"]`,
+		"BLOCKCHAIN_SOLANA_ACCOUNTS": "",
+		"BLOCKCHAIN_SOLANA_SLOTS":    "true",
+	} {
+		if got := env[name]; got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestMakeReceiveAdapterBeacon(t *testing.T) {
+	src := &sourcesv1alpha1.BlockchainSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "source-name",
+			Namespace: "source-namespace",
+		},
+		Spec: sourcesv1alpha1.BlockchainSourceSpec{
+			Family:    sourcesv1alpha1.ChainFamilyBeacon,
+			ChainID:   "1",
+			Endpoints: []sourcesv1alpha1.RPCEndpoint{{URL: "http://beacon:5052"}},
+			Beacon: &sourcesv1alpha1.BeaconOptions{
+				Topics: []sourcesv1alpha1.BeaconTopic{sourcesv1alpha1.BeaconTopicHead, sourcesv1alpha1.BeaconTopicChainReorg},
+			},
+			StartBlock: ptr.Int64(8000000),
+		},
+	}
+
+	got, err := MakeReceiveAdapter(&ReceiveAdapterArgs{
+		Source:  src,
+		Configs: &reconcilersource.EmptyVarsGenerator{},
+	})
+	if err != nil {
+		t.Fatalf("MakeReceiveAdapter() = %v", err)
+	}
+
+	env := make(map[string]string)
+	for _, e := range got.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{
+		"BLOCKCHAIN_FAMILY":        "beacon",
+		"BLOCKCHAIN_CHAIN_ID":      "1",
+		"BLOCKCHAIN_ENDPOINTS":     `[{"url":"http://beacon:5052"}]`,
+		"BLOCKCHAIN_BEACON_TOPICS": `["head","chain_reorg"]`,
+		"BLOCKCHAIN_START_BLOCK":   "8000000",
+	} {
+		if got := env[name]; got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
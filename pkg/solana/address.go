@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package solana implements the parts of the RPC API of Solana nodes needed
+// to follow the activity of a cluster: the base58 encoding of its addresses,
+// the results of the methods reading transactions and accounts, and the
+// values notified to PubSub subscriptions.
+package solana
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// AddressLength is the length in bytes of an account address, the public key
+// of an ed25519 key pair or a program derived address.
+const AddressLength = 32
+
+// base58Alphabet is the Bitcoin alphabet Solana encodes addresses, hashes and
+// signatures with.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Digits = func() [256]int8 {
+	var digits [256]int8
+	for i := range digits {
+		digits[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		digits[base58Alphabet[i]] = int8(i)
+	}
+	return digits
+}()
+
+var bigRadix = big.NewInt(58)
+
+// DecodeBase58 decodes a base58 string, each of its leading '1' characters
+// standing for a zero byte.
+func DecodeBase58(s string) ([]byte, error) {
+	n := new(big.Int)
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	for i := 0; i < len(s); i++ {
+		d := base58Digits[s[i]]
+		if d < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q at offset %d", s[i], i)
+		}
+		n.Mul(n, bigRadix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// EncodeBase58 returns the base58 encoding of b.
+func EncodeBase58(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	n := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, bigRadix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// ParseAddress parses the base58 encoding of an account address.
+func ParseAddress(s string) ([]byte, error) {
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", s, err)
+	}
+	if len(b) != AddressLength {
+		return nil, fmt.Errorf("invalid address %q: expected %d bytes, got %d", s, AddressLength, len(b))
+	}
+	return b, nil
+}
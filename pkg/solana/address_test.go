@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package solana
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	for _, tt := range []struct {
+		hex, base58 string
+	}{
+		{"", ""},
+		{"00", "1"},
+		{"0000", "11"},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"00000000000000000000000000000000000000000000000000000000000000000000", "1111111111111111111111111111111111"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	} {
+		b, _ := hex.DecodeString(tt.hex)
+		if got := EncodeBase58(b); got != tt.base58 {
+			t.Errorf("EncodeBase58(%s) = %s, want %s", tt.hex, got, tt.base58)
+		}
+		got, err := DecodeBase58(tt.base58)
+		if err != nil {
+			t.Fatalf("DecodeBase58(%s) = %v", tt.base58, err)
+		}
+		if !bytes.Equal(got, b) {
+			t.Errorf("DecodeBase58(%s) = %x, want %s", tt.base58, got, tt.hex)
+		}
+	}
+
+	if _, err := DecodeBase58("0OIl"); err == nil {
+		t.Error("DecodeBase58() = nil for characters out of the alphabet, wanted error")
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	for _, s := range []string{
+		"11111111111111111111111111111111",
+		"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",
+		"Vote111111111111111111111111111111111111111",
+	} {
+		b, err := ParseAddress(s)
+		if err != nil {
+			t.Fatalf("ParseAddress(%s) = %v", s, err)
+		}
+		if got := EncodeBase58(b); got != s {
+			t.Errorf("EncodeBase58(ParseAddress(%s)) = %s", s, got)
+		}
+	}
+
+	for name, s := range map[string]string{
+		"not base58": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5D0",
+		"too short":  "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5",
+		"empty":      "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseAddress(s); err == nil {
+				t.Errorf("ParseAddress(%s) = nil, wanted error", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package solana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// Commitment levels of the state a node reads or notifies: the newest slot
+// it processed, the newest slot voted on by a supermajority of the cluster,
+// and the newest slot the supermajority rooted.
+const (
+	CommitmentProcessed = "processed"
+	CommitmentConfirmed = "confirmed"
+	CommitmentFinalized = "finalized"
+)
+
+// chainReferenceLength is the length of the CAIP-2 reference of a cluster,
+// the prefix of the base58 encoding of its genesis hash.
+const chainReferenceLength = 32
+
+// ChainReference returns the CAIP-2 reference of the cluster with the given
+// genesis hash, the result of the getGenesisHash method.
+func ChainReference(genesisHash string) string {
+	if len(genesisHash) > chainReferenceLength {
+		return genesisHash[:chainReferenceLength]
+	}
+	return genesisHash
+}
+
+// PubSubURL returns the URL of the PubSub WebSocket endpoint of the node
+// serving JSON-RPC at rpcURL. As the clients of the Solana Foundation do, it
+// switches to the ws or wss scheme and, when the URL has an explicit port,
+// increments it: nodes listen for PubSub on the port following the RPC one.
+func PubSubURL(rpcURL string) (string, error) {
+	u, err := url.Parse(rpcURL)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported scheme %q of RPC URL, expected http or https", u.Scheme)
+	}
+	if p := u.Port(); p != "" {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil || port == 65535 {
+			return "", fmt.Errorf("invalid port %q of RPC URL", p)
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.FormatUint(port+1, 10))
+	}
+	return u.String(), nil
+}
+
+// Context is the context of the value a method returns or a subscription
+// notifies: the slot the node read it at.
+type Context struct {
+	Slot uint64 `json:"slot"`
+}
+
+// SlotInfo is the value notified to slotSubscribe subscriptions when the
+// node processes a slot.
+type SlotInfo struct {
+	Slot   uint64 `json:"slot"`
+	Parent uint64 `json:"parent"`
+	Root   uint64 `json:"root"`
+}
+
+// LogsNotification is the value notified to logsSubscribe subscriptions for
+// each transaction matching their filter.
+type LogsNotification struct {
+	Context Context `json:"context"`
+	Value   Logs    `json:"value"`
+}
+
+// Logs are the log messages of a transaction. Err is null when the
+// transaction succeeded.
+type Logs struct {
+	Signature string          `json:"signature"`
+	Err       json.RawMessage `json:"err"`
+	Logs      []string        `json:"logs"`
+}
+
+// AccountNotification is the value notified to accountSubscribe
+// subscriptions when the account changes, and the result of the
+// getAccountInfo method.
+type AccountNotification struct {
+	Context Context  `json:"context"`
+	Value   *Account `json:"value"`
+}
+
+// Account is the state of an account. Data holds its content as encoded
+// with the encoding requested from the node, parsed to JSON for the accounts
+// of the programs the node knows, or as a [data, encoding] pair.
+type Account struct {
+	Lamports   uint64          `json:"lamports"`
+	Owner      string          `json:"owner"`
+	Data       json.RawMessage `json:"data"`
+	Executable bool            `json:"executable"`
+	RentEpoch  uint64          `json:"rentEpoch"`
+	Space      uint64          `json:"space"`
+}
+
+// SignatureInfo is an element of the result of the getSignaturesForAddress
+// method, which lists the transactions mentioning an address from the
+// newest.
+type SignatureInfo struct {
+	Signature          string          `json:"signature"`
+	Slot               uint64          `json:"slot"`
+	Err                json.RawMessage `json:"err"`
+	BlockTime          *int64          `json:"blockTime"`
+	ConfirmationStatus string          `json:"confirmationStatus"`
+}
+
+// TransactionResult is the result of the getTransaction method with the
+// json encoding.
+type TransactionResult struct {
+	Slot        uint64           `json:"slot"`
+	BlockTime   *int64           `json:"blockTime"`
+	Meta        *TransactionMeta `json:"meta"`
+	Transaction Transaction      `json:"transaction"`
+	// Version is "legacy" or the number of the version of the
+	// transaction.
+	Version json.RawMessage `json:"version"`
+}
+
+// AccountKeys returns the addresses of the accounts a transaction uses: the
+// ones of its message, followed by the ones it loaded from address lookup
+// tables.
+func (r *TransactionResult) AccountKeys() []string {
+	keys := append([]string(nil), r.Transaction.Message.AccountKeys...)
+	if r.Meta != nil && r.Meta.LoadedAddresses != nil {
+		keys = append(keys, r.Meta.LoadedAddresses.Writable...)
+		keys = append(keys, r.Meta.LoadedAddresses.Readonly...)
+	}
+	return keys
+}
+
+// TransactionMeta is the status of an executed transaction. Err is null when
+// the transaction succeeded.
+type TransactionMeta struct {
+	Err                  json.RawMessage  `json:"err"`
+	Fee                  uint64           `json:"fee"`
+	LogMessages          []string         `json:"logMessages"`
+	ComputeUnitsConsumed *uint64          `json:"computeUnitsConsumed"`
+	LoadedAddresses      *LoadedAddresses `json:"loadedAddresses"`
+}
+
+// LoadedAddresses are the addresses a versioned transaction loaded from
+// address lookup tables.
+type LoadedAddresses struct {
+	Writable []string `json:"writable"`
+	Readonly []string `json:"readonly"`
+}
+
+// Transaction is a signed transaction.
+type Transaction struct {
+	Signatures []string `json:"signatures"`
+	Message    Message  `json:"message"`
+}
+
+// Message is the message of a transaction, which its signatures sign.
+type Message struct {
+	AccountKeys     []string `json:"accountKeys"`
+	RecentBlockhash string   `json:"recentBlockhash"`
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package solana
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChainReference(t *testing.T) {
+	// Genesis hash of mainnet-beta.
+	const genesis = "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d"
+	if got, want := ChainReference(genesis), "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"; got != want {
+		t.Errorf("ChainReference() = %s, want %s", got, want)
+	}
+	if got := ChainReference("short"); got != "short" {
+		t.Errorf("ChainReference() = %s, want short", got)
+	}
+}
+
+func TestPubSubURL(t *testing.T) {
+	for rpc, want := range map[string]string{
+		"https://api.mainnet-beta.solana.com":     "wss://api.mainnet-beta.solana.com",
+		"https://rpc.example.com/?api-key=secret": "wss://rpc.example.com/?api-key=secret",
+		"http://localhost:8899":                   "ws://localhost:8900",
+		"http://[::1]:8899/rpc":                   "ws://[::1]:8900/rpc",
+	} {
+		got, err := PubSubURL(rpc)
+		if err != nil {
+			t.Fatalf("PubSubURL(%s) = %v", rpc, err)
+		}
+		if got != want {
+			t.Errorf("PubSubURL(%s) = %s, want %s", rpc, got, want)
+		}
+	}
+
+	for _, rpc := range []string{"wss://api.mainnet-beta.solana.com", "http://localhost:65535"} {
+		if _, err := PubSubURL(rpc); err == nil {
+			t.Errorf("PubSubURL(%s) = nil, wanted error", rpc)
+		}
+	}
+}
+
+func TestTransactionResultAccountKeys(t *testing.T) {
+	const result = `{
+		"slot": 291540362,
+		"blockTime": 1726000000,
+		"meta": {
+			"err": null,
+			"fee": 5000,
+			"logMessages": ["Program 11111111111111111111111111111111 invoke [1]"],
+			"computeUnitsConsumed": 150,
+			"loadedAddresses": {"writable": ["W"], "readonly": ["R"]}
+		},
+		"transaction": {
+			"signatures": ["sig"],
+			"message": {"accountKeys": ["A", "B"], "recentBlockhash": "hash"}
+		},
+		"version": 0
+	}`
+	var r TransactionResult
+	if err := json.Unmarshal([]byte(result), &r); err != nil {
+		t.Fatal("Unmarshal() =", err)
+	}
+	if diff := cmp.Diff([]string{"A", "B", "W", "R"}, r.AccountKeys()); diff != "" {
+		t.Error("AccountKeys() (-want, +got):", diff)
+	}
+	if r.Meta.Fee != 5000 || *r.Meta.ComputeUnitsConsumed != 150 || *r.BlockTime != 1726000000 {
+		t.Errorf("Unmarshal() = %+v", r)
+	}
+
+	legacy := TransactionResult{Transaction: Transaction{Message: Message{AccountKeys: []string{"A"}}}}
+	if diff := cmp.Diff([]string{"A"}, legacy.AccountKeys()); diff != "" {
+		t.Error("AccountKeys() (-want, +got):", diff)
+	}
+}
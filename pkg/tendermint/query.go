@@ -0,0 +1,356 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tendermint implements the parts of the RPC API of Tendermint and
+// CometBFT nodes needed to follow the events of a chain: the results of the
+// methods reading blocks, the events notified to subscriptions, and the
+// queries selecting them.
+package tendermint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the events of the node that queries select with the tm.event
+// key.
+const (
+	EventNewBlock       = "NewBlock"
+	EventNewBlockHeader = "NewBlockHeader"
+	EventTx             = "Tx"
+)
+
+// Reserved keys of the attributes queries are matched against, alongside
+// the attributes of the ABCI events, keyed by <event type>.<attribute key>.
+const (
+	EventTypeKey   = "tm.event"
+	BlockHeightKey = "block.height"
+	TxHashKey      = "tx.hash"
+	TxHeightKey    = "tx.height"
+)
+
+// dateLayout is the layout of the DATE operands of queries.
+const dateLayout = "2006-01-02"
+
+// operator compares the values of an attribute to an operand.
+type operator string
+
+const (
+	opEqual          operator = "="
+	opLess           operator = "<"
+	opLessOrEqual    operator = "<="
+	opGreater        operator = ">"
+	opGreaterOrEqual operator = ">="
+	opContains       operator = "CONTAINS"
+	opExists         operator = "EXISTS"
+)
+
+// operandKind is the type of the operand of a condition, which determines
+// how attribute values are compared to it.
+type operandKind int
+
+const (
+	operandString operandKind = iota
+	operandNumber
+	operandDate
+	operandTime
+)
+
+type operand struct {
+	kind operandKind
+	s    string
+	n    float64
+	t    time.Time
+}
+
+// condition is a comparison of the values of an attribute.
+type condition struct {
+	key   string
+	op    operator
+	value operand
+}
+
+// Query selects the events notified to a subscription, with the syntax of
+// the queries of Tendermint and CometBFT: conditions on the attributes of
+// events joined by AND, such as
+// "tm.event='Tx' AND transfer.recipient='cosmos1...' AND tx.height > 5".
+type Query struct {
+	source     string
+	conditions []condition
+}
+
+// numberPattern extracts the number of attribute values compared to a
+// number, such as 100 out of "100stake".
+var numberPattern = regexp.MustCompile(`[0-9]+(\.[0-9]*)?`)
+
+// ParseQuery parses a query.
+func ParseQuery(s string) (*Query, error) {
+	p := &queryParser{input: s}
+	q := &Query{source: s}
+	for {
+		c, err := p.condition()
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %q: %w", s, err)
+		}
+		q.conditions = append(q.conditions, c)
+
+		p.skipSpaces()
+		if p.done() {
+			return q, nil
+		}
+		if !p.keyword("AND") {
+			return nil, fmt.Errorf("invalid query %q: expected AND at offset %d", s, p.pos)
+		}
+	}
+}
+
+// String returns the query as given to ParseQuery.
+func (q *Query) String() string {
+	return q.source
+}
+
+// EventType returns the name of the event the query requires with a
+// tm.event='<name>' condition, or "" when it does not require one.
+func (q *Query) EventType() string {
+	for _, c := range q.conditions {
+		if c.key == EventTypeKey && c.op == opEqual && c.value.kind == operandString {
+			return c.value.s
+		}
+	}
+	return ""
+}
+
+// Matches reports whether attributes, the values of the attributes of an
+// event by key, satisfy every condition of the query.
+func (q *Query) Matches(attributes map[string][]string) bool {
+	for _, c := range q.conditions {
+		if !c.matches(attributes[c.key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether any of the values satisfies the condition.
+func (c *condition) matches(values []string) bool {
+	if c.op == opExists {
+		return len(values) > 0
+	}
+	for _, v := range values {
+		if c.matchesValue(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *condition) matchesValue(v string) bool {
+	if c.op == opContains {
+		return strings.Contains(v, c.value.s)
+	}
+
+	var cmp int
+	switch c.value.kind {
+	case operandString:
+		if c.op != opEqual {
+			return false
+		}
+		return v == c.value.s
+	case operandNumber:
+		n, err := strconv.ParseFloat(numberPattern.FindString(v), 64)
+		if err != nil {
+			return false
+		}
+		cmp = compareFloats(n, c.value.n)
+	case operandDate:
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, v); err != nil {
+				return false
+			}
+		}
+		cmp = compareTimes(t, c.value.t)
+	case operandTime:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return false
+		}
+		cmp = compareTimes(t, c.value.t)
+	}
+
+	switch c.op {
+	case opEqual:
+		return cmp == 0
+	case opLess:
+		return cmp < 0
+	case opLessOrEqual:
+		return cmp <= 0
+	case opGreater:
+		return cmp > 0
+	case opGreaterOrEqual:
+		return cmp >= 0
+	}
+	return false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// queryParser reads a query from left to right.
+type queryParser struct {
+	input string
+	pos   int
+}
+
+func (p *queryParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *queryParser) skipSpaces() {
+	for !p.done() && strings.IndexByte(" \t\r\n", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// keyword consumes the given keyword, which must be followed by a space or
+// a quote, or end the input.
+func (p *queryParser) keyword(kw string) bool {
+	p.skipSpaces()
+	rest := p.input[p.pos:]
+	if !strings.HasPrefix(rest, kw) {
+		return false
+	}
+	if len(rest) > len(kw) && strings.IndexByte(" \t\r\n'", rest[len(kw)]) < 0 {
+		return false
+	}
+	p.pos += len(kw)
+	return true
+}
+
+func (p *queryParser) condition() (condition, error) {
+	var c condition
+	p.skipSpaces()
+	start := p.pos
+	for !p.done() && strings.IndexByte(" \t\r\n\\()\"'=<>", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	c.key = p.input[start:p.pos]
+	if c.key == "" {
+		return c, fmt.Errorf("expected an attribute key at offset %d", p.pos)
+	}
+
+	p.skipSpaces()
+	switch {
+	case p.keyword(string(opExists)):
+		c.op = opExists
+		return c, nil
+	case p.keyword(string(opContains)):
+		c.op = opContains
+	case strings.HasPrefix(p.input[p.pos:], "<="):
+		c.op, p.pos = opLessOrEqual, p.pos+2
+	case strings.HasPrefix(p.input[p.pos:], ">="):
+		c.op, p.pos = opGreaterOrEqual, p.pos+2
+	case strings.HasPrefix(p.input[p.pos:], "<"):
+		c.op, p.pos = opLess, p.pos+1
+	case strings.HasPrefix(p.input[p.pos:], ">"):
+		c.op, p.pos = opGreater, p.pos+1
+	case strings.HasPrefix(p.input[p.pos:], "="):
+		c.op, p.pos = opEqual, p.pos+1
+	default:
+		return c, fmt.Errorf("expected an operator after %s at offset %d", c.key, p.pos)
+	}
+
+	value, err := p.operand()
+	if err != nil {
+		return c, err
+	}
+	if c.op == opContains && value.kind != operandString {
+		return c, fmt.Errorf("CONTAINS requires a string operand for %s", c.key)
+	}
+	c.value = value
+	return c, nil
+}
+
+func (p *queryParser) operand() (operand, error) {
+	p.skipSpaces()
+	switch {
+	case p.done():
+		return operand{}, errors.New("unexpected end of query, expected an operand")
+	case p.input[p.pos] == '\'':
+		end := strings.IndexByte(p.input[p.pos+1:], '\'')
+		if end < 0 {
+			return operand{}, fmt.Errorf("unterminated string at offset %d", p.pos)
+		}
+		s := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return operand{kind: operandString, s: s}, nil
+	case p.keyword("DATE"):
+		s := p.word()
+		t, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+		}
+		return operand{kind: operandDate, t: t}, nil
+	case p.keyword("TIME"):
+		s := p.word()
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid time %q, expected RFC 3339", s)
+		}
+		return operand{kind: operandTime, t: t}, nil
+	}
+
+	s := p.word()
+	if !numberPattern.MatchString(s) || numberPattern.FindString(s) != s {
+		return operand{}, fmt.Errorf("invalid operand %q, expected a quoted string, a number, a DATE or a TIME", s)
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return operand{}, fmt.Errorf("invalid number %q", s)
+	}
+	return operand{kind: operandNumber, n: n}, nil
+}
+
+// word consumes the characters up to the next space.
+func (p *queryParser) word() string {
+	p.skipSpaces()
+	start := p.pos
+	for !p.done() && strings.IndexByte(" \t\r\n", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
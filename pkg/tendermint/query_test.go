@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tendermint
+
+import (
+	"testing"
+)
+
+func TestQueryMatches(t *testing.T) {
+	attrs := map[string][]string{
+		"tm.event":           {"Tx"},
+		"tx.height":          {"12"},
+		"transfer.recipient": {"cosmos1alice", "cosmos1bob"},
+		"transfer.amount":    {"100stake"},
+		"message.action":     {"/cosmos.bank.v1beta1.MsgSend"},
+		"block.time":         {"2024-03-01T10:00:00Z"},
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"tm.event='Tx'", true},
+		{"tm.event = 'NewBlock'", false},
+		{"tm.event='Tx' AND transfer.recipient='cosmos1bob'", true},
+		{"tm.event='Tx' AND transfer.recipient='cosmos1carol'", false},
+		{"tx.height = 12", true},
+		{"tx.height > 12", false},
+		{"tx.height >= 12 AND tx.height < 13", true},
+		{"tx.height <= 11", false},
+		{"transfer.amount > 99.5", true},
+		{"message.action CONTAINS 'MsgSend'", true},
+		{"message.action CONTAINS 'MsgDelegate'", false},
+		{"transfer.sender EXISTS", false},
+		{"transfer.recipient EXISTS AND tm.event='Tx'", true},
+		{"block.time > TIME 2024-03-01T09:00:00Z", true},
+		{"block.time < DATE 2024-03-01", false},
+		// Strings are not ordered.
+		{"transfer.recipient > 'cosmos1a'", false},
+	}
+	for _, test := range tests {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) = %v", test.query, err)
+			continue
+		}
+		if got := q.Matches(attrs); got != test.want {
+			t.Errorf("%q matches = %v, want %v", test.query, got, test.want)
+		}
+		if q.String() != test.query {
+			t.Errorf("String() = %q, want %q", q.String(), test.query)
+		}
+	}
+}
+
+func TestQueryEventType(t *testing.T) {
+	tests := map[string]string{
+		"tm.event='Tx' AND transfer.recipient='cosmos1bob'":         EventTx,
+		"transfer.recipient='cosmos1bob' AND tm.event = 'NewBlock'": EventNewBlock,
+		"transfer.recipient='cosmos1bob'":                           "",
+		"tm.event CONTAINS 'Tx'":                                    "",
+	}
+	for query, want := range tests {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) = %v", query, err)
+		}
+		if got := q.EventType(); got != want {
+			t.Errorf("EventType(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"tm.event",
+		"tm.event=",
+		"tm.event='Tx",
+		"tm.event='Tx' OR tm.event='NewBlock'",
+		"tm.event='Tx' AND",
+		"tx.height > 12abc",
+		"tx.height CONTAINS 12",
+		"block.time > DATE 2024-13-01",
+		"block.time > TIME yesterday",
+		"= 'Tx'",
+	} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) = nil, want an error", query)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tendermint
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of the data of the events notified to subscriptions.
+const (
+	EventDataTypeNewBlock       = "tendermint/event/NewBlock"
+	EventDataTypeNewBlockHeader = "tendermint/event/NewBlockHeader"
+	EventDataTypeTx             = "tendermint/event/Tx"
+)
+
+// Status is the result of the status method.
+type Status struct {
+	NodeInfo NodeInfo `json:"node_info"`
+	SyncInfo SyncInfo `json:"sync_info"`
+}
+
+// NodeInfo describes a node.
+type NodeInfo struct {
+	// Network is the chain ID of the chain the node belongs to.
+	Network string `json:"network"`
+	// Version is the version of Tendermint or CometBFT the node runs,
+	// e.g. 0.38.12.
+	Version string `json:"version"`
+}
+
+// SyncInfo tells how far a node is synced, and from which block it keeps
+// the blocks and their results.
+type SyncInfo struct {
+	LatestBlockHash     string    `json:"latest_block_hash"`
+	LatestBlockHeight   int64     `json:"latest_block_height,string"`
+	LatestBlockTime     time.Time `json:"latest_block_time"`
+	EarliestBlockHeight int64     `json:"earliest_block_height,string"`
+	CatchingUp          bool      `json:"catching_up"`
+}
+
+// ResultBlock is the result of the block method.
+type ResultBlock struct {
+	BlockID BlockID `json:"block_id"`
+	Block   Block   `json:"block"`
+}
+
+// BlockID identifies a block.
+type BlockID struct {
+	Hash string `json:"hash"`
+}
+
+// Block is a block of the chain.
+type Block struct {
+	Header Header    `json:"header"`
+	Data   BlockData `json:"data"`
+}
+
+// Header is the header of a block.
+type Header struct {
+	ChainID         string    `json:"chain_id"`
+	Height          int64     `json:"height,string"`
+	Time            time.Time `json:"time"`
+	ProposerAddress string    `json:"proposer_address"`
+}
+
+// BlockData holds the transactions of a block.
+type BlockData struct {
+	Txs [][]byte `json:"txs"`
+}
+
+// ResultBlockResults is the result of the block_results method: the
+// results of the transactions of a block, and the events the application
+// emitted while processing it. Nodes before CometBFT 0.38 split the latter
+// between the beginning and the end of the block.
+type ResultBlockResults struct {
+	Height              int64      `json:"height,string"`
+	TxsResults          []TxResult `json:"txs_results"`
+	BeginBlockEvents    []Event    `json:"begin_block_events"`
+	EndBlockEvents      []Event    `json:"end_block_events"`
+	FinalizeBlockEvents []Event    `json:"finalize_block_events"`
+}
+
+// BlockEvents returns the events the application emitted while processing
+// the block, outside of its transactions.
+func (r *ResultBlockResults) BlockEvents() []Event {
+	var events []Event
+	events = append(events, r.BeginBlockEvents...)
+	events = append(events, r.EndBlockEvents...)
+	return append(events, r.FinalizeBlockEvents...)
+}
+
+// TxResult is the result of the execution of a transaction. A zero code
+// means success.
+type TxResult struct {
+	Code      uint32  `json:"code"`
+	Data      []byte  `json:"data,omitempty"`
+	Log       string  `json:"log,omitempty"`
+	Info      string  `json:"info,omitempty"`
+	GasWanted int64   `json:"gas_wanted,string"`
+	GasUsed   int64   `json:"gas_used,string"`
+	Events    []Event `json:"events,omitempty"`
+	Codespace string  `json:"codespace,omitempty"`
+}
+
+// Event is an ABCI event emitted by the application.
+type Event struct {
+	Type       string           `json:"type"`
+	Attributes []EventAttribute `json:"attributes,omitempty"`
+}
+
+// EventAttribute is an attribute of an ABCI event. Index tells whether the
+// node indexes it.
+type EventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Index bool   `json:"index,omitempty"`
+}
+
+// ResultEvent is the result of the responses notifying an event to a
+// subscription.
+type ResultEvent struct {
+	Query string    `json:"query"`
+	Data  EventData `json:"data"`
+	// Events are the attributes of the event the query matched, by key.
+	Events map[string][]string `json:"events"`
+}
+
+// EventData is the data of an event, whose value depends on its type.
+type EventData struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// EventDataNewBlock is the data of NewBlock events. Nodes before CometBFT
+// 0.38 do not send the ID of the block.
+type EventDataNewBlock struct {
+	Block               Block        `json:"block"`
+	BlockID             *BlockID     `json:"block_id,omitempty"`
+	ResultBeginBlock    resultEvents `json:"result_begin_block"`
+	ResultEndBlock      resultEvents `json:"result_end_block"`
+	ResultFinalizeBlock resultEvents `json:"result_finalize_block"`
+}
+
+// resultEvents holds the events of the result of a step of the processing
+// of a block.
+type resultEvents struct {
+	Events []Event `json:"events,omitempty"`
+}
+
+// Events returns the events the application emitted while processing the
+// block, outside of its transactions.
+func (d *EventDataNewBlock) Events() []Event {
+	var events []Event
+	events = append(events, d.ResultBeginBlock.Events...)
+	events = append(events, d.ResultEndBlock.Events...)
+	return append(events, d.ResultFinalizeBlock.Events...)
+}
+
+// EventDataNewBlockHeader is the data of NewBlockHeader events.
+type EventDataNewBlockHeader struct {
+	Header Header `json:"header"`
+}
+
+// EventDataTx is the data of Tx events.
+type EventDataTx struct {
+	TxResult TxResultWithHeight `json:"TxResult"`
+}
+
+// TxResultWithHeight is a transaction along with its position in the chain
+// and its result.
+type TxResultWithHeight struct {
+	Height int64    `json:"height,string"`
+	Index  uint32   `json:"index"`
+	Tx     []byte   `json:"tx"`
+	Result TxResult `json:"result"`
+}
+
+// TxHash returns the hash of a transaction, in uppercase hexadecimal as
+// nodes report it.
+func TxHash(tx []byte) string {
+	h := sha256.Sum256(tx)
+	return strings.ToUpper(hex.EncodeToString(h[:]))
+}
+
+// AttributesEncoded reports whether the nodes of a version of Tendermint
+// encode the keys and values of event attributes in base64, as up to
+// Tendermint 0.34 in the results of methods and in the data of events.
+func AttributesEncoded(version string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return major == 0 && minor <= 34
+}
+
+// DecodeEvents returns events whose attribute keys and values are decoded
+// from base64.
+func DecodeEvents(events []Event) ([]Event, error) {
+	decoded := make([]Event, len(events))
+	for i, ev := range events {
+		decoded[i] = Event{Type: ev.Type, Attributes: make([]EventAttribute, len(ev.Attributes))}
+		for j, attr := range ev.Attributes {
+			key, err := base64.StdEncoding.DecodeString(attr.Key)
+			if err != nil {
+				return nil, fmt.Errorf("event %s: invalid attribute key %q: %w", ev.Type, attr.Key, err)
+			}
+			value, err := base64.StdEncoding.DecodeString(attr.Value)
+			if err != nil {
+				return nil, fmt.Errorf("event %s: invalid value of attribute %s: %w", ev.Type, key, err)
+			}
+			decoded[i].Attributes[j] = EventAttribute{Key: string(key), Value: string(value), Index: attr.Index}
+		}
+	}
+	return decoded, nil
+}
+
+// attributes returns the values of the attributes of events by composite
+// key, <event type>.<attribute key>, the way queries see them.
+func attributes(events []Event) map[string][]string {
+	attrs := make(map[string][]string)
+	for _, ev := range events {
+		if ev.Type == "" {
+			continue
+		}
+		for _, attr := range ev.Attributes {
+			if attr.Key == "" {
+				continue
+			}
+			key := ev.Type + "." + attr.Key
+			attrs[key] = append(attrs[key], attr.Value)
+		}
+	}
+	return attrs
+}
+
+// NewBlockAttributes returns the attributes queries are matched against for
+// the NewBlock event of a block, given the events of the block.
+func NewBlockAttributes(height int64, events []Event) map[string][]string {
+	attrs := attributes(events)
+	attrs[EventTypeKey] = append(attrs[EventTypeKey], EventNewBlock)
+	attrs[BlockHeightKey] = append(attrs[BlockHeightKey], strconv.FormatInt(height, 10))
+	return attrs
+}
+
+// TxAttributes returns the attributes queries are matched against for the
+// Tx event of a transaction, given its events.
+func TxAttributes(height int64, hash string, events []Event) map[string][]string {
+	attrs := attributes(events)
+	attrs[EventTypeKey] = append(attrs[EventTypeKey], EventTx)
+	attrs[TxHashKey] = append(attrs[TxHashKey], hash)
+	attrs[TxHeightKey] = append(attrs[TxHeightKey], strconv.FormatInt(height, 10))
+	return attrs
+}
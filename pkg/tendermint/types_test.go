@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tendermint
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTxHash(t *testing.T) {
+	if got, want := TxHash([]byte("tx")), "1B5B9CCB3E8D006A5230DE9BDA23FF91EDC794D4F56410560830B418528E446C"; got != want {
+		t.Errorf("TxHash() = %s, want %s", got, want)
+	}
+}
+
+func TestAttributesEncoded(t *testing.T) {
+	tests := map[string]bool{
+		"0.34.24":  true,
+		"v0.34.29": true,
+		"0.33.9":   true,
+		"0.37.4":   false,
+		"0.38.12":  false,
+		"1.0.0":    false,
+		"":         false,
+	}
+	for version, want := range tests {
+		if got := AttributesEncoded(version); got != want {
+			t.Errorf("AttributesEncoded(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	events := []Event{{
+		Type: "transfer",
+		Attributes: []EventAttribute{
+			{Key: "cmVjaXBpZW50", Value: "Y29zbW9zMWJvYg==", Index: true},
+			{Key: "YW1vdW50", Value: ""},
+		},
+	}}
+	got, err := DecodeEvents(events)
+	if err != nil {
+		t.Fatalf("DecodeEvents() = %v", err)
+	}
+	want := []Event{{
+		Type: "transfer",
+		Attributes: []EventAttribute{
+			{Key: "recipient", Value: "cosmos1bob", Index: true},
+			{Key: "amount", Value: ""},
+		},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+
+	if _, err := DecodeEvents([]Event{{Type: "transfer", Attributes: []EventAttribute{{Key: "recipient"}}}}); err == nil {
+		t.Error("DecodeEvents() = nil for plain attributes, want an error")
+	}
+}
+
+func TestTxAttributes(t *testing.T) {
+	events := []Event{{
+		Type:       "transfer",
+		Attributes: []EventAttribute{{Key: "recipient", Value: "cosmos1alice"}, {Key: "recipient", Value: "cosmos1bob"}},
+	}, {
+		Type:       "message",
+		Attributes: []EventAttribute{{Key: "action", Value: "send"}, {Key: "", Value: "ignored"}},
+	}}
+	want := map[string][]string{
+		"tm.event":           {"Tx"},
+		"tx.hash":            {"ABCD"},
+		"tx.height":          {"7"},
+		"transfer.recipient": {"cosmos1alice", "cosmos1bob"},
+		"message.action":     {"send"},
+	}
+	if diff := cmp.Diff(want, TxAttributes(7, "ABCD", events)); diff != "" {
+		t.Errorf("unexpected attributes (-want, +got) = %v", diff)
+	}
+
+	wantBlock := map[string][]string{
+		"tm.event":       {"NewBlock"},
+		"block.height":   {"7"},
+		"message.action": {"send"},
+	}
+	if diff := cmp.Diff(wantBlock, NewBlockAttributes(7, events[1:])); diff != "" {
+		t.Errorf("unexpected block attributes (-want, +got) = %v", diff)
+	}
+}
+
+func TestUnmarshalResultEvent(t *testing.T) {
+	raw := `{
+		"query": "tm.event='Tx'",
+		"data": {
+			"type": "tendermint/event/Tx",
+			"value": {"TxResult": {"height": "12", "index": 1, "tx": "dHg=", "result": {
+				"code": 0, "gas_wanted": "200000", "gas_used": "51234",
+				"events": [{"type": "transfer", "attributes": [{"key": "recipient", "value": "cosmos1bob", "index": true}]}]
+			}}}
+		},
+		"events": {"tm.event": ["Tx"], "tx.hash": ["1B5B9CCB3E8D006A5230DE9BDA23FF91EDC794D4F56410560830B418528E446C"]}
+	}`
+	var ev ResultEvent
+	if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if ev.Data.Type != EventDataTypeTx {
+		t.Fatalf("data type = %s, want %s", ev.Data.Type, EventDataTypeTx)
+	}
+	var data EventDataTx
+	if err := json.Unmarshal(ev.Data.Value, &data); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := TxResultWithHeight{
+		Height: 12,
+		Index:  1,
+		Tx:     []byte("tx"),
+		Result: TxResult{
+			GasWanted: 200000,
+			GasUsed:   51234,
+			Events: []Event{{
+				Type:       "transfer",
+				Attributes: []EventAttribute{{Key: "recipient", Value: "cosmos1bob", Index: true}},
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, data.TxResult); diff != "" {
+		t.Errorf("unexpected transaction (-want, +got) = %v", diff)
+	}
+	if got := TxHash(data.TxResult.Tx); got != ev.Events[TxHashKey][0] {
+		t.Errorf("TxHash() = %s, want %s", got, ev.Events[TxHashKey][0])
+	}
+}
+
+func TestNewBlockEvents(t *testing.T) {
+	raw := `{
+		"block": {"header": {"chain_id": "cosmoshub-4", "height": "5", "time": "2024-03-01T10:00:00Z"}, "data": {"txs": null}},
+		"result_begin_block": {"events": [{"type": "mint"}]},
+		"result_end_block": {"events": [{"type": "complete_unbonding"}]}
+	}`
+	var data EventDataNewBlock
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if data.Block.Header.Height != 5 || data.BlockID != nil {
+		t.Errorf("block = %+v, ID %v, want height 5 without ID", data.Block.Header, data.BlockID)
+	}
+	if diff := cmp.Diff([]Event{{Type: "mint"}, {Type: "complete_unbonding"}}, data.Events()); diff != "" {
+		t.Errorf("unexpected events (-want, +got) = %v", diff)
+	}
+
+	results := &ResultBlockResults{FinalizeBlockEvents: []Event{{Type: "mint"}}}
+	if diff := cmp.Diff([]Event{{Type: "mint"}}, results.BlockEvents()); diff != "" {
+		t.Errorf("unexpected block events (-want, +got) = %v", diff)
+	}
+}